@@ -1,49 +1,271 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/codecrafters-io/kafka-starter-go/core/application/api_version_service"
+	"github.com/codecrafters-io/kafka-starter-go/core/application/create_partitions_service"
+	"github.com/codecrafters-io/kafka-starter-go/core/application/create_topics_service"
+	"github.com/codecrafters-io/kafka-starter-go/core/application/delete_topics_service"
+	"github.com/codecrafters-io/kafka-starter-go/core/application/describe_producers_service"
 	"github.com/codecrafters-io/kafka-starter-go/core/application/fetch_service"
+	"github.com/codecrafters-io/kafka-starter-go/core/application/group_coordinator_service"
 	"github.com/codecrafters-io/kafka-starter-go/core/application/kafka_describe_topic_service"
 	"github.com/codecrafters-io/kafka-starter-go/core/application/kafka_router"
+	"github.com/codecrafters-io/kafka-starter-go/core/application/list_offsets_service"
+	"github.com/codecrafters-io/kafka-starter-go/core/application/metadata_service"
+	"github.com/codecrafters-io/kafka-starter-go/core/application/produce_service"
+	"github.com/codecrafters-io/kafka-starter-go/core/application/transaction_coordinator_service"
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
 	"github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/driving"
 	parser "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/parser"
 	"github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/cluster_metadata_repository"
-	fetch_repository "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/fetch"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/consumer_offsets_repository"
 	partition_file_repository "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/partition_repository"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/transaction_state_repository"
 )
 
 func main() {
+	os.Exit(run())
+}
+
+// run serves until the broker is interrupted or terminated and returns the exit code.
+// The repositories and coordinators close once the server stops, so their state, such
+// as the producer snapshots, is written before the process exits.
+func run() int {
 	// You can use print statements as follows for debugging, they'll be visible when running tests.
 	fmt.Println("Logs from your program will appear here!")
 
+	tlsCertFile := flag.String("tls-cert", "", "PEM certificate chain; serves the listener over TLS when set")
+	tlsKeyFile := flag.String("tls-key", "", "PEM private key of the TLS certificate")
+	tlsClientCAFile := flag.String("tls-client-ca", "", "PEM CAs verifying client certificates")
+	tlsClientAuth := flag.String("tls-client-auth", "none", "client certificates: none, requested or required")
+	tlsMinVersion := flag.String("tls-min-version", "TLSv1.2", "oldest TLS version accepted: TLSv1.2 or TLSv1.3")
+	tlsCipherSuites := flag.String("tls-cipher-suites", "", "comma-separated cipher suites accepted up to TLS 1.2")
+	flag.Parse()
+	var serverOptions []driving.TCPServerOption
+	if *tlsCertFile != "" {
+		tlsConfig, err := tlsListenerConfig(*tlsCertFile, *tlsKeyFile, *tlsClientCAFile, *tlsClientAuth, *tlsMinVersion, *tlsCipherSuites)
+		if err != nil {
+			fmt.Printf("Invalid TLS configuration: %v\n", err)
+			return 2
+		}
+		serverOptions = append(serverOptions, driving.WithTLS(tlsConfig))
+	}
+
 	// Create the parser adapters (protocol parser - infrastructure)
 	protocolParser := parser.NewKafkaProtocolParser()
 	apiVersionService := api_version_service.NewApiVersionService(protocolParser)
 
 	protocolParserDescribeTopic := parser.NewKafkaProtocolParserDescribeTopic()
 	clusterMetadataRepository := cluster_metadata_repository.NewClusterMetadataRepository()
+	if err := clusterMetadataRepository.Start(); err != nil {
+		fmt.Printf("Loading cluster metadata failed: %v\n", err)
+	}
+	defer clusterMetadataRepository.Close()
 	kafkaServiceDescribeTopic := kafka_describe_topic_service.NewKafkaDescribeTopicService(protocolParserDescribeTopic, clusterMetadataRepository)
 
 	protocolParserFetch := parser.NewKafkaProtocolParserFetch()
-	fetchRepository := fetch_repository.NewFetchRepository()
-	partitionFileRepository := partition_file_repository.NewPartitionFileRepository()
+	fetchPurgatory := fetch_service.NewFetchPurgatory()
+	partitionFileRepository := partition_file_repository.NewPartitionFileRepository(
+		partition_file_repository.WithAppendListener(fetchPurgatory))
+	if err := partitionFileRepository.Start(); err != nil {
+		fmt.Printf("Starting the partition deleter failed: %v\n", err)
+	}
+	defer partitionFileRepository.Close()
 	fetchService := fetch_service.NewFetchService(
 		protocolParserFetch,
-		fetchRepository,
 		clusterMetadataRepository,
-		partitionFileRepository)
+		partitionFileRepository,
+		fetchPurgatory,
+		fetch_service.NewFetchSessionCache(fetch_service.DefaultMaxFetchSessions))
+
+	protocolParserProduce := parser.NewKafkaProtocolParserProduce()
+	produceService := produce_service.NewProduceService(protocolParserProduce, clusterMetadataRepository, partitionFileRepository)
+
+	protocolParserListOffsets := parser.NewKafkaProtocolParserListOffsets()
+	listOffsetsService := list_offsets_service.NewListOffsetsService(protocolParserListOffsets, clusterMetadataRepository, partitionFileRepository)
+
+	protocolParserDescribeProducers := parser.NewKafkaProtocolParserDescribeProducers()
+	describeProducersService := describe_producers_service.NewDescribeProducersService(protocolParserDescribeProducers, clusterMetadataRepository, partitionFileRepository)
+
+	brokerConfig := domain.BrokerConfig{
+		Broker:    domain.Broker{NodeID: 1, Host: "localhost", Port: 9092},
+		ClusterID: cluster_metadata_repository.ReadClusterID(partition_file_repository.DefaultLogDir),
+	}
+	protocolParserMetadata := parser.NewKafkaProtocolParserMetadata()
+	metadataService := metadata_service.NewMetadataService(protocolParserMetadata, clusterMetadataRepository, brokerConfig)
+
+	protocolParserCreateTopics := parser.NewKafkaProtocolParserCreateTopics()
+	createTopicsService := create_topics_service.NewCreateTopicsService(protocolParserCreateTopics, clusterMetadataRepository, clusterMetadataRepository, partitionFileRepository, brokerConfig)
+
+	protocolParserDeleteTopics := parser.NewKafkaProtocolParserDeleteTopics()
+	deleteTopicsService := delete_topics_service.NewDeleteTopicsService(protocolParserDeleteTopics, clusterMetadataRepository, clusterMetadataRepository, partitionFileRepository)
+
+	protocolParserCreatePartitions := parser.NewKafkaProtocolParserCreatePartitions()
+	createPartitionsService := create_partitions_service.NewCreatePartitionsService(protocolParserCreatePartitions, clusterMetadataRepository, clusterMetadataRepository, partitionFileRepository, brokerConfig)
+
+	protocolParserFindCoordinator := parser.NewKafkaProtocolParserFindCoordinator()
+	findCoordinatorService := group_coordinator_service.NewFindCoordinatorService(protocolParserFindCoordinator, brokerConfig)
+
+	consumerOffsetsRepository := consumer_offsets_repository.NewConsumerOffsetsRepository(partitionFileRepository)
+	groupCoordinator := group_coordinator_service.NewGroupCoordinator(
+		group_coordinator_service.WithOffsetsRepository(consumerOffsetsRepository),
+		group_coordinator_service.WithPartitionRepository(partitionFileRepository))
+	if err := groupCoordinator.Start(); err != nil {
+		fmt.Printf("Loading committed offsets failed: %v\n", err)
+	}
+	defer groupCoordinator.Close()
+	protocolParserJoinGroup := parser.NewKafkaProtocolParserJoinGroup()
+	joinGroupService := group_coordinator_service.NewJoinGroupService(protocolParserJoinGroup, groupCoordinator)
+	protocolParserSyncGroup := parser.NewKafkaProtocolParserSyncGroup()
+	syncGroupService := group_coordinator_service.NewSyncGroupService(protocolParserSyncGroup, groupCoordinator)
+	protocolParserHeartbeat := parser.NewKafkaProtocolParserHeartbeat()
+	heartbeatService := group_coordinator_service.NewHeartbeatService(protocolParserHeartbeat, groupCoordinator)
+	protocolParserLeaveGroup := parser.NewKafkaProtocolParserLeaveGroup()
+	leaveGroupService := group_coordinator_service.NewLeaveGroupService(protocolParserLeaveGroup, groupCoordinator)
+	protocolParserOffsetCommit := parser.NewKafkaProtocolParserOffsetCommit()
+	offsetCommitService := group_coordinator_service.NewOffsetCommitService(protocolParserOffsetCommit, clusterMetadataRepository, groupCoordinator)
+	protocolParserOffsetFetch := parser.NewKafkaProtocolParserOffsetFetch()
+	offsetFetchService := group_coordinator_service.NewOffsetFetchService(protocolParserOffsetFetch, groupCoordinator)
+	protocolParserOffsetDelete := parser.NewKafkaProtocolParserOffsetDelete()
+	offsetDeleteService := group_coordinator_service.NewOffsetDeleteService(protocolParserOffsetDelete, clusterMetadataRepository, groupCoordinator)
+	protocolParserListGroups := parser.NewKafkaProtocolParserListGroups()
+	listGroupsService := group_coordinator_service.NewListGroupsService(protocolParserListGroups, groupCoordinator)
+	protocolParserDescribeGroups := parser.NewKafkaProtocolParserDescribeGroups()
+	describeGroupsService := group_coordinator_service.NewDescribeGroupsService(protocolParserDescribeGroups, groupCoordinator)
+	protocolParserDeleteGroups := parser.NewKafkaProtocolParserDeleteGroups()
+	deleteGroupsService := group_coordinator_service.NewDeleteGroupsService(protocolParserDeleteGroups, groupCoordinator)
+	protocolParserConsumerGroupHeartbeat := parser.NewKafkaProtocolParserConsumerGroupHeartbeat()
+	consumerGroupHeartbeatService := group_coordinator_service.NewConsumerGroupHeartbeatService(protocolParserConsumerGroupHeartbeat, clusterMetadataRepository, groupCoordinator)
+	protocolParserConsumerGroupDescribe := parser.NewKafkaProtocolParserConsumerGroupDescribe()
+	consumerGroupDescribeService := group_coordinator_service.NewConsumerGroupDescribeService(protocolParserConsumerGroupDescribe, clusterMetadataRepository, groupCoordinator)
+	protocolParserTxnOffsetCommit := parser.NewKafkaProtocolParserTxnOffsetCommit()
+	txnOffsetCommitService := group_coordinator_service.NewTxnOffsetCommitService(protocolParserTxnOffsetCommit, clusterMetadataRepository, groupCoordinator)
+
+	producerIDManager := transaction_coordinator_service.NewProducerIDManager(brokerConfig.Broker.NodeID, clusterMetadataRepository)
+	txnMarkerWriter := transaction_coordinator_service.NewTxnMarkerWriter(clusterMetadataRepository, partitionFileRepository, groupCoordinator)
+	transactionCoordinator := transaction_coordinator_service.NewTransactionCoordinator(
+		producerIDManager,
+		txnMarkerWriter,
+		consumerOffsetsRepository,
+		transaction_coordinator_service.WithTransactionStateRepository(
+			transaction_state_repository.NewTransactionStateRepository(partitionFileRepository)))
+	if err := transactionCoordinator.Start(); err != nil {
+		fmt.Printf("Loading transaction state failed: %v\n", err)
+	}
+	defer transactionCoordinator.Close()
+	protocolParserInitProducerId := parser.NewKafkaProtocolParserInitProducerId()
+	initProducerIdService := transaction_coordinator_service.NewInitProducerIdService(protocolParserInitProducerId, transactionCoordinator)
+	protocolParserAddPartitionsToTxn := parser.NewKafkaProtocolParserAddPartitionsToTxn()
+	addPartitionsToTxnService := transaction_coordinator_service.NewAddPartitionsToTxnService(protocolParserAddPartitionsToTxn, clusterMetadataRepository, transactionCoordinator)
+	protocolParserAddOffsetsToTxn := parser.NewKafkaProtocolParserAddOffsetsToTxn()
+	addOffsetsToTxnService := transaction_coordinator_service.NewAddOffsetsToTxnService(protocolParserAddOffsetsToTxn, transactionCoordinator)
+	protocolParserEndTxn := parser.NewKafkaProtocolParserEndTxn()
+	endTxnService := transaction_coordinator_service.NewEndTxnService(protocolParserEndTxn, transactionCoordinator)
+	protocolParserWriteTxnMarkers := parser.NewKafkaProtocolParserWriteTxnMarkers()
+	writeTxnMarkersService := transaction_coordinator_service.NewWriteTxnMarkersService(protocolParserWriteTxnMarkers, txnMarkerWriter)
+	protocolParserDescribeTransactions := parser.NewKafkaProtocolParserDescribeTransactions()
+	describeTransactionsService := transaction_coordinator_service.NewDescribeTransactionsService(protocolParserDescribeTransactions, transactionCoordinator)
+	protocolParserListTransactions := parser.NewKafkaProtocolParserListTransactions()
+	listTransactionsService := transaction_coordinator_service.NewListTransactionsService(protocolParserListTransactions, transactionCoordinator)
 
 	// Create unified router that routes based on API key
 	router := kafka_router.NewKafkaRouter(apiVersionService, kafkaServiceDescribeTopic, fetchService)
+	router.RegisterHandler(domain.ApiKeyProduce, produceService)
+	router.RegisterHandler(domain.ApiKeyListOffsets, listOffsetsService)
+	router.RegisterHandler(domain.ApiKeyMetadata, metadataService)
+	router.RegisterHandler(domain.ApiKeyCreateTopics, createTopicsService)
+	router.RegisterHandler(domain.ApiKeyDeleteTopics, deleteTopicsService)
+	router.RegisterHandler(domain.ApiKeyCreatePartitions, createPartitionsService)
+	router.RegisterHandler(domain.ApiKeyFindCoordinator, findCoordinatorService)
+	router.RegisterHandler(domain.ApiKeyInitProducerId, initProducerIdService)
+	router.RegisterHandler(domain.ApiKeyJoinGroup, joinGroupService)
+	router.RegisterHandler(domain.ApiKeySyncGroup, syncGroupService)
+	router.RegisterHandler(domain.ApiKeyHeartbeat, heartbeatService)
+	router.RegisterHandler(domain.ApiKeyLeaveGroup, leaveGroupService)
+	router.RegisterHandler(domain.ApiKeyOffsetCommit, offsetCommitService)
+	router.RegisterHandler(domain.ApiKeyOffsetFetch, offsetFetchService)
+	router.RegisterHandler(domain.ApiKeyOffsetDelete, offsetDeleteService)
+	router.RegisterHandler(domain.ApiKeyListGroups, listGroupsService)
+	router.RegisterHandler(domain.ApiKeyDescribeGroups, describeGroupsService)
+	router.RegisterHandler(domain.ApiKeyDeleteGroups, deleteGroupsService)
+	router.RegisterHandler(domain.ApiKeyConsumerGroupHeartbeat, consumerGroupHeartbeatService)
+	router.RegisterHandler(domain.ApiKeyConsumerGroupDescribe, consumerGroupDescribeService)
+	router.RegisterHandler(domain.ApiKeyAddPartitionsToTxn, addPartitionsToTxnService)
+	router.RegisterHandler(domain.ApiKeyAddOffsetsToTxn, addOffsetsToTxnService)
+	router.RegisterHandler(domain.ApiKeyEndTxn, endTxnService)
+	router.RegisterHandler(domain.ApiKeyWriteTxnMarkers, writeTxnMarkersService)
+	router.RegisterHandler(domain.ApiKeyTxnOffsetCommit, txnOffsetCommitService)
+	router.RegisterHandler(domain.ApiKeyDescribeProducers, describeProducersService)
+	router.RegisterHandler(domain.ApiKeyDescribeTransactions, describeTransactionsService)
+	router.RegisterHandler(domain.ApiKeyListTransactions, listTransactionsService)
 
-	tcpServer := driving.NewTCPServer(router, "0.0.0.0:9092")
+	tcpServer := driving.NewTCPServer(router, "0.0.0.0:9092", serverOptions...)
+	defer tcpServer.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// Start the server
-	if err := tcpServer.Start(); err != nil {
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- tcpServer.Start()
+	}()
+	select {
+	case <-ctx.Done():
+		fmt.Println("Shutting down")
+		return 0
+	case err := <-serverErr:
 		fmt.Printf("Failed to start server: %v\n", err)
-		os.Exit(1)
+		return 1
+	}
+}
+
+// tlsListenerConfig builds the TLS listener configuration from the command line, naming
+// client authentication and TLS versions the way Kafka's ssl.client.auth and
+// ssl.protocol do
+func tlsListenerConfig(certFile, keyFile, clientCAFile, clientAuth, minVersion, cipherSuites string) (driving.TLSListenerConfig, error) {
+	config := driving.TLSListenerConfig{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientCAFile: clientCAFile,
+	}
+	if keyFile == "" {
+		return config, fmt.Errorf("-tls-cert needs -tls-key")
+	}
+
+	switch clientAuth {
+	case "none":
+		config.ClientAuth = tls.NoClientCert
+	case "requested":
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	case "required":
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return config, fmt.Errorf("unknown client authentication %q", clientAuth)
+	}
+
+	switch minVersion {
+	case "TLSv1.2":
+		config.MinVersion = tls.VersionTLS12
+	case "TLSv1.3":
+		config.MinVersion = tls.VersionTLS13
+	default:
+		return config, fmt.Errorf("unsupported TLS version %q", minVersion)
+	}
+
+	if cipherSuites != "" {
+		for _, suite := range strings.Split(cipherSuites, ",") {
+			config.CipherSuites = append(config.CipherSuites, strings.TrimSpace(suite))
+		}
 	}
+	return config, nil
 }
@@ -34,18 +34,16 @@ func (s *KafkaService) HandleRequest(req domain.Request) (domain.Response, error
 
 	errorCode := s.determineErrorCode(parsedReq.APIVersion)
 
+	apiKeys := getSupportedApiKeys()
+
 	// Build response data structure
 	responseData := &parser.ResponseData{
 		CorrelationID:      parsedReq.CorrelationID,
 		ErrorCode:          errorCode,
-		ApiKeysArrayLength: []byte{0x04},
-		ApiKeys: []parser.ApiKey{
-			getFetchApiKey(),
-			getApiVersionApiKey(),
-			getDescribeTopicPartitionsApiKey(),
-		},
-		ThrottleTimeMs:  []byte{0x00, 0x00, 0x00, 0x00},
-		TagBufferParent: []byte{0x00},
+		ApiKeysArrayLength: []byte{byte(len(apiKeys) + 1)},
+		ApiKeys:            apiKeys,
+		ThrottleTimeMs:     []byte{0x00, 0x00, 0x00, 0x00},
+		TagBufferParent:    []byte{0x00},
 	}
 
 	// Encode the response using the protocol parser (infrastructure concern)
@@ -76,34 +74,56 @@ func (s *KafkaService) determineErrorCode(apiVersion int) []byte {
 	return errorCodeBuffer
 }
 
-func getDescribeTopicPartitionsApiKey() parser.ApiKey {
-
-	apiKey := int16ToBytes(75)
-
-	return parser.ApiKey{
-		ApiKey:         apiKey,
-		MinVersion:     []byte{0x00, 0x00},
-		MaxVersion:     []byte{0x00, 0x00},
-		TagBufferChild: []byte{0x00},
-	}
-}
-
-func getFetchApiKey() parser.ApiKey {
-	return parser.ApiKey{
-		ApiKey:         int16ToBytes(1),
-		MinVersion:     []byte{0x00, 0x00},
-		MaxVersion:     []byte{0x00, 0x10},
-		TagBufferChild: []byte{0x00},
-	}
+// supportedApiVersions lists every API key the broker handles with its version range
+var supportedApiVersions = []struct {
+	apiKey     int16
+	minVersion int16
+	maxVersion int16
+}{
+	{domain.ApiKeyProduce, 3, 11},
+	{domain.ApiKeyFetch, 0, 16},
+	{domain.ApiKeyListOffsets, 0, 7},
+	{domain.ApiKeyMetadata, 0, 12},
+	{domain.ApiKeyOffsetCommit, 0, 9},
+	{domain.ApiKeyOffsetFetch, 0, 9},
+	{domain.ApiKeyFindCoordinator, 0, 5},
+	{domain.ApiKeyJoinGroup, 0, 9},
+	{domain.ApiKeyHeartbeat, 0, 4},
+	{domain.ApiKeyLeaveGroup, 0, 5},
+	{domain.ApiKeySyncGroup, 0, 5},
+	{domain.ApiKeyDescribeGroups, 0, 5},
+	{domain.ApiKeyListGroups, 0, 5},
+	{domain.ApiKeyApiVersions, 0, 4},
+	{domain.ApiKeyCreateTopics, 2, 7},
+	{domain.ApiKeyDeleteTopics, 1, 6},
+	{domain.ApiKeyInitProducerId, 0, 5},
+	{domain.ApiKeyAddPartitionsToTxn, 0, 3},
+	{domain.ApiKeyAddOffsetsToTxn, 0, 3},
+	{domain.ApiKeyEndTxn, 0, 3},
+	{domain.ApiKeyWriteTxnMarkers, 0, 1},
+	{domain.ApiKeyTxnOffsetCommit, 0, 3},
+	{domain.ApiKeyCreatePartitions, 0, 3},
+	{domain.ApiKeyDeleteGroups, 0, 2},
+	{domain.ApiKeyOffsetDelete, 0, 0},
+	{domain.ApiKeyDescribeProducers, 0, 0},
+	{domain.ApiKeyDescribeTransactions, 0, 0},
+	{domain.ApiKeyListTransactions, 0, 1},
+	{domain.ApiKeyConsumerGroupHeartbeat, 0, 1},
+	{domain.ApiKeyConsumerGroupDescribe, 0, 0},
+	{domain.ApiKeyDescribeTopicPartitions, 0, 0},
 }
 
-func getApiVersionApiKey() parser.ApiKey {
-	return parser.ApiKey{
-		ApiKey:         []byte{0x00, 0x12},
-		MinVersion:     []byte{0x00, 0x00},
-		MaxVersion:     []byte{0x00, 0x04},
-		TagBufferChild: []byte{0x00},
+func getSupportedApiKeys() []parser.ApiKey {
+	apiKeys := make([]parser.ApiKey, 0, len(supportedApiVersions))
+	for _, supported := range supportedApiVersions {
+		apiKeys = append(apiKeys, parser.ApiKey{
+			ApiKey:         int16ToBytes(supported.apiKey),
+			MinVersion:     int16ToBytes(supported.minVersion),
+			MaxVersion:     int16ToBytes(supported.maxVersion),
+			TagBufferChild: []byte{0x00},
+		})
 	}
+	return apiKeys
 }
 
 func int16ToBytes(i int16) []byte {
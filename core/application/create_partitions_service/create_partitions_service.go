@@ -0,0 +1,214 @@
+package create_partitions_service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+	port_repo "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/partition_file_repository"
+)
+
+// CreatePartitionsService implements the driving port for CreatePartitions requests,
+// which grow existing topics. Partitions can only be added, never removed.
+type CreatePartitionsService struct {
+	parser                    parser.CreatePartitionsParser
+	metadata_repository       port_cluster_metadata_repository.ClusterMetadataRepository
+	metadata_writer           port_cluster_metadata_repository.ClusterMetadataWriter
+	partition_file_repository port_repo.PartitionFileRepository
+	brokerConfig              domain.BrokerConfig
+	maxPartitions             int32
+}
+
+type CreatePartitionsOption func(*CreatePartitionsService)
+
+// WithMaxPartitions changes the most partitions a topic may be grown to from
+// domain.DefaultMaxPartitions
+func WithMaxPartitions(maxPartitions int32) CreatePartitionsOption {
+	return func(s *CreatePartitionsService) {
+		s.maxPartitions = maxPartitions
+	}
+}
+
+func NewCreatePartitionsService(parser parser.CreatePartitionsParser, metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository, metadata_writer port_cluster_metadata_repository.ClusterMetadataWriter, partition_file_repository port_repo.PartitionFileRepository, brokerConfig domain.BrokerConfig, opts ...CreatePartitionsOption) driving.KafkaHandler {
+	s := &CreatePartitionsService{
+		parser:                    parser,
+		metadata_repository:       metadata_repository,
+		metadata_writer:           metadata_writer,
+		partition_file_repository: partition_file_repository,
+		brokerConfig:              brokerConfig,
+		maxPartitions:             domain.DefaultMaxPartitions,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *CreatePartitionsService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	clusterMetaData, err := s.metadata_repository.GetClusterMetadata()
+	if err != nil {
+		fmt.Println("CreatePartitions: there is no cluster metadata", err.Error())
+	}
+
+	requested := make(map[string]int, len(parsedReq.Topics))
+	for _, topic := range parsedReq.Topics {
+		requested[topic.Name]++
+	}
+
+	responseData := &domain.ResponseDataCreatePartitions{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		Results:        make([]domain.CreatePartitionsTopicResult, 0, len(parsedReq.Topics)),
+	}
+	for _, topic := range parsedReq.Topics {
+		if requested[topic.Name] > 1 {
+			responseData.Results = append(responseData.Results, topicError(topic.Name, domain.ErrorCodeInvalidRequest, "Duplicate topic name."))
+			continue
+		}
+		responseData.Results = append(responseData.Results, s.createPartitions(clusterMetaData, topic, parsedReq.ValidateOnly))
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
+
+// createPartitions validates the new partition count against the topic's current
+// partitions, places the new replicas and, unless validateOnly is set, writes them to
+// the metadata log
+func (s *CreatePartitionsService) createPartitions(clusterMetaData port_cluster_metadata_repository.ClusterMetadataRepositoryResponse, topic domain.CreatePartitionsTopic, validateOnly bool) domain.CreatePartitionsTopicResult {
+	topicUuid, exists := clusterMetaData.TopicNameTopicUuidMap[topic.Name]
+	topicMetadata := clusterMetaData.TopicUUIDTopicMetadataInfoMap[topicUuid]
+	if !exists || topicMetadata == nil {
+		return topicError(topic.Name, domain.ErrorCodeInvalidPartitions, fmt.Sprintf("The topic '%s' does not exist.", topic.Name))
+	}
+
+	current := clusterMetaData.TopicUUIDPartitionMetadataMap[topicUuid]
+	if int(topic.Count) < len(current) {
+		return topicError(topic.Name, domain.ErrorCodeInvalidPartitions, fmt.Sprintf("The topic %s currently has %d partition(s); %d would not be an increase.", topic.Name, len(current), topic.Count))
+	}
+	if int(topic.Count) == len(current) {
+		return topicError(topic.Name, domain.ErrorCodeInvalidPartitions, fmt.Sprintf("Topic already has %d partition(s).", len(current)))
+	}
+	if topic.Count > s.maxPartitions {
+		return topicError(topic.Name, domain.ErrorCodeInvalidPartitions, fmt.Sprintf("Number of partitions %d exceeds the maximum of %d partitions per topic.", topic.Count, s.maxPartitions))
+	}
+
+	replicationFactor := 1
+	if len(current) > 0 {
+		replicationFactor = len(current[0].ReplicaNodesArray) / 4
+	}
+	partitions, errorCode, message := assignReplicas(topic, int32(len(current)), replicationFactor, s.usableBrokers(clusterMetaData))
+	if errorCode != domain.ErrorCodeNone {
+		return topicError(topic.Name, errorCode, message)
+	}
+	if validateOnly {
+		return domain.CreatePartitionsTopicResult{Name: topic.Name, ErrorCode: domain.ErrorCodeNone}
+	}
+
+	err := s.metadata_writer.CreatePartitions(topicMetadata.TopicId, partitions)
+	if errors.Is(err, domain.ErrUnknownTopicID) || errors.Is(err, domain.ErrInvalidPartitions) {
+		return topicError(topic.Name, domain.ErrorCodeInvalidPartitions, err.Error())
+	}
+	if err != nil {
+		fmt.Printf("CreatePartitions: writing partitions of %s failed: %v\n", topic.Name, err)
+		return topicError(topic.Name, domain.ErrorCodeUnknownServerError, err.Error())
+	}
+
+	for _, partition := range partitions {
+		if !slices.Contains(partition.Replicas, s.brokerConfig.NodeID) {
+			continue
+		}
+		if err := s.partition_file_repository.CreatePartition(topic.Name, int(partition.PartitionIndex), topicMetadata.TopicId); err != nil {
+			fmt.Printf("CreatePartitions: creating partition %s-%d failed: %v\n", topic.Name, partition.PartitionIndex, err)
+		}
+	}
+	return domain.CreatePartitionsTopicResult{Name: topic.Name, ErrorCode: domain.ErrorCodeNone}
+}
+
+// usableBrokers returns the unfenced brokers new replicas can be placed on, in ID order.
+// A log without broker registrations is served by this broker alone.
+func (s *CreatePartitionsService) usableBrokers(clusterMetaData port_cluster_metadata_repository.ClusterMetadataRepositoryResponse) []int32 {
+	if len(clusterMetaData.Brokers) == 0 {
+		return []int32{s.brokerConfig.NodeID}
+	}
+	brokers := []int32{}
+	for brokerID, registration := range clusterMetaData.Brokers {
+		if !registration.Fenced {
+			brokers = append(brokers, brokerID)
+		}
+	}
+	slices.Sort(brokers)
+	return brokers
+}
+
+// assignReplicas returns the partitions added after the first existing ones, taken from
+// the manual assignments or spread round-robin over the brokers with the replication
+// factor of the existing partitions
+func assignReplicas(topic domain.CreatePartitionsTopic, existing int32, replicationFactor int, brokers []int32) ([]domain.NewPartition, int16, string) {
+	added := topic.Count - existing
+	if topic.Assignments != nil {
+		if len(topic.Assignments) != int(added) {
+			return nil, domain.ErrorCodeInvalidReplicaAssignment, fmt.Sprintf("Attempted to add %d additional partition(s), but only %d assignment(s) were specified.", added, len(topic.Assignments))
+		}
+		return manualAssignment(topic.Assignments, existing, replicationFactor, brokers)
+	}
+
+	if replicationFactor > len(brokers) {
+		return nil, domain.ErrorCodeInvalidReplicationFactor, fmt.Sprintf("Unable to replicate the partition %d time(s): The target replication factor of %d cannot be reached because only %d broker(s) are registered.", replicationFactor, replicationFactor, len(brokers))
+	}
+	partitions := make([]domain.NewPartition, 0, added)
+	for partitionIndex := existing; partitionIndex < topic.Count; partitionIndex++ {
+		replicas := make([]int32, 0, replicationFactor)
+		for replica := range replicationFactor {
+			replicas = append(replicas, brokers[(int(partitionIndex)+replica)%len(brokers)])
+		}
+		partitions = append(partitions, domain.NewPartition{PartitionIndex: partitionIndex, Replicas: replicas})
+	}
+	return partitions, domain.ErrorCodeNone, ""
+}
+
+func manualAssignment(assignments [][]int32, existing int32, replicationFactor int, brokers []int32) ([]domain.NewPartition, int16, string) {
+	partitions := make([]domain.NewPartition, 0, len(assignments))
+	for i, brokerIDs := range assignments {
+		if len(brokerIDs) == 0 {
+			return nil, domain.ErrorCodeInvalidReplicaAssignment, "The manual partition assignment includes an empty replica list."
+		}
+		if len(brokerIDs) != replicationFactor {
+			return nil, domain.ErrorCodeInvalidReplicaAssignment, fmt.Sprintf("The manual partition assignment includes a partition with %d replica(s), but this is not consistent with previous partitions, which have %d replica(s).", len(brokerIDs), replicationFactor)
+		}
+		for j, brokerID := range brokerIDs {
+			if slices.Contains(brokerIDs[:j], brokerID) {
+				return nil, domain.ErrorCodeInvalidReplicaAssignment, fmt.Sprintf("The manual partition assignment includes the broker %d more than once.", brokerID)
+			}
+			if !slices.Contains(brokers, brokerID) {
+				return nil, domain.ErrorCodeInvalidReplicaAssignment, fmt.Sprintf("The manual partition assignment includes broker %d, but no such broker is registered.", brokerID)
+			}
+		}
+		partitions = append(partitions, domain.NewPartition{PartitionIndex: existing + int32(i), Replicas: slices.Clone(brokerIDs)})
+	}
+	return partitions, domain.ErrorCodeNone, ""
+}
+
+func topicError(name string, errorCode int16, message string) domain.CreatePartitionsTopicResult {
+	return domain.CreatePartitionsTopicResult{
+		Name:         name,
+		ErrorCode:    errorCode,
+		ErrorMessage: message,
+	}
+}
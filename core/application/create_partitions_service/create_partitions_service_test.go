@@ -0,0 +1,145 @@
+package create_partitions_service
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/cluster_metadata_repository"
+	partition_file_repository "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/partition_repository"
+)
+
+// mockParser hands the service a fixed request and captures the response
+type mockParser struct {
+	request  *domain.ParsedRequestCreatePartitions
+	response *domain.ResponseDataCreatePartitions
+}
+
+func (m *mockParser) ParseRequest(data []byte) (*domain.ParsedRequestCreatePartitions, error) {
+	return m.request, nil
+}
+
+func (m *mockParser) EncodeResponse(response *domain.ResponseDataCreatePartitions) ([]byte, error) {
+	m.response = response
+	return []byte{}, nil
+}
+
+func TestCreatePartitionsService_HandleRequest(t *testing.T) {
+	logDir := t.TempDir()
+	metadata := cluster_metadata_repository.NewClusterMetadataRepository(
+		cluster_metadata_repository.WithMetadataLogDir(filepath.Join(logDir, "__cluster_metadata-0")))
+	partitions := partition_file_repository.NewPartitionFileRepositoryWithLogDir(logDir)
+	brokerConfig := domain.BrokerConfig{Broker: domain.Broker{NodeID: 1, Host: "localhost", Port: 9092}}
+
+	fooTopicID := bytes.Repeat([]byte{0xaa}, 16)
+	for _, topic := range []domain.NewTopic{
+		{Name: "foo", TopicID: fooTopicID, Partitions: []domain.NewPartition{{PartitionIndex: 0, Replicas: []int32{1}}}},
+		{Name: "bar", TopicID: bytes.Repeat([]byte{0xbb}, 16), Partitions: []domain.NewPartition{{PartitionIndex: 0, Replicas: []int32{1}}, {PartitionIndex: 1, Replicas: []int32{1}}}},
+	} {
+		if err := metadata.CreateTopic(topic); err != nil {
+			t.Fatalf("CreateTopic(%s) error = %v", topic.Name, err)
+		}
+	}
+
+	handle := func(request *domain.ParsedRequestCreatePartitions) []domain.CreatePartitionsTopicResult {
+		t.Helper()
+		mockParser := &mockParser{request: request}
+		service := NewCreatePartitionsService(mockParser, metadata, metadata, partitions, brokerConfig)
+		if _, err := service.HandleRequest(domain.Request{}); err != nil {
+			t.Fatalf("HandleRequest() error = %v", err)
+		}
+		return mockParser.response.Results
+	}
+
+	results := handle(&domain.ParsedRequestCreatePartitions{APIVersion: 3, Topics: []domain.CreatePartitionsTopic{
+		{Name: "foo", Count: 3},
+		{Name: "bar", Count: 1},
+		{Name: "bar", Count: 3},
+		{Name: "missing", Count: 2},
+	}})
+	wantErrorCodes := []int16{
+		domain.ErrorCodeNone,
+		domain.ErrorCodeInvalidRequest,
+		domain.ErrorCodeInvalidRequest,
+		domain.ErrorCodeInvalidPartitions,
+	}
+	for i, result := range results {
+		if result.ErrorCode != wantErrorCodes[i] {
+			t.Errorf("topic %s error = %d (%s), want %d", result.Name, result.ErrorCode, result.ErrorMessage, wantErrorCodes[i])
+		}
+	}
+
+	results = handle(&domain.ParsedRequestCreatePartitions{APIVersion: 3, Topics: []domain.CreatePartitionsTopic{
+		{Name: "bar", Count: 1},
+		{Name: "foo", Count: 3},
+	}})
+	if results[0].ErrorCode != domain.ErrorCodeInvalidPartitions || results[1].ErrorCode != domain.ErrorCodeInvalidPartitions {
+		t.Errorf("shrinking bar and keeping foo's count = %+v, want INVALID_PARTITIONS for both", results)
+	}
+
+	results = handle(&domain.ParsedRequestCreatePartitions{APIVersion: 3, Topics: []domain.CreatePartitionsTopic{
+		{Name: "foo", Count: 5, Assignments: [][]int32{{1}}},
+		{Name: "bar", Count: 3, Assignments: [][]int32{{2}}},
+	}})
+	if results[0].ErrorCode != domain.ErrorCodeInvalidReplicaAssignment || results[1].ErrorCode != domain.ErrorCodeInvalidReplicaAssignment {
+		t.Errorf("bad assignments = %+v, want INVALID_REPLICA_ASSIGNMENT for both", results)
+	}
+
+	// validate_only writes nothing
+	results = handle(&domain.ParsedRequestCreatePartitions{APIVersion: 3, ValidateOnly: true, Topics: []domain.CreatePartitionsTopic{
+		{Name: "bar", Count: 3, Assignments: [][]int32{{1}}},
+	}})
+	if results[0].ErrorCode != domain.ErrorCodeNone {
+		t.Errorf("validate_only result = %+v", results[0])
+	}
+
+	image, err := metadata.GetClusterMetadata()
+	if err != nil {
+		t.Fatalf("GetClusterMetadata() error = %v", err)
+	}
+	if partitions := len(image.TopicUUIDPartitionMetadataMap[image.TopicNameTopicUuidMap["foo"]]); partitions != 3 {
+		t.Errorf("foo has %d partitions, want 3", partitions)
+	}
+	if image.FindPartition("bar", 2) != nil {
+		t.Errorf("validate_only added partition bar-2")
+	}
+	for _, partition := range []string{"foo-1", "foo-2"} {
+		if _, err := os.Stat(filepath.Join(logDir, partition, "partition.metadata")); err != nil {
+			t.Errorf("partition %s was not created on disk: %v", partition, err)
+		}
+	}
+}
+
+func TestCreatePartitionsService_MaxPartitions(t *testing.T) {
+	logDir := t.TempDir()
+	metadata := cluster_metadata_repository.NewClusterMetadataRepository(
+		cluster_metadata_repository.WithMetadataLogDir(filepath.Join(logDir, "__cluster_metadata-0")))
+	partitions := partition_file_repository.NewPartitionFileRepositoryWithLogDir(logDir)
+	brokerConfig := domain.BrokerConfig{Broker: domain.Broker{NodeID: 1, Host: "localhost", Port: 9092}}
+	if err := metadata.CreateTopic(domain.NewTopic{Name: "foo", TopicID: bytes.Repeat([]byte{0xaa}, 16), Partitions: []domain.NewPartition{{PartitionIndex: 0, Replicas: []int32{1}}}}); err != nil {
+		t.Fatalf("CreateTopic() error = %v", err)
+	}
+
+	handle := func(count int32, opts ...CreatePartitionsOption) domain.CreatePartitionsTopicResult {
+		t.Helper()
+		mockParser := &mockParser{request: &domain.ParsedRequestCreatePartitions{APIVersion: 3, ValidateOnly: true, Topics: []domain.CreatePartitionsTopic{{Name: "foo", Count: count}}}}
+		service := NewCreatePartitionsService(mockParser, metadata, metadata, partitions, brokerConfig, opts...)
+		if _, err := service.HandleRequest(domain.Request{}); err != nil {
+			t.Fatalf("HandleRequest() error = %v", err)
+		}
+		return mockParser.response.Results[0]
+	}
+
+	if result := handle(math.MaxInt32); result.ErrorCode != domain.ErrorCodeInvalidPartitions {
+		t.Errorf("growing to %d partitions = %+v, want INVALID_PARTITIONS", int32(math.MaxInt32), result)
+	}
+	if result := handle(3, WithMaxPartitions(3)); result.ErrorCode != domain.ErrorCodeNone {
+		t.Errorf("growing to the configured maximum = %+v, want no error", result)
+	}
+	if result := handle(4, WithMaxPartitions(3)); result.ErrorCode != domain.ErrorCodeInvalidPartitions {
+		t.Errorf("growing past the configured maximum = %+v, want INVALID_PARTITIONS", result)
+	}
+}
@@ -0,0 +1,314 @@
+package create_topics_service
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+	port_repo "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/partition_file_repository"
+)
+
+// maxTopicNameLength leaves room for the partition suffix of a 255 byte directory name
+const maxTopicNameLength = 249
+
+var zeroTopicId = make([]byte, 16)
+
+// CreateTopicsService implements the driving port for CreateTopics requests, which admin
+// clients use to add topics to the cluster metadata log.
+type CreateTopicsService struct {
+	parser                    parser.CreateTopicsParser
+	metadata_repository       port_cluster_metadata_repository.ClusterMetadataRepository
+	metadata_writer           port_cluster_metadata_repository.ClusterMetadataWriter
+	partition_file_repository port_repo.PartitionFileRepository
+	brokerConfig              domain.BrokerConfig
+	maxPartitions             int32
+}
+
+type CreateTopicsOption func(*CreateTopicsService)
+
+// WithMaxPartitions changes the most partitions a topic may be created with from
+// domain.DefaultMaxPartitions
+func WithMaxPartitions(maxPartitions int32) CreateTopicsOption {
+	return func(s *CreateTopicsService) {
+		s.maxPartitions = maxPartitions
+	}
+}
+
+func NewCreateTopicsService(parser parser.CreateTopicsParser, metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository, metadata_writer port_cluster_metadata_repository.ClusterMetadataWriter, partition_file_repository port_repo.PartitionFileRepository, brokerConfig domain.BrokerConfig, opts ...CreateTopicsOption) driving.KafkaHandler {
+	s := &CreateTopicsService{
+		parser:                    parser,
+		metadata_repository:       metadata_repository,
+		metadata_writer:           metadata_writer,
+		partition_file_repository: partition_file_repository,
+		brokerConfig:              brokerConfig,
+		maxPartitions:             domain.DefaultMaxPartitions,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *CreateTopicsService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	clusterMetaData, err := s.metadata_repository.GetClusterMetadata()
+	if err != nil {
+		fmt.Println("CreateTopics: there is no cluster metadata", err.Error())
+	}
+
+	requested := make(map[string]int, len(parsedReq.Topics))
+	for _, topic := range parsedReq.Topics {
+		requested[topic.Name]++
+	}
+
+	responseData := &domain.ResponseDataCreateTopics{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		Topics:         make([]domain.CreatableTopicResult, 0, len(parsedReq.Topics)),
+	}
+	for _, topic := range parsedReq.Topics {
+		if requested[topic.Name] > 1 {
+			responseData.Topics = append(responseData.Topics, topicError(topic.Name, domain.ErrorCodeInvalidRequest, "Duplicate topic name."))
+			continue
+		}
+		responseData.Topics = append(responseData.Topics, s.createTopic(clusterMetaData, topic, parsedReq.ValidateOnly))
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
+
+// createTopic validates a topic, places its replicas and, unless validateOnly is set,
+// writes it to the metadata log
+func (s *CreateTopicsService) createTopic(clusterMetaData port_cluster_metadata_repository.ClusterMetadataRepositoryResponse, topic domain.CreatableTopic, validateOnly bool) domain.CreatableTopicResult {
+	if message := validateTopicName(topic.Name); message != "" {
+		return topicError(topic.Name, domain.ErrorCodeInvalidTopicException, message)
+	}
+	if _, exists := clusterMetaData.TopicNameTopicUuidMap[topic.Name]; exists {
+		return topicError(topic.Name, domain.ErrorCodeTopicAlreadyExists, fmt.Sprintf("Topic '%s' already exists.", topic.Name))
+	}
+	if collision := findCollidingTopic(clusterMetaData, topic.Name); collision != "" {
+		return topicError(topic.Name, domain.ErrorCodeInvalidTopicException, fmt.Sprintf("Topic '%s' collides with existing topic: %s", topic.Name, collision))
+	}
+
+	configs := make(map[string]string, len(topic.Configs))
+	for _, config := range topic.Configs {
+		if config.IsNull {
+			return topicError(topic.Name, domain.ErrorCodeInvalidConfig, "Null value not supported for topic configs: "+config.Name)
+		}
+		configs[config.Name] = config.Value
+	}
+
+	partitions, errorCode, message := assignReplicas(topic, s.usableBrokers(clusterMetaData), s.maxPartitions)
+	if errorCode != domain.ErrorCodeNone {
+		return topicError(topic.Name, errorCode, message)
+	}
+
+	result := domain.CreatableTopicResult{
+		Name:              topic.Name,
+		TopicID:           zeroTopicId,
+		ErrorCode:         domain.ErrorCodeNone,
+		NumPartitions:     int32(len(partitions)),
+		ReplicationFactor: int16(len(partitions[0].Replicas)),
+	}
+	for _, name := range slices.Sorted(maps.Keys(configs)) {
+		result.Configs = append(result.Configs, domain.CreatableTopicConfigResult{
+			Name:         name,
+			Value:        configs[name],
+			ConfigSource: domain.ConfigSourceDynamicTopicConfig,
+		})
+	}
+	if validateOnly {
+		return result
+	}
+
+	topicID := newTopicId()
+	err := s.metadata_writer.CreateTopic(domain.NewTopic{Name: topic.Name, TopicID: topicID, Partitions: partitions, Configs: configs})
+	if errors.Is(err, domain.ErrTopicAlreadyExists) {
+		return topicError(topic.Name, domain.ErrorCodeTopicAlreadyExists, fmt.Sprintf("Topic '%s' already exists.", topic.Name))
+	}
+	if err != nil {
+		fmt.Printf("CreateTopics: writing topic %s failed: %v\n", topic.Name, err)
+		return topicError(topic.Name, domain.ErrorCodeUnknownServerError, err.Error())
+	}
+
+	for _, partition := range partitions {
+		if !slices.Contains(partition.Replicas, s.brokerConfig.NodeID) {
+			continue
+		}
+		if err := s.partition_file_repository.CreatePartition(topic.Name, int(partition.PartitionIndex), topicID); err != nil {
+			fmt.Printf("CreateTopics: creating partition %s-%d failed: %v\n", topic.Name, partition.PartitionIndex, err)
+		}
+	}
+	result.TopicID = topicID
+	return result
+}
+
+// usableBrokers returns the unfenced brokers new replicas can be placed on, in ID order.
+// A log without broker registrations is served by this broker alone.
+func (s *CreateTopicsService) usableBrokers(clusterMetaData port_cluster_metadata_repository.ClusterMetadataRepositoryResponse) []int32 {
+	if len(clusterMetaData.Brokers) == 0 {
+		return []int32{s.brokerConfig.NodeID}
+	}
+	brokers := []int32{}
+	for brokerID, registration := range clusterMetaData.Brokers {
+		if !registration.Fenced {
+			brokers = append(brokers, brokerID)
+		}
+	}
+	slices.Sort(brokers)
+	return brokers
+}
+
+// assignReplicas returns the partitions of the new topic, taken from the manual
+// assignments or spread round-robin over the brokers. Topics with more than
+// maxPartitions partitions are refused before anything is allocated for them.
+func assignReplicas(topic domain.CreatableTopic, brokers []int32, maxPartitions int32) ([]domain.NewPartition, int16, string) {
+	if len(topic.Assignments) > 0 {
+		if topic.NumPartitions != -1 || topic.ReplicationFactor != -1 {
+			return nil, domain.ErrorCodeInvalidRequest, "Both numPartitions or replicationFactor and replicasAssignments were set. Both cannot be used at the same time."
+		}
+		if len(topic.Assignments) > int(maxPartitions) {
+			return nil, domain.ErrorCodeInvalidPartitions, tooManyPartitions(int32(len(topic.Assignments)), maxPartitions)
+		}
+		return manualAssignment(topic.Assignments, brokers)
+	}
+
+	numPartitions := topic.NumPartitions
+	if numPartitions == -1 {
+		numPartitions = domain.DefaultNumPartitions
+	}
+	if numPartitions <= 0 {
+		return nil, domain.ErrorCodeInvalidPartitions, "Number of partitions was set to an invalid non-positive value."
+	}
+	if numPartitions > maxPartitions {
+		return nil, domain.ErrorCodeInvalidPartitions, tooManyPartitions(numPartitions, maxPartitions)
+	}
+	replicationFactor := topic.ReplicationFactor
+	if replicationFactor == -1 {
+		replicationFactor = domain.DefaultReplicationFactor
+	}
+	if replicationFactor <= 0 {
+		return nil, domain.ErrorCodeInvalidReplicationFactor, "Replication factor must be larger than 0, or -1 to use the default value."
+	}
+	if int(replicationFactor) > len(brokers) {
+		return nil, domain.ErrorCodeInvalidReplicationFactor, fmt.Sprintf("Unable to replicate the partition %d time(s): The target replication factor of %d cannot be reached because only %d broker(s) are registered.", replicationFactor, replicationFactor, len(brokers))
+	}
+
+	partitions := make([]domain.NewPartition, 0, numPartitions)
+	for partitionIndex := range numPartitions {
+		replicas := make([]int32, 0, replicationFactor)
+		for replica := range int(replicationFactor) {
+			replicas = append(replicas, brokers[(int(partitionIndex)+replica)%len(brokers)])
+		}
+		partitions = append(partitions, domain.NewPartition{PartitionIndex: partitionIndex, Replicas: replicas})
+	}
+	return partitions, domain.ErrorCodeNone, ""
+}
+
+func tooManyPartitions(numPartitions int32, maxPartitions int32) string {
+	return fmt.Sprintf("Number of partitions %d exceeds the maximum of %d partitions per topic.", numPartitions, maxPartitions)
+}
+
+func manualAssignment(assignments []domain.CreatableReplicaAssignment, brokers []int32) ([]domain.NewPartition, int16, string) {
+	assignments = slices.Clone(assignments)
+	slices.SortFunc(assignments, func(a, b domain.CreatableReplicaAssignment) int {
+		return int(a.PartitionIndex) - int(b.PartitionIndex)
+	})
+
+	partitions := make([]domain.NewPartition, 0, len(assignments))
+	for i, assignment := range assignments {
+		if assignment.PartitionIndex != int32(i) {
+			return nil, domain.ErrorCodeInvalidReplicaAssignment, "Partitions should be a consecutive 0-based integer sequence."
+		}
+		if len(assignment.BrokerIDs) == 0 {
+			return nil, domain.ErrorCodeInvalidReplicaAssignment, "The manual partition assignment includes an empty replica list."
+		}
+		for j, brokerID := range assignment.BrokerIDs {
+			if slices.Contains(assignment.BrokerIDs[:j], brokerID) {
+				return nil, domain.ErrorCodeInvalidReplicaAssignment, fmt.Sprintf("The manual partition assignment includes the broker %d more than once.", brokerID)
+			}
+			if !slices.Contains(brokers, brokerID) {
+				return nil, domain.ErrorCodeInvalidReplicaAssignment, fmt.Sprintf("The manual partition assignment includes broker %d, but no such broker is registered.", brokerID)
+			}
+		}
+		partitions = append(partitions, domain.NewPartition{PartitionIndex: assignment.PartitionIndex, Replicas: slices.Clone(assignment.BrokerIDs)})
+	}
+	return partitions, domain.ErrorCodeNone, ""
+}
+
+// validateTopicName applies Kafka's topic naming rules and returns why a name is
+// illegal, or "" when it is fine
+func validateTopicName(name string) string {
+	switch {
+	case name == "":
+		return "Topic name is illegal, it can't be empty"
+	case name == "." || name == "..":
+		return "Topic name cannot be \".\" or \"..\""
+	case len(name) > maxTopicNameLength:
+		return fmt.Sprintf("Topic name is illegal, it can't be longer than %d characters, topic name: %s", maxTopicNameLength, name)
+	}
+	for _, c := range name {
+		legal := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '_' || c == '-'
+		if !legal {
+			return fmt.Sprintf("Topic name \"%s\" is illegal, it contains a character other than ASCII alphanumerics, '.', '_' and '-'", name)
+		}
+	}
+	return ""
+}
+
+// findCollidingTopic returns an existing topic whose name only differs from name in
+// '.' versus '_', which metric names can't tell apart
+func findCollidingTopic(clusterMetaData port_cluster_metadata_repository.ClusterMetadataRepositoryResponse, name string) string {
+	if !strings.ContainsAny(name, "._") {
+		return ""
+	}
+	unified := strings.ReplaceAll(name, ".", "_")
+	for existing := range clusterMetaData.TopicNameTopicUuidMap {
+		if existing != name && strings.ReplaceAll(existing, ".", "_") == unified {
+			return existing
+		}
+	}
+	return ""
+}
+
+// newTopicId returns a random UUID that is neither zero nor starts with '-' once base64
+// encoded, which Kafka reserves
+func newTopicId() []byte {
+	topicId := make([]byte, 16)
+	for {
+		rand.Read(topicId)
+		if topicId[0]>>2 != 62 && !slices.Equal(topicId, zeroTopicId) {
+			return topicId
+		}
+	}
+}
+
+func topicError(name string, errorCode int16, message string) domain.CreatableTopicResult {
+	return domain.CreatableTopicResult{
+		Name:              name,
+		TopicID:           zeroTopicId,
+		ErrorCode:         errorCode,
+		ErrorMessage:      message,
+		NumPartitions:     -1,
+		ReplicationFactor: -1,
+	}
+}
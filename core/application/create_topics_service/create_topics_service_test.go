@@ -0,0 +1,141 @@
+package create_topics_service
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/cluster_metadata_repository"
+	partition_file_repository "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/partition_repository"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// mockParser hands the service a fixed request and captures the response
+type mockParser struct {
+	request  *domain.ParsedRequestCreateTopics
+	response *domain.ResponseDataCreateTopics
+}
+
+func (m *mockParser) ParseRequest(data []byte) (*domain.ParsedRequestCreateTopics, error) {
+	return m.request, nil
+}
+
+func (m *mockParser) EncodeResponse(response *domain.ResponseDataCreateTopics) ([]byte, error) {
+	m.response = response
+	return []byte{}, nil
+}
+
+func TestCreateTopicsService_HandleRequest(t *testing.T) {
+	logDir := t.TempDir()
+	metadata := cluster_metadata_repository.NewClusterMetadataRepository(
+		cluster_metadata_repository.WithMetadataLogDir(filepath.Join(logDir, "__cluster_metadata-0")))
+	brokerConfig := domain.BrokerConfig{Broker: domain.Broker{NodeID: 1, Host: "localhost", Port: 9092}}
+
+	handle := func(request *domain.ParsedRequestCreateTopics) []domain.CreatableTopicResult {
+		t.Helper()
+		mockParser := &mockParser{request: request}
+		service := NewCreateTopicsService(mockParser, metadata, metadata, partition_file_repository.NewPartitionFileRepositoryWithLogDir(logDir), brokerConfig)
+		if _, err := service.HandleRequest(domain.Request{}); err != nil {
+			t.Fatalf("HandleRequest() error = %v", err)
+		}
+		return mockParser.response.Topics
+	}
+
+	results := handle(&domain.ParsedRequestCreateTopics{APIVersion: 7, Topics: []domain.CreatableTopic{
+		{Name: "foo", NumPartitions: 2, ReplicationFactor: -1, Configs: []domain.CreatableTopicConfig{{Name: "retention.ms", Value: "1000"}}},
+		{Name: "bar", NumPartitions: -1, ReplicationFactor: -1, Assignments: []domain.CreatableReplicaAssignment{{PartitionIndex: 0, BrokerIDs: []int32{1}}}},
+		{Name: "validated", NumPartitions: 1, ReplicationFactor: 1},
+		{Name: "validated", NumPartitions: 1, ReplicationFactor: 1},
+		{Name: "bad/name", NumPartitions: 1, ReplicationFactor: 1},
+		{Name: "few", NumPartitions: 0, ReplicationFactor: 1},
+		{Name: "many", NumPartitions: 1, ReplicationFactor: 3},
+		{Name: "gap", NumPartitions: -1, ReplicationFactor: -1, Assignments: []domain.CreatableReplicaAssignment{{PartitionIndex: 1, BrokerIDs: []int32{1}}}},
+		{Name: "nowhere", NumPartitions: -1, ReplicationFactor: -1, Assignments: []domain.CreatableReplicaAssignment{{PartitionIndex: 0, BrokerIDs: []int32{2}}}},
+		{Name: "nulls", NumPartitions: 1, ReplicationFactor: 1, Configs: []domain.CreatableTopicConfig{{Name: "retention.ms", IsNull: true}}},
+		{Name: "huge", NumPartitions: math.MaxInt32, ReplicationFactor: 1},
+	}})
+	wantErrorCodes := []int16{
+		domain.ErrorCodeNone,
+		domain.ErrorCodeNone,
+		domain.ErrorCodeInvalidRequest,
+		domain.ErrorCodeInvalidRequest,
+		domain.ErrorCodeInvalidTopicException,
+		domain.ErrorCodeInvalidPartitions,
+		domain.ErrorCodeInvalidReplicationFactor,
+		domain.ErrorCodeInvalidReplicaAssignment,
+		domain.ErrorCodeInvalidReplicaAssignment,
+		domain.ErrorCodeInvalidConfig,
+		domain.ErrorCodeInvalidPartitions,
+	}
+	for i, result := range results {
+		if result.ErrorCode != wantErrorCodes[i] {
+			t.Errorf("topic %s error = %d (%s), want %d", result.Name, result.ErrorCode, result.ErrorMessage, wantErrorCodes[i])
+		}
+	}
+	if foo := results[0]; foo.NumPartitions != 2 || foo.ReplicationFactor != 1 || len(foo.Configs) != 1 || slices.Equal(foo.TopicID, zeroTopicId) {
+		t.Errorf("foo result = %+v, want 2 partitions, replication factor 1, its config and a topic ID", foo)
+	}
+
+	image, err := metadata.GetClusterMetadata()
+	if err != nil {
+		t.Fatalf("GetClusterMetadata() error = %v", err)
+	}
+	for _, name := range []string{"foo", "bar"} {
+		if image.FindPartition(name, 0) == nil {
+			t.Errorf("partition %s-0 is not in the metadata", name)
+		}
+	}
+	if partition := image.FindPartition("foo", 1); partition == nil || common.BytesToInt(partition.LeaderId) != 1 {
+		t.Errorf("partition foo-1 = %+v, want it led by broker 1", partition)
+	}
+	metadataFile, err := os.ReadFile(filepath.Join(logDir, "foo-1", "partition.metadata"))
+	if err != nil || !strings.Contains(string(metadataFile), "topic_id: ") {
+		t.Errorf("partition.metadata of foo-1 = %q, %v", metadataFile, err)
+	}
+
+	// Existing and colliding names are rejected; validate_only writes nothing
+	results = handle(&domain.ParsedRequestCreateTopics{APIVersion: 7, ValidateOnly: true, Topics: []domain.CreatableTopic{
+		{Name: "foo", NumPartitions: 1, ReplicationFactor: 1},
+		{Name: "a.b", NumPartitions: 1, ReplicationFactor: 1},
+	}})
+	if results[0].ErrorCode != domain.ErrorCodeTopicAlreadyExists || results[1].ErrorCode != domain.ErrorCodeNone || !slices.Equal(results[1].TopicID, zeroTopicId) {
+		t.Errorf("validate_only results = %+v", results)
+	}
+	if image, _ := metadata.GetClusterMetadata(); image.TopicNameTopicUuidMap["a.b"] != "" {
+		t.Errorf("validate_only created topic a.b")
+	}
+	handle(&domain.ParsedRequestCreateTopics{APIVersion: 7, Topics: []domain.CreatableTopic{{Name: "a.b", NumPartitions: 1, ReplicationFactor: 1}}})
+	results = handle(&domain.ParsedRequestCreateTopics{APIVersion: 7, Topics: []domain.CreatableTopic{{Name: "a_b", NumPartitions: 1, ReplicationFactor: 1}}})
+	if results[0].ErrorCode != domain.ErrorCodeInvalidTopicException {
+		t.Errorf("a_b error = %d, want INVALID_TOPIC_EXCEPTION for colliding with a.b", results[0].ErrorCode)
+	}
+}
+
+func TestCreateTopicsService_MaxPartitions(t *testing.T) {
+	logDir := t.TempDir()
+	metadata := cluster_metadata_repository.NewClusterMetadataRepository(
+		cluster_metadata_repository.WithMetadataLogDir(filepath.Join(logDir, "__cluster_metadata-0")))
+	brokerConfig := domain.BrokerConfig{Broker: domain.Broker{NodeID: 1, Host: "localhost", Port: 9092}}
+	assignments := []domain.CreatableReplicaAssignment{{PartitionIndex: 0, BrokerIDs: []int32{1}}, {PartitionIndex: 1, BrokerIDs: []int32{1}}, {PartitionIndex: 2, BrokerIDs: []int32{1}}}
+
+	mockParser := &mockParser{request: &domain.ParsedRequestCreateTopics{APIVersion: 7, Topics: []domain.CreatableTopic{
+		{Name: "at-limit", NumPartitions: 2, ReplicationFactor: 1},
+		{Name: "over-limit", NumPartitions: 3, ReplicationFactor: 1},
+		{Name: "assigned-over-limit", NumPartitions: -1, ReplicationFactor: -1, Assignments: assignments},
+	}}}
+	service := NewCreateTopicsService(mockParser, metadata, metadata, partition_file_repository.NewPartitionFileRepositoryWithLogDir(logDir), brokerConfig, WithMaxPartitions(2))
+	if _, err := service.HandleRequest(domain.Request{}); err != nil {
+		t.Fatalf("HandleRequest() error = %v", err)
+	}
+
+	wantErrorCodes := []int16{domain.ErrorCodeNone, domain.ErrorCodeInvalidPartitions, domain.ErrorCodeInvalidPartitions}
+	for i, result := range mockParser.response.Topics {
+		if result.ErrorCode != wantErrorCodes[i] {
+			t.Errorf("topic %s error = %d (%s), want %d", result.Name, result.ErrorCode, result.ErrorMessage, wantErrorCodes[i])
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package delete_topics_service
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+	port_repo "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/partition_file_repository"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+var zeroTopicId = make([]byte, 16)
+
+// DeleteTopicsService implements the driving port for DeleteTopics requests. A deleted
+// topic disappears from the metadata right away; its partition directories are removed
+// in the background.
+type DeleteTopicsService struct {
+	parser                    parser.DeleteTopicsParser
+	metadata_repository       port_cluster_metadata_repository.ClusterMetadataRepository
+	metadata_writer           port_cluster_metadata_repository.ClusterMetadataWriter
+	partition_file_repository port_repo.PartitionFileRepository
+}
+
+func NewDeleteTopicsService(parser parser.DeleteTopicsParser, metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository, metadata_writer port_cluster_metadata_repository.ClusterMetadataWriter, partition_file_repository port_repo.PartitionFileRepository) driving.KafkaHandler {
+	return &DeleteTopicsService{
+		parser:                    parser,
+		metadata_repository:       metadata_repository,
+		metadata_writer:           metadata_writer,
+		partition_file_repository: partition_file_repository,
+	}
+}
+
+func (s *DeleteTopicsService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	clusterMetaData, err := s.metadata_repository.GetClusterMetadata()
+	if err != nil {
+		fmt.Println("DeleteTopics: there is no cluster metadata", err.Error())
+	}
+
+	requested := make(map[string]int, len(parsedReq.Topics))
+	for _, topic := range parsedReq.Topics {
+		requested[requestKey(topic)]++
+	}
+
+	responseData := &domain.ResponseDataDeleteTopics{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		Responses:      make([]domain.DeletableTopicResult, 0, len(parsedReq.Topics)),
+	}
+	for _, topic := range parsedReq.Topics {
+		var result domain.DeletableTopicResult
+		switch {
+		case topic.Name != "" && byTopicId(topic):
+			result = topicError(topic, domain.ErrorCodeInvalidRequest, "You may not specify both topic name and topic id.")
+		case requested[requestKey(topic)] > 1 && byTopicId(topic):
+			result = topicError(topic, domain.ErrorCodeInvalidRequest, "Duplicate topic id.")
+		case requested[requestKey(topic)] > 1:
+			result = topicError(topic, domain.ErrorCodeInvalidRequest, "Duplicate topic name.")
+		default:
+			result = s.deleteTopic(clusterMetaData, topic)
+		}
+		responseData.Responses = append(responseData.Responses, result)
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
+
+// deleteTopic resolves the topic, removes it from the metadata log and hands its
+// partition directories to the background deleter
+func (s *DeleteTopicsService) deleteTopic(clusterMetaData port_cluster_metadata_repository.ClusterMetadataRepositoryResponse, topic domain.DeleteTopicState) domain.DeletableTopicResult {
+	topicUuid, exists := "", false
+	if byTopicId(topic) {
+		topicUuid = hex.EncodeToString(topic.TopicID)
+		_, exists = clusterMetaData.TopicUUIDTopicMetadataInfoMap[topicUuid]
+	} else {
+		topicUuid, exists = clusterMetaData.TopicNameTopicUuidMap[topic.Name]
+	}
+	if !exists {
+		return unknownTopic(topic)
+	}
+
+	topicMetadata := clusterMetaData.TopicUUIDTopicMetadataInfoMap[topicUuid]
+	result := domain.DeletableTopicResult{
+		Name:      topicMetadata.TopicNameInfo.TopicName,
+		TopicID:   topicMetadata.TopicId,
+		ErrorCode: domain.ErrorCodeNone,
+	}
+
+	err := s.metadata_writer.DeleteTopic(topicMetadata.TopicId)
+	if errors.Is(err, domain.ErrUnknownTopicID) {
+		return unknownTopic(topic)
+	}
+	if err != nil {
+		fmt.Printf("DeleteTopics: removing topic %s failed: %v\n", result.Name, err)
+		result.ErrorCode = domain.ErrorCodeUnknownServerError
+		result.ErrorMessage = err.Error()
+		return result
+	}
+
+	for _, partition := range clusterMetaData.TopicUUIDPartitionMetadataMap[topicUuid] {
+		partitionIndex := common.BytesToInt(partition.PartitionIndex)
+		if err := s.partition_file_repository.DeletePartition(result.Name, partitionIndex); err != nil {
+			fmt.Printf("DeleteTopics: deleting partition %s-%d failed: %v\n", result.Name, partitionIndex, err)
+		}
+	}
+	return result
+}
+
+// byTopicId reports whether a v6+ request names the topic by its ID
+func byTopicId(topic domain.DeleteTopicState) bool {
+	return len(topic.TopicID) == 16 && !bytes.Equal(topic.TopicID, zeroTopicId)
+}
+
+// requestKey identifies the topic an entry refers to, to find duplicates
+func requestKey(topic domain.DeleteTopicState) string {
+	if byTopicId(topic) {
+		return "id:" + hex.EncodeToString(topic.TopicID)
+	}
+	return "name:" + topic.Name
+}
+
+func unknownTopic(topic domain.DeleteTopicState) domain.DeletableTopicResult {
+	if byTopicId(topic) {
+		return topicError(topic, domain.ErrorCodeUnknownTopicID, "This server does not host this topic ID.")
+	}
+	return topicError(topic, domain.ErrorCodeUnknownTopicOrPartition, "This server does not host this topic-partition.")
+}
+
+func topicError(topic domain.DeleteTopicState, errorCode int16, message string) domain.DeletableTopicResult {
+	return domain.DeletableTopicResult{
+		Name:         topic.Name,
+		TopicID:      topic.TopicID,
+		ErrorCode:    errorCode,
+		ErrorMessage: message,
+	}
+}
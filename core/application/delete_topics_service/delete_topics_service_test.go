@@ -0,0 +1,99 @@
+package delete_topics_service
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/cluster_metadata_repository"
+	partition_file_repository "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/partition_repository"
+)
+
+// mockParser hands the service a fixed request and captures the response
+type mockParser struct {
+	request  *domain.ParsedRequestDeleteTopics
+	response *domain.ResponseDataDeleteTopics
+}
+
+func (m *mockParser) ParseRequest(data []byte) (*domain.ParsedRequestDeleteTopics, error) {
+	return m.request, nil
+}
+
+func (m *mockParser) EncodeResponse(response *domain.ResponseDataDeleteTopics) ([]byte, error) {
+	m.response = response
+	return []byte{}, nil
+}
+
+var (
+	fooTopicId = bytes.Repeat([]byte{0xaa}, 16)
+	barTopicId = bytes.Repeat([]byte{0xbb}, 16)
+)
+
+func TestDeleteTopicsService_HandleRequest(t *testing.T) {
+	logDir := t.TempDir()
+	metadata := cluster_metadata_repository.NewClusterMetadataRepository(
+		cluster_metadata_repository.WithMetadataLogDir(filepath.Join(logDir, "__cluster_metadata-0")))
+	partitions := partition_file_repository.NewPartitionFileRepositoryWithLogDir(logDir)
+	for name, topicId := range map[string][]byte{"foo": fooTopicId, "bar": barTopicId} {
+		if err := metadata.CreateTopic(domain.NewTopic{Name: name, TopicID: topicId, Partitions: []domain.NewPartition{{Replicas: []int32{1}}}}); err != nil {
+			t.Fatalf("CreateTopic(%s) error = %v", name, err)
+		}
+		if err := partitions.CreatePartition(name, 0, topicId); err != nil {
+			t.Fatalf("CreatePartition(%s) error = %v", name, err)
+		}
+	}
+
+	mockParser := &mockParser{request: &domain.ParsedRequestDeleteTopics{APIVersion: 6, Topics: []domain.DeleteTopicState{
+		{Name: "foo", TopicID: zeroTopicId},
+		{TopicID: barTopicId},
+		{Name: "missing", TopicID: zeroTopicId},
+		{TopicID: bytes.Repeat([]byte{0xcc}, 16)},
+		{Name: "foo", TopicID: fooTopicId},
+		{Name: "dup", TopicID: zeroTopicId},
+		{Name: "dup", TopicID: zeroTopicId},
+	}}}
+	service := NewDeleteTopicsService(mockParser, metadata, metadata, partitions)
+	if _, err := service.HandleRequest(domain.Request{}); err != nil {
+		t.Fatalf("HandleRequest() error = %v", err)
+	}
+
+	want := []struct {
+		name      string
+		errorCode int16
+	}{
+		{"foo", domain.ErrorCodeNone},
+		{"bar", domain.ErrorCodeNone},
+		{"missing", domain.ErrorCodeUnknownTopicOrPartition},
+		{"", domain.ErrorCodeUnknownTopicID},
+		{"foo", domain.ErrorCodeInvalidRequest},
+		{"dup", domain.ErrorCodeInvalidRequest},
+		{"dup", domain.ErrorCodeInvalidRequest},
+	}
+	for i, result := range mockParser.response.Responses {
+		if result.Name != want[i].name || result.ErrorCode != want[i].errorCode {
+			t.Errorf("response %d = %q with error %d (%s), want %q with error %d", i, result.Name, result.ErrorCode, result.ErrorMessage, want[i].name, want[i].errorCode)
+		}
+	}
+	if !bytes.Equal(mockParser.response.Responses[0].TopicID, fooTopicId) {
+		t.Errorf("foo TopicID = %x, want %x", mockParser.response.Responses[0].TopicID, fooTopicId)
+	}
+
+	image, err := metadata.GetClusterMetadata()
+	if err != nil {
+		t.Fatalf("GetClusterMetadata() error = %v", err)
+	}
+	for _, name := range []string{"foo", "bar"} {
+		if image.FindPartition(name, 0) != nil {
+			t.Errorf("deleted topic %s is still in the metadata", name)
+		}
+		if _, err := os.Stat(filepath.Join(logDir, name+"-0")); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("directory %s-0 was not renamed for deletion: %v", name, err)
+		}
+	}
+	if renamed, _ := filepath.Glob(filepath.Join(logDir, "*-delete")); len(renamed) != 2 {
+		t.Errorf("directories renamed for deletion = %v, want foo-0 and bar-0", renamed)
+	}
+}
@@ -0,0 +1,89 @@
+package describe_producers_service
+
+import (
+	"fmt"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+	port_repo "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/partition_file_repository"
+)
+
+// DescribeProducersService implements the driving port for DescribeProducers requests,
+// which show the producers a partition knows of and the transactions they keep open
+// there, the first place to look when a transaction hangs.
+type DescribeProducersService struct {
+	parser                    parser.DescribeProducersParser
+	metadata_repository       port_cluster_metadata_repository.ClusterMetadataRepository
+	partition_file_repository port_repo.PartitionFileRepository
+}
+
+func NewDescribeProducersService(parser parser.DescribeProducersParser, metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository, partition_file_repository port_repo.PartitionFileRepository) driving.KafkaHandler {
+	return &DescribeProducersService{
+		parser:                    parser,
+		metadata_repository:       metadata_repository,
+		partition_file_repository: partition_file_repository,
+	}
+}
+
+func (s *DescribeProducersService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	clusterMetaData, err := s.metadata_repository.GetClusterMetadata()
+	if err != nil {
+		fmt.Println("DescribeProducers: there is no cluster metadata", err.Error())
+	}
+
+	responseData := &domain.ResponseDataDescribeProducers{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		Topics:         make([]domain.DescribeProducersTopicResponse, 0, len(parsedReq.Topics)),
+	}
+	for _, topic := range parsedReq.Topics {
+		responseTopic := domain.DescribeProducersTopicResponse{
+			Name:       topic.Name,
+			Partitions: make([]domain.DescribeProducersPartitionResponse, 0, len(topic.PartitionIndexes)),
+		}
+		for _, partitionIndex := range topic.PartitionIndexes {
+			responseTopic.Partitions = append(responseTopic.Partitions, s.describePartition(clusterMetaData, topic.Name, partitionIndex))
+		}
+		responseData.Topics = append(responseData.Topics, responseTopic)
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
+
+func (s *DescribeProducersService) describePartition(clusterMetaData port_cluster_metadata_repository.ClusterMetadataRepositoryResponse, topicName string, partitionIndex int32) domain.DescribeProducersPartitionResponse {
+	result := domain.DescribeProducersPartitionResponse{
+		PartitionIndex:  partitionIndex,
+		ErrorCode:       domain.ErrorCodeNone,
+		ActiveProducers: []domain.ProducerState{},
+	}
+	if clusterMetaData.FindPartition(topicName, partitionIndex) == nil {
+		result.ErrorCode = domain.ErrorCodeUnknownTopicOrPartition
+		return result
+	}
+
+	producers, err := s.partition_file_repository.DescribeProducers(topicName, int(partitionIndex))
+	if err != nil {
+		fmt.Printf("DescribeProducers for %s-%d failed: %v\n", topicName, partitionIndex, err)
+		message := err.Error()
+		result.ErrorCode = domain.ErrorCodeKafkaStorageError
+		result.ErrorMessage = &message
+		return result
+	}
+	result.ActiveProducers = producers
+	return result
+}
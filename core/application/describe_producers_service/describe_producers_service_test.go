@@ -0,0 +1,80 @@
+package describe_producers_service
+
+import (
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+	partition_file_repository "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/partition_repository"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// mockParser hands the service a fixed request and captures the response
+type mockParser struct {
+	request  *domain.ParsedRequestDescribeProducers
+	response *domain.ResponseDataDescribeProducers
+}
+
+func (m *mockParser) ParseRequest(data []byte) (*domain.ParsedRequestDescribeProducers, error) {
+	return m.request, nil
+}
+
+func (m *mockParser) EncodeResponse(response *domain.ResponseDataDescribeProducers) ([]byte, error) {
+	m.response = response
+	return []byte{}, nil
+}
+
+// mockMetadataRepository serves topic "foo" with partition 0
+type mockMetadataRepository struct{}
+
+func (m *mockMetadataRepository) GetClusterMetadata() (port_cluster_metadata_repository.ClusterMetadataRepositoryResponse, error) {
+	return port_cluster_metadata_repository.ClusterMetadataRepositoryResponse{
+		TopicNameTopicUuidMap: map[string]string{"foo": "01"},
+		TopicUUIDPartitionMetadataMap: map[string][]*domain.PartitionMetadata{
+			"01": {{PartitionIndex: common.IntToFourBytes(0), LeaderEpoch: common.IntToFourBytes(0)}},
+		},
+	}, nil
+}
+
+func TestDescribeProducersService_HandleRequest(t *testing.T) {
+	repo := partition_file_repository.NewPartitionFileRepositoryWithLogDir(t.TempDir())
+	transactional := common.EncodeRecordBatch(common.RecordBatch{
+		RecordBatchHeader: common.RecordBatchHeader{
+			Attributes:      common.TransactionalAttributeFlag,
+			LastOffsetDelta: 1,
+			MaxTimestamp:    1700000000000,
+			ProducerID:      7,
+			ProducerEpoch:   2,
+			BaseSequence:    0,
+		},
+		Records: []common.Record{{Value: []byte("a")}, {OffsetDelta: 1, Value: []byte("b")}},
+	})
+	if _, err := repo.AppendRecordBatches(domain.AppendRequest{TopicName: "foo", PartitionIndex: 0, Records: transactional}); err != nil {
+		t.Fatalf("AppendRecordBatches() error = %v", err)
+	}
+
+	mockParser := &mockParser{request: &domain.ParsedRequestDescribeProducers{
+		Topics: []domain.DescribeProducersTopic{
+			{Name: "foo", PartitionIndexes: []int32{0, 1}},
+			{Name: "bar", PartitionIndexes: []int32{0}},
+		},
+	}}
+	if _, err := NewDescribeProducersService(mockParser, &mockMetadataRepository{}, repo).HandleRequest(domain.Request{}); err != nil {
+		t.Fatalf("HandleRequest() error = %v", err)
+	}
+
+	topics := mockParser.response.Topics
+	if len(topics) != 2 || len(topics[0].Partitions) != 2 || len(topics[1].Partitions) != 1 {
+		t.Fatalf("Topics = %+v, want foo with 2 partitions and bar with 1", topics)
+	}
+	described := topics[0].Partitions[0]
+	want := domain.ProducerState{ProducerID: 7, ProducerEpoch: 2, LastSequence: 1, LastTimestamp: 1700000000000, CoordinatorEpoch: -1, CurrentTxnStartOffset: 0}
+	if described.ErrorCode != domain.ErrorCodeNone || len(described.ActiveProducers) != 1 || described.ActiveProducers[0] != want {
+		t.Errorf("foo-0 = %+v, want %+v", described, want)
+	}
+	for _, unknown := range []domain.DescribeProducersPartitionResponse{topics[0].Partitions[1], topics[1].Partitions[0]} {
+		if unknown.ErrorCode != domain.ErrorCodeUnknownTopicOrPartition {
+			t.Errorf("partition %d error = %d, want UNKNOWN_TOPIC_OR_PARTITION", unknown.PartitionIndex, unknown.ErrorCode)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package fetch_service
+
+import (
+	"sync"
+	"time"
+)
+
+// partitionKey identifies a partition log the way the partition repository names it
+type partitionKey struct {
+	topicName      string
+	partitionIndex int
+}
+
+// delayedFetch is a Fetch parked in the purgatory. tryComplete re-reads the partitions and
+// answers the request if enough data is available, or unconditionally once expired; it
+// reports whether the request was answered.
+type delayedFetch struct {
+	mu          sync.Mutex
+	completed   bool
+	timer       *time.Timer
+	keys        []partitionKey
+	tryComplete func(expired bool) bool
+}
+
+// try runs tryComplete unless the fetch was already answered
+func (f *delayedFetch) try(expired bool) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.completed {
+		return true
+	}
+	if !f.tryComplete(expired) {
+		return false
+	}
+	f.completed = true
+	if f.timer != nil {
+		f.timer.Stop()
+	}
+	return true
+}
+
+// FetchPurgatory holds Fetch requests waiting for MinBytes of data until an append to one
+// of their partitions satisfies them or their MaxWaitMS elapses. It is registered as an
+// append listener on the partition repository.
+type FetchPurgatory struct {
+	mu       sync.Mutex
+	watchers map[partitionKey]map[*delayedFetch]struct{}
+}
+
+func NewFetchPurgatory() *FetchPurgatory {
+	return &FetchPurgatory{watchers: make(map[partitionKey]map[*delayedFetch]struct{})}
+}
+
+// OnAppend retries the fetches watching the partition that was appended to
+func (p *FetchPurgatory) OnAppend(topicName string, partitionIndex int) {
+	key := partitionKey{topicName: topicName, partitionIndex: partitionIndex}
+
+	p.mu.Lock()
+	fetches := make([]*delayedFetch, 0, len(p.watchers[key]))
+	for fetch := range p.watchers[key] {
+		fetches = append(fetches, fetch)
+	}
+	p.mu.Unlock()
+
+	for _, fetch := range fetches {
+		if fetch.try(false) {
+			p.unwatch(fetch)
+		}
+	}
+}
+
+// tryCompleteElseWatch parks fetch on its partitions and arms its timeout. The fetch is
+// retried once after being watched so an append racing with the first read is not missed.
+func (p *FetchPurgatory) tryCompleteElseWatch(fetch *delayedFetch, maxWait time.Duration) {
+	p.mu.Lock()
+	for _, key := range fetch.keys {
+		if p.watchers[key] == nil {
+			p.watchers[key] = make(map[*delayedFetch]struct{})
+		}
+		p.watchers[key][fetch] = struct{}{}
+	}
+	p.mu.Unlock()
+
+	if fetch.try(false) {
+		p.unwatch(fetch)
+		return
+	}
+
+	fetch.mu.Lock()
+	if !fetch.completed {
+		fetch.timer = time.AfterFunc(maxWait, func() {
+			fetch.try(true)
+			p.unwatch(fetch)
+		})
+	}
+	fetch.mu.Unlock()
+}
+
+func (p *FetchPurgatory) unwatch(fetch *delayedFetch) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, key := range fetch.keys {
+		delete(p.watchers[key], fetch)
+		if len(p.watchers[key]) == 0 {
+			delete(p.watchers, key)
+		}
+	}
+}
+
+// watched returns the number of fetches waiting on a partition
+func (p *FetchPurgatory) watched(key partitionKey) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.watchers[key])
+}
@@ -1,33 +1,61 @@
 package fetch_service
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/codecrafters-io/kafka-starter-go/core/domain"
 	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
 	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
 	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
-	fetch_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/fetch"
 	port_repo "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/partition_file_repository"
 	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
 )
 
 type FetchService struct {
 	parser                    parser.FetchParser
-	fetch_repository          fetch_repository.FetchRepository
 	metadata_repository       port_cluster_metadata_repository.ClusterMetadataRepository
 	partition_file_repository port_repo.PartitionFileRepository
+	purgatory                 *FetchPurgatory
+	sessions                  *FetchSessionCache
 }
 
-func NewFetchService(parser parser.FetchParser, repository fetch_repository.FetchRepository, metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository, partition_file_repository port_repo.PartitionFileRepository) driving.KafkaHandler {
+// NewFetchService creates the Fetch handler. Fetches that can't be answered with MinBytes
+// wait in purgatory, which must be registered as an append listener on the partition
+// repository; with a nil purgatory every Fetch is answered immediately. Incremental fetch
+// sessions are kept in sessions; with a nil cache every Fetch is sessionless.
+func NewFetchService(parser parser.FetchParser, metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository, partition_file_repository port_repo.PartitionFileRepository, purgatory *FetchPurgatory, sessions *FetchSessionCache) driving.KafkaHandler {
+	if sessions == nil {
+		sessions = NewFetchSessionCache(0)
+	}
 	return &FetchService{
 		parser:                    parser,
-		fetch_repository:          repository,
 		metadata_repository:       metadata_repository,
 		partition_file_repository: partition_file_repository,
+		purgatory:                 purgatory,
+		sessions:                  sessions,
 	}
 }
 
+// fetchContext holds the partitions a Fetch reads: the request's own, or those of its
+// fetch session
+type fetchContext struct {
+	partitions  []*cachedPartition
+	session     *fetchSession // nil for sessionless fetches
+	incremental bool          // Only partitions with news are returned
+	errorCode   int16         // Session error answering the whole request
+}
+
+// fetchResult summarizes a pass over the context's partitions
+type fetchResult struct {
+	partitions []*domain.FetchResponsePartition // One per context partition, in the same order
+	bytes      int
+	hasError   bool
+	keys       []partitionKey // Partition logs that were read
+}
+
 func (s *FetchService) HandleRequest(req domain.Request) (domain.Response, error) {
 	parsedReq, err := s.parser.ParseRequest(req.Data)
 	if err != nil {
@@ -35,24 +63,140 @@ func (s *FetchService) HandleRequest(req domain.Request) (domain.Response, error
 		return domain.Response{}, err
 	}
 
-	// Build response data structure
-	topicFetchResponse, err := s.fetch_repository.GetTopicFetch(*parsedReq)
-	if err != nil {
-		fmt.Println(">>>>>>>> ", err.Error())
-		panic("panic")
+	fetchCtx := s.newFetchContext(parsedReq)
+	if fetchCtx.errorCode != domain.ErrorCodeNone {
+		return s.encode(&domain.ResponseDataFetch{
+			CorrelationID: parsedReq.CorrelationID,
+			APIVersion:    parsedReq.APIVersion,
+			ErrorCode:     fetchCtx.errorCode,
+			Topics:        []domain.FetchResponseTopic{},
+		})
+	}
+
+	result := s.fetch(parsedReq, fetchCtx)
+	if s.purgatory == nil || canAnswer(parsedReq, result) {
+		return s.encode(s.buildResponse(parsedReq, fetchCtx, result))
+	}
+
+	// Not enough data yet: answer once an append brings MinBytes or MaxWaitMS elapses
+	deferred := make(chan domain.Response, 1)
+	delayed := &delayedFetch{keys: result.keys}
+	delayed.tryComplete = func(expired bool) bool {
+		result := s.fetch(parsedReq, fetchCtx)
+		if !expired && !canAnswer(parsedReq, result) {
+			return false
+		}
+		response, err := s.encode(s.buildResponse(parsedReq, fetchCtx, result))
+		if err != nil {
+			fmt.Printf("Completing delayed Fetch failed: %v\n", err)
+		}
+		deferred <- response
+		return true
 	}
+	s.purgatory.tryCompleteElseWatch(delayed, time.Duration(parsedReq.MaxWaitMS)*time.Millisecond)
+
+	return domain.Response{Deferred: deferred}, nil
+}
 
+// newFetchContext resolves the request's SessionID and SessionEpoch (KIP-227). Epoch -1
+// closes the session and fetches without one, epoch 0 opens a new session over the
+// requested partitions, and later epochs update the session's partitions and read all of
+// them.
+func (s *FetchService) newFetchContext(parsedReq *domain.ParsedRequestFetch) fetchContext {
+	requested := []*cachedPartition{}
+	for _, topic := range parsedReq.Topics {
+		for _, partition := range topic.Partitions {
+			requested = append(requested, newCachedPartition(topic, partition))
+		}
+	}
+
+	switch parsedReq.SessionEpoch {
+	case fetchSessionFinalEpoch:
+		if parsedReq.SessionID != 0 {
+			s.sessions.remove(parsedReq.SessionID)
+		}
+		return fetchContext{partitions: requested}
+	case fetchSessionInitialEpoch:
+		if parsedReq.SessionID != 0 {
+			s.sessions.remove(parsedReq.SessionID)
+		}
+		return fetchContext{partitions: requested, session: s.sessions.create(slices.Clone(requested))}
+	}
+
+	session, exists := s.sessions.get(parsedReq.SessionID)
+	if !exists {
+		return fetchContext{errorCode: domain.ErrorCodeFetchSessionIDNotFound}
+	}
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.epoch != parsedReq.SessionEpoch {
+		return fetchContext{errorCode: domain.ErrorCodeInvalidFetchSessionEpoch}
+	}
+	session.update(requested, parsedReq.ForgottenTopics)
+	session.epoch = nextFetchSessionEpoch(session.epoch)
+	return fetchContext{partitions: slices.Clone(session.partitions), session: session, incremental: true}
+}
+
+// canAnswer reports whether a Fetch should be answered now rather than wait for more data
+func canAnswer(parsedReq *domain.ParsedRequestFetch, result fetchResult) bool {
+	return parsedReq.MaxWaitMS <= 0 ||
+		len(result.keys) == 0 ||
+		result.hasError ||
+		result.bytes >= int(parsedReq.MinBytes)
+}
+
+// fetch reads the context's partitions from the current state of the partition logs
+func (s *FetchService) fetch(parsedReq *domain.ParsedRequestFetch, fetchCtx fetchContext) fetchResult {
 	clusterMetaData, err := s.metadata_repository.GetClusterMetadata()
 	if err != nil {
 		fmt.Println(">>>>>>>> there is no cluster metadata", err.Error())
 	}
-	_ = clusterMetaData
 
-	messageFetchRequest := s.getMessageRequestForValidTopics(&topicFetchResponse, clusterMetaData)
-	s.partition_file_repository.GetPartitionMessage(messageFetchRequest)
+	return s.readPartitions(parsedReq, fetchCtx.partitions, clusterMetaData)
+}
 
+// buildResponse groups the partitions read into topics. Session partitions remember what
+// is sent so incremental responses can leave out the ones without news.
+func (s *FetchService) buildResponse(parsedReq *domain.ParsedRequestFetch, fetchCtx fetchContext, result fetchResult) *domain.ResponseDataFetch {
+	response := &domain.ResponseDataFetch{
+		CorrelationID: parsedReq.CorrelationID,
+		APIVersion:    parsedReq.APIVersion,
+		Topics:        []domain.FetchResponseTopic{},
+	}
+	if fetchCtx.session != nil {
+		fetchCtx.session.mu.Lock()
+		defer fetchCtx.session.mu.Unlock()
+		response.SessionID = fetchCtx.session.id
+	}
+
+	topicIndexes := make(map[string]int)
+	for i, partition := range fetchCtx.partitions {
+		partitionResponse := result.partitions[i]
+		if fetchCtx.session != nil {
+			mustRespond := partition.maybeUpdateResponseData(partitionResponse)
+			if fetchCtx.incremental && !mustRespond {
+				continue
+			}
+		}
+
+		index, exists := topicIndexes[partition.topic]
+		if !exists {
+			index = len(response.Topics)
+			topicIndexes[partition.topic] = index
+			response.Topics = append(response.Topics, domain.FetchResponseTopic{
+				TopicName:      partition.topic,
+				TopicNameBytes: partition.topicID,
+			})
+		}
+		response.Topics[index].Partitions = append(response.Topics[index].Partitions, partitionResponse)
+	}
+	return response
+}
+
+func (s *FetchService) encode(response *domain.ResponseDataFetch) (domain.Response, error) {
 	// Encode the response using the protocol parser (infrastructure concern)
-	encodedResponse, err := s.parser.EncodeResponse(&topicFetchResponse)
+	encodedResponse, err := s.parser.EncodeResponse(response)
 	if err != nil {
 		return domain.Response{}, err
 	}
@@ -62,27 +206,102 @@ func (s *FetchService) HandleRequest(req domain.Request) (domain.Response, error
 	}, nil
 }
 
-func (s *FetchService) getMessageRequestForValidTopics(topicFetchResponse *domain.ResponseDataFetch, clusterMetaData port_cluster_metadata_repository.ClusterMetadataRepositoryResponse) domain.MessageFetchRequest {
-	retVal := domain.MessageFetchRequest{PartitionsToFetch: make([]domain.PartitionToFetch, 0)}
+// readPartitions reads the batches at every partition's fetch offset. The request's
+// MaxBytes is shared by all partitions in order; only the first partition that returns
+// data may exceed its budget, so an oversized batch never stalls a consumer. Consumers
+// reading committed data get no further than the last stable offset, along with the
+// aborted transactions whose batches they must skip.
+func (s *FetchService) readPartitions(parsedReq *domain.ParsedRequestFetch, partitions []*cachedPartition, clusterMetaData port_cluster_metadata_repository.ClusterMetadataRepositoryResponse) fetchResult {
+	result := fetchResult{}
+	remainingBytes := parsedReq.MaxBytes
+	minOneBatch := true
 
-	for _, topic := range topicFetchResponse.Topics {
-		partitionMetadataArray := clusterMetaData.TopicUUIDPartitionMetadataMap[topic.TopicName]
-		topicMetadata := clusterMetaData.TopicUUIDTopicMetadataInfoMap[topic.TopicName]
-		for partitionIndex, partition := range topic.Partitions {
-			if partitionMetadataArray == nil || partitionIndex >= len(partitionMetadataArray) {
-				partition.ErrorCode = 100
-				continue
-			}
-			errorCode := int16(common.BytesToInt(partitionMetadataArray[partitionIndex].ErrorCode))
+	for _, fetchPartition := range partitions {
+		partition := &domain.FetchResponsePartition{
+			PartitionIndex:       fetchPartition.request.PartitionIndex,
+			HighWatermark:        -1,
+			LastStableOffset:     -1,
+			LogStartOffset:       -1,
+			PreferredReadReplica: -1,
+		}
+		result.partitions = append(result.partitions, partition)
+
+		topicUuid, errorCode := resolveTopic(fetchPartition, clusterMetaData)
+		partitionMetadata := clusterMetaData.FindPartitionByTopicUUID(topicUuid, partition.PartitionIndex)
+		if errorCode == domain.ErrorCodeNone && partitionMetadata == nil {
+			errorCode = domain.ErrorCodeUnknownTopicOrPartition
+		}
+		if errorCode != domain.ErrorCodeNone {
 			partition.ErrorCode = errorCode
-			if errorCode == 0 {
-				retVal.PartitionsToFetch = append(retVal.PartitionsToFetch, domain.PartitionToFetch{
-					TopicName:          topicMetadata.TopicNameInfo.TopicName,
-					PartitionIndex:     partitionIndex,
-					TopicFetchResponse: partition,
-				})
-			}
+			result.hasError = true
+			continue
+		}
+		topicMetadata := clusterMetaData.TopicUUIDTopicMetadataInfoMap[topicUuid]
+		partition.ErrorCode = int16(common.BytesToInt(partitionMetadata.ErrorCode))
+		if partition.ErrorCode != domain.ErrorCodeNone {
+			result.hasError = true
+			continue
+		}
+
+		readResult, err := s.partition_file_repository.ReadRecordBatches(domain.ReadRequest{
+			TopicName:      topicMetadata.TopicNameInfo.TopicName,
+			PartitionIndex: int(partition.PartitionIndex),
+			FetchOffset:    fetchPartition.request.FetchOffset,
+			MaxBytes:       min(fetchPartition.request.PartitionMaxBytes, max(remainingBytes, 0)),
+			MinOneBatch:    minOneBatch,
+			IsolationLevel: parsedReq.IsolationLevel,
+		})
+		partition.ErrorCode = errorCodeForReadError(err)
+		if partition.ErrorCode == domain.ErrorCodeKafkaStorageError {
+			fmt.Printf("Fetch for partition %d failed: %v\n", partition.PartitionIndex, err)
+			result.hasError = true
+			continue
 		}
+		result.hasError = result.hasError || partition.ErrorCode != domain.ErrorCodeNone
+		result.keys = append(result.keys, partitionKey{
+			topicName:      topicMetadata.TopicNameInfo.TopicName,
+			partitionIndex: int(partition.PartitionIndex),
+		})
+
+		partition.HighWatermark = readResult.HighWatermark
+		partition.LastStableOffset = readResult.LastStableOffset
+		partition.LogStartOffset = readResult.LogStartOffset
+		partition.AbortedTransactions = readResult.AbortedTransactions
+		partition.Records = readResult.Records
+		if len(readResult.Records) > 0 {
+			remainingBytes -= int32(len(readResult.Records))
+			minOneBatch = false
+			result.bytes += len(readResult.Records)
+		}
+	}
+	return result
+}
+
+// resolveTopic returns the hex UUID of the partition's topic. Requests from v13 name
+// topics by ID and get UNKNOWN_TOPIC_ID for unknown ones; older requests name them and
+// get UNKNOWN_TOPIC_OR_PARTITION.
+func resolveTopic(partition *cachedPartition, clusterMetaData port_cluster_metadata_repository.ClusterMetadataRepositoryResponse) (string, int16) {
+	if partition.topicID != nil {
+		if clusterMetaData.TopicUUIDTopicMetadataInfoMap[partition.topic] == nil {
+			return "", domain.ErrorCodeUnknownTopicID
+		}
+		return partition.topic, domain.ErrorCodeNone
+	}
+
+	topicUuid, exists := clusterMetaData.TopicNameTopicUuidMap[partition.topic]
+	if !exists || clusterMetaData.TopicUUIDTopicMetadataInfoMap[topicUuid] == nil {
+		return "", domain.ErrorCodeUnknownTopicOrPartition
+	}
+	return topicUuid, domain.ErrorCodeNone
+}
+
+func errorCodeForReadError(err error) int16 {
+	switch {
+	case err == nil:
+		return domain.ErrorCodeNone
+	case errors.Is(err, domain.ErrOffsetOutOfRange):
+		return domain.ErrorCodeOffsetOutOfRange
+	default:
+		return domain.ErrorCodeKafkaStorageError
 	}
-	return retVal
 }
@@ -1,13 +1,19 @@
 package fetch_service
 
 import (
+	"bytes"
+	"reflect"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	port_parser "github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
 	infraparser "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/parser"
 	"github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/cluster_metadata_repository"
-	fetch_repository "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/fetch"
 	partition_file_repository "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/partition_repository"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
 )
 
 func TestKafkaFetchService(t *testing.T) {
@@ -28,11 +34,10 @@ func TestKafkaFetchService(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			parser := infraparser.NewKafkaProtocolParserFetch()
-			repo := fetch_repository.NewFetchRepository()
 			fmr := cluster_metadata_repository.NewClusterMetadataRepository()
 			pfr := partition_file_repository.NewPartitionFileRepository()
 
-			service := NewFetchService(parser, repo, fmr, pfr)
+			service := NewFetchService(parser, fmr, pfr, nil, nil)
 			_, err := service.HandleRequest(domain.Request{Data: tt.data})
 			if err != nil {
 				t.Errorf("HandleRequest failed: %v", err)
@@ -40,3 +45,465 @@ func TestKafkaFetchService(t *testing.T) {
 		})
 	}
 }
+
+var testTopicID = bytes.Repeat([]byte{0x11}, 16)
+
+// mockMetadataRepository serves topic "foo" with partitions 0 and 1
+type mockMetadataRepository struct{}
+
+func (m *mockMetadataRepository) GetClusterMetadata() (port_cluster_metadata_repository.ClusterMetadataRepositoryResponse, error) {
+	topicUuid := "11111111111111111111111111111111"
+	partitions := []*domain.PartitionMetadata{
+		{ErrorCode: common.IntToTwoBytes(0), PartitionIndex: common.IntToFourBytes(0)},
+		{ErrorCode: common.IntToTwoBytes(0), PartitionIndex: common.IntToFourBytes(1)},
+	}
+	return port_cluster_metadata_repository.ClusterMetadataRepositoryResponse{
+		TopicNameTopicUuidMap: map[string]string{"foo": topicUuid},
+		TopicUUIDTopicMetadataInfoMap: map[string]*port_cluster_metadata_repository.TopicMetadataInfo{
+			topicUuid: {TopicNameInfo: port_parser.TopicNameInfo{TopicName: "foo"}, TopicId: testTopicID, PartitionsArray: partitions},
+		},
+		TopicUUIDPartitionMetadataMap: map[string][]*domain.PartitionMetadata{topicUuid: partitions},
+	}, nil
+}
+
+// fetchRequestV16 builds a sessionless Fetch v16 request for partition 0 of the test topic
+func fetchRequestV16(fetchOffset int64, maxBytes int32, partitionMaxBytes int32, maxWaitMS int32, minBytes int32) []byte {
+	partitions := []domain.FetchPartition{{PartitionIndex: 0, FetchOffset: fetchOffset, PartitionMaxBytes: partitionMaxBytes}}
+	return fetchSessionRequestV16(0, -1, maxBytes, maxWaitMS, minBytes, partitions, nil)
+}
+
+// fetchSessionRequestV16 builds a Fetch v16 request for partitions of the test topic in a
+// fetch session, forgetting the forgotten partitions
+func fetchSessionRequestV16(sessionID int32, sessionEpoch int32, maxBytes int32, maxWaitMS int32, minBytes int32, partitions []domain.FetchPartition, forgotten []int32) []byte {
+	w := common.NewKafkaWriter()
+	w.Int16(1)
+	w.Int16(16)
+	w.Int32(1)
+	w.String("consumer", false)
+	w.EmptyTaggedFields()
+	w.Int32(maxWaitMS)
+	w.Int32(minBytes)
+	w.Int32(maxBytes)
+	w.Int8(0) // IsolationLevel
+	w.Int32(sessionID)
+	w.Int32(sessionEpoch)
+	if len(partitions) == 0 {
+		w.ArrayLength(0, true)
+	} else {
+		w.ArrayLength(1, true)
+		w.UUID(testTopicID)
+		w.ArrayLength(len(partitions), true)
+		for _, partition := range partitions {
+			w.Int32(partition.PartitionIndex)
+			w.Int32(-1) // CurrentLeaderEpoch
+			w.Int64(partition.FetchOffset)
+			w.Int32(-1) // LastFetchedEpoch
+			w.Int64(-1) // LogStartOffset
+			w.Int32(partition.PartitionMaxBytes)
+			w.EmptyTaggedFields()
+		}
+		w.EmptyTaggedFields()
+	}
+	if len(forgotten) == 0 {
+		w.ArrayLength(0, true)
+	} else {
+		w.ArrayLength(1, true)
+		w.UUID(testTopicID)
+		w.ArrayLength(len(forgotten), true)
+		for _, partitionIndex := range forgotten {
+			w.Int32(partitionIndex)
+		}
+		w.EmptyTaggedFields()
+	}
+	w.String("", true) // RackID
+	w.EmptyTaggedFields()
+	return w.WithSizePrefix()
+}
+
+// capturingParser records the response handed to the encoder
+type capturingParser struct {
+	*infraparser.KafkaProtocolParserFetch
+	response *domain.ResponseDataFetch
+}
+
+func (p *capturingParser) EncodeResponse(response *domain.ResponseDataFetch) ([]byte, error) {
+	p.response = response
+	return p.KafkaProtocolParserFetch.EncodeResponse(response)
+}
+
+// valueBatch is a batch of a single record written without a producer ID
+func valueBatch(value string) []byte {
+	return common.EncodeRecordBatch(common.RecordBatch{
+		RecordBatchHeader: common.RecordBatchHeader{ProducerID: -1, ProducerEpoch: -1, BaseSequence: -1},
+		Records:           []common.Record{{Value: []byte(value)}},
+	})
+}
+
+func TestFetchService_ReadsFromFetchOffset(t *testing.T) {
+	pfr := partition_file_repository.NewPartitionFileRepositoryWithLogDir(t.TempDir())
+	var batches [][]byte
+	for _, value := range []string{"a", "b", "c"} {
+		batch := valueBatch(value)
+		batches = append(batches, batch)
+		if _, err := pfr.AppendRecordBatches(domain.AppendRequest{TopicName: "foo", PartitionIndex: 0, Records: batch}); err != nil {
+			t.Fatalf("AppendRecordBatches() error = %v", err)
+		}
+	}
+	batchSize := int32(len(batches[0]))
+
+	tests := []struct {
+		name              string
+		fetchOffset       int64
+		maxBytes          int32
+		partitionMaxBytes int32
+		wantErrorCode     int16
+		wantRecordBytes   int32
+	}{
+		{name: "all batches", fetchOffset: 0, maxBytes: 1 << 20, partitionMaxBytes: 1 << 20, wantRecordBytes: 3 * batchSize},
+		{name: "from fetch offset", fetchOffset: 1, maxBytes: 1 << 20, partitionMaxBytes: 1 << 20, wantRecordBytes: 2 * batchSize},
+		{name: "partition max bytes", fetchOffset: 0, maxBytes: 1 << 20, partitionMaxBytes: 2 * batchSize, wantRecordBytes: 2 * batchSize},
+		{name: "response max bytes", fetchOffset: 0, maxBytes: batchSize, partitionMaxBytes: 1 << 20, wantRecordBytes: batchSize},
+		{name: "at least one batch", fetchOffset: 0, maxBytes: 1, partitionMaxBytes: 1, wantRecordBytes: batchSize},
+		{name: "offset out of range", fetchOffset: 4, maxBytes: 1 << 20, partitionMaxBytes: 1 << 20, wantErrorCode: domain.ErrorCodeOffsetOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &capturingParser{KafkaProtocolParserFetch: infraparser.NewKafkaProtocolParserFetch()}
+			service := NewFetchService(parser, &mockMetadataRepository{}, pfr, nil, nil)
+			if _, err := service.HandleRequest(domain.Request{Data: fetchRequestV16(tt.fetchOffset, tt.maxBytes, tt.partitionMaxBytes, 0, 1)}); err != nil {
+				t.Fatalf("HandleRequest() error = %v", err)
+			}
+
+			partition := parser.response.Topics[0].Partitions[0]
+			if partition.ErrorCode != tt.wantErrorCode {
+				t.Errorf("ErrorCode = %d, want %d", partition.ErrorCode, tt.wantErrorCode)
+			}
+			if int32(len(partition.Records)) != tt.wantRecordBytes {
+				t.Errorf("Records has %d bytes, want %d", len(partition.Records), tt.wantRecordBytes)
+			}
+			if partition.HighWatermark != 3 || partition.LogStartOffset != 0 {
+				t.Errorf("HighWatermark = %d, LogStartOffset = %d, want 3 and 0", partition.HighWatermark, partition.LogStartOffset)
+			}
+		})
+	}
+}
+
+// readCommittedParser parses every request as a read_committed one
+type readCommittedParser struct {
+	*capturingParser
+}
+
+func (p readCommittedParser) ParseRequest(data []byte) (*domain.ParsedRequestFetch, error) {
+	parsedReq, err := p.capturingParser.ParseRequest(data)
+	if err == nil {
+		parsedReq.IsolationLevel = domain.IsolationLevelReadCommitted
+	}
+	return parsedReq, err
+}
+
+func TestFetchService_ReadCommitted(t *testing.T) {
+	pfr := partition_file_repository.NewPartitionFileRepositoryWithLogDir(t.TempDir())
+	transactional := common.EncodeRecordBatch(common.RecordBatch{
+		RecordBatchHeader: common.RecordBatchHeader{
+			Attributes:    common.TransactionalAttributeFlag,
+			ProducerID:    7,
+			ProducerEpoch: 0,
+			BaseSequence:  0,
+		},
+		Records: []common.Record{{Value: []byte("pending")}},
+	})
+	for _, batch := range [][]byte{transactional, valueBatch("a")} {
+		if _, err := pfr.AppendRecordBatches(domain.AppendRequest{TopicName: "foo", PartitionIndex: 0, Records: batch}); err != nil {
+			t.Fatalf("AppendRecordBatches() error = %v", err)
+		}
+	}
+	fetch := func(isolationLevel int8) *domain.FetchResponsePartition {
+		t.Helper()
+		capturing := &capturingParser{KafkaProtocolParserFetch: infraparser.NewKafkaProtocolParserFetch()}
+		var parser port_parser.FetchParser = capturing
+		if isolationLevel == domain.IsolationLevelReadCommitted {
+			parser = readCommittedParser{capturing}
+		}
+		if _, err := NewFetchService(parser, &mockMetadataRepository{}, pfr, nil, nil).HandleRequest(domain.Request{Data: fetchRequestV16(0, 1<<20, 1<<20, 0, 1)}); err != nil {
+			t.Fatalf("HandleRequest() error = %v", err)
+		}
+		return capturing.response.Topics[0].Partitions[0]
+	}
+
+	if uncommitted := fetch(domain.IsolationLevelReadUncommitted); len(uncommitted.Records) == 0 || uncommitted.LastStableOffset != 0 || uncommitted.HighWatermark != 2 {
+		t.Errorf("read_uncommitted = %d bytes, LSO %d, HW %d, want the records, LSO 0, HW 2", len(uncommitted.Records), uncommitted.LastStableOffset, uncommitted.HighWatermark)
+	}
+	if pending := fetch(domain.IsolationLevelReadCommitted); len(pending.Records) != 0 || pending.LastStableOffset != 0 {
+		t.Errorf("read_committed with an open transaction = %d bytes, LSO %d, want nothing, LSO 0", len(pending.Records), pending.LastStableOffset)
+	}
+
+	pfr.WriteTxnMarker(domain.TxnMarkerAppend{TopicName: "foo", ProducerID: 7, ProducerEpoch: 0})
+	aborted := fetch(domain.IsolationLevelReadCommitted)
+	if len(aborted.Records) == 0 || aborted.LastStableOffset != 3 || aborted.HighWatermark != 3 {
+		t.Errorf("read_committed after the abort = %d bytes, LSO %d, HW %d, want the records, LSO 3, HW 3", len(aborted.Records), aborted.LastStableOffset, aborted.HighWatermark)
+	}
+	want := []domain.AbortedTransaction{{ProducerID: 7, FirstOffset: 0}}
+	if !reflect.DeepEqual(aborted.AbortedTransactions, want) {
+		t.Errorf("AbortedTransactions = %+v, want %+v", aborted.AbortedTransactions, want)
+	}
+}
+
+func TestFetchService_LongPoll(t *testing.T) {
+	purgatory := NewFetchPurgatory()
+	pfr := partition_file_repository.NewPartitionFileRepositoryWithLogDir(t.TempDir(), partition_file_repository.WithAppendListener(purgatory))
+	parser := &capturingParser{KafkaProtocolParserFetch: infraparser.NewKafkaProtocolParserFetch()}
+	service := NewFetchService(parser, &mockMetadataRepository{}, pfr, purgatory, nil)
+	key := partitionKey{topicName: "foo", partitionIndex: 0}
+
+	t.Run("woken by an append", func(t *testing.T) {
+		resp, err := service.HandleRequest(domain.Request{Data: fetchRequestV16(0, 1<<20, 1<<20, 10000, 1)})
+		if err != nil {
+			t.Fatalf("HandleRequest() error = %v", err)
+		}
+		if resp.Deferred == nil || purgatory.watched(key) != 1 {
+			t.Fatalf("Fetch on an empty partition was not parked")
+		}
+
+		batch := valueBatch("a")
+		if _, err := pfr.AppendRecordBatches(domain.AppendRequest{TopicName: "foo", PartitionIndex: 0, Records: batch}); err != nil {
+			t.Fatalf("AppendRecordBatches() error = %v", err)
+		}
+
+		select {
+		case response := <-resp.Deferred:
+			if len(response.Data) == 0 || len(parser.response.Topics[0].Partitions[0].Records) != len(batch) {
+				t.Errorf("delayed Fetch did not return the appended batch")
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("delayed Fetch was not completed by the append")
+		}
+		if purgatory.watched(key) != 0 {
+			t.Errorf("completed Fetch is still watched")
+		}
+	})
+
+	t.Run("expires after MaxWaitMS", func(t *testing.T) {
+		start := time.Now()
+		resp, err := service.HandleRequest(domain.Request{Data: fetchRequestV16(1, 1<<20, 1<<20, 50, 1)})
+		if err != nil || resp.Deferred == nil {
+			t.Fatalf("HandleRequest() = %+v, %v, want a deferred response", resp, err)
+		}
+
+		select {
+		case response := <-resp.Deferred:
+			if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+				t.Errorf("Fetch answered after %v, before MaxWaitMS", elapsed)
+			}
+			if len(response.Data) == 0 || len(parser.response.Topics[0].Partitions[0].Records) != 0 {
+				t.Errorf("expired Fetch should return an empty record set")
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("delayed Fetch did not expire")
+		}
+	})
+
+	t.Run("answered at once when MinBytes are available", func(t *testing.T) {
+		resp, err := service.HandleRequest(domain.Request{Data: fetchRequestV16(0, 1<<20, 1<<20, 10000, 1)})
+		if err != nil || resp.Deferred != nil || len(resp.Data) == 0 {
+			t.Errorf("HandleRequest() = %+v, %v, want an immediate response", resp, err)
+		}
+	})
+}
+
+func TestFetchService_Sessions(t *testing.T) {
+	pfr := partition_file_repository.NewPartitionFileRepositoryWithLogDir(t.TempDir())
+	parser := &capturingParser{KafkaProtocolParserFetch: infraparser.NewKafkaProtocolParserFetch()}
+	service := NewFetchService(parser, &mockMetadataRepository{}, pfr, nil, NewFetchSessionCache(DefaultMaxFetchSessions))
+	both := []domain.FetchPartition{{PartitionIndex: 0, PartitionMaxBytes: 1 << 20}, {PartitionIndex: 1, PartitionMaxBytes: 1 << 20}}
+
+	fetch := func(t *testing.T, sessionID int32, sessionEpoch int32, partitions []domain.FetchPartition, forgotten []int32) *domain.ResponseDataFetch {
+		t.Helper()
+		if _, err := service.HandleRequest(domain.Request{Data: fetchSessionRequestV16(sessionID, sessionEpoch, 1<<20, 0, 1, partitions, forgotten)}); err != nil {
+			t.Fatalf("HandleRequest() error = %v", err)
+		}
+		return parser.response
+	}
+	partitionIndexes := func(response *domain.ResponseDataFetch) []int32 {
+		indexes := []int32{}
+		for _, topic := range response.Topics {
+			for _, partition := range topic.Partitions {
+				indexes = append(indexes, partition.PartitionIndex)
+			}
+		}
+		return indexes
+	}
+
+	full := fetch(t, 0, 0, both, nil)
+	sessionID := full.SessionID
+	if sessionID == 0 || !slices.Equal(partitionIndexes(full), []int32{0, 1}) {
+		t.Fatalf("full fetch = session %d with partitions %v, want a session with partitions [0 1]", sessionID, partitionIndexes(full))
+	}
+
+	if response := fetch(t, sessionID, 1, nil, nil); len(response.Topics) != 0 || response.SessionID != sessionID {
+		t.Errorf("incremental fetch without changes returned partitions %v", partitionIndexes(response))
+	}
+
+	batch := valueBatch("a")
+	if _, err := pfr.AppendRecordBatches(domain.AppendRequest{TopicName: "foo", PartitionIndex: 0, Records: batch}); err != nil {
+		t.Fatalf("AppendRecordBatches() error = %v", err)
+	}
+	response := fetch(t, sessionID, 2, nil, nil)
+	if !slices.Equal(partitionIndexes(response), []int32{0}) || len(response.Topics[0].Partitions[0].Records) != len(batch) {
+		t.Errorf("incremental fetch after an append returned partitions %v, want [0] with the batch", partitionIndexes(response))
+	}
+
+	if response := fetch(t, sessionID, 2, nil, nil); response.ErrorCode != domain.ErrorCodeInvalidFetchSessionEpoch || response.SessionID != 0 {
+		t.Errorf("replayed epoch: ErrorCode = %d, want %d", response.ErrorCode, domain.ErrorCodeInvalidFetchSessionEpoch)
+	}
+	if response := fetch(t, sessionID+1, 1, nil, nil); response.ErrorCode != domain.ErrorCodeFetchSessionIDNotFound {
+		t.Errorf("unknown session: ErrorCode = %d, want %d", response.ErrorCode, domain.ErrorCodeFetchSessionIDNotFound)
+	}
+
+	// Forget partition 1 and move partition 0 back to the start of the log
+	response = fetch(t, sessionID, 3, []domain.FetchPartition{{PartitionIndex: 0, PartitionMaxBytes: 1 << 20}}, []int32{1})
+	if !slices.Equal(partitionIndexes(response), []int32{0}) {
+		t.Errorf("incremental fetch returned partitions %v, want [0]", partitionIndexes(response))
+	}
+	session, _ := service.(*FetchService).sessions.get(sessionID)
+	if len(session.partitions) != 1 || session.partitions[0].request.PartitionIndex != 0 {
+		t.Errorf("session holds %d partitions after forgetting partition 1, want only partition 0", len(session.partitions))
+	}
+
+	if response := fetch(t, sessionID, -1, both, nil); response.SessionID != 0 || len(partitionIndexes(response)) != 2 {
+		t.Errorf("closing fetch = session %d with partitions %v, want a sessionless full response", response.SessionID, partitionIndexes(response))
+	}
+	if _, exists := service.(*FetchService).sessions.get(sessionID); exists {
+		t.Errorf("session %d was not closed by epoch -1", sessionID)
+	}
+}
+
+func TestFetchSessionCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewFetchSessionCache(2)
+	first := cache.create(nil)
+	second := cache.create(nil)
+	cache.get(first.id)
+	third := cache.create(nil)
+
+	if _, exists := cache.get(second.id); exists {
+		t.Errorf("least recently used session %d was not evicted", second.id)
+	}
+	for _, session := range []*fetchSession{first, third} {
+		if _, exists := cache.get(session.id); !exists {
+			t.Errorf("session %d was evicted", session.id)
+		}
+	}
+	if session := NewFetchSessionCache(0).create(nil); session != nil {
+		t.Errorf("cache without slots created session %d", session.id)
+	}
+}
+
+// fetchRequestV4 builds a Fetch v4 request, which names topics instead of using IDs
+func fetchRequestV4(topics []domain.FetchTopic) []byte {
+	w := common.NewKafkaWriter()
+	w.Int16(1)
+	w.Int16(4)
+	w.Int32(1)
+	w.String("consumer", false)
+	w.Int32(-1) // ReplicaID
+	w.Int32(0)  // MaxWaitMS
+	w.Int32(1)  // MinBytes
+	w.Int32(1 << 20)
+	w.Int8(0) // IsolationLevel
+	w.ArrayLength(len(topics), false)
+	for _, topic := range topics {
+		w.String(topic.Name, false)
+		w.ArrayLength(len(topic.Partitions), false)
+		for _, partition := range topic.Partitions {
+			w.Int32(partition.PartitionIndex)
+			w.Int64(partition.FetchOffset)
+			w.Int32(1 << 20)
+		}
+	}
+	return w.WithSizePrefix()
+}
+
+func TestFetchService_EveryTopicAndPartition(t *testing.T) {
+	pfr := partition_file_repository.NewPartitionFileRepositoryWithLogDir(t.TempDir())
+	for partitionIndex, values := range [][]string{{"a"}, {"b", "c"}} {
+		for _, value := range values {
+			batch := valueBatch(value)
+			if _, err := pfr.AppendRecordBatches(domain.AppendRequest{TopicName: "foo", PartitionIndex: partitionIndex, Records: batch}); err != nil {
+				t.Fatalf("AppendRecordBatches() error = %v", err)
+			}
+		}
+	}
+	parser := &capturingParser{KafkaProtocolParserFetch: infraparser.NewKafkaProtocolParserFetch()}
+	service := NewFetchService(parser, &mockMetadataRepository{}, pfr, nil, nil)
+
+	type wantPartition struct {
+		partitionIndex int32
+		errorCode      int16
+		highWatermark  int64
+	}
+	tests := []struct {
+		name string
+		data []byte
+		want [][]wantPartition // Per response topic
+	}{
+		{
+			name: "by topic ID",
+			data: fetchSessionRequestV16(0, -1, 1<<20, 0, 1, []domain.FetchPartition{
+				{PartitionIndex: 1, PartitionMaxBytes: 1 << 20},
+				{PartitionIndex: 0, PartitionMaxBytes: 1 << 20},
+				{PartitionIndex: 5, PartitionMaxBytes: 1 << 20},
+			}, nil),
+			want: [][]wantPartition{{
+				{partitionIndex: 1, highWatermark: 2},
+				{partitionIndex: 0, highWatermark: 1},
+				{partitionIndex: 5, errorCode: domain.ErrorCodeUnknownTopicOrPartition, highWatermark: -1},
+			}},
+		},
+		{
+			name: "by topic name",
+			data: fetchRequestV4([]domain.FetchTopic{
+				{Name: "foo", Partitions: []domain.FetchPartition{{PartitionIndex: 0}, {PartitionIndex: 1, FetchOffset: 1}}},
+				{Name: "bar", Partitions: []domain.FetchPartition{{PartitionIndex: 0}}},
+			}),
+			want: [][]wantPartition{
+				{{partitionIndex: 0, highWatermark: 1}, {partitionIndex: 1, highWatermark: 2}},
+				{{partitionIndex: 0, errorCode: domain.ErrorCodeUnknownTopicOrPartition, highWatermark: -1}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.HandleRequest(domain.Request{Data: tt.data}); err != nil {
+				t.Fatalf("HandleRequest() error = %v", err)
+			}
+			if len(parser.response.Topics) != len(tt.want) {
+				t.Fatalf("response has %d topics, want %d", len(parser.response.Topics), len(tt.want))
+			}
+			for i, topic := range parser.response.Topics {
+				if len(topic.Partitions) != len(tt.want[i]) {
+					t.Fatalf("topic %d has %d partitions, want %d", i, len(topic.Partitions), len(tt.want[i]))
+				}
+				for j, partition := range topic.Partitions {
+					want := tt.want[i][j]
+					if partition.PartitionIndex != want.partitionIndex || partition.ErrorCode != want.errorCode || partition.HighWatermark != want.highWatermark {
+						t.Errorf("topic %d partition %d = index %d, error %d, high watermark %d, want %+v",
+							i, j, partition.PartitionIndex, partition.ErrorCode, partition.HighWatermark, want)
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("unknown topic ID", func(t *testing.T) {
+		data := fetchSessionRequestV16(0, -1, 1<<20, 0, 1, []domain.FetchPartition{{PartitionIndex: 0}}, nil)
+		// Swap the test topic ID for an unknown one
+		data = bytes.Replace(data, testTopicID, bytes.Repeat([]byte{0x22}, 16), 1)
+		if _, err := service.HandleRequest(domain.Request{Data: data}); err != nil {
+			t.Fatalf("HandleRequest() error = %v", err)
+		}
+		if errorCode := parser.response.Topics[0].Partitions[0].ErrorCode; errorCode != domain.ErrorCodeUnknownTopicID {
+			t.Errorf("ErrorCode = %d, want %d", errorCode, domain.ErrorCodeUnknownTopicID)
+		}
+	})
+}
@@ -0,0 +1,203 @@
+package fetch_service
+
+import (
+	"container/list"
+	"math"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+)
+
+const (
+	// Session epochs defined by KIP-227
+	fetchSessionInitialEpoch int32 = 0  // Creates a new session with a full fetch
+	fetchSessionFinalEpoch   int32 = -1 // Closes the session, or asks for a sessionless fetch
+
+	// DefaultMaxFetchSessions matches Kafka's max.incremental.fetch.session.cache.slots
+	DefaultMaxFetchSessions = 1000
+)
+
+// topicPartition identifies a partition the way a Fetch request does: by topic name,
+// or from v13 by the hex encoded topic ID
+type topicPartition struct {
+	topic          string
+	partitionIndex int32
+}
+
+// cachedPartition is a partition of a fetch session: the latest fetch parameters the
+// client sent for it and the offsets last returned, used to leave unchanged partitions
+// out of incremental responses
+type cachedPartition struct {
+	topic            string
+	topicID          []byte
+	request          domain.FetchPartition
+	highWatermark    int64
+	lastStableOffset int64
+	logStartOffset   int64
+}
+
+func newCachedPartition(topic domain.FetchTopic, partition domain.FetchPartition) *cachedPartition {
+	return &cachedPartition{
+		topic:            topic.Name,
+		topicID:          topic.TopicNameBytes,
+		request:          partition,
+		highWatermark:    -1,
+		lastStableOffset: -1,
+		logStartOffset:   -1,
+	}
+}
+
+func (c *cachedPartition) key() topicPartition {
+	return topicPartition{topic: c.topic, partitionIndex: c.request.PartitionIndex}
+}
+
+// maybeUpdateResponseData records what is sent for the partition and reports whether an
+// incremental response must include it: it has records or an error, or one of its
+// offsets changed since the last response
+func (c *cachedPartition) maybeUpdateResponseData(partition *domain.FetchResponsePartition) bool {
+	mustRespond := len(partition.Records) > 0
+	if c.highWatermark != partition.HighWatermark {
+		c.highWatermark = partition.HighWatermark
+		mustRespond = true
+	}
+	if c.lastStableOffset != partition.LastStableOffset {
+		c.lastStableOffset = partition.LastStableOffset
+		mustRespond = true
+	}
+	if c.logStartOffset != partition.LogStartOffset {
+		c.logStartOffset = partition.LogStartOffset
+		mustRespond = true
+	}
+	if partition.ErrorCode != domain.ErrorCodeNone {
+		// Forget the high watermark so the partition is sent again once the error clears
+		c.highWatermark = -1
+		mustRespond = true
+	}
+	return mustRespond
+}
+
+// fetchSession is the server side of an incremental fetch session
+type fetchSession struct {
+	mu         sync.Mutex
+	id         int32
+	epoch      int32 // Epoch the next request in the session must carry
+	partitions []*cachedPartition
+	element    *list.Element // Position in the cache's LRU list
+}
+
+// update applies the partitions added or changed by an incremental request and removes
+// the forgotten ones
+func (s *fetchSession) update(requested []*cachedPartition, forgotten []domain.ForgottenTopic) {
+	index := make(map[topicPartition]*cachedPartition, len(s.partitions))
+	for _, partition := range s.partitions {
+		index[partition.key()] = partition
+	}
+
+	for _, partition := range requested {
+		if cached, exists := index[partition.key()]; exists {
+			cached.request = partition.request
+			continue
+		}
+		s.partitions = append(s.partitions, partition)
+		index[partition.key()] = partition
+	}
+
+	removed := make(map[topicPartition]bool)
+	for _, topic := range forgotten {
+		for _, partitionIndex := range topic.Partitions {
+			removed[topicPartition{topic: topic.Name, partitionIndex: partitionIndex}] = true
+		}
+	}
+	if len(removed) > 0 {
+		kept := s.partitions[:0]
+		for _, partition := range s.partitions {
+			if !removed[partition.key()] {
+				kept = append(kept, partition)
+			}
+		}
+		s.partitions = kept
+	}
+}
+
+// nextFetchSessionEpoch returns the epoch following epoch, wrapping around to 1
+func nextFetchSessionEpoch(epoch int32) int32 {
+	if epoch == math.MaxInt32 {
+		return 1
+	}
+	return epoch + 1
+}
+
+// FetchSessionCache holds the incremental fetch sessions of all clients, evicting the
+// least recently used session once maxSessions are open
+type FetchSessionCache struct {
+	mu          sync.Mutex
+	maxSessions int
+	sessions    map[int32]*fetchSession
+	lru         *list.List // Most recently used session at the front
+}
+
+func NewFetchSessionCache(maxSessions int) *FetchSessionCache {
+	return &FetchSessionCache{
+		maxSessions: maxSessions,
+		sessions:    make(map[int32]*fetchSession),
+		lru:         list.New(),
+	}
+}
+
+// create opens a session over partitions, or returns nil when the cache holds no sessions
+func (c *FetchSessionCache) create(partitions []*cachedPartition) *fetchSession {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.maxSessions <= 0 {
+		return nil
+	}
+	for len(c.sessions) >= c.maxSessions {
+		c.removeLocked(c.lru.Back().Value.(*fetchSession).id)
+	}
+
+	session := &fetchSession{
+		id:         c.newSessionID(),
+		epoch:      nextFetchSessionEpoch(fetchSessionInitialEpoch),
+		partitions: partitions,
+	}
+	session.element = c.lru.PushFront(session)
+	c.sessions[session.id] = session
+	return session
+}
+
+// newSessionID picks a random unused positive session ID; 0 means no session
+func (c *FetchSessionCache) newSessionID() int32 {
+	for {
+		id := rand.Int32N(math.MaxInt32-1) + 1
+		if _, exists := c.sessions[id]; !exists {
+			return id
+		}
+	}
+}
+
+// get returns the session with id and marks it as recently used
+func (c *FetchSessionCache) get(id int32) (*fetchSession, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	session, exists := c.sessions[id]
+	if exists {
+		c.lru.MoveToFront(session.element)
+	}
+	return session, exists
+}
+
+func (c *FetchSessionCache) remove(id int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removeLocked(id)
+}
+
+func (c *FetchSessionCache) removeLocked(id int32) {
+	if session, exists := c.sessions[id]; exists {
+		c.lru.Remove(session.element)
+		delete(c.sessions, id)
+	}
+}
@@ -0,0 +1,245 @@
+package group_coordinator_service
+
+import (
+	"cmp"
+	"encoding/hex"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+)
+
+// consumerProtocolType is the protocol type of the groups using the consumer rebalance
+// protocol, the same as the classic groups of Kafka consumers
+const consumerProtocolType = "consumer"
+
+// topicPartition is a partition of the topic with the hex encoded ID
+type topicPartition struct {
+	topicID   string
+	partition int32
+}
+
+// partitionSet is a set of partitions, as assigned to a member
+type partitionSet map[topicPartition]struct{}
+
+func partitionSetOf(topics []domain.ConsumerGroupTopicPartitions) partitionSet {
+	set := partitionSet{}
+	for _, topic := range topics {
+		for _, partition := range topic.Partitions {
+			set[topicPartition{topicID: hex.EncodeToString(topic.TopicID), partition: partition}] = struct{}{}
+		}
+	}
+	return set
+}
+
+func (s partitionSet) contains(tp topicPartition) bool {
+	_, exists := s[tp]
+	return exists
+}
+
+// intersect returns the partitions in both s and other
+func (s partitionSet) intersect(other partitionSet) partitionSet {
+	result := partitionSet{}
+	for tp := range s {
+		if other.contains(tp) {
+			result[tp] = struct{}{}
+		}
+	}
+	return result
+}
+
+// minus returns the partitions in s but not in other
+func (s partitionSet) minus(other partitionSet) partitionSet {
+	result := partitionSet{}
+	for tp := range s {
+		if !other.contains(tp) {
+			result[tp] = struct{}{}
+		}
+	}
+	return result
+}
+
+func (s partitionSet) sorted() []topicPartition {
+	return slices.SortedFunc(maps.Keys(s), func(a, b topicPartition) int {
+		if a.topicID != b.topicID {
+			return strings.Compare(a.topicID, b.topicID)
+		}
+		return cmp.Compare(a.partition, b.partition)
+	})
+}
+
+// byTopic lists the partitions topic by topic, the way the protocol carries them
+func (s partitionSet) byTopic() []domain.ConsumerGroupTopicPartitions {
+	topics := []domain.ConsumerGroupTopicPartitions{}
+	for _, tp := range s.sorted() {
+		if len(topics) == 0 || hex.EncodeToString(topics[len(topics)-1].TopicID) != tp.topicID {
+			topicID, _ := hex.DecodeString(tp.topicID)
+			topics = append(topics, domain.ConsumerGroupTopicPartitions{TopicID: topicID})
+		}
+		topic := &topics[len(topics)-1]
+		topic.Partitions = append(topic.Partitions, tp.partition)
+	}
+	return topics
+}
+
+// topicMetadata is what the assignors know of a topic
+type topicMetadata struct {
+	id         string // Hex encoded topic ID
+	name       string
+	partitions int32
+}
+
+// topicsOf returns the topics of the cluster metadata by name
+func topicsOf(clusterMetaData port_cluster_metadata_repository.ClusterMetadataRepositoryResponse) map[string]topicMetadata {
+	topics := make(map[string]topicMetadata, len(clusterMetaData.TopicNameTopicUuidMap))
+	for name, topicUuid := range clusterMetaData.TopicNameTopicUuidMap {
+		topics[name] = topicMetadata{
+			id:         topicUuid,
+			name:       name,
+			partitions: int32(len(clusterMetaData.TopicUUIDPartitionMetadataMap[topicUuid])),
+		}
+	}
+	return topics
+}
+
+// consumerMemberState tells how far a member got reconciling its assignment with the
+// target assignment of the group
+type consumerMemberState int
+
+const (
+	// consumerMemberStable owns its target assignment
+	consumerMemberStable consumerMemberState = iota
+	// consumerMemberUnrevokedPartitions must revoke partitions before going on
+	consumerMemberUnrevokedPartitions
+	// consumerMemberUnreleasedPartitions waits for other members to release partitions
+	// it is assigned
+	consumerMemberUnreleasedPartitions
+)
+
+// consumerMember is a member of a group using the consumer rebalance protocol
+type consumerMember struct {
+	memberID             string
+	instanceID           string
+	rackID               string
+	clientID             string
+	rebalanceTimeout     time.Duration
+	subscribedTopicNames []string
+	subscribedTopicRegex string
+	serverAssignor       string
+
+	memberEpoch         int32
+	previousMemberEpoch int32
+	state               consumerMemberState
+	assigned            partitionSet // Partitions the member owns
+	pendingRevocation   partitionSet // Partitions the member still has to give up
+
+	session    groupTimer // Fences the member when it stops heartbeating
+	revocation groupTimer // Fences the member when it doesn't revoke in time
+}
+
+// subscribes reports whether the member subscribed to the topic by name or regex
+func (m *consumerMember) subscribes(topic string, regex *regexp.Regexp) bool {
+	return slices.Contains(m.subscribedTopicNames, topic) || regex != nil && regex.MatchString(topic)
+}
+
+// consumerGroup is the state of a group using the consumer rebalance protocol. The
+// group epoch is bumped whenever the members or their subscriptions change; the target
+// assignment is then recomputed for that epoch and every member reconciles towards it.
+type consumerGroup struct {
+	groupEpoch       int32
+	assignmentEpoch  int32
+	assignorName     string
+	members          map[string]*consumerMember
+	staticMembers    map[string]string       // Instance ID to member ID
+	targetAssignment map[string]partitionSet // Member ID to its target partitions
+
+	// subscribedTopics are the subscribed topics the target assignment was computed from,
+	// by name
+	subscribedTopics map[string]topicMetadata
+}
+
+func newConsumerGroup() *consumerGroup {
+	return &consumerGroup{
+		members:          make(map[string]*consumerMember),
+		staticMembers:    make(map[string]string),
+		targetAssignment: make(map[string]partitionSet),
+		subscribedTopics: make(map[string]topicMetadata),
+	}
+}
+
+// state is the state of the group as ConsumerGroupDescribe reports it
+func (cg *consumerGroup) state() string {
+	if len(cg.members) == 0 {
+		return "Empty"
+	}
+	if cg.groupEpoch > cg.assignmentEpoch {
+		return "Assigning"
+	}
+	for _, m := range cg.members {
+		if m.memberEpoch != cg.assignmentEpoch || m.state != consumerMemberStable {
+			return "Reconciling"
+		}
+	}
+	return "Stable"
+}
+
+// subscriptionsOf returns the topics the members subscribe to, by name
+func (cg *consumerGroup) subscriptionsOf(topics map[string]topicMetadata) map[string]topicMetadata {
+	subscribed := map[string]topicMetadata{}
+	for _, m := range cg.members {
+		regex := compileSubscriptionRegex(m.subscribedTopicRegex)
+		for name, topic := range topics {
+			if m.subscribes(name, regex) {
+				subscribed[name] = topic
+			}
+		}
+	}
+	return subscribed
+}
+
+// ownedByOthers reports whether another member still owns the partition
+func (cg *consumerGroup) ownedByOthers(memberID string, tp topicPartition) bool {
+	for _, m := range cg.members {
+		if m.memberID != memberID && (m.assigned.contains(tp) || m.pendingRevocation.contains(tp)) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectAssignor picks the assignor most members asked for, the default one when none did
+func (cg *consumerGroup) selectAssignor() string {
+	votes := map[string]int{}
+	for _, m := range cg.members {
+		if m.serverAssignor != "" {
+			votes[m.serverAssignor]++
+		}
+	}
+	selected := ""
+	for _, name := range slices.Sorted(maps.Keys(votes)) {
+		if selected == "" || votes[name] > votes[selected] {
+			selected = name
+		}
+	}
+	if selected == "" {
+		return consumerGroupAssignors[0].name
+	}
+	return selected
+}
+
+// compileSubscriptionRegex compiles a subscription regex, which like in Kafka must match
+// the whole topic name. An empty or invalid regex subscribes to nothing.
+func compileSubscriptionRegex(regex string) *regexp.Regexp {
+	if regex == "" {
+		return nil
+	}
+	compiled, err := regexp.Compile("^(?:" + regex + ")$")
+	if err != nil {
+		return nil
+	}
+	return compiled
+}
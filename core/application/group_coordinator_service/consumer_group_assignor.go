@@ -0,0 +1,154 @@
+package group_coordinator_service
+
+import (
+	"maps"
+	"slices"
+)
+
+// consumerGroupAssignor computes the target assignment of a consumer group on the
+// broker: the partitions of the subscribed topics spread over the members subscribing
+// to them. current is the previous target assignment, which assignors may stick to.
+type consumerGroupAssignor struct {
+	name   string
+	assign func(members map[string]*consumerMember, topics map[string]topicMetadata, current map[string]partitionSet) map[string]partitionSet
+}
+
+// consumerGroupAssignors are the assignors members may ask for, the default one first
+var consumerGroupAssignors = []consumerGroupAssignor{
+	{name: "uniform", assign: assignUniform},
+	{name: "range", assign: assignRange},
+}
+
+func findConsumerGroupAssignor(name string) (consumerGroupAssignor, bool) {
+	for _, assignor := range consumerGroupAssignors {
+		if assignor.name == name {
+			return assignor, true
+		}
+	}
+	return consumerGroupAssignor{}, false
+}
+
+// eligibleTopics returns the topics each member subscribes to, by topic ID
+func eligibleTopics(members map[string]*consumerMember, topics map[string]topicMetadata) map[string]map[string]struct{} {
+	eligible := make(map[string]map[string]struct{}, len(members))
+	for memberID, m := range members {
+		regex := compileSubscriptionRegex(m.subscribedTopicRegex)
+		eligible[memberID] = map[string]struct{}{}
+		for name, topic := range topics {
+			if m.subscribes(name, regex) {
+				eligible[memberID][topic.id] = struct{}{}
+			}
+		}
+	}
+	return eligible
+}
+
+// assignUniform spreads the partitions so that the members subscribing to them own as
+// many as possible, give or take one. Members keep the partitions of the current
+// assignment they are still eligible for, so that a rebalance moves few partitions.
+func assignUniform(members map[string]*consumerMember, topics map[string]topicMetadata, current map[string]partitionSet) map[string]partitionSet {
+	memberIDs := slices.Sorted(maps.Keys(members))
+	eligible := eligibleTopics(members, topics)
+	partitionCounts := map[string]int32{}
+	for _, topic := range topics {
+		partitionCounts[topic.id] = topic.partitions
+	}
+	canOwn := func(memberID string, tp topicPartition) bool {
+		_, subscribed := eligible[memberID][tp.topicID]
+		return subscribed && tp.partition < partitionCounts[tp.topicID]
+	}
+
+	assignment := make(map[string]partitionSet, len(members))
+	taken := partitionSet{}
+	for _, memberID := range memberIDs {
+		assignment[memberID] = partitionSet{}
+		for _, tp := range current[memberID].sorted() {
+			if canOwn(memberID, tp) && !taken.contains(tp) {
+				assignment[memberID][tp] = struct{}{}
+				taken[tp] = struct{}{}
+			}
+		}
+	}
+
+	// Partitions nobody kept go to the least loaded member that can own them
+	unassigned := partitionSet{}
+	for _, topic := range topics {
+		for partition := int32(0); partition < topic.partitions; partition++ {
+			tp := topicPartition{topicID: topic.id, partition: partition}
+			if !taken.contains(tp) {
+				unassigned[tp] = struct{}{}
+			}
+		}
+	}
+	for _, tp := range unassigned.sorted() {
+		target := ""
+		for _, memberID := range memberIDs {
+			if canOwn(memberID, tp) && (target == "" || len(assignment[memberID]) < len(assignment[target])) {
+				target = memberID
+			}
+		}
+		if target != "" {
+			assignment[target][tp] = struct{}{}
+		}
+	}
+
+	// Kept partitions may leave members unbalanced; move partitions from the most to the
+	// least loaded members until no move narrows the gap
+	for moved := true; moved; {
+		moved = false
+		for _, from := range memberIDs {
+			for _, to := range memberIDs {
+				if len(assignment[from]) <= len(assignment[to])+1 {
+					continue
+				}
+				for _, tp := range slices.Backward(assignment[from].sorted()) {
+					if canOwn(to, tp) {
+						delete(assignment[from], tp)
+						assignment[to][tp] = struct{}{}
+						moved = true
+						break
+					}
+				}
+			}
+		}
+	}
+	return assignment
+}
+
+// assignRange hands the members subscribing to a topic contiguous ranges of its
+// partitions, the members sorted by ID and the first ones getting one more partition
+// when they don't divide evenly
+func assignRange(members map[string]*consumerMember, topics map[string]topicMetadata, _ map[string]partitionSet) map[string]partitionSet {
+	memberIDs := slices.Sorted(maps.Keys(members))
+	eligible := eligibleTopics(members, topics)
+
+	assignment := make(map[string]partitionSet, len(members))
+	for _, memberID := range memberIDs {
+		assignment[memberID] = partitionSet{}
+	}
+	for _, topic := range topics {
+		subscribers := []string{}
+		for _, memberID := range memberIDs {
+			if _, subscribed := eligible[memberID][topic.id]; subscribed {
+				subscribers = append(subscribers, memberID)
+			}
+		}
+		if len(subscribers) == 0 {
+			continue
+		}
+		perMember := topic.partitions / int32(len(subscribers))
+		extra := topic.partitions % int32(len(subscribers))
+		next := int32(0)
+		for i, memberID := range subscribers {
+			count := perMember
+			if int32(i) < extra {
+				count++
+			}
+			for partition := next; partition < next+count; partition++ {
+				assignment[memberID][topicPartition{topicID: topic.id, partition: partition}] = struct{}{}
+			}
+			next += count
+		}
+	}
+	return assignment
+}
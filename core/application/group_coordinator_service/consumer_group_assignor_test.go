@@ -0,0 +1,96 @@
+package group_coordinator_service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func assignorMembers(subscriptions map[string][]string) map[string]*consumerMember {
+	members := map[string]*consumerMember{}
+	for memberID, topics := range subscriptions {
+		members[memberID] = &consumerMember{memberID: memberID, subscribedTopicNames: topics}
+	}
+	return members
+}
+
+func assignorTopics() map[string]topicMetadata {
+	return map[string]topicMetadata{
+		"orders":   {id: "aa", name: "orders", partitions: 4},
+		"payments": {id: "bb", name: "payments", partitions: 3},
+	}
+}
+
+func partitionsOf(topicID string, partitions ...int32) partitionSet {
+	set := partitionSet{}
+	for _, partition := range partitions {
+		set[topicPartition{topicID: topicID, partition: partition}] = struct{}{}
+	}
+	return set
+}
+
+func TestAssignRange(t *testing.T) {
+	members := assignorMembers(map[string][]string{
+		"member-a": {"orders", "payments"},
+		"member-b": {"orders", "payments"},
+		"member-c": {"orders"},
+	})
+	got := assignRange(members, assignorTopics(), nil)
+
+	union := func(sets ...partitionSet) partitionSet {
+		result := partitionSet{}
+		for _, set := range sets {
+			for tp := range set {
+				result[tp] = struct{}{}
+			}
+		}
+		return result
+	}
+	want := map[string]partitionSet{
+		"member-a": union(partitionsOf("aa", 0, 1), partitionsOf("bb", 0, 1)),
+		"member-b": union(partitionsOf("aa", 2), partitionsOf("bb", 2)),
+		"member-c": partitionsOf("aa", 3),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("assignRange() = %v, want %v", got, want)
+	}
+}
+
+func TestAssignUniform(t *testing.T) {
+	topics := map[string]topicMetadata{"orders": {id: "aa", name: "orders", partitions: 6}}
+
+	// The partitions of a member leaving spread over the others, who keep theirs
+	current := map[string]partitionSet{
+		"member-a": partitionsOf("aa", 0, 1),
+		"member-b": partitionsOf("aa", 2, 3),
+		"member-c": partitionsOf("aa", 4, 5),
+	}
+	got := assignUniform(assignorMembers(map[string][]string{"member-a": {"orders"}, "member-b": {"orders"}}), topics, current)
+	want := map[string]partitionSet{
+		"member-a": partitionsOf("aa", 0, 1, 4),
+		"member-b": partitionsOf("aa", 2, 3, 5),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("assignUniform() after a member left = %v, want %v", got, want)
+	}
+
+	// A new member takes partitions from the most loaded ones
+	got = assignUniform(assignorMembers(map[string][]string{"member-a": {"orders"}, "member-b": {"orders"}, "member-d": {"orders"}}), topics, want)
+	for memberID, partitions := range got {
+		if len(partitions) != 2 {
+			t.Errorf("assignUniform() gave %s %v, want 2 partitions each", memberID, partitions)
+		}
+	}
+	if len(got["member-a"].intersect(want["member-a"])) != 2 || len(got["member-b"].intersect(want["member-b"])) != 2 {
+		t.Errorf("assignUniform() = %v, want the members to keep 2 of their partitions", got)
+	}
+
+	// Members only get partitions of the topics they subscribe to
+	got = assignUniform(assignorMembers(map[string][]string{"member-a": {"orders"}, "member-b": {"payments"}}), assignorTopics(), nil)
+	want = map[string]partitionSet{
+		"member-a": partitionsOf("aa", 0, 1, 2, 3),
+		"member-b": partitionsOf("bb", 0, 1, 2),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("assignUniform() with different subscriptions = %v, want %v", got, want)
+	}
+}
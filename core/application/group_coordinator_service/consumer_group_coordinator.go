@@ -0,0 +1,471 @@
+package group_coordinator_service
+
+import (
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+)
+
+// consumerGroupHeartbeatFirstClientMemberIDVersion is the first ConsumerGroupHeartbeat
+// version whose members generate their member ID before joining
+const consumerGroupHeartbeatFirstClientMemberIDVersion = 1
+
+// ConsumerGroupHeartbeat joins, updates or removes the member of a group using the
+// consumer rebalance protocol. A member joining or changing its subscription bumps the
+// group epoch, which has the target assignment recomputed; the response then carries the
+// partitions the member may own on its way there.
+func (c *GroupCoordinator) ConsumerGroupHeartbeat(req *domain.ParsedRequestConsumerGroupHeartbeat, clusterMetaData port_cluster_metadata_repository.ClusterMetadataRepositoryResponse) domain.ResponseDataConsumerGroupHeartbeat {
+	if errorCode, message := validateConsumerGroupHeartbeat(req); errorCode != domain.ErrorCodeNone {
+		return consumerGroupHeartbeatError(errorCode, message)
+	}
+
+	g := c.group(req.GroupID, req.MemberEpoch == domain.ConsumerGroupJoinEpoch)
+	if g == nil {
+		return consumerGroupHeartbeatError(domain.ErrorCodeGroupIDNotFound, fmt.Sprintf("Group %s not found.", req.GroupID))
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.state == groupStateDead {
+		return consumerGroupHeartbeatError(domain.ErrorCodeCoordinatorNotAvailable, "")
+	}
+	if g.consumer == nil {
+		// Only a classic group without members may switch to the consumer protocol
+		if !g.isEmpty() || len(g.pendingMembers) > 0 || req.MemberEpoch != domain.ConsumerGroupJoinEpoch {
+			return consumerGroupHeartbeatError(domain.ErrorCodeGroupIDNotFound, fmt.Sprintf("Group %s is not a consumer group.", req.GroupID))
+		}
+		g.consumer = newConsumerGroup()
+		g.protocolType = consumerProtocolType
+	}
+	if req.MemberEpoch < 0 {
+		return c.consumerGroupLeave(g, req)
+	}
+
+	cg := g.consumer
+	m, joined, errorCode, message := c.consumerGroupMember(g, req)
+	if errorCode != domain.ErrorCodeNone {
+		return consumerGroupHeartbeatError(errorCode, message)
+	}
+	bumpGroupEpoch := m.update(req) || joined
+	if subscribed := cg.subscriptionsOf(topicsOf(clusterMetaData)); !maps.Equal(subscribed, cg.subscribedTopics) {
+		cg.subscribedTopics = subscribed
+		bumpGroupEpoch = true
+	}
+	if bumpGroupEpoch {
+		cg.groupEpoch++
+	}
+	if cg.groupEpoch > cg.assignmentEpoch {
+		cg.computeTargetAssignment()
+	}
+
+	previouslyAssigned := maps.Clone(m.assigned)
+	c.reconcile(g, m, req.TopicPartitions)
+	c.scheduleConsumerGroupSession(g, m)
+
+	response := domain.ResponseDataConsumerGroupHeartbeat{
+		ErrorCode:           domain.ErrorCodeNone,
+		MemberID:            m.memberID,
+		MemberEpoch:         m.memberEpoch,
+		HeartbeatIntervalMs: int32(c.consumerGroupHeartbeatInterval.Milliseconds()),
+	}
+	// The members send every field when (re)joining or recovering from an error, and then
+	// expect their whole assignment back
+	fullRequest := req.MemberEpoch == domain.ConsumerGroupJoinEpoch ||
+		req.RebalanceTimeoutMs != -1 && req.SubscribedTopicNames != nil && req.TopicPartitions != nil
+	if fullRequest || !maps.Equal(previouslyAssigned, m.assigned) {
+		response.Assignment = m.assigned.byTopic()
+	}
+	return response
+}
+
+func validateConsumerGroupHeartbeat(req *domain.ParsedRequestConsumerGroupHeartbeat) (int16, string) {
+	if req.GroupID == "" {
+		return domain.ErrorCodeInvalidRequest, "GroupId can't be empty."
+	}
+	if req.MemberID == "" && (req.APIVersion >= consumerGroupHeartbeatFirstClientMemberIDVersion || req.MemberEpoch != domain.ConsumerGroupJoinEpoch) {
+		return domain.ErrorCodeInvalidRequest, "MemberId can't be empty."
+	}
+	switch {
+	case req.MemberEpoch == domain.ConsumerGroupJoinEpoch:
+		if req.RebalanceTimeoutMs == -1 {
+			return domain.ErrorCodeInvalidRequest, "RebalanceTimeoutMs must be provided in first request."
+		}
+		if req.TopicPartitions == nil || len(req.TopicPartitions) > 0 {
+			return domain.ErrorCodeInvalidRequest, "TopicPartitions must be empty when (re-)joining."
+		}
+		if req.SubscribedTopicNames == nil && req.SubscribedTopicRegex == nil {
+			return domain.ErrorCodeInvalidRequest, "SubscribedTopicNames or SubscribedTopicRegex must be set in first request."
+		}
+	case req.MemberEpoch == domain.ConsumerGroupStaticLeaveEpoch:
+		if req.InstanceID == "" {
+			return domain.ErrorCodeInvalidRequest, "InstanceId can't be null."
+		}
+	case req.MemberEpoch < domain.ConsumerGroupStaticLeaveEpoch:
+		return domain.ErrorCodeInvalidRequest, "MemberEpoch is invalid."
+	}
+
+	if req.ServerAssignor != "" {
+		if _, exists := findConsumerGroupAssignor(req.ServerAssignor); !exists {
+			names := make([]string, 0, len(consumerGroupAssignors))
+			for _, assignor := range consumerGroupAssignors {
+				names = append(names, assignor.name)
+			}
+			return domain.ErrorCodeUnsupportedAssignor, fmt.Sprintf("ServerAssignor %s is not supported. Supported assignors: %s.", req.ServerAssignor, strings.Join(names, ", "))
+		}
+	}
+	if req.SubscribedTopicRegex != nil && *req.SubscribedTopicRegex != "" {
+		if _, err := regexp.Compile(*req.SubscribedTopicRegex); err != nil {
+			return domain.ErrorCodeInvalidRegularExpression, fmt.Sprintf("SubscribedTopicRegex %s is not a valid regular expression: %v.", *req.SubscribedTopicRegex, err)
+		}
+	}
+	return domain.ErrorCodeNone, ""
+}
+
+func consumerGroupHeartbeatError(errorCode int16, message string) domain.ResponseDataConsumerGroupHeartbeat {
+	return domain.ResponseDataConsumerGroupHeartbeat{
+		ErrorCode:    errorCode,
+		ErrorMessage: message,
+	}
+}
+
+// consumerGroupMember returns the member the heartbeat comes from, and whether it just
+// joined. A static member rejoining takes the place, and the assignment, of its previous
+// incarnation once that one left.
+func (c *GroupCoordinator) consumerGroupMember(g *group, req *domain.ParsedRequestConsumerGroupHeartbeat) (*consumerMember, bool, int16, string) {
+	cg := g.consumer
+	if req.MemberEpoch == domain.ConsumerGroupJoinEpoch {
+		memberID := req.MemberID
+		if memberID == "" {
+			memberID = newMemberID(req.ClientID)
+		}
+		if previousID, exists := cg.staticMembers[req.InstanceID]; exists && req.InstanceID != "" {
+			previous := cg.members[previousID]
+			if previous.memberEpoch != domain.ConsumerGroupStaticLeaveEpoch {
+				return nil, false, domain.ErrorCodeUnreleasedInstanceID, fmt.Sprintf("Static member %s with instance id %s is not released yet.", previousID, req.InstanceID)
+			}
+			return cg.replaceStaticMember(previous, memberID), false, domain.ErrorCodeNone, ""
+		}
+		if m, exists := cg.members[memberID]; exists {
+			errorCode, message := m.validateEpoch(req.MemberEpoch, req.TopicPartitions)
+			return m, false, errorCode, message
+		}
+		m := &consumerMember{
+			memberID:            memberID,
+			instanceID:          req.InstanceID,
+			previousMemberEpoch: -1,
+			assigned:            partitionSet{},
+			pendingRevocation:   partitionSet{},
+		}
+		cg.members[memberID] = m
+		if m.instanceID != "" {
+			cg.staticMembers[m.instanceID] = memberID
+		}
+		return m, true, domain.ErrorCodeNone, ""
+	}
+
+	m, exists := cg.members[req.MemberID]
+	if !exists {
+		return nil, false, domain.ErrorCodeUnknownMemberID, fmt.Sprintf("Member %s is not a member of group %s.", req.MemberID, g.groupID)
+	}
+	if req.InstanceID != "" && cg.staticMembers[req.InstanceID] != m.memberID {
+		return nil, false, domain.ErrorCodeFencedInstanceID, fmt.Sprintf("Static member %s with instance id %s was replaced.", req.MemberID, req.InstanceID)
+	}
+	errorCode, message := m.validateEpoch(req.MemberEpoch, req.TopicPartitions)
+	return m, false, errorCode, message
+}
+
+// validateEpoch fences a member whose epoch differs from the one it has on the broker.
+// A member may lag one epoch behind, as long as it doesn't own partitions it lost
+// meanwhile: its response moving it to the current epoch may have been lost.
+func (m *consumerMember) validateEpoch(memberEpoch int32, owned []domain.ConsumerGroupTopicPartitions) (int16, string) {
+	if memberEpoch > m.memberEpoch {
+		return domain.ErrorCodeFencedMemberEpoch, fmt.Sprintf("The consumer group member has a greater member epoch (%d) than the one known by the group coordinator (%d). The member must abandon all its partitions and rejoin.", memberEpoch, m.memberEpoch)
+	}
+	if memberEpoch < m.memberEpoch && (memberEpoch != m.previousMemberEpoch || owned == nil || len(partitionSetOf(owned).minus(m.assigned)) > 0) {
+		return domain.ErrorCodeFencedMemberEpoch, fmt.Sprintf("The consumer group member has a smaller member epoch (%d) than the one known by the group coordinator (%d). The member must abandon all its partitions and rejoin.", memberEpoch, m.memberEpoch)
+	}
+	return domain.ErrorCodeNone, ""
+}
+
+// update applies the fields of the heartbeat that changed and reports whether the
+// member's subscription did
+func (m *consumerMember) update(req *domain.ParsedRequestConsumerGroupHeartbeat) bool {
+	m.clientID = req.ClientID
+	if req.RackID != "" {
+		m.rackID = req.RackID
+	}
+	if req.RebalanceTimeoutMs != -1 {
+		m.rebalanceTimeout = time.Duration(req.RebalanceTimeoutMs) * time.Millisecond
+	}
+
+	changed := false
+	if req.ServerAssignor != "" && req.ServerAssignor != m.serverAssignor {
+		m.serverAssignor = req.ServerAssignor
+		changed = true
+	}
+	if req.SubscribedTopicNames != nil {
+		names := slices.Sorted(slices.Values(req.SubscribedTopicNames))
+		if !slices.Equal(names, m.subscribedTopicNames) {
+			m.subscribedTopicNames = names
+			changed = true
+		}
+	}
+	if req.SubscribedTopicRegex != nil && *req.SubscribedTopicRegex != m.subscribedTopicRegex {
+		m.subscribedTopicRegex = *req.SubscribedTopicRegex
+		changed = true
+	}
+	return changed
+}
+
+// replaceStaticMember hands the place of a static member that left to its new
+// incarnation, which keeps the partitions and picks up at the epoch it left at
+func (cg *consumerGroup) replaceStaticMember(previous *consumerMember, memberID string) *consumerMember {
+	previous.session.stop()
+	previous.revocation.stop()
+	delete(cg.members, previous.memberID)
+
+	m := &consumerMember{
+		memberID:             memberID,
+		instanceID:           previous.instanceID,
+		rackID:               previous.rackID,
+		clientID:             previous.clientID,
+		rebalanceTimeout:     previous.rebalanceTimeout,
+		subscribedTopicNames: previous.subscribedTopicNames,
+		subscribedTopicRegex: previous.subscribedTopicRegex,
+		serverAssignor:       previous.serverAssignor,
+		memberEpoch:          previous.previousMemberEpoch,
+		previousMemberEpoch:  -1,
+		state:                previous.state,
+		assigned:             previous.assigned,
+		pendingRevocation:    previous.pendingRevocation,
+	}
+	cg.members[memberID] = m
+	cg.staticMembers[m.instanceID] = memberID
+	if target, exists := cg.targetAssignment[previous.memberID]; exists {
+		delete(cg.targetAssignment, previous.memberID)
+		cg.targetAssignment[memberID] = target
+	}
+	return m
+}
+
+// computeTargetAssignment assigns the subscribed partitions for the group epoch with the
+// assignor the members asked for
+func (cg *consumerGroup) computeTargetAssignment() {
+	cg.assignorName = cg.selectAssignor()
+	assignor, _ := findConsumerGroupAssignor(cg.assignorName)
+	cg.targetAssignment = assignor.assign(cg.members, cg.subscribedTopics, cg.targetAssignment)
+	cg.assignmentEpoch = cg.groupEpoch
+}
+
+// reconcile moves the member towards its target assignment. A member first revokes the
+// partitions it lost, staying at its epoch until it reports them gone, and is fenced if
+// it doesn't within its rebalance timeout. It then moves to the assignment epoch with the
+// partitions it keeps, and gets the new ones as soon as other members release them.
+func (c *GroupCoordinator) reconcile(g *group, m *consumerMember, owned []domain.ConsumerGroupTopicPartitions) {
+	cg := g.consumer
+	switch m.state {
+	case consumerMemberStable:
+		if m.memberEpoch == cg.assignmentEpoch {
+			return
+		}
+	case consumerMemberUnrevokedPartitions:
+		if owned == nil || len(partitionSetOf(owned).minus(m.assigned)) > 0 {
+			return
+		}
+		m.pendingRevocation = partitionSet{}
+		m.revocation.stop()
+	}
+
+	target := cg.targetAssignment[m.memberID]
+	assigned := m.assigned.intersect(target)
+	revoking := m.assigned.minus(target)
+	if len(revoking) > 0 && (owned == nil || len(partitionSetOf(owned).intersect(revoking)) > 0) {
+		m.state = consumerMemberUnrevokedPartitions
+		m.assigned = assigned
+		m.pendingRevocation = revoking
+		m.revocation.reset(g, m.rebalanceTimeout, func() {
+			if g.consumer != nil && g.consumer.members[m.memberID] == m && m.state == consumerMemberUnrevokedPartitions {
+				fmt.Printf("Group %s: member %s did not revoke its partitions in time\n", g.groupID, m.memberID)
+				c.removeConsumerGroupMember(g, m)
+			}
+		})
+		return
+	}
+
+	m.state = consumerMemberStable
+	for tp := range target.minus(assigned) {
+		if cg.ownedByOthers(m.memberID, tp) {
+			m.state = consumerMemberUnreleasedPartitions
+			continue
+		}
+		assigned[tp] = struct{}{}
+	}
+	m.assigned = assigned
+	m.pendingRevocation = partitionSet{}
+	if m.memberEpoch != cg.assignmentEpoch {
+		m.previousMemberEpoch = m.memberEpoch
+		m.memberEpoch = cg.assignmentEpoch
+	}
+}
+
+// scheduleConsumerGroupSession (re)starts the session of m
+func (c *GroupCoordinator) scheduleConsumerGroupSession(g *group, m *consumerMember) {
+	m.session.reset(g, c.consumerGroupSessionTimeout, func() {
+		if g.consumer != nil && g.consumer.members[m.memberID] == m {
+			fmt.Printf("Group %s: session of member %s timed out\n", g.groupID, m.memberID)
+			c.removeConsumerGroupMember(g, m)
+		}
+	})
+}
+
+// removeConsumerGroupMember removes m and bumps the group epoch so that its partitions
+// are assigned to the other members
+func (c *GroupCoordinator) removeConsumerGroupMember(g *group, m *consumerMember) {
+	cg := g.consumer
+	m.session.stop()
+	m.revocation.stop()
+	delete(cg.members, m.memberID)
+	delete(cg.targetAssignment, m.memberID)
+	if m.instanceID != "" && cg.staticMembers[m.instanceID] == m.memberID {
+		delete(cg.staticMembers, m.instanceID)
+	}
+	cg.groupEpoch++
+	if len(cg.members) == 0 {
+		// The retention of the group's offsets counts from now
+		g.transitionTo(groupStateEmpty)
+	}
+}
+
+// consumerGroupLeave removes the member, or for a static member leaving for now (-2)
+// keeps its partitions for its next incarnation until its session expires
+func (c *GroupCoordinator) consumerGroupLeave(g *group, req *domain.ParsedRequestConsumerGroupHeartbeat) domain.ResponseDataConsumerGroupHeartbeat {
+	cg := g.consumer
+	if req.InstanceID != "" {
+		current, exists := cg.staticMembers[req.InstanceID]
+		if !exists {
+			return consumerGroupHeartbeatError(domain.ErrorCodeUnknownMemberID, fmt.Sprintf("Instance id %s is unknown.", req.InstanceID))
+		}
+		if current != req.MemberID {
+			return consumerGroupHeartbeatError(domain.ErrorCodeFencedInstanceID, fmt.Sprintf("Static member %s with instance id %s was replaced.", req.MemberID, req.InstanceID))
+		}
+	}
+	m, exists := cg.members[req.MemberID]
+	if !exists {
+		return consumerGroupHeartbeatError(domain.ErrorCodeUnknownMemberID, fmt.Sprintf("Member %s is not a member of group %s.", req.MemberID, g.groupID))
+	}
+
+	if req.MemberEpoch == domain.ConsumerGroupStaticLeaveEpoch {
+		m.revocation.stop()
+		if m.memberEpoch != domain.ConsumerGroupStaticLeaveEpoch {
+			m.previousMemberEpoch = m.memberEpoch
+			m.memberEpoch = domain.ConsumerGroupStaticLeaveEpoch
+		}
+		c.scheduleConsumerGroupSession(g, m)
+	} else {
+		c.removeConsumerGroupMember(g, m)
+	}
+	return domain.ResponseDataConsumerGroupHeartbeat{
+		ErrorCode:           domain.ErrorCodeNone,
+		MemberID:            req.MemberID,
+		MemberEpoch:         req.MemberEpoch,
+		HeartbeatIntervalMs: int32(c.consumerGroupHeartbeatInterval.Milliseconds()),
+	}
+}
+
+// validateMemberEpoch checks that memberID is a member at memberEpoch, for committing or
+// fetching offsets. Outside of any epoch (-1) only a group without members is accepted.
+func (cg *consumerGroup) validateMemberEpoch(memberID string, memberEpoch int32) int16 {
+	if memberEpoch < 0 && len(cg.members) == 0 {
+		return domain.ErrorCodeNone
+	}
+	m, exists := cg.members[memberID]
+	if !exists {
+		return domain.ErrorCodeUnknownMemberID
+	}
+	if memberEpoch != m.memberEpoch {
+		return domain.ErrorCodeStaleMemberEpoch
+	}
+	return domain.ErrorCodeNone
+}
+
+// DescribeConsumerGroups describes the groups using the consumer rebalance protocol,
+// naming their topics after the cluster metadata
+func (c *GroupCoordinator) DescribeConsumerGroups(groupIDs []string, clusterMetaData port_cluster_metadata_repository.ClusterMetadataRepositoryResponse) []domain.ConsumerGroupDescription {
+	topicNames := map[string]string{}
+	for name, topic := range topicsOf(clusterMetaData) {
+		topicNames[topic.id] = name
+	}
+
+	descriptions := make([]domain.ConsumerGroupDescription, 0, len(groupIDs))
+	for _, groupID := range groupIDs {
+		descriptions = append(descriptions, c.describeConsumerGroup(groupID, topicNames))
+	}
+	return descriptions
+}
+
+func (c *GroupCoordinator) describeConsumerGroup(groupID string, topicNames map[string]string) domain.ConsumerGroupDescription {
+	description := domain.ConsumerGroupDescription{
+		ErrorCode: domain.ErrorCodeNone,
+		GroupID:   groupID,
+		Members:   []domain.ConsumerGroupMemberDescription{},
+	}
+	g := c.group(groupID, false)
+	if g == nil {
+		description.ErrorCode = domain.ErrorCodeGroupIDNotFound
+		description.ErrorMessage = fmt.Sprintf("Group %s not found.", groupID)
+		return description
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.state == groupStateDead {
+		description.ErrorCode = domain.ErrorCodeGroupIDNotFound
+		description.ErrorMessage = fmt.Sprintf("Group %s not found.", groupID)
+		return description
+	}
+	if g.consumer == nil {
+		description.ErrorCode = domain.ErrorCodeGroupIDNotFound
+		description.ErrorMessage = fmt.Sprintf("Group %s is not a consumer group.", groupID)
+		return description
+	}
+
+	cg := g.consumer
+	description.GroupState = cg.state()
+	description.GroupEpoch = cg.groupEpoch
+	description.AssignmentEpoch = cg.assignmentEpoch
+	description.AssignorName = cg.assignorName
+	for _, memberID := range slices.Sorted(maps.Keys(cg.members)) {
+		m := cg.members[memberID]
+		description.Members = append(description.Members, domain.ConsumerGroupMemberDescription{
+			MemberID:             m.memberID,
+			InstanceID:           m.instanceID,
+			RackID:               m.rackID,
+			MemberEpoch:          m.memberEpoch,
+			ClientID:             m.clientID,
+			SubscribedTopicNames: slices.Clone(m.subscribedTopicNames),
+			SubscribedTopicRegex: m.subscribedTopicRegex,
+			Assignment:           describedPartitions(m.assigned, topicNames),
+			TargetAssignment:     describedPartitions(cg.targetAssignment[memberID], topicNames),
+		})
+	}
+	return description
+}
+
+func describedPartitions(partitions partitionSet, topicNames map[string]string) []domain.ConsumerGroupDescribedPartitions {
+	described := []domain.ConsumerGroupDescribedPartitions{}
+	for _, topic := range partitions.byTopic() {
+		described = append(described, domain.ConsumerGroupDescribedPartitions{
+			TopicID:    topic.TopicID,
+			TopicName:  topicNames[hex.EncodeToString(topic.TopicID)],
+			Partitions: topic.Partitions,
+		})
+	}
+	return described
+}
@@ -0,0 +1,343 @@
+package group_coordinator_service
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/cluster_metadata_repository"
+)
+
+var (
+	ordersID   = bytes.Repeat([]byte{0xaa}, 16)
+	paymentsID = bytes.Repeat([]byte{0xbb}, 16)
+)
+
+// testClusterMetadata has orders with 4 partitions and payments with 2
+func testClusterMetadata() port_cluster_metadata_repository.ClusterMetadataRepositoryResponse {
+	return port_cluster_metadata_repository.ClusterMetadataRepositoryResponse{
+		TopicNameTopicUuidMap: map[string]string{
+			"orders":   hex.EncodeToString(ordersID),
+			"payments": hex.EncodeToString(paymentsID),
+		},
+		TopicUUIDPartitionMetadataMap: map[string][]*domain.PartitionMetadata{
+			hex.EncodeToString(ordersID):   make([]*domain.PartitionMetadata, 4),
+			hex.EncodeToString(paymentsID): make([]*domain.PartitionMetadata, 2),
+		},
+	}
+}
+
+func orders(partitions ...int32) []domain.ConsumerGroupTopicPartitions {
+	if len(partitions) == 0 {
+		return []domain.ConsumerGroupTopicPartitions{}
+	}
+	return []domain.ConsumerGroupTopicPartitions{{TopicID: ordersID, Partitions: partitions}}
+}
+
+func consumerJoinRequest(memberID string, topics ...string) *domain.ParsedRequestConsumerGroupHeartbeat {
+	return &domain.ParsedRequestConsumerGroupHeartbeat{
+		APIVersion:           1,
+		ClientID:             "consumer",
+		GroupID:              "group-a",
+		MemberID:             memberID,
+		MemberEpoch:          domain.ConsumerGroupJoinEpoch,
+		RebalanceTimeoutMs:   10000,
+		SubscribedTopicNames: topics,
+		TopicPartitions:      []domain.ConsumerGroupTopicPartitions{},
+	}
+}
+
+func consumerHeartbeatRequest(memberID string, memberEpoch int32, owned []domain.ConsumerGroupTopicPartitions) *domain.ParsedRequestConsumerGroupHeartbeat {
+	return &domain.ParsedRequestConsumerGroupHeartbeat{
+		APIVersion:         1,
+		ClientID:           "consumer",
+		GroupID:            "group-a",
+		MemberID:           memberID,
+		MemberEpoch:        memberEpoch,
+		RebalanceTimeoutMs: -1,
+		TopicPartitions:    owned,
+	}
+}
+
+func consumerHeartbeat(t *testing.T, c *GroupCoordinator, req *domain.ParsedRequestConsumerGroupHeartbeat) domain.ResponseDataConsumerGroupHeartbeat {
+	t.Helper()
+	response := c.ConsumerGroupHeartbeat(req, testClusterMetadata())
+	if response.ErrorCode != domain.ErrorCodeNone {
+		t.Fatalf("ConsumerGroupHeartbeat(%s, epoch %d) = %d %q, want none", req.MemberID, req.MemberEpoch, response.ErrorCode, response.ErrorMessage)
+	}
+	return response
+}
+
+func expectAssignment(t *testing.T, response domain.ResponseDataConsumerGroupHeartbeat, epoch int32, want []domain.ConsumerGroupTopicPartitions) {
+	t.Helper()
+	if response.MemberEpoch != epoch || !reflect.DeepEqual(response.Assignment, want) {
+		t.Errorf("heartbeat of %s = epoch %d assignment %v, want epoch %d assignment %v", response.MemberID, response.MemberEpoch, response.Assignment, epoch, want)
+	}
+}
+
+func TestGroupCoordinator_ConsumerGroupHeartbeatValidation(t *testing.T) {
+	regex := "orders("
+	tests := []struct {
+		name      string
+		modify    func(req *domain.ParsedRequestConsumerGroupHeartbeat)
+		errorCode int16
+	}{
+		{"empty group", func(req *domain.ParsedRequestConsumerGroupHeartbeat) { req.GroupID = "" }, domain.ErrorCodeInvalidRequest},
+		{"empty member ID", func(req *domain.ParsedRequestConsumerGroupHeartbeat) { req.MemberID = "" }, domain.ErrorCodeInvalidRequest},
+		{"no rebalance timeout", func(req *domain.ParsedRequestConsumerGroupHeartbeat) { req.RebalanceTimeoutMs = -1 }, domain.ErrorCodeInvalidRequest},
+		{"owned partitions", func(req *domain.ParsedRequestConsumerGroupHeartbeat) { req.TopicPartitions = orders(0) }, domain.ErrorCodeInvalidRequest},
+		{"no subscription", func(req *domain.ParsedRequestConsumerGroupHeartbeat) { req.SubscribedTopicNames = nil }, domain.ErrorCodeInvalidRequest},
+		{"static leave without instance", func(req *domain.ParsedRequestConsumerGroupHeartbeat) { req.MemberEpoch = -2 }, domain.ErrorCodeInvalidRequest},
+		{"invalid epoch", func(req *domain.ParsedRequestConsumerGroupHeartbeat) { req.MemberEpoch = -3 }, domain.ErrorCodeInvalidRequest},
+		{"unknown assignor", func(req *domain.ParsedRequestConsumerGroupHeartbeat) { req.ServerAssignor = "sticky" }, domain.ErrorCodeUnsupportedAssignor},
+		{"invalid regex", func(req *domain.ParsedRequestConsumerGroupHeartbeat) { req.SubscribedTopicRegex = &regex }, domain.ErrorCodeInvalidRegularExpression},
+		{"unknown group", func(req *domain.ParsedRequestConsumerGroupHeartbeat) { req.MemberEpoch = 1 }, domain.ErrorCodeGroupIDNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := consumerJoinRequest("member-a", "orders")
+			tt.modify(req)
+			response := newTestCoordinator().ConsumerGroupHeartbeat(req, testClusterMetadata())
+			if response.ErrorCode != tt.errorCode || response.ErrorMessage == "" {
+				t.Errorf("ConsumerGroupHeartbeat() = %d %q, want %d with a message", response.ErrorCode, response.ErrorMessage, tt.errorCode)
+			}
+		})
+	}
+}
+
+func TestGroupCoordinator_ConsumerGroupReconciliation(t *testing.T) {
+	c := newTestCoordinator()
+
+	expectAssignment(t, consumerHeartbeat(t, c, consumerJoinRequest("member-a", "orders")), 1, orders(0, 1, 2, 3))
+
+	// The new member waits for partitions the first one still owns
+	expectAssignment(t, consumerHeartbeat(t, c, consumerJoinRequest("member-b", "orders")), 2, orders())
+
+	// The first member revokes the partitions it lost before moving to the new epoch
+	expectAssignment(t, consumerHeartbeat(t, c, consumerHeartbeatRequest("member-a", 1, orders(0, 1, 2, 3))), 1, orders(0, 1))
+	response := consumerHeartbeat(t, c, consumerHeartbeatRequest("member-a", 1, orders(0, 1, 2, 3)))
+	if response.MemberEpoch != 1 || response.Assignment != nil {
+		t.Errorf("heartbeat still owning revoked partitions = %+v, want epoch 1 and no new assignment", response)
+	}
+	response = consumerHeartbeat(t, c, consumerHeartbeatRequest("member-a", 1, orders(0, 1)))
+	if response.MemberEpoch != 2 || response.Assignment != nil {
+		t.Errorf("heartbeat after revoking = %+v, want epoch 2 with the assignment unchanged", response)
+	}
+
+	// Once released, the partitions go to the new member
+	expectAssignment(t, consumerHeartbeat(t, c, consumerHeartbeatRequest("member-b", 2, orders())), 2, orders(2, 3))
+
+	description := c.DescribeConsumerGroups([]string{"group-a"}, testClusterMetadata())[0]
+	if description.GroupState != "Stable" || description.GroupEpoch != 2 || description.AssignmentEpoch != 2 || description.AssignorName != "uniform" {
+		t.Errorf("DescribeConsumerGroups() = %+v, want a stable group at epoch 2", description)
+	}
+	wantMembers := []domain.ConsumerGroupMemberDescription{
+		{
+			MemberID: "member-a", MemberEpoch: 2, ClientID: "consumer", SubscribedTopicNames: []string{"orders"},
+			Assignment:       []domain.ConsumerGroupDescribedPartitions{{TopicID: ordersID, TopicName: "orders", Partitions: []int32{0, 1}}},
+			TargetAssignment: []domain.ConsumerGroupDescribedPartitions{{TopicID: ordersID, TopicName: "orders", Partitions: []int32{0, 1}}},
+		},
+		{
+			MemberID: "member-b", MemberEpoch: 2, ClientID: "consumer", SubscribedTopicNames: []string{"orders"},
+			Assignment:       []domain.ConsumerGroupDescribedPartitions{{TopicID: ordersID, TopicName: "orders", Partitions: []int32{2, 3}}},
+			TargetAssignment: []domain.ConsumerGroupDescribedPartitions{{TopicID: ordersID, TopicName: "orders", Partitions: []int32{2, 3}}},
+		},
+	}
+	if !reflect.DeepEqual(description.Members, wantMembers) {
+		t.Errorf("described members = %+v, want %+v", description.Members, wantMembers)
+	}
+
+	// A member leaving hands its partitions to the others
+	left := consumerHeartbeat(t, c, consumerHeartbeatRequest("member-b", domain.ConsumerGroupLeaveEpoch, nil))
+	if left.MemberID != "member-b" || left.MemberEpoch != domain.ConsumerGroupLeaveEpoch {
+		t.Errorf("leave response = %+v, want the member and the leave epoch", left)
+	}
+	expectAssignment(t, consumerHeartbeat(t, c, consumerHeartbeatRequest("member-a", 2, orders(0, 1))), 3, orders(0, 1, 2, 3))
+}
+
+func TestGroupCoordinator_ConsumerGroupFencing(t *testing.T) {
+	c := newTestCoordinator()
+	consumerHeartbeat(t, c, consumerJoinRequest("member-a", "orders"))
+	consumerHeartbeat(t, c, consumerJoinRequest("member-b", "orders"))
+	consumerHeartbeat(t, c, consumerHeartbeatRequest("member-a", 1, orders(0, 1, 2, 3)))
+
+	tests := []struct {
+		name      string
+		req       *domain.ParsedRequestConsumerGroupHeartbeat
+		errorCode int16
+	}{
+		{"epoch ahead", consumerHeartbeatRequest("member-a", 5, orders(0, 1)), domain.ErrorCodeFencedMemberEpoch},
+		{"unknown member", consumerHeartbeatRequest("member-c", 2, orders()), domain.ErrorCodeUnknownMemberID},
+	}
+	for _, tt := range tests {
+		if response := c.ConsumerGroupHeartbeat(tt.req, testClusterMetadata()); response.ErrorCode != tt.errorCode {
+			t.Errorf("%s: ConsumerGroupHeartbeat() = %d %q, want %d", tt.name, response.ErrorCode, response.ErrorMessage, tt.errorCode)
+		}
+	}
+
+	// A member whose response got lost heartbeats one epoch behind
+	consumerHeartbeat(t, c, consumerHeartbeatRequest("member-b", 2, orders()))
+	expectAssignment(t, consumerHeartbeat(t, c, consumerHeartbeatRequest("member-a", 1, orders(0, 1))), 2, nil)
+	consumerHeartbeat(t, c, consumerHeartbeatRequest("member-a", 1, orders(0, 1)))
+	if response := c.ConsumerGroupHeartbeat(consumerHeartbeatRequest("member-a", 1, orders(0, 1, 2)), testClusterMetadata()); response.ErrorCode != domain.ErrorCodeFencedMemberEpoch {
+		t.Errorf("heartbeat one epoch behind owning revoked partitions = %d, want FENCED_MEMBER_EPOCH", response.ErrorCode)
+	}
+
+	// Classic members can't join a consumer group with members
+	if response := await(t, join(c, joinRequest("", "", "range"))); response.ErrorCode != domain.ErrorCodeInconsistentGroupProtocol {
+		t.Errorf("JoinGroup of a consumer group = %d, want INCONSISTENT_GROUP_PROTOCOL", response.ErrorCode)
+	}
+}
+
+func TestGroupCoordinator_ConsumerGroupStaticMember(t *testing.T) {
+	c := newTestCoordinator()
+	join := consumerJoinRequest("member-a", "orders")
+	join.InstanceID = "instance-a"
+	consumerHeartbeat(t, c, join)
+
+	rejoin := consumerJoinRequest("member-a2", "orders")
+	rejoin.InstanceID = "instance-a"
+	if response := c.ConsumerGroupHeartbeat(rejoin, testClusterMetadata()); response.ErrorCode != domain.ErrorCodeUnreleasedInstanceID {
+		t.Errorf("join of an active instance = %d, want UNRELEASED_INSTANCE_ID", response.ErrorCode)
+	}
+
+	leave := consumerHeartbeatRequest("member-a", domain.ConsumerGroupStaticLeaveEpoch, nil)
+	leave.InstanceID = "instance-a"
+	consumerHeartbeat(t, c, leave)
+
+	// The next incarnation takes over the assignment without a new group epoch
+	expectAssignment(t, consumerHeartbeat(t, c, rejoin), 1, orders(0, 1, 2, 3))
+	if description := c.DescribeConsumerGroups([]string{"group-a"}, testClusterMetadata())[0]; description.GroupEpoch != 1 || len(description.Members) != 1 {
+		t.Errorf("DescribeConsumerGroups() = %+v, want the replaced member alone at epoch 1", description)
+	}
+}
+
+func TestGroupCoordinator_ConsumerGroupRegexSubscription(t *testing.T) {
+	c := newTestCoordinator()
+	regex := "pay.*"
+	req := consumerJoinRequest("member-a")
+	req.SubscribedTopicNames = nil
+	req.SubscribedTopicRegex = &regex
+	req.ServerAssignor = "range"
+	want := []domain.ConsumerGroupTopicPartitions{{TopicID: paymentsID, Partitions: []int32{0, 1}}}
+	expectAssignment(t, consumerHeartbeat(t, c, req), 1, want)
+
+	// The regex must match the whole topic name
+	regex = "order"
+	subscribe := consumerHeartbeatRequest("member-a", 1, want)
+	subscribe.SubscribedTopicRegex = &regex
+	response := consumerHeartbeat(t, c, subscribe)
+	if response.MemberEpoch != 1 || !reflect.DeepEqual(response.Assignment, []domain.ConsumerGroupTopicPartitions{}) {
+		t.Errorf("heartbeat of a regex matching no topic = %+v, want payments revoked", response)
+	}
+}
+
+func TestGroupCoordinator_ConsumerGroupTimeouts(t *testing.T) {
+	c := newTestCoordinator(WithConsumerGroupSessionTimeout(50*time.Millisecond, 10*time.Millisecond))
+	consumerHeartbeat(t, c, consumerJoinRequest("member-a", "orders"))
+	if response := consumerHeartbeat(t, c, consumerJoinRequest("member-b", "orders")); response.HeartbeatIntervalMs != 10 {
+		t.Errorf("heartbeat interval = %d, want 10", response.HeartbeatIntervalMs)
+	}
+
+	// member-b keeps heartbeating while member-a goes silent, and gets its partitions
+	epoch, owned := int32(2), orders()
+	for deadline := time.Now().Add(150 * time.Millisecond); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		response := consumerHeartbeat(t, c, consumerHeartbeatRequest("member-b", epoch, owned))
+		epoch = response.MemberEpoch
+		if response.Assignment != nil {
+			owned = response.Assignment
+		}
+	}
+	if epoch != 3 || !reflect.DeepEqual(owned, orders(0, 1, 2, 3)) {
+		t.Errorf("member-b after the session of member-a expired = epoch %d owning %v, want epoch 3 owning every partition", epoch, owned)
+	}
+
+	// A member that doesn't revoke within its rebalance timeout is fenced
+	c = newTestCoordinator()
+	join := consumerJoinRequest("member-a", "orders")
+	join.RebalanceTimeoutMs = 20
+	consumerHeartbeat(t, c, join)
+	consumerHeartbeat(t, c, consumerJoinRequest("member-b", "orders"))
+	consumerHeartbeat(t, c, consumerHeartbeatRequest("member-a", 1, orders(0, 1, 2, 3)))
+	time.Sleep(60 * time.Millisecond)
+	if response := c.ConsumerGroupHeartbeat(consumerHeartbeatRequest("member-a", 1, orders(0, 1)), testClusterMetadata()); response.ErrorCode != domain.ErrorCodeUnknownMemberID {
+		t.Errorf("heartbeat after the rebalance timeout = %d, want UNKNOWN_MEMBER_ID", response.ErrorCode)
+	}
+}
+
+func TestGroupCoordinator_ConsumerGroupOffsets(t *testing.T) {
+	c := newTestCoordinator()
+	consumerHeartbeat(t, c, consumerJoinRequest("member-a", "orders"))
+
+	if errorCode := commit(c, "group-a", "member-a", 0, 10); errorCode != domain.ErrorCodeStaleMemberEpoch {
+		t.Errorf("commit of an old epoch = %d, want STALE_MEMBER_EPOCH", errorCode)
+	}
+	if errorCode := commit(c, "group-a", "", -1, 10); errorCode != domain.ErrorCodeUnknownMemberID {
+		t.Errorf("commit outside of the group = %d, want UNKNOWN_MEMBER_ID", errorCode)
+	}
+	if errorCode := commit(c, "group-a", "member-a", 1, 42); errorCode != domain.ErrorCodeNone {
+		t.Errorf("commit of the member = %d, want none", errorCode)
+	}
+	if fetched := c.FetchOffsets(domain.OffsetFetchGroup{GroupID: "group-a", MemberID: "member-a", MemberEpoch: 0}); fetched.ErrorCode != domain.ErrorCodeStaleMemberEpoch {
+		t.Errorf("fetch of an old epoch = %d, want STALE_MEMBER_EPOCH", fetched.ErrorCode)
+	}
+	if got := fetchAll(c, "group-a"); got[orders0] != 42 {
+		t.Errorf("committed offsets = %v, want orders-0 at 42", got)
+	}
+
+	// Once the last member left, the offsets expire from then on
+	consumerHeartbeat(t, c, consumerHeartbeatRequest("member-a", domain.ConsumerGroupLeaveEpoch, nil))
+	c.expireOffsets(time.Now().Add(DefaultOffsetsRetention))
+	if got := fetchAll(c, "group-a"); len(got) != 0 {
+		t.Errorf("offsets of the empty group past the retention = %v, want them removed", got)
+	}
+}
+
+func TestConsumerGroupDescribeService_HandleRequest(t *testing.T) {
+	metadata := cluster_metadata_repository.NewClusterMetadataRepository(
+		cluster_metadata_repository.WithMetadataLogDir(filepath.Join(t.TempDir(), "__cluster_metadata-0")))
+	coordinator := newTestCoordinator()
+	consumerHeartbeat(t, coordinator, consumerJoinRequest("member-a", "orders"))
+	commit(coordinator, "classic", "", -1, 10)
+
+	mockParser := &mockConsumerGroupDescribeParser{request: &domain.ParsedRequestConsumerGroupDescribe{
+		GroupIDs:                    []string{"group-a", "classic", "unknown"},
+		IncludeAuthorizedOperations: true,
+	}}
+	if _, err := NewConsumerGroupDescribeService(mockParser, metadata, coordinator).HandleRequest(domain.Request{}); err != nil {
+		t.Fatalf("HandleRequest() error = %v", err)
+	}
+
+	groups := mockParser.response.Groups
+	if len(groups) != 3 {
+		t.Fatalf("described %d groups, want 3", len(groups))
+	}
+	if groups[0].ErrorCode != domain.ErrorCodeNone || groups[0].GroupState != "Stable" || groups[0].AuthorizedOperations != groupAuthorizedOperations {
+		t.Errorf("consumer group = %+v, want it stable with its operations", groups[0])
+	}
+	for _, group := range groups[1:] {
+		if group.ErrorCode != domain.ErrorCodeGroupIDNotFound || group.AuthorizedOperations != math.MinInt32 {
+			t.Errorf("group %s = %+v, want GROUP_ID_NOT_FOUND", group.GroupID, group)
+		}
+	}
+}
+
+type mockConsumerGroupDescribeParser struct {
+	request  *domain.ParsedRequestConsumerGroupDescribe
+	response *domain.ResponseDataConsumerGroupDescribe
+}
+
+func (m *mockConsumerGroupDescribeParser) ParseRequest(data []byte) (*domain.ParsedRequestConsumerGroupDescribe, error) {
+	return m.request, nil
+}
+
+func (m *mockConsumerGroupDescribeParser) EncodeResponse(response *domain.ResponseDataConsumerGroupDescribe) ([]byte, error) {
+	m.response = response
+	return []byte{}, nil
+}
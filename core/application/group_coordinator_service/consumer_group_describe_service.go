@@ -0,0 +1,66 @@
+package group_coordinator_service
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+)
+
+// Authorized operations of a group: READ (3), DESCRIBE (8) and DELETE (6), as bits of the
+// operation codes. Without an authorizer every operation is allowed.
+const groupAuthorizedOperations int32 = 1<<3 | 1<<6 | 1<<8
+
+// ConsumerGroupDescribeService implements the driving port for ConsumerGroupDescribe
+// requests
+type ConsumerGroupDescribeService struct {
+	parser              parser.ConsumerGroupDescribeParser
+	metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository
+	coordinator         *GroupCoordinator
+}
+
+func NewConsumerGroupDescribeService(parser parser.ConsumerGroupDescribeParser, metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository, coordinator *GroupCoordinator) driving.KafkaHandler {
+	return &ConsumerGroupDescribeService{
+		parser:              parser,
+		metadata_repository: metadata_repository,
+		coordinator:         coordinator,
+	}
+}
+
+func (s *ConsumerGroupDescribeService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	clusterMetaData, err := s.metadata_repository.GetClusterMetadata()
+	if err != nil {
+		fmt.Println("ConsumerGroupDescribe: there is no cluster metadata", err.Error())
+	}
+
+	responseData := &domain.ResponseDataConsumerGroupDescribe{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		Groups:         s.coordinator.DescribeConsumerGroups(parsedReq.GroupIDs, clusterMetaData),
+	}
+	for i := range responseData.Groups {
+		// INT32_MIN tells the client the operations were not requested
+		responseData.Groups[i].AuthorizedOperations = math.MinInt32
+		if parsedReq.IncludeAuthorizedOperations && responseData.Groups[i].ErrorCode == domain.ErrorCodeNone {
+			responseData.Groups[i].AuthorizedOperations = groupAuthorizedOperations
+		}
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
@@ -0,0 +1,53 @@
+package group_coordinator_service
+
+import (
+	"fmt"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+)
+
+// ConsumerGroupHeartbeatService implements the driving port for ConsumerGroupHeartbeat
+// requests
+type ConsumerGroupHeartbeatService struct {
+	parser              parser.ConsumerGroupHeartbeatParser
+	metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository
+	coordinator         *GroupCoordinator
+}
+
+func NewConsumerGroupHeartbeatService(parser parser.ConsumerGroupHeartbeatParser, metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository, coordinator *GroupCoordinator) driving.KafkaHandler {
+	return &ConsumerGroupHeartbeatService{
+		parser:              parser,
+		metadata_repository: metadata_repository,
+		coordinator:         coordinator,
+	}
+}
+
+func (s *ConsumerGroupHeartbeatService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	// Without metadata the members subscribe to no topic yet
+	clusterMetaData, err := s.metadata_repository.GetClusterMetadata()
+	if err != nil {
+		fmt.Println("ConsumerGroupHeartbeat: there is no cluster metadata", err.Error())
+	}
+
+	responseData := s.coordinator.ConsumerGroupHeartbeat(parsedReq, clusterMetaData)
+	responseData.CorrelationID = parsedReq.CorrelationID
+	responseData.APIVersion = parsedReq.APIVersion
+	responseData.ThrottleTimeMs = 0
+
+	encodedResponse, err := s.parser.EncodeResponse(&responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
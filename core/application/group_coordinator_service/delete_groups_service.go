@@ -0,0 +1,43 @@
+package group_coordinator_service
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// DeleteGroupsService implements the driving port for DeleteGroups requests
+type DeleteGroupsService struct {
+	parser      parser.DeleteGroupsParser
+	coordinator *GroupCoordinator
+}
+
+func NewDeleteGroupsService(parser parser.DeleteGroupsParser, coordinator *GroupCoordinator) driving.KafkaHandler {
+	return &DeleteGroupsService{
+		parser:      parser,
+		coordinator: coordinator,
+	}
+}
+
+func (s *DeleteGroupsService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	responseData := &domain.ResponseDataDeleteGroups{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		Results:        s.coordinator.DeleteGroups(parsedReq.GroupsNames),
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
@@ -0,0 +1,52 @@
+package group_coordinator_service
+
+import (
+	"math"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// DescribeGroupsService implements the driving port for DescribeGroups requests
+type DescribeGroupsService struct {
+	parser      parser.DescribeGroupsParser
+	coordinator *GroupCoordinator
+}
+
+func NewDescribeGroupsService(parser parser.DescribeGroupsParser, coordinator *GroupCoordinator) driving.KafkaHandler {
+	return &DescribeGroupsService{
+		parser:      parser,
+		coordinator: coordinator,
+	}
+}
+
+func (s *DescribeGroupsService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	responseData := &domain.ResponseDataDescribeGroups{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		Groups:         s.coordinator.DescribeGroups(parsedReq.Groups),
+	}
+	for i := range responseData.Groups {
+		// INT32_MIN tells the client the operations were not requested
+		responseData.Groups[i].AuthorizedOperations = math.MinInt32
+		if parsedReq.IncludeAuthorizedOperations && responseData.Groups[i].ErrorCode == domain.ErrorCodeNone {
+			responseData.Groups[i].AuthorizedOperations = groupAuthorizedOperations
+		}
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
@@ -0,0 +1,74 @@
+package group_coordinator_service
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// FindCoordinatorService implements the driving port for FindCoordinator requests. This
+// broker coordinates every group and transactional ID, so it always answers with itself.
+type FindCoordinatorService struct {
+	parser       parser.FindCoordinatorParser
+	brokerConfig domain.BrokerConfig
+}
+
+func NewFindCoordinatorService(parser parser.FindCoordinatorParser, brokerConfig domain.BrokerConfig) driving.KafkaHandler {
+	return &FindCoordinatorService{
+		parser:       parser,
+		brokerConfig: brokerConfig,
+	}
+}
+
+func (s *FindCoordinatorService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	responseData := &domain.ResponseDataFindCoordinator{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		Coordinators:   make([]domain.Coordinator, 0, len(parsedReq.CoordinatorKeys)),
+	}
+	for _, key := range parsedReq.CoordinatorKeys {
+		responseData.Coordinators = append(responseData.Coordinators, s.findCoordinator(parsedReq.KeyType, key))
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
+
+func (s *FindCoordinatorService) findCoordinator(keyType int8, key string) domain.Coordinator {
+	switch {
+	case keyType != domain.CoordinatorKeyTypeGroup && keyType != domain.CoordinatorKeyTypeTransaction:
+		return coordinatorError(key, domain.ErrorCodeInvalidRequest, "Invalid coordinator type.")
+	case keyType == domain.CoordinatorKeyTypeTransaction && key == "":
+		return coordinatorError(key, domain.ErrorCodeInvalidRequest, "Transactional ID must not be empty.")
+	}
+	return domain.Coordinator{
+		Key:       key,
+		NodeID:    s.brokerConfig.NodeID,
+		Host:      s.brokerConfig.Host,
+		Port:      s.brokerConfig.Port,
+		ErrorCode: domain.ErrorCodeNone,
+	}
+}
+
+func coordinatorError(key string, errorCode int16, message string) domain.Coordinator {
+	return domain.Coordinator{
+		Key:          key,
+		NodeID:       -1,
+		Host:         "",
+		Port:         -1,
+		ErrorCode:    errorCode,
+		ErrorMessage: message,
+	}
+}
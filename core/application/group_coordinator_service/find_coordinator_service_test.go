@@ -0,0 +1,54 @@
+package group_coordinator_service
+
+import (
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+)
+
+// mockFindCoordinatorParser hands the service a fixed request and captures the response
+type mockFindCoordinatorParser struct {
+	request  *domain.ParsedRequestFindCoordinator
+	response *domain.ResponseDataFindCoordinator
+}
+
+func (m *mockFindCoordinatorParser) ParseRequest(data []byte) (*domain.ParsedRequestFindCoordinator, error) {
+	return m.request, nil
+}
+
+func (m *mockFindCoordinatorParser) EncodeResponse(response *domain.ResponseDataFindCoordinator) ([]byte, error) {
+	m.response = response
+	return []byte{}, nil
+}
+
+func TestFindCoordinatorService_HandleRequest(t *testing.T) {
+	brokerConfig := domain.BrokerConfig{Broker: domain.Broker{NodeID: 1, Host: "localhost", Port: 9092}}
+
+	tests := []struct {
+		name    string
+		keyType int8
+		keys    []string
+		want    []int16
+	}{
+		{name: "groups", keyType: domain.CoordinatorKeyTypeGroup, keys: []string{"group-a", "group-b"}, want: []int16{domain.ErrorCodeNone, domain.ErrorCodeNone}},
+		{name: "transactional IDs", keyType: domain.CoordinatorKeyTypeTransaction, keys: []string{"txn", ""}, want: []int16{domain.ErrorCodeNone, domain.ErrorCodeInvalidRequest}},
+		{name: "unknown key type", keyType: 5, keys: []string{"group-a"}, want: []int16{domain.ErrorCodeInvalidRequest}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &mockFindCoordinatorParser{request: &domain.ParsedRequestFindCoordinator{APIVersion: 4, KeyType: tt.keyType, CoordinatorKeys: tt.keys}}
+			if _, err := NewFindCoordinatorService(parser, brokerConfig).HandleRequest(domain.Request{}); err != nil {
+				t.Fatalf("HandleRequest() error = %v", err)
+			}
+
+			for i, coordinator := range parser.response.Coordinators {
+				if coordinator.Key != tt.keys[i] || coordinator.ErrorCode != tt.want[i] {
+					t.Errorf("coordinator %d = %+v, want key %q with error %d", i, coordinator, tt.keys[i], tt.want[i])
+				}
+				if coordinator.ErrorCode == domain.ErrorCodeNone && (coordinator.NodeID != 1 || coordinator.Host != "localhost" || coordinator.Port != 9092) {
+					t.Errorf("coordinator %d = %+v, want localhost:9092", i, coordinator)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package group_coordinator_service
+
+import (
+	"bytes"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+)
+
+// groupState is the state of a group in the classic rebalance protocol
+type groupState int
+
+const (
+	// groupStateEmpty has no members; it may still have committed offsets
+	groupStateEmpty groupState = iota
+	// groupStatePreparingRebalance waits for the members to rejoin
+	groupStatePreparingRebalance
+	// groupStateCompletingRebalance waits for the leader's assignment
+	groupStateCompletingRebalance
+	// groupStateStable has every member working on its assignment
+	groupStateStable
+	// groupStateDead was removed from the coordinator
+	groupStateDead
+)
+
+func (s groupState) String() string {
+	switch s {
+	case groupStateEmpty:
+		return "Empty"
+	case groupStatePreparingRebalance:
+		return "PreparingRebalance"
+	case groupStateCompletingRebalance:
+		return "CompletingRebalance"
+	case groupStateStable:
+		return "Stable"
+	default:
+		return "Dead"
+	}
+}
+
+// groupTimer runs a callback under its group's lock. Stopping or resetting the timer
+// turns a callback that already fired but is still waiting for the lock into a no-op.
+type groupTimer struct {
+	timer *time.Timer
+	seq   int
+}
+
+// reset must be called with g.mu held
+func (t *groupTimer) reset(g *group, d time.Duration, fn func()) {
+	t.stop()
+	seq := t.seq
+	t.timer = time.AfterFunc(d, func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if t.seq == seq {
+			fn()
+		}
+	})
+}
+
+// stop must be called with g.mu held
+func (t *groupTimer) stop() {
+	t.seq++
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
+
+// member is a member of a group. A member with a groupInstanceID is static: it keeps
+// its place in the group across restarts of the client.
+type member struct {
+	memberID         string
+	groupInstanceID  string
+	clientID         string
+	sessionTimeout   time.Duration
+	rebalanceTimeout time.Duration
+	protocolType     string
+	protocols        []domain.JoinGroupProtocol
+	assignment       []byte
+
+	// awaitingJoin and awaitingSync answer the member's parked JoinGroup and SyncGroup
+	awaitingJoin func(domain.ResponseDataJoinGroup)
+	awaitingSync func(domain.ResponseDataSyncGroup)
+
+	heartbeat groupTimer // Expires the member when its session times out
+}
+
+// metadata returns the member's metadata for protocol
+func (m *member) metadata(protocol string) []byte {
+	for _, p := range m.protocols {
+		if p.Name == protocol {
+			return p.Metadata
+		}
+	}
+	return nil
+}
+
+func (m *member) supports(protocol string) bool {
+	return slices.ContainsFunc(m.protocols, func(p domain.JoinGroupProtocol) bool { return p.Name == protocol })
+}
+
+// matches reports whether the member joined with exactly these protocols
+func (m *member) matches(protocols []domain.JoinGroupProtocol) bool {
+	return slices.EqualFunc(m.protocols, protocols, func(a, b domain.JoinGroupProtocol) bool {
+		return a.Name == b.Name && bytes.Equal(a.Metadata, b.Metadata)
+	})
+}
+
+// group is a consumer group managed with the classic rebalance protocol, or with the
+// consumer rebalance protocol once consumer is set. Every field is guarded by mu.
+type group struct {
+	mu             sync.Mutex
+	groupID        string
+	state          groupState
+	stateTimestamp time.Time // When the group entered its state
+	protocolType   string
+	protocolName   string // Protocol selected for the current generation
+	generationID   int32
+	leaderID       string
+
+	members        map[string]*member
+	staticMembers  map[string]string      // Group instance ID to member ID
+	pendingMembers map[string]*groupTimer // Member IDs handed out that didn't join yet
+	pendingSync    map[string]struct{}    // Members that didn't sync this generation yet
+
+	// rebalanceTimer bounds how long a rebalance waits for joins and, once completing,
+	// for syncs. During the initial delay of a new group it keeps extending while
+	// members keep arriving.
+	rebalanceTimer groupTimer
+	initialDelay   bool
+	newMemberAdded bool
+
+	consumer *consumerGroup // Set while the group uses the consumer rebalance protocol
+
+	offsets map[domain.TopicPartition]domain.CommittedOffset
+}
+
+func newGroup(groupID string) *group {
+	return &group{
+		groupID:        groupID,
+		state:          groupStateEmpty,
+		stateTimestamp: time.Now(),
+		members:        make(map[string]*member),
+		staticMembers:  make(map[string]string),
+		pendingMembers: make(map[string]*groupTimer),
+		pendingSync:    make(map[string]struct{}),
+		offsets:        make(map[domain.TopicPartition]domain.CommittedOffset),
+	}
+}
+
+func (g *group) transitionTo(state groupState) {
+	g.state = state
+	g.stateTimestamp = time.Now()
+}
+
+// isEmpty reports whether the group has no members under either protocol
+func (g *group) isEmpty() bool {
+	return g.state == groupStateEmpty && (g.consumer == nil || len(g.consumer.members) == 0)
+}
+
+// add makes m a member, the leader when there is none
+func (g *group) add(m *member) {
+	if len(g.members) == 0 {
+		g.protocolType = m.protocolType
+	}
+	g.members[m.memberID] = m
+	if m.groupInstanceID != "" {
+		g.staticMembers[m.groupInstanceID] = m.memberID
+	}
+	if g.leaderID == "" {
+		g.leaderID = m.memberID
+	}
+}
+
+// remove drops m from the group, answering its parked requests with UNKNOWN_MEMBER_ID,
+// and elects a new leader when m led the group
+func (g *group) remove(m *member) {
+	m.heartbeat.stop()
+	if m.awaitingJoin != nil {
+		m.awaitingJoin(joinError(m.memberID, domain.ErrorCodeUnknownMemberID))
+		m.awaitingJoin = nil
+	}
+	if m.awaitingSync != nil {
+		m.awaitingSync(domain.ResponseDataSyncGroup{ErrorCode: domain.ErrorCodeUnknownMemberID})
+		m.awaitingSync = nil
+	}
+	delete(g.members, m.memberID)
+	delete(g.pendingSync, m.memberID)
+	if m.groupInstanceID != "" && g.staticMembers[m.groupInstanceID] == m.memberID {
+		delete(g.staticMembers, m.groupInstanceID)
+	}
+	if g.leaderID == m.memberID {
+		g.leaderID = g.electLeader()
+	}
+}
+
+// electLeader prefers a member that rejoined the current rebalance
+func (g *group) electLeader() string {
+	memberIDs := slices.Sorted(maps.Keys(g.members))
+	for _, memberID := range memberIDs {
+		if g.members[memberID].awaitingJoin != nil {
+			return memberID
+		}
+	}
+	if len(memberIDs) > 0 {
+		return memberIDs[0]
+	}
+	return ""
+}
+
+// validateMember checks that memberID is a member and, for static members, that it is
+// the current incarnation of groupInstanceID
+func (g *group) validateMember(memberID string, groupInstanceID string) int16 {
+	if groupInstanceID != "" {
+		if current, exists := g.staticMembers[groupInstanceID]; exists && current != memberID {
+			return domain.ErrorCodeFencedInstanceID
+		}
+	}
+	if _, exists := g.members[memberID]; !exists {
+		return domain.ErrorCodeUnknownMemberID
+	}
+	return domain.ErrorCodeNone
+}
+
+// allMembersJoined reports whether every member rejoined the rebalance
+func (g *group) allMembersJoined() bool {
+	if len(g.pendingMembers) > 0 {
+		return false
+	}
+	for _, m := range g.members {
+		if m.awaitingJoin == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// supportsProtocols reports whether a member joining with protocolType and protocols
+// can be part of the group: it must share the group's protocol type and at least one
+// protocol with every member
+func (g *group) supportsProtocols(protocolType string, protocols []domain.JoinGroupProtocol) bool {
+	if protocolType == "" || len(protocols) == 0 {
+		return false
+	}
+	if len(g.members) == 0 {
+		return true
+	}
+	if protocolType != g.protocolType {
+		return false
+	}
+	for _, protocol := range protocols {
+		if g.supportedByAll(protocol.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *group) supportedByAll(protocol string) bool {
+	for _, m := range g.members {
+		if !m.supports(protocol) {
+			return false
+		}
+	}
+	return true
+}
+
+// selectProtocol picks the protocol of the next generation: among the protocols every
+// member supports, the one most members prefer
+func (g *group) selectProtocol() string {
+	votes := map[string]int{}
+	for _, m := range g.members {
+		for _, protocol := range m.protocols {
+			if g.supportedByAll(protocol.Name) {
+				votes[protocol.Name]++
+				break
+			}
+		}
+	}
+	selected := ""
+	for _, protocol := range slices.Sorted(maps.Keys(votes)) {
+		if selected == "" || votes[protocol] > votes[selected] {
+			selected = protocol
+		}
+	}
+	return selected
+}
+
+// rebalanceTimeout is the longest rebalance timeout of the members
+func (g *group) rebalanceTimeout() time.Duration {
+	timeout := time.Duration(0)
+	for _, m := range g.members {
+		timeout = max(timeout, m.rebalanceTimeout)
+	}
+	return timeout
+}
+
+// joinResponse is the JoinGroup response of m for the current generation. Only the
+// leader gets the members and their metadata to compute the assignment with.
+func (g *group) joinResponse(m *member) domain.ResponseDataJoinGroup {
+	response := domain.ResponseDataJoinGroup{
+		ErrorCode:    domain.ErrorCodeNone,
+		GenerationID: g.generationID,
+		ProtocolType: g.protocolType,
+		ProtocolName: g.protocolName,
+		Leader:       g.leaderID,
+		MemberID:     m.memberID,
+		Members:      []domain.JoinGroupResponseMember{},
+	}
+	if m.memberID != g.leaderID {
+		return response
+	}
+	for _, memberID := range slices.Sorted(maps.Keys(g.members)) {
+		member := g.members[memberID]
+		response.Members = append(response.Members, domain.JoinGroupResponseMember{
+			MemberID:        member.memberID,
+			GroupInstanceID: member.groupInstanceID,
+			Metadata:        member.metadata(g.protocolName),
+		})
+	}
+	return response
+}
+
+func joinError(memberID string, errorCode int16) domain.ResponseDataJoinGroup {
+	return domain.ResponseDataJoinGroup{
+		ErrorCode:    errorCode,
+		GenerationID: -1,
+		MemberID:     memberID,
+		Members:      []domain.JoinGroupResponseMember{},
+	}
+}
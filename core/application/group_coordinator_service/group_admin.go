@@ -0,0 +1,270 @@
+package group_coordinator_service
+
+import (
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+)
+
+// Group types as ListGroups reports them
+const (
+	groupTypeClassic  = "classic"
+	groupTypeConsumer = "consumer"
+)
+
+// ListGroups lists the groups in one of the states and of one of the types, every group
+// when a filter is empty. Filters match regardless of case.
+func (c *GroupCoordinator) ListGroups(statesFilter []string, typesFilter []string) []domain.ListedGroup {
+	c.mu.Lock()
+	groups := make([]*group, 0, len(c.groups))
+	for _, groupID := range slices.Sorted(maps.Keys(c.groups)) {
+		groups = append(groups, c.groups[groupID])
+	}
+	c.mu.Unlock()
+
+	matches := func(filter []string, value string) bool {
+		return len(filter) == 0 || slices.ContainsFunc(filter, func(f string) bool { return strings.EqualFold(f, value) })
+	}
+	listed := []domain.ListedGroup{}
+	for _, g := range groups {
+		g.mu.Lock()
+		if g.state != groupStateDead {
+			group := domain.ListedGroup{
+				GroupID:      g.groupID,
+				ProtocolType: g.protocolType,
+				GroupState:   g.state.String(),
+				GroupType:    groupTypeClassic,
+			}
+			if g.consumer != nil {
+				group.GroupState = g.consumer.state()
+				group.GroupType = groupTypeConsumer
+			}
+			if matches(statesFilter, group.GroupState) && matches(typesFilter, group.GroupType) {
+				listed = append(listed, group)
+			}
+		}
+		g.mu.Unlock()
+	}
+	return listed
+}
+
+// DescribeGroups describes the classic groups with their members. The members' protocol
+// metadata and assignment are only known once the group is stable. Groups that don't
+// exist, or use the consumer rebalance protocol, are reported dead.
+func (c *GroupCoordinator) DescribeGroups(groupIDs []string) []domain.DescribedGroup {
+	described := make([]domain.DescribedGroup, 0, len(groupIDs))
+	for _, groupID := range groupIDs {
+		described = append(described, c.describeGroup(groupID))
+	}
+	return described
+}
+
+func (c *GroupCoordinator) describeGroup(groupID string) domain.DescribedGroup {
+	description := domain.DescribedGroup{
+		ErrorCode:  domain.ErrorCodeNone,
+		GroupID:    groupID,
+		GroupState: groupStateDead.String(),
+		Members:    []domain.DescribedGroupMember{},
+	}
+	if groupID == "" {
+		description.ErrorCode = domain.ErrorCodeInvalidGroupID
+		return description
+	}
+	g := c.group(groupID, false)
+	if g == nil {
+		return description
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.consumer != nil {
+		return description
+	}
+	description.GroupState = g.state.String()
+	description.ProtocolType = g.protocolType
+	if g.state == groupStateStable {
+		description.ProtocolData = g.protocolName
+	}
+	for _, memberID := range slices.Sorted(maps.Keys(g.members)) {
+		m := g.members[memberID]
+		member := domain.DescribedGroupMember{
+			MemberID:        m.memberID,
+			GroupInstanceID: m.groupInstanceID,
+			ClientID:        m.clientID,
+		}
+		if g.state == groupStateStable {
+			member.MemberMetadata = m.metadata(g.protocolName)
+			member.MemberAssignment = m.assignment
+		}
+		description.Members = append(description.Members, member)
+	}
+	return description
+}
+
+// DeleteGroups removes the groups without members along with their committed offsets
+func (c *GroupCoordinator) DeleteGroups(groupIDs []string) []domain.DeleteGroupsResult {
+	results := make([]domain.DeleteGroupsResult, 0, len(groupIDs))
+	for _, groupID := range groupIDs {
+		results = append(results, domain.DeleteGroupsResult{GroupID: groupID, ErrorCode: c.deleteGroup(groupID)})
+	}
+	return results
+}
+
+func (c *GroupCoordinator) deleteGroup(groupID string) int16 {
+	if groupID == "" {
+		return domain.ErrorCodeInvalidGroupID
+	}
+	g := c.group(groupID, false)
+	if g == nil {
+		return domain.ErrorCodeGroupIDNotFound
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.state == groupStateDead {
+		return domain.ErrorCodeGroupIDNotFound
+	}
+	if !g.isEmpty() {
+		return domain.ErrorCodeNonEmptyGroup
+	}
+	if len(g.offsets) > 0 && c.offsetsRepository != nil {
+		if err := c.offsetsRepository.DeleteOffsets(g.groupID, sortedPartitions(slices.Collect(maps.Keys(g.offsets)))); err != nil {
+			fmt.Printf("Group %s: removing offsets failed: %v\n", g.groupID, err)
+			return domain.ErrorCodeCoordinatorNotAvailable
+		}
+	}
+	for _, pending := range g.pendingMembers {
+		pending.stop()
+	}
+	clear(g.offsets)
+	c.removeGroup(g)
+	fmt.Printf("Group %s: deleted\n", g.groupID)
+	return domain.ErrorCodeNone
+}
+
+// DeleteOffsets removes committed offsets of the group and returns the outcome for each
+// partition. A group with members keeps the offsets of the topics it consumes, and one
+// whose members don't use the consumer protocol keeps all of them.
+func (c *GroupCoordinator) DeleteOffsets(groupID string, partitions []domain.TopicPartition) (int16, map[domain.TopicPartition]int16) {
+	if groupID == "" {
+		return domain.ErrorCodeInvalidGroupID, nil
+	}
+	g := c.group(groupID, false)
+	if g == nil {
+		return domain.ErrorCodeGroupIDNotFound, nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.state == groupStateDead {
+		return domain.ErrorCodeGroupIDNotFound, nil
+	}
+	subscribed := map[string]struct{}{}
+	if !g.isEmpty() {
+		switch {
+		case g.consumer != nil:
+			for topic := range g.consumer.subscribedTopics {
+				subscribed[topic] = struct{}{}
+			}
+		case g.protocolType == consumerProtocolType:
+			subscribed = g.subscribedTopics()
+		default:
+			return domain.ErrorCodeNonEmptyGroup, nil
+		}
+	}
+
+	results := make(map[domain.TopicPartition]int16, len(partitions))
+	deleted := []domain.TopicPartition{}
+	for _, tp := range partitions {
+		if _, consumed := subscribed[tp.Topic]; consumed {
+			results[tp] = domain.ErrorCodeGroupSubscribedToTopic
+			continue
+		}
+		results[tp] = domain.ErrorCodeNone
+		if _, exists := g.offsets[tp]; exists {
+			deleted = append(deleted, tp)
+		}
+	}
+	if len(deleted) > 0 && c.offsetsRepository != nil {
+		if err := c.offsetsRepository.DeleteOffsets(g.groupID, deleted); err != nil {
+			fmt.Printf("Group %s: removing offsets failed: %v\n", g.groupID, err)
+			return domain.ErrorCodeCoordinatorNotAvailable, nil
+		}
+	}
+	for _, tp := range deleted {
+		delete(g.offsets, tp)
+	}
+	return domain.ErrorCodeNone, results
+}
+
+// subscribedTopics returns the topics the members of a classic consumer group subscribe
+// to in the protocol of the generation, none before a protocol is selected
+func (g *group) subscribedTopics() map[string]struct{} {
+	topics := map[string]struct{}{}
+	if g.protocolName == "" {
+		return topics
+	}
+	for _, m := range g.members {
+		for _, topic := range consumerProtocolTopics(m.metadata(g.protocolName)) {
+			topics[topic] = struct{}{}
+		}
+	}
+	return topics
+}
+
+// consumerProtocolTopics decodes the topics of a ConsumerProtocolSubscription, the member
+// metadata of the consumer protocol type: a version, then an array of topic names
+func consumerProtocolTopics(metadata []byte) []string {
+	topics := []string{}
+	if len(metadata) < 6 {
+		return topics
+	}
+	count := int(int32(binary.BigEndian.Uint32(metadata[2:6])))
+	offset := 6
+	for range max(count, 0) {
+		if offset+2 > len(metadata) {
+			break
+		}
+		length := int(int16(binary.BigEndian.Uint16(metadata[offset:])))
+		offset += 2
+		if length < 0 || offset+length > len(metadata) {
+			break
+		}
+		topics = append(topics, string(metadata[offset:offset+length]))
+		offset += length
+	}
+	return topics
+}
+
+// Lag tells how far the group is behind on every partition it committed an offset for:
+// the distance from the committed offset to the high watermark of the partition
+func (c *GroupCoordinator) Lag(groupID string) []domain.PartitionLag {
+	committed := map[domain.TopicPartition]domain.CommittedOffset{}
+	if g := c.group(groupID, false); g != nil {
+		g.mu.Lock()
+		committed = maps.Clone(g.offsets)
+		g.mu.Unlock()
+	}
+
+	lags := make([]domain.PartitionLag, 0, len(committed))
+	for _, tp := range sortedPartitions(slices.Collect(maps.Keys(committed))) {
+		lag := domain.PartitionLag{
+			TopicPartition:  tp,
+			CommittedOffset: committed[tp].Offset,
+			HighWatermark:   -1,
+			Lag:             -1,
+		}
+		if c.partitionRepository != nil {
+			if logOffsets, err := c.partitionRepository.GetLogOffsets(tp.Topic, int(tp.Partition)); err == nil {
+				lag.HighWatermark = logOffsets.HighWatermark
+				lag.Lag = max(logOffsets.HighWatermark-lag.CommittedOffset, 0)
+			}
+		}
+		lags = append(lags, lag)
+	}
+	return lags
+}
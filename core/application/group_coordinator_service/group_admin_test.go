@@ -0,0 +1,194 @@
+package group_coordinator_service
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/consumer_offsets_repository"
+	partition_file_repository "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/partition_repository"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// consumerSubscription encodes a version 0 ConsumerProtocolSubscription of the topics
+func consumerSubscription(topics ...string) []byte {
+	metadata := binary.BigEndian.AppendUint16(nil, 0)
+	metadata = binary.BigEndian.AppendUint32(metadata, uint32(len(topics)))
+	for _, topic := range topics {
+		metadata = binary.BigEndian.AppendUint16(metadata, uint16(len(topic)))
+		metadata = append(metadata, topic...)
+	}
+	return binary.BigEndian.AppendUint32(metadata, 0xffffffff) // no user data
+}
+
+// stableGroup runs group-a through a single member generation of the range protocol
+func stableGroup(t *testing.T, c *GroupCoordinator, metadata []byte) string {
+	t.Helper()
+	memberID := joinNew(t, c, "range")
+	req := joinRequest(memberID, "", "range")
+	req.Protocols[0].Metadata = metadata
+	await(t, join(c, req))
+	await(t, syncGroup(c, &domain.ParsedRequestSyncGroup{GroupID: "group-a", GenerationID: 1, MemberID: memberID, Assignments: []domain.SyncGroupAssignment{
+		{MemberID: memberID, Assignment: []byte{0x01}},
+	}}))
+	return memberID
+}
+
+func TestGroupCoordinator_ListGroups(t *testing.T) {
+	c := newTestCoordinator()
+	stableGroup(t, c, []byte("metadata"))
+	commit(c, "standalone", "", -1, 10)
+	consumerJoin := consumerJoinRequest("member-a", "orders")
+	consumerJoin.GroupID = "group-b"
+	consumerHeartbeat(t, c, consumerJoin)
+
+	tests := []struct {
+		name         string
+		statesFilter []string
+		typesFilter  []string
+		want         []string
+	}{
+		{name: "no filter", want: []string{"group-a", "group-b", "standalone"}},
+		{name: "state", statesFilter: []string{"stable"}, want: []string{"group-a", "group-b"}},
+		{name: "states", statesFilter: []string{"Empty", "Reconciling"}, want: []string{"standalone"}},
+		{name: "type", typesFilter: []string{"Consumer"}, want: []string{"group-b"}},
+		{name: "state and type", statesFilter: []string{"Empty"}, typesFilter: []string{"consumer"}, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, g := range c.ListGroups(tt.statesFilter, tt.typesFilter) {
+				got = append(got, g.GroupID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListGroups(%v, %v) = %v, want %v", tt.statesFilter, tt.typesFilter, got, tt.want)
+			}
+		})
+	}
+
+	want := []domain.ListedGroup{{GroupID: "group-a", ProtocolType: "consumer", GroupState: "Stable", GroupType: "classic"}}
+	if got := c.ListGroups([]string{"Stable"}, []string{"classic"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ListGroups(Stable, classic) = %+v, want %+v", got, want)
+	}
+}
+
+func TestGroupCoordinator_DescribeGroups(t *testing.T) {
+	c := newTestCoordinator()
+	memberID := stableGroup(t, c, []byte("metadata"))
+
+	described := c.DescribeGroups([]string{"group-a", "unknown", ""})
+	want := []domain.DescribedGroup{
+		{ErrorCode: domain.ErrorCodeNone, GroupID: "group-a", GroupState: "Stable", ProtocolType: "consumer", ProtocolData: "range", Members: []domain.DescribedGroupMember{
+			{MemberID: memberID, ClientID: "consumer", MemberMetadata: []byte("metadata"), MemberAssignment: []byte{0x01}},
+		}},
+		{ErrorCode: domain.ErrorCodeNone, GroupID: "unknown", GroupState: "Dead", Members: []domain.DescribedGroupMember{}},
+		{ErrorCode: domain.ErrorCodeInvalidGroupID, GroupID: "", GroupState: "Dead", Members: []domain.DescribedGroupMember{}},
+	}
+	if !reflect.DeepEqual(described, want) {
+		t.Errorf("DescribeGroups() = %+v, want %+v", described, want)
+	}
+
+	// While rebalancing the members' metadata and assignment are not settled
+	await(t, join(c, joinRequest(memberID, "", "range")))
+	rebalancing := c.DescribeGroups([]string{"group-a"})[0]
+	if rebalancing.GroupState != "CompletingRebalance" || rebalancing.ProtocolData != "" || rebalancing.Members[0].MemberAssignment != nil {
+		t.Errorf("DescribeGroups() while rebalancing = %+v, want no protocol data nor assignment", rebalancing)
+	}
+}
+
+func TestGroupCoordinator_DeleteGroups(t *testing.T) {
+	logDir := t.TempDir()
+	repository := consumer_offsets_repository.NewConsumerOffsetsRepository(partition_file_repository.NewPartitionFileRepositoryWithLogDir(logDir))
+	c := newTestCoordinator(WithOffsetsRepository(repository))
+	memberID := stableGroup(t, c, []byte("metadata"))
+	commit(c, "standalone", "", -1, 10)
+
+	results := c.DeleteGroups([]string{"group-a", "standalone", "unknown", ""})
+	want := []domain.DeleteGroupsResult{
+		{GroupID: "group-a", ErrorCode: domain.ErrorCodeNonEmptyGroup},
+		{GroupID: "standalone", ErrorCode: domain.ErrorCodeNone},
+		{GroupID: "unknown", ErrorCode: domain.ErrorCodeGroupIDNotFound},
+		{GroupID: "", ErrorCode: domain.ErrorCodeInvalidGroupID},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("DeleteGroups() = %+v, want %+v", results, want)
+	}
+	if got := c.DeleteGroups([]string{"standalone"}); got[0].ErrorCode != domain.ErrorCodeGroupIDNotFound {
+		t.Errorf("deleting a deleted group = %d, want GROUP_ID_NOT_FOUND", got[0].ErrorCode)
+	}
+
+	// Once its last member leaves the group can go
+	c.LeaveGroup(&domain.ParsedRequestLeaveGroup{GroupID: "group-a", Members: []domain.LeaveGroupMember{{MemberID: memberID}}})
+	if got := c.DeleteGroups([]string{"group-a"}); got[0].ErrorCode != domain.ErrorCodeNone {
+		t.Errorf("deleting an emptied group = %d, want none", got[0].ErrorCode)
+	}
+	if got := c.ListGroups(nil, nil); len(got) != 0 {
+		t.Errorf("ListGroups() after deleting = %+v, want none", got)
+	}
+
+	// The offsets of a deleted group stay deleted after a restart
+	restarted := newTestCoordinator(WithOffsetsRepository(
+		consumer_offsets_repository.NewConsumerOffsetsRepository(partition_file_repository.NewPartitionFileRepositoryWithLogDir(logDir))))
+	if err := restarted.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(restarted.Close)
+	if got := fetchAll(restarted, "standalone"); len(got) != 0 {
+		t.Errorf("offsets of a deleted group after restart = %v, want none", got)
+	}
+}
+
+func TestGroupCoordinator_DeleteOffsets(t *testing.T) {
+	payments0 := domain.TopicPartition{Topic: "payments", Partition: 0}
+	c := newTestCoordinator()
+	memberID := stableGroup(t, c, consumerSubscription("orders"))
+	c.CommitOffsets(&domain.ParsedRequestOffsetCommit{GroupID: "group-a", GenerationID: 1, MemberID: memberID, RetentionTimeMs: -1},
+		map[domain.TopicPartition]domain.CommittedOffset{
+			orders0:   {Offset: 5, ExpireTimestamp: -1},
+			payments0: {Offset: 5, ExpireTimestamp: -1},
+		})
+
+	errorCode, results := c.DeleteOffsets("group-a", []domain.TopicPartition{orders0, payments0})
+	want := map[domain.TopicPartition]int16{orders0: domain.ErrorCodeGroupSubscribedToTopic, payments0: domain.ErrorCodeNone}
+	if errorCode != domain.ErrorCodeNone || !reflect.DeepEqual(results, want) {
+		t.Errorf("DeleteOffsets() = %d %v, want none %v", errorCode, results, want)
+	}
+	if got := fetchAll(c, "group-a"); !reflect.DeepEqual(got, map[domain.TopicPartition]int64{orders0: 5}) {
+		t.Errorf("offsets after DeleteOffsets() = %v, want orders-0 only", got)
+	}
+
+	if errorCode, _ := c.DeleteOffsets("unknown", []domain.TopicPartition{orders0}); errorCode != domain.ErrorCodeGroupIDNotFound {
+		t.Errorf("DeleteOffsets() of an unknown group = %d, want GROUP_ID_NOT_FOUND", errorCode)
+	}
+
+	// Members of other protocol types keep every offset
+	other := newTestCoordinator()
+	req := joinRequest(joinNew(t, other, "range"), "", "range")
+	req.ProtocolType = "connect"
+	await(t, join(other, req))
+	if errorCode, _ := other.DeleteOffsets("group-a", []domain.TopicPartition{orders0}); errorCode != domain.ErrorCodeNonEmptyGroup {
+		t.Errorf("DeleteOffsets() of a connect group = %d, want NON_EMPTY_GROUP", errorCode)
+	}
+}
+
+func TestGroupCoordinator_Lag(t *testing.T) {
+	partitions := partition_file_repository.NewPartitionFileRepositoryWithLogDir(t.TempDir())
+	batch := common.EncodeRecordBatch(common.RecordBatch{
+		RecordBatchHeader: common.RecordBatchHeader{LastOffsetDelta: 9, PartitionLeaderEpoch: -1, ProducerID: -1, ProducerEpoch: -1, BaseSequence: -1},
+		Records:           make([]common.Record, 10),
+	})
+	if _, err := partitions.AppendRecordBatches(domain.AppendRequest{TopicName: "orders", PartitionIndex: 0, Records: batch}); err != nil {
+		t.Fatalf("AppendRecordBatches() error = %v", err)
+	}
+	c := newTestCoordinator(WithPartitionRepository(partitions))
+	commit(c, "group-a", "", -1, 4)
+
+	want := []domain.PartitionLag{{TopicPartition: orders0, CommittedOffset: 4, HighWatermark: 10, Lag: 6}}
+	if got := c.Lag("group-a"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lag() = %+v, want %+v", got, want)
+	}
+	if got := c.Lag("unknown"); len(got) != 0 {
+		t.Errorf("Lag() of an unknown group = %+v, want none", got)
+	}
+}
@@ -0,0 +1,662 @@
+package group_coordinator_service
+
+import (
+	"crypto/rand"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	port_consumer_offsets "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/consumer_offsets"
+	port_repo "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/partition_file_repository"
+)
+
+// Defaults of group.min.session.timeout.ms, group.max.session.timeout.ms and
+// group.initial.rebalance.delay.ms
+const (
+	DefaultMinSessionTimeout     = 6 * time.Second
+	DefaultMaxSessionTimeout     = 30 * time.Minute
+	DefaultInitialRebalanceDelay = 3 * time.Second
+)
+
+// Defaults of offsets.retention.minutes and offsets.retention.check.interval.ms
+const (
+	DefaultOffsetsRetention              = 7 * 24 * time.Hour
+	DefaultOffsetsRetentionCheckInterval = 10 * time.Minute
+)
+
+// Defaults of group.consumer.session.timeout.ms and group.consumer.heartbeat.interval.ms
+const (
+	DefaultConsumerGroupSessionTimeout    = 45 * time.Second
+	DefaultConsumerGroupHeartbeatInterval = 5 * time.Second
+)
+
+// joinGroupFirstKnownMemberIDVersion is the first JoinGroup version whose new dynamic
+// members must rejoin with the member ID handed out in a MEMBER_ID_REQUIRED error
+const joinGroupFirstKnownMemberIDVersion = 4
+
+type GroupCoordinatorOption func(*GroupCoordinator)
+
+// WithSessionTimeoutBounds changes the session timeouts members may ask for
+func WithSessionTimeoutBounds(minTimeout time.Duration, maxTimeout time.Duration) GroupCoordinatorOption {
+	return func(c *GroupCoordinator) {
+		c.minSessionTimeout = minTimeout
+		c.maxSessionTimeout = maxTimeout
+	}
+}
+
+// WithInitialRebalanceDelay changes how long the first rebalance of an empty group waits
+// for more members to join. Zero completes it as soon as every member joined.
+func WithInitialRebalanceDelay(delay time.Duration) GroupCoordinatorOption {
+	return func(c *GroupCoordinator) {
+		c.initialRebalanceDelay = delay
+	}
+}
+
+// WithOffsetsRepository persists committed offsets, which otherwise only live in memory
+func WithOffsetsRepository(repository port_consumer_offsets.ConsumerOffsetsRepository) GroupCoordinatorOption {
+	return func(c *GroupCoordinator) {
+		c.offsetsRepository = repository
+	}
+}
+
+// WithPartitionRepository gives the high watermarks the lag of the groups is computed
+// against
+func WithPartitionRepository(repository port_repo.PartitionFileRepository) GroupCoordinatorOption {
+	return func(c *GroupCoordinator) {
+		c.partitionRepository = repository
+	}
+}
+
+// WithOffsetsRetention changes how long the offsets of an empty group are kept, and how
+// often expired offsets are looked for
+func WithOffsetsRetention(retention time.Duration, checkInterval time.Duration) GroupCoordinatorOption {
+	return func(c *GroupCoordinator) {
+		c.offsetsRetention = retention
+		c.offsetsRetentionCheckInterval = checkInterval
+	}
+}
+
+// WithConsumerGroupSessionTimeout changes how long members of the groups using the
+// consumer rebalance protocol may go without a heartbeat, and how often they heartbeat
+func WithConsumerGroupSessionTimeout(sessionTimeout time.Duration, heartbeatInterval time.Duration) GroupCoordinatorOption {
+	return func(c *GroupCoordinator) {
+		c.consumerGroupSessionTimeout = sessionTimeout
+		c.consumerGroupHeartbeatInterval = heartbeatInterval
+	}
+}
+
+// GroupCoordinator runs the classic rebalance protocol for the consumer groups of this
+// broker. JoinGroup and SyncGroup requests are parked until the rebalance they take part
+// in gets there and answered through a callback; members whose session times out are
+// removed from their group. Groups may also use the consumer rebalance protocol, where
+// the broker assigns the partitions and members converge through their heartbeats. It
+// also keeps the offsets the groups commit, directly or within a producer's transaction.
+type GroupCoordinator struct {
+	minSessionTimeout     time.Duration
+	maxSessionTimeout     time.Duration
+	initialRebalanceDelay time.Duration
+
+	consumerGroupSessionTimeout    time.Duration
+	consumerGroupHeartbeatInterval time.Duration
+
+	offsetsRepository             port_consumer_offsets.ConsumerOffsetsRepository
+	offsetsRetention              time.Duration
+	offsetsRetentionCheckInterval time.Duration
+	stopExpiration                chan struct{}
+
+	partitionRepository port_repo.PartitionFileRepository
+
+	mu     sync.Mutex // Guards groups; each group has its own lock
+	groups map[string]*group
+
+	txnMu sync.Mutex // Guards pendingTxnOffsets
+	// pendingTxnOffsets are the offsets committed in transactions that have not ended,
+	// by producer ID and group
+	pendingTxnOffsets map[int64]map[string]map[domain.TopicPartition]domain.CommittedOffset
+}
+
+func NewGroupCoordinator(opts ...GroupCoordinatorOption) *GroupCoordinator {
+	c := &GroupCoordinator{
+		minSessionTimeout:              DefaultMinSessionTimeout,
+		maxSessionTimeout:              DefaultMaxSessionTimeout,
+		initialRebalanceDelay:          DefaultInitialRebalanceDelay,
+		consumerGroupSessionTimeout:    DefaultConsumerGroupSessionTimeout,
+		consumerGroupHeartbeatInterval: DefaultConsumerGroupHeartbeatInterval,
+		offsetsRetention:               DefaultOffsetsRetention,
+		offsetsRetentionCheckInterval:  DefaultOffsetsRetentionCheckInterval,
+		stopExpiration:                 make(chan struct{}),
+		groups:                         make(map[string]*group),
+		pendingTxnOffsets:              make(map[int64]map[string]map[domain.TopicPartition]domain.CommittedOffset),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// group returns the group with the ID, creating an empty one when create is set
+func (c *GroupCoordinator) group(groupID string, create bool) *group {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	g, exists := c.groups[groupID]
+	if !exists && create {
+		g = newGroup(groupID)
+		c.groups[groupID] = g
+	}
+	return g
+}
+
+// JoinGroup adds the member to the group or updates it, and answers through respond once
+// the rebalance it takes part in completes. Errors, and members rejoining a group whose
+// assignment they already have, are answered right away.
+func (c *GroupCoordinator) JoinGroup(req *domain.ParsedRequestJoinGroup, respond func(domain.ResponseDataJoinGroup)) {
+	if req.GroupID == "" {
+		respond(joinError(req.MemberID, domain.ErrorCodeInvalidGroupID))
+		return
+	}
+	sessionTimeout := time.Duration(req.SessionTimeoutMs) * time.Millisecond
+	if sessionTimeout < c.minSessionTimeout || sessionTimeout > c.maxSessionTimeout {
+		respond(joinError(req.MemberID, domain.ErrorCodeInvalidSessionTimeout))
+		return
+	}
+
+	g := c.group(req.GroupID, req.MemberID == "")
+	if g == nil {
+		respond(joinError(req.MemberID, domain.ErrorCodeUnknownMemberID))
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.state == groupStateDead {
+		respond(joinError(req.MemberID, domain.ErrorCodeCoordinatorNotAvailable))
+		return
+	}
+	if g.consumer != nil {
+		// Only a consumer group without members may go back to the classic protocol
+		if len(g.consumer.members) > 0 {
+			respond(joinError(req.MemberID, domain.ErrorCodeInconsistentGroupProtocol))
+			return
+		}
+		g.consumer = nil
+	}
+	if !g.supportsProtocols(req.ProtocolType, req.Protocols) {
+		respond(joinError(req.MemberID, domain.ErrorCodeInconsistentGroupProtocol))
+		return
+	}
+
+	if req.MemberID == "" {
+		c.joinNewMember(g, req, respond)
+	} else {
+		c.joinKnownMember(g, req, respond)
+	}
+}
+
+func (c *GroupCoordinator) joinNewMember(g *group, req *domain.ParsedRequestJoinGroup, respond func(domain.ResponseDataJoinGroup)) {
+	if req.GroupInstanceID != "" {
+		memberID := newMemberID(req.GroupInstanceID)
+		if previous, exists := g.staticMembers[req.GroupInstanceID]; exists {
+			c.replaceStaticMember(g, g.members[previous], memberID, req, respond)
+			return
+		}
+		c.addMemberAndRebalance(g, memberID, req, respond)
+		return
+	}
+
+	memberID := newMemberID(req.ClientID)
+	if req.APIVersion >= joinGroupFirstKnownMemberIDVersion {
+		// The client retries with the member ID, so a member whose JoinGroup response got
+		// lost can't leave a ghost behind
+		pending := &groupTimer{}
+		g.pendingMembers[memberID] = pending
+		pending.reset(g, sessionTimeoutOf(req), func() {
+			delete(g.pendingMembers, memberID)
+			c.tryCompleteJoin(g)
+		})
+		respond(joinError(memberID, domain.ErrorCodeMemberIDRequired))
+		return
+	}
+	c.addMemberAndRebalance(g, memberID, req, respond)
+}
+
+func (c *GroupCoordinator) joinKnownMember(g *group, req *domain.ParsedRequestJoinGroup, respond func(domain.ResponseDataJoinGroup)) {
+	if pending, exists := g.pendingMembers[req.MemberID]; exists {
+		pending.stop()
+		delete(g.pendingMembers, req.MemberID)
+		c.addMemberAndRebalance(g, req.MemberID, req, respond)
+		return
+	}
+	if errorCode := g.validateMember(req.MemberID, req.GroupInstanceID); errorCode != domain.ErrorCodeNone {
+		respond(joinError(req.MemberID, errorCode))
+		return
+	}
+
+	m := g.members[req.MemberID]
+	switch g.state {
+	case groupStatePreparingRebalance:
+		c.updateMemberAndRebalance(g, m, req, respond)
+	case groupStateCompletingRebalance:
+		// A member whose JoinGroup response got lost gets it again
+		if m.matches(req.Protocols) {
+			respond(g.joinResponse(m))
+			return
+		}
+		c.updateMemberAndRebalance(g, m, req, respond)
+	case groupStateStable:
+		// The leader rejoins to have the assignment recomputed, e.g. for new partitions
+		if m.memberID == g.leaderID || !m.matches(req.Protocols) {
+			c.updateMemberAndRebalance(g, m, req, respond)
+			return
+		}
+		respond(g.joinResponse(m))
+	default:
+		respond(joinError(req.MemberID, domain.ErrorCodeUnknownMemberID))
+	}
+}
+
+func (c *GroupCoordinator) addMemberAndRebalance(g *group, memberID string, req *domain.ParsedRequestJoinGroup, respond func(domain.ResponseDataJoinGroup)) {
+	m := &member{
+		memberID:        memberID,
+		groupInstanceID: req.GroupInstanceID,
+		clientID:        req.ClientID,
+		awaitingJoin:    respond,
+	}
+	updateMember(m, req)
+	if g.state == groupStatePreparingRebalance && g.initialDelay {
+		g.newMemberAdded = true
+	}
+	g.add(m)
+	c.maybePrepareRebalance(g)
+}
+
+func (c *GroupCoordinator) updateMemberAndRebalance(g *group, m *member, req *domain.ParsedRequestJoinGroup, respond func(domain.ResponseDataJoinGroup)) {
+	if m.awaitingJoin != nil {
+		m.awaitingJoin(joinError(m.memberID, domain.ErrorCodeRebalanceInProgress))
+	}
+	updateMember(m, req)
+	m.awaitingJoin = respond
+	c.maybePrepareRebalance(g)
+}
+
+// replaceStaticMember hands the place of a static member to its new incarnation and
+// fences the previous one. A stable group whose protocol stays the same keeps its
+// assignment; the new incarnation gets the current generation without a rebalance.
+func (c *GroupCoordinator) replaceStaticMember(g *group, previous *member, memberID string, req *domain.ParsedRequestJoinGroup, respond func(domain.ResponseDataJoinGroup)) {
+	previous.heartbeat.stop()
+	if previous.awaitingJoin != nil {
+		previous.awaitingJoin(joinError(previous.memberID, domain.ErrorCodeFencedInstanceID))
+	}
+	if previous.awaitingSync != nil {
+		previous.awaitingSync(domain.ResponseDataSyncGroup{ErrorCode: domain.ErrorCodeFencedInstanceID})
+	}
+	delete(g.members, previous.memberID)
+	if _, pending := g.pendingSync[previous.memberID]; pending {
+		delete(g.pendingSync, previous.memberID)
+		g.pendingSync[memberID] = struct{}{}
+	}
+
+	m := &member{
+		memberID:        memberID,
+		groupInstanceID: req.GroupInstanceID,
+		clientID:        req.ClientID,
+		assignment:      previous.assignment,
+	}
+	updateMember(m, req)
+	g.members[memberID] = m
+	g.staticMembers[req.GroupInstanceID] = memberID
+	if g.leaderID == previous.memberID {
+		g.leaderID = memberID
+	}
+
+	if g.state == groupStateStable && g.selectProtocol() == g.protocolName {
+		c.scheduleHeartbeat(g, m)
+		response := g.joinResponse(m)
+		response.SkipAssignment = m.memberID == g.leaderID
+		respond(response)
+		return
+	}
+	m.awaitingJoin = respond
+	c.maybePrepareRebalance(g)
+}
+
+func updateMember(m *member, req *domain.ParsedRequestJoinGroup) {
+	m.sessionTimeout = sessionTimeoutOf(req)
+	m.rebalanceTimeout = m.sessionTimeout
+	if req.RebalanceTimeoutMs > 0 {
+		m.rebalanceTimeout = time.Duration(req.RebalanceTimeoutMs) * time.Millisecond
+	}
+	m.protocolType = req.ProtocolType
+	m.protocols = req.Protocols
+}
+
+func sessionTimeoutOf(req *domain.ParsedRequestJoinGroup) time.Duration {
+	return time.Duration(req.SessionTimeoutMs) * time.Millisecond
+}
+
+// maybePrepareRebalance starts a rebalance, or completes the running one once every
+// member rejoined
+func (c *GroupCoordinator) maybePrepareRebalance(g *group) {
+	switch g.state {
+	case groupStateEmpty, groupStateCompletingRebalance, groupStateStable:
+		c.prepareRebalance(g)
+	case groupStatePreparingRebalance:
+		c.tryCompleteJoin(g)
+	}
+}
+
+// prepareRebalance asks every member to rejoin. The members waiting for the assignment
+// of the generation being abandoned are told a rebalance is in progress. The first
+// rebalance of an empty group waits for more members to arrive before completing.
+func (c *GroupCoordinator) prepareRebalance(g *group) {
+	if g.state == groupStateCompletingRebalance {
+		for _, m := range g.members {
+			m.assignment = nil
+			if m.awaitingSync != nil {
+				m.awaitingSync(domain.ResponseDataSyncGroup{ErrorCode: domain.ErrorCodeRebalanceInProgress})
+				m.awaitingSync = nil
+			}
+		}
+	}
+	clear(g.pendingSync)
+
+	initial := g.state == groupStateEmpty
+	g.transitionTo(groupStatePreparingRebalance)
+	if initial && c.initialRebalanceDelay > 0 {
+		g.initialDelay = true
+		g.newMemberAdded = false
+		c.scheduleInitialDelay(g, min(c.initialRebalanceDelay, g.rebalanceTimeout()), g.rebalanceTimeout())
+		return
+	}
+	g.rebalanceTimer.reset(g, g.rebalanceTimeout(), func() { c.completeJoin(g) })
+	c.tryCompleteJoin(g)
+}
+
+// scheduleInitialDelay completes the initial rebalance after delay, or waits another
+// delay when members joined meanwhile and the rebalance timeout allows it
+func (c *GroupCoordinator) scheduleInitialDelay(g *group, delay time.Duration, remaining time.Duration) {
+	g.rebalanceTimer.reset(g, delay, func() {
+		remaining -= delay
+		if g.newMemberAdded && remaining > 0 {
+			g.newMemberAdded = false
+			c.scheduleInitialDelay(g, min(c.initialRebalanceDelay, remaining), remaining)
+			return
+		}
+		g.initialDelay = false
+		c.completeJoin(g)
+	})
+}
+
+func (c *GroupCoordinator) tryCompleteJoin(g *group) {
+	if g.state == groupStatePreparingRebalance && !g.initialDelay && g.allMembersJoined() {
+		c.completeJoin(g)
+	}
+}
+
+// completeJoin starts the next generation with the members that rejoined and answers
+// their JoinGroup requests. Dynamic members that didn't rejoin in time are removed;
+// static members keep their place.
+func (c *GroupCoordinator) completeJoin(g *group) {
+	if g.state != groupStatePreparingRebalance {
+		return
+	}
+	g.rebalanceTimer.stop()
+	g.initialDelay = false
+	for _, m := range g.members {
+		if m.awaitingJoin == nil && m.groupInstanceID == "" {
+			g.remove(m)
+		}
+	}
+	if leader, exists := g.members[g.leaderID]; !exists || leader.awaitingJoin == nil {
+		g.leaderID = g.electLeader()
+	}
+
+	g.generationID++
+	if len(g.members) == 0 {
+		g.protocolName = ""
+		g.transitionTo(groupStateEmpty)
+		return
+	}
+	g.protocolName = g.selectProtocol()
+	g.transitionTo(groupStateCompletingRebalance)
+
+	for _, memberID := range slices.Sorted(maps.Keys(g.members)) {
+		m := g.members[memberID]
+		g.pendingSync[memberID] = struct{}{}
+		if m.awaitingJoin != nil {
+			m.awaitingJoin(g.joinResponse(m))
+			m.awaitingJoin = nil
+		}
+		c.scheduleHeartbeat(g, m)
+	}
+	g.rebalanceTimer.reset(g, g.rebalanceTimeout(), func() { c.expirePendingSync(g) })
+}
+
+// expirePendingSync removes the members that didn't send SyncGroup within the rebalance
+// timeout and rebalances the group without them
+func (c *GroupCoordinator) expirePendingSync(g *group) {
+	if g.state != groupStateCompletingRebalance && g.state != groupStateStable {
+		return
+	}
+	for memberID := range g.pendingSync {
+		if m, exists := g.members[memberID]; exists {
+			fmt.Printf("Group %s: member %s did not sync in time\n", g.groupID, memberID)
+			g.remove(m)
+		}
+	}
+	clear(g.pendingSync)
+	c.prepareRebalance(g)
+}
+
+// scheduleHeartbeat (re)starts the session of m. A member with a parked JoinGroup or
+// SyncGroup stays alive until it is answered.
+func (c *GroupCoordinator) scheduleHeartbeat(g *group, m *member) {
+	m.heartbeat.reset(g, m.sessionTimeout, func() {
+		if m.awaitingJoin != nil || m.awaitingSync != nil {
+			c.scheduleHeartbeat(g, m)
+			return
+		}
+		if g.members[m.memberID] == m {
+			fmt.Printf("Group %s: session of member %s timed out\n", g.groupID, m.memberID)
+			c.removeMemberAndUpdateGroup(g, m)
+		}
+	})
+}
+
+// removeMemberAndUpdateGroup removes m and rebalances the group without it
+func (c *GroupCoordinator) removeMemberAndUpdateGroup(g *group, m *member) {
+	g.remove(m)
+	switch g.state {
+	case groupStateCompletingRebalance, groupStateStable:
+		c.prepareRebalance(g)
+	case groupStatePreparingRebalance:
+		c.tryCompleteJoin(g)
+	}
+}
+
+// SyncGroup answers through respond with the member's assignment. Members are parked
+// until the leader sends the assignment of the generation.
+func (c *GroupCoordinator) SyncGroup(req *domain.ParsedRequestSyncGroup, respond func(domain.ResponseDataSyncGroup)) {
+	g := c.group(req.GroupID, false)
+	if g == nil {
+		respond(domain.ResponseDataSyncGroup{ErrorCode: domain.ErrorCodeUnknownMemberID})
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.state == groupStateDead {
+		respond(domain.ResponseDataSyncGroup{ErrorCode: domain.ErrorCodeCoordinatorNotAvailable})
+		return
+	}
+	if errorCode := g.validateMember(req.MemberID, req.GroupInstanceID); errorCode != domain.ErrorCodeNone {
+		respond(domain.ResponseDataSyncGroup{ErrorCode: errorCode})
+		return
+	}
+	if req.GenerationID != g.generationID {
+		respond(domain.ResponseDataSyncGroup{ErrorCode: domain.ErrorCodeIllegalGeneration})
+		return
+	}
+	if req.ProtocolType != "" && req.ProtocolType != g.protocolType || req.ProtocolName != "" && req.ProtocolName != g.protocolName {
+		respond(domain.ResponseDataSyncGroup{ErrorCode: domain.ErrorCodeInconsistentGroupProtocol})
+		return
+	}
+
+	m := g.members[req.MemberID]
+	switch g.state {
+	case groupStatePreparingRebalance:
+		respond(domain.ResponseDataSyncGroup{ErrorCode: domain.ErrorCodeRebalanceInProgress})
+		return
+	case groupStateCompletingRebalance:
+		// A retried SyncGroup supersedes the parked one, which still needs its response
+		if m.awaitingSync != nil {
+			m.awaitingSync(domain.ResponseDataSyncGroup{ErrorCode: domain.ErrorCodeRebalanceInProgress})
+		}
+		m.awaitingSync = respond
+		if m.memberID == g.leaderID {
+			c.completeSync(g, req.Assignments)
+		}
+	case groupStateStable:
+		respond(g.syncResponse(m))
+	default:
+		respond(domain.ResponseDataSyncGroup{ErrorCode: domain.ErrorCodeUnknownMemberID})
+		return
+	}
+
+	delete(g.pendingSync, m.memberID)
+	if len(g.pendingSync) == 0 && g.state == groupStateStable {
+		g.rebalanceTimer.stop()
+	}
+	c.scheduleHeartbeat(g, m)
+}
+
+// completeSync stores the leader's assignment, stabilizes the group and answers the
+// members waiting for it. Members the leader left out get an empty assignment.
+func (c *GroupCoordinator) completeSync(g *group, assignments []domain.SyncGroupAssignment) {
+	for _, m := range g.members {
+		m.assignment = []byte{}
+	}
+	for _, assignment := range assignments {
+		if m, exists := g.members[assignment.MemberID]; exists && assignment.Assignment != nil {
+			m.assignment = assignment.Assignment
+		}
+	}
+	g.transitionTo(groupStateStable)
+
+	for _, m := range g.members {
+		if m.awaitingSync != nil {
+			m.awaitingSync(g.syncResponse(m))
+			m.awaitingSync = nil
+		}
+	}
+}
+
+func (g *group) syncResponse(m *member) domain.ResponseDataSyncGroup {
+	return domain.ResponseDataSyncGroup{
+		ErrorCode:    domain.ErrorCodeNone,
+		ProtocolType: g.protocolType,
+		ProtocolName: g.protocolName,
+		Assignment:   m.assignment,
+	}
+}
+
+// Heartbeat keeps the member's session alive and tells it when it has to rejoin
+func (c *GroupCoordinator) Heartbeat(req *domain.ParsedRequestHeartbeat) int16 {
+	g := c.group(req.GroupID, false)
+	if g == nil {
+		return domain.ErrorCodeUnknownMemberID
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.state == groupStateDead {
+		return domain.ErrorCodeCoordinatorNotAvailable
+	}
+	if errorCode := g.validateMember(req.MemberID, req.GroupInstanceID); errorCode != domain.ErrorCodeNone {
+		return errorCode
+	}
+
+	m := g.members[req.MemberID]
+	switch g.state {
+	case groupStatePreparingRebalance:
+		c.scheduleHeartbeat(g, m)
+		return domain.ErrorCodeRebalanceInProgress
+	case groupStateCompletingRebalance, groupStateStable:
+		if req.GenerationID != g.generationID {
+			return domain.ErrorCodeIllegalGeneration
+		}
+		c.scheduleHeartbeat(g, m)
+		return domain.ErrorCodeNone
+	default:
+		return domain.ErrorCodeUnknownMemberID
+	}
+}
+
+// LeaveGroup removes the members from the group and rebalances it without them. Static
+// members may be named by their group instance ID alone.
+func (c *GroupCoordinator) LeaveGroup(req *domain.ParsedRequestLeaveGroup) (int16, []domain.LeaveGroupMemberResult) {
+	results := make([]domain.LeaveGroupMemberResult, 0, len(req.Members))
+	g := c.group(req.GroupID, false)
+	if g == nil {
+		for _, leaving := range req.Members {
+			results = append(results, leaveResult(leaving, domain.ErrorCodeUnknownMemberID))
+		}
+		return domain.ErrorCodeNone, results
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.state == groupStateDead {
+		return domain.ErrorCodeCoordinatorNotAvailable, results
+	}
+	for _, leaving := range req.Members {
+		results = append(results, leaveResult(leaving, c.leave(g, leaving)))
+	}
+	return domain.ErrorCodeNone, results
+}
+
+func (c *GroupCoordinator) leave(g *group, leaving domain.LeaveGroupMember) int16 {
+	memberID := leaving.MemberID
+	if leaving.GroupInstanceID != "" {
+		current, exists := g.staticMembers[leaving.GroupInstanceID]
+		if !exists {
+			return domain.ErrorCodeUnknownMemberID
+		}
+		if memberID != "" && memberID != current {
+			return domain.ErrorCodeFencedInstanceID
+		}
+		memberID = current
+	}
+
+	if pending, exists := g.pendingMembers[memberID]; exists {
+		pending.stop()
+		delete(g.pendingMembers, memberID)
+		c.tryCompleteJoin(g)
+		return domain.ErrorCodeNone
+	}
+	m, exists := g.members[memberID]
+	if !exists {
+		return domain.ErrorCodeUnknownMemberID
+	}
+	c.removeMemberAndUpdateGroup(g, m)
+	return domain.ErrorCodeNone
+}
+
+func leaveResult(leaving domain.LeaveGroupMember, errorCode int16) domain.LeaveGroupMemberResult {
+	return domain.LeaveGroupMemberResult{
+		MemberID:        leaving.MemberID,
+		GroupInstanceID: leaving.GroupInstanceID,
+		ErrorCode:       errorCode,
+	}
+}
+
+// newMemberID returns prefix followed by a random UUID, the way Kafka names members
+// after their client or group instance ID
+func newMemberID(prefix string) string {
+	uuid := make([]byte, 16)
+	rand.Read(uuid)
+	return fmt.Sprintf("%s-%x-%x-%x-%x-%x", prefix, uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16])
+}
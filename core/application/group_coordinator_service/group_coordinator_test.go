@@ -0,0 +1,293 @@
+package group_coordinator_service
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+)
+
+func newTestCoordinator(opts ...GroupCoordinatorOption) *GroupCoordinator {
+	opts = append([]GroupCoordinatorOption{
+		WithSessionTimeoutBounds(10*time.Millisecond, time.Minute),
+		WithInitialRebalanceDelay(0),
+	}, opts...)
+	return NewGroupCoordinator(opts...)
+}
+
+func joinRequest(memberID string, instanceID string, protocols ...string) *domain.ParsedRequestJoinGroup {
+	req := &domain.ParsedRequestJoinGroup{
+		APIVersion:         9,
+		ClientID:           "consumer",
+		GroupID:            "group-a",
+		SessionTimeoutMs:   10000,
+		RebalanceTimeoutMs: 10000,
+		MemberID:           memberID,
+		GroupInstanceID:    instanceID,
+		ProtocolType:       "consumer",
+	}
+	for _, protocol := range protocols {
+		req.Protocols = append(req.Protocols, domain.JoinGroupProtocol{Name: protocol, Metadata: []byte(memberID + protocol)})
+	}
+	return req
+}
+
+// join sends a JoinGroup and returns where its response arrives
+func join(c *GroupCoordinator, req *domain.ParsedRequestJoinGroup) <-chan domain.ResponseDataJoinGroup {
+	responses := make(chan domain.ResponseDataJoinGroup, 1)
+	c.JoinGroup(req, func(response domain.ResponseDataJoinGroup) { responses <- response })
+	return responses
+}
+
+func syncGroup(c *GroupCoordinator, req *domain.ParsedRequestSyncGroup) <-chan domain.ResponseDataSyncGroup {
+	responses := make(chan domain.ResponseDataSyncGroup, 1)
+	c.SyncGroup(req, func(response domain.ResponseDataSyncGroup) { responses <- response })
+	return responses
+}
+
+func await[T any](t *testing.T, responses <-chan T) T {
+	t.Helper()
+	select {
+	case response := <-responses:
+		return response
+	case <-time.After(2 * time.Second):
+		t.Fatal("no response")
+	}
+	var zero T
+	return zero
+}
+
+func pending[T any](t *testing.T, responses <-chan T) {
+	t.Helper()
+	select {
+	case response := <-responses:
+		t.Fatalf("unexpected response %+v", response)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+// joinNew runs the MEMBER_ID_REQUIRED round trip and returns the member ID handed out
+func joinNew(t *testing.T, c *GroupCoordinator, protocols ...string) string {
+	t.Helper()
+	response := await(t, join(c, joinRequest("", "", protocols...)))
+	if response.ErrorCode != domain.ErrorCodeMemberIDRequired || response.MemberID == "" {
+		t.Fatalf("first JoinGroup = %+v, want MEMBER_ID_REQUIRED with a member ID", response)
+	}
+	return response.MemberID
+}
+
+func heartbeat(c *GroupCoordinator, memberID string, generation int32) int16 {
+	return c.Heartbeat(&domain.ParsedRequestHeartbeat{GroupID: "group-a", MemberID: memberID, GenerationID: generation})
+}
+
+func TestGroupCoordinator_Rebalance(t *testing.T) {
+	c := newTestCoordinator()
+	first, second := joinNew(t, c, "range"), joinNew(t, c, "cooperative-sticky", "range")
+
+	firstJoin := join(c, joinRequest(first, "", "range"))
+	pending(t, firstJoin) // second is still pending
+	secondJoin := join(c, joinRequest(second, "", "cooperative-sticky", "range"))
+	firstResponse, secondResponse := await(t, firstJoin), await(t, secondJoin)
+
+	if firstResponse.ErrorCode != domain.ErrorCodeNone || firstResponse.GenerationID != 1 || firstResponse.ProtocolName != "range" ||
+		firstResponse.Leader != first || len(firstResponse.Members) != 2 {
+		t.Fatalf("leader JoinGroup = %+v, want generation 1 of range led by %s with both members", firstResponse, first)
+	}
+	if secondResponse.GenerationID != 1 || secondResponse.Leader != first || len(secondResponse.Members) != 0 {
+		t.Errorf("follower JoinGroup = %+v, want generation 1 without members", secondResponse)
+	}
+	for _, m := range firstResponse.Members {
+		if !bytes.Equal(m.Metadata, []byte(m.MemberID+"range")) {
+			t.Errorf("member %s metadata = %q, want its range metadata", m.MemberID, m.Metadata)
+		}
+	}
+
+	// The follower waits for the leader's assignment
+	followerSync := syncGroup(c, &domain.ParsedRequestSyncGroup{GroupID: "group-a", GenerationID: 1, MemberID: second})
+	pending(t, followerSync)
+	if errorCode := heartbeat(c, second, 1); errorCode != domain.ErrorCodeNone {
+		t.Errorf("heartbeat while completing = %d, want none", errorCode)
+	}
+	leaderSync := await(t, syncGroup(c, &domain.ParsedRequestSyncGroup{GroupID: "group-a", GenerationID: 1, MemberID: first, Assignments: []domain.SyncGroupAssignment{
+		{MemberID: first, Assignment: []byte{0x01}},
+		{MemberID: second, Assignment: []byte{0x02}},
+	}}))
+	if leaderSync.ErrorCode != domain.ErrorCodeNone || !bytes.Equal(leaderSync.Assignment, []byte{0x01}) || leaderSync.ProtocolName != "range" {
+		t.Errorf("leader SyncGroup = %+v, want its assignment", leaderSync)
+	}
+	if response := await(t, followerSync); !bytes.Equal(response.Assignment, []byte{0x02}) {
+		t.Errorf("follower SyncGroup = %+v, want its assignment", response)
+	}
+
+	if errorCode := heartbeat(c, first, 0); errorCode != domain.ErrorCodeIllegalGeneration {
+		t.Errorf("heartbeat of an old generation = %d, want ILLEGAL_GENERATION", errorCode)
+	}
+	if errorCode := heartbeat(c, "stranger", 1); errorCode != domain.ErrorCodeUnknownMemberID {
+		t.Errorf("heartbeat of an unknown member = %d, want UNKNOWN_MEMBER_ID", errorCode)
+	}
+
+	// A third member triggers a rebalance the others learn about from their heartbeats
+	third := joinNew(t, c, "range")
+	thirdJoin := join(c, joinRequest(third, "", "range"))
+	if errorCode := heartbeat(c, first, 1); errorCode != domain.ErrorCodeRebalanceInProgress {
+		t.Errorf("heartbeat during rebalance = %d, want REBALANCE_IN_PROGRESS", errorCode)
+	}
+	if response := await(t, syncGroup(c, &domain.ParsedRequestSyncGroup{GroupID: "group-a", GenerationID: 1, MemberID: second})); response.ErrorCode != domain.ErrorCodeRebalanceInProgress {
+		t.Errorf("SyncGroup during rebalance = %d, want REBALANCE_IN_PROGRESS", response.ErrorCode)
+	}
+	firstJoin, secondJoin = join(c, joinRequest(first, "", "range")), join(c, joinRequest(second, "", "cooperative-sticky", "range"))
+	for _, responses := range []<-chan domain.ResponseDataJoinGroup{firstJoin, secondJoin, thirdJoin} {
+		if response := await(t, responses); response.GenerationID != 2 {
+			t.Errorf("rejoin = %+v, want generation 2", response)
+		}
+	}
+
+	// Leaving rebalances the group without the member
+	errorCode, results := c.LeaveGroup(&domain.ParsedRequestLeaveGroup{GroupID: "group-a", Members: []domain.LeaveGroupMember{{MemberID: third}, {MemberID: "stranger"}}})
+	if errorCode != domain.ErrorCodeNone || results[0].ErrorCode != domain.ErrorCodeNone || results[1].ErrorCode != domain.ErrorCodeUnknownMemberID {
+		t.Errorf("LeaveGroup = %d %+v, want the stranger unknown", errorCode, results)
+	}
+	firstJoin, secondJoin = join(c, joinRequest(first, "", "range")), join(c, joinRequest(second, "", "cooperative-sticky", "range"))
+	if response := await(t, firstJoin); response.GenerationID != 3 || len(response.Members) != 2 {
+		t.Errorf("rejoin after leave = %+v, want generation 3 with two members", response)
+	}
+	await(t, secondJoin)
+}
+
+func TestGroupCoordinator_SyncGroupRetry(t *testing.T) {
+	c := newTestCoordinator()
+	first, second := joinNew(t, c, "range"), joinNew(t, c, "range")
+	firstJoin, secondJoin := join(c, joinRequest(first, "", "range")), join(c, joinRequest(second, "", "range"))
+	await(t, firstJoin)
+	await(t, secondJoin)
+
+	// The follower retries its SyncGroup before the leader sent the assignment
+	followerSync := syncGroup(c, &domain.ParsedRequestSyncGroup{GroupID: "group-a", GenerationID: 1, MemberID: second})
+	pending(t, followerSync)
+	retriedSync := syncGroup(c, &domain.ParsedRequestSyncGroup{GroupID: "group-a", GenerationID: 1, MemberID: second})
+	if response := await(t, followerSync); response.ErrorCode != domain.ErrorCodeRebalanceInProgress {
+		t.Errorf("superseded SyncGroup = %+v, want REBALANCE_IN_PROGRESS", response)
+	}
+	pending(t, retriedSync)
+
+	await(t, syncGroup(c, &domain.ParsedRequestSyncGroup{GroupID: "group-a", GenerationID: 1, MemberID: first, Assignments: []domain.SyncGroupAssignment{
+		{MemberID: second, Assignment: []byte{0x02}},
+	}}))
+	if response := await(t, retriedSync); response.ErrorCode != domain.ErrorCodeNone || !bytes.Equal(response.Assignment, []byte{0x02}) {
+		t.Errorf("retried SyncGroup = %+v, want the follower's assignment", response)
+	}
+}
+
+func TestGroupCoordinator_SessionTimeout(t *testing.T) {
+	c := newTestCoordinator()
+	first, second := joinNew(t, c, "range"), joinNew(t, c, "range")
+	firstJoin, secondJoin := join(c, joinRequest(first, "", "range")), join(c, joinRequest(second, "", "range"))
+	await(t, firstJoin)
+	await(t, secondJoin)
+	await(t, syncGroup(c, &domain.ParsedRequestSyncGroup{GroupID: "group-a", GenerationID: 1, MemberID: first}))
+	await(t, syncGroup(c, &domain.ParsedRequestSyncGroup{GroupID: "group-a", GenerationID: 1, MemberID: second}))
+
+	// The leader rejoining a stable group starts generation 2, with short sessions of
+	// which only the first member's is kept alive
+	short := joinRequest(first, "", "range")
+	short.SessionTimeoutMs = 50
+	short.RebalanceTimeoutMs = 1000
+	firstJoin = join(c, short)
+	secondRejoin := joinRequest(second, "", "range")
+	secondRejoin.SessionTimeoutMs = 50
+	await(t, join(c, secondRejoin))
+	response := await(t, firstJoin)
+	if response.GenerationID != 2 {
+		t.Fatalf("rejoin = %+v, want generation 2", response)
+	}
+	await(t, syncGroup(c, &domain.ParsedRequestSyncGroup{GroupID: "group-a", GenerationID: 2, MemberID: first}))
+	await(t, syncGroup(c, &domain.ParsedRequestSyncGroup{GroupID: "group-a", GenerationID: 2, MemberID: second}))
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if heartbeat(c, first, 2) == domain.ErrorCodeRebalanceInProgress {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if errorCode := heartbeat(c, second, 2); errorCode != domain.ErrorCodeUnknownMemberID {
+		t.Errorf("heartbeat of the timed out member = %d, want UNKNOWN_MEMBER_ID", errorCode)
+	}
+	if response := await(t, join(c, short)); response.GenerationID != 3 || len(response.Members) != 1 {
+		t.Errorf("rejoin after timeout = %+v, want generation 3 with one member", response)
+	}
+}
+
+func TestGroupCoordinator_StaticMembership(t *testing.T) {
+	c := newTestCoordinator()
+	response := await(t, join(c, joinRequest("", "instance-1", "range")))
+	if response.ErrorCode != domain.ErrorCodeNone || response.GenerationID != 1 {
+		t.Fatalf("static JoinGroup = %+v, want generation 1 without MEMBER_ID_REQUIRED", response)
+	}
+	previous := response.MemberID
+	await(t, syncGroup(c, &domain.ParsedRequestSyncGroup{GroupID: "group-a", GenerationID: 1, MemberID: previous, Assignments: []domain.SyncGroupAssignment{
+		{MemberID: previous, Assignment: []byte{0x01}},
+	}}))
+
+	// A restarted instance takes over the assignment without a rebalance
+	response = await(t, join(c, joinRequest("", "instance-1", "range")))
+	if response.ErrorCode != domain.ErrorCodeNone || response.GenerationID != 1 || response.MemberID == previous || !response.SkipAssignment {
+		t.Fatalf("static rejoin = %+v, want generation 1 under a new member ID, skipping the assignment", response)
+	}
+	if errorCode := c.Heartbeat(&domain.ParsedRequestHeartbeat{GroupID: "group-a", GenerationID: 1, MemberID: previous, GroupInstanceID: "instance-1"}); errorCode != domain.ErrorCodeFencedInstanceID {
+		t.Errorf("heartbeat of the previous incarnation = %d, want FENCED_INSTANCE_ID", errorCode)
+	}
+	synced := await(t, syncGroup(c, &domain.ParsedRequestSyncGroup{GroupID: "group-a", GenerationID: 1, MemberID: response.MemberID, GroupInstanceID: "instance-1"}))
+	if !bytes.Equal(synced.Assignment, []byte{0x01}) {
+		t.Errorf("SyncGroup of the new incarnation = %+v, want the previous assignment", synced)
+	}
+
+	_, results := c.LeaveGroup(&domain.ParsedRequestLeaveGroup{GroupID: "group-a", Members: []domain.LeaveGroupMember{{GroupInstanceID: "instance-1"}}})
+	if results[0].ErrorCode != domain.ErrorCodeNone {
+		t.Errorf("LeaveGroup by instance ID = %+v", results[0])
+	}
+	if errorCode := heartbeat(c, response.MemberID, 1); errorCode != domain.ErrorCodeUnknownMemberID {
+		t.Errorf("heartbeat after leaving = %d, want UNKNOWN_MEMBER_ID", errorCode)
+	}
+}
+
+func TestGroupCoordinator_InitialRebalanceDelay(t *testing.T) {
+	c := newTestCoordinator(WithInitialRebalanceDelay(100 * time.Millisecond))
+	first, second := joinNew(t, c, "range"), joinNew(t, c, "range")
+	firstJoin := join(c, joinRequest(first, "", "range"))
+	secondJoin := join(c, joinRequest(second, "", "range"))
+
+	// Every member joined, the first rebalance still waits for stragglers
+	pending(t, firstJoin)
+	if response := await(t, firstJoin); response.GenerationID != 1 || len(response.Members) != 2 {
+		t.Errorf("delayed JoinGroup = %+v, want generation 1 with both members", response)
+	}
+	await(t, secondJoin)
+}
+
+func TestGroupCoordinator_JoinGroupErrors(t *testing.T) {
+	c := newTestCoordinator()
+	await(t, join(c, joinRequest("", "instance-1", "range")))
+
+	tests := []struct {
+		name string
+		req  *domain.ParsedRequestJoinGroup
+		want int16
+	}{
+		{name: "empty group ID", req: &domain.ParsedRequestJoinGroup{SessionTimeoutMs: 10000, ProtocolType: "consumer"}, want: domain.ErrorCodeInvalidGroupID},
+		{name: "session timeout too short", req: func() *domain.ParsedRequestJoinGroup {
+			req := joinRequest("", "", "range")
+			req.SessionTimeoutMs = 1
+			return req
+		}(), want: domain.ErrorCodeInvalidSessionTimeout},
+		{name: "no common protocol", req: joinRequest("", "", "roundrobin"), want: domain.ErrorCodeInconsistentGroupProtocol},
+		{name: "unknown member", req: joinRequest("stranger", "", "range"), want: domain.ErrorCodeUnknownMemberID},
+		{name: "fenced instance", req: joinRequest("stranger", "instance-1", "range"), want: domain.ErrorCodeFencedInstanceID},
+	}
+	for _, tt := range tests {
+		if response := await(t, join(c, tt.req)); response.ErrorCode != tt.want {
+			t.Errorf("%s: JoinGroup error = %d, want %d", tt.name, response.ErrorCode, tt.want)
+		}
+	}
+}
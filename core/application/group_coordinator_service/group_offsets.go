@@ -0,0 +1,245 @@
+package group_coordinator_service
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+)
+
+// Start loads the committed offsets and starts expiring the offsets of empty groups.
+// Every group with offsets comes back as an empty group; the offsets of transactions that
+// have not ended wait for their marker again.
+func (c *GroupCoordinator) Start() error {
+	if c.offsetsRepository != nil {
+		loaded, err := c.offsetsRepository.LoadOffsets()
+		if err != nil {
+			return err
+		}
+		for groupID, offsets := range loaded.Committed {
+			g := c.group(groupID, true)
+			g.mu.Lock()
+			maps.Copy(g.offsets, offsets)
+			g.mu.Unlock()
+		}
+		c.txnMu.Lock()
+		maps.Copy(c.pendingTxnOffsets, loaded.Pending)
+		c.txnMu.Unlock()
+		fmt.Printf("Loaded the committed offsets of %d group(s)\n", len(loaded.Committed))
+	}
+
+	go c.runOffsetExpiration()
+	return nil
+}
+
+// Close stops expiring offsets
+func (c *GroupCoordinator) Close() {
+	close(c.stopExpiration)
+}
+
+func (c *GroupCoordinator) runOffsetExpiration() {
+	ticker := time.NewTicker(c.offsetsRetentionCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stopExpiration:
+			return
+		case now := <-ticker.C:
+			c.expireOffsets(now)
+		}
+	}
+}
+
+// CommitOffsets stores the offsets once the commit is validated against the group: a
+// member commits within its current generation, while a commit outside of any generation
+// (-1) is only accepted for a group without members.
+func (c *GroupCoordinator) CommitOffsets(req *domain.ParsedRequestOffsetCommit, offsets map[domain.TopicPartition]domain.CommittedOffset) int16 {
+	if req.GroupID == "" {
+		return domain.ErrorCodeInvalidGroupID
+	}
+	g := c.group(req.GroupID, req.GenerationID < 0)
+	if g == nil {
+		return domain.ErrorCodeIllegalGeneration
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if errorCode := g.validateOffsetCommit(req); errorCode != domain.ErrorCodeNone {
+		return errorCode
+	}
+	if c.offsetsRepository != nil {
+		if err := c.offsetsRepository.StoreOffsets(g.groupID, offsets); err != nil {
+			fmt.Printf("Group %s: storing committed offsets failed: %v\n", g.groupID, err)
+			return domain.ErrorCodeCoordinatorNotAvailable
+		}
+	}
+	maps.Copy(g.offsets, offsets)
+
+	// Committing shows the member is alive
+	if m, exists := g.members[req.MemberID]; exists && m.awaitingJoin == nil && m.awaitingSync == nil {
+		c.scheduleHeartbeat(g, m)
+	}
+	return domain.ErrorCodeNone
+}
+
+func (g *group) validateOffsetCommit(req *domain.ParsedRequestOffsetCommit) int16 {
+	if g.state == groupStateDead {
+		return domain.ErrorCodeCoordinatorNotAvailable
+	}
+	if g.consumer != nil {
+		return g.consumer.validateMemberEpoch(req.MemberID, req.GenerationID)
+	}
+	if req.GenerationID < 0 && g.state == groupStateEmpty {
+		return domain.ErrorCodeNone
+	}
+	if errorCode := g.validateMember(req.MemberID, req.GroupInstanceID); errorCode != domain.ErrorCodeNone {
+		return errorCode
+	}
+	if g.state == groupStateCompletingRebalance {
+		return domain.ErrorCodeRebalanceInProgress
+	}
+	if req.GenerationID != g.generationID {
+		return domain.ErrorCodeIllegalGeneration
+	}
+	return domain.ErrorCodeNone
+}
+
+// FetchOffsets returns the offsets the group committed for the requested partitions, -1
+// for those without one, or every committed offset when no topics are requested. A member
+// of a consumer group fetches within its current epoch.
+func (c *GroupCoordinator) FetchOffsets(req domain.OffsetFetchGroup) domain.OffsetFetchResponseGroup {
+	result := domain.OffsetFetchResponseGroup{
+		GroupID:   req.GroupID,
+		Topics:    []domain.OffsetFetchResponseTopic{},
+		ErrorCode: domain.ErrorCodeNone,
+	}
+	committed := map[domain.TopicPartition]domain.CommittedOffset{}
+	if g := c.group(req.GroupID, false); g != nil {
+		g.mu.Lock()
+		if g.consumer != nil && (req.MemberID != "" || req.MemberEpoch >= 0) {
+			result.ErrorCode = g.consumer.validateMemberEpoch(req.MemberID, req.MemberEpoch)
+		}
+		if g.state != groupStateDead {
+			committed = maps.Clone(g.offsets)
+		}
+		g.mu.Unlock()
+	}
+	if result.ErrorCode != domain.ErrorCodeNone {
+		return result
+	}
+
+	if req.Topics == nil {
+		for _, tp := range sortedPartitions(slices.Collect(maps.Keys(committed))) {
+			if len(result.Topics) == 0 || result.Topics[len(result.Topics)-1].Name != tp.Topic {
+				result.Topics = append(result.Topics, domain.OffsetFetchResponseTopic{Name: tp.Topic})
+			}
+			topic := &result.Topics[len(result.Topics)-1]
+			topic.Partitions = append(topic.Partitions, fetchedOffset(tp.Partition, committed[tp], true))
+		}
+		return result
+	}
+	for _, requested := range req.Topics {
+		topic := domain.OffsetFetchResponseTopic{
+			Name:       requested.Name,
+			Partitions: make([]domain.OffsetFetchResponsePartition, 0, len(requested.PartitionIndexes)),
+		}
+		for _, partition := range requested.PartitionIndexes {
+			offset, exists := committed[domain.TopicPartition{Topic: requested.Name, Partition: partition}]
+			topic.Partitions = append(topic.Partitions, fetchedOffset(partition, offset, exists))
+		}
+		result.Topics = append(result.Topics, topic)
+	}
+	return result
+}
+
+func fetchedOffset(partition int32, offset domain.CommittedOffset, exists bool) domain.OffsetFetchResponsePartition {
+	if !exists {
+		return domain.OffsetFetchResponsePartition{PartitionIndex: partition, CommittedOffset: -1, CommittedLeaderEpoch: -1}
+	}
+	return domain.OffsetFetchResponsePartition{
+		PartitionIndex:       partition,
+		CommittedOffset:      offset.Offset,
+		CommittedLeaderEpoch: offset.LeaderEpoch,
+		Metadata:             offset.Metadata,
+	}
+}
+
+// expireOffsets removes the offsets of empty groups that outlived the retention, and
+// the groups left without members or offsets
+func (c *GroupCoordinator) expireOffsets(now time.Time) {
+	c.mu.Lock()
+	groups := slices.Collect(maps.Values(c.groups))
+	c.mu.Unlock()
+
+	for _, g := range groups {
+		g.mu.Lock()
+		c.expireGroupOffsets(g, now)
+		g.mu.Unlock()
+	}
+}
+
+func (c *GroupCoordinator) expireGroupOffsets(g *group, now time.Time) {
+	if !g.isEmpty() {
+		return
+	}
+
+	expired := []domain.TopicPartition{}
+	for tp, offset := range g.offsets {
+		if g.offsetExpired(offset, now, c.offsetsRetention) {
+			expired = append(expired, tp)
+		}
+	}
+	if len(expired) > 0 && c.offsetsRepository != nil {
+		if err := c.offsetsRepository.DeleteOffsets(g.groupID, expired); err != nil {
+			fmt.Printf("Group %s: removing expired offsets failed: %v\n", g.groupID, err)
+			return
+		}
+	}
+	for _, tp := range expired {
+		delete(g.offsets, tp)
+	}
+	if len(expired) > 0 {
+		fmt.Printf("Group %s: removed %d expired offset(s)\n", g.groupID, len(expired))
+	}
+
+	if len(g.offsets) == 0 && len(g.pendingMembers) == 0 {
+		c.removeGroup(g)
+	}
+}
+
+// removeGroup marks the group dead and forgets it. It must be called with g.mu held.
+func (c *GroupCoordinator) removeGroup(g *group) {
+	g.transitionTo(groupStateDead)
+	c.mu.Lock()
+	if c.groups[g.groupID] == g {
+		delete(c.groups, g.groupID)
+	}
+	c.mu.Unlock()
+}
+
+// offsetExpired reports whether an offset of the empty group outlived the retention. The
+// retention of a group that had consumers counts from when it became empty, otherwise
+// from the commit; an offset committed with its own retention expires on its own.
+func (g *group) offsetExpired(offset domain.CommittedOffset, now time.Time, retention time.Duration) bool {
+	if offset.ExpireTimestamp >= 0 {
+		return now.UnixMilli() >= offset.ExpireTimestamp
+	}
+	base := time.UnixMilli(offset.CommitTimestamp)
+	if g.protocolType != "" {
+		base = g.stateTimestamp
+	}
+	return !now.Before(base.Add(retention))
+}
+
+func sortedPartitions(partitions []domain.TopicPartition) []domain.TopicPartition {
+	return slices.SortedFunc(slices.Values(partitions), func(a, b domain.TopicPartition) int {
+		if a.Topic != b.Topic {
+			return strings.Compare(a.Topic, b.Topic)
+		}
+		return cmp.Compare(a.Partition, b.Partition)
+	})
+}
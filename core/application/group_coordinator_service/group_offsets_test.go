@@ -0,0 +1,159 @@
+package group_coordinator_service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/consumer_offsets_repository"
+	partition_file_repository "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/partition_repository"
+)
+
+var orders0 = domain.TopicPartition{Topic: "orders", Partition: 0}
+
+func commit(c *GroupCoordinator, groupID string, memberID string, generation int32, offset int64) int16 {
+	return c.CommitOffsets(
+		&domain.ParsedRequestOffsetCommit{GroupID: groupID, GenerationID: generation, MemberID: memberID, RetentionTimeMs: -1},
+		map[domain.TopicPartition]domain.CommittedOffset{
+			orders0: {Offset: offset, LeaderEpoch: 1, Metadata: "m", CommitTimestamp: time.Now().UnixMilli(), ExpireTimestamp: -1},
+		})
+}
+
+// fetchAll returns the committed offset of every partition of the group
+func fetchAll(c *GroupCoordinator, groupID string) map[domain.TopicPartition]int64 {
+	offsets := map[domain.TopicPartition]int64{}
+	for _, topic := range c.FetchOffsets(domain.OffsetFetchGroup{GroupID: groupID, MemberEpoch: -1}).Topics {
+		for _, partition := range topic.Partitions {
+			offsets[domain.TopicPartition{Topic: topic.Name, Partition: partition.PartitionIndex}] = partition.CommittedOffset
+		}
+	}
+	return offsets
+}
+
+func TestGroupCoordinator_CommitOffsets(t *testing.T) {
+	c := newTestCoordinator()
+
+	// Outside of a generation a group without members takes commits from anyone
+	if errorCode := commit(c, "standalone", "", -1, 10); errorCode != domain.ErrorCodeNone {
+		t.Errorf("commit to a new group = %d, want none", errorCode)
+	}
+	if errorCode := commit(c, "unknown", "member", 3, 10); errorCode != domain.ErrorCodeIllegalGeneration {
+		t.Errorf("commit of a generation to an unknown group = %d, want ILLEGAL_GENERATION", errorCode)
+	}
+	if errorCode := commit(c, "", "", -1, 10); errorCode != domain.ErrorCodeInvalidGroupID {
+		t.Errorf("commit without group = %d, want INVALID_GROUP_ID", errorCode)
+	}
+
+	first := joinNew(t, c, "range")
+	await(t, join(c, joinRequest(first, "", "range")))
+	if errorCode := commit(c, "group-a", first, 1, 10); errorCode != domain.ErrorCodeRebalanceInProgress {
+		t.Errorf("commit before syncing = %d, want REBALANCE_IN_PROGRESS", errorCode)
+	}
+	await(t, syncGroup(c, &domain.ParsedRequestSyncGroup{GroupID: "group-a", GenerationID: 1, MemberID: first}))
+
+	if errorCode := commit(c, "group-a", "", -1, 10); errorCode != domain.ErrorCodeUnknownMemberID {
+		t.Errorf("commit outside of the generation = %d, want UNKNOWN_MEMBER_ID", errorCode)
+	}
+	if errorCode := commit(c, "group-a", first, 0, 10); errorCode != domain.ErrorCodeIllegalGeneration {
+		t.Errorf("commit of an old generation = %d, want ILLEGAL_GENERATION", errorCode)
+	}
+	if errorCode := commit(c, "group-a", first, 1, 42); errorCode != domain.ErrorCodeNone {
+		t.Errorf("commit of the member = %d, want none", errorCode)
+	}
+
+	fetched := c.FetchOffsets(domain.OffsetFetchGroup{GroupID: "group-a", Topics: []domain.OffsetFetchTopic{
+		{Name: "orders", PartitionIndexes: []int32{0, 1}},
+	}})
+	want := []domain.OffsetFetchResponseTopic{{Name: "orders", Partitions: []domain.OffsetFetchResponsePartition{
+		{PartitionIndex: 0, CommittedOffset: 42, CommittedLeaderEpoch: 1, Metadata: "m"},
+		{PartitionIndex: 1, CommittedOffset: -1, CommittedLeaderEpoch: -1},
+	}}}
+	if fetched.ErrorCode != domain.ErrorCodeNone || !reflect.DeepEqual(fetched.Topics, want) {
+		t.Errorf("FetchOffsets() = %+v, want %+v", fetched, want)
+	}
+	if got := fetchAll(c, "unknown"); len(got) != 0 {
+		t.Errorf("offsets of an unknown group = %v, want none", got)
+	}
+}
+
+func TestGroupCoordinator_OffsetsSurviveRestart(t *testing.T) {
+	logDir := t.TempDir()
+	newCoordinator := func() *GroupCoordinator {
+		repository := consumer_offsets_repository.NewConsumerOffsetsRepository(partition_file_repository.NewPartitionFileRepositoryWithLogDir(logDir))
+		c := newTestCoordinator(WithOffsetsRepository(repository))
+		if err := c.Start(); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		t.Cleanup(c.Close)
+		return c
+	}
+
+	c := newCoordinator()
+	commit(c, "group-a", "", -1, 10)
+	commit(c, "group-a", "", -1, 11)
+	commit(c, "group-b", "", -1, 20)
+
+	restarted := newCoordinator()
+	if got := fetchAll(restarted, "group-a"); !reflect.DeepEqual(got, map[domain.TopicPartition]int64{orders0: 11}) {
+		t.Errorf("group-a offsets after restart = %v, want orders-0 at 11", got)
+	}
+	if got := fetchAll(restarted, "group-b"); !reflect.DeepEqual(got, map[domain.TopicPartition]int64{orders0: 20}) {
+		t.Errorf("group-b offsets after restart = %v, want orders-0 at 20", got)
+	}
+
+	// Expired offsets are gone for good
+	restarted.expireOffsets(time.Now().Add(DefaultOffsetsRetention))
+	if got := fetchAll(newCoordinator(), "group-a"); len(got) != 0 {
+		t.Errorf("expired offsets after restart = %v, want none", got)
+	}
+}
+
+func TestGroupCoordinator_ExpireOffsets(t *testing.T) {
+	c := newTestCoordinator(WithOffsetsRetention(time.Hour, time.Hour))
+	now := time.Now()
+
+	// Offsets committed outside of a group expire one by one from their commit
+	commit(c, "standalone", "", -1, 10)
+	c.expireOffsets(now.Add(59 * time.Minute))
+	if got := fetchAll(c, "standalone"); len(got) != 1 {
+		t.Errorf("offsets within the retention = %v, want them kept", got)
+	}
+	c.expireOffsets(now.Add(61 * time.Minute))
+	if got := fetchAll(c, "standalone"); len(got) != 0 {
+		t.Errorf("offsets past the retention = %v, want them removed", got)
+	}
+	if c.group("standalone", false) != nil {
+		t.Error("group without offsets is still there")
+	}
+
+	// An offset committed with its own retention expires on its own
+	c.CommitOffsets(&domain.ParsedRequestOffsetCommit{GroupID: "short", GenerationID: -1},
+		map[domain.TopicPartition]domain.CommittedOffset{orders0: {Offset: 5, CommitTimestamp: now.UnixMilli(), ExpireTimestamp: now.Add(time.Minute).UnixMilli()}})
+	c.expireOffsets(now.Add(2 * time.Minute))
+	if got := fetchAll(c, "short"); len(got) != 0 {
+		t.Errorf("offsets past their own retention = %v, want them removed", got)
+	}
+
+	// The offsets of a consumer group are kept while it has members
+	member := joinNew(t, c, "range")
+	await(t, join(c, joinRequest(member, "", "range")))
+	await(t, syncGroup(c, &domain.ParsedRequestSyncGroup{GroupID: "group-a", GenerationID: 1, MemberID: member}))
+	commit(c, "group-a", member, 1, 42)
+	c.expireOffsets(now.Add(2 * time.Hour))
+	if got := fetchAll(c, "group-a"); len(got) != 1 {
+		t.Errorf("offsets of a stable group = %v, want them kept", got)
+	}
+
+	// Once empty, the retention counts from when the last member left
+	c.LeaveGroup(&domain.ParsedRequestLeaveGroup{GroupID: "group-a", Members: []domain.LeaveGroupMember{{MemberID: member}}})
+	left := time.Now()
+	c.expireOffsets(left.Add(59 * time.Minute))
+	if got := fetchAll(c, "group-a"); len(got) != 1 {
+		t.Errorf("offsets of a group empty within the retention = %v, want them kept", got)
+	}
+	c.expireOffsets(left.Add(61 * time.Minute))
+	if got := fetchAll(c, "group-a"); len(got) != 0 {
+		t.Errorf("offsets of a group empty past the retention = %v, want them removed", got)
+	}
+}
@@ -0,0 +1,79 @@
+package group_coordinator_service
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+)
+
+// CommitTransactionalOffsets stores the offsets a transactional producer commits for the
+// group. They are kept aside until the transaction ends and don't show in the group's
+// committed offsets before. A commit made as a member is validated against the group
+// like a regular one; one outside of any generation is accepted for any group.
+func (c *GroupCoordinator) CommitTransactionalOffsets(req *domain.ParsedRequestTxnOffsetCommit, offsets map[domain.TopicPartition]domain.CommittedOffset) int16 {
+	if req.GroupID == "" {
+		return domain.ErrorCodeInvalidGroupID
+	}
+	g := c.group(req.GroupID, true)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.state == groupStateDead {
+		return domain.ErrorCodeCoordinatorNotAvailable
+	}
+	if req.GenerationID >= 0 || req.MemberID != "" {
+		commit := &domain.ParsedRequestOffsetCommit{
+			GroupID:         req.GroupID,
+			GenerationID:    req.GenerationID,
+			MemberID:        req.MemberID,
+			GroupInstanceID: req.GroupInstanceID,
+		}
+		if errorCode := g.validateOffsetCommit(commit); errorCode != domain.ErrorCodeNone {
+			return errorCode
+		}
+	}
+	if c.offsetsRepository != nil {
+		err := c.offsetsRepository.StoreTransactionalOffsets(g.groupID, req.ProducerID, req.ProducerEpoch, offsets)
+		if errors.Is(err, domain.ErrInvalidProducerEpoch) {
+			return domain.ErrorCodeProducerFenced
+		}
+		if err != nil {
+			fmt.Printf("Group %s: storing offsets of producer %d failed: %v\n", g.groupID, req.ProducerID, err)
+			return domain.ErrorCodeCoordinatorNotAvailable
+		}
+	}
+
+	c.txnMu.Lock()
+	defer c.txnMu.Unlock()
+	if c.pendingTxnOffsets[req.ProducerID] == nil {
+		c.pendingTxnOffsets[req.ProducerID] = make(map[string]map[domain.TopicPartition]domain.CommittedOffset)
+	}
+	pending := c.pendingTxnOffsets[req.ProducerID]
+	if pending[g.groupID] == nil {
+		pending[g.groupID] = make(map[domain.TopicPartition]domain.CommittedOffset)
+	}
+	maps.Copy(pending[g.groupID], offsets)
+	return domain.ErrorCodeNone
+}
+
+// CompleteTransactionalOffsets ends the producer's transaction once its marker is written
+// to __consumer_offsets: the offsets it committed become the groups' committed offsets
+// when it commits, and are dropped when it aborts
+func (c *GroupCoordinator) CompleteTransactionalOffsets(producerID int64, committed bool) {
+	c.txnMu.Lock()
+	groups := c.pendingTxnOffsets[producerID]
+	delete(c.pendingTxnOffsets, producerID)
+	c.txnMu.Unlock()
+
+	if !committed {
+		return
+	}
+	for groupID, offsets := range groups {
+		g := c.group(groupID, true)
+		g.mu.Lock()
+		maps.Copy(g.offsets, offsets)
+		g.mu.Unlock()
+	}
+}
@@ -0,0 +1,85 @@
+package group_coordinator_service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/consumer_offsets_repository"
+	partition_file_repository "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/partition_repository"
+)
+
+func txnCommit(c *GroupCoordinator, groupID string, producerID int64, offset int64) int16 {
+	return c.CommitTransactionalOffsets(
+		&domain.ParsedRequestTxnOffsetCommit{GroupID: groupID, ProducerID: producerID, GenerationID: -1},
+		map[domain.TopicPartition]domain.CommittedOffset{
+			orders0: {Offset: offset, LeaderEpoch: -1, CommitTimestamp: time.Now().UnixMilli(), ExpireTimestamp: -1},
+		})
+}
+
+func TestGroupCoordinator_TransactionalOffsets(t *testing.T) {
+	c := newTestCoordinator()
+	commit(c, "group-a", "", -1, 5)
+
+	if errorCode := txnCommit(c, "group-a", 4000, 10); errorCode != domain.ErrorCodeNone {
+		t.Fatalf("transactional commit = %d, want none", errorCode)
+	}
+	if got := fetchAll(c, "group-a"); got[orders0] != 5 {
+		t.Errorf("offset before the transaction ends = %d, want 5", got[orders0])
+	}
+	c.CompleteTransactionalOffsets(4000, true)
+	if got := fetchAll(c, "group-a"); got[orders0] != 10 {
+		t.Errorf("offset after the transaction commits = %d, want 10", got[orders0])
+	}
+
+	txnCommit(c, "group-a", 4000, 20)
+	c.CompleteTransactionalOffsets(4000, false)
+	if got := fetchAll(c, "group-a"); got[orders0] != 10 {
+		t.Errorf("offset after the transaction aborts = %d, want 10", got[orders0])
+	}
+
+	// As a member the commit is checked against the group
+	errorCode := c.CommitTransactionalOffsets(
+		&domain.ParsedRequestTxnOffsetCommit{GroupID: "group-a", ProducerID: 4000, GenerationID: 3, MemberID: "stranger"},
+		map[domain.TopicPartition]domain.CommittedOffset{orders0: {Offset: 30}})
+	if errorCode != domain.ErrorCodeUnknownMemberID {
+		t.Errorf("transactional commit of an unknown member = %d, want UNKNOWN_MEMBER_ID", errorCode)
+	}
+	if errorCode := txnCommit(c, "", 4000, 30); errorCode != domain.ErrorCodeInvalidGroupID {
+		t.Errorf("transactional commit without group = %d, want INVALID_GROUP_ID", errorCode)
+	}
+}
+
+func TestGroupCoordinator_TransactionalOffsetsSurviveRestart(t *testing.T) {
+	logDir := t.TempDir()
+	partitions := partition_file_repository.NewPartitionFileRepositoryWithLogDir(logDir)
+	repository := consumer_offsets_repository.NewConsumerOffsetsRepository(partitions)
+	c := newTestCoordinator(WithOffsetsRepository(repository))
+	txnCommit(c, "group-a", 4000, 10)
+	txnCommit(c, "group-b", 4001, 20)
+	partitions.WriteTxnMarker(domain.TxnMarkerAppend{
+		TopicName:      domain.ConsumerOffsetsTopic,
+		PartitionIndex: repository.PartitionFor("group-b"),
+		ProducerID:     4001,
+		Committed:      true,
+	})
+
+	// The open transaction completes after the restart
+	restarted := newTestCoordinator(WithOffsetsRepository(
+		consumer_offsets_repository.NewConsumerOffsetsRepository(partition_file_repository.NewPartitionFileRepositoryWithLogDir(logDir))))
+	if err := restarted.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(restarted.Close)
+	if got := fetchAll(restarted, "group-b"); !reflect.DeepEqual(got, map[domain.TopicPartition]int64{orders0: 20}) {
+		t.Errorf("group-b offsets after restart = %v, want orders-0 at 20", got)
+	}
+	if got := fetchAll(restarted, "group-a"); len(got) != 0 {
+		t.Errorf("group-a offsets of the open transaction = %v, want none yet", got)
+	}
+	restarted.CompleteTransactionalOffsets(4000, true)
+	if got := fetchAll(restarted, "group-a"); !reflect.DeepEqual(got, map[domain.TopicPartition]int64{orders0: 10}) {
+		t.Errorf("group-a offsets after the commit = %v, want orders-0 at 10", got)
+	}
+}
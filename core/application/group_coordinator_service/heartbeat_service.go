@@ -0,0 +1,43 @@
+package group_coordinator_service
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// HeartbeatService implements the driving port for Heartbeat requests
+type HeartbeatService struct {
+	parser      parser.HeartbeatParser
+	coordinator *GroupCoordinator
+}
+
+func NewHeartbeatService(parser parser.HeartbeatParser, coordinator *GroupCoordinator) driving.KafkaHandler {
+	return &HeartbeatService{
+		parser:      parser,
+		coordinator: coordinator,
+	}
+}
+
+func (s *HeartbeatService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	responseData := &domain.ResponseDataHeartbeat{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		ErrorCode:      s.coordinator.Heartbeat(parsedReq),
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
@@ -0,0 +1,43 @@
+package group_coordinator_service
+
+import (
+	"fmt"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// JoinGroupService implements the driving port for JoinGroup requests. The response is
+// deferred until the rebalance the member joined completes.
+type JoinGroupService struct {
+	parser      parser.JoinGroupParser
+	coordinator *GroupCoordinator
+}
+
+func NewJoinGroupService(parser parser.JoinGroupParser, coordinator *GroupCoordinator) driving.KafkaHandler {
+	return &JoinGroupService{
+		parser:      parser,
+		coordinator: coordinator,
+	}
+}
+
+func (s *JoinGroupService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	deferred := make(chan domain.Response, 1)
+	s.coordinator.JoinGroup(parsedReq, func(responseData domain.ResponseDataJoinGroup) {
+		responseData.CorrelationID = parsedReq.CorrelationID
+		responseData.APIVersion = parsedReq.APIVersion
+		encodedResponse, err := s.parser.EncodeResponse(&responseData)
+		if err != nil {
+			fmt.Printf("Encoding JoinGroup response failed: %v\n", err)
+		}
+		deferred <- domain.Response{Data: encodedResponse}
+	})
+
+	return domain.Response{Deferred: deferred}, nil
+}
@@ -0,0 +1,54 @@
+package group_coordinator_service
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// leaveGroupFirstBatchVersion is the first LeaveGroup version that removes several
+// members at once and reports an error per member
+const leaveGroupFirstBatchVersion = 3
+
+// LeaveGroupService implements the driving port for LeaveGroup requests
+type LeaveGroupService struct {
+	parser      parser.LeaveGroupParser
+	coordinator *GroupCoordinator
+}
+
+func NewLeaveGroupService(parser parser.LeaveGroupParser, coordinator *GroupCoordinator) driving.KafkaHandler {
+	return &LeaveGroupService{
+		parser:      parser,
+		coordinator: coordinator,
+	}
+}
+
+func (s *LeaveGroupService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	errorCode, members := s.coordinator.LeaveGroup(parsedReq)
+	// Before batching the single member's error is the request's
+	if parsedReq.APIVersion < leaveGroupFirstBatchVersion && errorCode == domain.ErrorCodeNone && len(members) > 0 {
+		errorCode = members[0].ErrorCode
+	}
+
+	responseData := &domain.ResponseDataLeaveGroup{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		ErrorCode:      errorCode,
+		Members:        members,
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
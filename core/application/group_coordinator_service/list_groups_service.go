@@ -0,0 +1,44 @@
+package group_coordinator_service
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// ListGroupsService implements the driving port for ListGroups requests
+type ListGroupsService struct {
+	parser      parser.ListGroupsParser
+	coordinator *GroupCoordinator
+}
+
+func NewListGroupsService(parser parser.ListGroupsParser, coordinator *GroupCoordinator) driving.KafkaHandler {
+	return &ListGroupsService{
+		parser:      parser,
+		coordinator: coordinator,
+	}
+}
+
+func (s *ListGroupsService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	responseData := &domain.ResponseDataListGroups{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		ErrorCode:      domain.ErrorCodeNone,
+		Groups:         s.coordinator.ListGroups(parsedReq.StatesFilter, parsedReq.TypesFilter),
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
@@ -0,0 +1,108 @@
+package group_coordinator_service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+)
+
+// maxOffsetMetadataSize matches Kafka's offset.metadata.max.bytes
+const maxOffsetMetadataSize = 4096
+
+// OffsetCommitService implements the driving port for OffsetCommit requests
+type OffsetCommitService struct {
+	parser              parser.OffsetCommitParser
+	metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository
+	coordinator         *GroupCoordinator
+}
+
+func NewOffsetCommitService(parser parser.OffsetCommitParser, metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository, coordinator *GroupCoordinator) driving.KafkaHandler {
+	return &OffsetCommitService{
+		parser:              parser,
+		metadata_repository: metadata_repository,
+		coordinator:         coordinator,
+	}
+}
+
+func (s *OffsetCommitService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	clusterMetaData, err := s.metadata_repository.GetClusterMetadata()
+	if err != nil {
+		fmt.Println("OffsetCommit: there is no cluster metadata", err.Error())
+	}
+
+	responseData := &domain.ResponseDataOffsetCommit{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		Topics:         make([]domain.OffsetCommitResponseTopic, 0, len(parsedReq.Topics)),
+	}
+
+	// Partitions that pass the checks here get the outcome of the commit
+	now := time.Now().UnixMilli()
+	offsets := map[domain.TopicPartition]domain.CommittedOffset{}
+	committed := []*domain.OffsetCommitResponsePartition{}
+	for i, topic := range parsedReq.Topics {
+		responseData.Topics = append(responseData.Topics, domain.OffsetCommitResponseTopic{
+			Name:       topic.Name,
+			Partitions: make([]domain.OffsetCommitResponsePartition, len(topic.Partitions)),
+		})
+		for j, partition := range topic.Partitions {
+			result := &responseData.Topics[i].Partitions[j]
+			result.PartitionIndex = partition.PartitionIndex
+			switch {
+			case clusterMetaData.FindPartition(topic.Name, partition.PartitionIndex) == nil:
+				result.ErrorCode = domain.ErrorCodeUnknownTopicOrPartition
+			case len(partition.CommittedMetadata) > maxOffsetMetadataSize:
+				result.ErrorCode = domain.ErrorCodeOffsetMetadataTooLarge
+			default:
+				tp := domain.TopicPartition{Topic: topic.Name, Partition: partition.PartitionIndex}
+				offsets[tp] = committedOffset(parsedReq, partition, now)
+				committed = append(committed, result)
+			}
+		}
+	}
+
+	if len(offsets) > 0 {
+		errorCode := s.coordinator.CommitOffsets(parsedReq, offsets)
+		for _, result := range committed {
+			result.ErrorCode = errorCode
+		}
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
+
+// committedOffset is what gets stored for a partition. Only v1 sets the commit timestamp
+// and only v2-v4 ask for a retention of their own.
+func committedOffset(req *domain.ParsedRequestOffsetCommit, partition domain.OffsetCommitPartition, now int64) domain.CommittedOffset {
+	offset := domain.CommittedOffset{
+		Offset:          partition.CommittedOffset,
+		LeaderEpoch:     partition.CommittedLeaderEpoch,
+		Metadata:        partition.CommittedMetadata,
+		CommitTimestamp: now,
+		ExpireTimestamp: -1,
+	}
+	if partition.CommitTimestamp >= 0 {
+		offset.CommitTimestamp = partition.CommitTimestamp
+	}
+	if req.RetentionTimeMs >= 0 {
+		offset.ExpireTimestamp = now + req.RetentionTimeMs
+	}
+	return offset
+}
@@ -0,0 +1,84 @@
+package group_coordinator_service
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/cluster_metadata_repository"
+)
+
+type mockOffsetCommitParser struct {
+	request  *domain.ParsedRequestOffsetCommit
+	response *domain.ResponseDataOffsetCommit
+}
+
+func (m *mockOffsetCommitParser) ParseRequest(data []byte) (*domain.ParsedRequestOffsetCommit, error) {
+	return m.request, nil
+}
+
+func (m *mockOffsetCommitParser) EncodeResponse(response *domain.ResponseDataOffsetCommit) ([]byte, error) {
+	m.response = response
+	return []byte{}, nil
+}
+
+func TestOffsetCommitService_HandleRequest(t *testing.T) {
+	metadata := cluster_metadata_repository.NewClusterMetadataRepository(
+		cluster_metadata_repository.WithMetadataLogDir(filepath.Join(t.TempDir(), "__cluster_metadata-0")))
+	if err := metadata.CreateTopic(domain.NewTopic{
+		Name:       "orders",
+		TopicID:    bytes.Repeat([]byte{0xaa}, 16),
+		Partitions: []domain.NewPartition{{PartitionIndex: 0, Replicas: []int32{1}}},
+	}); err != nil {
+		t.Fatalf("CreateTopic() error = %v", err)
+	}
+	coordinator := newTestCoordinator()
+
+	handle := func(request *domain.ParsedRequestOffsetCommit) []domain.OffsetCommitResponseTopic {
+		t.Helper()
+		mockParser := &mockOffsetCommitParser{request: request}
+		service := NewOffsetCommitService(mockParser, metadata, coordinator)
+		if _, err := service.HandleRequest(domain.Request{}); err != nil {
+			t.Fatalf("HandleRequest() error = %v", err)
+		}
+		return mockParser.response.Topics
+	}
+
+	topics := []domain.OffsetCommitTopic{
+		{Name: "orders", Partitions: []domain.OffsetCommitPartition{
+			{PartitionIndex: 0, CommittedOffset: 42, CommittedLeaderEpoch: 3, CommitTimestamp: -1, CommittedMetadata: "checkpoint"},
+			{PartitionIndex: 1, CommittedOffset: 7, CommitTimestamp: -1},
+		}},
+		{Name: "missing", Partitions: []domain.OffsetCommitPartition{{PartitionIndex: 0, CommittedOffset: 1, CommitTimestamp: -1}}},
+	}
+	got := handle(&domain.ParsedRequestOffsetCommit{APIVersion: 9, GroupID: "group-a", GenerationID: -1, RetentionTimeMs: -1, Topics: topics})
+	want := []domain.OffsetCommitResponseTopic{
+		{Name: "orders", Partitions: []domain.OffsetCommitResponsePartition{
+			{PartitionIndex: 0, ErrorCode: domain.ErrorCodeNone},
+			{PartitionIndex: 1, ErrorCode: domain.ErrorCodeUnknownTopicOrPartition},
+		}},
+		{Name: "missing", Partitions: []domain.OffsetCommitResponsePartition{{PartitionIndex: 0, ErrorCode: domain.ErrorCodeUnknownTopicOrPartition}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("response topics = %+v, want %+v", got, want)
+	}
+	fetched := coordinator.FetchOffsets(domain.OffsetFetchGroup{GroupID: "group-a"}).Topics
+	if len(fetched) != 1 || fetched[0].Partitions[0].CommittedOffset != 42 || fetched[0].Partitions[0].Metadata != "checkpoint" {
+		t.Errorf("committed offsets = %+v, want orders-0 at 42", fetched)
+	}
+
+	// Metadata over the limit is refused; the group's verdict applies to the rest
+	got = handle(&domain.ParsedRequestOffsetCommit{APIVersion: 9, GroupID: "group-a", GenerationID: 4, MemberID: "stranger", RetentionTimeMs: -1,
+		Topics: []domain.OffsetCommitTopic{{Name: "orders", Partitions: []domain.OffsetCommitPartition{
+			{PartitionIndex: 0, CommittedOffset: 50, CommitTimestamp: -1},
+		}}, {Name: "orders", Partitions: []domain.OffsetCommitPartition{
+			{PartitionIndex: 0, CommittedOffset: 50, CommitTimestamp: -1, CommittedMetadata: strings.Repeat("x", maxOffsetMetadataSize+1)},
+		}}},
+	})
+	if got[0].Partitions[0].ErrorCode != domain.ErrorCodeUnknownMemberID || got[1].Partitions[0].ErrorCode != domain.ErrorCodeOffsetMetadataTooLarge {
+		t.Errorf("response topics = %+v, want UNKNOWN_MEMBER_ID and OFFSET_METADATA_TOO_LARGE", got)
+	}
+}
@@ -0,0 +1,84 @@
+package group_coordinator_service
+
+import (
+	"fmt"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+)
+
+// OffsetDeleteService implements the driving port for OffsetDelete requests
+type OffsetDeleteService struct {
+	parser              parser.OffsetDeleteParser
+	metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository
+	coordinator         *GroupCoordinator
+}
+
+func NewOffsetDeleteService(parser parser.OffsetDeleteParser, metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository, coordinator *GroupCoordinator) driving.KafkaHandler {
+	return &OffsetDeleteService{
+		parser:              parser,
+		metadata_repository: metadata_repository,
+		coordinator:         coordinator,
+	}
+}
+
+func (s *OffsetDeleteService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	clusterMetaData, err := s.metadata_repository.GetClusterMetadata()
+	if err != nil {
+		fmt.Println("OffsetDelete: there is no cluster metadata", err.Error())
+	}
+
+	// Only the offsets of existing partitions are handed to the coordinator
+	partitions := []domain.TopicPartition{}
+	for _, topic := range parsedReq.Topics {
+		for _, partition := range topic.Partitions {
+			if clusterMetaData.FindPartition(topic.Name, partition) != nil {
+				partitions = append(partitions, domain.TopicPartition{Topic: topic.Name, Partition: partition})
+			}
+		}
+	}
+	errorCode, results := s.coordinator.DeleteOffsets(parsedReq.GroupID, partitions)
+
+	responseData := &domain.ResponseDataOffsetDelete{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ErrorCode:      errorCode,
+		ThrottleTimeMs: 0,
+		Topics:         []domain.OffsetDeleteResponseTopic{},
+	}
+	if errorCode == domain.ErrorCodeNone {
+		for _, topic := range parsedReq.Topics {
+			responseTopic := domain.OffsetDeleteResponseTopic{
+				Name:       topic.Name,
+				Partitions: make([]domain.OffsetDeleteResponsePartition, 0, len(topic.Partitions)),
+			}
+			for _, partition := range topic.Partitions {
+				partitionErrorCode, exists := results[domain.TopicPartition{Topic: topic.Name, Partition: partition}]
+				if !exists {
+					partitionErrorCode = domain.ErrorCodeUnknownTopicOrPartition
+				}
+				responseTopic.Partitions = append(responseTopic.Partitions, domain.OffsetDeleteResponsePartition{
+					PartitionIndex: partition,
+					ErrorCode:      partitionErrorCode,
+				})
+			}
+			responseData.Topics = append(responseData.Topics, responseTopic)
+		}
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
@@ -0,0 +1,73 @@
+package group_coordinator_service
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/cluster_metadata_repository"
+)
+
+type mockOffsetDeleteParser struct {
+	request  *domain.ParsedRequestOffsetDelete
+	response *domain.ResponseDataOffsetDelete
+}
+
+func (m *mockOffsetDeleteParser) ParseRequest(data []byte) (*domain.ParsedRequestOffsetDelete, error) {
+	return m.request, nil
+}
+
+func (m *mockOffsetDeleteParser) EncodeResponse(response *domain.ResponseDataOffsetDelete) ([]byte, error) {
+	m.response = response
+	return []byte{}, nil
+}
+
+func TestOffsetDeleteService_HandleRequest(t *testing.T) {
+	metadata := cluster_metadata_repository.NewClusterMetadataRepository(
+		cluster_metadata_repository.WithMetadataLogDir(filepath.Join(t.TempDir(), "__cluster_metadata-0")))
+	if err := metadata.CreateTopic(domain.NewTopic{
+		Name:       "orders",
+		TopicID:    bytes.Repeat([]byte{0xaa}, 16),
+		Partitions: []domain.NewPartition{{PartitionIndex: 0, Replicas: []int32{1}}},
+	}); err != nil {
+		t.Fatalf("CreateTopic() error = %v", err)
+	}
+	coordinator := newTestCoordinator()
+	commit(coordinator, "group-a", "", -1, 42)
+
+	handle := func(request *domain.ParsedRequestOffsetDelete) *domain.ResponseDataOffsetDelete {
+		t.Helper()
+		mockParser := &mockOffsetDeleteParser{request: request}
+		service := NewOffsetDeleteService(mockParser, metadata, coordinator)
+		if _, err := service.HandleRequest(domain.Request{}); err != nil {
+			t.Fatalf("HandleRequest() error = %v", err)
+		}
+		return mockParser.response
+	}
+
+	got := handle(&domain.ParsedRequestOffsetDelete{GroupID: "group-a", Topics: []domain.OffsetDeleteTopic{
+		{Name: "orders", Partitions: []int32{0, 1}},
+		{Name: "missing", Partitions: []int32{0}},
+	}})
+	want := []domain.OffsetDeleteResponseTopic{
+		{Name: "orders", Partitions: []domain.OffsetDeleteResponsePartition{
+			{PartitionIndex: 0, ErrorCode: domain.ErrorCodeNone},
+			{PartitionIndex: 1, ErrorCode: domain.ErrorCodeUnknownTopicOrPartition},
+		}},
+		{Name: "missing", Partitions: []domain.OffsetDeleteResponsePartition{{PartitionIndex: 0, ErrorCode: domain.ErrorCodeUnknownTopicOrPartition}}},
+	}
+	if got.ErrorCode != domain.ErrorCodeNone || !reflect.DeepEqual(got.Topics, want) {
+		t.Errorf("response = %+v, want topics %+v", got, want)
+	}
+	if offsets := fetchAll(coordinator, "group-a"); len(offsets) != 0 {
+		t.Errorf("offsets after OffsetDelete = %v, want none", offsets)
+	}
+
+	// A group-level error leaves the topics out
+	got = handle(&domain.ParsedRequestOffsetDelete{GroupID: "unknown", Topics: []domain.OffsetDeleteTopic{{Name: "orders", Partitions: []int32{0}}}})
+	if got.ErrorCode != domain.ErrorCodeGroupIDNotFound || len(got.Topics) != 0 {
+		t.Errorf("response for an unknown group = %+v, want GROUP_ID_NOT_FOUND without topics", got)
+	}
+}
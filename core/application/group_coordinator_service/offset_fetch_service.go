@@ -0,0 +1,58 @@
+package group_coordinator_service
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// offsetFetchFirstGroupErrorVersion is the first OffsetFetch version with a group-level
+// error code; older versions report it on every partition
+const offsetFetchFirstGroupErrorVersion = 2
+
+// OffsetFetchService implements the driving port for OffsetFetch requests
+type OffsetFetchService struct {
+	parser      parser.OffsetFetchParser
+	coordinator *GroupCoordinator
+}
+
+func NewOffsetFetchService(parser parser.OffsetFetchParser, coordinator *GroupCoordinator) driving.KafkaHandler {
+	return &OffsetFetchService{
+		parser:      parser,
+		coordinator: coordinator,
+	}
+}
+
+func (s *OffsetFetchService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	responseData := &domain.ResponseDataOffsetFetch{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		Groups:         make([]domain.OffsetFetchResponseGroup, 0, len(parsedReq.Groups)),
+	}
+	for _, group := range parsedReq.Groups {
+		result := s.coordinator.FetchOffsets(group)
+		if parsedReq.APIVersion < offsetFetchFirstGroupErrorVersion && result.ErrorCode != domain.ErrorCodeNone {
+			for _, topic := range result.Topics {
+				for i := range topic.Partitions {
+					topic.Partitions[i].ErrorCode = result.ErrorCode
+				}
+			}
+		}
+		responseData.Groups = append(responseData.Groups, result)
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
@@ -0,0 +1,43 @@
+package group_coordinator_service
+
+import (
+	"fmt"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// SyncGroupService implements the driving port for SyncGroup requests. The response is
+// deferred until the leader sent the assignment of the generation.
+type SyncGroupService struct {
+	parser      parser.SyncGroupParser
+	coordinator *GroupCoordinator
+}
+
+func NewSyncGroupService(parser parser.SyncGroupParser, coordinator *GroupCoordinator) driving.KafkaHandler {
+	return &SyncGroupService{
+		parser:      parser,
+		coordinator: coordinator,
+	}
+}
+
+func (s *SyncGroupService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	deferred := make(chan domain.Response, 1)
+	s.coordinator.SyncGroup(parsedReq, func(responseData domain.ResponseDataSyncGroup) {
+		responseData.CorrelationID = parsedReq.CorrelationID
+		responseData.APIVersion = parsedReq.APIVersion
+		encodedResponse, err := s.parser.EncodeResponse(&responseData)
+		if err != nil {
+			fmt.Printf("Encoding SyncGroup response failed: %v\n", err)
+		}
+		deferred <- domain.Response{Data: encodedResponse}
+	})
+
+	return domain.Response{Deferred: deferred}, nil
+}
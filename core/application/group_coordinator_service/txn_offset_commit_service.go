@@ -0,0 +1,92 @@
+package group_coordinator_service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+)
+
+// TxnOffsetCommitService implements the driving port for TxnOffsetCommit requests
+type TxnOffsetCommitService struct {
+	parser              parser.TxnOffsetCommitParser
+	metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository
+	coordinator         *GroupCoordinator
+}
+
+func NewTxnOffsetCommitService(parser parser.TxnOffsetCommitParser, metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository, coordinator *GroupCoordinator) driving.KafkaHandler {
+	return &TxnOffsetCommitService{
+		parser:              parser,
+		metadata_repository: metadata_repository,
+		coordinator:         coordinator,
+	}
+}
+
+func (s *TxnOffsetCommitService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	clusterMetaData, err := s.metadata_repository.GetClusterMetadata()
+	if err != nil {
+		fmt.Println("TxnOffsetCommit: there is no cluster metadata", err.Error())
+	}
+
+	responseData := &domain.ResponseDataTxnOffsetCommit{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		Topics:         make([]domain.TxnOffsetCommitResponseTopic, 0, len(parsedReq.Topics)),
+	}
+
+	// Partitions that pass the checks here get the outcome of the commit
+	now := time.Now().UnixMilli()
+	offsets := map[domain.TopicPartition]domain.CommittedOffset{}
+	committed := []*domain.TxnOffsetCommitResponsePartition{}
+	for i, topic := range parsedReq.Topics {
+		responseData.Topics = append(responseData.Topics, domain.TxnOffsetCommitResponseTopic{
+			Name:       topic.Name,
+			Partitions: make([]domain.TxnOffsetCommitResponsePartition, len(topic.Partitions)),
+		})
+		for j, partition := range topic.Partitions {
+			result := &responseData.Topics[i].Partitions[j]
+			result.PartitionIndex = partition.PartitionIndex
+			switch {
+			case clusterMetaData.FindPartition(topic.Name, partition.PartitionIndex) == nil:
+				result.ErrorCode = domain.ErrorCodeUnknownTopicOrPartition
+			case len(partition.CommittedMetadata) > maxOffsetMetadataSize:
+				result.ErrorCode = domain.ErrorCodeOffsetMetadataTooLarge
+			default:
+				tp := domain.TopicPartition{Topic: topic.Name, Partition: partition.PartitionIndex}
+				offsets[tp] = domain.CommittedOffset{
+					Offset:          partition.CommittedOffset,
+					LeaderEpoch:     partition.CommittedLeaderEpoch,
+					Metadata:        partition.CommittedMetadata,
+					CommitTimestamp: now,
+					ExpireTimestamp: -1,
+				}
+				committed = append(committed, result)
+			}
+		}
+	}
+
+	if len(offsets) > 0 {
+		errorCode := s.coordinator.CommitTransactionalOffsets(parsedReq, offsets)
+		for _, result := range committed {
+			result.ErrorCode = errorCode
+		}
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
@@ -1,12 +1,15 @@
 package kafka_describe_topic_service
 
 import (
+	"bytes"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/codecrafters-io/kafka-starter-go/core/domain"
 	portparser "github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
 	infraparser "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/parser"
-	infraClusterMetadata "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/cluster_metadata"
+	infraClusterMetadata "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/cluster_metadata_repository"
 )
 
 // mockParser is a mock implementation of ProtocolParser for testing
@@ -51,3 +54,52 @@ func TestKafkaKafkaDescribeService(t *testing.T) {
 		})
 	}
 }
+
+func TestKafkaDescribeService_ConcurrentWithTailing(t *testing.T) {
+	metadata := infraClusterMetadata.NewClusterMetadataRepository(
+		infraClusterMetadata.WithMetadataLogDir(t.TempDir()),
+		infraClusterMetadata.WithPollInterval(time.Millisecond))
+	topicID := bytes.Repeat([]byte{0xaa}, 16)
+	if err := metadata.CreateTopic(domain.NewTopic{Name: "foo", TopicID: topicID, Partitions: []domain.NewPartition{{PartitionIndex: 0, Replicas: []int32{1}}}}); err != nil {
+		t.Fatalf("CreateTopic() error = %v", err)
+	}
+	if err := metadata.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer metadata.Close()
+
+	parser := &mockParser{
+		parseRequestFunc: func(data []byte) (*portparser.ParsedRequestDescribeTopic, error) {
+			return &portparser.ParsedRequestDescribeTopic{
+				CorrelationIdBytes: []byte{0x00, 0x00, 0x00, 0x01},
+				Topics:             []portparser.ParsedTopic{{TopicName: "foo", TopicNameBytes: []byte("foo")}},
+			}, nil
+		},
+		encodeResponseFunc: func(response *portparser.ResponseDataDescribeTopic) ([]byte, error) {
+			if topics := response.Topics; len(topics) != 1 || len(topics[0].Partitions) == 0 {
+				t.Errorf("topics = %+v, want foo with its partitions", topics)
+			}
+			return nil, nil
+		},
+	}
+	service := NewKafkaDescribeTopicService(parser, metadata)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 200 {
+				if _, err := service.HandleRequest(domain.Request{}); err != nil {
+					t.Errorf("HandleRequest() error = %v", err)
+				}
+			}
+		}()
+	}
+	for partitionIndex := int32(1); partitionIndex <= 10; partitionIndex++ {
+		if err := metadata.CreatePartitions(topicID, []domain.NewPartition{{PartitionIndex: partitionIndex, Replicas: []int32{1}}}); err != nil {
+			t.Errorf("CreatePartitions(%d) error = %v", partitionIndex, err)
+		}
+	}
+	wg.Wait()
+}
@@ -53,8 +53,6 @@ func (s *KafkaDescribeService) HandleRequest(req domain.Request) (domain.Respons
 	fmt.Printf("TopicUUIDPartitionMetadataMap: %+v\n", clusterMetadata.TopicUUIDPartitionMetadataMap)
 	fmt.Printf("TopicNameTopicUuidMap: %+v\n", clusterMetadata.TopicNameTopicUuidMap)
 
-	s.GetPartitionMetadataForTopic(clusterMetadata)
-
 	//responseData := getOriginalResponse(parsedReq, topicResponseInfo)
 
 	fmt.Printf("Topics to find: %+v \n", topicsToFind)
@@ -127,7 +125,8 @@ func (s *KafkaDescribeService) GetTopicsFromRequestData(clusterMetadata cluster_
 
 func (s *KafkaDescribeService) GetTopicsNotFoundFromRequestData(topicsToFind map[string]parser.TopicNameInfo, clusterMetadata cluster_metadata_port.ClusterMetadataRepositoryResponse, topicsUnknown []parser.ResponseDataDescribeTopicInfo) []parser.ResponseDataDescribeTopicInfo {
 	for topicName, topicToFind := range topicsToFind {
-		if _, exists := clusterMetadata.TopicNameTopicUuidMap[topicName]; !exists {
+		topicUuid, exists := clusterMetadata.TopicNameTopicUuidMap[topicName]
+		if _, hasMetadata := clusterMetadata.TopicUUIDTopicMetadataInfoMap[topicUuid]; !exists || !hasMetadata {
 
 			fmt.Printf("Could Not find Topic: %+v \n", topicName)
 			nonExistingInfo := parser.ResponseDataDescribeTopicInfo{
@@ -146,13 +145,3 @@ func (s *KafkaDescribeService) GetTopicsNotFoundFromRequestData(topicsToFind map
 	}
 	return topicsUnknown
 }
-
-func (s *KafkaDescribeService) GetPartitionMetadataForTopic(clusterMetadata cluster_metadata_port.ClusterMetadataRepositoryResponse) {
-	for topicUuid, partitionMetadata := range clusterMetadata.TopicUUIDPartitionMetadataMap {
-		if topicMetadata, exists := clusterMetadata.TopicUUIDTopicMetadataInfoMap[topicUuid]; !exists {
-			panic("Partition Metadata has invalid topic")
-		} else {
-			topicMetadata.PartitionsArray = partitionMetadata
-		}
-	}
-}
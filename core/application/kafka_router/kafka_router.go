@@ -11,18 +11,25 @@ import (
 // KafkaRouter is a unified handler that routes requests to the appropriate service
 // based on the API key in the request.
 type KafkaRouter struct {
-	apiVersionsHandler   driving.KafkaHandler
-	describeTopicHandler driving.KafkaHandler
-	fetchHandler         driving.KafkaHandler
+	apiVersionsHandler driving.KafkaHandler
+	handlers           map[int16]driving.KafkaHandler
 }
 
 // NewKafkaRouter creates a new Kafka router that implements the driving port
-func NewKafkaRouter(apiVersionsHandler, describeTopicHandler driving.KafkaHandler, fetchHandler driving.KafkaHandler) driving.KafkaHandler {
-	return &KafkaRouter{
-		apiVersionsHandler:   apiVersionsHandler,
-		describeTopicHandler: describeTopicHandler,
-		fetchHandler:         fetchHandler,
+func NewKafkaRouter(apiVersionsHandler, describeTopicHandler driving.KafkaHandler, fetchHandler driving.KafkaHandler) *KafkaRouter {
+	router := &KafkaRouter{
+		apiVersionsHandler: apiVersionsHandler,
+		handlers:           make(map[int16]driving.KafkaHandler),
 	}
+	router.RegisterHandler(domain.ApiKeyFetch, fetchHandler)
+	router.RegisterHandler(domain.ApiKeyApiVersions, apiVersionsHandler)
+	router.RegisterHandler(domain.ApiKeyDescribeTopicPartitions, describeTopicHandler)
+	return router
+}
+
+// RegisterHandler routes requests with the given API key to handler
+func (r *KafkaRouter) RegisterHandler(apiKey int16, handler driving.KafkaHandler) {
+	r.handlers[apiKey] = handler
 }
 
 // HandleRequest routes the request to the appropriate handler based on the API key.
@@ -37,24 +44,15 @@ func (r *KafkaRouter) HandleRequest(req domain.Request) (domain.Response, error)
 
 	// API key is at bytes 4-5 (0-indexed: indices 4 and 5)
 	apiKeyBytes := req.Data[4:6]
-	apiKey := binary.BigEndian.Uint16(apiKeyBytes)
-
-	// Route based on API key
-	// API Key 1 == Fetch
-	// API key 18 (0x00, 0x12) = ApiVersions
-	// API key 75 (0x4B) = DescribeTopicPartitions
+	apiKey := int16(binary.BigEndian.Uint16(apiKeyBytes))
 
 	fmt.Printf("Sent API Key %+v\n", apiKey)
 
-	switch apiKey {
-	case 1:
-		return r.fetchHandler.HandleRequest(req)
-	case 18: // ApiVersions
-		return r.apiVersionsHandler.HandleRequest(req)
-	case 75: // DescribeTopicPartitions
-		return r.describeTopicHandler.HandleRequest(req)
-	default:
+	// Route based on API key, see domain/api_keys.go
+	handler, exists := r.handlers[apiKey]
+	if !exists {
 		// Default to API Versions handler for unknown API keys
 		return r.apiVersionsHandler.HandleRequest(req)
 	}
+	return handler.HandleRequest(req)
 }
@@ -0,0 +1,160 @@
+package list_offsets_service
+
+import (
+	"fmt"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+	port_repo "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/partition_file_repository"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// ListOffsetsService implements the driving port for ListOffsets requests, which
+// consumers use to find the offset to start reading from.
+type ListOffsetsService struct {
+	parser                    parser.ListOffsetsParser
+	metadata_repository       port_cluster_metadata_repository.ClusterMetadataRepository
+	partition_file_repository port_repo.PartitionFileRepository
+}
+
+func NewListOffsetsService(parser parser.ListOffsetsParser, metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository, partition_file_repository port_repo.PartitionFileRepository) driving.KafkaHandler {
+	return &ListOffsetsService{
+		parser:                    parser,
+		metadata_repository:       metadata_repository,
+		partition_file_repository: partition_file_repository,
+	}
+}
+
+func (s *ListOffsetsService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	clusterMetaData, err := s.metadata_repository.GetClusterMetadata()
+	if err != nil {
+		fmt.Println("ListOffsets: there is no cluster metadata", err.Error())
+	}
+
+	responseData := &domain.ResponseDataListOffsets{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		Topics:         make([]domain.ListOffsetsResponseTopic, 0, len(parsedReq.Topics)),
+	}
+
+	for _, topic := range parsedReq.Topics {
+		responseTopic := domain.ListOffsetsResponseTopic{
+			Name:       topic.Name,
+			Partitions: make([]domain.ListOffsetsResponsePartition, 0, len(topic.Partitions)),
+		}
+		for _, partition := range topic.Partitions {
+			responseTopic.Partitions = append(responseTopic.Partitions, s.listOffset(clusterMetaData, parsedReq.IsolationLevel, topic.Name, partition))
+		}
+		responseData.Topics = append(responseData.Topics, responseTopic)
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
+
+func (s *ListOffsetsService) listOffset(clusterMetaData port_cluster_metadata_repository.ClusterMetadataRepositoryResponse, isolationLevel int8, topicName string, partition domain.ListOffsetsPartition) domain.ListOffsetsResponsePartition {
+	result := domain.ListOffsetsResponsePartition{
+		PartitionIndex: partition.PartitionIndex,
+		ErrorCode:      domain.ErrorCodeNone,
+		Timestamp:      -1,
+		Offset:         -1,
+		LeaderEpoch:    -1,
+	}
+
+	partitionMetadata := clusterMetaData.FindPartition(topicName, partition.PartitionIndex)
+	if partitionMetadata == nil {
+		result.ErrorCode = domain.ErrorCodeUnknownTopicOrPartition
+		return result
+	}
+
+	leaderEpoch := int32(common.BytesToInt(partitionMetadata.LeaderEpoch))
+	if errorCode := validateLeaderEpoch(partition.CurrentLeaderEpoch, leaderEpoch); errorCode != domain.ErrorCodeNone {
+		result.ErrorCode = errorCode
+		return result
+	}
+
+	partitionIndex := int(partition.PartitionIndex)
+	switch partition.Timestamp {
+	case domain.ListOffsetsEarliestTimestamp, domain.ListOffsetsEarliestLocalTimestamp:
+		logOffsets, err := s.partition_file_repository.GetLogOffsets(topicName, partitionIndex)
+		if err != nil {
+			return storageError(result, err)
+		}
+		result.Offset = logOffsets.LogStartOffset
+		result.LeaderEpoch = leaderEpoch
+		if first, err := s.partition_file_repository.FindOffsetByTimestamp(topicName, partitionIndex, -1); err == nil && first.Found {
+			result.LeaderEpoch = first.LeaderEpoch
+		}
+	case domain.ListOffsetsLatestTimestamp:
+		logOffsets, err := s.partition_file_repository.GetLogOffsets(topicName, partitionIndex)
+		if err != nil {
+			return storageError(result, err)
+		}
+		result.Offset = logOffsets.HighWatermark
+		if isolationLevel == domain.IsolationLevelReadCommitted {
+			result.Offset = logOffsets.LastStableOffset
+		}
+		result.LeaderEpoch = leaderEpoch
+	case domain.ListOffsetsMaxTimestamp:
+		lookup, err := s.partition_file_repository.FindOffsetOfMaxTimestamp(topicName, partitionIndex)
+		if err != nil {
+			return storageError(result, err)
+		}
+		applyLookup(&result, lookup)
+	default:
+		if partition.Timestamp < 0 {
+			result.ErrorCode = domain.ErrorCodeInvalidRequest
+			return result
+		}
+		lookup, err := s.partition_file_repository.FindOffsetByTimestamp(topicName, partitionIndex, partition.Timestamp)
+		if err != nil {
+			return storageError(result, err)
+		}
+		applyLookup(&result, lookup)
+	}
+	return result
+}
+
+// validateLeaderEpoch compares the epoch the client believes is current with the
+// partition's leader epoch; -1 means the client did not send one
+func validateLeaderEpoch(currentLeaderEpoch int32, leaderEpoch int32) int16 {
+	switch {
+	case currentLeaderEpoch < 0:
+		return domain.ErrorCodeNone
+	case currentLeaderEpoch < leaderEpoch:
+		return domain.ErrorCodeFencedLeaderEpoch
+	case currentLeaderEpoch > leaderEpoch:
+		return domain.ErrorCodeUnknownLeaderEpoch
+	default:
+		return domain.ErrorCodeNone
+	}
+}
+
+func applyLookup(result *domain.ListOffsetsResponsePartition, lookup domain.OffsetLookupResult) {
+	if !lookup.Found {
+		return
+	}
+	result.Offset = lookup.Offset
+	result.Timestamp = lookup.Timestamp
+	result.LeaderEpoch = lookup.LeaderEpoch
+}
+
+func storageError(result domain.ListOffsetsResponsePartition, err error) domain.ListOffsetsResponsePartition {
+	fmt.Printf("ListOffsets for partition %d failed: %v\n", result.PartitionIndex, err)
+	result.ErrorCode = domain.ErrorCodeKafkaStorageError
+	return result
+}
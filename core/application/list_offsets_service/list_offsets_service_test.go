@@ -0,0 +1,101 @@
+package list_offsets_service
+
+import (
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+	partition_file_repository "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/partition_repository"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// mockParser hands the service a fixed request and captures the response
+type mockParser struct {
+	request  *domain.ParsedRequestListOffsets
+	response *domain.ResponseDataListOffsets
+}
+
+func (m *mockParser) ParseRequest(data []byte) (*domain.ParsedRequestListOffsets, error) {
+	return m.request, nil
+}
+
+func (m *mockParser) EncodeResponse(response *domain.ResponseDataListOffsets) ([]byte, error) {
+	m.response = response
+	return []byte{}, nil
+}
+
+// mockMetadataRepository serves topic "foo" with partition 0 at leader epoch 7
+type mockMetadataRepository struct{}
+
+func (m *mockMetadataRepository) GetClusterMetadata() (port_cluster_metadata_repository.ClusterMetadataRepositoryResponse, error) {
+	return port_cluster_metadata_repository.ClusterMetadataRepositoryResponse{
+		TopicNameTopicUuidMap: map[string]string{"foo": "01"},
+		TopicUUIDPartitionMetadataMap: map[string][]*domain.PartitionMetadata{
+			"01": {{PartitionIndex: common.IntToFourBytes(0), LeaderEpoch: common.IntToFourBytes(7)}},
+		},
+	}, nil
+}
+
+func batch(baseTimestamp int64, count int) []byte {
+	records := []common.Record{}
+	for i := range count {
+		records = append(records, common.Record{OffsetDelta: int32(i), TimestampDelta: int64(i), Value: []byte("v")})
+	}
+	return common.EncodeRecordBatch(common.RecordBatch{
+		RecordBatchHeader: common.RecordBatchHeader{
+			LastOffsetDelta: int32(count - 1),
+			BaseTimestamp:   baseTimestamp,
+			MaxTimestamp:    baseTimestamp + int64(count-1),
+			ProducerID:      -1,
+			ProducerEpoch:   -1,
+			BaseSequence:    -1,
+		},
+		Records: records,
+	})
+}
+
+func TestListOffsetsService_HandleRequest(t *testing.T) {
+	repo := partition_file_repository.NewPartitionFileRepositoryWithLogDir(t.TempDir())
+	for _, b := range [][]byte{batch(100, 3), batch(200, 2)} {
+		if _, err := repo.AppendRecordBatches(domain.AppendRequest{TopicName: "foo", PartitionIndex: 0, LeaderEpoch: 7, Records: b}); err != nil {
+			t.Fatalf("AppendRecordBatches() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		topic         string
+		partition     domain.ListOffsetsPartition
+		wantErrorCode int16
+		wantOffset    int64
+		wantTimestamp int64
+		wantEpoch     int32
+	}{
+		{name: "earliest", topic: "foo", partition: domain.ListOffsetsPartition{Timestamp: -2, CurrentLeaderEpoch: -1}, wantOffset: 0, wantTimestamp: -1, wantEpoch: 7},
+		{name: "latest", topic: "foo", partition: domain.ListOffsetsPartition{Timestamp: -1, CurrentLeaderEpoch: 7}, wantOffset: 5, wantTimestamp: -1, wantEpoch: 7},
+		{name: "max timestamp", topic: "foo", partition: domain.ListOffsetsPartition{Timestamp: -3, CurrentLeaderEpoch: -1}, wantOffset: 4, wantTimestamp: 201, wantEpoch: 7},
+		{name: "by timestamp", topic: "foo", partition: domain.ListOffsetsPartition{Timestamp: 150, CurrentLeaderEpoch: -1}, wantOffset: 3, wantTimestamp: 200, wantEpoch: 7},
+		{name: "timestamp after last record", topic: "foo", partition: domain.ListOffsetsPartition{Timestamp: 300, CurrentLeaderEpoch: -1}, wantOffset: -1, wantTimestamp: -1, wantEpoch: -1},
+		{name: "fenced leader epoch", topic: "foo", partition: domain.ListOffsetsPartition{Timestamp: -1, CurrentLeaderEpoch: 6}, wantErrorCode: domain.ErrorCodeFencedLeaderEpoch, wantOffset: -1, wantTimestamp: -1, wantEpoch: -1},
+		{name: "unknown leader epoch", topic: "foo", partition: domain.ListOffsetsPartition{Timestamp: -1, CurrentLeaderEpoch: 8}, wantErrorCode: domain.ErrorCodeUnknownLeaderEpoch, wantOffset: -1, wantTimestamp: -1, wantEpoch: -1},
+		{name: "unknown topic", topic: "bar", partition: domain.ListOffsetsPartition{Timestamp: -1, CurrentLeaderEpoch: -1}, wantErrorCode: domain.ErrorCodeUnknownTopicOrPartition, wantOffset: -1, wantTimestamp: -1, wantEpoch: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockParser := &mockParser{request: &domain.ParsedRequestListOffsets{
+				APIVersion: 7,
+				Topics:     []domain.ListOffsetsTopic{{Name: tt.topic, Partitions: []domain.ListOffsetsPartition{tt.partition}}},
+			}}
+			service := NewListOffsetsService(mockParser, &mockMetadataRepository{}, repo)
+			if _, err := service.HandleRequest(domain.Request{}); err != nil {
+				t.Fatalf("HandleRequest() error = %v", err)
+			}
+
+			got := mockParser.response.Topics[0].Partitions[0]
+			if got.ErrorCode != tt.wantErrorCode || got.Offset != tt.wantOffset || got.Timestamp != tt.wantTimestamp || got.LeaderEpoch != tt.wantEpoch {
+				t.Errorf("got %+v, want error %d offset %d timestamp %d epoch %d", got, tt.wantErrorCode, tt.wantOffset, tt.wantTimestamp, tt.wantEpoch)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package metadata_service
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// authorizedOperationsNotRequested is returned when the client did not ask for authorized operations
+const authorizedOperationsNotRequested int32 = math.MinInt32
+
+// allTopicOperations is the bit field of topic operations a client may perform without an
+// authorizer: READ, WRITE, CREATE, DELETE, ALTER, DESCRIBE, DESCRIBE_CONFIGS and ALTER_CONFIGS
+const allTopicOperations int32 = 0x0df8
+
+// allClusterOperations is the bit field of cluster operations a client may perform without an
+// authorizer: CREATE, ALTER, DESCRIBE, CLUSTER_ACTION, DESCRIBE_CONFIGS, ALTER_CONFIGS, IDEMPOTENT_WRITE
+const allClusterOperations int32 = 0x1fa0
+
+var zeroTopicId = make([]byte, 16)
+
+// MetadataService implements the driving port for Metadata requests, which clients
+// use to discover brokers, topics and partition leaders.
+type MetadataService struct {
+	parser              parser.MetadataParser
+	metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository
+	brokerConfig        domain.BrokerConfig
+}
+
+func NewMetadataService(parser parser.MetadataParser, metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository, brokerConfig domain.BrokerConfig) driving.KafkaHandler {
+	return &MetadataService{
+		parser:              parser,
+		metadata_repository: metadata_repository,
+		brokerConfig:        brokerConfig,
+	}
+}
+
+func (s *MetadataService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	clusterMetaData, err := s.metadata_repository.GetClusterMetadata()
+	if err != nil {
+		fmt.Println("Metadata: there is no cluster metadata", err.Error())
+	}
+
+	brokers := s.getBrokers(clusterMetaData)
+	clusterAuthorizedOperations := authorizedOperationsNotRequested
+	if parsedReq.IncludeClusterAuthorizedOperations {
+		clusterAuthorizedOperations = allClusterOperations
+	}
+
+	responseData := &domain.ResponseDataMetadata{
+		CorrelationID:               parsedReq.CorrelationID,
+		APIVersion:                  parsedReq.APIVersion,
+		ThrottleTimeMs:              0,
+		Brokers:                     brokers,
+		ClusterID:                   s.brokerConfig.ClusterID,
+		ControllerID:                s.brokerConfig.NodeID,
+		Topics:                      s.getTopics(parsedReq, clusterMetaData, brokers),
+		ClusterAuthorizedOperations: clusterAuthorizedOperations,
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
+
+// getBrokers returns the brokers that are advertised to clients: the unfenced brokers
+// registered in the metadata log, in ID order, at the first listener they registered.
+// This broker is advertised as configured, and alone when no broker is registered.
+func (s *MetadataService) getBrokers(clusterMetaData port_cluster_metadata_repository.ClusterMetadataRepositoryResponse) []domain.Broker {
+	if len(clusterMetaData.Brokers) == 0 {
+		return []domain.Broker{s.brokerConfig.Broker}
+	}
+
+	brokers := []domain.Broker{}
+	for _, brokerID := range slices.Sorted(maps.Keys(clusterMetaData.Brokers)) {
+		registration := clusterMetaData.Brokers[brokerID]
+		switch {
+		case registration.Fenced:
+			continue
+		case brokerID == s.brokerConfig.NodeID:
+			brokers = append(brokers, s.brokerConfig.Broker)
+		case len(registration.Endpoints) > 0:
+			endpoint := registration.Endpoints[0]
+			brokers = append(brokers, domain.Broker{NodeID: brokerID, Host: endpoint.Host, Port: endpoint.Port, Rack: registration.Rack})
+		}
+	}
+	return brokers
+}
+
+func (s *MetadataService) getTopics(parsedReq *domain.ParsedRequestMetadata, clusterMetaData port_cluster_metadata_repository.ClusterMetadataRepositoryResponse, brokers []domain.Broker) []domain.MetadataResponseTopic {
+	topics := []domain.MetadataResponseTopic{}
+
+	if parsedReq.AllTopics {
+		for topicUuid := range clusterMetaData.TopicUUIDTopicMetadataInfoMap {
+			topics = append(topics, s.getTopic(parsedReq, clusterMetaData, topicUuid, brokers))
+		}
+		sort.Slice(topics, func(i, j int) bool {
+			return topics[i].Name < topics[j].Name
+		})
+		return topics
+	}
+
+	for _, requestedTopic := range parsedReq.Topics {
+		if len(requestedTopic.TopicID) == 16 && !bytes.Equal(requestedTopic.TopicID, zeroTopicId) {
+			topicUuid := hex.EncodeToString(requestedTopic.TopicID)
+			if _, exists := clusterMetaData.TopicUUIDTopicMetadataInfoMap[topicUuid]; !exists {
+				topics = append(topics, domain.MetadataResponseTopic{
+					ErrorCode:                 domain.ErrorCodeUnknownTopicID,
+					TopicID:                   requestedTopic.TopicID,
+					TopicAuthorizedOperations: authorizedOperationsNotRequested,
+				})
+				continue
+			}
+			topics = append(topics, s.getTopic(parsedReq, clusterMetaData, topicUuid, brokers))
+			continue
+		}
+
+		topicUuid, exists := clusterMetaData.TopicNameTopicUuidMap[requestedTopic.Name]
+		if !exists {
+			topics = append(topics, domain.MetadataResponseTopic{
+				ErrorCode:                 domain.ErrorCodeUnknownTopicOrPartition,
+				Name:                      requestedTopic.Name,
+				TopicID:                   zeroTopicId,
+				TopicAuthorizedOperations: authorizedOperationsNotRequested,
+			})
+			continue
+		}
+		topics = append(topics, s.getTopic(parsedReq, clusterMetaData, topicUuid, brokers))
+	}
+	return topics
+}
+
+func (s *MetadataService) getTopic(parsedReq *domain.ParsedRequestMetadata, clusterMetaData port_cluster_metadata_repository.ClusterMetadataRepositoryResponse, topicUuid string, brokers []domain.Broker) domain.MetadataResponseTopic {
+	topicMetadata := clusterMetaData.TopicUUIDTopicMetadataInfoMap[topicUuid]
+
+	topic := domain.MetadataResponseTopic{
+		ErrorCode:                 domain.ErrorCodeNone,
+		Name:                      topicMetadata.TopicNameInfo.TopicName,
+		TopicID:                   topicMetadata.TopicId,
+		IsInternal:                common.BytesToInt(topicMetadata.IsInternal) != 0,
+		Partitions:                []domain.MetadataResponsePartition{},
+		TopicAuthorizedOperations: authorizedOperationsNotRequested,
+	}
+	if parsedReq.IncludeTopicAuthorizedOperations {
+		topic.TopicAuthorizedOperations = allTopicOperations
+	}
+
+	for _, partitionMetadata := range clusterMetaData.TopicUUIDPartitionMetadataMap[topicUuid] {
+		replicaNodes := bytesToNodeIds(partitionMetadata.ReplicaNodesArray)
+		topic.Partitions = append(topic.Partitions, domain.MetadataResponsePartition{
+			ErrorCode:       int16(common.BytesToInt(partitionMetadata.ErrorCode)),
+			PartitionIndex:  int32(common.BytesToInt(partitionMetadata.PartitionIndex)),
+			LeaderID:        int32(common.BytesToInt(partitionMetadata.LeaderId)),
+			LeaderEpoch:     int32(common.BytesToInt(partitionMetadata.LeaderEpoch)),
+			ReplicaNodes:    replicaNodes,
+			IsrNodes:        bytesToNodeIds(partitionMetadata.IsrNodeArray),
+			OfflineReplicas: getOfflineReplicas(replicaNodes, brokers),
+		})
+	}
+	sort.Slice(topic.Partitions, func(i, j int) bool {
+		return topic.Partitions[i].PartitionIndex < topic.Partitions[j].PartitionIndex
+	})
+	return topic
+}
+
+// bytesToNodeIds splits a byte array of 4 byte broker IDs
+func bytesToNodeIds(data []byte) []int32 {
+	nodeIds := make([]int32, 0, len(data)/4)
+	for offset := 0; offset+4 <= len(data); offset += 4 {
+		nodeIds = append(nodeIds, int32(common.BytesToInt(data[offset:offset+4])))
+	}
+	return nodeIds
+}
+
+// getOfflineReplicas returns the replicas that are not hosted by a live broker
+func getOfflineReplicas(replicaNodes []int32, brokers []domain.Broker) []int32 {
+	offline := []int32{}
+	for _, replica := range replicaNodes {
+		live := false
+		for _, broker := range brokers {
+			if broker.NodeID == replica {
+				live = true
+				break
+			}
+		}
+		if !live {
+			offline = append(offline, replica)
+		}
+	}
+	return offline
+}
@@ -0,0 +1,168 @@
+package metadata_service
+
+import (
+	"encoding/hex"
+	"slices"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// mockParser hands the service a fixed request and captures the response
+type mockParser struct {
+	request  *domain.ParsedRequestMetadata
+	response *domain.ResponseDataMetadata
+}
+
+func (m *mockParser) ParseRequest(data []byte) (*domain.ParsedRequestMetadata, error) {
+	return m.request, nil
+}
+
+func (m *mockParser) EncodeResponse(response *domain.ResponseDataMetadata) ([]byte, error) {
+	m.response = response
+	return []byte{}, nil
+}
+
+type mockMetadataRepository struct {
+	response port_cluster_metadata_repository.ClusterMetadataRepositoryResponse
+}
+
+func (m *mockMetadataRepository) GetClusterMetadata() (port_cluster_metadata_repository.ClusterMetadataRepositoryResponse, error) {
+	return m.response, nil
+}
+
+var fooTopicId = []byte{0x71, 0xa5, 0x9a, 0x51, 0x89, 0x68, 0x4f, 0x8b, 0x93, 0x7e, 0x00, 0x00, 0x00, 0x00, 0x07, 0x7e}
+
+func newMockMetadataRepository() *mockMetadataRepository {
+	fooUuid := hex.EncodeToString(fooTopicId)
+	partition := func(index int, replicas ...int) *domain.PartitionMetadata {
+		replicaBytes := []byte{}
+		for _, replica := range replicas {
+			replicaBytes = append(replicaBytes, common.IntToFourBytes(replica)...)
+		}
+		return &domain.PartitionMetadata{
+			ErrorCode:      []byte{0x00, 0x00},
+			PartitionIndex: common.IntToFourBytes(index),
+			LeaderId:       common.IntToFourBytes(1),
+			LeaderEpoch:    common.IntToFourBytes(4),
+			ReplicaNodes:   domain.ReplicaNodes{ReplicaNodesArray: replicaBytes},
+			IsrNodes:       domain.IsrNodes{IsrNodeArray: common.IntToFourBytes(1)},
+		}
+	}
+	return &mockMetadataRepository{response: port_cluster_metadata_repository.ClusterMetadataRepositoryResponse{
+		TopicUUIDTopicMetadataInfoMap: map[string]*port_cluster_metadata_repository.TopicMetadataInfo{
+			fooUuid: {TopicNameInfo: parser.TopicNameInfo{TopicName: "foo"}, TopicId: fooTopicId, IsInternal: []byte{0x00}},
+		},
+		TopicUUIDPartitionMetadataMap: map[string][]*domain.PartitionMetadata{
+			fooUuid: {partition(1, 1, 2), partition(0, 1)},
+		},
+		TopicNameTopicUuidMap: map[string]string{"foo": fooUuid},
+	}}
+}
+
+func TestMetadataService_HandleRequest(t *testing.T) {
+	brokerConfig := domain.BrokerConfig{Broker: domain.Broker{NodeID: 1, Host: "localhost", Port: 9092}, ClusterID: "abc"}
+
+	tests := []struct {
+		name           string
+		request        *domain.ParsedRequestMetadata
+		wantTopics     []string
+		wantErrorCodes []int16
+	}{
+		{
+			name:           "all topics",
+			request:        &domain.ParsedRequestMetadata{APIVersion: 12, AllTopics: true},
+			wantTopics:     []string{"foo"},
+			wantErrorCodes: []int16{domain.ErrorCodeNone},
+		},
+		{
+			name:           "known and unknown names",
+			request:        &domain.ParsedRequestMetadata{APIVersion: 9, Topics: []domain.MetadataRequestTopic{{Name: "foo"}, {Name: "bar"}}},
+			wantTopics:     []string{"foo", "bar"},
+			wantErrorCodes: []int16{domain.ErrorCodeNone, domain.ErrorCodeUnknownTopicOrPartition},
+		},
+		{
+			name:           "by topic id",
+			request:        &domain.ParsedRequestMetadata{APIVersion: 12, Topics: []domain.MetadataRequestTopic{{TopicID: fooTopicId}, {TopicID: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}}}},
+			wantTopics:     []string{"foo", ""},
+			wantErrorCodes: []int16{domain.ErrorCodeNone, domain.ErrorCodeUnknownTopicID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockParser := &mockParser{request: tt.request}
+			service := NewMetadataService(mockParser, newMockMetadataRepository(), brokerConfig)
+			if _, err := service.HandleRequest(domain.Request{}); err != nil {
+				t.Fatalf("HandleRequest() error = %v", err)
+			}
+
+			response := mockParser.response
+			if len(response.Brokers) != 1 || response.Brokers[0].NodeID != 1 || response.ControllerID != 1 || response.ClusterID != "abc" {
+				t.Errorf("unexpected brokers %+v, controller %d, cluster %q", response.Brokers, response.ControllerID, response.ClusterID)
+			}
+			if len(response.Topics) != len(tt.wantTopics) {
+				t.Fatalf("got %d topics, want %d", len(response.Topics), len(tt.wantTopics))
+			}
+			for i, topic := range response.Topics {
+				if topic.Name != tt.wantTopics[i] || topic.ErrorCode != tt.wantErrorCodes[i] {
+					t.Errorf("topic %d = %q with error %d, want %q with error %d", i, topic.Name, topic.ErrorCode, tt.wantTopics[i], tt.wantErrorCodes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMetadataService_HandleRequest_Partitions(t *testing.T) {
+	mockParser := &mockParser{request: &domain.ParsedRequestMetadata{APIVersion: 12, AllTopics: true}}
+	brokerConfig := domain.BrokerConfig{Broker: domain.Broker{NodeID: 1, Host: "localhost", Port: 9092}}
+	service := NewMetadataService(mockParser, newMockMetadataRepository(), brokerConfig)
+	if _, err := service.HandleRequest(domain.Request{}); err != nil {
+		t.Fatalf("HandleRequest() error = %v", err)
+	}
+
+	partitions := mockParser.response.Topics[0].Partitions
+	if len(partitions) != 2 {
+		t.Fatalf("got %d partitions, want 2", len(partitions))
+	}
+	if partitions[0].PartitionIndex != 0 || partitions[1].PartitionIndex != 1 {
+		t.Errorf("partitions are not sorted by index: %+v", partitions)
+	}
+	if partitions[1].LeaderID != 1 || partitions[1].LeaderEpoch != 4 {
+		t.Errorf("partition 1 leader = %d epoch %d, want 1 and 4", partitions[1].LeaderID, partitions[1].LeaderEpoch)
+	}
+	if len(partitions[1].OfflineReplicas) != 1 || partitions[1].OfflineReplicas[0] != 2 {
+		t.Errorf("partition 1 offline replicas = %v, want [2]", partitions[1].OfflineReplicas)
+	}
+}
+
+func TestMetadataService_HandleRequest_RegisteredBrokers(t *testing.T) {
+	repository := newMockMetadataRepository()
+	repository.response.Brokers = map[int32]*domain.BrokerRegistration{
+		1: {BrokerID: 1, Endpoints: []domain.BrokerEndpoint{{Name: "PLAINTEXT", Host: "broker-1", Port: 19092}}},
+		2: {BrokerID: 2, Rack: "rack-b", Endpoints: []domain.BrokerEndpoint{{Name: "PLAINTEXT", Host: "broker-2", Port: 9092}}},
+		3: {BrokerID: 3, Fenced: true, Endpoints: []domain.BrokerEndpoint{{Name: "PLAINTEXT", Host: "broker-3", Port: 9092}}},
+	}
+	mockParser := &mockParser{request: &domain.ParsedRequestMetadata{APIVersion: 12, AllTopics: true}}
+	brokerConfig := domain.BrokerConfig{Broker: domain.Broker{NodeID: 1, Host: "localhost", Port: 9092}}
+	service := NewMetadataService(mockParser, repository, brokerConfig)
+	if _, err := service.HandleRequest(domain.Request{}); err != nil {
+		t.Fatalf("HandleRequest() error = %v", err)
+	}
+
+	wantBrokers := []domain.Broker{
+		{NodeID: 1, Host: "localhost", Port: 9092},
+		{NodeID: 2, Host: "broker-2", Port: 9092, Rack: "rack-b"},
+	}
+	if !slices.Equal(mockParser.response.Brokers, wantBrokers) {
+		t.Errorf("brokers = %+v, want %+v", mockParser.response.Brokers, wantBrokers)
+	}
+	// foo-1 is replicated on brokers 1 and 2, both advertised
+	partitions := mockParser.response.Topics[0].Partitions
+	if len(partitions[1].OfflineReplicas) != 0 {
+		t.Errorf("partition 1 offline replicas = %v, want none", partitions[1].OfflineReplicas)
+	}
+}
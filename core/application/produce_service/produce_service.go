@@ -0,0 +1,130 @@
+package produce_service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+	port_repo "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/partition_file_repository"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// ProduceService implements the driving port for Produce requests.
+// It validates every topic partition against the cluster metadata and appends
+// the RecordBatches to the partition log.
+type ProduceService struct {
+	parser                    parser.ProduceParser
+	metadata_repository       port_cluster_metadata_repository.ClusterMetadataRepository
+	partition_file_repository port_repo.PartitionFileRepository
+}
+
+func NewProduceService(parser parser.ProduceParser, metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository, partition_file_repository port_repo.PartitionFileRepository) driving.KafkaHandler {
+	return &ProduceService{
+		parser:                    parser,
+		metadata_repository:       metadata_repository,
+		partition_file_repository: partition_file_repository,
+	}
+}
+
+func (s *ProduceService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	clusterMetaData, err := s.metadata_repository.GetClusterMetadata()
+	if err != nil {
+		fmt.Println("Produce: there is no cluster metadata", err.Error())
+	}
+
+	responseData := &domain.ResponseDataProduce{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		Topics:         make([]domain.ProduceResponseTopic, 0, len(parsedReq.Topics)),
+	}
+
+	for _, topic := range parsedReq.Topics {
+		responseTopic := domain.ProduceResponseTopic{
+			Name:       topic.Name,
+			Partitions: make([]domain.ProduceResponsePartition, 0, len(topic.Partitions)),
+		}
+		for _, partition := range topic.Partitions {
+			responseTopic.Partitions = append(responseTopic.Partitions, s.producePartition(clusterMetaData, topic.Name, partition))
+		}
+		responseData.Topics = append(responseData.Topics, responseTopic)
+	}
+
+	// With acks=0 the client does not wait for, or read, a response
+	if parsedReq.Acks == 0 {
+		return domain.Response{}, nil
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
+
+func (s *ProduceService) producePartition(clusterMetaData port_cluster_metadata_repository.ClusterMetadataRepositoryResponse, topicName string, partition domain.ProducePartition) domain.ProduceResponsePartition {
+	result := domain.ProduceResponsePartition{
+		PartitionIndex:  partition.PartitionIndex,
+		ErrorCode:       domain.ErrorCodeNone,
+		BaseOffset:      -1,
+		LogAppendTimeMs: -1,
+		LogStartOffset:  -1,
+	}
+
+	partitionMetadata := clusterMetaData.FindPartition(topicName, partition.PartitionIndex)
+	if partitionMetadata == nil {
+		result.ErrorCode = domain.ErrorCodeUnknownTopicOrPartition
+		return result
+	}
+
+	appendResult, err := s.partition_file_repository.AppendRecordBatches(domain.AppendRequest{
+		TopicName:      topicName,
+		PartitionIndex: int(partition.PartitionIndex),
+		LeaderEpoch:    int32(common.BytesToInt(partitionMetadata.LeaderEpoch)),
+		Records:        partition.Records,
+	})
+	if errors.Is(err, domain.ErrDuplicateSequenceNumber) {
+		// A retried batch is acknowledged with the offset it was first appended at
+		result.ErrorCode = domain.ErrorCodeDuplicateSequenceNumber
+		result.BaseOffset = appendResult.BaseOffset
+		result.LogStartOffset = appendResult.LogStartOffset
+		return result
+	}
+	if err != nil {
+		fmt.Printf("Produce to %s-%d failed: %v\n", topicName, partition.PartitionIndex, err)
+		result.ErrorCode = errorCodeForAppendError(err)
+		result.ErrorMessage = err.Error()
+		return result
+	}
+
+	result.BaseOffset = appendResult.BaseOffset
+	result.LogAppendTimeMs = appendResult.LogAppendTimeMs
+	result.LogStartOffset = appendResult.LogStartOffset
+	return result
+}
+
+func errorCodeForAppendError(err error) int16 {
+	switch {
+	case errors.Is(err, domain.ErrCorruptMessage):
+		return domain.ErrorCodeCorruptMessage
+	case errors.Is(err, domain.ErrUnknownTopicOrPartition):
+		return domain.ErrorCodeUnknownTopicOrPartition
+	case errors.Is(err, domain.ErrOutOfOrderSequenceNumber):
+		return domain.ErrorCodeOutOfOrderSequenceNumber
+	case errors.Is(err, domain.ErrInvalidProducerEpoch):
+		return domain.ErrorCodeInvalidProducerEpoch
+	default:
+		return domain.ErrorCodeKafkaStorageError
+	}
+}
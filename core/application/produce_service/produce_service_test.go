@@ -0,0 +1,127 @@
+package produce_service
+
+import (
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+	infraparser "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/parser"
+	partition_file_repository "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/partition_repository"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// mockMetadataRepository serves a single topic "foo" with one partition
+type mockMetadataRepository struct{}
+
+func (m *mockMetadataRepository) GetClusterMetadata() (port_cluster_metadata_repository.ClusterMetadataRepositoryResponse, error) {
+	topicUuid := "00000000000000000000000000000001"
+	return port_cluster_metadata_repository.ClusterMetadataRepositoryResponse{
+		TopicNameTopicUuidMap: map[string]string{"foo": topicUuid},
+		TopicUUIDPartitionMetadataMap: map[string][]*domain.PartitionMetadata{
+			topicUuid: {{PartitionIndex: common.IntToFourBytes(0), LeaderEpoch: common.IntToFourBytes(0)}},
+		},
+	}, nil
+}
+
+func buildRequest(acks int16, partitions map[int32][]byte) []byte {
+	w := common.NewKafkaWriter()
+	w.Int16(domain.ApiKeyProduce)
+	w.Int16(9)
+	w.Int32(7)
+	w.String("test", false)
+	w.EmptyTaggedFields()
+	w.NullableString(nil, true)
+	w.Int16(acks)
+	w.Int32(1000)
+	w.ArrayLength(1, true)
+	w.String("foo", true)
+	w.ArrayLength(len(partitions), true)
+	for index, records := range partitions {
+		w.Int32(index)
+		w.BytesField(records, true)
+		w.EmptyTaggedFields()
+	}
+	w.EmptyTaggedFields()
+	w.EmptyTaggedFields()
+	return w.WithSizePrefix()
+}
+
+func recordBatch() []byte {
+	return common.EncodeRecordBatch(common.RecordBatch{
+		RecordBatchHeader: common.RecordBatchHeader{ProducerID: -1, ProducerEpoch: -1, BaseSequence: -1},
+		Records:           []common.Record{{Value: []byte("hello")}},
+	})
+}
+
+// idempotentBatch is a single record batch of producer 4000
+func idempotentBatch(epoch int16, sequence int32) []byte {
+	return common.EncodeRecordBatch(common.RecordBatch{
+		RecordBatchHeader: common.RecordBatchHeader{ProducerID: 4000, ProducerEpoch: epoch, BaseSequence: sequence},
+		Records:           []common.Record{{Value: []byte("hello")}},
+	})
+}
+
+func readPartitionResult(t *testing.T, data []byte) (int16, int64) {
+	reader := common.NewKafkaReader(data, 4)
+	reader.Int32("CorrelationID")
+	reader.SkipTaggedFields()
+	reader.ArrayLength("Topics", true)
+	reader.String("Name", true)
+	reader.ArrayLength("Partitions", true)
+	reader.Int32("PartitionIndex")
+	errorCode := reader.Int16("ErrorCode")
+	baseOffset := reader.Int64("BaseOffset")
+	if err := reader.Err(); err != nil {
+		t.Fatalf("reading response: %v", err)
+	}
+	return errorCode, baseOffset
+}
+
+func TestProduceService_HandleRequest(t *testing.T) {
+	corrupt := recordBatch()
+	corrupt[len(corrupt)-1] ^= 0xff
+
+	tests := []struct {
+		name           string
+		partitionIndex int32
+		records        []byte
+		wantErrorCode  int16
+		wantBaseOffset int64
+	}{
+		{name: "first append", partitionIndex: 0, records: recordBatch(), wantErrorCode: domain.ErrorCodeNone, wantBaseOffset: 0},
+		{name: "second append", partitionIndex: 0, records: recordBatch(), wantErrorCode: domain.ErrorCodeNone, wantBaseOffset: 1},
+		{name: "unknown partition", partitionIndex: 5, records: recordBatch(), wantErrorCode: domain.ErrorCodeUnknownTopicOrPartition, wantBaseOffset: -1},
+		{name: "corrupt batch", partitionIndex: 0, records: corrupt, wantErrorCode: domain.ErrorCodeCorruptMessage, wantBaseOffset: -1},
+		{name: "idempotent append", partitionIndex: 0, records: idempotentBatch(0, 0), wantErrorCode: domain.ErrorCodeNone, wantBaseOffset: 2},
+		{name: "retried batch", partitionIndex: 0, records: idempotentBatch(0, 0), wantErrorCode: domain.ErrorCodeDuplicateSequenceNumber, wantBaseOffset: 2},
+		{name: "sequence gap", partitionIndex: 0, records: idempotentBatch(0, 2), wantErrorCode: domain.ErrorCodeOutOfOrderSequenceNumber, wantBaseOffset: -1},
+		{name: "next sequence", partitionIndex: 0, records: idempotentBatch(0, 1), wantErrorCode: domain.ErrorCodeNone, wantBaseOffset: 3},
+		{name: "new epoch", partitionIndex: 0, records: idempotentBatch(1, 0), wantErrorCode: domain.ErrorCodeNone, wantBaseOffset: 4},
+		{name: "fenced epoch", partitionIndex: 0, records: idempotentBatch(0, 2), wantErrorCode: domain.ErrorCodeInvalidProducerEpoch, wantBaseOffset: -1},
+	}
+
+	service := NewProduceService(infraparser.NewKafkaProtocolParserProduce(), &mockMetadataRepository{}, partition_file_repository.NewPartitionFileRepositoryWithLogDir(t.TempDir()))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := service.HandleRequest(domain.Request{Data: buildRequest(-1, map[int32][]byte{tt.partitionIndex: tt.records})})
+			if err != nil {
+				t.Fatalf("HandleRequest() error = %v", err)
+			}
+			errorCode, baseOffset := readPartitionResult(t, resp.Data)
+			if errorCode != tt.wantErrorCode || baseOffset != tt.wantBaseOffset {
+				t.Errorf("got error code %d and base offset %d, want %d and %d", errorCode, baseOffset, tt.wantErrorCode, tt.wantBaseOffset)
+			}
+		})
+	}
+}
+
+func TestProduceService_HandleRequest_AcksZeroHasNoResponse(t *testing.T) {
+	service := NewProduceService(infraparser.NewKafkaProtocolParserProduce(), &mockMetadataRepository{}, partition_file_repository.NewPartitionFileRepositoryWithLogDir(t.TempDir()))
+	resp, err := service.HandleRequest(domain.Request{Data: buildRequest(0, map[int32][]byte{0: recordBatch()})})
+	if err != nil {
+		t.Fatalf("HandleRequest() error = %v", err)
+	}
+	if len(resp.Data) != 0 {
+		t.Errorf("HandleRequest() with acks=0 returned %d bytes, want none", len(resp.Data))
+	}
+}
@@ -0,0 +1,43 @@
+package transaction_coordinator_service
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// AddOffsetsToTxnService implements the driving port for AddOffsetsToTxn requests
+type AddOffsetsToTxnService struct {
+	parser      parser.AddOffsetsToTxnParser
+	coordinator *TransactionCoordinator
+}
+
+func NewAddOffsetsToTxnService(parser parser.AddOffsetsToTxnParser, coordinator *TransactionCoordinator) driving.KafkaHandler {
+	return &AddOffsetsToTxnService{
+		parser:      parser,
+		coordinator: coordinator,
+	}
+}
+
+func (s *AddOffsetsToTxnService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	responseData := &domain.ResponseDataAddOffsetsToTxn{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		ErrorCode:      s.coordinator.AddOffsets(parsedReq.TransactionalID, parsedReq.ProducerID, parsedReq.ProducerEpoch, parsedReq.GroupID),
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
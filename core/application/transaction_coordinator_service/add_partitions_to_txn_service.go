@@ -0,0 +1,86 @@
+package transaction_coordinator_service
+
+import (
+	"fmt"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+)
+
+// AddPartitionsToTxnService implements the driving port for AddPartitionsToTxn requests.
+// The partitions are added all together or not at all: when one of them is unknown the
+// others are reported OPERATION_NOT_ATTEMPTED.
+type AddPartitionsToTxnService struct {
+	parser              parser.AddPartitionsToTxnParser
+	metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository
+	coordinator         *TransactionCoordinator
+}
+
+func NewAddPartitionsToTxnService(parser parser.AddPartitionsToTxnParser, metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository, coordinator *TransactionCoordinator) driving.KafkaHandler {
+	return &AddPartitionsToTxnService{
+		parser:              parser,
+		metadata_repository: metadata_repository,
+		coordinator:         coordinator,
+	}
+}
+
+func (s *AddPartitionsToTxnService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	clusterMetaData, err := s.metadata_repository.GetClusterMetadata()
+	if err != nil {
+		fmt.Println("AddPartitionsToTxn: there is no cluster metadata", err.Error())
+	}
+
+	responseData := &domain.ResponseDataAddPartitionsToTxn{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		Results:        make([]domain.AddPartitionsToTxnTopicResult, 0, len(parsedReq.Topics)),
+	}
+
+	partitions := []domain.TopicPartition{}
+	unknown := map[domain.TopicPartition]bool{}
+	for _, topic := range parsedReq.Topics {
+		for _, partition := range topic.Partitions {
+			tp := domain.TopicPartition{Topic: topic.Name, Partition: partition}
+			partitions = append(partitions, tp)
+			if clusterMetaData.FindPartition(topic.Name, partition) == nil {
+				unknown[tp] = true
+			}
+		}
+	}
+
+	errorCode := domain.ErrorCodeOperationNotAttempted
+	if len(unknown) == 0 {
+		errorCode = s.coordinator.AddPartitions(parsedReq.TransactionalID, parsedReq.ProducerID, parsedReq.ProducerEpoch, partitions)
+	}
+	for _, topic := range parsedReq.Topics {
+		result := domain.AddPartitionsToTxnTopicResult{
+			Name:       topic.Name,
+			Partitions: make([]domain.AddPartitionsToTxnPartitionResult, 0, len(topic.Partitions)),
+		}
+		for _, partition := range topic.Partitions {
+			partitionResult := domain.AddPartitionsToTxnPartitionResult{PartitionIndex: partition, ErrorCode: errorCode}
+			if unknown[domain.TopicPartition{Topic: topic.Name, Partition: partition}] {
+				partitionResult.ErrorCode = domain.ErrorCodeUnknownTopicOrPartition
+			}
+			result.Partitions = append(result.Partitions, partitionResult)
+		}
+		responseData.Results = append(responseData.Results, result)
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
@@ -0,0 +1,63 @@
+package transaction_coordinator_service
+
+import (
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+)
+
+type mockAddPartitionsToTxnParser struct {
+	request  *domain.ParsedRequestAddPartitionsToTxn
+	response *domain.ResponseDataAddPartitionsToTxn
+}
+
+func (m *mockAddPartitionsToTxnParser) ParseRequest(data []byte) (*domain.ParsedRequestAddPartitionsToTxn, error) {
+	return m.request, nil
+}
+
+func (m *mockAddPartitionsToTxnParser) EncodeResponse(response *domain.ResponseDataAddPartitionsToTxn) ([]byte, error) {
+	m.response = response
+	return []byte{}, nil
+}
+
+func TestAddPartitionsToTxnService_HandleRequest(t *testing.T) {
+	b := newTestBroker(t, t.TempDir())
+	producerID, epoch := b.initTransactional(t, "txn-1", -1, -1)
+
+	handle := func(topics ...domain.AddPartitionsToTxnTopic) map[domain.TopicPartition]int16 {
+		t.Helper()
+		mockParser := &mockAddPartitionsToTxnParser{request: &domain.ParsedRequestAddPartitionsToTxn{
+			APIVersion:      3,
+			TransactionalID: "txn-1",
+			ProducerID:      producerID,
+			ProducerEpoch:   epoch,
+			Topics:          topics,
+		}}
+		if _, err := NewAddPartitionsToTxnService(mockParser, b.metadata, b.coordinator).HandleRequest(domain.Request{}); err != nil {
+			t.Fatalf("HandleRequest() error = %v", err)
+		}
+		errorCodes := map[domain.TopicPartition]int16{}
+		for _, result := range mockParser.response.Results {
+			for _, partition := range result.Partitions {
+				errorCodes[domain.TopicPartition{Topic: result.Name, Partition: partition.PartitionIndex}] = partition.ErrorCode
+			}
+		}
+		return errorCodes
+	}
+
+	// One unknown partition fails the whole request
+	got := handle(domain.AddPartitionsToTxnTopic{Name: "orders", Partitions: []int32{0, 5}})
+	if got[orders0] != domain.ErrorCodeOperationNotAttempted || got[domain.TopicPartition{Topic: "orders", Partition: 5}] != domain.ErrorCodeUnknownTopicOrPartition {
+		t.Errorf("AddPartitionsToTxn with an unknown partition = %v", got)
+	}
+	if errorCode := b.coordinator.EndTransaction("txn-1", producerID, epoch, true); errorCode != domain.ErrorCodeInvalidTxnState {
+		t.Errorf("EndTransaction() after a failed AddPartitionsToTxn = %d, want INVALID_TXN_STATE", errorCode)
+	}
+
+	got = handle(domain.AddPartitionsToTxnTopic{Name: "orders", Partitions: []int32{0, 1}})
+	for tp, errorCode := range got {
+		if errorCode != domain.ErrorCodeNone {
+			t.Errorf("AddPartitionsToTxn %s-%d = %d, want none", tp.Topic, tp.Partition, errorCode)
+		}
+	}
+}
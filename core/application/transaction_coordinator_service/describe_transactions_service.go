@@ -0,0 +1,43 @@
+package transaction_coordinator_service
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// DescribeTransactionsService implements the driving port for DescribeTransactions requests
+type DescribeTransactionsService struct {
+	parser      parser.DescribeTransactionsParser
+	coordinator *TransactionCoordinator
+}
+
+func NewDescribeTransactionsService(parser parser.DescribeTransactionsParser, coordinator *TransactionCoordinator) driving.KafkaHandler {
+	return &DescribeTransactionsService{
+		parser:      parser,
+		coordinator: coordinator,
+	}
+}
+
+func (s *DescribeTransactionsService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	responseData := &domain.ResponseDataDescribeTransactions{
+		CorrelationID:     parsedReq.CorrelationID,
+		APIVersion:        parsedReq.APIVersion,
+		ThrottleTimeMs:    0,
+		TransactionStates: s.coordinator.DescribeTransactions(parsedReq.TransactionalIDs),
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
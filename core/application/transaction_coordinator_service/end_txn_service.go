@@ -0,0 +1,43 @@
+package transaction_coordinator_service
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// EndTxnService implements the driving port for EndTxn requests
+type EndTxnService struct {
+	parser      parser.EndTxnParser
+	coordinator *TransactionCoordinator
+}
+
+func NewEndTxnService(parser parser.EndTxnParser, coordinator *TransactionCoordinator) driving.KafkaHandler {
+	return &EndTxnService{
+		parser:      parser,
+		coordinator: coordinator,
+	}
+}
+
+func (s *EndTxnService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	responseData := &domain.ResponseDataEndTxn{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		ErrorCode:      s.coordinator.EndTransaction(parsedReq.TransactionalID, parsedReq.ProducerID, parsedReq.ProducerEpoch, parsedReq.Committed),
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
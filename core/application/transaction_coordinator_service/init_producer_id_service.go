@@ -0,0 +1,48 @@
+package transaction_coordinator_service
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// InitProducerIdService implements the driving port for InitProducerId requests. Idempotent
+// producers get a new producer ID at epoch 0 every time they initialize; transactional
+// ones keep the ID of their transactional ID at the next epoch.
+type InitProducerIdService struct {
+	parser      parser.InitProducerIdParser
+	coordinator *TransactionCoordinator
+}
+
+func NewInitProducerIdService(parser parser.InitProducerIdParser, coordinator *TransactionCoordinator) driving.KafkaHandler {
+	return &InitProducerIdService{
+		parser:      parser,
+		coordinator: coordinator,
+	}
+}
+
+func (s *InitProducerIdService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	producerID, producerEpoch, errorCode := s.coordinator.InitProducerID(parsedReq)
+	responseData := &domain.ResponseDataInitProducerId{
+		CorrelationID:  parsedReq.CorrelationID,
+		APIVersion:     parsedReq.APIVersion,
+		ThrottleTimeMs: 0,
+		ErrorCode:      errorCode,
+		ProducerID:     producerID,
+		ProducerEpoch:  producerEpoch,
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
@@ -0,0 +1,78 @@
+package transaction_coordinator_service
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/cluster_metadata_repository"
+)
+
+type mockInitProducerIdParser struct {
+	request  *domain.ParsedRequestInitProducerId
+	response *domain.ResponseDataInitProducerId
+}
+
+func (m *mockInitProducerIdParser) ParseRequest(data []byte) (*domain.ParsedRequestInitProducerId, error) {
+	return m.request, nil
+}
+
+func (m *mockInitProducerIdParser) EncodeResponse(response *domain.ResponseDataInitProducerId) ([]byte, error) {
+	m.response = response
+	return []byte{}, nil
+}
+
+func TestProducerIDManager_GenerateProducerID(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "__cluster_metadata-0")
+	manager := NewProducerIDManager(1, cluster_metadata_repository.NewClusterMetadataRepository(cluster_metadata_repository.WithMetadataLogDir(dir)))
+	manager.blockSize = 2
+
+	for _, want := range []int64{0, 1, 2} {
+		if producerID, err := manager.GenerateProducerID(); err != nil || producerID != want {
+			t.Errorf("GenerateProducerID() = %d, %v, want %d", producerID, err, want)
+		}
+	}
+
+	// A restarted broker continues after the blocks allocated before
+	restarted := NewProducerIDManager(1, cluster_metadata_repository.NewClusterMetadataRepository(cluster_metadata_repository.WithMetadataLogDir(dir)))
+	if producerID, err := restarted.GenerateProducerID(); err != nil || producerID != 4 {
+		t.Errorf("GenerateProducerID() after restart = %d, %v, want 4", producerID, err)
+	}
+}
+
+func TestInitProducerIdService_HandleRequest(t *testing.T) {
+	b := newTestBroker(t, t.TempDir())
+
+	handle := func(request *domain.ParsedRequestInitProducerId) *domain.ResponseDataInitProducerId {
+		t.Helper()
+		mockParser := &mockInitProducerIdParser{request: request}
+		if _, err := NewInitProducerIdService(mockParser, b.coordinator).HandleRequest(domain.Request{}); err != nil {
+			t.Fatalf("HandleRequest() error = %v", err)
+		}
+		return mockParser.response
+	}
+
+	first := handle(&domain.ParsedRequestInitProducerId{APIVersion: 4, TransactionTimeoutMs: -1, ProducerID: -1, ProducerEpoch: -1})
+	if first.ErrorCode != domain.ErrorCodeNone || first.ProducerID != 0 || first.ProducerEpoch != 0 {
+		t.Errorf("first InitProducerId = %+v, want producer 0 at epoch 0", first)
+	}
+	// Reinitializing an idempotent producer gives it a fresh ID
+	second := handle(&domain.ParsedRequestInitProducerId{APIVersion: 4, TransactionTimeoutMs: -1, ProducerID: 0, ProducerEpoch: 0})
+	if second.ErrorCode != domain.ErrorCodeNone || second.ProducerID != 1 || second.ProducerEpoch != 0 {
+		t.Errorf("second InitProducerId = %+v, want producer 1 at epoch 0", second)
+	}
+
+	// A transactional producer keeps its ID at the next epoch
+	transactional := handle(&domain.ParsedRequestInitProducerId{APIVersion: 4, TransactionalID: "txn-1", TransactionTimeoutMs: 60000, ProducerID: -1, ProducerEpoch: -1})
+	if transactional.ErrorCode != domain.ErrorCodeNone || transactional.ProducerID != 2 || transactional.ProducerEpoch != 0 {
+		t.Errorf("transactional InitProducerId = %+v, want producer 2 at epoch 0", transactional)
+	}
+	again := handle(&domain.ParsedRequestInitProducerId{APIVersion: 4, TransactionalID: "txn-1", TransactionTimeoutMs: 60000, ProducerID: -1, ProducerEpoch: -1})
+	if again.ErrorCode != domain.ErrorCodeNone || again.ProducerID != 2 || again.ProducerEpoch != 1 {
+		t.Errorf("transactional InitProducerId again = %+v, want producer 2 at epoch 1", again)
+	}
+	timedOut := handle(&domain.ParsedRequestInitProducerId{APIVersion: 4, TransactionalID: "txn-1", TransactionTimeoutMs: 0, ProducerID: -1, ProducerEpoch: -1})
+	if timedOut.ErrorCode != domain.ErrorCodeInvalidTransactionTimeout || timedOut.ProducerID != -1 {
+		t.Errorf("InitProducerId without timeout = %+v, want INVALID_TRANSACTION_TIMEOUT", timedOut)
+	}
+}
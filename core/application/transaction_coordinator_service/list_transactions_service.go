@@ -0,0 +1,46 @@
+package transaction_coordinator_service
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// ListTransactionsService implements the driving port for ListTransactions requests
+type ListTransactionsService struct {
+	parser      parser.ListTransactionsParser
+	coordinator *TransactionCoordinator
+}
+
+func NewListTransactionsService(parser parser.ListTransactionsParser, coordinator *TransactionCoordinator) driving.KafkaHandler {
+	return &ListTransactionsService{
+		parser:      parser,
+		coordinator: coordinator,
+	}
+}
+
+func (s *ListTransactionsService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	transactions, unknownStates := s.coordinator.ListTransactions(parsedReq.StateFilters, parsedReq.ProducerIDFilters, parsedReq.DurationFilter)
+	responseData := &domain.ResponseDataListTransactions{
+		CorrelationID:       parsedReq.CorrelationID,
+		APIVersion:          parsedReq.APIVersion,
+		ThrottleTimeMs:      0,
+		ErrorCode:           domain.ErrorCodeNone,
+		UnknownStateFilters: unknownStates,
+		TransactionStates:   transactions,
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
@@ -0,0 +1,52 @@
+package transaction_coordinator_service
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+)
+
+// DefaultProducerIDBlockSize is how many producer IDs are allocated from the metadata
+// log at once, the block size of Kafka's controller
+const DefaultProducerIDBlockSize int64 = 1000
+
+// ProducerIDManager hands out producer IDs from blocks allocated through ProducerIdsRecords
+// in the metadata log, so no ID is handed out twice, even across restarts
+type ProducerIDManager struct {
+	brokerID  int32
+	blockSize int64
+	writer    port_cluster_metadata_repository.ClusterMetadataWriter
+
+	mu    sync.Mutex
+	block domain.ProducerIDBlock
+	next  int64 // Next ID of the block to hand out
+}
+
+func NewProducerIDManager(brokerID int32, writer port_cluster_metadata_repository.ClusterMetadataWriter) *ProducerIDManager {
+	return &ProducerIDManager{
+		brokerID:  brokerID,
+		blockSize: DefaultProducerIDBlockSize,
+		writer:    writer,
+	}
+}
+
+// GenerateProducerID returns a producer ID never handed out before, allocating the next
+// block once the current one is used up
+func (m *ProducerIDManager) GenerateProducerID() (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.next >= m.block.NextBlockFirstProducerID() {
+		block, err := m.writer.AllocateProducerIds(m.brokerID, m.blockSize)
+		if err != nil {
+			return -1, fmt.Errorf("allocating producer IDs: %w", err)
+		}
+		m.block = block
+		m.next = block.FirstProducerID
+	}
+	producerID := m.next
+	m.next++
+	return producerID, nil
+}
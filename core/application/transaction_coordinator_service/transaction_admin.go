@@ -0,0 +1,100 @@
+package transaction_coordinator_service
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+)
+
+// DescribeTransactions describes the state of the transactional IDs; unknown ones get
+// TRANSACTIONAL_ID_NOT_FOUND
+func (c *TransactionCoordinator) DescribeTransactions(transactionalIDs []string) []domain.DescribedTransaction {
+	described := make([]domain.DescribedTransaction, 0, len(transactionalIDs))
+	for _, transactionalID := range transactionalIDs {
+		txn := c.transaction(transactionalID, false)
+		if txn == nil {
+			described = append(described, domain.DescribedTransaction{
+				ErrorCode:              domain.ErrorCodeTransactionalIDNotFound,
+				TransactionalID:        transactionalID,
+				TransactionStartTimeMs: -1,
+				ProducerID:             -1,
+				ProducerEpoch:          -1,
+				Topics:                 []domain.DescribedTransactionTopic{},
+			})
+			continue
+		}
+
+		txn.mu.Lock()
+		described = append(described, domain.DescribedTransaction{
+			ErrorCode:              domain.ErrorCodeNone,
+			TransactionalID:        transactionalID,
+			TransactionState:       txn.State.String(),
+			TransactionTimeoutMs:   txn.TimeoutMs,
+			TransactionStartTimeMs: txn.StartTimestamp,
+			ProducerID:             txn.ProducerID,
+			ProducerEpoch:          txn.ProducerEpoch,
+			Topics:                 describeTopics(txn.Partitions),
+		})
+		txn.mu.Unlock()
+	}
+	return described
+}
+
+// describeTopics groups the partitions by topic, in order
+func describeTopics(partitions []domain.TopicPartition) []domain.DescribedTransactionTopic {
+	byTopic := map[string][]int32{}
+	for _, tp := range partitions {
+		byTopic[tp.Topic] = append(byTopic[tp.Topic], tp.Partition)
+	}
+	topics := make([]domain.DescribedTransactionTopic, 0, len(byTopic))
+	for _, topic := range slices.Sorted(maps.Keys(byTopic)) {
+		topics = append(topics, domain.DescribedTransactionTopic{Topic: topic, Partitions: slices.Sorted(slices.Values(byTopic[topic]))})
+	}
+	return topics
+}
+
+// ListTransactions lists the transactional IDs in one of the states of stateFilters,
+// held by one of the producers of producerIDFilters and in a transaction that started
+// more than durationFilter ms ago; empty filters and a negative duration match every
+// transactional ID. It also returns the filtered states that are not transaction states.
+func (c *TransactionCoordinator) ListTransactions(stateFilters []string, producerIDFilters []int64, durationFilter int64) ([]domain.ListedTransaction, []string) {
+	states := []domain.TransactionState{}
+	unknownStates := []string{}
+	for _, name := range stateFilters {
+		if state, known := domain.ParseTransactionState(name); known {
+			states = append(states, state)
+		} else {
+			unknownStates = append(unknownStates, name)
+		}
+	}
+	// Only unknown states are filtered: nothing matches
+	if len(stateFilters) > 0 && len(states) == 0 {
+		return []domain.ListedTransaction{}, unknownStates
+	}
+
+	c.mu.Lock()
+	transactions := make([]*transaction, 0, len(c.transactions))
+	for _, transactionalID := range slices.Sorted(maps.Keys(c.transactions)) {
+		transactions = append(transactions, c.transactions[transactionalID])
+	}
+	c.mu.Unlock()
+
+	now := time.Now().UnixMilli()
+	listed := []domain.ListedTransaction{}
+	for _, txn := range transactions {
+		txn.mu.Lock()
+		if (len(states) == 0 || slices.Contains(states, txn.State)) &&
+			(len(producerIDFilters) == 0 || slices.Contains(producerIDFilters, txn.ProducerID)) &&
+			(durationFilter < 0 || now-txn.StartTimestamp > durationFilter) {
+			listed = append(listed, domain.ListedTransaction{
+				TransactionalID:  txn.TransactionalID,
+				ProducerID:       txn.ProducerID,
+				TransactionState: txn.State.String(),
+			})
+		}
+		txn.mu.Unlock()
+	}
+	return listed, unknownStates
+}
@@ -0,0 +1,71 @@
+package transaction_coordinator_service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+)
+
+func TestTransactionCoordinator_DescribeTransactions(t *testing.T) {
+	b := newTestBroker(t, t.TempDir())
+	producerID, epoch := b.initTransactional(t, "txn-1", -1, -1)
+	b.coordinator.AddPartitions("txn-1", producerID, epoch, []domain.TopicPartition{
+		{Topic: "orders", Partition: 1}, orders0, {Topic: domain.ConsumerOffsetsTopic, Partition: 3},
+	})
+
+	described := b.coordinator.DescribeTransactions([]string{"txn-1", "txn-2"})
+	if len(described) != 2 {
+		t.Fatalf("DescribeTransactions() = %+v, want 2 transactions", described)
+	}
+	ongoing := described[0]
+	if ongoing.ErrorCode != domain.ErrorCodeNone || ongoing.TransactionState != "Ongoing" || ongoing.ProducerID != producerID ||
+		ongoing.ProducerEpoch != epoch || ongoing.TransactionTimeoutMs != 60000 || ongoing.TransactionStartTimeMs <= 0 {
+		t.Errorf("txn-1 = %+v, want an ongoing transaction of producer %d", ongoing, producerID)
+	}
+	wantTopics := []domain.DescribedTransactionTopic{
+		{Topic: domain.ConsumerOffsetsTopic, Partitions: []int32{3}},
+		{Topic: "orders", Partitions: []int32{0, 1}},
+	}
+	if !reflect.DeepEqual(ongoing.Topics, wantTopics) {
+		t.Errorf("txn-1 topics = %+v, want %+v", ongoing.Topics, wantTopics)
+	}
+	if described[1].ErrorCode != domain.ErrorCodeTransactionalIDNotFound {
+		t.Errorf("txn-2 error = %d, want TRANSACTIONAL_ID_NOT_FOUND", described[1].ErrorCode)
+	}
+}
+
+func TestTransactionCoordinator_ListTransactions(t *testing.T) {
+	b := newTestBroker(t, t.TempDir())
+	ongoingID, ongoingEpoch := b.initTransactional(t, "txn-ongoing", -1, -1)
+	b.coordinator.AddPartitions("txn-ongoing", ongoingID, ongoingEpoch, []domain.TopicPartition{orders0})
+	emptyID, _ := b.initTransactional(t, "txn-empty", -1, -1)
+
+	ids := func(listed []domain.ListedTransaction) []string {
+		transactionalIDs := []string{}
+		for _, txn := range listed {
+			transactionalIDs = append(transactionalIDs, txn.TransactionalID)
+		}
+		return transactionalIDs
+	}
+	tests := []struct {
+		name        string
+		states      []string
+		producerIDs []int64
+		duration    int64
+		want        []string
+		wantUnknown []string
+	}{
+		{name: "all", duration: -1, want: []string{"txn-empty", "txn-ongoing"}, wantUnknown: []string{}},
+		{name: "by state", states: []string{"ongoing", "Pending"}, duration: -1, want: []string{"txn-ongoing"}, wantUnknown: []string{"Pending"}},
+		{name: "only unknown states", states: []string{"Pending"}, duration: -1, want: []string{}, wantUnknown: []string{"Pending"}},
+		{name: "by producer", producerIDs: []int64{emptyID}, duration: -1, want: []string{"txn-empty"}, wantUnknown: []string{}},
+		{name: "running too short", states: []string{"Ongoing"}, duration: 60000, want: []string{}, wantUnknown: []string{}},
+	}
+	for _, tt := range tests {
+		listed, unknown := b.coordinator.ListTransactions(tt.states, tt.producerIDs, tt.duration)
+		if got := ids(listed); !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(unknown, tt.wantUnknown) {
+			t.Errorf("%s: ListTransactions() = %v, unknown %v, want %v, unknown %v", tt.name, got, unknown, tt.want, tt.wantUnknown)
+		}
+	}
+}
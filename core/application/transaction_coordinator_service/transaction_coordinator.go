@@ -0,0 +1,386 @@
+package transaction_coordinator_service
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	port_transaction_state "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/transaction_state"
+)
+
+// Defaults of transaction.max.timeout.ms and
+// transaction.abort.timed.out.transaction.cleanup.interval.ms
+const (
+	DefaultMaxTransactionTimeout             = 15 * time.Minute
+	DefaultAbortTimedOutTransactionsInterval = 10 * time.Second
+)
+
+// GroupPartitioner tells which partition of __consumer_offsets keeps the offsets of a group
+type GroupPartitioner interface {
+	PartitionFor(groupID string) int
+}
+
+type TransactionCoordinatorOption func(*TransactionCoordinator)
+
+// WithTransactionStateRepository persists the state of the transactional IDs, which
+// otherwise only lives in memory
+func WithTransactionStateRepository(repository port_transaction_state.TransactionStateRepository) TransactionCoordinatorOption {
+	return func(c *TransactionCoordinator) {
+		c.stateRepository = repository
+	}
+}
+
+// WithMaxTransactionTimeout changes the longest transaction timeout producers may ask for
+func WithMaxTransactionTimeout(timeout time.Duration) TransactionCoordinatorOption {
+	return func(c *TransactionCoordinator) {
+		c.maxTransactionTimeout = timeout
+	}
+}
+
+// WithAbortTimedOutTransactionsInterval changes how often transactions that outlived
+// their timeout are looked for
+func WithAbortTimedOutTransactionsInterval(interval time.Duration) TransactionCoordinatorOption {
+	return func(c *TransactionCoordinator) {
+		c.abortInterval = interval
+	}
+}
+
+// TransactionCoordinator runs the transactions of the transactional producers of this
+// broker. A producer registers the partitions it writes to before writing, and ending
+// the transaction writes a COMMIT or ABORT marker to each of them. The state of every
+// transactional ID goes through __transaction_state first, so a transaction that was
+// ending when the broker stopped is completed on start. Transactions left open past
+// their timeout are aborted in the background.
+type TransactionCoordinator struct {
+	producerIDManager *ProducerIDManager
+	markerWriter      *TxnMarkerWriter
+	groupPartitioner  GroupPartitioner
+
+	stateRepository       port_transaction_state.TransactionStateRepository
+	maxTransactionTimeout time.Duration
+	abortInterval         time.Duration
+	stopAborts            chan struct{}
+
+	// coordinatorEpoch is written into the markers; this broker is the only coordinator
+	coordinatorEpoch int32
+
+	mu           sync.Mutex // Guards transactions; each transaction has its own lock
+	transactions map[string]*transaction
+}
+
+// transaction is the state of a transactional ID
+type transaction struct {
+	mu sync.Mutex
+	domain.TransactionMetadata
+	// lastProducerEpoch is the epoch before the coordinator bumped it to abort a timed
+	// out transaction, -1 otherwise; the fenced producer may still initialize with it
+	lastProducerEpoch int16
+}
+
+func NewTransactionCoordinator(producerIDManager *ProducerIDManager, markerWriter *TxnMarkerWriter, groupPartitioner GroupPartitioner, opts ...TransactionCoordinatorOption) *TransactionCoordinator {
+	c := &TransactionCoordinator{
+		producerIDManager:     producerIDManager,
+		markerWriter:          markerWriter,
+		groupPartitioner:      groupPartitioner,
+		maxTransactionTimeout: DefaultMaxTransactionTimeout,
+		abortInterval:         DefaultAbortTimedOutTransactionsInterval,
+		stopAborts:            make(chan struct{}),
+		transactions:          make(map[string]*transaction),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Start loads the state of the transactional IDs, completes the transactions that were
+// ending, and starts aborting timed out transactions
+func (c *TransactionCoordinator) Start() error {
+	if c.stateRepository != nil {
+		loaded, err := c.stateRepository.LoadTransactions()
+		if err != nil {
+			return err
+		}
+		c.mu.Lock()
+		for transactionalID, metadata := range loaded {
+			c.transactions[transactionalID] = &transaction{TransactionMetadata: metadata, lastProducerEpoch: -1}
+		}
+		c.mu.Unlock()
+		for _, transactionalID := range slices.Sorted(maps.Keys(loaded)) {
+			txn := c.transaction(transactionalID, false)
+			txn.mu.Lock()
+			if txn.State == domain.TransactionStatePrepareCommit || txn.State == domain.TransactionStatePrepareAbort {
+				c.writeMarkers(txn)
+			}
+			txn.mu.Unlock()
+		}
+		fmt.Printf("Loaded the state of %d transactional ID(s)\n", len(loaded))
+	}
+
+	go c.runAbortTimedOutTransactions()
+	return nil
+}
+
+// Close stops aborting timed out transactions
+func (c *TransactionCoordinator) Close() {
+	close(c.stopAborts)
+}
+
+func (c *TransactionCoordinator) runAbortTimedOutTransactions() {
+	ticker := time.NewTicker(c.abortInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stopAborts:
+			return
+		case now := <-ticker.C:
+			c.abortTimedOutTransactions(now)
+		}
+	}
+}
+
+// transaction returns the state of the transactional ID, creating it without a producer
+// when create is set
+func (c *TransactionCoordinator) transaction(transactionalID string, create bool) *transaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	txn, exists := c.transactions[transactionalID]
+	if !exists && create {
+		txn = &transaction{
+			TransactionMetadata: domain.TransactionMetadata{
+				TransactionalID: transactionalID,
+				ProducerID:      -1,
+				ProducerEpoch:   -1,
+				State:           domain.TransactionStateEmpty,
+				StartTimestamp:  -1,
+			},
+			lastProducerEpoch: -1,
+		}
+		c.transactions[transactionalID] = txn
+	}
+	return txn
+}
+
+// InitProducerID hands an idempotent producer a new producer ID. A transactional producer
+// gets the ID of its transactional ID with the epoch bumped, fencing its previous
+// instances; a transaction left open by them is aborted first.
+func (c *TransactionCoordinator) InitProducerID(req *domain.ParsedRequestInitProducerId) (int64, int16, int16) {
+	if req.TransactionalID == "" {
+		producerID, err := c.producerIDManager.GenerateProducerID()
+		if err != nil {
+			fmt.Println("InitProducerId:", err.Error())
+			return -1, -1, domain.ErrorCodeCoordinatorNotAvailable
+		}
+		return producerID, 0, domain.ErrorCodeNone
+	}
+	if req.TransactionTimeoutMs <= 0 || time.Duration(req.TransactionTimeoutMs)*time.Millisecond > c.maxTransactionTimeout {
+		return -1, -1, domain.ErrorCodeInvalidTransactionTimeout
+	}
+
+	txn := c.transaction(req.TransactionalID, true)
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+
+	switch {
+	case txn.State == domain.TransactionStatePrepareCommit || txn.State == domain.TransactionStatePrepareAbort:
+		return -1, -1, domain.ErrorCodeConcurrentTransactions
+	case req.ProducerID >= 0 && txn.ProducerID >= 0 && (req.ProducerID != txn.ProducerID ||
+		req.ProducerEpoch != txn.ProducerEpoch && req.ProducerEpoch != txn.lastProducerEpoch):
+		return -1, -1, domain.ErrorCodeProducerFenced
+	}
+	if txn.State == domain.TransactionStateOngoing {
+		if errorCode := c.completeTransaction(txn, false, true); errorCode != domain.ErrorCodeNone {
+			return -1, -1, errorCode
+		}
+	}
+
+	initialized := txn.TransactionMetadata
+	if txn.ProducerID < 0 || txn.ProducerEpoch >= math.MaxInt16-1 {
+		producerID, err := c.producerIDManager.GenerateProducerID()
+		if err != nil {
+			fmt.Println("InitProducerId:", err.Error())
+			return -1, -1, domain.ErrorCodeCoordinatorNotAvailable
+		}
+		initialized.ProducerID = producerID
+		initialized.ProducerEpoch = 0
+	} else {
+		initialized.ProducerEpoch++
+	}
+	initialized.TimeoutMs = req.TransactionTimeoutMs
+	initialized.State = domain.TransactionStateEmpty
+	initialized.Partitions = nil
+	initialized.StartTimestamp = -1
+	if errorCode := c.store(txn, initialized); errorCode != domain.ErrorCodeNone {
+		return -1, -1, errorCode
+	}
+	txn.lastProducerEpoch = -1
+	return txn.ProducerID, txn.ProducerEpoch, domain.ErrorCodeNone
+}
+
+// AddPartitions adds partitions to the producer's transaction, beginning one when none is
+// ongoing
+func (c *TransactionCoordinator) AddPartitions(transactionalID string, producerID int64, producerEpoch int16, partitions []domain.TopicPartition) int16 {
+	txn := c.transaction(transactionalID, false)
+	if txn == nil {
+		return domain.ErrorCodeInvalidProducerIDMapping
+	}
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+
+	if errorCode := txn.validateProducer(producerID, producerEpoch); errorCode != domain.ErrorCodeNone {
+		return errorCode
+	}
+	if txn.State == domain.TransactionStatePrepareCommit || txn.State == domain.TransactionStatePrepareAbort {
+		return domain.ErrorCodeConcurrentTransactions
+	}
+	if txn.State == domain.TransactionStateOngoing && !slices.ContainsFunc(partitions, func(tp domain.TopicPartition) bool {
+		return !slices.Contains(txn.Partitions, tp)
+	}) {
+		return domain.ErrorCodeNone
+	}
+
+	ongoing := txn.TransactionMetadata
+	if txn.State != domain.TransactionStateOngoing {
+		ongoing.State = domain.TransactionStateOngoing
+		ongoing.Partitions = nil
+		ongoing.StartTimestamp = time.Now().UnixMilli()
+	}
+	ongoing.Partitions = slices.Clone(ongoing.Partitions)
+	for _, tp := range partitions {
+		if !slices.Contains(ongoing.Partitions, tp) {
+			ongoing.Partitions = append(ongoing.Partitions, tp)
+		}
+	}
+	return c.store(txn, ongoing)
+}
+
+// AddOffsets adds the partition of __consumer_offsets keeping the group's offsets to the
+// producer's transaction, so the offsets it commits for the group end with it
+func (c *TransactionCoordinator) AddOffsets(transactionalID string, producerID int64, producerEpoch int16, groupID string) int16 {
+	if groupID == "" {
+		return domain.ErrorCodeInvalidGroupID
+	}
+	tp := domain.TopicPartition{Topic: domain.ConsumerOffsetsTopic, Partition: int32(c.groupPartitioner.PartitionFor(groupID))}
+	return c.AddPartitions(transactionalID, producerID, producerEpoch, []domain.TopicPartition{tp})
+}
+
+// EndTransaction commits or aborts the producer's ongoing transaction, returning once
+// the markers are written. Retrying the request after it completed succeeds.
+func (c *TransactionCoordinator) EndTransaction(transactionalID string, producerID int64, producerEpoch int16, committed bool) int16 {
+	txn := c.transaction(transactionalID, false)
+	if txn == nil {
+		return domain.ErrorCodeInvalidProducerIDMapping
+	}
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+
+	if errorCode := txn.validateProducer(producerID, producerEpoch); errorCode != domain.ErrorCodeNone {
+		return errorCode
+	}
+	switch txn.State {
+	case domain.TransactionStateOngoing:
+		return c.completeTransaction(txn, committed, false)
+	case domain.TransactionStateCompleteCommit:
+		if committed {
+			return domain.ErrorCodeNone
+		}
+	case domain.TransactionStateCompleteAbort:
+		if !committed {
+			return domain.ErrorCodeNone
+		}
+	case domain.TransactionStatePrepareCommit, domain.TransactionStatePrepareAbort:
+		return domain.ErrorCodeConcurrentTransactions
+	}
+	return domain.ErrorCodeInvalidTxnState
+}
+
+// validateProducer checks that a request comes from the current producer of the
+// transactional ID. It must be called with txn.mu held.
+func (txn *transaction) validateProducer(producerID int64, producerEpoch int16) int16 {
+	if txn.ProducerID < 0 || producerID != txn.ProducerID {
+		return domain.ErrorCodeInvalidProducerIDMapping
+	}
+	if producerEpoch != txn.ProducerEpoch {
+		return domain.ErrorCodeProducerFenced
+	}
+	return domain.ErrorCodeNone
+}
+
+// completeTransaction records that the ongoing transaction commits or aborts, then writes
+// its markers. Bumping the epoch fences the producer that left it open. It must be
+// called with txn.mu held.
+func (c *TransactionCoordinator) completeTransaction(txn *transaction, committed bool, bumpEpoch bool) int16 {
+	prepared := txn.TransactionMetadata
+	prepared.State = domain.TransactionStatePrepareAbort
+	if committed {
+		prepared.State = domain.TransactionStatePrepareCommit
+	}
+	if bumpEpoch && prepared.ProducerEpoch < math.MaxInt16-1 {
+		prepared.ProducerEpoch++
+	}
+	lastProducerEpoch := txn.ProducerEpoch
+	if errorCode := c.store(txn, prepared); errorCode != domain.ErrorCodeNone {
+		return errorCode
+	}
+	if bumpEpoch {
+		txn.lastProducerEpoch = lastProducerEpoch
+	}
+	return c.writeMarkers(txn)
+}
+
+// writeMarkers writes the markers of the transaction being prepared to its partitions and
+// records it complete. Markers that can't be written are reported and skipped, like
+// those of deleted partitions. It must be called with txn.mu held.
+func (c *TransactionCoordinator) writeMarkers(txn *transaction) int16 {
+	committed := txn.State == domain.TransactionStatePrepareCommit
+	for _, tp := range txn.Partitions {
+		errorCode := c.markerWriter.WriteMarker(tp, txn.ProducerID, txn.ProducerEpoch, c.coordinatorEpoch, committed)
+		if errorCode != domain.ErrorCodeNone {
+			fmt.Printf("Transaction %s: no marker written to %s-%d, error %d\n", txn.TransactionalID, tp.Topic, tp.Partition, errorCode)
+		}
+	}
+
+	completed := txn.TransactionMetadata
+	completed.State = domain.TransactionStateCompleteAbort
+	if committed {
+		completed.State = domain.TransactionStateCompleteCommit
+	}
+	completed.Partitions = nil
+	return c.store(txn, completed)
+}
+
+// store persists the new state of the transaction and makes it current. It must be
+// called with txn.mu held.
+func (c *TransactionCoordinator) store(txn *transaction, updated domain.TransactionMetadata) int16 {
+	updated.LastUpdateTimestamp = time.Now().UnixMilli()
+	if c.stateRepository != nil {
+		if err := c.stateRepository.StoreTransaction(updated); err != nil {
+			fmt.Printf("Transaction %s: storing the state failed: %v\n", updated.TransactionalID, err)
+			return domain.ErrorCodeCoordinatorNotAvailable
+		}
+	}
+	txn.TransactionMetadata = updated
+	return domain.ErrorCodeNone
+}
+
+// abortTimedOutTransactions aborts the transactions that have been ongoing for longer
+// than their timeout, fencing their producers
+func (c *TransactionCoordinator) abortTimedOutTransactions(now time.Time) {
+	c.mu.Lock()
+	transactions := slices.Collect(maps.Values(c.transactions))
+	c.mu.Unlock()
+
+	for _, txn := range transactions {
+		txn.mu.Lock()
+		if txn.State == domain.TransactionStateOngoing && now.UnixMilli() > txn.StartTimestamp+int64(txn.TimeoutMs) {
+			fmt.Printf("Transaction %s: aborting after its timeout of %d ms\n", txn.TransactionalID, txn.TimeoutMs)
+			c.completeTransaction(txn, false, true)
+		}
+		txn.mu.Unlock()
+	}
+}
@@ -0,0 +1,256 @@
+package transaction_coordinator_service
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/cluster_metadata_repository"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/consumer_offsets_repository"
+	partition_file_repository "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/partition_repository"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/transaction_state_repository"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+var orders0 = domain.TopicPartition{Topic: "orders", Partition: 0}
+
+// offsetsRecorder records the transactions whose offsets were completed
+type offsetsRecorder struct {
+	completed map[int64]bool
+}
+
+func (r *offsetsRecorder) CompleteTransactionalOffsets(producerID int64, committed bool) {
+	r.completed[producerID] = committed
+}
+
+// testBroker is a transaction coordinator over a topic orders with two partitions,
+// persisting everything to logDir
+type testBroker struct {
+	metadata    *cluster_metadata_repository.ClusterMetadata
+	partitions  *partition_file_repository.PartitionFileRepository
+	offsets     *offsetsRecorder
+	coordinator *TransactionCoordinator
+}
+
+func newTestBroker(t *testing.T, logDir string, opts ...TransactionCoordinatorOption) *testBroker {
+	t.Helper()
+	b := &testBroker{
+		metadata: cluster_metadata_repository.NewClusterMetadataRepository(
+			cluster_metadata_repository.WithMetadataLogDir(filepath.Join(logDir, "__cluster_metadata-0"))),
+		partitions: partition_file_repository.NewPartitionFileRepositoryWithLogDir(logDir),
+		offsets:    &offsetsRecorder{completed: map[int64]bool{}},
+	}
+	if _, err := b.metadata.GetClusterMetadata(); err != nil {
+		if err := b.metadata.CreateTopic(domain.NewTopic{
+			Name:    "orders",
+			TopicID: bytes.Repeat([]byte{0xaa}, 16),
+			Partitions: []domain.NewPartition{
+				{PartitionIndex: 0, Replicas: []int32{1}},
+				{PartitionIndex: 1, Replicas: []int32{1}},
+			},
+		}); err != nil {
+			t.Fatalf("CreateTopic() error = %v", err)
+		}
+	}
+	opts = append([]TransactionCoordinatorOption{
+		WithTransactionStateRepository(transaction_state_repository.NewTransactionStateRepository(b.partitions)),
+	}, opts...)
+	b.coordinator = NewTransactionCoordinator(
+		NewProducerIDManager(1, b.metadata),
+		NewTxnMarkerWriter(b.metadata, b.partitions, b.offsets),
+		consumer_offsets_repository.NewConsumerOffsetsRepository(b.partitions),
+		opts...,
+	)
+	if err := b.coordinator.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(b.coordinator.Close)
+	return b
+}
+
+func (b *testBroker) initTransactional(t *testing.T, transactionalID string, producerID int64, producerEpoch int16) (int64, int16) {
+	t.Helper()
+	producerID, producerEpoch, errorCode := b.coordinator.InitProducerID(&domain.ParsedRequestInitProducerId{
+		TransactionalID:      transactionalID,
+		TransactionTimeoutMs: 60000,
+		ProducerID:           producerID,
+		ProducerEpoch:        producerEpoch,
+	})
+	if errorCode != domain.ErrorCodeNone {
+		t.Fatalf("InitProducerID(%s) error = %d", transactionalID, errorCode)
+	}
+	return producerID, producerEpoch
+}
+
+// lastBatch returns the last batch of the partition
+func (b *testBroker) lastBatch(t *testing.T, tp domain.TopicPartition) common.RecordBatch {
+	t.Helper()
+	read, err := b.partitions.ReadRecordBatches(domain.ReadRequest{TopicName: tp.Topic, PartitionIndex: int(tp.Partition), MaxBytes: 1 << 20})
+	if err != nil {
+		t.Fatalf("ReadRecordBatches() error = %v", err)
+	}
+	batches, _ := common.SplitRecordBatches(read.Records)
+	if len(batches) == 0 {
+		t.Fatalf("%s-%d is empty", tp.Topic, tp.Partition)
+	}
+	batch, _ := common.DecodeRecordBatch(batches[len(batches)-1])
+	return batch
+}
+
+func (b *testBroker) assertMarker(t *testing.T, tp domain.TopicPartition, producerEpoch int16, markerType int16) {
+	t.Helper()
+	batch := b.lastBatch(t, tp)
+	marker, err := common.DecodeControlRecord(batch)
+	if err != nil || marker.Type != markerType || batch.ProducerEpoch != producerEpoch {
+		t.Errorf("last batch of %s-%d = marker %+v at epoch %d, %v, want type %d at epoch %d", tp.Topic, tp.Partition, marker, batch.ProducerEpoch, err, markerType, producerEpoch)
+	}
+}
+
+func TestTransactionCoordinator_CommitAndAbort(t *testing.T) {
+	b := newTestBroker(t, t.TempDir())
+	c := b.coordinator
+	producerID, epoch := b.initTransactional(t, "txn-1", -1, -1)
+	if producerID != 0 || epoch != 0 {
+		t.Errorf("InitProducerID() = producer %d epoch %d, want 0 epoch 0", producerID, epoch)
+	}
+
+	if errorCode := c.EndTransaction("txn-1", producerID, epoch, true); errorCode != domain.ErrorCodeInvalidTxnState {
+		t.Errorf("EndTransaction() without a transaction = %d, want INVALID_TXN_STATE", errorCode)
+	}
+	if errorCode := c.AddPartitions("txn-1", producerID, epoch, []domain.TopicPartition{orders0}); errorCode != domain.ErrorCodeNone {
+		t.Fatalf("AddPartitions() = %d, want none", errorCode)
+	}
+	if errorCode := c.AddOffsets("txn-1", producerID, epoch, "group-a"); errorCode != domain.ErrorCodeNone {
+		t.Fatalf("AddOffsets() = %d, want none", errorCode)
+	}
+	if errorCode := c.EndTransaction("txn-1", producerID, epoch, true); errorCode != domain.ErrorCodeNone {
+		t.Fatalf("EndTransaction() = %d, want none", errorCode)
+	}
+	b.assertMarker(t, orders0, 0, common.ControlRecordTypeCommit)
+	b.assertMarker(t, domain.TopicPartition{Topic: domain.ConsumerOffsetsTopic, Partition: 47}, 0, common.ControlRecordTypeCommit)
+	if committed, completed := b.offsets.completed[producerID]; !completed || !committed {
+		t.Errorf("offsets of the transaction completed = %v, %v, want committed", completed, committed)
+	}
+
+	// A retry succeeds, the opposite outcome doesn't
+	if errorCode := c.EndTransaction("txn-1", producerID, epoch, true); errorCode != domain.ErrorCodeNone {
+		t.Errorf("retried EndTransaction() = %d, want none", errorCode)
+	}
+	if errorCode := c.EndTransaction("txn-1", producerID, epoch, false); errorCode != domain.ErrorCodeInvalidTxnState {
+		t.Errorf("abort after commit = %d, want INVALID_TXN_STATE", errorCode)
+	}
+
+	c.AddPartitions("txn-1", producerID, epoch, []domain.TopicPartition{{Topic: "orders", Partition: 1}})
+	if errorCode := c.EndTransaction("txn-1", producerID, epoch, false); errorCode != domain.ErrorCodeNone {
+		t.Fatalf("abort = %d, want none", errorCode)
+	}
+	b.assertMarker(t, domain.TopicPartition{Topic: "orders", Partition: 1}, 0, common.ControlRecordTypeAbort)
+
+	for _, tc := range []struct {
+		name            string
+		transactionalID string
+		producerID      int64
+		epoch           int16
+		want            int16
+	}{
+		{"unknown transactional ID", "txn-2", producerID, epoch, domain.ErrorCodeInvalidProducerIDMapping},
+		{"other producer", "txn-1", producerID + 1, epoch, domain.ErrorCodeInvalidProducerIDMapping},
+		{"other epoch", "txn-1", producerID, epoch + 1, domain.ErrorCodeProducerFenced},
+	} {
+		if errorCode := c.AddPartitions(tc.transactionalID, tc.producerID, tc.epoch, []domain.TopicPartition{orders0}); errorCode != tc.want {
+			t.Errorf("%s: AddPartitions() = %d, want %d", tc.name, errorCode, tc.want)
+		}
+	}
+}
+
+func TestTransactionCoordinator_InitProducerIDFencesOngoing(t *testing.T) {
+	b := newTestBroker(t, t.TempDir())
+	c := b.coordinator
+	producerID, epoch := b.initTransactional(t, "txn-1", -1, -1)
+	c.AddPartitions("txn-1", producerID, epoch, []domain.TopicPartition{orders0})
+
+	// The new instance's transaction aborts the old instance's one, fencing it
+	newProducerID, newEpoch := b.initTransactional(t, "txn-1", -1, -1)
+	if newProducerID != producerID || newEpoch != 2 {
+		t.Errorf("InitProducerID() = producer %d epoch %d, want %d epoch 2", newProducerID, newEpoch, producerID)
+	}
+	b.assertMarker(t, orders0, 1, common.ControlRecordTypeAbort)
+	if errorCode := c.AddPartitions("txn-1", producerID, epoch, []domain.TopicPartition{orders0}); errorCode != domain.ErrorCodeProducerFenced {
+		t.Errorf("AddPartitions() of the old instance = %d, want PRODUCER_FENCED", errorCode)
+	}
+
+	_, _, errorCode := c.InitProducerID(&domain.ParsedRequestInitProducerId{TransactionalID: "txn-1", TransactionTimeoutMs: 60000, ProducerID: producerID, ProducerEpoch: epoch})
+	if errorCode != domain.ErrorCodeProducerFenced {
+		t.Errorf("InitProducerID() at a stale epoch = %d, want PRODUCER_FENCED", errorCode)
+	}
+	_, _, errorCode = c.InitProducerID(&domain.ParsedRequestInitProducerId{TransactionalID: "txn-1", TransactionTimeoutMs: int32(time.Hour.Milliseconds()), ProducerID: -1, ProducerEpoch: -1})
+	if errorCode != domain.ErrorCodeInvalidTransactionTimeout {
+		t.Errorf("InitProducerID() with a timeout over the maximum = %d, want INVALID_TRANSACTION_TIMEOUT", errorCode)
+	}
+}
+
+func TestTransactionCoordinator_AbortTimedOutTransactions(t *testing.T) {
+	b := newTestBroker(t, t.TempDir())
+	c := b.coordinator
+	producerID, epoch := b.initTransactional(t, "txn-1", -1, -1)
+	c.AddPartitions("txn-1", producerID, epoch, []domain.TopicPartition{orders0})
+
+	c.abortTimedOutTransactions(time.Now())
+	if errorCode := c.AddPartitions("txn-1", producerID, epoch, []domain.TopicPartition{orders0}); errorCode != domain.ErrorCodeNone {
+		t.Fatalf("AddPartitions() within the timeout = %d, want none", errorCode)
+	}
+
+	c.abortTimedOutTransactions(time.Now().Add(2 * time.Minute))
+	b.assertMarker(t, orders0, 1, common.ControlRecordTypeAbort)
+	if errorCode := c.EndTransaction("txn-1", producerID, epoch, true); errorCode != domain.ErrorCodeProducerFenced {
+		t.Errorf("EndTransaction() after the timeout = %d, want PRODUCER_FENCED", errorCode)
+	}
+	// The fenced producer may still initialize again with its last epoch
+	if _, newEpoch := b.initTransactional(t, "txn-1", producerID, epoch); newEpoch != 2 {
+		t.Errorf("InitProducerID() after the timeout = epoch %d, want 2", newEpoch)
+	}
+}
+
+func TestTransactionCoordinator_StateSurvivesRestart(t *testing.T) {
+	logDir := t.TempDir()
+	b := newTestBroker(t, logDir)
+	producerID, epoch := b.initTransactional(t, "txn-1", -1, -1)
+	b.coordinator.AddPartitions("txn-1", producerID, epoch, []domain.TopicPartition{orders0})
+	// The broker stops after deciding to commit, before writing the markers
+	stateRepository := transaction_state_repository.NewTransactionStateRepository(b.partitions)
+	stateRepository.StoreTransaction(domain.TransactionMetadata{
+		TransactionalID: "txn-1",
+		ProducerID:      producerID,
+		ProducerEpoch:   epoch,
+		TimeoutMs:       60000,
+		State:           domain.TransactionStatePrepareCommit,
+		Partitions:      []domain.TopicPartition{orders0},
+		StartTimestamp:  time.Now().UnixMilli(),
+	})
+
+	restarted := newTestBroker(t, logDir)
+	restarted.assertMarker(t, orders0, epoch, common.ControlRecordTypeCommit)
+	if errorCode := restarted.coordinator.EndTransaction("txn-1", producerID, epoch, true); errorCode != domain.ErrorCodeNone {
+		t.Errorf("retried EndTransaction() after restart = %d, want none", errorCode)
+	}
+	if newProducerID, newEpoch := restarted.initTransactional(t, "txn-1", -1, -1); newProducerID != producerID || newEpoch != epoch+1 {
+		t.Errorf("InitProducerID() after restart = producer %d epoch %d, want %d epoch %d", newProducerID, newEpoch, producerID, epoch+1)
+	}
+}
+
+func TestTxnMarkerWriter_WriteMarker_UnreadableMetadata(t *testing.T) {
+	logDir := t.TempDir()
+	metadata := cluster_metadata_repository.NewClusterMetadataRepository(
+		cluster_metadata_repository.WithMetadataLogDir(filepath.Join(logDir, "__cluster_metadata-0")))
+	partitions := partition_file_repository.NewPartitionFileRepositoryWithLogDir(logDir)
+	w := NewTxnMarkerWriter(metadata, partitions, nil)
+
+	if errorCode := w.WriteMarker(orders0, 1, 0, 0, true); errorCode != domain.ErrorCodeKafkaStorageError {
+		t.Errorf("WriteMarker() without cluster metadata = %d, want KAFKA_STORAGE_ERROR", errorCode)
+	}
+	if read, _ := partitions.ReadRecordBatches(domain.ReadRequest{TopicName: "orders", PartitionIndex: 0, MaxBytes: 1 << 20}); len(read.Records) != 0 {
+		t.Errorf("orders-0 has %d bytes of records, want no marker written", len(read.Records))
+	}
+}
@@ -0,0 +1,76 @@
+package transaction_coordinator_service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	port_cluster_metadata_repository "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+	port_repo "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/partition_file_repository"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// TransactionalOffsetsCompleter applies or drops the offsets a producer committed to
+// consumer groups within its transaction once the transaction ends
+type TransactionalOffsetsCompleter interface {
+	CompleteTransactionalOffsets(producerID int64, committed bool)
+}
+
+// TxnMarkerWriter writes the COMMIT and ABORT markers ending transactions to the
+// partitions the transactions wrote to
+type TxnMarkerWriter struct {
+	metadata_repository       port_cluster_metadata_repository.ClusterMetadataRepository
+	partition_file_repository port_repo.PartitionFileRepository
+	offsets                   TransactionalOffsetsCompleter
+}
+
+// NewTxnMarkerWriter creates a marker writer; offsets may be nil when no group
+// coordinator takes part in transactions
+func NewTxnMarkerWriter(metadata_repository port_cluster_metadata_repository.ClusterMetadataRepository, partition_file_repository port_repo.PartitionFileRepository, offsets TransactionalOffsetsCompleter) *TxnMarkerWriter {
+	return &TxnMarkerWriter{
+		metadata_repository:       metadata_repository,
+		partition_file_repository: partition_file_repository,
+		offsets:                   offsets,
+	}
+}
+
+// WriteMarker appends the marker ending the producer's transaction to the partition and
+// returns the error code for it. A marker on a partition of __consumer_offsets also
+// completes the offsets the producer committed in the transaction.
+func (w *TxnMarkerWriter) WriteMarker(tp domain.TopicPartition, producerID int64, producerEpoch int16, coordinatorEpoch int32, committed bool) int16 {
+	leaderEpoch := int32(0)
+	if tp.Topic != domain.ConsumerOffsetsTopic {
+		clusterMetaData, err := w.metadata_repository.GetClusterMetadata()
+		if err != nil {
+			fmt.Printf("Writing the marker of producer %d to %s-%d failed: %v\n", producerID, tp.Topic, tp.Partition, err)
+			return domain.ErrorCodeKafkaStorageError
+		}
+		partitionMetadata := clusterMetaData.FindPartition(tp.Topic, tp.Partition)
+		if partitionMetadata == nil {
+			return domain.ErrorCodeUnknownTopicOrPartition
+		}
+		leaderEpoch = int32(common.BytesToInt(partitionMetadata.LeaderEpoch))
+	}
+
+	_, err := w.partition_file_repository.WriteTxnMarker(domain.TxnMarkerAppend{
+		TopicName:        tp.Topic,
+		PartitionIndex:   int(tp.Partition),
+		LeaderEpoch:      leaderEpoch,
+		ProducerID:       producerID,
+		ProducerEpoch:    producerEpoch,
+		CoordinatorEpoch: coordinatorEpoch,
+		Committed:        committed,
+	})
+	if errors.Is(err, domain.ErrInvalidProducerEpoch) {
+		return domain.ErrorCodeInvalidProducerEpoch
+	}
+	if err != nil {
+		fmt.Printf("Writing the marker of producer %d to %s-%d failed: %v\n", producerID, tp.Topic, tp.Partition, err)
+		return domain.ErrorCodeKafkaStorageError
+	}
+
+	if tp.Topic == domain.ConsumerOffsetsTopic && w.offsets != nil {
+		w.offsets.CompleteTransactionalOffsets(producerID, committed)
+	}
+	return domain.ErrorCodeNone
+}
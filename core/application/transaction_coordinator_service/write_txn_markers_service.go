@@ -0,0 +1,64 @@
+package transaction_coordinator_service
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// WriteTxnMarkersService implements the driving port for WriteTxnMarkers requests, the
+// markers a transaction coordinator asks the partition leaders to write
+type WriteTxnMarkersService struct {
+	parser       parser.WriteTxnMarkersParser
+	markerWriter *TxnMarkerWriter
+}
+
+func NewWriteTxnMarkersService(parser parser.WriteTxnMarkersParser, markerWriter *TxnMarkerWriter) driving.KafkaHandler {
+	return &WriteTxnMarkersService{
+		parser:       parser,
+		markerWriter: markerWriter,
+	}
+}
+
+func (s *WriteTxnMarkersService) HandleRequest(req domain.Request) (domain.Response, error) {
+	parsedReq, err := s.parser.ParseRequest(req.Data)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	responseData := &domain.ResponseDataWriteTxnMarkers{
+		CorrelationID: parsedReq.CorrelationID,
+		APIVersion:    parsedReq.APIVersion,
+		Markers:       make([]domain.WritableTxnMarkerResult, 0, len(parsedReq.Markers)),
+	}
+	for _, marker := range parsedReq.Markers {
+		result := domain.WritableTxnMarkerResult{
+			ProducerID: marker.ProducerID,
+			Topics:     make([]domain.WritableTxnMarkerTopicResult, 0, len(marker.Topics)),
+		}
+		for _, topic := range marker.Topics {
+			topicResult := domain.WritableTxnMarkerTopicResult{
+				Name:       topic.Name,
+				Partitions: make([]domain.WritableTxnMarkerPartitionResult, 0, len(topic.PartitionIndexes)),
+			}
+			for _, partition := range topic.PartitionIndexes {
+				tp := domain.TopicPartition{Topic: topic.Name, Partition: partition}
+				topicResult.Partitions = append(topicResult.Partitions, domain.WritableTxnMarkerPartitionResult{
+					PartitionIndex: partition,
+					ErrorCode:      s.markerWriter.WriteMarker(tp, marker.ProducerID, marker.ProducerEpoch, marker.CoordinatorEpoch, marker.TransactionResult),
+				})
+			}
+			result.Topics = append(result.Topics, topicResult)
+		}
+		responseData.Markers = append(responseData.Markers, result)
+	}
+
+	encodedResponse, err := s.parser.EncodeResponse(responseData)
+	if err != nil {
+		return domain.Response{}, err
+	}
+
+	return domain.Response{
+		Data: encodedResponse,
+	}, nil
+}
@@ -0,0 +1,23 @@
+package domain
+
+type ParsedRequestAddOffsetsToTxn struct {
+	// Header fields
+	APIKey        int    // API Key (25 for AddOffsetsToTxn)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	TransactionalID string
+	ProducerID      int64
+	ProducerEpoch   int16
+	GroupID         string // Group whose offsets the transaction commits
+}
+
+// ResponseDataAddOffsetsToTxn represents the data needed to build an AddOffsetsToTxn response
+type ResponseDataAddOffsetsToTxn struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds
+	ErrorCode      int16
+}
@@ -0,0 +1,38 @@
+package domain
+
+type ParsedRequestAddPartitionsToTxn struct {
+	// Header fields
+	APIKey        int    // API Key (24 for AddPartitionsToTxn)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	TransactionalID string
+	ProducerID      int64
+	ProducerEpoch   int16
+	Topics          []AddPartitionsToTxnTopic
+}
+
+type AddPartitionsToTxnTopic struct {
+	Name       string
+	Partitions []int32
+}
+
+// ResponseDataAddPartitionsToTxn represents the data needed to build an AddPartitionsToTxn response
+type ResponseDataAddPartitionsToTxn struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds
+	Results        []AddPartitionsToTxnTopicResult
+}
+
+type AddPartitionsToTxnTopicResult struct {
+	Name       string
+	Partitions []AddPartitionsToTxnPartitionResult
+}
+
+type AddPartitionsToTxnPartitionResult struct {
+	PartitionIndex int32
+	ErrorCode      int16
+}
@@ -0,0 +1,36 @@
+package domain
+
+// Kafka API keys (https://kafka.apache.org/protocol#protocol_api_keys)
+const (
+	ApiKeyProduce                 int16 = 0
+	ApiKeyFetch                   int16 = 1
+	ApiKeyListOffsets             int16 = 2
+	ApiKeyMetadata                int16 = 3
+	ApiKeyOffsetCommit            int16 = 8
+	ApiKeyOffsetFetch             int16 = 9
+	ApiKeyFindCoordinator         int16 = 10
+	ApiKeyJoinGroup               int16 = 11
+	ApiKeyHeartbeat               int16 = 12
+	ApiKeyLeaveGroup              int16 = 13
+	ApiKeySyncGroup               int16 = 14
+	ApiKeyDescribeGroups          int16 = 15
+	ApiKeyListGroups              int16 = 16
+	ApiKeyApiVersions             int16 = 18
+	ApiKeyCreateTopics            int16 = 19
+	ApiKeyDeleteTopics            int16 = 20
+	ApiKeyInitProducerId          int16 = 22
+	ApiKeyAddPartitionsToTxn      int16 = 24
+	ApiKeyAddOffsetsToTxn         int16 = 25
+	ApiKeyEndTxn                  int16 = 26
+	ApiKeyWriteTxnMarkers         int16 = 27
+	ApiKeyTxnOffsetCommit         int16 = 28
+	ApiKeyCreatePartitions        int16 = 37
+	ApiKeyDeleteGroups            int16 = 42
+	ApiKeyOffsetDelete            int16 = 47
+	ApiKeyDescribeProducers       int16 = 61
+	ApiKeyDescribeTransactions    int16 = 65
+	ApiKeyListTransactions        int16 = 66
+	ApiKeyConsumerGroupHeartbeat  int16 = 68
+	ApiKeyConsumerGroupDescribe   int16 = 69
+	ApiKeyDescribeTopicPartitions int16 = 75
+)
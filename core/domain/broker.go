@@ -0,0 +1,15 @@
+package domain
+
+// Broker describes a broker node as advertised to clients
+type Broker struct {
+	NodeID int32
+	Host   string
+	Port   int32
+	Rack   string // Empty when the broker has no rack
+}
+
+// BrokerConfig holds the identity of this broker
+type BrokerConfig struct {
+	Broker
+	ClusterID string
+}
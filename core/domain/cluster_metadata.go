@@ -0,0 +1,48 @@
+package domain
+
+// Config resource types used by ConfigRecord and the config APIs
+const (
+	ConfigResourceTypeTopic  int8 = 2
+	ConfigResourceTypeBroker int8 = 4
+)
+
+// ConfigResource names the entity a set of configs belongs to. Cluster-wide broker
+// defaults use an empty Name.
+type ConfigResource struct {
+	Type int8
+	Name string
+}
+
+// BrokerRegistration is a broker as registered with the KRaft controller
+type BrokerRegistration struct {
+	BrokerID             int32
+	Epoch                int64
+	IncarnationID        []byte // UUID
+	Endpoints            []BrokerEndpoint
+	Rack                 string // Empty when the broker has no rack
+	Fenced               bool
+	InControlledShutdown bool
+}
+
+// BrokerEndpoint is a listener a registered broker accepts connections on
+type BrokerEndpoint struct {
+	Name             string
+	Host             string
+	Port             int32
+	SecurityProtocol int16
+}
+
+// NewTopic is a topic to be added to the cluster metadata
+type NewTopic struct {
+	Name       string
+	TopicID    []byte // UUID
+	Partitions []NewPartition
+	Configs    map[string]string
+}
+
+// NewPartition is a partition to be added to the cluster metadata. The first replica
+// leads and every replica starts in sync.
+type NewPartition struct {
+	PartitionIndex int32
+	Replicas       []int32
+}
@@ -0,0 +1,54 @@
+package domain
+
+type ParsedRequestConsumerGroupDescribe struct {
+	// Header fields
+	APIKey        int    // API Key (69 for ConsumerGroupDescribe)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	GroupIDs                    []string
+	IncludeAuthorizedOperations bool
+}
+
+// ResponseDataConsumerGroupDescribe represents the data needed to build a
+// ConsumerGroupDescribe response
+type ResponseDataConsumerGroupDescribe struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds
+	Groups         []ConsumerGroupDescription
+}
+
+type ConsumerGroupDescription struct {
+	ErrorCode            int16
+	ErrorMessage         string
+	GroupID              string
+	GroupState           string
+	GroupEpoch           int32
+	AssignmentEpoch      int32
+	AssignorName         string
+	Members              []ConsumerGroupMemberDescription
+	AuthorizedOperations int32
+}
+
+type ConsumerGroupMemberDescription struct {
+	MemberID             string
+	InstanceID           string
+	RackID               string
+	MemberEpoch          int32
+	ClientID             string
+	ClientHost           string
+	SubscribedTopicNames []string
+	SubscribedTopicRegex string
+	Assignment           []ConsumerGroupDescribedPartitions // Partitions the member owns
+	TargetAssignment     []ConsumerGroupDescribedPartitions // Partitions the member is heading to
+}
+
+// ConsumerGroupDescribedPartitions lists partitions of a topic by ID and name
+type ConsumerGroupDescribedPartitions struct {
+	TopicID    []byte
+	TopicName  string
+	Partitions []int32
+}
@@ -0,0 +1,53 @@
+package domain
+
+// Member epochs with a special meaning in a ConsumerGroupHeartbeat request
+const (
+	// ConsumerGroupJoinEpoch joins, or rejoins, the group
+	ConsumerGroupJoinEpoch int32 = 0
+	// ConsumerGroupLeaveEpoch leaves the group
+	ConsumerGroupLeaveEpoch int32 = -1
+	// ConsumerGroupStaticLeaveEpoch leaves the group for now, the static member keeping
+	// its assignment until its session expires
+	ConsumerGroupStaticLeaveEpoch int32 = -2
+)
+
+type ParsedRequestConsumerGroupHeartbeat struct {
+	// Header fields
+	APIKey        int    // API Key (68 for ConsumerGroupHeartbeat)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields. The nullable fields are null when they didn't change since the last
+	// heartbeat of the member.
+	GroupID              string
+	MemberID             string // Generated by the client from v1, empty when joining before
+	MemberEpoch          int32
+	InstanceID           string // Empty (null) for dynamic members
+	RackID               string
+	RebalanceTimeoutMs   int32                          // -1 when unchanged
+	SubscribedTopicNames []string                       // Nil (null) when unchanged
+	SubscribedTopicRegex *string                        // v1+, nil (null) when unchanged
+	ServerAssignor       string                         // Empty (null) to leave the choice to the broker
+	TopicPartitions      []ConsumerGroupTopicPartitions // Owned partitions, nil (null) when unchanged
+}
+
+// ConsumerGroupTopicPartitions lists partitions of the topic with the ID
+type ConsumerGroupTopicPartitions struct {
+	TopicID    []byte
+	Partitions []int32
+}
+
+// ResponseDataConsumerGroupHeartbeat represents the data needed to build a
+// ConsumerGroupHeartbeat response
+type ResponseDataConsumerGroupHeartbeat struct {
+	CorrelationID       []byte // Correlation ID (4 bytes)
+	APIVersion          int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs      int32  // Throttle time in milliseconds
+	ErrorCode           int16
+	ErrorMessage        string
+	MemberID            string
+	MemberEpoch         int32
+	HeartbeatIntervalMs int32
+	Assignment          []ConsumerGroupTopicPartitions // Nil (null) when unchanged
+}
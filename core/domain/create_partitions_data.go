@@ -0,0 +1,36 @@
+package domain
+
+type ParsedRequestCreatePartitions struct {
+	// Header fields
+	APIKey        int    // API Key (37 for CreatePartitions)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	Topics       []CreatePartitionsTopic
+	TimeoutMs    int32 // How long to wait for the partitions to be created
+	ValidateOnly bool  // Validate the request without creating anything
+}
+
+// CreatePartitionsTopic grows a topic to Count partitions
+type CreatePartitionsTopic struct {
+	Name        string
+	Count       int32     // New total number of partitions
+	Assignments [][]int32 // Brokers of each new partition, nil to let the broker place them
+}
+
+// ResponseDataCreatePartitions represents the data needed to build a CreatePartitions response
+type ResponseDataCreatePartitions struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds
+	Results        []CreatePartitionsTopicResult
+}
+
+// CreatePartitionsTopicResult is the outcome for one topic of the CreatePartitions request
+type CreatePartitionsTopicResult struct {
+	Name         string
+	ErrorCode    int16
+	ErrorMessage string // Encoded as null when empty
+}
@@ -0,0 +1,81 @@
+package domain
+
+// Defaults used when CreateTopics leaves the partition count or replication factor to
+// the broker, matching Kafka's num.partitions and default.replication.factor
+const (
+	DefaultNumPartitions     int32 = 1
+	DefaultReplicationFactor int16 = 1
+)
+
+// DefaultMaxPartitions is the most partitions a topic may be created with or grown to,
+// unless the broker is configured otherwise
+const DefaultMaxPartitions int32 = 10_000
+
+// Config sources reported with topic configs
+const (
+	ConfigSourceDynamicTopicConfig int8 = 1
+	ConfigSourceDefaultConfig      int8 = 5
+)
+
+type ParsedRequestCreateTopics struct {
+	// Header fields
+	APIKey        int    // API Key (19 for CreateTopics)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	Topics       []CreatableTopic
+	TimeoutMs    int32 // How long to wait for the topics to be created
+	ValidateOnly bool  // Validate the request without creating anything (v1+)
+}
+
+// CreatableTopic is a topic to create in the CreateTopics request
+type CreatableTopic struct {
+	Name              string
+	NumPartitions     int32 // -1 for the broker default or when Assignments are given
+	ReplicationFactor int16 // -1 for the broker default or when Assignments are given
+	Assignments       []CreatableReplicaAssignment
+	Configs           []CreatableTopicConfig
+}
+
+// CreatableReplicaAssignment places a partition on brokers; the first one leads
+type CreatableReplicaAssignment struct {
+	PartitionIndex int32
+	BrokerIDs      []int32
+}
+
+// CreatableTopicConfig is a config to set on the new topic
+type CreatableTopicConfig struct {
+	Name   string
+	Value  string
+	IsNull bool // The value was sent as null
+}
+
+// ResponseDataCreateTopics represents the data needed to build a CreateTopics response
+type ResponseDataCreateTopics struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds (v2+)
+	Topics         []CreatableTopicResult
+}
+
+// CreatableTopicResult is the outcome for one topic of the CreateTopics request
+type CreatableTopicResult struct {
+	Name              string
+	TopicID           []byte // UUID, zero on error or with validate_only (v7+)
+	ErrorCode         int16
+	ErrorMessage      string                       // Encoded as null when empty (v1+)
+	NumPartitions     int32                        // -1 on error (v5+)
+	ReplicationFactor int16                        // -1 on error (v5+)
+	Configs           []CreatableTopicConfigResult // Encoded as null on error (v5+)
+}
+
+// CreatableTopicConfigResult is a config of the created topic
+type CreatableTopicConfigResult struct {
+	Name         string
+	Value        string
+	ReadOnly     bool
+	ConfigSource int8
+	IsSensitive  bool
+}
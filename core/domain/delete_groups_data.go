@@ -0,0 +1,25 @@
+package domain
+
+type ParsedRequestDeleteGroups struct {
+	// Header fields
+	APIKey        int    // API Key (42 for DeleteGroups)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	GroupsNames []string
+}
+
+// ResponseDataDeleteGroups represents the data needed to build a DeleteGroups response
+type ResponseDataDeleteGroups struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds
+	Results        []DeleteGroupsResult
+}
+
+type DeleteGroupsResult struct {
+	GroupID   string
+	ErrorCode int16
+}
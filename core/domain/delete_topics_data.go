@@ -0,0 +1,35 @@
+package domain
+
+type ParsedRequestDeleteTopics struct {
+	// Header fields
+	APIKey        int    // API Key (20 for DeleteTopics)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	Topics    []DeleteTopicState // Topics by name, or by name or ID (v6+)
+	TimeoutMs int32              // How long to wait for the deletions to complete
+}
+
+// DeleteTopicState names a topic to delete by name or by topic ID
+type DeleteTopicState struct {
+	Name    string // Empty when null (v6+)
+	TopicID []byte // UUID, nil before v6 and zero when deleting by name
+}
+
+// ResponseDataDeleteTopics represents the data needed to build a DeleteTopics response
+type ResponseDataDeleteTopics struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds (v1+)
+	Responses      []DeletableTopicResult
+}
+
+// DeletableTopicResult is the outcome for one topic of the DeleteTopics request
+type DeletableTopicResult struct {
+	Name         string // Encoded as null when empty (v6+)
+	TopicID      []byte // UUID (v6+)
+	ErrorCode    int16
+	ErrorMessage string // Encoded as null when empty (v5+)
+}
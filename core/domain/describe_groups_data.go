@@ -0,0 +1,40 @@
+package domain
+
+type ParsedRequestDescribeGroups struct {
+	// Header fields
+	APIKey        int    // API Key (15 for DescribeGroups)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	Groups                      []string
+	IncludeAuthorizedOperations bool // v3+
+}
+
+// ResponseDataDescribeGroups represents the data needed to build a DescribeGroups response
+type ResponseDataDescribeGroups struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds
+	Groups         []DescribedGroup
+}
+
+type DescribedGroup struct {
+	ErrorCode            int16
+	GroupID              string
+	GroupState           string
+	ProtocolType         string
+	ProtocolData         string // The protocol of the generation once the group is stable
+	Members              []DescribedGroupMember
+	AuthorizedOperations int32 // v3+
+}
+
+type DescribedGroupMember struct {
+	MemberID         string
+	GroupInstanceID  string // v4+, encoded as null when empty
+	ClientID         string
+	ClientHost       string
+	MemberMetadata   []byte // Metadata for the protocol of the generation, empty unless stable
+	MemberAssignment []byte // Empty unless stable
+}
@@ -0,0 +1,47 @@
+package domain
+
+type ParsedRequestDescribeProducers struct {
+	// Header fields
+	APIKey        int    // API Key (61 for DescribeProducers)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	Topics []DescribeProducersTopic
+}
+
+type DescribeProducersTopic struct {
+	Name             string
+	PartitionIndexes []int32
+}
+
+// ResponseDataDescribeProducers represents the data needed to build a DescribeProducers response
+type ResponseDataDescribeProducers struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds
+	Topics         []DescribeProducersTopicResponse
+}
+
+type DescribeProducersTopicResponse struct {
+	Name       string
+	Partitions []DescribeProducersPartitionResponse
+}
+
+type DescribeProducersPartitionResponse struct {
+	PartitionIndex  int32
+	ErrorCode       int16
+	ErrorMessage    *string // Null without error
+	ActiveProducers []ProducerState
+}
+
+// ProducerState is what a partition keeps of a producer writing to it
+type ProducerState struct {
+	ProducerID            int64
+	ProducerEpoch         int32
+	LastSequence          int32 // -1 when the producer wrote no batch with a sequence
+	LastTimestamp         int64 // Max timestamp of the producer's last batch, -1 when unknown
+	CoordinatorEpoch      int32 // Epoch of the coordinator that wrote the last marker, -1 when unknown
+	CurrentTxnStartOffset int64 // First offset of the open transaction, -1 outside of a transaction
+}
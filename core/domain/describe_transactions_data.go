@@ -0,0 +1,36 @@
+package domain
+
+type ParsedRequestDescribeTransactions struct {
+	// Header fields
+	APIKey        int    // API Key (65 for DescribeTransactions)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	TransactionalIDs []string
+}
+
+// ResponseDataDescribeTransactions represents the data needed to build a DescribeTransactions response
+type ResponseDataDescribeTransactions struct {
+	CorrelationID     []byte // Correlation ID (4 bytes)
+	APIVersion        int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs    int32  // Throttle time in milliseconds
+	TransactionStates []DescribedTransaction
+}
+
+type DescribedTransaction struct {
+	ErrorCode              int16
+	TransactionalID        string
+	TransactionState       string
+	TransactionTimeoutMs   int32
+	TransactionStartTimeMs int64 // -1 outside of a transaction
+	ProducerID             int64
+	ProducerEpoch          int16
+	Topics                 []DescribedTransactionTopic // Partitions of the ongoing or ending transaction
+}
+
+type DescribedTransactionTopic struct {
+	Topic      string
+	Partitions []int32
+}
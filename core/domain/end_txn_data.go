@@ -0,0 +1,23 @@
+package domain
+
+type ParsedRequestEndTxn struct {
+	// Header fields
+	APIKey        int    // API Key (26 for EndTxn)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	TransactionalID string
+	ProducerID      int64
+	ProducerEpoch   int16
+	Committed       bool // Commits the transaction when set, aborts it otherwise
+}
+
+// ResponseDataEndTxn represents the data needed to build an EndTxn response
+type ResponseDataEndTxn struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds
+	ErrorCode      int16
+}
@@ -0,0 +1,53 @@
+package domain
+
+// Kafka protocol error codes (https://kafka.apache.org/protocol#protocol_error_codes)
+const (
+	ErrorCodeUnknownServerError        int16 = -1
+	ErrorCodeNone                      int16 = 0
+	ErrorCodeOffsetOutOfRange          int16 = 1
+	ErrorCodeCorruptMessage            int16 = 2
+	ErrorCodeUnknownTopicOrPartition   int16 = 3
+	ErrorCodeMessageTooLarge           int16 = 10
+	ErrorCodeOffsetMetadataTooLarge    int16 = 12
+	ErrorCodeCoordinatorNotAvailable   int16 = 15
+	ErrorCodeInvalidTopicException     int16 = 17
+	ErrorCodeIllegalGeneration         int16 = 22
+	ErrorCodeInconsistentGroupProtocol int16 = 23
+	ErrorCodeInvalidGroupID            int16 = 24
+	ErrorCodeUnknownMemberID           int16 = 25
+	ErrorCodeInvalidSessionTimeout     int16 = 26
+	ErrorCodeRebalanceInProgress       int16 = 27
+	ErrorCodeUnsupportedVersion        int16 = 35
+	ErrorCodeTopicAlreadyExists        int16 = 36
+	ErrorCodeInvalidPartitions         int16 = 37
+	ErrorCodeInvalidReplicationFactor  int16 = 38
+	ErrorCodeInvalidReplicaAssignment  int16 = 39
+	ErrorCodeInvalidConfig             int16 = 40
+	ErrorCodeInvalidRequest            int16 = 42
+	ErrorCodeOutOfOrderSequenceNumber  int16 = 45
+	ErrorCodeDuplicateSequenceNumber   int16 = 46
+	ErrorCodeInvalidProducerEpoch      int16 = 47
+	ErrorCodeInvalidTxnState           int16 = 48
+	ErrorCodeInvalidProducerIDMapping  int16 = 49
+	ErrorCodeInvalidTransactionTimeout int16 = 50
+	ErrorCodeConcurrentTransactions    int16 = 51
+	ErrorCodeOperationNotAttempted     int16 = 55
+	ErrorCodeKafkaStorageError         int16 = 56
+	ErrorCodeNonEmptyGroup             int16 = 68
+	ErrorCodeGroupIDNotFound           int16 = 69
+	ErrorCodeFetchSessionIDNotFound    int16 = 70
+	ErrorCodeInvalidFetchSessionEpoch  int16 = 71
+	ErrorCodeFencedLeaderEpoch         int16 = 74
+	ErrorCodeUnknownLeaderEpoch        int16 = 75
+	ErrorCodeMemberIDRequired          int16 = 79
+	ErrorCodeFencedInstanceID          int16 = 82
+	ErrorCodeGroupSubscribedToTopic    int16 = 86
+	ErrorCodeProducerFenced            int16 = 90
+	ErrorCodeUnknownTopicID            int16 = 100
+	ErrorCodeTransactionalIDNotFound   int16 = 105
+	ErrorCodeFencedMemberEpoch         int16 = 110
+	ErrorCodeUnreleasedInstanceID      int16 = 111
+	ErrorCodeUnsupportedAssignor       int16 = 112
+	ErrorCodeStaleMemberEpoch          int16 = 113
+	ErrorCodeInvalidRegularExpression  int16 = 128
+)
@@ -0,0 +1,16 @@
+package domain
+
+import "errors"
+
+// Errors returned by driven adapters that the core maps to Kafka error codes
+var (
+	ErrCorruptMessage           = errors.New("corrupt message")
+	ErrUnknownTopicOrPartition  = errors.New("unknown topic or partition")
+	ErrOffsetOutOfRange         = errors.New("offset out of range")
+	ErrTopicAlreadyExists       = errors.New("topic already exists")
+	ErrUnknownTopicID           = errors.New("unknown topic id")
+	ErrInvalidPartitions        = errors.New("invalid partitions")
+	ErrDuplicateSequenceNumber  = errors.New("duplicate sequence number")
+	ErrOutOfOrderSequenceNumber = errors.New("out of order sequence number")
+	ErrInvalidProducerEpoch     = errors.New("invalid producer epoch")
+)
@@ -8,6 +8,7 @@ type ParsedRequestFetch struct {
 	ClientID      string // Client ID string
 
 	// Body fields
+	ReplicaID       int32 // Replica ID, -1 for consumers (v0-v14)
 	MaxWaitMS       int32 // Maximum wait time in milliseconds (4 bytes INT32)
 	MinBytes        int32 // Minimum bytes to fetch (4 bytes INT32)
 	MaxBytes        int32 // Maximum bytes to fetch (4 bytes INT32)
@@ -45,6 +46,7 @@ type ForgottenTopic struct {
 // ResponseDataFetch represents the data needed to build a Fetch response
 type ResponseDataFetch struct {
 	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version of the request being answered
 	ThrottleTimeMs int32  // Throttle time in milliseconds (4 bytes INT32)
 	ErrorCode      int16  // Error code (2 bytes INT16)
 	SessionID      int32  // Session ID (4 bytes INT32)
@@ -76,3 +78,29 @@ type AbortedTransaction struct {
 	ProducerID  int64 // Producer ID (8 bytes INT64)
 	FirstOffset int64 // First offset (8 bytes INT64)
 }
+
+// Isolation levels of Fetch and ListOffsets requests
+const (
+	IsolationLevelReadUncommitted int8 = 0
+	IsolationLevelReadCommitted   int8 = 1 // Only records of decided transactions
+)
+
+// ReadRequest asks the partition log for the record batches starting at FetchOffset
+type ReadRequest struct {
+	TopicName      string
+	PartitionIndex int
+	FetchOffset    int64
+	MaxBytes       int32 // Byte budget for whole batches
+	MinOneBatch    bool  // Return the first batch even if it exceeds MaxBytes
+	IsolationLevel int8  // IsolationLevelReadCommitted stops at the last stable offset
+}
+
+// ReadResult carries the batches read from a partition log and the log's current bounds
+type ReadResult struct {
+	Records          []byte
+	LogStartOffset   int64
+	LastStableOffset int64
+	HighWatermark    int64
+	// AbortedTransactions overlapping Records, only listed when reading committed data
+	AbortedTransactions []AbortedTransaction
+}
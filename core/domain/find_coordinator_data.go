@@ -0,0 +1,38 @@
+package domain
+
+// Coordinator key types of a FindCoordinator request
+const (
+	CoordinatorKeyTypeGroup       int8 = 0
+	CoordinatorKeyTypeTransaction int8 = 1
+)
+
+type ParsedRequestFindCoordinator struct {
+	// Header fields
+	APIKey        int    // API Key (10 for FindCoordinator)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	KeyType         int8     // What the keys are, see CoordinatorKeyTypeGroup
+	CoordinatorKeys []string // Group or transactional IDs; v0-3 carry a single key
+}
+
+// ResponseDataFindCoordinator represents the data needed to build a FindCoordinator response
+type ResponseDataFindCoordinator struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds
+	Coordinators   []Coordinator
+}
+
+// Coordinator is the broker coordinating a key. Versions before 4 answer for a single
+// key and encode its coordinator as the top level fields.
+type Coordinator struct {
+	Key          string
+	NodeID       int32
+	Host         string
+	Port         int32
+	ErrorCode    int16
+	ErrorMessage string // Encoded as null when empty
+}
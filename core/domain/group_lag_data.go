@@ -0,0 +1,9 @@
+package domain
+
+// PartitionLag is how far a group is behind on a partition it committed an offset for
+type PartitionLag struct {
+	TopicPartition
+	CommittedOffset int64
+	HighWatermark   int64 // -1 when the partition is not in the log anymore
+	Lag             int64 // -1 when the high watermark is unknown
+}
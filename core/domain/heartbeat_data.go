@@ -0,0 +1,23 @@
+package domain
+
+type ParsedRequestHeartbeat struct {
+	// Header fields
+	APIKey        int    // API Key (12 for Heartbeat)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	GroupID         string
+	GenerationID    int32
+	MemberID        string
+	GroupInstanceID string // Empty (null) for dynamic members
+}
+
+// ResponseDataHeartbeat represents the data needed to build a Heartbeat response
+type ResponseDataHeartbeat struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds
+	ErrorCode      int16
+}
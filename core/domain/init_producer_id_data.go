@@ -0,0 +1,36 @@
+package domain
+
+type ParsedRequestInitProducerId struct {
+	// Header fields
+	APIKey        int    // API Key (22 for InitProducerId)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	TransactionalID      string // Empty (null) for producers that are only idempotent
+	TransactionTimeoutMs int32
+	ProducerID           int64 // Producer ID to bump the epoch of, -1 for a new producer (v3+)
+	ProducerEpoch        int16 // Current epoch of ProducerID, -1 for a new producer (v3+)
+}
+
+// ResponseDataInitProducerId represents the data needed to build an InitProducerId response
+type ResponseDataInitProducerId struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds
+	ErrorCode      int16
+	ProducerID     int64
+	ProducerEpoch  int16
+}
+
+// ProducerIDBlock is a range of producer IDs the controller handed to a broker
+type ProducerIDBlock struct {
+	FirstProducerID int64
+	Size            int64
+}
+
+// NextBlockFirstProducerID returns the first producer ID after the block
+func (b ProducerIDBlock) NextBlockFirstProducerID() int64 {
+	return b.FirstProducerID + b.Size
+}
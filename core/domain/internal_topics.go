@@ -0,0 +1,8 @@
+package domain
+
+// Internal topics the coordinators keep their state in. They are plain partition logs,
+// not part of the cluster metadata.
+const (
+	ConsumerOffsetsTopic  = "__consumer_offsets"
+	TransactionStateTopic = "__transaction_state"
+)
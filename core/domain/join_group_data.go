@@ -0,0 +1,47 @@
+package domain
+
+type ParsedRequestJoinGroup struct {
+	// Header fields
+	APIKey        int    // API Key (11 for JoinGroup)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	GroupID            string
+	SessionTimeoutMs   int32
+	RebalanceTimeoutMs int32  // -1 before v1, where the session timeout is used instead
+	MemberID           string // Empty for a member joining for the first time
+	GroupInstanceID    string // Empty (null) for dynamic members
+	ProtocolType       string // "consumer" for consumer groups
+	Protocols          []JoinGroupProtocol
+	Reason             string
+}
+
+// JoinGroupProtocol is one of the assignors a member supports, most preferred first
+type JoinGroupProtocol struct {
+	Name     string
+	Metadata []byte
+}
+
+// ResponseDataJoinGroup represents the data needed to build a JoinGroup response
+type ResponseDataJoinGroup struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds
+	ErrorCode      int16
+	GenerationID   int32
+	ProtocolType   string // Encoded as null when empty
+	ProtocolName   string // Encoded as null when empty from v7 on
+	Leader         string
+	SkipAssignment bool // The leader must not compute a new assignment
+	MemberID       string
+	Members        []JoinGroupResponseMember // Only sent to the leader
+}
+
+// JoinGroupResponseMember is a member the leader computes the assignment for
+type JoinGroupResponseMember struct {
+	MemberID        string
+	GroupInstanceID string // Encoded as null when empty
+	Metadata        []byte // The member's metadata for the selected protocol
+}
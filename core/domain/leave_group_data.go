@@ -0,0 +1,37 @@
+package domain
+
+type ParsedRequestLeaveGroup struct {
+	// Header fields
+	APIKey        int    // API Key (13 for LeaveGroup)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	GroupID string
+	Members []LeaveGroupMember // v0-2 carry a single member ID
+}
+
+// LeaveGroupMember identifies a member leaving the group by member ID, or by
+// GroupInstanceID for static members
+type LeaveGroupMember struct {
+	MemberID        string
+	GroupInstanceID string // Empty (null) for dynamic members
+	Reason          string
+}
+
+// ResponseDataLeaveGroup represents the data needed to build a LeaveGroup response
+type ResponseDataLeaveGroup struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds
+	ErrorCode      int16
+	Members        []LeaveGroupMemberResult // Sent from v3 on
+}
+
+// LeaveGroupMemberResult is the outcome for one member of the LeaveGroup request
+type LeaveGroupMemberResult struct {
+	MemberID        string
+	GroupInstanceID string // Encoded as null when empty
+	ErrorCode       int16
+}
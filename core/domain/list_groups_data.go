@@ -0,0 +1,29 @@
+package domain
+
+type ParsedRequestListGroups struct {
+	// Header fields
+	APIKey        int    // API Key (16 for ListGroups)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields. An empty filter lists every group.
+	StatesFilter []string // v4+
+	TypesFilter  []string // v5+
+}
+
+// ResponseDataListGroups represents the data needed to build a ListGroups response
+type ResponseDataListGroups struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds
+	ErrorCode      int16
+	Groups         []ListedGroup
+}
+
+type ListedGroup struct {
+	GroupID      string
+	ProtocolType string
+	GroupState   string // v4+
+	GroupType    string // v5+, "classic" or "consumer"
+}
@@ -0,0 +1,74 @@
+package domain
+
+// Special ListOffsets timestamps
+const (
+	ListOffsetsLatestTimestamp        int64 = -1 // Offset of the next record to be appended
+	ListOffsetsEarliestTimestamp      int64 = -2 // Log start offset
+	ListOffsetsMaxTimestamp           int64 = -3 // Offset of the record with the largest timestamp
+	ListOffsetsEarliestLocalTimestamp int64 = -4 // Log start offset of the local log
+)
+
+type ParsedRequestListOffsets struct {
+	// Header fields
+	APIKey        int    // API Key (2 for ListOffsets)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	ReplicaID      int32 // Broker ID of the follower, -1 for consumers
+	IsolationLevel int8  // 0 = read_uncommitted, 1 = read_committed (v2+)
+	Topics         []ListOffsetsTopic
+}
+
+// ListOffsetsTopic represents a topic in the ListOffsets request
+type ListOffsetsTopic struct {
+	Name       string
+	Partitions []ListOffsetsPartition
+}
+
+// ListOffsetsPartition represents a partition in the ListOffsets request
+type ListOffsetsPartition struct {
+	PartitionIndex     int32
+	CurrentLeaderEpoch int32 // -1 when the client does not know the leader epoch (v4+)
+	Timestamp          int64 // Target timestamp or one of the special ListOffsets timestamps
+	MaxNumOffsets      int32 // v0 only
+}
+
+// ResponseDataListOffsets represents the data needed to build a ListOffsets response
+type ResponseDataListOffsets struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds (v2+)
+	Topics         []ListOffsetsResponseTopic
+}
+
+// ListOffsetsResponseTopic represents a topic in the ListOffsets response
+type ListOffsetsResponseTopic struct {
+	Name       string
+	Partitions []ListOffsetsResponsePartition
+}
+
+// ListOffsetsResponsePartition represents a partition in the ListOffsets response
+type ListOffsetsResponsePartition struct {
+	PartitionIndex int32
+	ErrorCode      int16
+	Timestamp      int64 // Timestamp of the returned offset, -1 when not applicable
+	Offset         int64 // Returned offset, -1 when no record matches
+	LeaderEpoch    int32 // Leader epoch of the returned offset (v4+)
+}
+
+// LogOffsets are the bounds of a partition log
+type LogOffsets struct {
+	LogStartOffset   int64 // First offset still present in the log
+	LastStableOffset int64 // First offset of the oldest open transaction, or the high watermark
+	HighWatermark    int64 // Offset of the next record to be appended
+}
+
+// OffsetLookupResult is the record found by a timestamp based offset lookup
+type OffsetLookupResult struct {
+	Found       bool
+	Offset      int64
+	Timestamp   int64
+	LeaderEpoch int32
+}
@@ -0,0 +1,30 @@
+package domain
+
+type ParsedRequestListTransactions struct {
+	// Header fields
+	APIKey        int    // API Key (66 for ListTransactions)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields. Empty filters list every transactional ID.
+	StateFilters      []string
+	ProducerIDFilters []int64
+	DurationFilter    int64 // Only transactions running longer than this many ms, -1 for all (v1+)
+}
+
+// ResponseDataListTransactions represents the data needed to build a ListTransactions response
+type ResponseDataListTransactions struct {
+	CorrelationID       []byte // Correlation ID (4 bytes)
+	APIVersion          int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs      int32  // Throttle time in milliseconds
+	ErrorCode           int16
+	UnknownStateFilters []string // States of StateFilters no transaction can be in
+	TransactionStates   []ListedTransaction
+}
+
+type ListedTransaction struct {
+	TransactionalID  string
+	ProducerID       int64
+	TransactionState string
+}
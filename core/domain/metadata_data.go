@@ -0,0 +1,55 @@
+package domain
+
+type ParsedRequestMetadata struct {
+	// Header fields
+	APIKey        int    // API Key (3 for Metadata)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	AllTopics                          bool // True when the client asked for every topic
+	Topics                             []MetadataRequestTopic
+	AllowAutoTopicCreation             bool // v4+
+	IncludeClusterAuthorizedOperations bool // v8-v10
+	IncludeTopicAuthorizedOperations   bool // v8+
+}
+
+// MetadataRequestTopic identifies a topic by name, or by ID from v10 on
+type MetadataRequestTopic struct {
+	TopicID []byte // 16 byte UUID, all zeroes when the topic is requested by name
+	Name    string
+}
+
+// ResponseDataMetadata represents the data needed to build a Metadata response
+type ResponseDataMetadata struct {
+	CorrelationID               []byte // Correlation ID (4 bytes)
+	APIVersion                  int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs              int32  // Throttle time in milliseconds (v3+)
+	Brokers                     []Broker
+	ClusterID                   string // Cluster ID, encoded as null when empty (v2+)
+	ControllerID                int32  // ID of the controller broker (v1+)
+	Topics                      []MetadataResponseTopic
+	ClusterAuthorizedOperations int32 // v8-v10
+}
+
+// MetadataResponseTopic represents a topic in the Metadata response
+type MetadataResponseTopic struct {
+	ErrorCode                 int16
+	Name                      string
+	TopicID                   []byte // 16 byte UUID (v10+)
+	IsInternal                bool   // v1+
+	Partitions                []MetadataResponsePartition
+	TopicAuthorizedOperations int32 // v8+
+}
+
+// MetadataResponsePartition represents a partition in the Metadata response
+type MetadataResponsePartition struct {
+	ErrorCode       int16
+	PartitionIndex  int32
+	LeaderID        int32
+	LeaderEpoch     int32 // v7+
+	ReplicaNodes    []int32
+	IsrNodes        []int32
+	OfflineReplicas []int32 // v5+
+}
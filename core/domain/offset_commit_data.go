@@ -0,0 +1,72 @@
+package domain
+
+type ParsedRequestOffsetCommit struct {
+	// Header fields
+	APIKey        int    // API Key (8 for OffsetCommit)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	GroupID         string
+	GenerationID    int32  // -1 before v1 and for commits made outside of a generation
+	MemberID        string // Empty before v1
+	GroupInstanceID string // v7+, empty (null) for dynamic members
+	RetentionTimeMs int64  // v2-v4, -1 to use the broker's retention
+	Topics          []OffsetCommitTopic
+}
+
+type OffsetCommitTopic struct {
+	Name       string
+	Partitions []OffsetCommitPartition
+}
+
+type OffsetCommitPartition struct {
+	PartitionIndex       int32
+	CommittedOffset      int64
+	CommittedLeaderEpoch int32  // v6+, -1 when unknown
+	CommitTimestamp      int64  // v1 only, -1 to use the broker's time
+	CommittedMetadata    string // Empty (null) when not set
+}
+
+// ResponseDataOffsetCommit represents the data needed to build an OffsetCommit response
+type ResponseDataOffsetCommit struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds, v3+
+	Topics         []OffsetCommitResponseTopic
+}
+
+type OffsetCommitResponseTopic struct {
+	Name       string
+	Partitions []OffsetCommitResponsePartition
+}
+
+type OffsetCommitResponsePartition struct {
+	PartitionIndex int32
+	ErrorCode      int16
+}
+
+// TopicPartition names a partition of a topic
+type TopicPartition struct {
+	Topic     string
+	Partition int32
+}
+
+// CommittedOffset is the offset a group committed for a partition, as kept in the
+// __consumer_offsets topic
+type CommittedOffset struct {
+	Offset          int64
+	LeaderEpoch     int32 // -1 when unknown
+	Metadata        string
+	CommitTimestamp int64 // Milliseconds since the epoch
+	ExpireTimestamp int64 // -1 unless the commit asked for its own retention
+}
+
+// LoadedOffsets is what replaying the __consumer_offsets topic gives back
+type LoadedOffsets struct {
+	Committed map[string]map[TopicPartition]CommittedOffset // By group
+	// Pending are the offsets of transactions without a marker yet, by producer ID and
+	// group
+	Pending map[int64]map[string]map[TopicPartition]CommittedOffset
+}
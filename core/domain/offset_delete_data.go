@@ -0,0 +1,37 @@
+package domain
+
+type ParsedRequestOffsetDelete struct {
+	// Header fields
+	APIKey        int    // API Key (47 for OffsetDelete)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	GroupID string
+	Topics  []OffsetDeleteTopic
+}
+
+type OffsetDeleteTopic struct {
+	Name       string
+	Partitions []int32
+}
+
+// ResponseDataOffsetDelete represents the data needed to build an OffsetDelete response
+type ResponseDataOffsetDelete struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ErrorCode      int16
+	ThrottleTimeMs int32 // Throttle time in milliseconds
+	Topics         []OffsetDeleteResponseTopic
+}
+
+type OffsetDeleteResponseTopic struct {
+	Name       string
+	Partitions []OffsetDeleteResponsePartition
+}
+
+type OffsetDeleteResponsePartition struct {
+	PartitionIndex int32
+	ErrorCode      int16
+}
@@ -0,0 +1,54 @@
+package domain
+
+type ParsedRequestOffsetFetch struct {
+	// Header fields
+	APIKey        int    // API Key (9 for OffsetFetch)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields. Before v8 the request names a single group, which is parsed into
+	// the only entry of Groups.
+	Groups        []OffsetFetchGroup
+	RequireStable bool // v7+
+}
+
+type OffsetFetchGroup struct {
+	GroupID     string
+	MemberID    string             // v9+, empty (null) when not fetching as a member
+	MemberEpoch int32              // v9+, -1 when not fetching as a member
+	Topics      []OffsetFetchTopic // Nil (null, v2+) fetches every committed offset of the group
+}
+
+type OffsetFetchTopic struct {
+	Name             string
+	PartitionIndexes []int32
+}
+
+// ResponseDataOffsetFetch represents the data needed to build an OffsetFetch response.
+// Before v8 the response is encoded from the only entry of Groups.
+type ResponseDataOffsetFetch struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds, v3+
+	Groups         []OffsetFetchResponseGroup
+}
+
+type OffsetFetchResponseGroup struct {
+	GroupID   string
+	Topics    []OffsetFetchResponseTopic
+	ErrorCode int16 // v2+
+}
+
+type OffsetFetchResponseTopic struct {
+	Name       string
+	Partitions []OffsetFetchResponsePartition
+}
+
+type OffsetFetchResponsePartition struct {
+	PartitionIndex       int32
+	CommittedOffset      int64 // -1 when the group has no committed offset
+	CommittedLeaderEpoch int32 // v5+, -1 when unknown
+	Metadata             string
+	ErrorCode            int16
+}
@@ -1,11 +0,0 @@
-package domain
-
-type PartitionToFetch struct {
-	TopicName          string
-	PartitionIndex     int
-	TopicFetchResponse *FetchResponsePartition
-}
-
-type MessageFetchRequest struct {
-	PartitionsToFetch []PartitionToFetch
-}
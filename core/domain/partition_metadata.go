@@ -5,6 +5,7 @@ type PartitionMetadata struct {
 	PartitionIndex                    []byte // The index of the partition in PartitionsArray     x
 	LeaderId                          []byte // 4 byte id of the leader for this partition        x
 	LeaderEpoch                       []byte // 4 byte representing the epoch of the leader       x
+	PartitionEpoch                    []byte // 4 byte epoch bumped on every partition change
 	ReplicaNodes                             //												   x
 	IsrNodes                                 //                                                   x
 	EligibleLeaderReplicasArrayLength []byte //  							   x
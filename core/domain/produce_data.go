@@ -0,0 +1,66 @@
+package domain
+
+type ParsedRequestProduce struct {
+	// Header fields
+	APIKey        int    // API Key (0 for Produce)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	TransactionalID string // Transactional ID, empty when null (v3+)
+	Acks            int16  // Number of acknowledgments required (0, 1 or -1)
+	TimeoutMS       int32  // Timeout to await a response in milliseconds
+	Topics          []ProduceTopic
+}
+
+// ProduceTopic represents a topic in the Produce request
+type ProduceTopic struct {
+	Name       string
+	Partitions []ProducePartition
+}
+
+// ProducePartition represents a partition in the Produce request
+type ProducePartition struct {
+	PartitionIndex int32
+	Records        []byte // One or more RecordBatches
+}
+
+// ResponseDataProduce represents the data needed to build a Produce response
+type ResponseDataProduce struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds (4 bytes INT32)
+	Topics         []ProduceResponseTopic
+}
+
+// ProduceResponseTopic represents a topic in the Produce response
+type ProduceResponseTopic struct {
+	Name       string
+	Partitions []ProduceResponsePartition
+}
+
+// ProduceResponsePartition represents a partition in the Produce response
+type ProduceResponsePartition struct {
+	PartitionIndex  int32  // Partition index (4 bytes INT32)
+	ErrorCode       int16  // Error code (2 bytes INT16)
+	BaseOffset      int64  // Offset assigned to the first record (8 bytes INT64)
+	LogAppendTimeMs int64  // Log append time, -1 when the topic uses CreateTime (8 bytes INT64)
+	LogStartOffset  int64  // Log start offset (8 bytes INT64)
+	ErrorMessage    string // Error message, encoded as null when empty
+}
+
+// AppendRequest asks the partition log to append the RecordBatches of one partition
+type AppendRequest struct {
+	TopicName      string
+	PartitionIndex int
+	LeaderEpoch    int32
+	Records        []byte
+}
+
+// AppendResult describes where the appended RecordBatches were written
+type AppendResult struct {
+	BaseOffset      int64
+	LogAppendTimeMs int64
+	LogStartOffset  int64
+}
@@ -1,7 +1,14 @@
 package domain
 
+// AnonymousPrincipal is the principal of clients that did not authenticate, as Kafka
+// names it
+const AnonymousPrincipal = "User:ANONYMOUS"
+
 // Request represents an incoming Kafka request
 type Request struct {
 	Data []byte
+	// Principal is the client the request comes from: "User:" followed by the subject
+	// of its verified TLS client certificate, or AnonymousPrincipal
+	Principal string
 }
 
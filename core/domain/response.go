@@ -3,5 +3,9 @@ package domain
 // Response represents an outgoing Kafka response
 type Response struct {
 	Data []byte
+
+	// Deferred is set instead of Data by handlers that answer later, such as a Fetch
+	// waiting for MinBytes. It delivers exactly one Response.
+	Deferred <-chan Response
 }
 
@@ -0,0 +1,35 @@
+package domain
+
+type ParsedRequestSyncGroup struct {
+	// Header fields
+	APIKey        int    // API Key (14 for SyncGroup)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	GroupID         string
+	GenerationID    int32
+	MemberID        string
+	GroupInstanceID string                // Empty (null) for dynamic members
+	ProtocolType    string                // Empty (null) when not checked
+	ProtocolName    string                // Empty (null) when not checked
+	Assignments     []SyncGroupAssignment // Only sent by the leader
+}
+
+// SyncGroupAssignment is the assignment the leader computed for a member
+type SyncGroupAssignment struct {
+	MemberID   string
+	Assignment []byte
+}
+
+// ResponseDataSyncGroup represents the data needed to build a SyncGroup response
+type ResponseDataSyncGroup struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds
+	ErrorCode      int16
+	ProtocolType   string // Encoded as null when empty
+	ProtocolName   string // Encoded as null when empty
+	Assignment     []byte
+}
@@ -0,0 +1,76 @@
+package domain
+
+import "strings"
+
+// TransactionState is the state of a transactional ID in the transaction coordinator,
+// numbered like Kafka's TransactionState so it can be written to __transaction_state
+type TransactionState int8
+
+const (
+	TransactionStateEmpty TransactionState = iota
+	TransactionStateOngoing
+	TransactionStatePrepareCommit
+	TransactionStatePrepareAbort
+	TransactionStateCompleteCommit
+	TransactionStateCompleteAbort
+	TransactionStateDead
+	TransactionStatePrepareEpochFence
+)
+
+// String returns the name Kafka gives the state
+func (s TransactionState) String() string {
+	switch s {
+	case TransactionStateEmpty:
+		return "Empty"
+	case TransactionStateOngoing:
+		return "Ongoing"
+	case TransactionStatePrepareCommit:
+		return "PrepareCommit"
+	case TransactionStatePrepareAbort:
+		return "PrepareAbort"
+	case TransactionStateCompleteCommit:
+		return "CompleteCommit"
+	case TransactionStateCompleteAbort:
+		return "CompleteAbort"
+	case TransactionStateDead:
+		return "Dead"
+	case TransactionStatePrepareEpochFence:
+		return "PrepareEpochFence"
+	}
+	return "Unknown"
+}
+
+// ParseTransactionState returns the state Kafka names name, ignoring case
+func ParseTransactionState(name string) (TransactionState, bool) {
+	for state := TransactionStateEmpty; state <= TransactionStatePrepareEpochFence; state++ {
+		if strings.EqualFold(state.String(), name) {
+			return state, true
+		}
+	}
+	return 0, false
+}
+
+// TransactionMetadata is what the transaction coordinator keeps of a transactional ID, as
+// written to the __transaction_state topic
+type TransactionMetadata struct {
+	TransactionalID     string
+	ProducerID          int64
+	ProducerEpoch       int16
+	TimeoutMs           int32
+	State               TransactionState
+	Partitions          []TopicPartition // Partitions of the ongoing or ending transaction
+	StartTimestamp      int64            // Milliseconds since the epoch, -1 outside of a transaction
+	LastUpdateTimestamp int64            // Milliseconds since the epoch
+}
+
+// TxnMarkerAppend asks a partition log to append the control batch ending a producer's
+// transaction
+type TxnMarkerAppend struct {
+	TopicName        string
+	PartitionIndex   int
+	LeaderEpoch      int32
+	ProducerID       int64
+	ProducerEpoch    int16
+	CoordinatorEpoch int32
+	Committed        bool // Commit marker when set, abort marker otherwise
+}
@@ -0,0 +1,49 @@
+package domain
+
+type ParsedRequestTxnOffsetCommit struct {
+	// Header fields
+	APIKey        int    // API Key (28 for TxnOffsetCommit)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	TransactionalID string
+	GroupID         string
+	ProducerID      int64
+	ProducerEpoch   int16
+	GenerationID    int32  // v3+, -1 when not committing as a member
+	MemberID        string // v3+, empty when not committing as a member
+	GroupInstanceID string // v3+, empty (null) for dynamic members
+	Topics          []TxnOffsetCommitTopic
+}
+
+type TxnOffsetCommitTopic struct {
+	Name       string
+	Partitions []TxnOffsetCommitPartition
+}
+
+type TxnOffsetCommitPartition struct {
+	PartitionIndex       int32
+	CommittedOffset      int64
+	CommittedLeaderEpoch int32  // v2+, -1 when unknown
+	CommittedMetadata    string // Empty (null) when not set
+}
+
+// ResponseDataTxnOffsetCommit represents the data needed to build a TxnOffsetCommit response
+type ResponseDataTxnOffsetCommit struct {
+	CorrelationID  []byte // Correlation ID (4 bytes)
+	APIVersion     int    // Version the response is encoded with, same as the request
+	ThrottleTimeMs int32  // Throttle time in milliseconds
+	Topics         []TxnOffsetCommitResponseTopic
+}
+
+type TxnOffsetCommitResponseTopic struct {
+	Name       string
+	Partitions []TxnOffsetCommitResponsePartition
+}
+
+type TxnOffsetCommitResponsePartition struct {
+	PartitionIndex int32
+	ErrorCode      int16
+}
@@ -0,0 +1,47 @@
+package domain
+
+type ParsedRequestWriteTxnMarkers struct {
+	// Header fields
+	APIKey        int    // API Key (27 for WriteTxnMarkers)
+	APIVersion    int    // API Version
+	CorrelationID []byte // Correlation ID (4 bytes)
+	ClientID      string // Client ID string
+
+	// Body fields
+	Markers []WritableTxnMarker
+}
+
+type WritableTxnMarker struct {
+	ProducerID        int64
+	ProducerEpoch     int16
+	TransactionResult bool // Commit when set, abort otherwise
+	Topics            []WritableTxnMarkerTopic
+	CoordinatorEpoch  int32
+}
+
+type WritableTxnMarkerTopic struct {
+	Name             string
+	PartitionIndexes []int32
+}
+
+// ResponseDataWriteTxnMarkers represents the data needed to build a WriteTxnMarkers response
+type ResponseDataWriteTxnMarkers struct {
+	CorrelationID []byte // Correlation ID (4 bytes)
+	APIVersion    int    // Version the response is encoded with, same as the request
+	Markers       []WritableTxnMarkerResult
+}
+
+type WritableTxnMarkerResult struct {
+	ProducerID int64
+	Topics     []WritableTxnMarkerTopicResult
+}
+
+type WritableTxnMarkerTopicResult struct {
+	Name       string
+	Partitions []WritableTxnMarkerPartitionResult
+}
+
+type WritableTxnMarkerPartitionResult struct {
+	PartitionIndex int32
+	ErrorCode      int16
+}
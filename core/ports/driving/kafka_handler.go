@@ -8,4 +8,3 @@ import "github.com/codecrafters-io/kafka-starter-go/core/domain"
 type KafkaHandler interface {
 	HandleRequest(req domain.Request) (domain.Response, error)
 }
-
@@ -0,0 +1,13 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+)
+
+type AddOffsetsToTxnParser interface {
+	// ParseRequest extracts structured data from raw binary request data
+	ParseRequest(data []byte) (*domain.ParsedRequestAddOffsetsToTxn, error)
+
+	// EncodeResponse converts a response into binary format
+	EncodeResponse(response *domain.ResponseDataAddOffsetsToTxn) ([]byte, error)
+}
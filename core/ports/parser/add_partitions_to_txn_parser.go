@@ -0,0 +1,13 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+)
+
+type AddPartitionsToTxnParser interface {
+	// ParseRequest extracts structured data from raw binary request data
+	ParseRequest(data []byte) (*domain.ParsedRequestAddPartitionsToTxn, error)
+
+	// EncodeResponse converts a response into binary format
+	EncodeResponse(response *domain.ResponseDataAddPartitionsToTxn) ([]byte, error)
+}
@@ -1,6 +1,8 @@
 package cluster_metadata_repository
 
 import (
+	"encoding/binary"
+
 	"github.com/codecrafters-io/kafka-starter-go/core/domain"
 	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
 )
@@ -9,10 +11,31 @@ type ClusterMetadataRepository interface {
 	GetClusterMetadata() (ClusterMetadataRepositoryResponse, error)
 }
 
+// ClusterMetadataWriter appends changes to the cluster metadata log. A change is part of
+// the image GetClusterMetadata returns once the call that made it returns.
+type ClusterMetadataWriter interface {
+	// CreateTopic adds a topic with its partitions and configs, failing with
+	// domain.ErrTopicAlreadyExists when a topic with the same name exists
+	CreateTopic(topic domain.NewTopic) error
+	// DeleteTopic removes the topic with the ID, failing with domain.ErrUnknownTopicID
+	// when there is none
+	DeleteTopic(topicID []byte) error
+	// CreatePartitions adds partitions to the topic with the ID, failing with
+	// domain.ErrUnknownTopicID when there is none and with domain.ErrInvalidPartitions
+	// when they don't directly follow its current partitions
+	CreatePartitions(topicID []byte, partitions []domain.NewPartition) error
+	// AllocateProducerIds hands the broker the next block of blockSize producer IDs
+	AllocateProducerIds(brokerID int32, blockSize int64) (domain.ProducerIDBlock, error)
+}
+
 type ClusterMetadataRepositoryResponse struct {
 	TopicUUIDTopicMetadataInfoMap map[string]*TopicMetadataInfo
 	TopicUUIDPartitionMetadataMap map[string][]*domain.PartitionMetadata
 	TopicNameTopicUuidMap         map[string]string
+	Brokers                       map[int32]*domain.BrokerRegistration
+	Configs                       map[domain.ConfigResource]map[string]string
+	FeatureLevels                 map[string]int16 // Finalized feature versions, such as metadata.version
+	NextProducerID                int64            // First producer ID not yet handed out to a broker
 	RecordsLength                 int
 	StartingRecordOffset          int
 }
@@ -26,3 +49,24 @@ type TopicMetadataInfo struct {
 	TagBuffer                 []byte //A single byte value 0x00                  // x
 
 }
+
+// FindPartition returns the metadata for a topic partition, or nil if the topic or
+// partition does not exist
+func (r ClusterMetadataRepositoryResponse) FindPartition(topicName string, partitionIndex int32) *domain.PartitionMetadata {
+	topicUuid, exists := r.TopicNameTopicUuidMap[topicName]
+	if !exists {
+		return nil
+	}
+	return r.FindPartitionByTopicUUID(topicUuid, partitionIndex)
+}
+
+// FindPartitionByTopicUUID returns the metadata for a partition of the topic with the
+// hex encoded UUID, or nil if the topic or partition does not exist
+func (r ClusterMetadataRepositoryResponse) FindPartitionByTopicUUID(topicUuid string, partitionIndex int32) *domain.PartitionMetadata {
+	for _, partitionMetadata := range r.TopicUUIDPartitionMetadataMap[topicUuid] {
+		if int32(binary.BigEndian.Uint32(partitionMetadata.PartitionIndex)) == partitionIndex {
+			return partitionMetadata
+		}
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package consumer_offsets
+
+import "github.com/codecrafters-io/kafka-starter-go/core/domain"
+
+// ConsumerOffsetsRepository keeps the offsets committed by groups in the internal
+// __consumer_offsets topic
+type ConsumerOffsetsRepository interface {
+	// PartitionFor returns the partition of the topic the offsets of a group are kept in
+	PartitionFor(groupID string) int
+	// StoreOffsets appends the offsets of a group as one batch; once it returns they
+	// survive a restart
+	StoreOffsets(groupID string, offsets map[domain.TopicPartition]domain.CommittedOffset) error
+	// StoreTransactionalOffsets appends the offsets a producer commits for a group within
+	// its transaction; they only take effect once the marker committing it is written
+	StoreTransactionalOffsets(groupID string, producerID int64, producerEpoch int16, offsets map[domain.TopicPartition]domain.CommittedOffset) error
+	// DeleteOffsets appends tombstones removing the committed offsets of the partitions
+	DeleteOffsets(groupID string, partitions []domain.TopicPartition) error
+	// LoadOffsets replays the topic and returns the offsets every group has committed,
+	// along with those of transactions that have not ended
+	LoadOffsets() (domain.LoadedOffsets, error)
+}
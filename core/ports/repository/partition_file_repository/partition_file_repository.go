@@ -3,5 +3,33 @@ package partition_file_repository
 import "github.com/codecrafters-io/kafka-starter-go/core/domain"
 
 type PartitionFileRepository interface {
-	GetPartitionMessage(messageFetchRequest domain.MessageFetchRequest)
+	// CreatePartition creates the directory of a new partition, recording its topic ID
+	CreatePartition(topicName string, partitionIndex int, topicID []byte) error
+	// DeletePartition takes the partition directory out of use right away; its files
+	// are removed in the background
+	DeletePartition(topicName string, partitionIndex int) error
+	// AppendRecordBatches appends the batches unless one of an idempotent producer is out
+	// of sequence or fenced. A batch already appended fails with
+	// domain.ErrDuplicateSequenceNumber and the AppendResult of the first append.
+	AppendRecordBatches(appendRequest domain.AppendRequest) (domain.AppendResult, error)
+	// WriteTxnMarker appends the control batch ending a producer's transaction in the
+	// partition, failing with domain.ErrInvalidProducerEpoch for a fenced epoch
+	WriteTxnMarker(marker domain.TxnMarkerAppend) (domain.AppendResult, error)
+	// ReadRecordBatches returns whole batches from the one containing the fetch offset,
+	// failing with domain.ErrOffsetOutOfRange when the offset is outside the log
+	ReadRecordBatches(readRequest domain.ReadRequest) (domain.ReadResult, error)
+	// GetLogOffsets returns the log start offset and high watermark of a partition
+	GetLogOffsets(topicName string, partitionIndex int) (domain.LogOffsets, error)
+	// FindOffsetByTimestamp returns the first record whose timestamp is at least timestamp
+	FindOffsetByTimestamp(topicName string, partitionIndex int, timestamp int64) (domain.OffsetLookupResult, error)
+	// FindOffsetOfMaxTimestamp returns the record with the largest timestamp in the partition
+	FindOffsetOfMaxTimestamp(topicName string, partitionIndex int) (domain.OffsetLookupResult, error)
+	// DescribeProducers returns the state of the producers that wrote to the partition,
+	// ordered by producer ID
+	DescribeProducers(topicName string, partitionIndex int) ([]domain.ProducerState, error)
+}
+
+// AppendListener is notified after records have been appended to a partition log
+type AppendListener interface {
+	OnAppend(topicName string, partitionIndex int)
 }
@@ -0,0 +1,14 @@
+package transaction_state
+
+import "github.com/codecrafters-io/kafka-starter-go/core/domain"
+
+// TransactionStateRepository keeps the state of the transactional IDs in the internal
+// __transaction_state topic
+type TransactionStateRepository interface {
+	// StoreTransaction appends the state of a transactional ID; once it returns it
+	// survives a restart
+	StoreTransaction(txn domain.TransactionMetadata) error
+	// LoadTransactions replays the topic and returns the last state of every
+	// transactional ID
+	LoadTransactions() (map[string]domain.TransactionMetadata, error)
+}
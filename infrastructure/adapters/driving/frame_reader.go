@@ -0,0 +1,72 @@
+package driving
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxRequestSize mirrors Kafka's socket.request.max.bytes default (100 MiB).
+const DefaultMaxRequestSize int32 = 100 * 1024 * 1024
+
+// requestSizeLength is the length of the INT32 size prefix in front of every Kafka request
+const requestSizeLength = 4
+
+// RequestSizeError is returned when a frame announces a size that is negative
+// or larger than the configured maximum request size.
+type RequestSizeError struct {
+	Size    int32
+	MaxSize int32
+}
+
+func (e *RequestSizeError) Error() string {
+	if e.Size < 0 {
+		return fmt.Sprintf("invalid request size %d", e.Size)
+	}
+	return fmt.Sprintf("request size %d exceeds maximum request size %d", e.Size, e.MaxSize)
+}
+
+// FrameReader splits a byte stream into complete length-prefixed Kafka requests.
+// Kafka request format: [4 bytes size][size bytes of header + body]
+// A single Read on the underlying stream may return part of a request or several
+// requests at once, so FrameReader accumulates exactly `size` bytes per frame.
+type FrameReader struct {
+	reader         *bufio.Reader
+	maxRequestSize int32
+}
+
+// NewFrameReader creates a frame reader on top of the given stream
+func NewFrameReader(r io.Reader, maxRequestSize int32) *FrameReader {
+	return &FrameReader{
+		reader:         bufio.NewReader(r),
+		maxRequestSize: maxRequestSize,
+	}
+}
+
+// ReadFrame blocks until a full request is available and returns it, including the
+// 4-byte size prefix, since the parsers expect the API key at bytes 4-5.
+// io.EOF is returned when the stream ends cleanly between two frames and
+// io.ErrUnexpectedEOF when it ends in the middle of one.
+func (f *FrameReader) ReadFrame() ([]byte, error) {
+	sizeBuffer := make([]byte, requestSizeLength)
+	if _, err := io.ReadFull(f.reader, sizeBuffer); err != nil {
+		return nil, err
+	}
+
+	size := int32(binary.BigEndian.Uint32(sizeBuffer))
+	if size < 0 || size > f.maxRequestSize {
+		return nil, &RequestSizeError{Size: size, MaxSize: f.maxRequestSize}
+	}
+
+	frame := make([]byte, requestSizeLength+int(size))
+	copy(frame, sizeBuffer)
+	if _, err := io.ReadFull(f.reader, frame[requestSizeLength:]); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	return frame, nil
+}
@@ -0,0 +1,118 @@
+package driving
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// chunkedReader returns at most chunkSize bytes per Read to simulate TCP segments
+type chunkedReader struct {
+	data      []byte
+	chunkSize int
+}
+
+func (c *chunkedReader) Read(p []byte) (int, error) {
+	if len(c.data) == 0 {
+		return 0, io.EOF
+	}
+	n := c.chunkSize
+	if n > len(c.data) {
+		n = len(c.data)
+	}
+	if n > len(p) {
+		n = len(p)
+	}
+	copy(p, c.data[:n])
+	c.data = c.data[n:]
+	return n, nil
+}
+
+func frame(payload []byte) []byte {
+	size := len(payload)
+	return append([]byte{byte(size >> 24), byte(size >> 16), byte(size >> 8), byte(size)}, payload...)
+}
+
+func TestFrameReader_ReadFrame(t *testing.T) {
+	first := frame([]byte{0x00, 0x12, 0x00, 0x04, 0x00, 0x00, 0x00, 0x07})
+	second := frame(bytes.Repeat([]byte{0xab}, 3000))
+
+	tests := []struct {
+		name      string
+		stream    []byte
+		chunkSize int
+		want      [][]byte
+	}{
+		{name: "single frame in one read", stream: first, chunkSize: len(first), want: [][]byte{first}},
+		{name: "frame split byte by byte", stream: first, chunkSize: 1, want: [][]byte{first}},
+		{name: "frame larger than 1 KB split across segments", stream: second, chunkSize: 1024, want: [][]byte{second}},
+		{name: "two coalesced frames", stream: append(append([]byte{}, first...), second...), chunkSize: 8192, want: [][]byte{first, second}},
+		{name: "coalesced frames split mid size prefix", stream: append(append([]byte{}, first...), second...), chunkSize: 14, want: [][]byte{first, second}},
+		{name: "empty payload", stream: frame([]byte{}), chunkSize: 4, want: [][]byte{frame([]byte{})}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewFrameReader(&chunkedReader{data: tt.stream, chunkSize: tt.chunkSize}, DefaultMaxRequestSize)
+			for i, want := range tt.want {
+				got, err := reader.ReadFrame()
+				if err != nil {
+					t.Fatalf("ReadFrame() #%d error = %v", i, err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Fatalf("ReadFrame() #%d returned %d bytes, want %d", i, len(got), len(want))
+				}
+			}
+			if _, err := reader.ReadFrame(); err != io.EOF {
+				t.Errorf("ReadFrame() after last frame error = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestFrameReader_ReadFrame_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		stream  []byte
+		maxSize int32
+		wantErr error
+	}{
+		{name: "truncated payload", stream: frame([]byte{0x01, 0x02, 0x03})[:5], maxSize: DefaultMaxRequestSize, wantErr: io.ErrUnexpectedEOF},
+		{name: "truncated size prefix", stream: []byte{0x00, 0x00}, maxSize: DefaultMaxRequestSize, wantErr: io.ErrUnexpectedEOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewFrameReader(bytes.NewReader(tt.stream), tt.maxSize)
+			if _, err := reader.ReadFrame(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ReadFrame() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFrameReader_ReadFrame_RequestTooLarge(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream []byte
+	}{
+		{name: "oversized request", stream: frame(bytes.Repeat([]byte{0x00}, 65))},
+		{name: "negative size", stream: []byte{0xff, 0xff, 0xff, 0xff}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewFrameReader(bytes.NewReader(tt.stream), 64)
+			_, err := reader.ReadFrame()
+			var sizeErr *RequestSizeError
+			if !errors.As(err, &sizeErr) {
+				t.Fatalf("ReadFrame() error = %v, want *RequestSizeError", err)
+			}
+			if sizeErr.MaxSize != 64 {
+				t.Errorf("RequestSizeError.MaxSize = %d, want 64", sizeErr.MaxSize)
+			}
+		})
+	}
+}
@@ -1,10 +1,12 @@
 package driving
 
 import (
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net"
-	"os"
+	"sync"
 
 	"github.com/codecrafters-io/kafka-starter-go/core/domain"
 	"github.com/codecrafters-io/kafka-starter-go/core/ports/driving"
@@ -14,19 +16,58 @@ import (
 // Rule 2: Adapters use the ports defined by the core.
 // Rule 3: Dependencies point inward - this adapter depends on the core port.
 type TCPServer struct {
-	handler driving.KafkaHandler
-	port    string
+	handler             driving.KafkaHandler
+	port                string
+	maxRequestSize      int32
+	maxPendingResponses int
+	tlsListenerConfig   *TLSListenerConfig // nil for a plaintext listener
+
+	mu          sync.Mutex
+	closed      bool
+	listener    net.Listener
+	conns       map[net.Conn]struct{}
+	connections sync.WaitGroup // handlers of the accepted connections
+}
+
+// DefaultMaxPendingResponses bounds how many responses a connection may have queued,
+// e.g. behind a long-polling Fetch, before the server stops reading its requests
+const DefaultMaxPendingResponses = 100
+
+// TCPServerOption configures optional TCPServer settings
+type TCPServerOption func(*TCPServer)
+
+// WithMaxRequestSize sets the largest request (excluding the 4-byte size prefix) the
+// server accepts before closing the connection
+func WithMaxRequestSize(maxRequestSize int32) TCPServerOption {
+	return func(s *TCPServer) {
+		s.maxRequestSize = maxRequestSize
+	}
+}
+
+// WithMaxPendingResponses sets how many responses a connection may have queued before
+// the server stops reading further requests from it
+func WithMaxPendingResponses(maxPendingResponses int) TCPServerOption {
+	return func(s *TCPServer) {
+		s.maxPendingResponses = maxPendingResponses
+	}
 }
 
 // NewTCPServer creates a new TCP server adapter
-func NewTCPServer(handler driving.KafkaHandler, port string) *TCPServer {
-	return &TCPServer{
-		handler: handler,
-		port:    port,
+func NewTCPServer(handler driving.KafkaHandler, port string, opts ...TCPServerOption) *TCPServer {
+	s := &TCPServer{
+		handler:             handler,
+		port:                port,
+		maxRequestSize:      DefaultMaxRequestSize,
+		maxPendingResponses: DefaultMaxPendingResponses,
+		conns:               map[net.Conn]struct{}{},
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-// Start starts the TCP server and begins accepting connections
+// Start starts the TCP server and accepts connections until Close is called
 func (s *TCPServer) Start() error {
 	l, err := net.Listen("tcp", s.port)
 	if err != nil {
@@ -34,35 +75,121 @@ func (s *TCPServer) Start() error {
 	}
 	defer l.Close()
 
-	fmt.Printf("Server listening on %s\n", s.port)
+	if s.tlsListenerConfig != nil {
+		tlsConfig, err := s.tlsListenerConfig.build()
+		if err != nil {
+			return fmt.Errorf("failed to configure TLS on port %s: %w", s.port, err)
+		}
+		l = tls.NewListener(l, tlsConfig)
+		fmt.Printf("Server listening on %s over TLS\n", s.port)
+	} else {
+		fmt.Printf("Server listening on %s\n", s.port)
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.listener = l
+	s.mu.Unlock()
 
 	for {
 		conn, err := l.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
 		if err != nil {
-			fmt.Printf("Error accepting connection: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to accept connections on port %s: %w", s.port, err)
+		}
+		if !s.track(conn) {
+			conn.Close()
+			continue
 		}
-		go s.handleConnection(conn)
+		go func() {
+			defer s.untrack(conn)
+			s.handleConnection(conn)
+		}()
 	}
 }
 
+// Close stops accepting connections and closes the open ones, returning once the
+// requests they were handling, deferred ones included, are done
+func (s *TCPServer) Close() {
+	s.mu.Lock()
+	s.closed = true
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.connections.Wait()
+}
+
+// track registers an accepted connection, refusing it once the server is closed
+func (s *TCPServer) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	s.connections.Add(1)
+	return true
+}
+
+// untrack forgets a connection whose handler returned
+func (s *TCPServer) untrack(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+	s.connections.Done()
+}
+
+// handleConnection handles the requests of a connection one at a time, in the order they
+// arrive. A handler may answer later through Response.Deferred; responses are queued and
+// written in request order, so a waiting Fetch doesn't stop the following requests from
+// being read and handled. Every request carries the principal of the connection's client.
 func (s *TCPServer) handleConnection(conn net.Conn) {
 	defer conn.Close()
 
+	principal, err := principalOf(conn)
+	if err != nil {
+		fmt.Printf("TLS handshake with %s failed: %v\n", conn.RemoteAddr(), err)
+		return
+	}
+
+	pending := make(chan (<-chan domain.Response), s.maxPendingResponses)
+	writerDone := make(chan struct{})
+	go s.writeResponses(conn, pending, writerDone)
+	defer func() {
+		close(pending)
+		<-writerDone
+	}()
+
+	frameReader := NewFrameReader(conn, s.maxRequestSize)
+
 	for {
-		buff := make([]byte, 1024)
-		n, err := conn.Read(buff)
+		frame, err := frameReader.ReadFrame()
 		if err != nil {
 			if err == io.EOF {
 				break
 			}
+			var sizeErr *RequestSizeError
+			if errors.As(err, &sizeErr) {
+				fmt.Printf("Closing connection from %s: %v\n", conn.RemoteAddr(), sizeErr)
+				break
+			}
 			fmt.Printf("Error reading from connection: %v\n", err)
 			break
 		}
 
 		// Create domain request
 		req := domain.Request{
-			Data: buff[:n],
+			Data:      frame,
+			Principal: principal,
 		}
 
 		// Call the driving port (core business logic)
@@ -74,11 +201,40 @@ func (s *TCPServer) handleConnection(conn net.Conn) {
 			break
 		}
 
+		if resp.Deferred != nil {
+			pending <- resp.Deferred
+			continue
+		}
+
+		// Requests such as Produce with acks=0 have no response
+		if len(resp.Data) == 0 {
+			continue
+		}
+
+		ready := make(chan domain.Response, 1)
+		ready <- resp
+		pending <- ready
+	}
+}
+
+// writeResponses writes the queued responses back to the client in order, waiting for
+// deferred ones to complete. After a failed write the connection is closed, which also
+// stops the request loop, and the remaining responses are drained unwritten.
+func (s *TCPServer) writeResponses(conn net.Conn, pending <-chan (<-chan domain.Response), done chan<- struct{}) {
+	defer close(done)
+
+	failed := false
+	for response := range pending {
+		resp := <-response
+		if failed || len(resp.Data) == 0 {
+			continue
+		}
+
 		// Write response back to client
-		_, err = conn.Write(resp.Data)
-		if err != nil {
+		if _, err := conn.Write(resp.Data); err != nil {
 			fmt.Printf("Error writing response: %v\n", err)
-			break
+			failed = true
+			conn.Close()
 		}
 	}
 }
@@ -0,0 +1,201 @@
+package driving
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+)
+
+// echoHandler answers every request with its own payload so the test can check
+// which frame was handed to the core
+type echoHandler struct {
+	requests [][]byte
+}
+
+func (h *echoHandler) HandleRequest(req domain.Request) (domain.Response, error) {
+	h.requests = append(h.requests, req.Data)
+	return domain.Response{Data: req.Data}, nil
+}
+
+func TestTCPServer_handleConnection_FragmentedAndCoalesced(t *testing.T) {
+	first := frame([]byte{0x00, 0x12, 0x00, 0x04, 0x00, 0x00, 0x00, 0x01})
+	second := frame(bytes.Repeat([]byte{0x01}, 2048))
+	third := frame([]byte{0x00, 0x4b, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03})
+
+	handler := &echoHandler{}
+	server := NewTCPServer(handler, "")
+	client, serverConn := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		server.handleConnection(serverConn)
+		close(done)
+	}()
+
+	go func() {
+		// first frame split across two writes, second and third coalesced into one write
+		client.Write(first[:3])
+		client.Write(append(append(first[3:], second...), third...))
+	}()
+
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for _, want := range [][]byte{first, second, third} {
+		got := make([]byte, len(want))
+		if _, err := io.ReadFull(client, got); err != nil {
+			t.Fatalf("reading response: %v", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("response of %d bytes does not match request of %d bytes", len(got), len(want))
+		}
+	}
+	client.Close()
+	<-done
+
+	if len(handler.requests) != 3 {
+		t.Errorf("handler received %d requests, want 3", len(handler.requests))
+	}
+}
+
+func TestTCPServer_handleConnection_ClosesOnOversizedRequest(t *testing.T) {
+	handler := &echoHandler{}
+	server := NewTCPServer(handler, "", WithMaxRequestSize(16))
+	client, serverConn := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		server.handleConnection(serverConn)
+		close(done)
+	}()
+
+	go client.Write(frame(bytes.Repeat([]byte{0x00}, 17)))
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection was not closed after an oversized request")
+	}
+	if len(handler.requests) != 0 {
+		t.Errorf("handler received %d requests, want 0", len(handler.requests))
+	}
+}
+
+// deferringHandler answers the first request through Response.Deferred, completed by
+// the test, and the others immediately
+type deferringHandler struct {
+	deferred chan domain.Response
+	handled  chan []byte
+	count    int
+}
+
+func (h *deferringHandler) HandleRequest(req domain.Request) (domain.Response, error) {
+	h.count++
+	h.handled <- req.Data
+	if h.count == 1 {
+		return domain.Response{Deferred: h.deferred}, nil
+	}
+	return domain.Response{Data: req.Data}, nil
+}
+
+func TestTCPServer_handleConnection_DeferredResponsesKeepOrder(t *testing.T) {
+	first := frame([]byte{0x00, 0x01, 0x00, 0x10, 0x00, 0x00, 0x00, 0x01})
+	second := frame([]byte{0x00, 0x12, 0x00, 0x04, 0x00, 0x00, 0x00, 0x02})
+
+	handler := &deferringHandler{deferred: make(chan domain.Response, 1), handled: make(chan []byte, 2)}
+	server := NewTCPServer(handler, "")
+	client, serverConn := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		server.handleConnection(serverConn)
+		close(done)
+	}()
+
+	go client.Write(append(append([]byte{}, first...), second...))
+
+	// The second request is read and handled while the first is still waiting
+	for range 2 {
+		select {
+		case <-handler.handled:
+		case <-time.After(2 * time.Second):
+			t.Fatal("second request was not handled while the first was deferred")
+		}
+	}
+	handler.deferred <- domain.Response{Data: first}
+
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for _, want := range [][]byte{first, second} {
+		got := make([]byte, len(want))
+		if _, err := io.ReadFull(client, got); err != nil {
+			t.Fatalf("reading response: %v", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("got response %x, want %x", got, want)
+		}
+	}
+	client.Close()
+	<-done
+}
+
+func TestTCPServer_Close(t *testing.T) {
+	handler := &deferringHandler{deferred: make(chan domain.Response, 1), handled: make(chan []byte, 1)}
+	server := NewTCPServer(handler, "127.0.0.1:0")
+	started := make(chan error, 1)
+	go func() {
+		started <- server.Start()
+	}()
+
+	var address string
+	for deadline := time.Now().Add(2 * time.Second); address == ""; {
+		server.mu.Lock()
+		if server.listener != nil {
+			address = server.listener.Addr().String()
+		}
+		server.mu.Unlock()
+		if time.Now().After(deadline) {
+			t.Fatal("server is not listening")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	client, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	request := frame([]byte{0x00, 0x01, 0x00, 0x10, 0x00, 0x00, 0x00, 0x01})
+	client.Write(request)
+	select {
+	case <-handler.handled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("request was not handled")
+	}
+
+	// Close waits for the deferred response of the request in flight
+	closed := make(chan struct{})
+	go func() {
+		server.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		t.Fatal("Close() returned while a request was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+	handler.deferred <- domain.Response{Data: request}
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close() did not return after the request was done")
+	}
+
+	if err := <-started; err != nil {
+		t.Errorf("Start() error = %v, want nil once closed", err)
+	}
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := io.ReadAll(client); err != nil {
+		t.Errorf("reading the closed connection: %v, want EOF", err)
+	}
+	if _, err := net.Dial("tcp", address); err == nil {
+		t.Error("server still accepts connections after Close()")
+	}
+}
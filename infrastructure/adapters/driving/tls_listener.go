@@ -0,0 +1,117 @@
+package driving
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+)
+
+// tlsHandshakeTimeout bounds how long a client may take to complete the TLS handshake
+const tlsHandshakeTimeout = 10 * time.Second
+
+// TLSListenerConfig configures a TLS (Kafka's SSL) listener
+type TLSListenerConfig struct {
+	CertFile string // PEM certificate chain the server presents
+	KeyFile  string // PEM private key of the certificate
+	// ClientAuth is tls.RequireAndVerifyClientCert for mutual TLS, or
+	// tls.VerifyClientCertIfGiven to also accept clients without a certificate.
+	// Client certificates are verified against the CAs of ClientCAFile.
+	ClientAuth   tls.ClientAuthType
+	ClientCAFile string
+	MinVersion   uint16 // Oldest TLS version accepted, TLS 1.2 when zero
+	// CipherSuites names the cipher suites accepted up to TLS 1.2, such as
+	// TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256; empty keeps Go's defaults. TLS 1.3 suites
+	// can't be restricted.
+	CipherSuites []string
+}
+
+// WithTLS serves the listener over TLS. Clients presenting a verified certificate get
+// its subject as the principal of their requests.
+func WithTLS(config TLSListenerConfig) TCPServerOption {
+	return func(s *TCPServer) {
+		s.tlsListenerConfig = &config
+	}
+}
+
+// build loads the certificates and returns the server's TLS configuration
+func (c TLSListenerConfig) build() (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading the TLS certificate: %w", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientAuth:   c.ClientAuth,
+		MinVersion:   c.MinVersion,
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+
+	if c.ClientCAFile != "" {
+		pem, err := os.ReadFile(c.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading the client CAs: %w", err)
+		}
+		config.ClientCAs = x509.NewCertPool()
+		if !config.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", c.ClientCAFile)
+		}
+	} else if c.ClientAuth >= tls.VerifyClientCertIfGiven {
+		return nil, fmt.Errorf("verifying client certificates needs a client CA file")
+	}
+
+	config.CipherSuites, err = cipherSuiteIDs(c.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// cipherSuiteIDs returns the IDs of the named cipher suites, refusing unknown and
+// insecure ones
+func cipherSuiteIDs(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	byName := map[string]uint16{}
+	for _, suite := range tls.CipherSuites() {
+		byName[suite.Name] = suite.ID
+	}
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, known := byName[name]
+		if !known {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %s", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// principalOf returns the principal of the client on conn. TLS connections complete
+// their handshake first; a client with a verified certificate is "User:" followed by
+// the certificate's subject, any other client is anonymous.
+func principalOf(conn net.Conn) (string, error) {
+	tlsConn, isTLS := conn.(*tls.Conn)
+	if !isTLS {
+		return domain.AnonymousPrincipal, nil
+	}
+
+	tlsConn.SetDeadline(time.Now().Add(tlsHandshakeTimeout))
+	if err := tlsConn.Handshake(); err != nil {
+		return "", err
+	}
+	tlsConn.SetDeadline(time.Time{})
+
+	verifiedChains := tlsConn.ConnectionState().VerifiedChains
+	if len(verifiedChains) == 0 {
+		return domain.AnonymousPrincipal, nil
+	}
+	return "User:" + verifiedChains[0][0].Subject.String(), nil
+}
@@ -0,0 +1,271 @@
+package driving
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+)
+
+// principalHandler records the principal of every request
+type principalHandler struct {
+	principals chan string
+}
+
+func (h *principalHandler) HandleRequest(req domain.Request) (domain.Response, error) {
+	h.principals <- req.Principal
+	return domain.Response{Data: req.Data}, nil
+}
+
+// testPKI is a CA with a server and a client certificate signed by it, written as PEM files
+type testPKI struct {
+	caFile, serverCertFile, serverKeyFile string
+	clientCertificate                     tls.Certificate
+	roots                                 *x509.CertPool
+}
+
+func newTestPKI(t *testing.T) testPKI {
+	t.Helper()
+	dir := t.TempDir()
+
+	caKey, caCert := newTestCertificate(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, nil)
+	serverKey, serverCert := newTestCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "broker"},
+		DNSNames:    []string{"broker"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, caCert, caKey)
+	clientKey, clientCert := newTestCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "alice", Organization: []string{"clients"}},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caCert, caKey)
+
+	pki := testPKI{
+		caFile:         filepath.Join(dir, "ca.pem"),
+		serverCertFile: filepath.Join(dir, "server.pem"),
+		serverKeyFile:  filepath.Join(dir, "server.key"),
+		clientCertificate: tls.Certificate{
+			Certificate: [][]byte{clientCert.Raw},
+			PrivateKey:  clientKey,
+		},
+		roots: x509.NewCertPool(),
+	}
+	pki.roots.AddCert(caCert)
+	writePEM(t, pki.caFile, "CERTIFICATE", caCert.Raw)
+	writePEM(t, pki.serverCertFile, "CERTIFICATE", serverCert.Raw)
+	serverKeyDER, err := x509.MarshalECPrivateKey(serverKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, pki.serverKeyFile, "EC PRIVATE KEY", serverKeyDER)
+	return pki
+}
+
+// newTestCertificate creates a certificate from template, self-signed when parent is nil
+func newTestCertificate(t *testing.T, template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*ecdsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, certificate
+}
+
+func writePEM(t *testing.T, path string, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// serveTLS serves one connection of a TLS listener configured by config to a client
+// configured by clientConfig. It returns the principal of a request sent by the client,
+// or the empty string once the connection closes without one.
+func serveTLS(t *testing.T, config TLSListenerConfig, clientConfig *tls.Config) string {
+	t.Helper()
+	serverConfig, err := config.build()
+	if err != nil {
+		t.Fatalf("building the TLS configuration: %v", err)
+	}
+	handler := &principalHandler{principals: make(chan string, 1)}
+	server := NewTCPServer(handler, "", WithTLS(config))
+	// a loopback connection rather than net.Pipe, whose unbuffered writes would deadlock
+	// the client's request against the alert of a failed handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serverConn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		server.handleConnection(tls.Server(serverConn, serverConfig))
+	}()
+
+	clientConn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := tls.Client(clientConn, clientConfig)
+	defer client.Close()
+	client.SetDeadline(time.Now().Add(2 * time.Second))
+	request := frame([]byte{0x00, 0x12, 0x00, 0x04, 0x00, 0x00, 0x00, 0x01})
+	responses := make(chan error, 1)
+	go func() {
+		client.Write(request)
+		_, err := io.ReadFull(client, make([]byte, len(request)))
+		responses <- err
+	}()
+
+	select {
+	case principal := <-handler.principals:
+		if err := <-responses; err != nil {
+			t.Errorf("reading response: %v", err)
+		}
+		return principal
+	case <-done:
+		return ""
+	case <-time.After(2 * time.Second):
+		t.Fatal("no request was handled and the connection stayed open")
+		return ""
+	}
+}
+
+func TestTCPServer_TLS(t *testing.T) {
+	pki := newTestPKI(t)
+	serverName := "broker"
+
+	tests := []struct {
+		name          string
+		config        TLSListenerConfig
+		clientConfig  *tls.Config
+		wantPrincipal string
+	}{
+		{
+			name: "mutual TLS exposes the client certificate subject",
+			config: TLSListenerConfig{
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAFile: pki.caFile,
+			},
+			clientConfig:  &tls.Config{Certificates: []tls.Certificate{pki.clientCertificate}},
+			wantPrincipal: "User:CN=alice,O=clients",
+		},
+		{
+			name: "mutual TLS refuses clients without a certificate",
+			config: TLSListenerConfig{
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAFile: pki.caFile,
+			},
+			clientConfig:  &tls.Config{},
+			wantPrincipal: "",
+		},
+		{
+			name: "optional client certificate accepts anonymous clients",
+			config: TLSListenerConfig{
+				ClientAuth:   tls.VerifyClientCertIfGiven,
+				ClientCAFile: pki.caFile,
+			},
+			clientConfig:  &tls.Config{},
+			wantPrincipal: domain.AnonymousPrincipal,
+		},
+		{
+			name:          "without client authentication clients are anonymous",
+			config:        TLSListenerConfig{},
+			clientConfig:  &tls.Config{Certificates: []tls.Certificate{pki.clientCertificate}},
+			wantPrincipal: domain.AnonymousPrincipal,
+		},
+		{
+			name:          "minimum version refuses older clients",
+			config:        TLSListenerConfig{MinVersion: tls.VersionTLS13},
+			clientConfig:  &tls.Config{MaxVersion: tls.VersionTLS12},
+			wantPrincipal: "",
+		},
+		{
+			name:          "cipher suites restrict TLS 1.2 clients",
+			config:        TLSListenerConfig{CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"}},
+			clientConfig:  &tls.Config{MaxVersion: tls.VersionTLS12, CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}},
+			wantPrincipal: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.CertFile = pki.serverCertFile
+			tt.config.KeyFile = pki.serverKeyFile
+			tt.clientConfig.RootCAs = pki.roots
+			tt.clientConfig.ServerName = serverName
+
+			if got := serveTLS(t, tt.config, tt.clientConfig); got != tt.wantPrincipal {
+				t.Errorf("principal = %q, want %q", got, tt.wantPrincipal)
+			}
+		})
+	}
+}
+
+func TestTLSListenerConfig_build_Errors(t *testing.T) {
+	pki := newTestPKI(t)
+
+	tests := []struct {
+		name   string
+		config TLSListenerConfig
+	}{
+		{
+			name:   "missing key",
+			config: TLSListenerConfig{CertFile: pki.serverCertFile, KeyFile: filepath.Join(t.TempDir(), "missing.key")},
+		},
+		{
+			name:   "client verification without CAs",
+			config: TLSListenerConfig{CertFile: pki.serverCertFile, KeyFile: pki.serverKeyFile, ClientAuth: tls.RequireAndVerifyClientCert},
+		},
+		{
+			name:   "unknown cipher suite",
+			config: TLSListenerConfig{CertFile: pki.serverCertFile, KeyFile: pki.serverKeyFile, CipherSuites: []string{"TLS_NOT_A_SUITE"}},
+		},
+		{
+			name:   "insecure cipher suite",
+			config: TLSListenerConfig{CertFile: pki.serverCertFile, KeyFile: pki.serverKeyFile, CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.config.build(); err == nil {
+				t.Error("build succeeded, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// AddOffsetsToTxn v3+ uses the flexible (compact) encodings
+const addOffsetsToTxnFirstFlexibleVersion = 3
+
+type KafkaProtocolParserAddOffsetsToTxn struct{}
+
+// NewKafkaProtocolParserAddOffsetsToTxn creates a new Kafka AddOffsetsToTxn protocol parser
+func NewKafkaProtocolParserAddOffsetsToTxn() parser.AddOffsetsToTxnParser {
+	return &KafkaProtocolParserAddOffsetsToTxn{}
+}
+
+func (p *KafkaProtocolParserAddOffsetsToTxn) ParseRequest(data []byte) (*domain.ParsedRequestAddOffsetsToTxn, error) {
+	header, reader, err := parseRequestHeader(data, addOffsetsToTxnFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	flexible := isFlexible(header.APIVersion, addOffsetsToTxnFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestAddOffsetsToTxn{
+		APIKey:          header.APIKey,
+		APIVersion:      header.APIVersion,
+		CorrelationID:   header.CorrelationID,
+		ClientID:        header.ClientID,
+		TransactionalID: reader.String("TransactionalId", flexible),
+		ProducerID:      reader.Int64("ProducerId"),
+		ProducerEpoch:   reader.Int16("ProducerEpoch"),
+		GroupID:         reader.String("GroupId", flexible),
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("AddOffsetsToTxn", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserAddOffsetsToTxn) EncodeResponse(response *domain.ResponseDataAddOffsetsToTxn) ([]byte, error) {
+	flexible := isFlexible(response.APIVersion, addOffsetsToTxnFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	writer.Int32(response.ThrottleTimeMs)
+	writer.Int16(response.ErrorCode)
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
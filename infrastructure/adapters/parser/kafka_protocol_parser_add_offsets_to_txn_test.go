@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func TestKafkaProtocolParserAddOffsetsToTxn_ParseRequest(t *testing.T) {
+	for _, version := range []int16{0, 3} {
+		flexible := version >= 3
+		w := common.NewKafkaWriter()
+		w.Int16(25)
+		w.Int16(version)
+		w.Int32(7)
+		w.String("producer-1", false)
+		if flexible {
+			w.EmptyTaggedFields()
+		}
+		w.String("txn-1", flexible)
+		w.Int64(4000)
+		w.Int16(2)
+		w.String("group-a", flexible)
+		if flexible {
+			w.EmptyTaggedFields()
+		}
+
+		parsed, err := NewKafkaProtocolParserAddOffsetsToTxn().ParseRequest(w.WithSizePrefix())
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+		if parsed.TransactionalID != "txn-1" || parsed.ProducerID != 4000 || parsed.ProducerEpoch != 2 || parsed.GroupID != "group-a" {
+			t.Errorf("v%d ParseRequest() = %+v", version, parsed)
+		}
+	}
+}
+
+func TestKafkaProtocolParserAddOffsetsToTxn_EncodeResponse(t *testing.T) {
+	for _, version := range []int{0, 3} {
+		flexible := version >= 3
+		encoded, err := NewKafkaProtocolParserAddOffsetsToTxn().EncodeResponse(&domain.ResponseDataAddOffsetsToTxn{
+			CorrelationID: []byte{0x00, 0x00, 0x00, 0x07},
+			APIVersion:    version,
+			ErrorCode:     domain.ErrorCodeInvalidProducerIDMapping,
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		reader.Int32("ThrottleTimeMs")
+		if errorCode := reader.Int16("ErrorCode"); errorCode != domain.ErrorCodeInvalidProducerIDMapping {
+			t.Errorf("v%d ErrorCode = %d, want %d", version, errorCode, domain.ErrorCodeInvalidProducerIDMapping)
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// AddPartitionsToTxn v3+ uses the flexible (compact) encodings. v4 batches the
+// transactions of several producers for brokers and isn't supported.
+const addPartitionsToTxnFirstFlexibleVersion = 3
+
+type KafkaProtocolParserAddPartitionsToTxn struct{}
+
+// NewKafkaProtocolParserAddPartitionsToTxn creates a new Kafka AddPartitionsToTxn protocol parser
+func NewKafkaProtocolParserAddPartitionsToTxn() parser.AddPartitionsToTxnParser {
+	return &KafkaProtocolParserAddPartitionsToTxn{}
+}
+
+func (p *KafkaProtocolParserAddPartitionsToTxn) ParseRequest(data []byte) (*domain.ParsedRequestAddPartitionsToTxn, error) {
+	header, reader, err := parseRequestHeader(data, addPartitionsToTxnFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	flexible := isFlexible(header.APIVersion, addPartitionsToTxnFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestAddPartitionsToTxn{
+		APIKey:          header.APIKey,
+		APIVersion:      header.APIVersion,
+		CorrelationID:   header.CorrelationID,
+		ClientID:        header.ClientID,
+		TransactionalID: reader.String("TransactionalId", flexible),
+		ProducerID:      reader.Int64("ProducerId"),
+		ProducerEpoch:   reader.Int16("ProducerEpoch"),
+	}
+	topicsLength := reader.ArrayLength("Topics", flexible)
+	for range topicsLength {
+		topic := domain.AddPartitionsToTxnTopic{
+			Name:       reader.String("Name", flexible),
+			Partitions: reader.Int32Array("Partitions", flexible),
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		parsed.Topics = append(parsed.Topics, topic)
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("AddPartitionsToTxn", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserAddPartitionsToTxn) EncodeResponse(response *domain.ResponseDataAddPartitionsToTxn) ([]byte, error) {
+	flexible := isFlexible(response.APIVersion, addPartitionsToTxnFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	writer.Int32(response.ThrottleTimeMs)
+	writer.ArrayLength(len(response.Results), flexible)
+	for _, topic := range response.Results {
+		writer.String(topic.Name, flexible)
+		writer.ArrayLength(len(topic.Partitions), flexible)
+		for _, partition := range topic.Partitions {
+			writer.Int32(partition.PartitionIndex)
+			writer.Int16(partition.ErrorCode)
+			if flexible {
+				writer.EmptyTaggedFields()
+			}
+		}
+		if flexible {
+			writer.EmptyTaggedFields()
+		}
+	}
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
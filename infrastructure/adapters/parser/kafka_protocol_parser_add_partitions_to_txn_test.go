@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func TestKafkaProtocolParserAddPartitionsToTxn_ParseRequest(t *testing.T) {
+	for _, version := range []int16{0, 2, 3} {
+		flexible := version >= 3
+		w := common.NewKafkaWriter()
+		w.Int16(24)
+		w.Int16(version)
+		w.Int32(7)
+		w.String("producer-1", false)
+		if flexible {
+			w.EmptyTaggedFields()
+		}
+		w.String("txn-1", flexible)
+		w.Int64(4000)
+		w.Int16(2)
+		w.ArrayLength(1, flexible)
+		w.String("orders", flexible)
+		w.Int32Array([]int32{0, 3}, flexible)
+		if flexible {
+			w.EmptyTaggedFields()
+			w.EmptyTaggedFields()
+		}
+
+		parsed, err := NewKafkaProtocolParserAddPartitionsToTxn().ParseRequest(w.WithSizePrefix())
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+		if parsed.TransactionalID != "txn-1" || parsed.ProducerID != 4000 || parsed.ProducerEpoch != 2 {
+			t.Errorf("v%d ParseRequest() = %+v", version, parsed)
+		}
+		if len(parsed.Topics) != 1 || parsed.Topics[0].Name != "orders" || !slices.Equal(parsed.Topics[0].Partitions, []int32{0, 3}) {
+			t.Errorf("v%d Topics = %+v, want orders [0 3]", version, parsed.Topics)
+		}
+	}
+}
+
+func TestKafkaProtocolParserAddPartitionsToTxn_EncodeResponse(t *testing.T) {
+	for _, version := range []int{0, 3} {
+		flexible := version >= 3
+		encoded, err := NewKafkaProtocolParserAddPartitionsToTxn().EncodeResponse(&domain.ResponseDataAddPartitionsToTxn{
+			CorrelationID: []byte{0x00, 0x00, 0x00, 0x07},
+			APIVersion:    version,
+			Results: []domain.AddPartitionsToTxnTopicResult{{
+				Name:       "orders",
+				Partitions: []domain.AddPartitionsToTxnPartitionResult{{PartitionIndex: 3, ErrorCode: domain.ErrorCodeConcurrentTransactions}},
+			}},
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		reader.Int32("ThrottleTimeMs")
+		if topics := reader.ArrayLength("Results", flexible); topics != 1 {
+			t.Fatalf("v%d %d topics, want 1", version, topics)
+		}
+		if name := reader.String("Name", flexible); name != "orders" {
+			t.Errorf("v%d Name = %q, want orders", version, name)
+		}
+		reader.ArrayLength("Partitions", flexible)
+		if partition, errorCode := reader.Int32("PartitionIndex"), reader.Int16("ErrorCode"); partition != 3 || errorCode != domain.ErrorCodeConcurrentTransactions {
+			t.Errorf("v%d partition %d error %d, want 3 error %d", version, partition, errorCode, domain.ErrorCodeConcurrentTransactions)
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+			reader.SkipTaggedFields()
+			reader.SkipTaggedFields()
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// ConsumerGroupDescribe is flexible in every version
+const consumerGroupDescribeFirstFlexibleVersion = 0
+
+type KafkaProtocolParserConsumerGroupDescribe struct{}
+
+// NewKafkaProtocolParserConsumerGroupDescribe creates a new Kafka ConsumerGroupDescribe protocol parser
+func NewKafkaProtocolParserConsumerGroupDescribe() parser.ConsumerGroupDescribeParser {
+	return &KafkaProtocolParserConsumerGroupDescribe{}
+}
+
+func (p *KafkaProtocolParserConsumerGroupDescribe) ParseRequest(data []byte) (*domain.ParsedRequestConsumerGroupDescribe, error) {
+	header, reader, err := parseRequestHeader(data, consumerGroupDescribeFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := &domain.ParsedRequestConsumerGroupDescribe{
+		APIKey:                      header.APIKey,
+		APIVersion:                  header.APIVersion,
+		CorrelationID:               header.CorrelationID,
+		ClientID:                    header.ClientID,
+		GroupIDs:                    reader.StringArray("GroupIds", true),
+		IncludeAuthorizedOperations: reader.Bool("IncludeAuthorizedOperations"),
+	}
+	reader.SkipTaggedFields()
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("ConsumerGroupDescribe", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserConsumerGroupDescribe) EncodeResponse(response *domain.ResponseDataConsumerGroupDescribe) ([]byte, error) {
+	writer := newResponseWriter(response.CorrelationID, true)
+
+	writer.Int32(response.ThrottleTimeMs)
+	writer.ArrayLength(len(response.Groups), true)
+	for _, group := range response.Groups {
+		writer.Int16(group.ErrorCode)
+		writer.NullableString(nullIfEmpty(group.ErrorMessage), true)
+		writer.String(group.GroupID, true)
+		writer.String(group.GroupState, true)
+		writer.Int32(group.GroupEpoch)
+		writer.Int32(group.AssignmentEpoch)
+		writer.String(group.AssignorName, true)
+		writer.ArrayLength(len(group.Members), true)
+		for _, member := range group.Members {
+			writer.String(member.MemberID, true)
+			writer.NullableString(nullIfEmpty(member.InstanceID), true)
+			writer.NullableString(nullIfEmpty(member.RackID), true)
+			writer.Int32(member.MemberEpoch)
+			writer.String(member.ClientID, true)
+			writer.String(member.ClientHost, true)
+			writer.StringArray(member.SubscribedTopicNames, true)
+			writer.NullableString(nullIfEmpty(member.SubscribedTopicRegex), true)
+			writeConsumerGroupDescribedAssignment(writer, member.Assignment)
+			writeConsumerGroupDescribedAssignment(writer, member.TargetAssignment)
+			writer.EmptyTaggedFields()
+		}
+		writer.Int32(group.AuthorizedOperations)
+		writer.EmptyTaggedFields()
+	}
+	writer.EmptyTaggedFields()
+
+	return writer.WithSizePrefix(), nil
+}
+
+// writeConsumerGroupDescribedAssignment writes an Assignment struct
+func writeConsumerGroupDescribedAssignment(writer *common.KafkaWriter, partitions []domain.ConsumerGroupDescribedPartitions) {
+	writer.ArrayLength(len(partitions), true)
+	for _, topic := range partitions {
+		writer.UUID(topic.TopicID)
+		writer.String(topic.TopicName, true)
+		writer.Int32Array(topic.Partitions, true)
+		writer.EmptyTaggedFields()
+	}
+	writer.EmptyTaggedFields()
+}
@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func TestKafkaProtocolParserConsumerGroupDescribe_ParseRequest(t *testing.T) {
+	w := common.NewKafkaWriter()
+	w.Int16(69)
+	w.Int16(0)
+	w.Int32(5)
+	w.String("admin-1", false)
+	w.EmptyTaggedFields()
+	w.StringArray([]string{"group-a", "group-b"}, true)
+	w.Bool(true)
+	w.EmptyTaggedFields()
+
+	parsed, err := NewKafkaProtocolParserConsumerGroupDescribe().ParseRequest(w.WithSizePrefix())
+	if err != nil {
+		t.Fatalf("ParseRequest() error = %v", err)
+	}
+	if !reflect.DeepEqual(parsed.GroupIDs, []string{"group-a", "group-b"}) || !parsed.IncludeAuthorizedOperations {
+		t.Errorf("parsed = %+v, want both groups with authorized operations", parsed)
+	}
+}
+
+func readConsumerGroupDescribedAssignment(reader *common.KafkaReader) []domain.ConsumerGroupDescribedPartitions {
+	partitions := []domain.ConsumerGroupDescribedPartitions{}
+	length := reader.ArrayLength("TopicPartitions", true)
+	for range length {
+		partitions = append(partitions, domain.ConsumerGroupDescribedPartitions{
+			TopicID:    reader.UUID("TopicId"),
+			TopicName:  reader.String("TopicName", true),
+			Partitions: reader.Int32Array("Partitions", true),
+		})
+		reader.SkipTaggedFields()
+	}
+	reader.SkipTaggedFields()
+	return partitions
+}
+
+func TestKafkaProtocolParserConsumerGroupDescribe_EncodeResponse(t *testing.T) {
+	orders := []domain.ConsumerGroupDescribedPartitions{{TopicID: bytes.Repeat([]byte{0xaa}, 16), TopicName: "orders", Partitions: []int32{0, 1}}}
+	groups := []domain.ConsumerGroupDescription{
+		{
+			GroupID:         "group-a",
+			GroupState:      "Stable",
+			GroupEpoch:      3,
+			AssignmentEpoch: 3,
+			AssignorName:    "uniform",
+			Members: []domain.ConsumerGroupMemberDescription{{
+				MemberID:             "member-1",
+				InstanceID:           "instance-1",
+				MemberEpoch:          3,
+				ClientID:             "consumer-1",
+				SubscribedTopicNames: []string{"orders"},
+				SubscribedTopicRegex: "audit-.*",
+				Assignment:           orders,
+				TargetAssignment:     orders,
+			}},
+			AuthorizedOperations: -2147483648,
+		},
+		{ErrorCode: domain.ErrorCodeGroupIDNotFound, ErrorMessage: "Group group-b not found.", GroupID: "group-b", Members: []domain.ConsumerGroupMemberDescription{}},
+	}
+	encoded, err := NewKafkaProtocolParserConsumerGroupDescribe().EncodeResponse(&domain.ResponseDataConsumerGroupDescribe{
+		CorrelationID: []byte{0x00, 0x00, 0x00, 0x05},
+		Groups:        groups,
+	})
+	if err != nil {
+		t.Fatalf("EncodeResponse() error = %v", err)
+	}
+
+	reader := common.NewKafkaReader(encoded, 4)
+	reader.Int32("CorrelationID")
+	reader.SkipTaggedFields()
+	reader.Int32("ThrottleTimeMs")
+	got := []domain.ConsumerGroupDescription{}
+	groupsLength := reader.ArrayLength("Groups", true)
+	for range groupsLength {
+		group := domain.ConsumerGroupDescription{
+			ErrorCode:       reader.Int16("ErrorCode"),
+			ErrorMessage:    reader.String("ErrorMessage", true),
+			GroupID:         reader.String("GroupId", true),
+			GroupState:      reader.String("GroupState", true),
+			GroupEpoch:      reader.Int32("GroupEpoch"),
+			AssignmentEpoch: reader.Int32("AssignmentEpoch"),
+			AssignorName:    reader.String("AssignorName", true),
+			Members:         []domain.ConsumerGroupMemberDescription{},
+		}
+		membersLength := reader.ArrayLength("Members", true)
+		for range membersLength {
+			member := domain.ConsumerGroupMemberDescription{
+				MemberID:             reader.String("MemberId", true),
+				InstanceID:           reader.String("InstanceId", true),
+				RackID:               reader.String("RackId", true),
+				MemberEpoch:          reader.Int32("MemberEpoch"),
+				ClientID:             reader.String("ClientId", true),
+				ClientHost:           reader.String("ClientHost", true),
+				SubscribedTopicNames: reader.StringArray("SubscribedTopicNames", true),
+				SubscribedTopicRegex: reader.String("SubscribedTopicRegex", true),
+			}
+			member.Assignment = readConsumerGroupDescribedAssignment(reader)
+			member.TargetAssignment = readConsumerGroupDescribedAssignment(reader)
+			reader.SkipTaggedFields()
+			group.Members = append(group.Members, member)
+		}
+		group.AuthorizedOperations = reader.Int32("AuthorizedOperations")
+		reader.SkipTaggedFields()
+		got = append(got, group)
+	}
+	reader.SkipTaggedFields()
+
+	if !reflect.DeepEqual(got, groups) {
+		t.Errorf("groups = %+v, want %+v", got, groups)
+	}
+	if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+		t.Errorf("response error %v with %d trailing bytes", err, reader.Remaining())
+	}
+}
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// ConsumerGroupHeartbeat is flexible in every version
+const consumerGroupHeartbeatFirstFlexibleVersion = 0
+
+type KafkaProtocolParserConsumerGroupHeartbeat struct{}
+
+// NewKafkaProtocolParserConsumerGroupHeartbeat creates a new Kafka ConsumerGroupHeartbeat protocol parser
+func NewKafkaProtocolParserConsumerGroupHeartbeat() parser.ConsumerGroupHeartbeatParser {
+	return &KafkaProtocolParserConsumerGroupHeartbeat{}
+}
+
+func (p *KafkaProtocolParserConsumerGroupHeartbeat) ParseRequest(data []byte) (*domain.ParsedRequestConsumerGroupHeartbeat, error) {
+	header, reader, err := parseRequestHeader(data, consumerGroupHeartbeatFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	version := header.APIVersion
+
+	parsed := &domain.ParsedRequestConsumerGroupHeartbeat{
+		APIKey:        header.APIKey,
+		APIVersion:    header.APIVersion,
+		CorrelationID: header.CorrelationID,
+		ClientID:      header.ClientID,
+		GroupID:       reader.String("GroupId", true),
+		MemberID:      reader.String("MemberId", true),
+		MemberEpoch:   reader.Int32("MemberEpoch"),
+	}
+	parsed.InstanceID, _ = reader.NullableString("InstanceId", true)
+	parsed.RackID, _ = reader.NullableString("RackId", true)
+	parsed.RebalanceTimeoutMs = reader.Int32("RebalanceTimeoutMs")
+	parsed.SubscribedTopicNames = reader.StringArray("SubscribedTopicNames", true)
+	if version >= 1 {
+		if regex, exists := reader.NullableString("SubscribedTopicRegex", true); exists {
+			parsed.SubscribedTopicRegex = &regex
+		}
+	}
+	parsed.ServerAssignor, _ = reader.NullableString("ServerAssignor", true)
+
+	topicPartitionsLength := reader.ArrayLength("TopicPartitions", true)
+	if topicPartitionsLength >= 0 {
+		parsed.TopicPartitions = make([]domain.ConsumerGroupTopicPartitions, 0, topicPartitionsLength)
+	}
+	for range topicPartitionsLength {
+		parsed.TopicPartitions = append(parsed.TopicPartitions, domain.ConsumerGroupTopicPartitions{
+			TopicID:    reader.UUID("TopicId"),
+			Partitions: reader.Int32Array("Partitions", true),
+		})
+		reader.SkipTaggedFields()
+	}
+	reader.SkipTaggedFields()
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("ConsumerGroupHeartbeat", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserConsumerGroupHeartbeat) EncodeResponse(response *domain.ResponseDataConsumerGroupHeartbeat) ([]byte, error) {
+	writer := newResponseWriter(response.CorrelationID, true)
+
+	writer.Int32(response.ThrottleTimeMs)
+	writer.Int16(response.ErrorCode)
+	writer.NullableString(nullIfEmpty(response.ErrorMessage), true)
+	writer.NullableString(nullIfEmpty(response.MemberID), true)
+	writer.Int32(response.MemberEpoch)
+	writer.Int32(response.HeartbeatIntervalMs)
+	// Assignment is a nullable struct, prefixed with -1 when null and 1 otherwise
+	if response.Assignment == nil {
+		writer.Int8(-1)
+	} else {
+		writer.Int8(1)
+		writer.ArrayLength(len(response.Assignment), true)
+		for _, topic := range response.Assignment {
+			writer.UUID(topic.TopicID)
+			writer.Int32Array(topic.Partitions, true)
+			writer.EmptyTaggedFields()
+		}
+		writer.EmptyTaggedFields()
+	}
+	writer.EmptyTaggedFields()
+
+	return writer.WithSizePrefix(), nil
+}
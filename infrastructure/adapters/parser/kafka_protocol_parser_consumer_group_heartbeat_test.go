@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func buildConsumerGroupHeartbeatRequest(version int16, joining bool) []byte {
+	w := common.NewKafkaWriter()
+	w.Int16(68)
+	w.Int16(version)
+	w.Int32(12)
+	w.String("consumer-1", false)
+	w.EmptyTaggedFields()
+	w.String("group-a", true)
+	w.String("member-1", true)
+	if joining {
+		w.Int32(0)
+		w.NullableString(nil, true)
+		rack := "rack-1"
+		w.NullableString(&rack, true)
+		w.Int32(45000)
+		w.StringArray([]string{"orders", "payments"}, true)
+		if version >= 1 {
+			regex := "audit-.*"
+			w.NullableString(&regex, true)
+		}
+		assignor := "range"
+		w.NullableString(&assignor, true)
+		w.ArrayLength(0, true)
+	} else {
+		w.Int32(3)
+		w.NullableString(nil, true)
+		w.NullableString(nil, true)
+		w.Int32(-1)
+		w.ArrayLength(-1, true)
+		if version >= 1 {
+			w.NullableString(nil, true)
+		}
+		w.NullableString(nil, true)
+		w.ArrayLength(1, true)
+		w.UUID(bytes.Repeat([]byte{0xaa}, 16))
+		w.Int32Array([]int32{0, 2}, true)
+		w.EmptyTaggedFields()
+	}
+	w.EmptyTaggedFields()
+	return w.WithSizePrefix()
+}
+
+func TestKafkaProtocolParserConsumerGroupHeartbeat_ParseRequest(t *testing.T) {
+	for _, version := range []int16{0, 1} {
+		parsed, err := NewKafkaProtocolParserConsumerGroupHeartbeat().ParseRequest(buildConsumerGroupHeartbeatRequest(version, true))
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+		want := &domain.ParsedRequestConsumerGroupHeartbeat{
+			APIKey:               68,
+			APIVersion:           int(version),
+			CorrelationID:        []byte{0x00, 0x00, 0x00, 0x0c},
+			ClientID:             "consumer-1",
+			GroupID:              "group-a",
+			MemberID:             "member-1",
+			RackID:               "rack-1",
+			RebalanceTimeoutMs:   45000,
+			SubscribedTopicNames: []string{"orders", "payments"},
+			ServerAssignor:       "range",
+			TopicPartitions:      []domain.ConsumerGroupTopicPartitions{},
+		}
+		if version >= 1 {
+			regex := "audit-.*"
+			want.SubscribedTopicRegex = &regex
+		}
+		if !reflect.DeepEqual(parsed, want) {
+			t.Errorf("v%d joining parsed = %+v, want %+v", version, parsed, want)
+		}
+
+		parsed, err = NewKafkaProtocolParserConsumerGroupHeartbeat().ParseRequest(buildConsumerGroupHeartbeatRequest(version, false))
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+		if parsed.MemberEpoch != 3 || parsed.RebalanceTimeoutMs != -1 || parsed.SubscribedTopicNames != nil || parsed.SubscribedTopicRegex != nil {
+			t.Errorf("v%d heartbeat parsed = %+v, want unchanged fields null", version, parsed)
+		}
+		wantOwned := []domain.ConsumerGroupTopicPartitions{{TopicID: bytes.Repeat([]byte{0xaa}, 16), Partitions: []int32{0, 2}}}
+		if !reflect.DeepEqual(parsed.TopicPartitions, wantOwned) {
+			t.Errorf("v%d owned partitions = %+v, want %+v", version, parsed.TopicPartitions, wantOwned)
+		}
+	}
+}
+
+func TestKafkaProtocolParserConsumerGroupHeartbeat_EncodeResponse(t *testing.T) {
+	assignment := []domain.ConsumerGroupTopicPartitions{{TopicID: bytes.Repeat([]byte{0xaa}, 16), Partitions: []int32{1, 3}}}
+	for _, response := range []domain.ResponseDataConsumerGroupHeartbeat{
+		{MemberID: "member-1", MemberEpoch: 4, HeartbeatIntervalMs: 5000, Assignment: assignment},
+		{MemberID: "member-1", MemberEpoch: 4, HeartbeatIntervalMs: 5000},
+		{ErrorCode: domain.ErrorCodeFencedMemberEpoch, ErrorMessage: "fenced"},
+	} {
+		response.CorrelationID = []byte{0x00, 0x00, 0x00, 0x0c}
+		encoded, err := NewKafkaProtocolParserConsumerGroupHeartbeat().EncodeResponse(&response)
+		if err != nil {
+			t.Fatalf("EncodeResponse() error = %v", err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		reader.SkipTaggedFields()
+		got := domain.ResponseDataConsumerGroupHeartbeat{CorrelationID: response.CorrelationID, ThrottleTimeMs: reader.Int32("ThrottleTimeMs")}
+		got.ErrorCode = reader.Int16("ErrorCode")
+		got.ErrorMessage = reader.String("ErrorMessage", true)
+		got.MemberID = reader.String("MemberId", true)
+		got.MemberEpoch = reader.Int32("MemberEpoch")
+		got.HeartbeatIntervalMs = reader.Int32("HeartbeatIntervalMs")
+		if reader.Int8("Assignment") == 1 {
+			length := reader.ArrayLength("TopicPartitions", true)
+			got.Assignment = []domain.ConsumerGroupTopicPartitions{}
+			for range length {
+				got.Assignment = append(got.Assignment, domain.ConsumerGroupTopicPartitions{
+					TopicID:    reader.UUID("TopicId"),
+					Partitions: reader.Int32Array("Partitions", true),
+				})
+				reader.SkipTaggedFields()
+			}
+			reader.SkipTaggedFields()
+		}
+		reader.SkipTaggedFields()
+		if !reflect.DeepEqual(got, response) {
+			t.Errorf("response = %+v, want %+v", got, response)
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("response error %v with %d trailing bytes", err, reader.Remaining())
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// CreatePartitions v2+ uses the flexible (compact) encodings
+const createPartitionsFirstFlexibleVersion = 2
+
+type KafkaProtocolParserCreatePartitions struct{}
+
+// NewKafkaProtocolParserCreatePartitions creates a new Kafka CreatePartitions protocol parser
+func NewKafkaProtocolParserCreatePartitions() parser.CreatePartitionsParser {
+	return &KafkaProtocolParserCreatePartitions{}
+}
+
+func (p *KafkaProtocolParserCreatePartitions) ParseRequest(data []byte) (*domain.ParsedRequestCreatePartitions, error) {
+	header, reader, err := parseRequestHeader(data, createPartitionsFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	flexible := isFlexible(header.APIVersion, createPartitionsFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestCreatePartitions{
+		APIKey:        header.APIKey,
+		APIVersion:    header.APIVersion,
+		CorrelationID: header.CorrelationID,
+		ClientID:      header.ClientID,
+	}
+
+	topicsLength := reader.ArrayLength("Topics", flexible)
+	for range topicsLength {
+		topic := domain.CreatePartitionsTopic{
+			Name:  reader.String("Name", flexible),
+			Count: reader.Int32("Count"),
+		}
+
+		// A null array leaves the placement of the new partitions to the broker
+		assignmentsLength := reader.ArrayLength("Assignments", flexible)
+		if assignmentsLength >= 0 {
+			topic.Assignments = make([][]int32, 0, assignmentsLength)
+		}
+		for range assignmentsLength {
+			topic.Assignments = append(topic.Assignments, reader.Int32Array("BrokerIds", flexible))
+			if flexible {
+				reader.SkipTaggedFields()
+			}
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		parsed.Topics = append(parsed.Topics, topic)
+	}
+
+	parsed.TimeoutMs = reader.Int32("TimeoutMs")
+	parsed.ValidateOnly = reader.Bool("ValidateOnly")
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("CreatePartitions", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserCreatePartitions) EncodeResponse(response *domain.ResponseDataCreatePartitions) ([]byte, error) {
+	flexible := isFlexible(response.APIVersion, createPartitionsFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	writer.Int32(response.ThrottleTimeMs)
+	writer.ArrayLength(len(response.Results), flexible)
+	for _, result := range response.Results {
+		writer.String(result.Name, flexible)
+		writer.Int16(result.ErrorCode)
+		writer.NullableString(nullIfEmpty(result.ErrorMessage), flexible)
+		if flexible {
+			writer.EmptyTaggedFields()
+		}
+	}
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
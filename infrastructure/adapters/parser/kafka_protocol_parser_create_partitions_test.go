@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func buildCreatePartitionsRequest(version int16) []byte {
+	flexible := version >= 2
+	w := common.NewKafkaWriter()
+	w.Int16(37)
+	w.Int16(version)
+	w.Int32(5)
+	w.String("admin", false)
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	w.ArrayLength(2, flexible)
+	w.String("foo", flexible)
+	w.Int32(4)
+	w.ArrayLength(-1, flexible) // Assignments
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	w.String("bar", flexible)
+	w.Int32(2)
+	w.ArrayLength(1, flexible)
+	w.Int32Array([]int32{1, 2}, flexible)
+	if flexible {
+		w.EmptyTaggedFields()
+		w.EmptyTaggedFields()
+	}
+	w.Int32(30000) // TimeoutMs
+	w.Bool(true)   // ValidateOnly
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	return w.WithSizePrefix()
+}
+
+func TestKafkaProtocolParserCreatePartitions_ParseRequest(t *testing.T) {
+	for _, version := range []int16{0, 1, 2, 3} {
+		parsed, err := NewKafkaProtocolParserCreatePartitions().ParseRequest(buildCreatePartitionsRequest(version))
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+
+		want := []domain.CreatePartitionsTopic{
+			{Name: "foo", Count: 4},
+			{Name: "bar", Count: 2, Assignments: [][]int32{{1, 2}}},
+		}
+		if !reflect.DeepEqual(parsed.Topics, want) {
+			t.Errorf("v%d Topics = %+v, want %+v", version, parsed.Topics, want)
+		}
+		if parsed.TimeoutMs != 30000 || !parsed.ValidateOnly {
+			t.Errorf("v%d TimeoutMs = %d, ValidateOnly = %v", version, parsed.TimeoutMs, parsed.ValidateOnly)
+		}
+	}
+}
+
+func TestKafkaProtocolParserCreatePartitions_EncodeResponse(t *testing.T) {
+	for _, version := range []int{0, 1, 2, 3} {
+		flexible := version >= 2
+		encoded, err := NewKafkaProtocolParserCreatePartitions().EncodeResponse(&domain.ResponseDataCreatePartitions{
+			CorrelationID: []byte{0x00, 0x00, 0x00, 0x05},
+			APIVersion:    version,
+			Results: []domain.CreatePartitionsTopicResult{
+				{Name: "foo"},
+				{Name: "bar", ErrorCode: domain.ErrorCodeInvalidPartitions, ErrorMessage: "Topic already has 2 partition(s)."},
+			},
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		reader.Int32("ThrottleTimeMs")
+		if length := reader.ArrayLength("Results", flexible); length != 2 {
+			t.Fatalf("v%d has %d results, want 2", version, length)
+		}
+		for i, wantName := range []string{"foo", "bar"} {
+			if name := reader.String("Name", flexible); name != wantName {
+				t.Errorf("v%d result %d Name = %q, want %q", version, i, name, wantName)
+			}
+			errorCode := reader.Int16("ErrorCode")
+			errorMessage, hasMessage := reader.NullableString("ErrorMessage", flexible)
+			if i == 0 && (errorCode != domain.ErrorCodeNone || hasMessage) {
+				t.Errorf("v%d foo error = %d %q, want none and a null message", version, errorCode, errorMessage)
+			}
+			if i == 1 && (errorCode != domain.ErrorCodeInvalidPartitions || errorMessage == "") {
+				t.Errorf("v%d bar error = %d %q, want INVALID_PARTITIONS with a message", version, errorCode, errorMessage)
+			}
+			if flexible {
+				reader.SkipTaggedFields()
+			}
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// CreateTopics v5+ uses the flexible (compact) encodings
+const createTopicsFirstFlexibleVersion = 5
+
+type KafkaProtocolParserCreateTopics struct{}
+
+// NewKafkaProtocolParserCreateTopics creates a new Kafka CreateTopics protocol parser
+func NewKafkaProtocolParserCreateTopics() parser.CreateTopicsParser {
+	return &KafkaProtocolParserCreateTopics{}
+}
+
+func (p *KafkaProtocolParserCreateTopics) ParseRequest(data []byte) (*domain.ParsedRequestCreateTopics, error) {
+	header, reader, err := parseRequestHeader(data, createTopicsFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	version := header.APIVersion
+	flexible := isFlexible(version, createTopicsFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestCreateTopics{
+		APIKey:        header.APIKey,
+		APIVersion:    header.APIVersion,
+		CorrelationID: header.CorrelationID,
+		ClientID:      header.ClientID,
+	}
+
+	topicsLength := reader.ArrayLength("Topics", flexible)
+	for range topicsLength {
+		topic := domain.CreatableTopic{
+			Name:              reader.String("Name", flexible),
+			NumPartitions:     reader.Int32("NumPartitions"),
+			ReplicationFactor: reader.Int16("ReplicationFactor"),
+		}
+
+		assignmentsLength := reader.ArrayLength("Assignments", flexible)
+		for range assignmentsLength {
+			topic.Assignments = append(topic.Assignments, domain.CreatableReplicaAssignment{
+				PartitionIndex: reader.Int32("PartitionIndex"),
+				BrokerIDs:      reader.Int32Array("BrokerIds", flexible),
+			})
+			if flexible {
+				reader.SkipTaggedFields()
+			}
+		}
+
+		configsLength := reader.ArrayLength("Configs", flexible)
+		for range configsLength {
+			config := domain.CreatableTopicConfig{Name: reader.String("Config Name", flexible)}
+			var exists bool
+			config.Value, exists = reader.NullableString("Config Value", flexible)
+			config.IsNull = !exists
+			if flexible {
+				reader.SkipTaggedFields()
+			}
+			topic.Configs = append(topic.Configs, config)
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		parsed.Topics = append(parsed.Topics, topic)
+	}
+
+	parsed.TimeoutMs = reader.Int32("TimeoutMs")
+	if version >= 1 {
+		parsed.ValidateOnly = reader.Bool("ValidateOnly")
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("CreateTopics", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserCreateTopics) EncodeResponse(response *domain.ResponseDataCreateTopics) ([]byte, error) {
+	version := response.APIVersion
+	flexible := isFlexible(version, createTopicsFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	if version >= 2 {
+		writer.Int32(response.ThrottleTimeMs)
+	}
+
+	writer.ArrayLength(len(response.Topics), flexible)
+	for _, topic := range response.Topics {
+		writer.String(topic.Name, flexible)
+		if version >= 7 {
+			writer.UUID(topic.TopicID)
+		}
+		writer.Int16(topic.ErrorCode)
+		if version >= 1 {
+			writer.NullableString(nullIfEmpty(topic.ErrorMessage), flexible)
+		}
+		if version >= 5 {
+			writer.Int32(topic.NumPartitions)
+			writer.Int16(topic.ReplicationFactor)
+			if topic.ErrorCode != domain.ErrorCodeNone {
+				writer.ArrayLength(-1, flexible)
+			} else {
+				writer.ArrayLength(len(topic.Configs), flexible)
+				for _, config := range topic.Configs {
+					writer.String(config.Name, flexible)
+					writer.String(config.Value, flexible)
+					writer.Bool(config.ReadOnly)
+					writer.Int8(config.ConfigSource)
+					writer.Bool(config.IsSensitive)
+					writer.EmptyTaggedFields()
+				}
+			}
+		}
+		if flexible {
+			writer.EmptyTaggedFields()
+		}
+	}
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
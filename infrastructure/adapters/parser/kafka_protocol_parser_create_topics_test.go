@@ -0,0 +1,159 @@
+package parser
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func buildCreateTopicsRequest(version int16) []byte {
+	flexible := version >= 5
+	w := common.NewKafkaWriter()
+	w.Int16(19)
+	w.Int16(version)
+	w.Int32(3)
+	w.String("admin", false)
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	w.ArrayLength(2, flexible)
+	w.String("foo", flexible)
+	w.Int32(3)
+	w.Int16(1)
+	w.ArrayLength(0, flexible) // Assignments
+	w.ArrayLength(2, flexible)
+	retention := "1000"
+	w.String("retention.ms", flexible)
+	w.NullableString(&retention, flexible)
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	w.String("cleanup.policy", flexible)
+	w.NullableString(nil, flexible)
+	if flexible {
+		w.EmptyTaggedFields()
+		w.EmptyTaggedFields()
+	}
+	w.String("bar", flexible)
+	w.Int32(-1)
+	w.Int16(-1)
+	w.ArrayLength(1, flexible)
+	w.Int32(0)
+	w.Int32Array([]int32{1, 2}, flexible)
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	w.ArrayLength(0, flexible) // Configs
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	w.Int32(30000) // TimeoutMs
+	if version >= 1 {
+		w.Bool(true) // ValidateOnly
+	}
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	return w.WithSizePrefix()
+}
+
+func TestKafkaProtocolParserCreateTopics_ParseRequest(t *testing.T) {
+	for _, version := range []int16{0, 2, 4, 5, 7} {
+		parsed, err := NewKafkaProtocolParserCreateTopics().ParseRequest(buildCreateTopicsRequest(version))
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+
+		want := []domain.CreatableTopic{
+			{Name: "foo", NumPartitions: 3, ReplicationFactor: 1, Configs: []domain.CreatableTopicConfig{
+				{Name: "retention.ms", Value: "1000"},
+				{Name: "cleanup.policy", IsNull: true},
+			}},
+			{Name: "bar", NumPartitions: -1, ReplicationFactor: -1, Assignments: []domain.CreatableReplicaAssignment{
+				{PartitionIndex: 0, BrokerIDs: []int32{1, 2}},
+			}},
+		}
+		if !reflect.DeepEqual(parsed.Topics, want) {
+			t.Errorf("v%d Topics = %+v, want %+v", version, parsed.Topics, want)
+		}
+		if parsed.TimeoutMs != 30000 || parsed.ValidateOnly != (version >= 1) {
+			t.Errorf("v%d TimeoutMs = %d, ValidateOnly = %v", version, parsed.TimeoutMs, parsed.ValidateOnly)
+		}
+	}
+}
+
+func TestKafkaProtocolParserCreateTopics_EncodeResponse(t *testing.T) {
+	topicID := bytes.Repeat([]byte{0xab}, 16)
+	for _, version := range []int{2, 4, 5, 7} {
+		flexible := version >= 5
+		encoded, err := NewKafkaProtocolParserCreateTopics().EncodeResponse(&domain.ResponseDataCreateTopics{
+			CorrelationID: []byte{0x00, 0x00, 0x00, 0x03},
+			APIVersion:    version,
+			Topics: []domain.CreatableTopicResult{
+				{Name: "foo", TopicID: topicID, NumPartitions: 3, ReplicationFactor: 1, Configs: []domain.CreatableTopicConfigResult{
+					{Name: "retention.ms", Value: "1000", ConfigSource: domain.ConfigSourceDynamicTopicConfig},
+				}},
+				{Name: "bar", TopicID: make([]byte, 16), ErrorCode: domain.ErrorCodeTopicAlreadyExists, ErrorMessage: "Topic 'bar' already exists.", NumPartitions: -1, ReplicationFactor: -1},
+			},
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		reader.Int32("ThrottleTimeMs")
+		if length := reader.ArrayLength("Topics", flexible); length != 2 {
+			t.Fatalf("v%d has %d topics, want 2", version, length)
+		}
+		for i, wantName := range []string{"foo", "bar"} {
+			if name := reader.String("Name", flexible); name != wantName {
+				t.Errorf("v%d topic %d Name = %q, want %q", version, i, name, wantName)
+			}
+			if version >= 7 {
+				if id := reader.UUID("TopicId"); i == 0 && !bytes.Equal(id, topicID) {
+					t.Errorf("v%d TopicId = %x, want %x", version, id, topicID)
+				}
+			}
+			errorCode := reader.Int16("ErrorCode")
+			errorMessage, hasMessage := reader.NullableString("ErrorMessage", flexible)
+			if i == 1 && (errorCode != domain.ErrorCodeTopicAlreadyExists || !hasMessage || errorMessage == "") {
+				t.Errorf("v%d bar error = %d %q, want TOPIC_ALREADY_EXISTS with a message", version, errorCode, errorMessage)
+			}
+			if i == 0 && hasMessage {
+				t.Errorf("v%d foo ErrorMessage = %q, want null", version, errorMessage)
+			}
+			if version >= 5 {
+				reader.Int32("NumPartitions")
+				reader.Int16("ReplicationFactor")
+				configsLength := reader.ArrayLength("Configs", flexible)
+				if i == 1 && configsLength != -1 {
+					t.Errorf("v%d bar has %d configs, want null", version, configsLength)
+				}
+				for range configsLength {
+					reader.String("Config Name", flexible)
+					reader.NullableString("Config Value", flexible)
+					reader.Bool("ReadOnly")
+					if source := reader.Int8("ConfigSource"); source != domain.ConfigSourceDynamicTopicConfig {
+						t.Errorf("v%d ConfigSource = %d, want %d", version, source, domain.ConfigSourceDynamicTopicConfig)
+					}
+					reader.Bool("IsSensitive")
+					reader.SkipTaggedFields()
+				}
+				reader.SkipTaggedFields()
+			}
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// DeleteGroups v2+ uses the flexible (compact) encodings
+const deleteGroupsFirstFlexibleVersion = 2
+
+type KafkaProtocolParserDeleteGroups struct{}
+
+// NewKafkaProtocolParserDeleteGroups creates a new Kafka DeleteGroups protocol parser
+func NewKafkaProtocolParserDeleteGroups() parser.DeleteGroupsParser {
+	return &KafkaProtocolParserDeleteGroups{}
+}
+
+func (p *KafkaProtocolParserDeleteGroups) ParseRequest(data []byte) (*domain.ParsedRequestDeleteGroups, error) {
+	header, reader, err := parseRequestHeader(data, deleteGroupsFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	flexible := isFlexible(header.APIVersion, deleteGroupsFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestDeleteGroups{
+		APIKey:        header.APIKey,
+		APIVersion:    header.APIVersion,
+		CorrelationID: header.CorrelationID,
+		ClientID:      header.ClientID,
+		GroupsNames:   reader.StringArray("GroupsNames", flexible),
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("DeleteGroups", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserDeleteGroups) EncodeResponse(response *domain.ResponseDataDeleteGroups) ([]byte, error) {
+	flexible := isFlexible(response.APIVersion, deleteGroupsFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	writer.Int32(response.ThrottleTimeMs)
+	writer.ArrayLength(len(response.Results), flexible)
+	for _, result := range response.Results {
+		writer.String(result.GroupID, flexible)
+		writer.Int16(result.ErrorCode)
+		if flexible {
+			writer.EmptyTaggedFields()
+		}
+	}
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func buildDeleteGroupsRequest(version int16) []byte {
+	flexible := version >= 2
+	w := common.NewKafkaWriter()
+	w.Int16(42)
+	w.Int16(version)
+	w.Int32(7)
+	w.String("admin-1", false)
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	w.StringArray([]string{"group-a", "group-b"}, flexible)
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	return w.WithSizePrefix()
+}
+
+func TestKafkaProtocolParserDeleteGroups_ParseRequest(t *testing.T) {
+	for _, version := range []int16{0, 1, 2} {
+		parsed, err := NewKafkaProtocolParserDeleteGroups().ParseRequest(buildDeleteGroupsRequest(version))
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+		if !reflect.DeepEqual(parsed.GroupsNames, []string{"group-a", "group-b"}) {
+			t.Errorf("v%d groups = %v, want group-a and group-b", version, parsed.GroupsNames)
+		}
+	}
+}
+
+func TestKafkaProtocolParserDeleteGroups_EncodeResponse(t *testing.T) {
+	results := []domain.DeleteGroupsResult{
+		{GroupID: "group-a"},
+		{GroupID: "group-b", ErrorCode: domain.ErrorCodeNonEmptyGroup},
+	}
+	for _, version := range []int{0, 1, 2} {
+		flexible := version >= 2
+		encoded, err := NewKafkaProtocolParserDeleteGroups().EncodeResponse(&domain.ResponseDataDeleteGroups{
+			CorrelationID: []byte{0x00, 0x00, 0x00, 0x07},
+			APIVersion:    version,
+			Results:       results,
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		reader.Int32("ThrottleTimeMs")
+		got := []domain.DeleteGroupsResult{}
+		length := reader.ArrayLength("Results", flexible)
+		for range length {
+			got = append(got, domain.DeleteGroupsResult{GroupID: reader.String("GroupId", flexible), ErrorCode: reader.Int16("ErrorCode")})
+			if flexible {
+				reader.SkipTaggedFields()
+			}
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if !reflect.DeepEqual(got, results) {
+			t.Errorf("v%d results = %+v, want %+v", version, got, results)
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
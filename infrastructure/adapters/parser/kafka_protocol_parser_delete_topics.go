@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// DeleteTopics v4+ uses the flexible (compact) encodings
+const deleteTopicsFirstFlexibleVersion = 4
+
+type KafkaProtocolParserDeleteTopics struct{}
+
+// NewKafkaProtocolParserDeleteTopics creates a new Kafka DeleteTopics protocol parser
+func NewKafkaProtocolParserDeleteTopics() parser.DeleteTopicsParser {
+	return &KafkaProtocolParserDeleteTopics{}
+}
+
+func (p *KafkaProtocolParserDeleteTopics) ParseRequest(data []byte) (*domain.ParsedRequestDeleteTopics, error) {
+	header, reader, err := parseRequestHeader(data, deleteTopicsFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	version := header.APIVersion
+	flexible := isFlexible(version, deleteTopicsFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestDeleteTopics{
+		APIKey:        header.APIKey,
+		APIVersion:    header.APIVersion,
+		CorrelationID: header.CorrelationID,
+		ClientID:      header.ClientID,
+	}
+
+	if version >= 6 {
+		topicsLength := reader.ArrayLength("Topics", flexible)
+		for range topicsLength {
+			topic := domain.DeleteTopicState{}
+			topic.Name, _ = reader.NullableString("Name", flexible)
+			topic.TopicID = reader.UUID("TopicId")
+			reader.SkipTaggedFields()
+			parsed.Topics = append(parsed.Topics, topic)
+		}
+	} else {
+		for _, name := range reader.StringArray("TopicNames", flexible) {
+			parsed.Topics = append(parsed.Topics, domain.DeleteTopicState{Name: name})
+		}
+	}
+	parsed.TimeoutMs = reader.Int32("TimeoutMs")
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("DeleteTopics", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserDeleteTopics) EncodeResponse(response *domain.ResponseDataDeleteTopics) ([]byte, error) {
+	version := response.APIVersion
+	flexible := isFlexible(version, deleteTopicsFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	if version >= 1 {
+		writer.Int32(response.ThrottleTimeMs)
+	}
+
+	writer.ArrayLength(len(response.Responses), flexible)
+	for _, topic := range response.Responses {
+		if version >= 6 {
+			writer.NullableString(nullIfEmpty(topic.Name), flexible)
+			writer.UUID(topic.TopicID)
+		} else {
+			writer.String(topic.Name, flexible)
+		}
+		writer.Int16(topic.ErrorCode)
+		if version >= 5 {
+			writer.NullableString(nullIfEmpty(topic.ErrorMessage), flexible)
+		}
+		if flexible {
+			writer.EmptyTaggedFields()
+		}
+	}
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
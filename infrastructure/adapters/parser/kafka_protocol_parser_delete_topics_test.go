@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+var deleteTopicID = bytes.Repeat([]byte{0xcd}, 16)
+
+func buildDeleteTopicsRequest(version int16) []byte {
+	flexible := version >= 4
+	w := common.NewKafkaWriter()
+	w.Int16(20)
+	w.Int16(version)
+	w.Int32(3)
+	w.String("admin", false)
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	if version >= 6 {
+		w.ArrayLength(2, flexible)
+		w.String("foo", flexible)
+		w.UUID(nil)
+		w.EmptyTaggedFields()
+		w.NullableString(nil, flexible)
+		w.UUID(deleteTopicID)
+		w.EmptyTaggedFields()
+	} else {
+		w.StringArray([]string{"foo", "bar"}, flexible)
+	}
+	w.Int32(30000) // TimeoutMs
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	return w.WithSizePrefix()
+}
+
+func TestKafkaProtocolParserDeleteTopics_ParseRequest(t *testing.T) {
+	for _, version := range []int16{1, 3, 4, 5, 6} {
+		parsed, err := NewKafkaProtocolParserDeleteTopics().ParseRequest(buildDeleteTopicsRequest(version))
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+
+		want := []domain.DeleteTopicState{{Name: "foo"}, {Name: "bar"}}
+		if version >= 6 {
+			want = []domain.DeleteTopicState{{Name: "foo", TopicID: make([]byte, 16)}, {TopicID: deleteTopicID}}
+		}
+		if !reflect.DeepEqual(parsed.Topics, want) {
+			t.Errorf("v%d Topics = %+v, want %+v", version, parsed.Topics, want)
+		}
+		if parsed.TimeoutMs != 30000 {
+			t.Errorf("v%d TimeoutMs = %d, want 30000", version, parsed.TimeoutMs)
+		}
+	}
+}
+
+func TestKafkaProtocolParserDeleteTopics_EncodeResponse(t *testing.T) {
+	for _, version := range []int{1, 4, 5, 6} {
+		flexible := version >= 4
+		encoded, err := NewKafkaProtocolParserDeleteTopics().EncodeResponse(&domain.ResponseDataDeleteTopics{
+			CorrelationID: []byte{0x00, 0x00, 0x00, 0x03},
+			APIVersion:    version,
+			Responses: []domain.DeletableTopicResult{
+				{Name: "foo", TopicID: deleteTopicID},
+				{Name: "bar", ErrorCode: domain.ErrorCodeUnknownTopicOrPartition, ErrorMessage: "This server does not host this topic-partition."},
+			},
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		reader.Int32("ThrottleTimeMs")
+		if length := reader.ArrayLength("Responses", flexible); length != 2 {
+			t.Fatalf("v%d has %d responses, want 2", version, length)
+		}
+		for i, wantName := range []string{"foo", "bar"} {
+			if name := reader.String("Name", flexible); name != wantName {
+				t.Errorf("v%d response %d Name = %q, want %q", version, i, name, wantName)
+			}
+			if version >= 6 {
+				if id := reader.UUID("TopicId"); i == 0 && !bytes.Equal(id, deleteTopicID) {
+					t.Errorf("v%d TopicId = %x, want %x", version, id, deleteTopicID)
+				}
+			}
+			if errorCode := reader.Int16("ErrorCode"); i == 1 && errorCode != domain.ErrorCodeUnknownTopicOrPartition {
+				t.Errorf("v%d bar ErrorCode = %d, want %d", version, errorCode, domain.ErrorCodeUnknownTopicOrPartition)
+			}
+			if version >= 5 {
+				if _, hasMessage := reader.NullableString("ErrorMessage", flexible); hasMessage != (i == 1) {
+					t.Errorf("v%d response %d has message = %v", version, i, hasMessage)
+				}
+			}
+			if flexible {
+				reader.SkipTaggedFields()
+			}
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
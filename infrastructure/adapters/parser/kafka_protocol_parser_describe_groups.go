@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// DescribeGroups v5+ uses the flexible (compact) encodings
+const describeGroupsFirstFlexibleVersion = 5
+
+type KafkaProtocolParserDescribeGroups struct{}
+
+// NewKafkaProtocolParserDescribeGroups creates a new Kafka DescribeGroups protocol parser
+func NewKafkaProtocolParserDescribeGroups() parser.DescribeGroupsParser {
+	return &KafkaProtocolParserDescribeGroups{}
+}
+
+func (p *KafkaProtocolParserDescribeGroups) ParseRequest(data []byte) (*domain.ParsedRequestDescribeGroups, error) {
+	header, reader, err := parseRequestHeader(data, describeGroupsFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	version := header.APIVersion
+	flexible := isFlexible(version, describeGroupsFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestDescribeGroups{
+		APIKey:        header.APIKey,
+		APIVersion:    header.APIVersion,
+		CorrelationID: header.CorrelationID,
+		ClientID:      header.ClientID,
+		Groups:        reader.StringArray("Groups", flexible),
+	}
+	if version >= 3 {
+		parsed.IncludeAuthorizedOperations = reader.Bool("IncludeAuthorizedOperations")
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("DescribeGroups", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserDescribeGroups) EncodeResponse(response *domain.ResponseDataDescribeGroups) ([]byte, error) {
+	version := response.APIVersion
+	flexible := isFlexible(version, describeGroupsFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	if version >= 1 {
+		writer.Int32(response.ThrottleTimeMs)
+	}
+	writer.ArrayLength(len(response.Groups), flexible)
+	for _, group := range response.Groups {
+		writer.Int16(group.ErrorCode)
+		writer.String(group.GroupID, flexible)
+		writer.String(group.GroupState, flexible)
+		writer.String(group.ProtocolType, flexible)
+		writer.String(group.ProtocolData, flexible)
+		writer.ArrayLength(len(group.Members), flexible)
+		for _, member := range group.Members {
+			writer.String(member.MemberID, flexible)
+			if version >= 4 {
+				writer.NullableString(nullIfEmpty(member.GroupInstanceID), flexible)
+			}
+			writer.String(member.ClientID, flexible)
+			writer.String(member.ClientHost, flexible)
+			writer.BytesField(nonNilBytes(member.MemberMetadata), flexible)
+			writer.BytesField(nonNilBytes(member.MemberAssignment), flexible)
+			if flexible {
+				writer.EmptyTaggedFields()
+			}
+		}
+		if version >= 3 {
+			writer.Int32(group.AuthorizedOperations)
+		}
+		if flexible {
+			writer.EmptyTaggedFields()
+		}
+	}
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func buildDescribeGroupsRequest(version int16) []byte {
+	flexible := version >= 5
+	w := common.NewKafkaWriter()
+	w.Int16(15)
+	w.Int16(version)
+	w.Int32(6)
+	w.String("admin-1", false)
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	w.StringArray([]string{"group-a", "group-b"}, flexible)
+	if version >= 3 {
+		w.Bool(true)
+	}
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	return w.WithSizePrefix()
+}
+
+func TestKafkaProtocolParserDescribeGroups_ParseRequest(t *testing.T) {
+	for _, version := range []int16{0, 3, 5} {
+		parsed, err := NewKafkaProtocolParserDescribeGroups().ParseRequest(buildDescribeGroupsRequest(version))
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+		if !reflect.DeepEqual(parsed.Groups, []string{"group-a", "group-b"}) || parsed.IncludeAuthorizedOperations != (version >= 3) {
+			t.Errorf("v%d parsed = %+v", version, parsed)
+		}
+	}
+}
+
+func TestKafkaProtocolParserDescribeGroups_EncodeResponse(t *testing.T) {
+	group := domain.DescribedGroup{
+		GroupID:      "group-a",
+		GroupState:   "Stable",
+		ProtocolType: "consumer",
+		ProtocolData: "range",
+		Members: []domain.DescribedGroupMember{{
+			MemberID:         "member-1",
+			GroupInstanceID:  "instance-1",
+			ClientID:         "consumer-1",
+			ClientHost:       "/127.0.0.1",
+			MemberMetadata:   []byte{0x01},
+			MemberAssignment: []byte{0x02, 0x03},
+		}},
+		AuthorizedOperations: 0x148,
+	}
+	for _, version := range []int{0, 1, 3, 4, 5} {
+		flexible := version >= 5
+		encoded, err := NewKafkaProtocolParserDescribeGroups().EncodeResponse(&domain.ResponseDataDescribeGroups{
+			CorrelationID: []byte{0x00, 0x00, 0x00, 0x06},
+			APIVersion:    version,
+			Groups:        []domain.DescribedGroup{group},
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if version >= 1 {
+			reader.Int32("ThrottleTimeMs")
+		}
+		if length := reader.ArrayLength("Groups", flexible); length != 1 {
+			t.Fatalf("v%d %d groups, want 1", version, length)
+		}
+		got := domain.DescribedGroup{
+			ErrorCode:    reader.Int16("ErrorCode"),
+			GroupID:      reader.String("GroupId", flexible),
+			GroupState:   reader.String("GroupState", flexible),
+			ProtocolType: reader.String("ProtocolType", flexible),
+			ProtocolData: reader.String("ProtocolData", flexible),
+		}
+		length := reader.ArrayLength("Members", flexible)
+		for range length {
+			member := domain.DescribedGroupMember{MemberID: reader.String("MemberId", flexible)}
+			if version >= 4 {
+				member.GroupInstanceID, _ = reader.NullableString("GroupInstanceId", flexible)
+			}
+			member.ClientID = reader.String("ClientId", flexible)
+			member.ClientHost = reader.String("ClientHost", flexible)
+			member.MemberMetadata = reader.Bytes("MemberMetadata", flexible)
+			member.MemberAssignment = reader.Bytes("MemberAssignment", flexible)
+			if flexible {
+				reader.SkipTaggedFields()
+			}
+			got.Members = append(got.Members, member)
+		}
+		if version >= 3 {
+			got.AuthorizedOperations = reader.Int32("AuthorizedOperations")
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+			reader.SkipTaggedFields()
+		}
+
+		want := group
+		want.Members = []domain.DescribedGroupMember{group.Members[0]}
+		if version < 4 {
+			want.Members[0].GroupInstanceID = ""
+		}
+		if version < 3 {
+			want.AuthorizedOperations = 0
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("v%d group = %+v, want %+v", version, got, want)
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
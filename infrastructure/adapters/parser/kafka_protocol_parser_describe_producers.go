@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// Every DescribeProducers version uses the flexible (compact) encodings
+const describeProducersFirstFlexibleVersion = 0
+
+type KafkaProtocolParserDescribeProducers struct{}
+
+// NewKafkaProtocolParserDescribeProducers creates a new Kafka DescribeProducers protocol parser
+func NewKafkaProtocolParserDescribeProducers() parser.DescribeProducersParser {
+	return &KafkaProtocolParserDescribeProducers{}
+}
+
+func (p *KafkaProtocolParserDescribeProducers) ParseRequest(data []byte) (*domain.ParsedRequestDescribeProducers, error) {
+	header, reader, err := parseRequestHeader(data, describeProducersFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := &domain.ParsedRequestDescribeProducers{
+		APIKey:        header.APIKey,
+		APIVersion:    header.APIVersion,
+		CorrelationID: header.CorrelationID,
+		ClientID:      header.ClientID,
+	}
+	topicCount := reader.ArrayLength("Topics", true)
+	for range topicCount {
+		topic := domain.DescribeProducersTopic{
+			Name:             reader.String("Name", true),
+			PartitionIndexes: reader.Int32Array("PartitionIndexes", true),
+		}
+		reader.SkipTaggedFields()
+		parsed.Topics = append(parsed.Topics, topic)
+	}
+	reader.SkipTaggedFields()
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("DescribeProducers", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserDescribeProducers) EncodeResponse(response *domain.ResponseDataDescribeProducers) ([]byte, error) {
+	writer := newResponseWriter(response.CorrelationID, true)
+
+	writer.Int32(response.ThrottleTimeMs)
+	writer.ArrayLength(len(response.Topics), true)
+	for _, topic := range response.Topics {
+		writer.String(topic.Name, true)
+		writer.ArrayLength(len(topic.Partitions), true)
+		for _, partition := range topic.Partitions {
+			writer.Int32(partition.PartitionIndex)
+			writer.Int16(partition.ErrorCode)
+			writer.NullableString(partition.ErrorMessage, true)
+			writer.ArrayLength(len(partition.ActiveProducers), true)
+			for _, producer := range partition.ActiveProducers {
+				writer.Int64(producer.ProducerID)
+				writer.Int32(producer.ProducerEpoch)
+				writer.Int32(producer.LastSequence)
+				writer.Int64(producer.LastTimestamp)
+				writer.Int32(producer.CoordinatorEpoch)
+				writer.Int64(producer.CurrentTxnStartOffset)
+				writer.EmptyTaggedFields()
+			}
+			writer.EmptyTaggedFields()
+		}
+		writer.EmptyTaggedFields()
+	}
+	writer.EmptyTaggedFields()
+
+	return writer.WithSizePrefix(), nil
+}
@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func TestKafkaProtocolParserDescribeProducers_ParseRequest(t *testing.T) {
+	w := common.NewKafkaWriter()
+	w.Int16(61)
+	w.Int16(0)
+	w.Int32(5)
+	w.String("admin-1", false)
+	w.EmptyTaggedFields()
+	w.ArrayLength(1, true)
+	w.String("orders", true)
+	w.Int32Array([]int32{0, 2}, true)
+	w.EmptyTaggedFields()
+	w.EmptyTaggedFields()
+
+	parsed, err := NewKafkaProtocolParserDescribeProducers().ParseRequest(w.WithSizePrefix())
+	if err != nil {
+		t.Fatalf("ParseRequest() error = %v", err)
+	}
+	want := []domain.DescribeProducersTopic{{Name: "orders", PartitionIndexes: []int32{0, 2}}}
+	if !reflect.DeepEqual(parsed.Topics, want) {
+		t.Errorf("Topics = %+v, want %+v", parsed.Topics, want)
+	}
+}
+
+func TestKafkaProtocolParserDescribeProducers_EncodeResponse(t *testing.T) {
+	message := "This server does not host this topic-partition."
+	topics := []domain.DescribeProducersTopicResponse{{
+		Name: "orders",
+		Partitions: []domain.DescribeProducersPartitionResponse{
+			{PartitionIndex: 0, ActiveProducers: []domain.ProducerState{
+				{ProducerID: 7, ProducerEpoch: 2, LastSequence: 41, LastTimestamp: 1700000000000, CoordinatorEpoch: -1, CurrentTxnStartOffset: 12},
+			}},
+			{PartitionIndex: 2, ErrorCode: domain.ErrorCodeUnknownTopicOrPartition, ErrorMessage: &message},
+		},
+	}}
+	encoded, err := NewKafkaProtocolParserDescribeProducers().EncodeResponse(&domain.ResponseDataDescribeProducers{
+		CorrelationID: []byte{0x00, 0x00, 0x00, 0x05},
+		Topics:        topics,
+	})
+	if err != nil {
+		t.Fatalf("EncodeResponse() error = %v", err)
+	}
+
+	reader := common.NewKafkaReader(encoded, 4)
+	reader.Int32("CorrelationID")
+	reader.SkipTaggedFields()
+	reader.Int32("ThrottleTimeMs")
+	got := []domain.DescribeProducersTopicResponse{}
+	topicCount := reader.ArrayLength("Topics", true)
+	for range topicCount {
+		topic := domain.DescribeProducersTopicResponse{Name: reader.String("Name", true)}
+		partitionCount := reader.ArrayLength("Partitions", true)
+		for range partitionCount {
+			partition := domain.DescribeProducersPartitionResponse{
+				PartitionIndex: reader.Int32("PartitionIndex"),
+				ErrorCode:      reader.Int16("ErrorCode"),
+			}
+			if errorMessage, present := reader.NullableString("ErrorMessage", true); present {
+				partition.ErrorMessage = &errorMessage
+			}
+			producerCount := reader.ArrayLength("ActiveProducers", true)
+			for range producerCount {
+				partition.ActiveProducers = append(partition.ActiveProducers, domain.ProducerState{
+					ProducerID:            reader.Int64("ProducerId"),
+					ProducerEpoch:         reader.Int32("ProducerEpoch"),
+					LastSequence:          reader.Int32("LastSequence"),
+					LastTimestamp:         reader.Int64("LastTimestamp"),
+					CoordinatorEpoch:      reader.Int32("CoordinatorEpoch"),
+					CurrentTxnStartOffset: reader.Int64("CurrentTxnStartOffset"),
+				})
+				reader.SkipTaggedFields()
+			}
+			reader.SkipTaggedFields()
+			topic.Partitions = append(topic.Partitions, partition)
+		}
+		reader.SkipTaggedFields()
+		got = append(got, topic)
+	}
+	reader.SkipTaggedFields()
+
+	if !reflect.DeepEqual(got, topics) {
+		t.Errorf("Topics = %+v, want %+v", got, topics)
+	}
+	if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+		t.Errorf("response error %v with %d trailing bytes", err, reader.Remaining())
+	}
+}
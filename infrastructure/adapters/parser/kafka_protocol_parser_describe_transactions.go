@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// Every DescribeTransactions version uses the flexible (compact) encodings
+const describeTransactionsFirstFlexibleVersion = 0
+
+type KafkaProtocolParserDescribeTransactions struct{}
+
+// NewKafkaProtocolParserDescribeTransactions creates a new Kafka DescribeTransactions protocol parser
+func NewKafkaProtocolParserDescribeTransactions() parser.DescribeTransactionsParser {
+	return &KafkaProtocolParserDescribeTransactions{}
+}
+
+func (p *KafkaProtocolParserDescribeTransactions) ParseRequest(data []byte) (*domain.ParsedRequestDescribeTransactions, error) {
+	header, reader, err := parseRequestHeader(data, describeTransactionsFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := &domain.ParsedRequestDescribeTransactions{
+		APIKey:           header.APIKey,
+		APIVersion:       header.APIVersion,
+		CorrelationID:    header.CorrelationID,
+		ClientID:         header.ClientID,
+		TransactionalIDs: reader.StringArray("TransactionalIds", true),
+	}
+	reader.SkipTaggedFields()
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("DescribeTransactions", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserDescribeTransactions) EncodeResponse(response *domain.ResponseDataDescribeTransactions) ([]byte, error) {
+	writer := newResponseWriter(response.CorrelationID, true)
+
+	writer.Int32(response.ThrottleTimeMs)
+	writer.ArrayLength(len(response.TransactionStates), true)
+	for _, state := range response.TransactionStates {
+		writer.Int16(state.ErrorCode)
+		writer.String(state.TransactionalID, true)
+		writer.String(state.TransactionState, true)
+		writer.Int32(state.TransactionTimeoutMs)
+		writer.Int64(state.TransactionStartTimeMs)
+		writer.Int64(state.ProducerID)
+		writer.Int16(state.ProducerEpoch)
+		writer.ArrayLength(len(state.Topics), true)
+		for _, topic := range state.Topics {
+			writer.String(topic.Topic, true)
+			writer.Int32Array(topic.Partitions, true)
+			writer.EmptyTaggedFields()
+		}
+		writer.EmptyTaggedFields()
+	}
+	writer.EmptyTaggedFields()
+
+	return writer.WithSizePrefix(), nil
+}
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func TestKafkaProtocolParserDescribeTransactions_ParseRequest(t *testing.T) {
+	w := common.NewKafkaWriter()
+	w.Int16(65)
+	w.Int16(0)
+	w.Int32(5)
+	w.String("admin-1", false)
+	w.EmptyTaggedFields()
+	w.StringArray([]string{"txn-1", "txn-2"}, true)
+	w.EmptyTaggedFields()
+
+	parsed, err := NewKafkaProtocolParserDescribeTransactions().ParseRequest(w.WithSizePrefix())
+	if err != nil {
+		t.Fatalf("ParseRequest() error = %v", err)
+	}
+	if want := []string{"txn-1", "txn-2"}; !reflect.DeepEqual(parsed.TransactionalIDs, want) {
+		t.Errorf("TransactionalIDs = %v, want %v", parsed.TransactionalIDs, want)
+	}
+}
+
+func TestKafkaProtocolParserDescribeTransactions_EncodeResponse(t *testing.T) {
+	states := []domain.DescribedTransaction{
+		{
+			TransactionalID:        "txn-1",
+			TransactionState:       "Ongoing",
+			TransactionTimeoutMs:   60000,
+			TransactionStartTimeMs: 1700000000000,
+			ProducerID:             7,
+			ProducerEpoch:          3,
+			Topics:                 []domain.DescribedTransactionTopic{{Topic: "orders", Partitions: []int32{0, 1}}},
+		},
+		{ErrorCode: domain.ErrorCodeTransactionalIDNotFound, TransactionalID: "txn-2", TransactionStartTimeMs: -1, ProducerID: -1, ProducerEpoch: -1},
+	}
+	encoded, err := NewKafkaProtocolParserDescribeTransactions().EncodeResponse(&domain.ResponseDataDescribeTransactions{
+		CorrelationID:     []byte{0x00, 0x00, 0x00, 0x05},
+		TransactionStates: states,
+	})
+	if err != nil {
+		t.Fatalf("EncodeResponse() error = %v", err)
+	}
+
+	reader := common.NewKafkaReader(encoded, 4)
+	reader.Int32("CorrelationID")
+	reader.SkipTaggedFields()
+	reader.Int32("ThrottleTimeMs")
+	got := []domain.DescribedTransaction{}
+	stateCount := reader.ArrayLength("TransactionStates", true)
+	for range stateCount {
+		state := domain.DescribedTransaction{
+			ErrorCode:              reader.Int16("ErrorCode"),
+			TransactionalID:        reader.String("TransactionalId", true),
+			TransactionState:       reader.String("TransactionState", true),
+			TransactionTimeoutMs:   reader.Int32("TransactionTimeoutMs"),
+			TransactionStartTimeMs: reader.Int64("TransactionStartTimeMs"),
+			ProducerID:             reader.Int64("ProducerId"),
+			ProducerEpoch:          reader.Int16("ProducerEpoch"),
+		}
+		topicCount := reader.ArrayLength("Topics", true)
+		for range topicCount {
+			state.Topics = append(state.Topics, domain.DescribedTransactionTopic{
+				Topic:      reader.String("Topic", true),
+				Partitions: reader.Int32Array("Partitions", true),
+			})
+			reader.SkipTaggedFields()
+		}
+		reader.SkipTaggedFields()
+		got = append(got, state)
+	}
+	reader.SkipTaggedFields()
+
+	if !reflect.DeepEqual(got, states) {
+		t.Errorf("TransactionStates = %+v, want %+v", got, states)
+	}
+	if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+		t.Errorf("response error %v with %d trailing bytes", err, reader.Remaining())
+	}
+}
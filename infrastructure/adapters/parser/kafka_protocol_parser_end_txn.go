@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// EndTxn v3+ uses the flexible (compact) encodings
+const endTxnFirstFlexibleVersion = 3
+
+type KafkaProtocolParserEndTxn struct{}
+
+// NewKafkaProtocolParserEndTxn creates a new Kafka EndTxn protocol parser
+func NewKafkaProtocolParserEndTxn() parser.EndTxnParser {
+	return &KafkaProtocolParserEndTxn{}
+}
+
+func (p *KafkaProtocolParserEndTxn) ParseRequest(data []byte) (*domain.ParsedRequestEndTxn, error) {
+	header, reader, err := parseRequestHeader(data, endTxnFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	flexible := isFlexible(header.APIVersion, endTxnFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestEndTxn{
+		APIKey:          header.APIKey,
+		APIVersion:      header.APIVersion,
+		CorrelationID:   header.CorrelationID,
+		ClientID:        header.ClientID,
+		TransactionalID: reader.String("TransactionalId", flexible),
+		ProducerID:      reader.Int64("ProducerId"),
+		ProducerEpoch:   reader.Int16("ProducerEpoch"),
+		Committed:       reader.Bool("Committed"),
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("EndTxn", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserEndTxn) EncodeResponse(response *domain.ResponseDataEndTxn) ([]byte, error) {
+	flexible := isFlexible(response.APIVersion, endTxnFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	writer.Int32(response.ThrottleTimeMs)
+	writer.Int16(response.ErrorCode)
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
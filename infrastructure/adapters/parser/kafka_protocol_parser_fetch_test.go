@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func TestKafkaProtocolParserFetch_ParseRequest_V16(t *testing.T) {
+	// Fetch v16 for partition 0 of topic 71a59a51-8968-4f8b-937e-00000000077e at offset 0
+	data := []byte{0x00, 0x00, 0x00, 0x63, 0x00, 0x01, 0x00, 0x10, 0x06, 0xab, 0x6f, 0xf9, 0x00, 0x0c, 0x6b, 0x61, 0x66, 0x6b, 0x61, 0x2d, 0x74, 0x65, 0x73, 0x74, 0x65, 0x72, 0x00, 0x00, 0x00, 0x01, 0xf4, 0x00, 0x00, 0x00, 0x01, 0x7f, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x71, 0xa5, 0x9a, 0x51, 0x89, 0x68, 0x4f, 0x8b, 0x93, 0x7e, 0x00, 0x00, 0x00, 0x00, 0x07, 0x7e, 0x02, 0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f, 0xff, 0xff, 0xff, 0x00, 0x00, 0x01, 0x01, 0x00}
+
+	parsed, err := NewKafkaProtocolParserFetch().ParseRequest(data)
+	if err != nil {
+		t.Fatalf("ParseRequest() error = %v", err)
+	}
+	if parsed.MaxWaitMS != 500 || parsed.MinBytes != 1 || parsed.MaxBytes != 0x7fffffff {
+		t.Errorf("limits = %d/%d/%d, want 500/1/2147483647", parsed.MaxWaitMS, parsed.MinBytes, parsed.MaxBytes)
+	}
+	if len(parsed.Topics) != 1 || parsed.Topics[0].Name != "71a59a5189684f8b937e00000000077e" {
+		t.Fatalf("Topics = %+v, want one topic with the request's ID", parsed.Topics)
+	}
+	partitions := parsed.Topics[0].Partitions
+	if len(partitions) != 1 || partitions[0].FetchOffset != 0 || partitions[0].PartitionMaxBytes != 0x7fffffff {
+		t.Errorf("Partitions = %+v, want partition 0 at offset 0", partitions)
+	}
+}
+
+func TestKafkaProtocolParserFetch_ParseRequest_V4(t *testing.T) {
+	w := common.NewKafkaWriter()
+	w.Int16(1)
+	w.Int16(4)
+	w.Int32(9)
+	w.String("consumer", false)
+	w.Int32(-1)    // ReplicaID
+	w.Int32(100)   // MaxWaitMS
+	w.Int32(1)     // MinBytes
+	w.Int32(65536) // MaxBytes
+	w.Int8(0)      // IsolationLevel
+	w.ArrayLength(1, false)
+	w.String("foo", false)
+	w.ArrayLength(1, false)
+	w.Int32(2)    // PartitionIndex
+	w.Int64(42)   // FetchOffset
+	w.Int32(1024) // PartitionMaxBytes
+
+	parsed, err := NewKafkaProtocolParserFetch().ParseRequest(w.WithSizePrefix())
+	if err != nil {
+		t.Fatalf("ParseRequest() error = %v", err)
+	}
+	if parsed.MaxBytes != 65536 || parsed.SessionEpoch != -1 {
+		t.Errorf("MaxBytes = %d, SessionEpoch = %d, want 65536 and -1", parsed.MaxBytes, parsed.SessionEpoch)
+	}
+	want := domain.FetchPartition{PartitionIndex: 2, CurrentLeaderEpoch: -1, FetchOffset: 42, LastFetchedEpoch: -1, LogStartOffset: -1, PartitionMaxBytes: 1024}
+	if len(parsed.Topics) != 1 || parsed.Topics[0].Name != "foo" || parsed.Topics[0].Partitions[0] != want {
+		t.Errorf("Topics = %+v, want foo with %+v", parsed.Topics, want)
+	}
+}
+
+func TestKafkaProtocolParserFetch_EncodeResponse_V16(t *testing.T) {
+	topicID := bytes.Repeat([]byte{0xab}, 16)
+	// Large enough that the compact records length needs a multi-byte varint
+	records := bytes.Repeat([]byte{0x01}, 300)
+
+	encoded, err := NewKafkaProtocolParserFetch().EncodeResponse(&domain.ResponseDataFetch{
+		CorrelationID: []byte{0x00, 0x00, 0x00, 0x07},
+		APIVersion:    16,
+		SessionID:     5,
+		Topics: []domain.FetchResponseTopic{{
+			TopicNameBytes: topicID,
+			Partitions: []*domain.FetchResponsePartition{
+				{PartitionIndex: 0, HighWatermark: 10, LastStableOffset: 10, LogStartOffset: 2, PreferredReadReplica: -1, Records: records},
+				{PartitionIndex: 1, ErrorCode: domain.ErrorCodeOffsetOutOfRange, HighWatermark: 3, LastStableOffset: 3, PreferredReadReplica: -1},
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("EncodeResponse() error = %v", err)
+	}
+
+	reader := common.NewKafkaReader(encoded, 4)
+	if correlationID := reader.Int32("CorrelationID"); correlationID != 7 {
+		t.Errorf("CorrelationID = %d, want 7", correlationID)
+	}
+	reader.SkipTaggedFields()
+	reader.Int32("ThrottleTimeMs")
+	reader.Int16("ErrorCode")
+	if sessionID := reader.Int32("SessionID"); sessionID != 5 {
+		t.Errorf("SessionID = %d, want 5", sessionID)
+	}
+	if topics := reader.ArrayLength("Topics", true); topics != 1 {
+		t.Fatalf("Topics length = %d, want 1", topics)
+	}
+	if id := reader.UUID("TopicID"); !bytes.Equal(id, topicID) {
+		t.Errorf("TopicID = %x, want %x", id, topicID)
+	}
+	partitions := reader.ArrayLength("Partitions", true)
+	wantRecords := [][]byte{records, {}}
+	for i := range partitions {
+		reader.Int32("PartitionIndex")
+		reader.Int16("ErrorCode")
+		reader.Int64("HighWatermark")
+		reader.Int64("LastStableOffset")
+		reader.Int64("LogStartOffset")
+		reader.ArrayLength("AbortedTransactions", true)
+		reader.Int32("PreferredReadReplica")
+		if got := reader.Bytes("Records", true); !bytes.Equal(got, wantRecords[i]) || got == nil {
+			t.Errorf("partition %d Records has %d bytes, want %d", i, len(got), len(wantRecords[i]))
+		}
+		reader.SkipTaggedFields()
+	}
+	reader.SkipTaggedFields()
+	reader.SkipTaggedFields()
+	if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+		t.Errorf("response error %v with %d trailing bytes", err, reader.Remaining())
+	}
+}
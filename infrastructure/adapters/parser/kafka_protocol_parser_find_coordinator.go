@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// FindCoordinator v3+ uses the flexible (compact) encodings
+const findCoordinatorFirstFlexibleVersion = 3
+
+type KafkaProtocolParserFindCoordinator struct{}
+
+// NewKafkaProtocolParserFindCoordinator creates a new Kafka FindCoordinator protocol parser
+func NewKafkaProtocolParserFindCoordinator() parser.FindCoordinatorParser {
+	return &KafkaProtocolParserFindCoordinator{}
+}
+
+func (p *KafkaProtocolParserFindCoordinator) ParseRequest(data []byte) (*domain.ParsedRequestFindCoordinator, error) {
+	header, reader, err := parseRequestHeader(data, findCoordinatorFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	version := header.APIVersion
+	flexible := isFlexible(version, findCoordinatorFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestFindCoordinator{
+		APIKey:        header.APIKey,
+		APIVersion:    header.APIVersion,
+		CorrelationID: header.CorrelationID,
+		ClientID:      header.ClientID,
+		KeyType:       domain.CoordinatorKeyTypeGroup,
+	}
+
+	if version < 4 {
+		parsed.CoordinatorKeys = []string{reader.String("Key", flexible)}
+	}
+	if version >= 1 {
+		parsed.KeyType = reader.Int8("KeyType")
+	}
+	if version >= 4 {
+		parsed.CoordinatorKeys = reader.StringArray("CoordinatorKeys", flexible)
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("FindCoordinator", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserFindCoordinator) EncodeResponse(response *domain.ResponseDataFindCoordinator) ([]byte, error) {
+	version := response.APIVersion
+	flexible := isFlexible(version, findCoordinatorFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	if version >= 1 {
+		writer.Int32(response.ThrottleTimeMs)
+	}
+
+	if version >= 4 {
+		writer.ArrayLength(len(response.Coordinators), flexible)
+		for _, coordinator := range response.Coordinators {
+			writer.String(coordinator.Key, flexible)
+			writer.Int32(coordinator.NodeID)
+			writer.String(coordinator.Host, flexible)
+			writer.Int32(coordinator.Port)
+			writer.Int16(coordinator.ErrorCode)
+			writer.NullableString(nullIfEmpty(coordinator.ErrorMessage), flexible)
+			writer.EmptyTaggedFields()
+		}
+	} else {
+		coordinator := domain.Coordinator{NodeID: -1, Port: -1, ErrorCode: domain.ErrorCodeInvalidRequest}
+		if len(response.Coordinators) > 0 {
+			coordinator = response.Coordinators[0]
+		}
+		writer.Int16(coordinator.ErrorCode)
+		if version >= 1 {
+			writer.NullableString(nullIfEmpty(coordinator.ErrorMessage), flexible)
+		}
+		writer.Int32(coordinator.NodeID)
+		writer.String(coordinator.Host, flexible)
+		writer.Int32(coordinator.Port)
+	}
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
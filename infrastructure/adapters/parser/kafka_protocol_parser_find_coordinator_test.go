@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func buildFindCoordinatorRequest(version int16) []byte {
+	flexible := version >= 3
+	w := common.NewKafkaWriter()
+	w.Int16(10)
+	w.Int16(version)
+	w.Int32(9)
+	w.String("consumer", false)
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	if version < 4 {
+		w.String("group-a", flexible)
+	}
+	if version >= 1 {
+		w.Int8(domain.CoordinatorKeyTypeTransaction)
+	}
+	if version >= 4 {
+		w.StringArray([]string{"group-a", "group-b"}, flexible)
+	}
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	return w.WithSizePrefix()
+}
+
+func TestKafkaProtocolParserFindCoordinator_ParseRequest(t *testing.T) {
+	for _, version := range []int16{0, 1, 3, 4, 5} {
+		parsed, err := NewKafkaProtocolParserFindCoordinator().ParseRequest(buildFindCoordinatorRequest(version))
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+
+		wantKeys, wantKeyType := []string{"group-a"}, domain.CoordinatorKeyTypeGroup
+		if version >= 1 {
+			wantKeyType = domain.CoordinatorKeyTypeTransaction
+		}
+		if version >= 4 {
+			wantKeys = []string{"group-a", "group-b"}
+		}
+		if !reflect.DeepEqual(parsed.CoordinatorKeys, wantKeys) || parsed.KeyType != wantKeyType {
+			t.Errorf("v%d keys = %v of type %d, want %v of type %d", version, parsed.CoordinatorKeys, parsed.KeyType, wantKeys, wantKeyType)
+		}
+	}
+}
+
+func TestKafkaProtocolParserFindCoordinator_EncodeResponse(t *testing.T) {
+	coordinators := []domain.Coordinator{
+		{Key: "group-a", NodeID: 1, Host: "localhost", Port: 9092},
+		{Key: "group-b", NodeID: 1, Host: "localhost", Port: 9092},
+	}
+	for _, version := range []int{0, 1, 3, 4, 5} {
+		flexible := version >= 3
+		encoded, err := NewKafkaProtocolParserFindCoordinator().EncodeResponse(&domain.ResponseDataFindCoordinator{
+			CorrelationID: []byte{0x00, 0x00, 0x00, 0x09},
+			APIVersion:    version,
+			Coordinators:  coordinators,
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if version >= 1 {
+			reader.Int32("ThrottleTimeMs")
+		}
+		got := []domain.Coordinator{}
+		if version >= 4 {
+			length := reader.ArrayLength("Coordinators", flexible)
+			for range length {
+				coordinator := domain.Coordinator{Key: reader.String("Key", flexible), NodeID: reader.Int32("NodeId"), Host: reader.String("Host", flexible), Port: reader.Int32("Port")}
+				coordinator.ErrorCode = reader.Int16("ErrorCode")
+				coordinator.ErrorMessage = reader.String("ErrorMessage", flexible)
+				reader.SkipTaggedFields()
+				got = append(got, coordinator)
+			}
+		} else {
+			coordinator := domain.Coordinator{Key: "group-a", ErrorCode: reader.Int16("ErrorCode")}
+			if version >= 1 {
+				coordinator.ErrorMessage = reader.String("ErrorMessage", flexible)
+			}
+			coordinator.NodeID = reader.Int32("NodeId")
+			coordinator.Host = reader.String("Host", flexible)
+			coordinator.Port = reader.Int32("Port")
+			got = append(got, coordinator)
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+
+		want := coordinators
+		if version < 4 {
+			want = coordinators[:1]
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("v%d coordinators = %+v, want %+v", version, got, want)
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
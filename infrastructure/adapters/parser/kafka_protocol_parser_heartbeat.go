@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// Heartbeat v4+ uses the flexible (compact) encodings
+const heartbeatFirstFlexibleVersion = 4
+
+type KafkaProtocolParserHeartbeat struct{}
+
+// NewKafkaProtocolParserHeartbeat creates a new Kafka Heartbeat protocol parser
+func NewKafkaProtocolParserHeartbeat() parser.HeartbeatParser {
+	return &KafkaProtocolParserHeartbeat{}
+}
+
+func (p *KafkaProtocolParserHeartbeat) ParseRequest(data []byte) (*domain.ParsedRequestHeartbeat, error) {
+	header, reader, err := parseRequestHeader(data, heartbeatFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	version := header.APIVersion
+	flexible := isFlexible(version, heartbeatFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestHeartbeat{
+		APIKey:        header.APIKey,
+		APIVersion:    header.APIVersion,
+		CorrelationID: header.CorrelationID,
+		ClientID:      header.ClientID,
+		GroupID:       reader.String("GroupId", flexible),
+		GenerationID:  reader.Int32("GenerationId"),
+		MemberID:      reader.String("MemberId", flexible),
+	}
+	if version >= 3 {
+		parsed.GroupInstanceID, _ = reader.NullableString("GroupInstanceId", flexible)
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("Heartbeat", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserHeartbeat) EncodeResponse(response *domain.ResponseDataHeartbeat) ([]byte, error) {
+	version := response.APIVersion
+	flexible := isFlexible(version, heartbeatFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	if version >= 1 {
+		writer.Int32(response.ThrottleTimeMs)
+	}
+	writer.Int16(response.ErrorCode)
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
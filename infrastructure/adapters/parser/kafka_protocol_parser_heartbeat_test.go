@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func TestKafkaProtocolParserHeartbeat_ParseRequest(t *testing.T) {
+	for _, version := range []int16{0, 3, 4} {
+		flexible := version >= 4
+		w := common.NewKafkaWriter()
+		w.Int16(12)
+		w.Int16(version)
+		w.Int32(7)
+		w.String("consumer-1", false)
+		if flexible {
+			w.EmptyTaggedFields()
+		}
+		w.String("group-a", flexible)
+		w.Int32(2)
+		w.String("member-1", flexible)
+		if version >= 3 {
+			instanceID := "instance-1"
+			w.NullableString(&instanceID, flexible)
+		}
+		if flexible {
+			w.EmptyTaggedFields()
+		}
+
+		parsed, err := NewKafkaProtocolParserHeartbeat().ParseRequest(w.WithSizePrefix())
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+		wantInstanceID := ""
+		if version >= 3 {
+			wantInstanceID = "instance-1"
+		}
+		if parsed.GroupID != "group-a" || parsed.GenerationID != 2 || parsed.MemberID != "member-1" || parsed.GroupInstanceID != wantInstanceID {
+			t.Errorf("v%d ParseRequest() = %+v", version, parsed)
+		}
+	}
+}
+
+func TestKafkaProtocolParserHeartbeat_EncodeResponse(t *testing.T) {
+	for _, version := range []int{0, 1, 4} {
+		flexible := version >= 4
+		encoded, err := NewKafkaProtocolParserHeartbeat().EncodeResponse(&domain.ResponseDataHeartbeat{
+			CorrelationID: []byte{0x00, 0x00, 0x00, 0x07},
+			APIVersion:    version,
+			ErrorCode:     domain.ErrorCodeRebalanceInProgress,
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if version >= 1 {
+			reader.Int32("ThrottleTimeMs")
+		}
+		if errorCode := reader.Int16("ErrorCode"); errorCode != domain.ErrorCodeRebalanceInProgress {
+			t.Errorf("v%d ErrorCode = %d, want REBALANCE_IN_PROGRESS", version, errorCode)
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// InitProducerId v2+ uses the flexible (compact) encodings
+const initProducerIdFirstFlexibleVersion = 2
+
+type KafkaProtocolParserInitProducerId struct{}
+
+// NewKafkaProtocolParserInitProducerId creates a new Kafka InitProducerId protocol parser
+func NewKafkaProtocolParserInitProducerId() parser.InitProducerIdParser {
+	return &KafkaProtocolParserInitProducerId{}
+}
+
+func (p *KafkaProtocolParserInitProducerId) ParseRequest(data []byte) (*domain.ParsedRequestInitProducerId, error) {
+	header, reader, err := parseRequestHeader(data, initProducerIdFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	version := header.APIVersion
+	flexible := isFlexible(version, initProducerIdFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestInitProducerId{
+		APIKey:        header.APIKey,
+		APIVersion:    header.APIVersion,
+		CorrelationID: header.CorrelationID,
+		ClientID:      header.ClientID,
+		ProducerID:    -1,
+		ProducerEpoch: -1,
+	}
+	parsed.TransactionalID, _ = reader.NullableString("TransactionalId", flexible)
+	parsed.TransactionTimeoutMs = reader.Int32("TransactionTimeoutMs")
+	if version >= 3 {
+		parsed.ProducerID = reader.Int64("ProducerId")
+		parsed.ProducerEpoch = reader.Int16("ProducerEpoch")
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("InitProducerId", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserInitProducerId) EncodeResponse(response *domain.ResponseDataInitProducerId) ([]byte, error) {
+	flexible := isFlexible(response.APIVersion, initProducerIdFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	writer.Int32(response.ThrottleTimeMs)
+	writer.Int16(response.ErrorCode)
+	writer.Int64(response.ProducerID)
+	writer.Int16(response.ProducerEpoch)
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
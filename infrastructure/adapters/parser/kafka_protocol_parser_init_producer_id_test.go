@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func TestKafkaProtocolParserInitProducerId_ParseRequest(t *testing.T) {
+	for _, version := range []int16{0, 2, 3, 5} {
+		flexible := version >= 2
+		w := common.NewKafkaWriter()
+		w.Int16(22)
+		w.Int16(version)
+		w.Int32(7)
+		w.String("producer-1", false)
+		if flexible {
+			w.EmptyTaggedFields()
+		}
+		transactionalID := "txn-1"
+		w.NullableString(&transactionalID, flexible)
+		w.Int32(60000)
+		if version >= 3 {
+			w.Int64(4000)
+			w.Int16(3)
+		}
+		if flexible {
+			w.EmptyTaggedFields()
+		}
+
+		parsed, err := NewKafkaProtocolParserInitProducerId().ParseRequest(w.WithSizePrefix())
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+		wantProducerID, wantEpoch := int64(-1), int16(-1)
+		if version >= 3 {
+			wantProducerID, wantEpoch = 4000, 3
+		}
+		if parsed.TransactionalID != "txn-1" || parsed.TransactionTimeoutMs != 60000 || parsed.ProducerID != wantProducerID || parsed.ProducerEpoch != wantEpoch {
+			t.Errorf("v%d ParseRequest() = %+v", version, parsed)
+		}
+	}
+
+	// Idempotent producers send a null transactional ID
+	w := common.NewKafkaWriter()
+	w.Int16(22)
+	w.Int16(4)
+	w.Int32(7)
+	w.String("producer-1", false)
+	w.EmptyTaggedFields()
+	w.NullableString(nil, true)
+	w.Int32(-1)
+	w.Int64(-1)
+	w.Int16(-1)
+	w.EmptyTaggedFields()
+	parsed, err := NewKafkaProtocolParserInitProducerId().ParseRequest(w.WithSizePrefix())
+	if err != nil || parsed.TransactionalID != "" {
+		t.Errorf("ParseRequest() without transactional ID = %+v, %v", parsed, err)
+	}
+}
+
+func TestKafkaProtocolParserInitProducerId_EncodeResponse(t *testing.T) {
+	for _, version := range []int{0, 2, 5} {
+		flexible := version >= 2
+		encoded, err := NewKafkaProtocolParserInitProducerId().EncodeResponse(&domain.ResponseDataInitProducerId{
+			CorrelationID: []byte{0x00, 0x00, 0x00, 0x07},
+			APIVersion:    version,
+			ProducerID:    4000,
+			ProducerEpoch: 0,
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		reader.Int32("ThrottleTimeMs")
+		if errorCode := reader.Int16("ErrorCode"); errorCode != domain.ErrorCodeNone {
+			t.Errorf("v%d ErrorCode = %d, want none", version, errorCode)
+		}
+		if producerID, epoch := reader.Int64("ProducerId"), reader.Int16("ProducerEpoch"); producerID != 4000 || epoch != 0 {
+			t.Errorf("v%d producer = %d epoch %d, want 4000 epoch 0", version, producerID, epoch)
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
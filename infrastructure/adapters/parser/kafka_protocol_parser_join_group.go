@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// JoinGroup v6+ uses the flexible (compact) encodings
+const joinGroupFirstFlexibleVersion = 6
+
+type KafkaProtocolParserJoinGroup struct{}
+
+// NewKafkaProtocolParserJoinGroup creates a new Kafka JoinGroup protocol parser
+func NewKafkaProtocolParserJoinGroup() parser.JoinGroupParser {
+	return &KafkaProtocolParserJoinGroup{}
+}
+
+func (p *KafkaProtocolParserJoinGroup) ParseRequest(data []byte) (*domain.ParsedRequestJoinGroup, error) {
+	header, reader, err := parseRequestHeader(data, joinGroupFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	version := header.APIVersion
+	flexible := isFlexible(version, joinGroupFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestJoinGroup{
+		APIKey:             header.APIKey,
+		APIVersion:         header.APIVersion,
+		CorrelationID:      header.CorrelationID,
+		ClientID:           header.ClientID,
+		GroupID:            reader.String("GroupId", flexible),
+		SessionTimeoutMs:   reader.Int32("SessionTimeoutMs"),
+		RebalanceTimeoutMs: -1,
+	}
+	if version >= 1 {
+		parsed.RebalanceTimeoutMs = reader.Int32("RebalanceTimeoutMs")
+	}
+	parsed.MemberID = reader.String("MemberId", flexible)
+	if version >= 5 {
+		parsed.GroupInstanceID, _ = reader.NullableString("GroupInstanceId", flexible)
+	}
+	parsed.ProtocolType = reader.String("ProtocolType", flexible)
+
+	protocolsLength := reader.ArrayLength("Protocols", flexible)
+	for range protocolsLength {
+		parsed.Protocols = append(parsed.Protocols, domain.JoinGroupProtocol{
+			Name:     reader.String("Name", flexible),
+			Metadata: reader.Bytes("Metadata", flexible),
+		})
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+	}
+	if version >= 8 {
+		parsed.Reason, _ = reader.NullableString("Reason", flexible)
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("JoinGroup", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserJoinGroup) EncodeResponse(response *domain.ResponseDataJoinGroup) ([]byte, error) {
+	version := response.APIVersion
+	flexible := isFlexible(version, joinGroupFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	if version >= 2 {
+		writer.Int32(response.ThrottleTimeMs)
+	}
+	writer.Int16(response.ErrorCode)
+	writer.Int32(response.GenerationID)
+	if version >= 7 {
+		writer.NullableString(nullIfEmpty(response.ProtocolType), flexible)
+		writer.NullableString(nullIfEmpty(response.ProtocolName), flexible)
+	} else {
+		writer.String(response.ProtocolName, flexible)
+	}
+	writer.String(response.Leader, flexible)
+	if version >= 9 {
+		writer.Bool(response.SkipAssignment)
+	}
+	writer.String(response.MemberID, flexible)
+
+	writer.ArrayLength(len(response.Members), flexible)
+	for _, member := range response.Members {
+		writer.String(member.MemberID, flexible)
+		if version >= 5 {
+			writer.NullableString(nullIfEmpty(member.GroupInstanceID), flexible)
+		}
+		writer.BytesField(nonNilBytes(member.Metadata), flexible)
+		if flexible {
+			writer.EmptyTaggedFields()
+		}
+	}
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
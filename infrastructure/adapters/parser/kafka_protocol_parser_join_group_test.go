@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func buildJoinGroupRequest(version int16) []byte {
+	flexible := version >= 6
+	w := common.NewKafkaWriter()
+	w.Int16(11)
+	w.Int16(version)
+	w.Int32(4)
+	w.String("consumer-1", false)
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	w.String("group-a", flexible)
+	w.Int32(45000) // SessionTimeoutMs
+	if version >= 1 {
+		w.Int32(300000) // RebalanceTimeoutMs
+	}
+	w.String("", flexible) // MemberId
+	if version >= 5 {
+		instanceID := "instance-1"
+		w.NullableString(&instanceID, flexible)
+	}
+	w.String("consumer", flexible)
+	w.ArrayLength(2, flexible)
+	for _, name := range []string{"range", "cooperative-sticky"} {
+		w.String(name, flexible)
+		w.BytesField([]byte(name+"-metadata"), flexible)
+		if flexible {
+			w.EmptyTaggedFields()
+		}
+	}
+	if version >= 8 {
+		w.NullableString(nil, flexible) // Reason
+	}
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	return w.WithSizePrefix()
+}
+
+func TestKafkaProtocolParserJoinGroup_ParseRequest(t *testing.T) {
+	for _, version := range []int16{0, 1, 5, 6, 9} {
+		parsed, err := NewKafkaProtocolParserJoinGroup().ParseRequest(buildJoinGroupRequest(version))
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+
+		want := &domain.ParsedRequestJoinGroup{
+			APIKey:             11,
+			APIVersion:         int(version),
+			CorrelationID:      []byte{0x00, 0x00, 0x00, 0x04},
+			ClientID:           "consumer-1",
+			GroupID:            "group-a",
+			SessionTimeoutMs:   45000,
+			RebalanceTimeoutMs: -1,
+			ProtocolType:       "consumer",
+			Protocols: []domain.JoinGroupProtocol{
+				{Name: "range", Metadata: []byte("range-metadata")},
+				{Name: "cooperative-sticky", Metadata: []byte("cooperative-sticky-metadata")},
+			},
+		}
+		if version >= 1 {
+			want.RebalanceTimeoutMs = 300000
+		}
+		if version >= 5 {
+			want.GroupInstanceID = "instance-1"
+		}
+		if !reflect.DeepEqual(parsed, want) {
+			t.Errorf("v%d ParseRequest() = %+v, want %+v", version, parsed, want)
+		}
+	}
+}
+
+func TestKafkaProtocolParserJoinGroup_EncodeResponse(t *testing.T) {
+	for _, version := range []int{0, 2, 5, 6, 7, 9} {
+		flexible := version >= 6
+		encoded, err := NewKafkaProtocolParserJoinGroup().EncodeResponse(&domain.ResponseDataJoinGroup{
+			CorrelationID: []byte{0x00, 0x00, 0x00, 0x04},
+			APIVersion:    version,
+			GenerationID:  3,
+			ProtocolType:  "consumer",
+			ProtocolName:  "range",
+			Leader:        "member-1",
+			MemberID:      "member-1",
+			Members: []domain.JoinGroupResponseMember{
+				{MemberID: "member-1", GroupInstanceID: "instance-1", Metadata: []byte{0x01}},
+				{MemberID: "member-2", Metadata: []byte{0x02}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if version >= 2 {
+			reader.Int32("ThrottleTimeMs")
+		}
+		errorCode, generation := reader.Int16("ErrorCode"), reader.Int32("GenerationId")
+		if version >= 7 {
+			if protocolType := reader.String("ProtocolType", flexible); protocolType != "consumer" {
+				t.Errorf("v%d ProtocolType = %q, want consumer", version, protocolType)
+			}
+		}
+		protocolName, leader := reader.String("ProtocolName", flexible), reader.String("Leader", flexible)
+		if version >= 9 {
+			reader.Bool("SkipAssignment")
+		}
+		memberID := reader.String("MemberId", flexible)
+		if errorCode != 0 || generation != 3 || protocolName != "range" || leader != "member-1" || memberID != "member-1" {
+			t.Errorf("v%d response = %d %d %q %q %q", version, errorCode, generation, protocolName, leader, memberID)
+		}
+
+		members := []domain.JoinGroupResponseMember{}
+		length := reader.ArrayLength("Members", flexible)
+		for range length {
+			member := domain.JoinGroupResponseMember{MemberID: reader.String("MemberId", flexible)}
+			if version >= 5 {
+				member.GroupInstanceID = reader.String("GroupInstanceId", flexible)
+			}
+			member.Metadata = reader.Bytes("Metadata", flexible)
+			if flexible {
+				reader.SkipTaggedFields()
+			}
+			members = append(members, member)
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if len(members) != 2 || members[1].MemberID != "member-2" || members[1].Metadata[0] != 0x02 ||
+			version >= 5 && members[0].GroupInstanceID != "instance-1" {
+			t.Errorf("v%d members = %+v", version, members)
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
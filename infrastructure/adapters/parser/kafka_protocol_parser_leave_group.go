@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// LeaveGroup v4+ uses the flexible (compact) encodings
+const leaveGroupFirstFlexibleVersion = 4
+
+type KafkaProtocolParserLeaveGroup struct{}
+
+// NewKafkaProtocolParserLeaveGroup creates a new Kafka LeaveGroup protocol parser
+func NewKafkaProtocolParserLeaveGroup() parser.LeaveGroupParser {
+	return &KafkaProtocolParserLeaveGroup{}
+}
+
+func (p *KafkaProtocolParserLeaveGroup) ParseRequest(data []byte) (*domain.ParsedRequestLeaveGroup, error) {
+	header, reader, err := parseRequestHeader(data, leaveGroupFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	version := header.APIVersion
+	flexible := isFlexible(version, leaveGroupFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestLeaveGroup{
+		APIKey:        header.APIKey,
+		APIVersion:    header.APIVersion,
+		CorrelationID: header.CorrelationID,
+		ClientID:      header.ClientID,
+		GroupID:       reader.String("GroupId", flexible),
+	}
+	if version < 3 {
+		parsed.Members = []domain.LeaveGroupMember{{MemberID: reader.String("MemberId", flexible)}}
+	} else {
+		membersLength := reader.ArrayLength("Members", flexible)
+		for range membersLength {
+			member := domain.LeaveGroupMember{MemberID: reader.String("MemberId", flexible)}
+			member.GroupInstanceID, _ = reader.NullableString("GroupInstanceId", flexible)
+			if version >= 5 {
+				member.Reason, _ = reader.NullableString("Reason", flexible)
+			}
+			if flexible {
+				reader.SkipTaggedFields()
+			}
+			parsed.Members = append(parsed.Members, member)
+		}
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("LeaveGroup", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserLeaveGroup) EncodeResponse(response *domain.ResponseDataLeaveGroup) ([]byte, error) {
+	version := response.APIVersion
+	flexible := isFlexible(version, leaveGroupFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	if version >= 1 {
+		writer.Int32(response.ThrottleTimeMs)
+	}
+	writer.Int16(response.ErrorCode)
+	if version >= 3 {
+		writer.ArrayLength(len(response.Members), flexible)
+		for _, member := range response.Members {
+			writer.String(member.MemberID, flexible)
+			writer.NullableString(nullIfEmpty(member.GroupInstanceID), flexible)
+			writer.Int16(member.ErrorCode)
+			if flexible {
+				writer.EmptyTaggedFields()
+			}
+		}
+	}
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
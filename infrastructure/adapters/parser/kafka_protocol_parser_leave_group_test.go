@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func buildLeaveGroupRequest(version int16) []byte {
+	flexible := version >= 4
+	w := common.NewKafkaWriter()
+	w.Int16(13)
+	w.Int16(version)
+	w.Int32(8)
+	w.String("consumer-1", false)
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	w.String("group-a", flexible)
+	if version < 3 {
+		w.String("member-1", flexible)
+	} else {
+		w.ArrayLength(2, flexible)
+		w.String("member-1", flexible)
+		w.NullableString(nil, flexible)
+		if version >= 5 {
+			reason := "shutting down"
+			w.NullableString(&reason, flexible)
+		}
+		if flexible {
+			w.EmptyTaggedFields()
+		}
+		w.String("", flexible)
+		instanceID := "instance-2"
+		w.NullableString(&instanceID, flexible)
+		if version >= 5 {
+			w.NullableString(nil, flexible)
+		}
+		if flexible {
+			w.EmptyTaggedFields()
+		}
+	}
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	return w.WithSizePrefix()
+}
+
+func TestKafkaProtocolParserLeaveGroup_ParseRequest(t *testing.T) {
+	for _, version := range []int16{0, 2, 3, 4, 5} {
+		parsed, err := NewKafkaProtocolParserLeaveGroup().ParseRequest(buildLeaveGroupRequest(version))
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+
+		want := []domain.LeaveGroupMember{{MemberID: "member-1"}}
+		if version >= 3 {
+			want = append(want, domain.LeaveGroupMember{GroupInstanceID: "instance-2"})
+		}
+		if version >= 5 {
+			want[0].Reason = "shutting down"
+		}
+		if parsed.GroupID != "group-a" || !reflect.DeepEqual(parsed.Members, want) {
+			t.Errorf("v%d group %q members = %+v, want %+v", version, parsed.GroupID, parsed.Members, want)
+		}
+	}
+}
+
+func TestKafkaProtocolParserLeaveGroup_EncodeResponse(t *testing.T) {
+	members := []domain.LeaveGroupMemberResult{
+		{MemberID: "member-1"},
+		{GroupInstanceID: "instance-2", ErrorCode: domain.ErrorCodeUnknownMemberID},
+	}
+	for _, version := range []int{0, 1, 3, 4, 5} {
+		flexible := version >= 4
+		encoded, err := NewKafkaProtocolParserLeaveGroup().EncodeResponse(&domain.ResponseDataLeaveGroup{
+			CorrelationID: []byte{0x00, 0x00, 0x00, 0x08},
+			APIVersion:    version,
+			Members:       members,
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if version >= 1 {
+			reader.Int32("ThrottleTimeMs")
+		}
+		reader.Int16("ErrorCode")
+		if version >= 3 {
+			got := []domain.LeaveGroupMemberResult{}
+			length := reader.ArrayLength("Members", flexible)
+			for range length {
+				member := domain.LeaveGroupMemberResult{MemberID: reader.String("MemberId", flexible)}
+				member.GroupInstanceID = reader.String("GroupInstanceId", flexible)
+				member.ErrorCode = reader.Int16("ErrorCode")
+				if flexible {
+					reader.SkipTaggedFields()
+				}
+				got = append(got, member)
+			}
+			if !reflect.DeepEqual(got, members) {
+				t.Errorf("v%d members = %+v, want %+v", version, got, members)
+			}
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// ListGroups v3+ uses the flexible (compact) encodings
+const listGroupsFirstFlexibleVersion = 3
+
+type KafkaProtocolParserListGroups struct{}
+
+// NewKafkaProtocolParserListGroups creates a new Kafka ListGroups protocol parser
+func NewKafkaProtocolParserListGroups() parser.ListGroupsParser {
+	return &KafkaProtocolParserListGroups{}
+}
+
+func (p *KafkaProtocolParserListGroups) ParseRequest(data []byte) (*domain.ParsedRequestListGroups, error) {
+	header, reader, err := parseRequestHeader(data, listGroupsFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	version := header.APIVersion
+	flexible := isFlexible(version, listGroupsFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestListGroups{
+		APIKey:        header.APIKey,
+		APIVersion:    header.APIVersion,
+		CorrelationID: header.CorrelationID,
+		ClientID:      header.ClientID,
+	}
+	if version >= 4 {
+		parsed.StatesFilter = reader.StringArray("StatesFilter", flexible)
+	}
+	if version >= 5 {
+		parsed.TypesFilter = reader.StringArray("TypesFilter", flexible)
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("ListGroups", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserListGroups) EncodeResponse(response *domain.ResponseDataListGroups) ([]byte, error) {
+	version := response.APIVersion
+	flexible := isFlexible(version, listGroupsFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	if version >= 1 {
+		writer.Int32(response.ThrottleTimeMs)
+	}
+	writer.Int16(response.ErrorCode)
+	writer.ArrayLength(len(response.Groups), flexible)
+	for _, group := range response.Groups {
+		writer.String(group.GroupID, flexible)
+		writer.String(group.ProtocolType, flexible)
+		if version >= 4 {
+			writer.String(group.GroupState, flexible)
+		}
+		if version >= 5 {
+			writer.String(group.GroupType, flexible)
+		}
+		if flexible {
+			writer.EmptyTaggedFields()
+		}
+	}
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
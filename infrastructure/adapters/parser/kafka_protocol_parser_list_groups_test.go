@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func buildListGroupsRequest(version int16) []byte {
+	flexible := version >= 3
+	w := common.NewKafkaWriter()
+	w.Int16(16)
+	w.Int16(version)
+	w.Int32(5)
+	w.String("admin-1", false)
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	if version >= 4 {
+		w.StringArray([]string{"Stable", "Empty"}, flexible)
+	}
+	if version >= 5 {
+		w.StringArray([]string{"consumer"}, flexible)
+	}
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	return w.WithSizePrefix()
+}
+
+func TestKafkaProtocolParserListGroups_ParseRequest(t *testing.T) {
+	for _, version := range []int16{0, 3, 4, 5} {
+		parsed, err := NewKafkaProtocolParserListGroups().ParseRequest(buildListGroupsRequest(version))
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+
+		var wantStates, wantTypes []string
+		if version >= 4 {
+			wantStates = []string{"Stable", "Empty"}
+		}
+		if version >= 5 {
+			wantTypes = []string{"consumer"}
+		}
+		if !reflect.DeepEqual(parsed.StatesFilter, wantStates) || !reflect.DeepEqual(parsed.TypesFilter, wantTypes) {
+			t.Errorf("v%d filters = %v %v, want %v %v", version, parsed.StatesFilter, parsed.TypesFilter, wantStates, wantTypes)
+		}
+	}
+}
+
+func TestKafkaProtocolParserListGroups_EncodeResponse(t *testing.T) {
+	groups := []domain.ListedGroup{
+		{GroupID: "group-a", ProtocolType: "consumer", GroupState: "Stable", GroupType: "classic"},
+		{GroupID: "group-b", ProtocolType: "", GroupState: "Empty", GroupType: "classic"},
+	}
+	for _, version := range []int{0, 1, 3, 4, 5} {
+		flexible := version >= 3
+		encoded, err := NewKafkaProtocolParserListGroups().EncodeResponse(&domain.ResponseDataListGroups{
+			CorrelationID: []byte{0x00, 0x00, 0x00, 0x05},
+			APIVersion:    version,
+			Groups:        groups,
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if version >= 1 {
+			reader.Int32("ThrottleTimeMs")
+		}
+		if errorCode := reader.Int16("ErrorCode"); errorCode != domain.ErrorCodeNone {
+			t.Errorf("v%d error code = %d, want none", version, errorCode)
+		}
+		got := []domain.ListedGroup{}
+		length := reader.ArrayLength("Groups", flexible)
+		for range length {
+			group := domain.ListedGroup{GroupID: reader.String("GroupId", flexible), ProtocolType: reader.String("ProtocolType", flexible)}
+			if version >= 4 {
+				group.GroupState = reader.String("GroupState", flexible)
+			}
+			if version >= 5 {
+				group.GroupType = reader.String("GroupType", flexible)
+			}
+			if flexible {
+				reader.SkipTaggedFields()
+			}
+			got = append(got, group)
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+
+		want := make([]domain.ListedGroup, len(groups))
+		for i, group := range groups {
+			want[i] = domain.ListedGroup{GroupID: group.GroupID, ProtocolType: group.ProtocolType}
+			if version >= 4 {
+				want[i].GroupState = group.GroupState
+			}
+			if version >= 5 {
+				want[i].GroupType = group.GroupType
+			}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("v%d groups = %+v, want %+v", version, got, want)
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// ListOffsets v6+ uses the flexible (compact) encodings
+const listOffsetsFirstFlexibleVersion = 6
+
+type KafkaProtocolParserListOffsets struct{}
+
+// NewKafkaProtocolParserListOffsets creates a new Kafka ListOffsets protocol parser
+func NewKafkaProtocolParserListOffsets() parser.ListOffsetsParser {
+	return &KafkaProtocolParserListOffsets{}
+}
+
+func (p *KafkaProtocolParserListOffsets) ParseRequest(data []byte) (*domain.ParsedRequestListOffsets, error) {
+	header, reader, err := parseRequestHeader(data, listOffsetsFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	version := header.APIVersion
+	flexible := isFlexible(version, listOffsetsFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestListOffsets{
+		APIKey:        header.APIKey,
+		APIVersion:    header.APIVersion,
+		CorrelationID: header.CorrelationID,
+		ClientID:      header.ClientID,
+		ReplicaID:     reader.Int32("ReplicaID"),
+	}
+	if version >= 2 {
+		parsed.IsolationLevel = reader.Int8("IsolationLevel")
+	}
+
+	topicsLength := reader.ArrayLength("Topics", flexible)
+	for range topicsLength {
+		topic := domain.ListOffsetsTopic{Name: reader.String("Name", flexible)}
+
+		partitionsLength := reader.ArrayLength("Partitions", flexible)
+		for range partitionsLength {
+			partition := domain.ListOffsetsPartition{
+				PartitionIndex:     reader.Int32("PartitionIndex"),
+				CurrentLeaderEpoch: -1,
+			}
+			if version >= 4 {
+				partition.CurrentLeaderEpoch = reader.Int32("CurrentLeaderEpoch")
+			}
+			partition.Timestamp = reader.Int64("Timestamp")
+			if version == 0 {
+				partition.MaxNumOffsets = reader.Int32("MaxNumOffsets")
+			}
+			if flexible {
+				reader.SkipTaggedFields()
+			}
+			topic.Partitions = append(topic.Partitions, partition)
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		parsed.Topics = append(parsed.Topics, topic)
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("ListOffsets", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserListOffsets) EncodeResponse(response *domain.ResponseDataListOffsets) ([]byte, error) {
+	version := response.APIVersion
+	flexible := isFlexible(version, listOffsetsFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	if version >= 2 {
+		writer.Int32(response.ThrottleTimeMs)
+	}
+
+	writer.ArrayLength(len(response.Topics), flexible)
+	for _, topic := range response.Topics {
+		writer.String(topic.Name, flexible)
+
+		writer.ArrayLength(len(topic.Partitions), flexible)
+		for _, partition := range topic.Partitions {
+			writer.Int32(partition.PartitionIndex)
+			writer.Int16(partition.ErrorCode)
+			if version == 0 {
+				// OldStyleOffsets array
+				if partition.Offset >= 0 {
+					writer.ArrayLength(1, flexible)
+					writer.Int64(partition.Offset)
+				} else {
+					writer.ArrayLength(0, flexible)
+				}
+			} else {
+				writer.Int64(partition.Timestamp)
+				writer.Int64(partition.Offset)
+			}
+			if version >= 4 {
+				writer.Int32(partition.LeaderEpoch)
+			}
+			if flexible {
+				writer.EmptyTaggedFields()
+			}
+		}
+		if flexible {
+			writer.EmptyTaggedFields()
+		}
+	}
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
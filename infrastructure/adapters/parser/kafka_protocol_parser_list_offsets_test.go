@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func buildListOffsetsRequest(version int16) []byte {
+	flexible := version >= 6
+	w := common.NewKafkaWriter()
+	w.Int16(2)
+	w.Int16(version)
+	w.Int32(3)
+	w.String("consumer", false)
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	w.Int32(-1) // ReplicaID
+	if version >= 2 {
+		w.Int8(1) // IsolationLevel
+	}
+	w.ArrayLength(1, flexible)
+	w.String("foo", flexible)
+	w.ArrayLength(1, flexible)
+	w.Int32(0)
+	if version >= 4 {
+		w.Int32(5) // CurrentLeaderEpoch
+	}
+	w.Int64(-2)
+	if version == 0 {
+		w.Int32(1)
+	}
+	if flexible {
+		w.EmptyTaggedFields()
+		w.EmptyTaggedFields()
+		w.EmptyTaggedFields()
+	}
+	return w.WithSizePrefix()
+}
+
+func TestKafkaProtocolParserListOffsets_ParseRequest(t *testing.T) {
+	for _, version := range []int16{0, 1, 2, 4, 6, 7} {
+		parsed, err := NewKafkaProtocolParserListOffsets().ParseRequest(buildListOffsetsRequest(version))
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+
+		wantPartition := domain.ListOffsetsPartition{PartitionIndex: 0, CurrentLeaderEpoch: -1, Timestamp: -2}
+		if version >= 4 {
+			wantPartition.CurrentLeaderEpoch = 5
+		}
+		if version == 0 {
+			wantPartition.MaxNumOffsets = 1
+		}
+		want := []domain.ListOffsetsTopic{{Name: "foo", Partitions: []domain.ListOffsetsPartition{wantPartition}}}
+		if !reflect.DeepEqual(parsed.Topics, want) {
+			t.Errorf("v%d Topics = %+v, want %+v", version, parsed.Topics, want)
+		}
+		if version >= 2 && parsed.IsolationLevel != 1 {
+			t.Errorf("v%d IsolationLevel = %d, want 1", version, parsed.IsolationLevel)
+		}
+	}
+}
+
+func TestKafkaProtocolParserListOffsets_EncodeResponse(t *testing.T) {
+	for _, version := range []int{0, 1, 4, 6, 7} {
+		flexible := version >= 6
+		encoded, err := NewKafkaProtocolParserListOffsets().EncodeResponse(&domain.ResponseDataListOffsets{
+			CorrelationID: []byte{0x00, 0x00, 0x00, 0x03},
+			APIVersion:    version,
+			Topics: []domain.ListOffsetsResponseTopic{{Name: "foo", Partitions: []domain.ListOffsetsResponsePartition{
+				{PartitionIndex: 0, Timestamp: 1000, Offset: 42, LeaderEpoch: 3},
+			}}},
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if version >= 2 {
+			reader.Int32("ThrottleTimeMs")
+		}
+		reader.ArrayLength("Topics", flexible)
+		reader.String("Name", flexible)
+		reader.ArrayLength("Partitions", flexible)
+		reader.Int32("PartitionIndex")
+		reader.Int16("ErrorCode")
+		var offset int64
+		if version == 0 {
+			reader.ArrayLength("OldStyleOffsets", flexible)
+			offset = reader.Int64("Offset")
+		} else {
+			reader.Int64("Timestamp")
+			offset = reader.Int64("Offset")
+		}
+		if offset != 42 {
+			t.Errorf("v%d Offset = %d, want 42", version, offset)
+		}
+		if version >= 4 {
+			if epoch := reader.Int32("LeaderEpoch"); epoch != 3 {
+				t.Errorf("v%d LeaderEpoch = %d, want 3", version, epoch)
+			}
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+			reader.SkipTaggedFields()
+			reader.SkipTaggedFields()
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
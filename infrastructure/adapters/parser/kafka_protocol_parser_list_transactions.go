@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// Every ListTransactions version uses the flexible (compact) encodings
+const listTransactionsFirstFlexibleVersion = 0
+
+type KafkaProtocolParserListTransactions struct{}
+
+// NewKafkaProtocolParserListTransactions creates a new Kafka ListTransactions protocol parser
+func NewKafkaProtocolParserListTransactions() parser.ListTransactionsParser {
+	return &KafkaProtocolParserListTransactions{}
+}
+
+func (p *KafkaProtocolParserListTransactions) ParseRequest(data []byte) (*domain.ParsedRequestListTransactions, error) {
+	header, reader, err := parseRequestHeader(data, listTransactionsFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := &domain.ParsedRequestListTransactions{
+		APIKey:         header.APIKey,
+		APIVersion:     header.APIVersion,
+		CorrelationID:  header.CorrelationID,
+		ClientID:       header.ClientID,
+		StateFilters:   reader.StringArray("StateFilters", true),
+		DurationFilter: -1,
+	}
+	producerIDCount := reader.ArrayLength("ProducerIdFilters", true)
+	for range producerIDCount {
+		parsed.ProducerIDFilters = append(parsed.ProducerIDFilters, reader.Int64("ProducerIdFilters"))
+	}
+	if header.APIVersion >= 1 {
+		parsed.DurationFilter = reader.Int64("DurationFilter")
+	}
+	reader.SkipTaggedFields()
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("ListTransactions", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserListTransactions) EncodeResponse(response *domain.ResponseDataListTransactions) ([]byte, error) {
+	writer := newResponseWriter(response.CorrelationID, true)
+
+	writer.Int32(response.ThrottleTimeMs)
+	writer.Int16(response.ErrorCode)
+	writer.StringArray(response.UnknownStateFilters, true)
+	writer.ArrayLength(len(response.TransactionStates), true)
+	for _, state := range response.TransactionStates {
+		writer.String(state.TransactionalID, true)
+		writer.Int64(state.ProducerID)
+		writer.String(state.TransactionState, true)
+		writer.EmptyTaggedFields()
+	}
+	writer.EmptyTaggedFields()
+
+	return writer.WithSizePrefix(), nil
+}
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func buildListTransactionsRequest(version int16) []byte {
+	w := common.NewKafkaWriter()
+	w.Int16(66)
+	w.Int16(version)
+	w.Int32(5)
+	w.String("admin-1", false)
+	w.EmptyTaggedFields()
+	w.StringArray([]string{"Ongoing"}, true)
+	w.ArrayLength(2, true)
+	w.Int64(7)
+	w.Int64(9)
+	if version >= 1 {
+		w.Int64(60000)
+	}
+	w.EmptyTaggedFields()
+	return w.WithSizePrefix()
+}
+
+func TestKafkaProtocolParserListTransactions_ParseRequest(t *testing.T) {
+	for _, version := range []int16{0, 1} {
+		parsed, err := NewKafkaProtocolParserListTransactions().ParseRequest(buildListTransactionsRequest(version))
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+
+		wantDuration := int64(-1)
+		if version >= 1 {
+			wantDuration = 60000
+		}
+		if !reflect.DeepEqual(parsed.StateFilters, []string{"Ongoing"}) || !reflect.DeepEqual(parsed.ProducerIDFilters, []int64{7, 9}) || parsed.DurationFilter != wantDuration {
+			t.Errorf("v%d filters = %v %v %d, want [Ongoing] [7 9] %d", version, parsed.StateFilters, parsed.ProducerIDFilters, parsed.DurationFilter, wantDuration)
+		}
+	}
+}
+
+func TestKafkaProtocolParserListTransactions_EncodeResponse(t *testing.T) {
+	states := []domain.ListedTransaction{
+		{TransactionalID: "txn-1", ProducerID: 7, TransactionState: "Ongoing"},
+		{TransactionalID: "txn-2", ProducerID: 9, TransactionState: "CompleteCommit"},
+	}
+	for _, version := range []int{0, 1} {
+		encoded, err := NewKafkaProtocolParserListTransactions().EncodeResponse(&domain.ResponseDataListTransactions{
+			CorrelationID:       []byte{0x00, 0x00, 0x00, 0x05},
+			APIVersion:          version,
+			UnknownStateFilters: []string{"Pending"},
+			TransactionStates:   states,
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		reader.SkipTaggedFields()
+		reader.Int32("ThrottleTimeMs")
+		if errorCode := reader.Int16("ErrorCode"); errorCode != domain.ErrorCodeNone {
+			t.Errorf("v%d error code = %d, want none", version, errorCode)
+		}
+		if unknown := reader.StringArray("UnknownStateFilters", true); !reflect.DeepEqual(unknown, []string{"Pending"}) {
+			t.Errorf("v%d UnknownStateFilters = %v, want [Pending]", version, unknown)
+		}
+		got := []domain.ListedTransaction{}
+		stateCount := reader.ArrayLength("TransactionStates", true)
+		for range stateCount {
+			got = append(got, domain.ListedTransaction{
+				TransactionalID:  reader.String("TransactionalId", true),
+				ProducerID:       reader.Int64("ProducerId"),
+				TransactionState: reader.String("TransactionState", true),
+			})
+			reader.SkipTaggedFields()
+		}
+		reader.SkipTaggedFields()
+
+		if !reflect.DeepEqual(got, states) {
+			t.Errorf("v%d TransactionStates = %+v, want %+v", version, got, states)
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
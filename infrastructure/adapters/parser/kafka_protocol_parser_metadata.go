@@ -0,0 +1,163 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// Metadata v9+ uses the flexible (compact) encodings
+const metadataFirstFlexibleVersion = 9
+
+type KafkaProtocolParserMetadata struct{}
+
+// NewKafkaProtocolParserMetadata creates a new Kafka Metadata protocol parser
+func NewKafkaProtocolParserMetadata() parser.MetadataParser {
+	return &KafkaProtocolParserMetadata{}
+}
+
+func (p *KafkaProtocolParserMetadata) ParseRequest(data []byte) (*domain.ParsedRequestMetadata, error) {
+	header, reader, err := parseRequestHeader(data, metadataFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	version := header.APIVersion
+	flexible := isFlexible(version, metadataFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestMetadata{
+		APIKey:        header.APIKey,
+		APIVersion:    header.APIVersion,
+		CorrelationID: header.CorrelationID,
+		ClientID:      header.ClientID,
+	}
+
+	// Topics array, null (v1+) or empty (v0) means every topic
+	topicsLength := reader.ArrayLength("Topics", flexible)
+	parsed.AllTopics = topicsLength < 0 || (version == 0 && topicsLength == 0)
+	for range topicsLength {
+		topic := domain.MetadataRequestTopic{}
+		if version >= 10 {
+			topic.TopicID = reader.UUID("TopicID")
+		}
+		topic.Name = reader.String("Name", flexible)
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		parsed.Topics = append(parsed.Topics, topic)
+	}
+
+	if version >= 4 {
+		parsed.AllowAutoTopicCreation = reader.Bool("AllowAutoTopicCreation")
+	} else {
+		parsed.AllowAutoTopicCreation = true
+	}
+	if version >= 8 && version <= 10 {
+		parsed.IncludeClusterAuthorizedOperations = reader.Bool("IncludeClusterAuthorizedOperations")
+	}
+	if version >= 8 {
+		parsed.IncludeTopicAuthorizedOperations = reader.Bool("IncludeTopicAuthorizedOperations")
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("Metadata", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserMetadata) EncodeResponse(response *domain.ResponseDataMetadata) ([]byte, error) {
+	version := response.APIVersion
+	flexible := isFlexible(version, metadataFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	if version >= 3 {
+		writer.Int32(response.ThrottleTimeMs)
+	}
+
+	writer.ArrayLength(len(response.Brokers), flexible)
+	for _, broker := range response.Brokers {
+		writer.Int32(broker.NodeID)
+		writer.String(broker.Host, flexible)
+		writer.Int32(broker.Port)
+		if version >= 1 {
+			writer.NullableString(nullIfEmpty(broker.Rack), flexible)
+		}
+		if flexible {
+			writer.EmptyTaggedFields()
+		}
+	}
+
+	if version >= 2 {
+		writer.NullableString(nullIfEmpty(response.ClusterID), flexible)
+	}
+	if version >= 1 {
+		writer.Int32(response.ControllerID)
+	}
+
+	writer.ArrayLength(len(response.Topics), flexible)
+	for _, topic := range response.Topics {
+		writer.Int16(topic.ErrorCode)
+		if version >= 12 {
+			writer.NullableString(nullIfEmpty(topic.Name), flexible)
+		} else {
+			writer.String(topic.Name, flexible)
+		}
+		if version >= 10 {
+			writer.UUID(topic.TopicID)
+		}
+		if version >= 1 {
+			writer.Bool(topic.IsInternal)
+		}
+
+		writer.ArrayLength(len(topic.Partitions), flexible)
+		for _, partition := range topic.Partitions {
+			writer.Int16(partition.ErrorCode)
+			writer.Int32(partition.PartitionIndex)
+			writer.Int32(partition.LeaderID)
+			if version >= 7 {
+				writer.Int32(partition.LeaderEpoch)
+			}
+			writer.Int32Array(partition.ReplicaNodes, flexible)
+			writer.Int32Array(partition.IsrNodes, flexible)
+			if version >= 5 {
+				writer.Int32Array(partition.OfflineReplicas, flexible)
+			}
+			if flexible {
+				writer.EmptyTaggedFields()
+			}
+		}
+
+		if version >= 8 {
+			writer.Int32(topic.TopicAuthorizedOperations)
+		}
+		if flexible {
+			writer.EmptyTaggedFields()
+		}
+	}
+
+	if version >= 8 && version <= 10 {
+		writer.Int32(response.ClusterAuthorizedOperations)
+	}
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
+
+// nullIfEmpty maps the empty string to a null nullable string
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// nonNilBytes encodes a missing BYTES value as empty rather than null
+func nonNilBytes(b []byte) []byte {
+	if b == nil {
+		return []byte{}
+	}
+	return b
+}
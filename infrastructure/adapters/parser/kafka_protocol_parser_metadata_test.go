@@ -0,0 +1,200 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func buildMetadataRequest(version int16, topics []string, nullTopics bool) []byte {
+	flexible := version >= 9
+	w := common.NewKafkaWriter()
+	w.Int16(3)
+	w.Int16(version)
+	w.Int32(11)
+	w.String("client", false)
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	if nullTopics {
+		w.ArrayLength(-1, flexible)
+	} else {
+		w.ArrayLength(len(topics), flexible)
+	}
+	for _, topic := range topics {
+		if version >= 10 {
+			w.UUID(nil)
+		}
+		w.String(topic, flexible)
+		if flexible {
+			w.EmptyTaggedFields()
+		}
+	}
+	if version >= 4 {
+		w.Bool(false)
+	}
+	if version >= 8 && version <= 10 {
+		w.Bool(false)
+	}
+	if version >= 8 {
+		w.Bool(true)
+	}
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	return w.WithSizePrefix()
+}
+
+func TestKafkaProtocolParserMetadata_ParseRequest(t *testing.T) {
+	tests := []struct {
+		name          string
+		version       int16
+		topics        []string
+		nullTopics    bool
+		wantAllTopics bool
+		wantTopics    []string
+	}{
+		{name: "v0 empty means all topics", version: 0, topics: []string{}, wantAllTopics: true},
+		{name: "v1 empty means no topics", version: 1, topics: []string{}, wantAllTopics: false},
+		{name: "v1 null means all topics", version: 1, nullTopics: true, wantAllTopics: true},
+		{name: "v4 named topics", version: 4, topics: []string{"foo", "bar"}, wantTopics: []string{"foo", "bar"}},
+		{name: "v9 flexible", version: 9, topics: []string{"foo"}, wantTopics: []string{"foo"}},
+		{name: "v12 with topic ids", version: 12, topics: []string{"foo"}, wantTopics: []string{"foo"}},
+		{name: "v12 null topics", version: 12, nullTopics: true, wantAllTopics: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := NewKafkaProtocolParserMetadata().ParseRequest(buildMetadataRequest(tt.version, tt.topics, tt.nullTopics))
+			if err != nil {
+				t.Fatalf("ParseRequest() error = %v", err)
+			}
+			if parsed.APIVersion != int(tt.version) {
+				t.Errorf("APIVersion = %d, want %d", parsed.APIVersion, tt.version)
+			}
+			if parsed.AllTopics != tt.wantAllTopics {
+				t.Errorf("AllTopics = %v, want %v", parsed.AllTopics, tt.wantAllTopics)
+			}
+			gotTopics := []string{}
+			for _, topic := range parsed.Topics {
+				gotTopics = append(gotTopics, topic.Name)
+			}
+			if tt.wantTopics == nil {
+				tt.wantTopics = []string{}
+			}
+			if !reflect.DeepEqual(gotTopics, tt.wantTopics) {
+				t.Errorf("Topics = %v, want %v", gotTopics, tt.wantTopics)
+			}
+			if tt.version >= 8 && !parsed.IncludeTopicAuthorizedOperations {
+				t.Error("IncludeTopicAuthorizedOperations = false, want true")
+			}
+		})
+	}
+}
+
+func TestKafkaProtocolParserMetadata_EncodeResponse(t *testing.T) {
+	topicId := []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 7}
+	response := &domain.ResponseDataMetadata{
+		CorrelationID: []byte{0x00, 0x00, 0x00, 0x0b},
+		Brokers:       []domain.Broker{{NodeID: 1, Host: "localhost", Port: 9092}},
+		ClusterID:     "cluster",
+		ControllerID:  1,
+		Topics: []domain.MetadataResponseTopic{{
+			Name:    "foo",
+			TopicID: topicId,
+			Partitions: []domain.MetadataResponsePartition{
+				{PartitionIndex: 0, LeaderID: 1, LeaderEpoch: 2, ReplicaNodes: []int32{1}, IsrNodes: []int32{1}, OfflineReplicas: []int32{}},
+			},
+		}},
+	}
+
+	for _, version := range []int{0, 1, 5, 8, 9, 10, 12} {
+		response.APIVersion = version
+		flexible := version >= 9
+		encoded, err := NewKafkaProtocolParserMetadata().EncodeResponse(response)
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if version >= 3 {
+			reader.Int32("ThrottleTimeMs")
+		}
+		if brokers := reader.ArrayLength("Brokers", flexible); brokers != 1 {
+			t.Fatalf("v%d Brokers length = %d, want 1", version, brokers)
+		}
+		reader.Int32("NodeID")
+		if host := reader.String("Host", flexible); host != "localhost" {
+			t.Errorf("v%d Host = %q, want localhost", version, host)
+		}
+		if port := reader.Int32("Port"); port != 9092 {
+			t.Errorf("v%d Port = %d, want 9092", version, port)
+		}
+		if version >= 1 {
+			reader.NullableString("Rack", flexible)
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if version >= 2 {
+			reader.NullableString("ClusterID", flexible)
+		}
+		if version >= 1 {
+			reader.Int32("ControllerID")
+		}
+		reader.ArrayLength("Topics", flexible)
+		reader.Int16("ErrorCode")
+		if name := reader.String("Name", flexible); name != "foo" {
+			t.Errorf("v%d topic Name = %q, want foo", version, name)
+		}
+		if version >= 10 {
+			if id := reader.UUID("TopicID"); !reflect.DeepEqual(id, topicId) {
+				t.Errorf("v%d TopicID = %v, want %v", version, id, topicId)
+			}
+		}
+		if version >= 1 {
+			reader.Bool("IsInternal")
+		}
+		reader.ArrayLength("Partitions", flexible)
+		reader.Int16("ErrorCode")
+		reader.Int32("PartitionIndex")
+		if leader := reader.Int32("LeaderID"); leader != 1 {
+			t.Errorf("v%d LeaderID = %d, want 1", version, leader)
+		}
+		if version >= 7 {
+			reader.Int32("LeaderEpoch")
+		}
+		reader.Int32Array("ReplicaNodes", flexible)
+		reader.Int32Array("IsrNodes", flexible)
+		if version >= 5 {
+			reader.Int32Array("OfflineReplicas", flexible)
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if version >= 8 {
+			reader.Int32("TopicAuthorizedOperations")
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if version >= 8 && version <= 10 {
+			reader.Int32("ClusterAuthorizedOperations")
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if err := reader.Err(); err != nil {
+			t.Fatalf("v%d reading response: %v", version, err)
+		}
+		if reader.Remaining() != 0 {
+			t.Errorf("v%d response has %d trailing bytes", version, reader.Remaining())
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// OffsetCommit v8+ uses the flexible (compact) encodings
+const offsetCommitFirstFlexibleVersion = 8
+
+type KafkaProtocolParserOffsetCommit struct{}
+
+// NewKafkaProtocolParserOffsetCommit creates a new Kafka OffsetCommit protocol parser
+func NewKafkaProtocolParserOffsetCommit() parser.OffsetCommitParser {
+	return &KafkaProtocolParserOffsetCommit{}
+}
+
+func (p *KafkaProtocolParserOffsetCommit) ParseRequest(data []byte) (*domain.ParsedRequestOffsetCommit, error) {
+	header, reader, err := parseRequestHeader(data, offsetCommitFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	version := header.APIVersion
+	flexible := isFlexible(version, offsetCommitFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestOffsetCommit{
+		APIKey:          header.APIKey,
+		APIVersion:      header.APIVersion,
+		CorrelationID:   header.CorrelationID,
+		ClientID:        header.ClientID,
+		GroupID:         reader.String("GroupId", flexible),
+		GenerationID:    -1,
+		RetentionTimeMs: -1,
+	}
+	if version >= 1 {
+		parsed.GenerationID = reader.Int32("GenerationIdOrMemberEpoch")
+		parsed.MemberID = reader.String("MemberId", flexible)
+	}
+	if version >= 7 {
+		parsed.GroupInstanceID, _ = reader.NullableString("GroupInstanceId", flexible)
+	}
+	if version >= 2 && version <= 4 {
+		parsed.RetentionTimeMs = reader.Int64("RetentionTimeMs")
+	}
+
+	topicsLength := reader.ArrayLength("Topics", flexible)
+	for range topicsLength {
+		topic := domain.OffsetCommitTopic{Name: reader.String("Name", flexible)}
+		partitionsLength := reader.ArrayLength("Partitions", flexible)
+		for range partitionsLength {
+			partition := domain.OffsetCommitPartition{
+				PartitionIndex:       reader.Int32("PartitionIndex"),
+				CommittedOffset:      reader.Int64("CommittedOffset"),
+				CommittedLeaderEpoch: -1,
+				CommitTimestamp:      -1,
+			}
+			if version >= 6 {
+				partition.CommittedLeaderEpoch = reader.Int32("CommittedLeaderEpoch")
+			}
+			if version == 1 {
+				partition.CommitTimestamp = reader.Int64("CommitTimestamp")
+			}
+			partition.CommittedMetadata, _ = reader.NullableString("CommittedMetadata", flexible)
+			if flexible {
+				reader.SkipTaggedFields()
+			}
+			topic.Partitions = append(topic.Partitions, partition)
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		parsed.Topics = append(parsed.Topics, topic)
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("OffsetCommit", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserOffsetCommit) EncodeResponse(response *domain.ResponseDataOffsetCommit) ([]byte, error) {
+	version := response.APIVersion
+	flexible := isFlexible(version, offsetCommitFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	if version >= 3 {
+		writer.Int32(response.ThrottleTimeMs)
+	}
+	writer.ArrayLength(len(response.Topics), flexible)
+	for _, topic := range response.Topics {
+		writer.String(topic.Name, flexible)
+		writer.ArrayLength(len(topic.Partitions), flexible)
+		for _, partition := range topic.Partitions {
+			writer.Int32(partition.PartitionIndex)
+			writer.Int16(partition.ErrorCode)
+			if flexible {
+				writer.EmptyTaggedFields()
+			}
+		}
+		if flexible {
+			writer.EmptyTaggedFields()
+		}
+	}
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
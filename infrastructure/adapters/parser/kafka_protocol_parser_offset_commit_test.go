@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func buildOffsetCommitRequest(version int16) []byte {
+	flexible := version >= 8
+	w := common.NewKafkaWriter()
+	w.Int16(8)
+	w.Int16(version)
+	w.Int32(9)
+	w.String("consumer-1", false)
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	w.String("group-a", flexible)
+	if version >= 1 {
+		w.Int32(3)
+		w.String("member-1", flexible)
+	}
+	if version >= 7 {
+		instanceID := "instance-1"
+		w.NullableString(&instanceID, flexible)
+	}
+	if version >= 2 && version <= 4 {
+		w.Int64(60000)
+	}
+	w.ArrayLength(1, flexible)
+	w.String("orders", flexible)
+	w.ArrayLength(2, flexible)
+	for i, metadata := range []string{"", "checkpoint"} {
+		w.Int32(int32(i))
+		w.Int64(int64(100 + i))
+		if version >= 6 {
+			w.Int32(5)
+		}
+		if version == 1 {
+			w.Int64(1700000000000)
+		}
+		w.NullableString(nullIfEmpty(metadata), flexible)
+		if flexible {
+			w.EmptyTaggedFields()
+		}
+	}
+	if flexible {
+		w.EmptyTaggedFields()
+		w.EmptyTaggedFields()
+	}
+	return w.WithSizePrefix()
+}
+
+func TestKafkaProtocolParserOffsetCommit_ParseRequest(t *testing.T) {
+	for _, version := range []int16{0, 1, 2, 5, 6, 7, 8, 9} {
+		parsed, err := NewKafkaProtocolParserOffsetCommit().ParseRequest(buildOffsetCommitRequest(version))
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+
+		want := &domain.ParsedRequestOffsetCommit{
+			APIKey:          8,
+			APIVersion:      int(version),
+			CorrelationID:   []byte{0x00, 0x00, 0x00, 0x09},
+			ClientID:        "consumer-1",
+			GroupID:         "group-a",
+			GenerationID:    -1,
+			RetentionTimeMs: -1,
+			Topics: []domain.OffsetCommitTopic{{
+				Name: "orders",
+				Partitions: []domain.OffsetCommitPartition{
+					{PartitionIndex: 0, CommittedOffset: 100, CommittedLeaderEpoch: -1, CommitTimestamp: -1},
+					{PartitionIndex: 1, CommittedOffset: 101, CommittedLeaderEpoch: -1, CommitTimestamp: -1, CommittedMetadata: "checkpoint"},
+				},
+			}},
+		}
+		if version >= 1 {
+			want.GenerationID = 3
+			want.MemberID = "member-1"
+		}
+		if version >= 7 {
+			want.GroupInstanceID = "instance-1"
+		}
+		if version >= 2 && version <= 4 {
+			want.RetentionTimeMs = 60000
+		}
+		for i := range want.Topics[0].Partitions {
+			if version >= 6 {
+				want.Topics[0].Partitions[i].CommittedLeaderEpoch = 5
+			}
+			if version == 1 {
+				want.Topics[0].Partitions[i].CommitTimestamp = 1700000000000
+			}
+		}
+		if !reflect.DeepEqual(parsed, want) {
+			t.Errorf("v%d parsed = %+v, want %+v", version, parsed, want)
+		}
+	}
+}
+
+func TestKafkaProtocolParserOffsetCommit_EncodeResponse(t *testing.T) {
+	topics := []domain.OffsetCommitResponseTopic{{
+		Name: "orders",
+		Partitions: []domain.OffsetCommitResponsePartition{
+			{PartitionIndex: 0},
+			{PartitionIndex: 1, ErrorCode: domain.ErrorCodeOffsetMetadataTooLarge},
+		},
+	}}
+	for _, version := range []int{0, 2, 3, 8, 9} {
+		flexible := version >= 8
+		encoded, err := NewKafkaProtocolParserOffsetCommit().EncodeResponse(&domain.ResponseDataOffsetCommit{
+			CorrelationID: []byte{0x00, 0x00, 0x00, 0x09},
+			APIVersion:    version,
+			Topics:        topics,
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if version >= 3 {
+			reader.Int32("ThrottleTimeMs")
+		}
+		got := []domain.OffsetCommitResponseTopic{}
+		topicsLength := reader.ArrayLength("Topics", flexible)
+		for range topicsLength {
+			topic := domain.OffsetCommitResponseTopic{Name: reader.String("Name", flexible)}
+			partitionsLength := reader.ArrayLength("Partitions", flexible)
+			for range partitionsLength {
+				topic.Partitions = append(topic.Partitions, domain.OffsetCommitResponsePartition{
+					PartitionIndex: reader.Int32("PartitionIndex"),
+					ErrorCode:      reader.Int16("ErrorCode"),
+				})
+				if flexible {
+					reader.SkipTaggedFields()
+				}
+			}
+			if flexible {
+				reader.SkipTaggedFields()
+			}
+			got = append(got, topic)
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if !reflect.DeepEqual(got, topics) {
+			t.Errorf("v%d topics = %+v, want %+v", version, got, topics)
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// OffsetDelete has no flexible versions
+const offsetDeleteFirstFlexibleVersion = -1
+
+type KafkaProtocolParserOffsetDelete struct{}
+
+// NewKafkaProtocolParserOffsetDelete creates a new Kafka OffsetDelete protocol parser
+func NewKafkaProtocolParserOffsetDelete() parser.OffsetDeleteParser {
+	return &KafkaProtocolParserOffsetDelete{}
+}
+
+func (p *KafkaProtocolParserOffsetDelete) ParseRequest(data []byte) (*domain.ParsedRequestOffsetDelete, error) {
+	header, reader, err := parseRequestHeader(data, offsetDeleteFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	flexible := isFlexible(header.APIVersion, offsetDeleteFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestOffsetDelete{
+		APIKey:        header.APIKey,
+		APIVersion:    header.APIVersion,
+		CorrelationID: header.CorrelationID,
+		ClientID:      header.ClientID,
+		GroupID:       reader.String("GroupId", flexible),
+	}
+	topicsLength := reader.ArrayLength("Topics", flexible)
+	for range topicsLength {
+		topic := domain.OffsetDeleteTopic{Name: reader.String("Name", flexible)}
+		partitionsLength := reader.ArrayLength("Partitions", flexible)
+		for range partitionsLength {
+			topic.Partitions = append(topic.Partitions, reader.Int32("PartitionIndex"))
+		}
+		parsed.Topics = append(parsed.Topics, topic)
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("OffsetDelete", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserOffsetDelete) EncodeResponse(response *domain.ResponseDataOffsetDelete) ([]byte, error) {
+	flexible := isFlexible(response.APIVersion, offsetDeleteFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	writer.Int16(response.ErrorCode)
+	writer.Int32(response.ThrottleTimeMs)
+	writer.ArrayLength(len(response.Topics), flexible)
+	for _, topic := range response.Topics {
+		writer.String(topic.Name, flexible)
+		writer.ArrayLength(len(topic.Partitions), flexible)
+		for _, partition := range topic.Partitions {
+			writer.Int32(partition.PartitionIndex)
+			writer.Int16(partition.ErrorCode)
+		}
+	}
+
+	return writer.WithSizePrefix(), nil
+}
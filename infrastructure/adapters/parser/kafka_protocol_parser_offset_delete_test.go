@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func TestKafkaProtocolParserOffsetDelete_ParseRequest(t *testing.T) {
+	w := common.NewKafkaWriter()
+	w.Int16(47)
+	w.Int16(0)
+	w.Int32(9)
+	w.String("admin-1", false)
+	w.String("group-a", false)
+	w.ArrayLength(1, false)
+	w.String("orders", false)
+	w.ArrayLength(2, false)
+	w.Int32(0)
+	w.Int32(3)
+
+	parsed, err := NewKafkaProtocolParserOffsetDelete().ParseRequest(w.WithSizePrefix())
+	if err != nil {
+		t.Fatalf("ParseRequest() error = %v", err)
+	}
+	want := []domain.OffsetDeleteTopic{{Name: "orders", Partitions: []int32{0, 3}}}
+	if parsed.GroupID != "group-a" || !reflect.DeepEqual(parsed.Topics, want) {
+		t.Errorf("group %q topics = %+v, want group-a %+v", parsed.GroupID, parsed.Topics, want)
+	}
+}
+
+func TestKafkaProtocolParserOffsetDelete_EncodeResponse(t *testing.T) {
+	topics := []domain.OffsetDeleteResponseTopic{{Name: "orders", Partitions: []domain.OffsetDeleteResponsePartition{
+		{PartitionIndex: 0},
+		{PartitionIndex: 3, ErrorCode: domain.ErrorCodeGroupSubscribedToTopic},
+	}}}
+	encoded, err := NewKafkaProtocolParserOffsetDelete().EncodeResponse(&domain.ResponseDataOffsetDelete{
+		CorrelationID: []byte{0x00, 0x00, 0x00, 0x09},
+		Topics:        topics,
+	})
+	if err != nil {
+		t.Fatalf("EncodeResponse() error = %v", err)
+	}
+
+	reader := common.NewKafkaReader(encoded, 4)
+	reader.Int32("CorrelationID")
+	if errorCode := reader.Int16("ErrorCode"); errorCode != domain.ErrorCodeNone {
+		t.Errorf("error code = %d, want none", errorCode)
+	}
+	reader.Int32("ThrottleTimeMs")
+	got := []domain.OffsetDeleteResponseTopic{}
+	topicsLength := reader.ArrayLength("Topics", false)
+	for range topicsLength {
+		topic := domain.OffsetDeleteResponseTopic{Name: reader.String("Name", false)}
+		partitionsLength := reader.ArrayLength("Partitions", false)
+		for range partitionsLength {
+			topic.Partitions = append(topic.Partitions, domain.OffsetDeleteResponsePartition{
+				PartitionIndex: reader.Int32("PartitionIndex"),
+				ErrorCode:      reader.Int16("ErrorCode"),
+			})
+		}
+		got = append(got, topic)
+	}
+	if !reflect.DeepEqual(got, topics) {
+		t.Errorf("topics = %+v, want %+v", got, topics)
+	}
+	if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+		t.Errorf("response error %v with %d trailing bytes", err, reader.Remaining())
+	}
+}
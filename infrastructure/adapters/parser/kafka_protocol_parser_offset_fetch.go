@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+const (
+	// OffsetFetch v6+ uses the flexible (compact) encodings
+	offsetFetchFirstFlexibleVersion = 6
+	// offsetFetchFirstBatchVersion is the first version fetching the offsets of several groups
+	offsetFetchFirstBatchVersion = 8
+)
+
+type KafkaProtocolParserOffsetFetch struct{}
+
+// NewKafkaProtocolParserOffsetFetch creates a new Kafka OffsetFetch protocol parser
+func NewKafkaProtocolParserOffsetFetch() parser.OffsetFetchParser {
+	return &KafkaProtocolParserOffsetFetch{}
+}
+
+func (p *KafkaProtocolParserOffsetFetch) ParseRequest(data []byte) (*domain.ParsedRequestOffsetFetch, error) {
+	header, reader, err := parseRequestHeader(data, offsetFetchFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	version := header.APIVersion
+	flexible := isFlexible(version, offsetFetchFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestOffsetFetch{
+		APIKey:        header.APIKey,
+		APIVersion:    header.APIVersion,
+		CorrelationID: header.CorrelationID,
+		ClientID:      header.ClientID,
+	}
+	if version < offsetFetchFirstBatchVersion {
+		group := domain.OffsetFetchGroup{GroupID: reader.String("GroupId", flexible), MemberEpoch: -1}
+		group.Topics = parseOffsetFetchTopics(reader, flexible)
+		parsed.Groups = []domain.OffsetFetchGroup{group}
+	} else {
+		groupsLength := reader.ArrayLength("Groups", flexible)
+		for range groupsLength {
+			group := domain.OffsetFetchGroup{GroupID: reader.String("GroupId", flexible), MemberEpoch: -1}
+			if version >= 9 {
+				group.MemberID, _ = reader.NullableString("MemberId", flexible)
+				group.MemberEpoch = reader.Int32("MemberEpoch")
+			}
+			group.Topics = parseOffsetFetchTopics(reader, flexible)
+			if flexible {
+				reader.SkipTaggedFields()
+			}
+			parsed.Groups = append(parsed.Groups, group)
+		}
+	}
+	if version >= 7 {
+		parsed.RequireStable = reader.Bool("RequireStable")
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("OffsetFetch", err)
+	}
+	return parsed, nil
+}
+
+// parseOffsetFetchTopics reads the topics of a group, nil when the array is null
+func parseOffsetFetchTopics(reader *common.KafkaReader, flexible bool) []domain.OffsetFetchTopic {
+	topicsLength := reader.ArrayLength("Topics", flexible)
+	if topicsLength < 0 {
+		return nil
+	}
+	topics := make([]domain.OffsetFetchTopic, 0, topicsLength)
+	for range topicsLength {
+		topic := domain.OffsetFetchTopic{
+			Name:             reader.String("Name", flexible),
+			PartitionIndexes: reader.Int32Array("PartitionIndexes", flexible),
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		topics = append(topics, topic)
+	}
+	return topics
+}
+
+func (p *KafkaProtocolParserOffsetFetch) EncodeResponse(response *domain.ResponseDataOffsetFetch) ([]byte, error) {
+	version := response.APIVersion
+	flexible := isFlexible(version, offsetFetchFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	if version >= 3 {
+		writer.Int32(response.ThrottleTimeMs)
+	}
+	if version < offsetFetchFirstBatchVersion {
+		group := domain.OffsetFetchResponseGroup{}
+		if len(response.Groups) > 0 {
+			group = response.Groups[0]
+		}
+		writeOffsetFetchTopics(writer, version, group.Topics, flexible)
+		if version >= 2 {
+			writer.Int16(group.ErrorCode)
+		}
+	} else {
+		writer.ArrayLength(len(response.Groups), flexible)
+		for _, group := range response.Groups {
+			writer.String(group.GroupID, flexible)
+			writeOffsetFetchTopics(writer, version, group.Topics, flexible)
+			writer.Int16(group.ErrorCode)
+			if flexible {
+				writer.EmptyTaggedFields()
+			}
+		}
+	}
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
+
+func writeOffsetFetchTopics(writer *common.KafkaWriter, version int, topics []domain.OffsetFetchResponseTopic, flexible bool) {
+	writer.ArrayLength(len(topics), flexible)
+	for _, topic := range topics {
+		writer.String(topic.Name, flexible)
+		writer.ArrayLength(len(topic.Partitions), flexible)
+		for _, partition := range topic.Partitions {
+			writer.Int32(partition.PartitionIndex)
+			writer.Int64(partition.CommittedOffset)
+			if version >= 5 {
+				writer.Int32(partition.CommittedLeaderEpoch)
+			}
+			writer.String(partition.Metadata, flexible)
+			writer.Int16(partition.ErrorCode)
+			if flexible {
+				writer.EmptyTaggedFields()
+			}
+		}
+		if flexible {
+			writer.EmptyTaggedFields()
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func writeOffsetFetchRequestTopics(w *common.KafkaWriter, topics []domain.OffsetFetchTopic, flexible bool) {
+	if topics == nil {
+		w.ArrayLength(-1, flexible)
+		return
+	}
+	w.ArrayLength(len(topics), flexible)
+	for _, topic := range topics {
+		w.String(topic.Name, flexible)
+		w.Int32Array(topic.PartitionIndexes, flexible)
+		if flexible {
+			w.EmptyTaggedFields()
+		}
+	}
+}
+
+func buildOffsetFetchRequest(version int16, groups []domain.OffsetFetchGroup) []byte {
+	flexible := version >= 6
+	w := common.NewKafkaWriter()
+	w.Int16(9)
+	w.Int16(version)
+	w.Int32(4)
+	w.String("consumer-1", false)
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	if version < 8 {
+		w.String(groups[0].GroupID, flexible)
+		writeOffsetFetchRequestTopics(w, groups[0].Topics, flexible)
+	} else {
+		w.ArrayLength(len(groups), flexible)
+		for _, group := range groups {
+			w.String(group.GroupID, flexible)
+			if version >= 9 {
+				w.NullableString(nullIfEmpty(group.MemberID), flexible)
+				w.Int32(group.MemberEpoch)
+			}
+			writeOffsetFetchRequestTopics(w, group.Topics, flexible)
+			if flexible {
+				w.EmptyTaggedFields()
+			}
+		}
+	}
+	if version >= 7 {
+		w.Bool(true)
+	}
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	return w.WithSizePrefix()
+}
+
+func TestKafkaProtocolParserOffsetFetch_ParseRequest(t *testing.T) {
+	orders := []domain.OffsetFetchTopic{{Name: "orders", PartitionIndexes: []int32{0, 2}}}
+	for _, version := range []int16{1, 2, 5, 6, 7} {
+		groups := []domain.OffsetFetchGroup{{GroupID: "group-a", MemberEpoch: -1, Topics: orders}}
+		parsed, err := NewKafkaProtocolParserOffsetFetch().ParseRequest(buildOffsetFetchRequest(version, groups))
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+		if !reflect.DeepEqual(parsed.Groups, groups) || parsed.RequireStable != (version >= 7) {
+			t.Errorf("v%d groups = %+v require stable %v", version, parsed.Groups, parsed.RequireStable)
+		}
+	}
+
+	for _, version := range []int16{8, 9} {
+		groups := []domain.OffsetFetchGroup{
+			{GroupID: "group-a", MemberEpoch: -1, Topics: orders},
+			{GroupID: "group-b", MemberEpoch: -1},
+		}
+		if version >= 9 {
+			groups[1].MemberID = "member-1"
+			groups[1].MemberEpoch = 4
+		}
+		parsed, err := NewKafkaProtocolParserOffsetFetch().ParseRequest(buildOffsetFetchRequest(version, groups))
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+		if !reflect.DeepEqual(parsed.Groups, groups) {
+			t.Errorf("v%d groups = %+v, want %+v", version, parsed.Groups, groups)
+		}
+		if parsed.Groups[1].Topics != nil {
+			t.Errorf("v%d null topics parsed as %+v", version, parsed.Groups[1].Topics)
+		}
+	}
+}
+
+func readOffsetFetchResponseTopics(reader *common.KafkaReader, version int, flexible bool) []domain.OffsetFetchResponseTopic {
+	topics := []domain.OffsetFetchResponseTopic{}
+	topicsLength := reader.ArrayLength("Topics", flexible)
+	for range topicsLength {
+		topic := domain.OffsetFetchResponseTopic{Name: reader.String("Name", flexible)}
+		partitionsLength := reader.ArrayLength("Partitions", flexible)
+		for range partitionsLength {
+			partition := domain.OffsetFetchResponsePartition{
+				PartitionIndex:       reader.Int32("PartitionIndex"),
+				CommittedOffset:      reader.Int64("CommittedOffset"),
+				CommittedLeaderEpoch: -1,
+			}
+			if version >= 5 {
+				partition.CommittedLeaderEpoch = reader.Int32("CommittedLeaderEpoch")
+			}
+			partition.Metadata = reader.String("Metadata", flexible)
+			partition.ErrorCode = reader.Int16("ErrorCode")
+			if flexible {
+				reader.SkipTaggedFields()
+			}
+			topic.Partitions = append(topic.Partitions, partition)
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		topics = append(topics, topic)
+	}
+	return topics
+}
+
+func TestKafkaProtocolParserOffsetFetch_EncodeResponse(t *testing.T) {
+	groups := []domain.OffsetFetchResponseGroup{
+		{
+			GroupID: "group-a",
+			Topics: []domain.OffsetFetchResponseTopic{{
+				Name: "orders",
+				Partitions: []domain.OffsetFetchResponsePartition{
+					{PartitionIndex: 0, CommittedOffset: 42, CommittedLeaderEpoch: 3, Metadata: "checkpoint"},
+					{PartitionIndex: 2, CommittedOffset: -1, CommittedLeaderEpoch: -1},
+				},
+			}},
+		},
+		{GroupID: "group-b", Topics: []domain.OffsetFetchResponseTopic{}, ErrorCode: domain.ErrorCodeCoordinatorNotAvailable},
+	}
+	for _, version := range []int{0, 2, 3, 5, 6, 8, 9} {
+		flexible := version >= 6
+		encoded, err := NewKafkaProtocolParserOffsetFetch().EncodeResponse(&domain.ResponseDataOffsetFetch{
+			CorrelationID: []byte{0x00, 0x00, 0x00, 0x04},
+			APIVersion:    version,
+			Groups:        groups,
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if version >= 3 {
+			reader.Int32("ThrottleTimeMs")
+		}
+		want := groups
+		got := []domain.OffsetFetchResponseGroup{}
+		if version < 8 {
+			want = groups[:1]
+			group := domain.OffsetFetchResponseGroup{GroupID: "group-a", Topics: readOffsetFetchResponseTopics(reader, version, flexible)}
+			if version >= 2 {
+				group.ErrorCode = reader.Int16("ErrorCode")
+			}
+			got = append(got, group)
+		} else {
+			groupsLength := reader.ArrayLength("Groups", flexible)
+			for range groupsLength {
+				group := domain.OffsetFetchResponseGroup{GroupID: reader.String("GroupId", flexible)}
+				group.Topics = readOffsetFetchResponseTopics(reader, version, flexible)
+				group.ErrorCode = reader.Int16("ErrorCode")
+				reader.SkipTaggedFields()
+				got = append(got, group)
+			}
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if version < 5 {
+			want = []domain.OffsetFetchResponseGroup{groups[0]}
+			want[0].Topics = []domain.OffsetFetchResponseTopic{{Name: "orders", Partitions: []domain.OffsetFetchResponsePartition{
+				{PartitionIndex: 0, CommittedOffset: 42, CommittedLeaderEpoch: -1, Metadata: "checkpoint"},
+				{PartitionIndex: 2, CommittedOffset: -1, CommittedLeaderEpoch: -1},
+			}}}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("v%d groups = %+v, want %+v", version, got, want)
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
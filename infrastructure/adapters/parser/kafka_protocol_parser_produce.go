@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// Produce v9+ uses the flexible (compact) encodings
+const produceFirstFlexibleVersion = 9
+
+type KafkaProtocolParserProduce struct{}
+
+// NewKafkaProtocolParserProduce creates a new Kafka Produce protocol parser
+func NewKafkaProtocolParserProduce() parser.ProduceParser {
+	return &KafkaProtocolParserProduce{}
+}
+
+func (p *KafkaProtocolParserProduce) ParseRequest(data []byte) (*domain.ParsedRequestProduce, error) {
+	header, reader, err := parseRequestHeader(data, produceFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	flexible := isFlexible(header.APIVersion, produceFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestProduce{
+		APIKey:        header.APIKey,
+		APIVersion:    header.APIVersion,
+		CorrelationID: header.CorrelationID,
+		ClientID:      header.ClientID,
+	}
+
+	// TransactionalID (nullable string, v3+)
+	if header.APIVersion >= 3 {
+		parsed.TransactionalID = reader.String("TransactionalID", flexible)
+	}
+	parsed.Acks = reader.Int16("Acks")
+	parsed.TimeoutMS = reader.Int32("TimeoutMS")
+
+	topicsLength := reader.ArrayLength("Topics", flexible)
+	for range topicsLength {
+		topic := domain.ProduceTopic{Name: reader.String("Topic name", flexible)}
+
+		partitionsLength := reader.ArrayLength("Partitions", flexible)
+		for range partitionsLength {
+			partition := domain.ProducePartition{
+				PartitionIndex: reader.Int32("PartitionIndex"),
+				Records:        reader.Bytes("Records", flexible),
+			}
+			if flexible {
+				reader.SkipTaggedFields()
+			}
+			topic.Partitions = append(topic.Partitions, partition)
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		parsed.Topics = append(parsed.Topics, topic)
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("Produce", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserProduce) EncodeResponse(response *domain.ResponseDataProduce) ([]byte, error) {
+	version := response.APIVersion
+	flexible := isFlexible(version, produceFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	writer.ArrayLength(len(response.Topics), flexible)
+	for _, topic := range response.Topics {
+		writer.String(topic.Name, flexible)
+
+		writer.ArrayLength(len(topic.Partitions), flexible)
+		for _, partition := range topic.Partitions {
+			writer.Int32(partition.PartitionIndex)
+			writer.Int16(partition.ErrorCode)
+			writer.Int64(partition.BaseOffset)
+			if version >= 2 {
+				writer.Int64(partition.LogAppendTimeMs)
+			}
+			if version >= 5 {
+				writer.Int64(partition.LogStartOffset)
+			}
+			if version >= 8 {
+				// RecordErrors array, per-record errors are not reported
+				writer.ArrayLength(0, flexible)
+				writer.NullableString(nullIfEmpty(partition.ErrorMessage), flexible)
+			}
+			if flexible {
+				writer.EmptyTaggedFields()
+			}
+		}
+		if flexible {
+			writer.EmptyTaggedFields()
+		}
+	}
+
+	if version >= 1 {
+		writer.Int32(response.ThrottleTimeMs)
+	}
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func buildProduceRequest(version int16, flexible bool, records []byte) []byte {
+	w := common.NewKafkaWriter()
+	w.Int16(0)       // API Key
+	w.Int16(version) // API Version
+	w.Int32(42)      // Correlation ID
+	w.String("producer-1", false)
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	w.NullableString(nil, flexible) // TransactionalID
+	w.Int16(-1)                     // Acks
+	w.Int32(30000)                  // TimeoutMS
+	w.ArrayLength(1, flexible)
+	w.String("foo", flexible)
+	w.ArrayLength(1, flexible)
+	w.Int32(1)
+	w.BytesField(records, flexible)
+	if flexible {
+		w.EmptyTaggedFields()
+		w.EmptyTaggedFields()
+		w.EmptyTaggedFields()
+	}
+	return w.WithSizePrefix()
+}
+
+func TestKafkaProtocolParserProduce_ParseRequest(t *testing.T) {
+	records := []byte{0x01, 0x02, 0x03}
+	tests := []struct {
+		name     string
+		version  int16
+		flexible bool
+	}{
+		{name: "classic v7", version: 7, flexible: false},
+		{name: "flexible v9", version: 9, flexible: true},
+		{name: "flexible v11", version: 11, flexible: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := NewKafkaProtocolParserProduce().ParseRequest(buildProduceRequest(tt.version, tt.flexible, records))
+			if err != nil {
+				t.Fatalf("ParseRequest() error = %v", err)
+			}
+
+			want := &domain.ParsedRequestProduce{
+				APIKey:        0,
+				APIVersion:    int(tt.version),
+				CorrelationID: []byte{0x00, 0x00, 0x00, 0x2a},
+				ClientID:      "producer-1",
+				Acks:          -1,
+				TimeoutMS:     30000,
+				Topics: []domain.ProduceTopic{
+					{Name: "foo", Partitions: []domain.ProducePartition{{PartitionIndex: 1, Records: records}}},
+				},
+			}
+			if !reflect.DeepEqual(parsed, want) {
+				t.Errorf("ParseRequest() = %+v, want %+v", parsed, want)
+			}
+		})
+	}
+}
+
+func TestKafkaProtocolParserProduce_ParseRequest_Truncated(t *testing.T) {
+	data := buildProduceRequest(9, true, []byte{0x01, 0x02, 0x03})
+	if _, err := NewKafkaProtocolParserProduce().ParseRequest(data[:len(data)-6]); err == nil {
+		t.Error("ParseRequest() of a truncated request returned no error")
+	}
+}
+
+func TestKafkaProtocolParserProduce_EncodeResponse(t *testing.T) {
+	response := &domain.ResponseDataProduce{
+		CorrelationID: []byte{0x00, 0x00, 0x00, 0x2a},
+		APIVersion:    9,
+		Topics: []domain.ProduceResponseTopic{
+			{Name: "foo", Partitions: []domain.ProduceResponsePartition{
+				{PartitionIndex: 1, ErrorCode: 0, BaseOffset: 5, LogAppendTimeMs: -1, LogStartOffset: 0},
+			}},
+		},
+	}
+
+	encoded, err := NewKafkaProtocolParserProduce().EncodeResponse(response)
+	if err != nil {
+		t.Fatalf("EncodeResponse() error = %v", err)
+	}
+	if size := int(binary.BigEndian.Uint32(encoded[:4])); size != len(encoded)-4 {
+		t.Fatalf("message size = %d, want %d", size, len(encoded)-4)
+	}
+
+	reader := common.NewKafkaReader(encoded, 4)
+	if correlationID := reader.Int32("CorrelationID"); correlationID != 42 {
+		t.Errorf("CorrelationID = %d, want 42", correlationID)
+	}
+	reader.SkipTaggedFields()
+	if topics := reader.ArrayLength("Topics", true); topics != 1 {
+		t.Fatalf("Topics length = %d, want 1", topics)
+	}
+	if name := reader.String("Name", true); name != "foo" {
+		t.Errorf("Topic name = %q, want foo", name)
+	}
+	reader.ArrayLength("Partitions", true)
+	if index := reader.Int32("PartitionIndex"); index != 1 {
+		t.Errorf("PartitionIndex = %d, want 1", index)
+	}
+	if errorCode := reader.Int16("ErrorCode"); errorCode != 0 {
+		t.Errorf("ErrorCode = %d, want 0", errorCode)
+	}
+	if baseOffset := reader.Int64("BaseOffset"); baseOffset != 5 {
+		t.Errorf("BaseOffset = %d, want 5", baseOffset)
+	}
+	if err := reader.Err(); err != nil {
+		t.Errorf("reading response: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// SyncGroup v4+ uses the flexible (compact) encodings
+const syncGroupFirstFlexibleVersion = 4
+
+type KafkaProtocolParserSyncGroup struct{}
+
+// NewKafkaProtocolParserSyncGroup creates a new Kafka SyncGroup protocol parser
+func NewKafkaProtocolParserSyncGroup() parser.SyncGroupParser {
+	return &KafkaProtocolParserSyncGroup{}
+}
+
+func (p *KafkaProtocolParserSyncGroup) ParseRequest(data []byte) (*domain.ParsedRequestSyncGroup, error) {
+	header, reader, err := parseRequestHeader(data, syncGroupFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	version := header.APIVersion
+	flexible := isFlexible(version, syncGroupFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestSyncGroup{
+		APIKey:        header.APIKey,
+		APIVersion:    header.APIVersion,
+		CorrelationID: header.CorrelationID,
+		ClientID:      header.ClientID,
+		GroupID:       reader.String("GroupId", flexible),
+		GenerationID:  reader.Int32("GenerationId"),
+		MemberID:      reader.String("MemberId", flexible),
+	}
+	if version >= 3 {
+		parsed.GroupInstanceID, _ = reader.NullableString("GroupInstanceId", flexible)
+	}
+	if version >= 5 {
+		parsed.ProtocolType, _ = reader.NullableString("ProtocolType", flexible)
+		parsed.ProtocolName, _ = reader.NullableString("ProtocolName", flexible)
+	}
+
+	assignmentsLength := reader.ArrayLength("Assignments", flexible)
+	for range assignmentsLength {
+		parsed.Assignments = append(parsed.Assignments, domain.SyncGroupAssignment{
+			MemberID:   reader.String("MemberId", flexible),
+			Assignment: reader.Bytes("Assignment", flexible),
+		})
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("SyncGroup", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserSyncGroup) EncodeResponse(response *domain.ResponseDataSyncGroup) ([]byte, error) {
+	version := response.APIVersion
+	flexible := isFlexible(version, syncGroupFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	if version >= 1 {
+		writer.Int32(response.ThrottleTimeMs)
+	}
+	writer.Int16(response.ErrorCode)
+	if version >= 5 {
+		writer.NullableString(nullIfEmpty(response.ProtocolType), flexible)
+		writer.NullableString(nullIfEmpty(response.ProtocolName), flexible)
+	}
+	writer.BytesField(nonNilBytes(response.Assignment), flexible)
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func buildSyncGroupRequest(version int16) []byte {
+	flexible := version >= 4
+	w := common.NewKafkaWriter()
+	w.Int16(14)
+	w.Int16(version)
+	w.Int32(6)
+	w.String("consumer-1", false)
+	if flexible {
+		w.EmptyTaggedFields()
+	}
+	w.String("group-a", flexible)
+	w.Int32(2) // GenerationId
+	w.String("member-1", flexible)
+	if version >= 3 {
+		w.NullableString(nil, flexible) // GroupInstanceId
+	}
+	if version >= 5 {
+		protocolType, protocolName := "consumer", "range"
+		w.NullableString(&protocolType, flexible)
+		w.NullableString(&protocolName, flexible)
+	}
+	w.ArrayLength(1, flexible)
+	w.String("member-1", flexible)
+	w.BytesField([]byte{0x00, 0x01}, flexible)
+	if flexible {
+		w.EmptyTaggedFields()
+		w.EmptyTaggedFields()
+	}
+	return w.WithSizePrefix()
+}
+
+func TestKafkaProtocolParserSyncGroup_ParseRequest(t *testing.T) {
+	for _, version := range []int16{0, 3, 4, 5} {
+		parsed, err := NewKafkaProtocolParserSyncGroup().ParseRequest(buildSyncGroupRequest(version))
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+
+		if parsed.GroupID != "group-a" || parsed.GenerationID != 2 || parsed.MemberID != "member-1" || parsed.GroupInstanceID != "" {
+			t.Errorf("v%d ParseRequest() = %+v", version, parsed)
+		}
+		if version >= 5 && (parsed.ProtocolType != "consumer" || parsed.ProtocolName != "range") {
+			t.Errorf("v%d protocol = %q %q, want consumer range", version, parsed.ProtocolType, parsed.ProtocolName)
+		}
+		want := []domain.SyncGroupAssignment{{MemberID: "member-1", Assignment: []byte{0x00, 0x01}}}
+		if !reflect.DeepEqual(parsed.Assignments, want) {
+			t.Errorf("v%d Assignments = %+v, want %+v", version, parsed.Assignments, want)
+		}
+	}
+}
+
+func TestKafkaProtocolParserSyncGroup_EncodeResponse(t *testing.T) {
+	for _, version := range []int{0, 1, 4, 5} {
+		flexible := version >= 4
+		encoded, err := NewKafkaProtocolParserSyncGroup().EncodeResponse(&domain.ResponseDataSyncGroup{
+			CorrelationID: []byte{0x00, 0x00, 0x00, 0x06},
+			APIVersion:    version,
+			ProtocolType:  "consumer",
+			ProtocolName:  "range",
+			Assignment:    []byte{0x00, 0x01},
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if version >= 1 {
+			reader.Int32("ThrottleTimeMs")
+		}
+		if errorCode := reader.Int16("ErrorCode"); errorCode != domain.ErrorCodeNone {
+			t.Errorf("v%d ErrorCode = %d", version, errorCode)
+		}
+		if version >= 5 {
+			protocolType, protocolName := reader.String("ProtocolType", flexible), reader.String("ProtocolName", flexible)
+			if protocolType != "consumer" || protocolName != "range" {
+				t.Errorf("v%d protocol = %q %q, want consumer range", version, protocolType, protocolName)
+			}
+		}
+		if assignment := reader.Bytes("Assignment", flexible); !bytes.Equal(assignment, []byte{0x00, 0x01}) {
+			t.Errorf("v%d Assignment = %x", version, assignment)
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// TxnOffsetCommit v3+ uses the flexible (compact) encodings
+const txnOffsetCommitFirstFlexibleVersion = 3
+
+type KafkaProtocolParserTxnOffsetCommit struct{}
+
+// NewKafkaProtocolParserTxnOffsetCommit creates a new Kafka TxnOffsetCommit protocol parser
+func NewKafkaProtocolParserTxnOffsetCommit() parser.TxnOffsetCommitParser {
+	return &KafkaProtocolParserTxnOffsetCommit{}
+}
+
+func (p *KafkaProtocolParserTxnOffsetCommit) ParseRequest(data []byte) (*domain.ParsedRequestTxnOffsetCommit, error) {
+	header, reader, err := parseRequestHeader(data, txnOffsetCommitFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	version := header.APIVersion
+	flexible := isFlexible(version, txnOffsetCommitFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestTxnOffsetCommit{
+		APIKey:          header.APIKey,
+		APIVersion:      header.APIVersion,
+		CorrelationID:   header.CorrelationID,
+		ClientID:        header.ClientID,
+		TransactionalID: reader.String("TransactionalId", flexible),
+		GroupID:         reader.String("GroupId", flexible),
+		ProducerID:      reader.Int64("ProducerId"),
+		ProducerEpoch:   reader.Int16("ProducerEpoch"),
+		GenerationID:    -1,
+	}
+	if version >= 3 {
+		parsed.GenerationID = reader.Int32("GenerationId")
+		parsed.MemberID = reader.String("MemberId", flexible)
+		parsed.GroupInstanceID, _ = reader.NullableString("GroupInstanceId", flexible)
+	}
+
+	topicsLength := reader.ArrayLength("Topics", flexible)
+	for range topicsLength {
+		topic := domain.TxnOffsetCommitTopic{Name: reader.String("Name", flexible)}
+		partitionsLength := reader.ArrayLength("Partitions", flexible)
+		for range partitionsLength {
+			partition := domain.TxnOffsetCommitPartition{
+				PartitionIndex:       reader.Int32("PartitionIndex"),
+				CommittedOffset:      reader.Int64("CommittedOffset"),
+				CommittedLeaderEpoch: -1,
+			}
+			if version >= 2 {
+				partition.CommittedLeaderEpoch = reader.Int32("CommittedLeaderEpoch")
+			}
+			partition.CommittedMetadata, _ = reader.NullableString("CommittedMetadata", flexible)
+			if flexible {
+				reader.SkipTaggedFields()
+			}
+			topic.Partitions = append(topic.Partitions, partition)
+		}
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		parsed.Topics = append(parsed.Topics, topic)
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("TxnOffsetCommit", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserTxnOffsetCommit) EncodeResponse(response *domain.ResponseDataTxnOffsetCommit) ([]byte, error) {
+	flexible := isFlexible(response.APIVersion, txnOffsetCommitFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	writer.Int32(response.ThrottleTimeMs)
+	writer.ArrayLength(len(response.Topics), flexible)
+	for _, topic := range response.Topics {
+		writer.String(topic.Name, flexible)
+		writer.ArrayLength(len(topic.Partitions), flexible)
+		for _, partition := range topic.Partitions {
+			writer.Int32(partition.PartitionIndex)
+			writer.Int16(partition.ErrorCode)
+			if flexible {
+				writer.EmptyTaggedFields()
+			}
+		}
+		if flexible {
+			writer.EmptyTaggedFields()
+		}
+	}
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func TestKafkaProtocolParserTxnOffsetCommit_ParseRequest(t *testing.T) {
+	for _, version := range []int16{0, 2, 3} {
+		flexible := version >= 3
+		w := common.NewKafkaWriter()
+		w.Int16(28)
+		w.Int16(version)
+		w.Int32(7)
+		w.String("producer-1", false)
+		if flexible {
+			w.EmptyTaggedFields()
+		}
+		w.String("txn-1", flexible)
+		w.String("group-a", flexible)
+		w.Int64(4000)
+		w.Int16(2)
+		if version >= 3 {
+			w.Int32(6)
+			w.String("member-a", flexible)
+			w.NullableString(nil, flexible)
+		}
+		w.ArrayLength(1, flexible)
+		w.String("orders", flexible)
+		w.ArrayLength(1, flexible)
+		w.Int32(1)
+		w.Int64(42)
+		if version >= 2 {
+			w.Int32(3)
+		}
+		metadata := "checkpoint"
+		w.NullableString(&metadata, flexible)
+		if flexible {
+			w.EmptyTaggedFields()
+			w.EmptyTaggedFields()
+			w.EmptyTaggedFields()
+		}
+
+		parsed, err := NewKafkaProtocolParserTxnOffsetCommit().ParseRequest(w.WithSizePrefix())
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+		wantGeneration, wantMember, wantLeaderEpoch := int32(-1), "", int32(-1)
+		if version >= 2 {
+			wantLeaderEpoch = 3
+		}
+		if version >= 3 {
+			wantGeneration, wantMember = 6, "member-a"
+		}
+		if parsed.TransactionalID != "txn-1" || parsed.GroupID != "group-a" || parsed.ProducerID != 4000 || parsed.ProducerEpoch != 2 ||
+			parsed.GenerationID != wantGeneration || parsed.MemberID != wantMember || parsed.GroupInstanceID != "" {
+			t.Errorf("v%d ParseRequest() = %+v", version, parsed)
+		}
+		if len(parsed.Topics) != 1 || len(parsed.Topics[0].Partitions) != 1 {
+			t.Fatalf("v%d Topics = %+v, want one partition", version, parsed.Topics)
+		}
+		want := domain.TxnOffsetCommitPartition{PartitionIndex: 1, CommittedOffset: 42, CommittedLeaderEpoch: wantLeaderEpoch, CommittedMetadata: "checkpoint"}
+		if partition := parsed.Topics[0].Partitions[0]; partition != want {
+			t.Errorf("v%d partition = %+v, want %+v", version, partition, want)
+		}
+	}
+}
+
+func TestKafkaProtocolParserTxnOffsetCommit_EncodeResponse(t *testing.T) {
+	for _, version := range []int{0, 3} {
+		flexible := version >= 3
+		encoded, err := NewKafkaProtocolParserTxnOffsetCommit().EncodeResponse(&domain.ResponseDataTxnOffsetCommit{
+			CorrelationID: []byte{0x00, 0x00, 0x00, 0x07},
+			APIVersion:    version,
+			Topics: []domain.TxnOffsetCommitResponseTopic{{
+				Name:       "orders",
+				Partitions: []domain.TxnOffsetCommitResponsePartition{{PartitionIndex: 1, ErrorCode: domain.ErrorCodeNone}},
+			}},
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		reader.Int32("ThrottleTimeMs")
+		reader.ArrayLength("Topics", flexible)
+		if name := reader.String("Name", flexible); name != "orders" {
+			t.Errorf("v%d Name = %q, want orders", version, name)
+		}
+		reader.ArrayLength("Partitions", flexible)
+		if partition, errorCode := reader.Int32("PartitionIndex"), reader.Int16("ErrorCode"); partition != 1 || errorCode != domain.ErrorCodeNone {
+			t.Errorf("v%d partition %d error %d, want 1 without error", version, partition, errorCode)
+		}
+		if flexible {
+			for range 3 {
+				reader.SkipTaggedFields()
+			}
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
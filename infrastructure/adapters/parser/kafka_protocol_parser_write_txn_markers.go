@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+)
+
+// WriteTxnMarkers v1+ uses the flexible (compact) encodings
+const writeTxnMarkersFirstFlexibleVersion = 1
+
+type KafkaProtocolParserWriteTxnMarkers struct{}
+
+// NewKafkaProtocolParserWriteTxnMarkers creates a new Kafka WriteTxnMarkers protocol parser
+func NewKafkaProtocolParserWriteTxnMarkers() parser.WriteTxnMarkersParser {
+	return &KafkaProtocolParserWriteTxnMarkers{}
+}
+
+func (p *KafkaProtocolParserWriteTxnMarkers) ParseRequest(data []byte) (*domain.ParsedRequestWriteTxnMarkers, error) {
+	header, reader, err := parseRequestHeader(data, writeTxnMarkersFirstFlexibleVersion)
+	if err != nil {
+		return nil, err
+	}
+	flexible := isFlexible(header.APIVersion, writeTxnMarkersFirstFlexibleVersion)
+
+	parsed := &domain.ParsedRequestWriteTxnMarkers{
+		APIKey:        header.APIKey,
+		APIVersion:    header.APIVersion,
+		CorrelationID: header.CorrelationID,
+		ClientID:      header.ClientID,
+	}
+	markersLength := reader.ArrayLength("Markers", flexible)
+	for range markersLength {
+		marker := domain.WritableTxnMarker{
+			ProducerID:        reader.Int64("ProducerId"),
+			ProducerEpoch:     reader.Int16("ProducerEpoch"),
+			TransactionResult: reader.Bool("TransactionResult"),
+		}
+		topicsLength := reader.ArrayLength("Topics", flexible)
+		for range topicsLength {
+			topic := domain.WritableTxnMarkerTopic{
+				Name:             reader.String("Name", flexible),
+				PartitionIndexes: reader.Int32Array("PartitionIndexes", flexible),
+			}
+			if flexible {
+				reader.SkipTaggedFields()
+			}
+			marker.Topics = append(marker.Topics, topic)
+		}
+		marker.CoordinatorEpoch = reader.Int32("CoordinatorEpoch")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		parsed.Markers = append(parsed.Markers, marker)
+	}
+	if flexible {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, invalidRequestError("WriteTxnMarkers", err)
+	}
+	return parsed, nil
+}
+
+func (p *KafkaProtocolParserWriteTxnMarkers) EncodeResponse(response *domain.ResponseDataWriteTxnMarkers) ([]byte, error) {
+	flexible := isFlexible(response.APIVersion, writeTxnMarkersFirstFlexibleVersion)
+	writer := newResponseWriter(response.CorrelationID, flexible)
+
+	writer.ArrayLength(len(response.Markers), flexible)
+	for _, marker := range response.Markers {
+		writer.Int64(marker.ProducerID)
+		writer.ArrayLength(len(marker.Topics), flexible)
+		for _, topic := range marker.Topics {
+			writer.String(topic.Name, flexible)
+			writer.ArrayLength(len(topic.Partitions), flexible)
+			for _, partition := range topic.Partitions {
+				writer.Int32(partition.PartitionIndex)
+				writer.Int16(partition.ErrorCode)
+				if flexible {
+					writer.EmptyTaggedFields()
+				}
+			}
+			if flexible {
+				writer.EmptyTaggedFields()
+			}
+		}
+		if flexible {
+			writer.EmptyTaggedFields()
+		}
+	}
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+
+	return writer.WithSizePrefix(), nil
+}
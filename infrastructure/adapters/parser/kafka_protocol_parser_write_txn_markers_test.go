@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func TestKafkaProtocolParserWriteTxnMarkers_ParseRequest(t *testing.T) {
+	for _, version := range []int16{0, 1} {
+		flexible := version >= 1
+		w := common.NewKafkaWriter()
+		w.Int16(27)
+		w.Int16(version)
+		w.Int32(7)
+		w.String("coordinator", false)
+		if flexible {
+			w.EmptyTaggedFields()
+		}
+		w.ArrayLength(1, flexible)
+		w.Int64(4000)
+		w.Int16(2)
+		w.Bool(true)
+		w.ArrayLength(1, flexible)
+		w.String("orders", flexible)
+		w.Int32Array([]int32{1, 2}, flexible)
+		if flexible {
+			w.EmptyTaggedFields()
+		}
+		w.Int32(5)
+		if flexible {
+			w.EmptyTaggedFields()
+			w.EmptyTaggedFields()
+		}
+
+		parsed, err := NewKafkaProtocolParserWriteTxnMarkers().ParseRequest(w.WithSizePrefix())
+		if err != nil {
+			t.Fatalf("v%d ParseRequest() error = %v", version, err)
+		}
+		if len(parsed.Markers) != 1 {
+			t.Fatalf("v%d %d markers, want 1", version, len(parsed.Markers))
+		}
+		marker := parsed.Markers[0]
+		if marker.ProducerID != 4000 || marker.ProducerEpoch != 2 || !marker.TransactionResult || marker.CoordinatorEpoch != 5 {
+			t.Errorf("v%d marker = %+v", version, marker)
+		}
+		if len(marker.Topics) != 1 || marker.Topics[0].Name != "orders" || !slices.Equal(marker.Topics[0].PartitionIndexes, []int32{1, 2}) {
+			t.Errorf("v%d marker topics = %+v, want orders [1 2]", version, marker.Topics)
+		}
+	}
+}
+
+func TestKafkaProtocolParserWriteTxnMarkers_EncodeResponse(t *testing.T) {
+	for _, version := range []int{0, 1} {
+		flexible := version >= 1
+		encoded, err := NewKafkaProtocolParserWriteTxnMarkers().EncodeResponse(&domain.ResponseDataWriteTxnMarkers{
+			CorrelationID: []byte{0x00, 0x00, 0x00, 0x07},
+			APIVersion:    version,
+			Markers: []domain.WritableTxnMarkerResult{{
+				ProducerID: 4000,
+				Topics: []domain.WritableTxnMarkerTopicResult{{
+					Name:       "orders",
+					Partitions: []domain.WritableTxnMarkerPartitionResult{{PartitionIndex: 1, ErrorCode: domain.ErrorCodeUnknownTopicOrPartition}},
+				}},
+			}},
+		})
+		if err != nil {
+			t.Fatalf("v%d EncodeResponse() error = %v", version, err)
+		}
+
+		reader := common.NewKafkaReader(encoded, 4)
+		reader.Int32("CorrelationID")
+		if flexible {
+			reader.SkipTaggedFields()
+		}
+		reader.ArrayLength("Markers", flexible)
+		if producerID := reader.Int64("ProducerId"); producerID != 4000 {
+			t.Errorf("v%d ProducerId = %d, want 4000", version, producerID)
+		}
+		reader.ArrayLength("Topics", flexible)
+		if name := reader.String("Name", flexible); name != "orders" {
+			t.Errorf("v%d Name = %q, want orders", version, name)
+		}
+		reader.ArrayLength("Partitions", flexible)
+		if partition, errorCode := reader.Int32("PartitionIndex"), reader.Int16("ErrorCode"); partition != 1 || errorCode != domain.ErrorCodeUnknownTopicOrPartition {
+			t.Errorf("v%d partition %d error %d", version, partition, errorCode)
+		}
+		if flexible {
+			for range 4 {
+				reader.SkipTaggedFields()
+			}
+		}
+		if err := reader.Err(); err != nil || reader.Remaining() != 0 {
+			t.Errorf("v%d response error %v with %d trailing bytes", version, err, reader.Remaining())
+		}
+	}
+}
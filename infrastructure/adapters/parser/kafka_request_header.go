@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// requestHeader holds the fields of request header v1 (classic) and v2 (flexible)
+type requestHeader struct {
+	APIKey        int
+	APIVersion    int
+	CorrelationID []byte
+	ClientID      string
+}
+
+// parseRequestHeader reads the request header that follows the 4-byte message size.
+// Requests at or above firstFlexibleVersion use header v2, which ends in a tag buffer.
+// The returned reader is positioned at the start of the request body.
+func parseRequestHeader(data []byte, firstFlexibleVersion int) (requestHeader, *common.KafkaReader, error) {
+	if len(data) < 12 {
+		return requestHeader{}, nil, ErrInvalidRequest
+	}
+
+	reader := common.NewKafkaReader(data, 4) // Skip message size (4 bytes)
+	header := requestHeader{
+		APIKey:     int(reader.Int16("APIKey")),
+		APIVersion: int(reader.Int16("APIVersion")),
+	}
+	header.CorrelationID = data[8:12]
+	reader.Int32("CorrelationID")
+
+	// ClientID is always a classic nullable string, even in header v2
+	header.ClientID = reader.String("ClientID", false)
+	if isFlexible(header.APIVersion, firstFlexibleVersion) {
+		reader.SkipTaggedFields()
+	}
+
+	if err := reader.Err(); err != nil {
+		return requestHeader{}, nil, &ParseError{Message: "invalid request header: " + err.Error()}
+	}
+	return header, reader, nil
+}
+
+// isFlexible reports whether a version uses the compact/tagged-field encodings.
+// A negative firstFlexibleVersion means the API has no flexible versions.
+func isFlexible(apiVersion int, firstFlexibleVersion int) bool {
+	return firstFlexibleVersion >= 0 && apiVersion >= firstFlexibleVersion
+}
+
+// newResponseWriter starts a response with response header v0 (correlation ID only)
+// or, for flexible versions, header v1 (correlation ID and a tag buffer)
+func newResponseWriter(correlationID []byte, flexible bool) *common.KafkaWriter {
+	writer := common.NewKafkaWriter()
+	writer.Raw(correlationID)
+	if flexible {
+		writer.EmptyTaggedFields()
+	}
+	return writer
+}
+
+// invalidRequestError wraps a reader error into the parser's error type
+func invalidRequestError(apiName string, err error) *ParseError {
+	return &ParseError{Message: "invalid " + apiName + " request: " + err.Error()}
+}
@@ -1,285 +1,358 @@
 package cluster_metadata_repository
 
 import (
-	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/codecrafters-io/kafka-starter-go/core/domain"
-	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
 	clutser_metadata_port "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
 	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
 )
 
-type ClusterMetadata struct {
-	*clutser_metadata_port.ClusterMetadataRepositoryResponse
-}
+// DefaultMetadataLogDir is where KRaft keeps the __cluster_metadata-0 partition
+const DefaultMetadataLogDir = "/tmp/kraft-combined-logs/__cluster_metadata-0"
+
+// DefaultPollInterval is how often the metadata log is checked for new batches
+const DefaultPollInterval = 500 * time.Millisecond
+
+// DefaultSnapshotMaxBytes and DefaultSnapshotMaxRecords are how much of the metadata log
+// is applied before the image is written out as a snapshot
+const (
+	DefaultSnapshotMaxBytes   = 20 * 1024 * 1024
+	DefaultSnapshotMaxRecords = 100_000
+)
+
+type ClusterMetadataOption func(*ClusterMetadata)
 
-func NewClusterMetadataRepository() *ClusterMetadata {
-	return &ClusterMetadata{}
+// WithMetadataLogDir reads the metadata log from dir instead of DefaultMetadataLogDir
+func WithMetadataLogDir(dir string) ClusterMetadataOption {
+	return func(c *ClusterMetadata) {
+		c.logDir = dir
+	}
 }
 
-func (c *ClusterMetadata) GetClusterMetadata() (clutser_metadata_port.ClusterMetadataRepositoryResponse, error) {
+// WithPollInterval changes how often Start's tailer checks the metadata log
+func WithPollInterval(interval time.Duration) ClusterMetadataOption {
+	return func(c *ClusterMetadata) {
+		c.pollInterval = interval
+	}
+}
 
-	c.ClusterMetadataRepositoryResponse = &clutser_metadata_port.ClusterMetadataRepositoryResponse{}
-	c.ClusterMetadataRepositoryResponse.TopicUUIDTopicMetadataInfoMap = make(map[string]*clutser_metadata_port.TopicMetadataInfo)
-	c.ClusterMetadataRepositoryResponse.TopicUUIDPartitionMetadataMap = make(map[string][]*domain.PartitionMetadata)
-	c.ClusterMetadataRepositoryResponse.TopicNameTopicUuidMap = make(map[string]string)
-
-	// 1. Parse a record batch
-	// 2. Find the Records Array
-	// 3. Figure out if the record in the record array is 1. A Topic Record, 2. A Partition Record, 3. A feature level record
-	//	- If 1, Use the information in there to build the topic portion of the  DescribeTopicPartitions Response Body
-	//  - If 2, Use the information to build the partitions array within the topic
-	//	- If 3, ignore
-
-	//Values to get from metadata
-	/*
-		error_code					INT16			Error code (0 for valid partitions)
-		partition_index				INT32			The partition ID
-		leader_id					INT32			The broker ID hosting this partition
-		leader_epoch				INT32			The leader epoch
-		replica_nodes				COMPACT_ARRAY	Array of replica broker IDs
-		isr_nodes					COMPACT_ARRAY	Array of in-sync replica broker IDs
-		eligible_leader_replicas	COMPACT_ARRAY	Array of eligible leader replica broker IDs
-		last_known_elr				COMPACT_ARRAY	Array of last known eligible leader replicas
-		offline_replicas			COMPACT_ARRAY	Array of offline replica broker IDs
-		TAG_BUFFER					TAGGED_FIELDS	Tagged fields
-	*/
-
-	data, err := os.ReadFile("/tmp/kraft-combined-logs/__cluster_metadata-0/00000000000000000000.log")
-	if err != nil {
-		return clutser_metadata_port.ClusterMetadataRepositoryResponse{}, err
+// WithSnapshotThresholds snapshots the image once maxBytes of log or maxRecords records
+// were applied since the last snapshot. Zero disables a threshold.
+func WithSnapshotThresholds(maxBytes int64, maxRecords int64) ClusterMetadataOption {
+	return func(c *ClusterMetadata) {
+		c.snapshotMaxBytes = maxBytes
+		c.snapshotMaxRecords = maxRecords
 	}
+}
 
-	//data := []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x4f, 0x00, 0x00, 0x00, 0x01, 0x02, 0xb0, 0x69, 0x45, 0x7c, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x91, 0xe0, 0x5a, 0xf8, 0x18, 0x00, 0x00, 0x01, 0x91, 0xe0, 0x5a, 0xf8, 0x18, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x01, 0x3a, 0x00, 0x00, 0x00, 0x01, 0x2e, 0x01, 0x0c, 0x00, 0x11, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x2e, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x00, 0x14, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0xe4, 0x00, 0x00, 0x00, 0x01, 0x02, 0x24, 0xdb, 0x12, 0xdd, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x01, 0x91, 0xe0, 0x5b, 0x2d, 0x15, 0x00, 0x00, 0x01, 0x91, 0xe0, 0x5b, 0x2d, 0x15, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x03, 0x3c, 0x00, 0x00, 0x00, 0x01, 0x30, 0x01, 0x02, 0x00, 0x04, 0x73, 0x61, 0x7a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x91, 0x00, 0x00, 0x90, 0x01, 0x00, 0x00, 0x02, 0x01, 0x82, 0x01, 0x01, 0x03, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x91, 0x02, 0x00, 0x00, 0x00, 0x01, 0x02, 0x00, 0x00, 0x00, 0x01, 0x01, 0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x10, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x90, 0x01, 0x00, 0x00, 0x04, 0x01, 0x82, 0x01, 0x01, 0x03, 0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x91, 0x02, 0x00, 0x00, 0x00, 0x01, 0x02, 0x00, 0x00, 0x00, 0x01, 0x01, 0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x10, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00}
+// ClusterMetadata serves an immutable image of the cluster metadata log. The image is
+// built once from the newest snapshot and the log after it, then only batches appended
+// since are applied to a copy that replaces it atomically, so a handler always sees one
+// consistent snapshot.
+type ClusterMetadata struct {
+	logDir             string
+	pollInterval       time.Duration
+	snapshotMaxBytes   int64
+	snapshotMaxRecords int64
+	image              atomic.Pointer[clutser_metadata_port.ClusterMetadataRepositoryResponse]
+
+	mu           sync.Mutex // Serializes refreshes
+	segmentBase  int64      // Base offset of the log segment being tailed
+	position     int64      // Bytes of that segment applied to the image
+	nextOffset   int64      // Offset of the next metadata record to apply
+	logEndOffset int64      // Offset the next batch appended to the log gets
+	transaction  metadataTransaction
+
+	lastEpoch            int32 // Leader epoch of the last batch applied
+	lastTimestamp        int64 // Max timestamp of the last batch applied
+	bytesSinceSnapshot   int64
+	recordsSinceSnapshot int64
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
 
-	c.processRecordBatches(data)
+func NewClusterMetadataRepository(opts ...ClusterMetadataOption) *ClusterMetadata {
+	c := &ClusterMetadata{
+		logDir:             DefaultMetadataLogDir,
+		pollInterval:       DefaultPollInterval,
+		snapshotMaxBytes:   DefaultSnapshotMaxBytes,
+		snapshotMaxRecords: DefaultSnapshotMaxRecords,
+		stop:               make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
 
-	return *c.ClusterMetadataRepositoryResponse, nil
+// Start builds the image and keeps tailing the metadata log until Close is called
+func (c *ClusterMetadata) Start() error {
+	err := c.refresh()
+	go c.tail()
+	return err
 }
 
-func ProcessRecordBatchesPublic(data []byte) ClusterMetadata {
-	x := ClusterMetadata{}
-	x.processRecordBatches(data)
-	return x
+func (c *ClusterMetadata) Close() {
+	c.stopOnce.Do(func() { close(c.stop) })
 }
 
-func (c *ClusterMetadata) processRecordBatches(data []byte) {
-	currentOffset := 0
+func (c *ClusterMetadata) tail() {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
 	for {
-		if currentOffset >= len(data) {
-			break
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			if err := c.refresh(); err != nil && !errors.Is(err, os.ErrNotExist) {
+				fmt.Printf("Refreshing cluster metadata failed: %v\n", err)
+			}
 		}
-
-		baseOffset := common.BytesToInt(data[currentOffset : currentOffset+8])
-		currentOffset += 8
-		_ = baseOffset
-
-		batchLength := common.BytesToInt(data[currentOffset : currentOffset+4]) //batchLength will be the total bytes of a RecordBatch without the Base Offset and Batch Length
-		currentOffset += 4
-		_ = batchLength
-
-		currentOffset = currentOffset + 4 + 1 + 4 + 2 + 4 + 8 + 8 + 8 + 2 + 4
-		// Skipping the following values:
-		// - Partition Leader Epoch
-		//- Magic Byte
-		//- CRC
-		//- Attributes
-		//- Last Offset Delta
-		//- Base Timestamp
-		//- Max Timestamp
-		//- Producer ID
-		//- Producer Epoch
-		//- Base Sequence
-		recordsLength := common.BytesToInt(data[currentOffset : currentOffset+4])
-		c.RecordsLength = recordsLength
-		currentOffset = currentOffset + 4
-		c.StartingRecordOffset = currentOffset
-
-		allRecordsBytesRead := c.processRecords(data, currentOffset, recordsLength)
-		currentOffset += allRecordsBytesRead
 	}
 }
 
-func (c *ClusterMetadata) processRecords(data []byte, offset int, totalRecordsToProcess int) int {
-	originalOffset := offset
-	for range totalRecordsToProcess {
-		totalRecordBytesRead := c.processRecord(data, offset)
-		offset += totalRecordBytesRead
+// GetClusterMetadata returns a copy of the current metadata image that the caller owns.
+// Without Start the image is built on first use.
+func (c *ClusterMetadata) GetClusterMetadata() (clutser_metadata_port.ClusterMetadataRepositoryResponse, error) {
+	if image := c.image.Load(); image != nil {
+		return copyImage(image), nil
+	}
+	if err := c.refresh(); err != nil {
+		return clutser_metadata_port.ClusterMetadataRepositoryResponse{}, err
 	}
-	return offset - originalOffset
+	return copyImage(c.image.Load()), nil
 }
 
-func (c *ClusterMetadata) processRecord(data []byte, offset int) int {
-	originalOffset := offset
+// refresh brings the image up to date with the metadata log and snapshots it when enough
+// of the log was applied since the last snapshot
+func (c *ClusterMetadata) refresh() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	recordLength, totalBytesRead := common.ReadVarIntSigned(offset, data)
-	offset += totalBytesRead
-
-	offset += 1 // Read Attributes
-
-	timestampDelta, totalBytesRead := common.ReadVarIntSigned(offset, data)
-	offset += totalBytesRead
-
-	offsetDelta, totalBytesRead := common.ReadVarIntSigned(offset, data)
-	offset += totalBytesRead
-
-	keyLength, totalBytesRead := common.ReadVarIntSigned(offset, data)
-	offset += totalBytesRead
-
-	valueLength, totalBytesRead := common.ReadVarIntSigned(offset, data)
-	offset += totalBytesRead
-
-	c.processRecordValue(data, offset)
-	offset += valueLength
-
-	_ = recordLength
-	_ = timestampDelta
-	_ = offsetDelta
-	_ = keyLength
-	_ = valueLength
-
-	offset += 1 // Add one for headers array count
-	allBytesRead := offset - originalOffset
-	return allBytesRead
+	if err := c.update(); err != nil {
+		return err
+	}
+	c.maybeSnapshot()
+	return nil
 }
 
-func (c *ClusterMetadata) processRecordValue(data []byte, offset int) {
-	frameVersion := data[offset]
-	offset += 1
-
-	recordValueType := data[offset]
-	offset += 1
+// update applies the complete batches appended to the metadata log since the last
+// refresh to a copy of the image and publishes it. When the segment being tailed shrank
+// or disappeared, the log was replaced and the image is rebuilt.
+func (c *ClusterMetadata) update() error {
+	current := c.image.Load()
+	if current == nil {
+		return c.reload()
+	}
 
-	fmt.Println(">>>>>>>>>>>>>> recordValueType ", recordValueType)
-	switch recordValueType {
-	case 0x00:
-		return
-	case 0x01:
-		// Feature Level Record, we ignore it
-		return
-	case 0x02:
-		c.processTopicRecord(data, offset)
-		// Topic Level Record
-	case 0x03:
-		c.processPartitionRecord(data, offset)
-		return
+	segments, err := listMetadataLogSegments(c.logDir)
+	if err != nil {
+		return err
+	}
+	index := slices.Index(segments, c.segmentBase)
+	if index < 0 && len(segments) == 0 {
+		return nil // Only a snapshot, nothing was appended after it yet
+	}
+	if index < 0 {
+		fmt.Printf("Metadata log segment %d is gone, reloading cluster metadata\n", c.segmentBase)
+		return c.reload()
+	}
 
+	next := newMetadataImage(current, &c.transaction)
+	changed, err := c.tailSegments(next, segments[index:])
+	if errors.Is(err, errMetadataLogTruncated) {
+		fmt.Printf("%v, reloading cluster metadata\n", err)
+		return c.reload()
 	}
-	_ = frameVersion
+	// The cursor moved past every batch applied to next, so next is published even when
+	// tailing stopped at an error
+	if changed {
+		c.image.Store(next.ClusterMetadataRepositoryResponse)
+	}
+	return err
 }
 
-func (c *ClusterMetadata) processTopicRecord(data []byte, offset int) {
-	version := data[offset]
-	offset += 1
-
-	nameLength, totalBytesRead := common.ReadVarIntUnsigned(offset, data)
-	offset += totalBytesRead
-	nameLength -= 1 // Because of https://binspec.org/kafka-cluster-metadata?highlight=161-161
-
-	topicNameBytes := data[offset : offset+nameLength]
-	topicNameString := string(topicNameBytes)
-	fmt.Printf("Topic TopicName for Topic Record: %+v\n", topicNameString)
-	offset += nameLength
-
-	topicNameInfo := parser.TopicNameInfo{TopicNameBytes: topicNameBytes, TopicName: topicNameString}
-
-	topicUuid := data[offset : offset+16]
+// reload builds the image from the newest complete snapshot and the log batches after
+// it, or from the whole log when there is no snapshot
+func (c *ClusterMetadata) reload() error {
+	c.transaction = metadataTransaction{}
+	image := newMetadataImage(nil, &c.transaction)
 
-	tmi := clutser_metadata_port.TopicMetadataInfo{
-		TopicNameInfo:             topicNameInfo,
-		TopicId:                   topicUuid,
-		IsInternal:                []byte{0x00},
-		PartitionsArray:           []*domain.PartitionMetadata{},
-		TopicAuthorizedOperations: []byte{0x00, 0x00, 0x00, 0x00},
-		TagBuffer:                 []byte{0x00},
+	snapshot, hasSnapshot, err := loadLatestSnapshot(c.logDir, image)
+	if err != nil {
+		return err
+	}
+	segments, err := listMetadataLogSegments(c.logDir)
+	if err != nil {
+		return err
+	}
+	if !hasSnapshot && len(segments) == 0 {
+		return fmt.Errorf("%w: no metadata log in %s", os.ErrNotExist, c.logDir)
 	}
 
-	c.TopicNameTopicUuidMap[topicNameString] = hex.EncodeToString(topicUuid)
+	// Start from the segment holding the first offset after the snapshot
+	start := 0
+	for i, baseOffset := range segments {
+		if baseOffset <= snapshot.endOffset {
+			start = i
+		}
+	}
+	c.nextOffset = snapshot.endOffset
+	c.logEndOffset = snapshot.endOffset
+	c.lastEpoch = snapshot.epoch
+	c.segmentBase = snapshot.endOffset
+	c.position = 0
+	c.bytesSinceSnapshot = 0
+	c.recordsSinceSnapshot = 0
+	if len(segments) > 0 {
+		c.segmentBase = segments[start]
+		_, err = c.tailSegments(image, segments[start:])
+	}
 
-	c.ClusterMetadataRepositoryResponse.TopicUUIDTopicMetadataInfoMap[hex.EncodeToString(topicUuid)] = &tmi
-	_ = version
+	c.image.Store(image.ClusterMetadataRepositoryResponse)
+	return err
 }
 
-func (c *ClusterMetadata) processPartitionRecord(data []byte, offset int) {
-	version := data[offset]
-	offset += 1
-	_ = version
-
-	partitionId := data[offset : offset+4]
-	offset += 4
-	_ = partitionId
-
-	topicUuid := data[offset : offset+16]
-	offset += 16
-	_ = topicUuid
-
-	partitionRecord := domain.PartitionMetadata{}
-	c.TopicUUIDPartitionMetadataMap[hex.EncodeToString(topicUuid)] = append(c.TopicUUIDPartitionMetadataMap[hex.EncodeToString(topicUuid)], &partitionRecord)
-
-	lengthOfReplicaArray, totalBytesRead := common.ReadVarIntUnsigned(offset, data)
-	lengthOfReplicaArray -= 1 //https://binspec.org/kafka-cluster-metadata?highlight=214-214 subtract 1 because of here
-	partitionRecord.ReplicaNodes.ArrayLength = data[offset : offset+totalBytesRead]
-	offset += totalBytesRead
+// tailSegments applies the complete batches of segments, starting at the current
+// position in the first one, and reports whether anything was applied. It stops at the
+// first unreadable segment or corrupt batch with the cursor right after the batches
+// applied, so the image must be published even when an error is returned.
+func (c *ClusterMetadata) tailSegments(image metadataImage, segments []int64) (bool, error) {
+	changed := false
+	for _, baseOffset := range segments {
+		if baseOffset != c.segmentBase {
+			c.segmentBase = baseOffset
+			c.position = 0
+		}
 
-	for range lengthOfReplicaArray {
-		replicaId := data[offset : offset+4]
-		partitionRecord.ReplicaNodesArray = append(partitionRecord.ReplicaNodesArray, replicaId...)
-		offset += 4
+		data, err := readFrom(filepath.Join(c.logDir, metadataSegmentFileName(baseOffset)), c.position)
+		if err != nil {
+			return changed, err
+		}
+		complete, last := completeBatches(data)
+		if complete == 0 {
+			continue
+		}
+		nextOffset, applied, err := image.processRecordBatches(data[:complete], c.nextOffset)
+		if applied > 0 {
+			if applied < complete {
+				_, last = completeBatches(data[:applied])
+			}
+			c.recordsSinceSnapshot += nextOffset - c.nextOffset
+			c.bytesSinceSnapshot += int64(applied)
+			c.nextOffset = nextOffset
+			c.logEndOffset = last.NextOffset()
+			c.lastEpoch = last.PartitionLeaderEpoch
+			c.lastTimestamp = last.MaxTimestamp
+			c.position += int64(applied)
+			changed = true
+		}
+		if err != nil {
+			return changed, fmt.Errorf("metadata log segment %d: %w", baseOffset, err)
+		}
 	}
+	return changed, nil
+}
 
-	lengthOfInSyncReplicaArray, totalBytesRead := common.ReadVarIntUnsigned(offset, data)
-	lengthOfInSyncReplicaArray -= 1 //https://binspec.org/kafka-cluster-metadata?highlight=219-219 subtract 1 because of here
-	partitionRecord.IsrNodes.ArrayLength = data[offset : offset+totalBytesRead]
-	offset += totalBytesRead
-
-	for range lengthOfInSyncReplicaArray {
-		inSyncReplicaArray := data[offset : offset+4]
-		partitionRecord.IsrNodes.IsrNodeArray = append(partitionRecord.IsrNodes.IsrNodeArray, inSyncReplicaArray...)
-
-		offset += 4
+var errMetadataLogTruncated = errors.New("metadata log was truncated")
 
+// readFrom returns the bytes of the file from position on
+func readFrom(path string, position int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
 	}
+	defer file.Close()
 
-	lengthOfRemovingReplicasArray, totalBytesRead := common.ReadVarIntUnsigned(offset, data)
-	lengthOfRemovingReplicasArray -= 1 //https://binspec.org/kafka-cluster-metadata?highlight=224-224 subtract 1 because of here
-	offset += totalBytesRead
-
-	lengthOfAddingReplicasArray, totalBytesRead := common.ReadVarIntUnsigned(offset, data)
-	lengthOfAddingReplicasArray -= 1 //https://binspec.org/kafka-cluster-metadata?highlight=225-225 subtract 1 because of here
-	offset += totalBytesRead
-
-	replicaIdOfLeader := data[offset : offset+4]
-	_ = replicaIdOfLeader
-	offset += 4
-
-	epochOfLeader := data[offset : offset+4]
-	_ = epochOfLeader
-	offset += 4
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < position {
+		return nil, fmt.Errorf("%w: %s is %d bytes, %d were applied", errMetadataLogTruncated, path, info.Size(), position)
+	}
+	data := make([]byte, info.Size()-position)
+	if _, err := file.ReadAt(data, position); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
 
-	epochOfPartition := data[offset : offset+4]
-	_ = epochOfPartition
-	offset += 4
+// completeBatches returns the length of the complete record batches at the start of data
+// and the header of the last one; a batch still being written is left for the next refresh
+func completeBatches(data []byte) (int, common.RecordBatchHeader) {
+	length := 0
+	last := common.RecordBatchHeader{}
+	for length+common.RecordBatchHeaderSize <= len(data) {
+		header, err := common.ReadRecordBatchHeader(data[length:])
+		if err != nil || header.Size() < common.RecordBatchHeaderSize || length+header.Size() > len(data) {
+			break
+		}
+		length += header.Size()
+		last = header
+	}
+	return length, last
+}
 
-	lengthOfDirectoriesArray, totalBytesRead := common.ReadVarIntUnsigned(offset, data)
-	_ = lengthOfDirectoriesArray
-	offset += totalBytesRead
+// maybeSnapshot writes the image to a snapshot once a threshold is crossed, rolls the log
+// to a new segment and prunes the segments the snapshot covers. A snapshot is never taken
+// inside a metadata transaction, whose records are not in the image yet.
+func (c *ClusterMetadata) maybeSnapshot() {
+	bytesDue := c.snapshotMaxBytes > 0 && c.bytesSinceSnapshot >= c.snapshotMaxBytes
+	recordsDue := c.snapshotMaxRecords > 0 && c.recordsSinceSnapshot >= c.snapshotMaxRecords
+	if !bytesDue && !recordsDue || c.transaction.open {
+		return
+	}
 
-	directoriesArrayUuid := data[offset : offset+16] //https://binspec.org/kafka-cluster-metadata?highlight=239-254
-	_ = directoriesArrayUuid
-	offset += 16
+	id := snapshotID{endOffset: c.nextOffset, epoch: c.lastEpoch}
+	data := encodeSnapshot(id, c.lastTimestamp, snapshotRecords(c.image.Load()))
+	if err := writeSnapshot(c.logDir, id, data); err != nil {
+		fmt.Printf("Writing metadata snapshot %s failed: %v\n", id.fileName(), err)
+		return
+	}
+	c.bytesSinceSnapshot = 0
+	c.recordsSinceSnapshot = 0
 
-	offset += 1 //Skip tagged fields count https://binspec.org/kafka-cluster-metadata?highlight=255-255
+	if err := c.rollSegment(); err != nil {
+		fmt.Printf("Rolling the metadata log failed: %v\n", err)
+	}
+	if err := pruneMetadataLog(c.logDir, id.endOffset, c.segmentBase); err != nil {
+		fmt.Printf("Pruning the metadata log failed: %v\n", err)
+	}
+}
 
-	partitionRecord.ErrorCode = []byte{0x00, 0x00}
-	topicPartitionMetadataSlice := c.TopicUUIDPartitionMetadataMap[hex.EncodeToString(topicUuid)]
-	partitionRecord.PartitionIndex = common.IntToFourBytes(len(topicPartitionMetadataSlice) - 1)
-	partitionRecord.LeaderId = replicaIdOfLeader
-	partitionRecord.LeaderEpoch = epochOfLeader
+// rollSegment starts a new, empty active segment at the log end offset, so the active
+// segment stops growing and the ones before it can be pruned. The log is not rolled while
+// the active segment ends with a batch still being written.
+func (c *ClusterMetadata) rollSegment() error {
+	if c.logEndOffset <= c.segmentBase {
+		return nil
+	}
+	info, err := os.Stat(filepath.Join(c.logDir, metadataSegmentFileName(c.segmentBase)))
+	if err != nil {
+		return err
+	}
+	if info.Size() != c.position {
+		return nil
+	}
 
-	partitionRecord.EligibleLeaderReplicasArrayLength = []byte{0x01}
-	partitionRecord.LastKnownElrArrayLength = []byte{0x01}
-	partitionRecord.OfflineReplicasArrayLength = []byte{0x01}
-	partitionRecord.TagBuffer = []byte{0x00}
+	file, err := os.OpenFile(filepath.Join(c.logDir, metadataSegmentFileName(c.logEndOffset)), os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	c.segmentBase = c.logEndOffset
+	c.position = 0
+	return nil
 }
@@ -1,8 +1,309 @@
 package cluster_metadata_repository
 
-import "testing"
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
 
 func TestClusterMetadata_ParseClusterMetadataFileByTopicNames(t *testing.T) {
 	parser := NewClusterMetadataRepository()
 	parser.GetClusterMetadata()
 }
+
+// featureLevelBatch holds the FeatureLevelRecord for metadata.version
+var featureLevelBatch = []byte{
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x4f, 0x00, 0x00, 0x00, 0x01,
+	0x02, 0xb0, 0x69, 0x45, 0x7c, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x91, 0xe0,
+	0x5a, 0xf8, 0x18, 0x00, 0x00, 0x01, 0x91, 0xe0, 0x5a, 0xf8, 0x18, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x01, 0x3a, 0x00, 0x00,
+	0x00, 0x01, 0x2e, 0x01, 0x0c, 0x00, 0x11, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x2e,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x00, 0x14, 0x00, 0x00,
+}
+
+// topicBatch holds the TopicRecord of topic "saz" and PartitionRecords for its two partitions
+var topicBatch = []byte{
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0xe4, 0x00, 0x00, 0x00, 0x01,
+	0x02, 0x24, 0xdb, 0x12, 0xdd, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x01, 0x91, 0xe0,
+	0x5b, 0x2d, 0x15, 0x00, 0x00, 0x01, 0x91, 0xe0, 0x5b, 0x2d, 0x15, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x03, 0x3c, 0x00, 0x00,
+	0x00, 0x01, 0x30, 0x01, 0x02, 0x00, 0x04, 0x73, 0x61, 0x7a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x40, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x91, 0x00, 0x00, 0x90, 0x01, 0x00, 0x00,
+	0x02, 0x01, 0x82, 0x01, 0x01, 0x03, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x40, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x91, 0x02, 0x00, 0x00, 0x00, 0x01,
+	0x02, 0x00, 0x00, 0x00, 0x01, 0x01, 0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x02, 0x10, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40, 0x00, 0x80, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x90, 0x01, 0x00, 0x00, 0x04, 0x01, 0x82, 0x01, 0x01, 0x03,
+	0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40, 0x00, 0x80, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x91, 0x02, 0x00, 0x00, 0x00, 0x01, 0x02, 0x00, 0x00, 0x00, 0x01, 0x01,
+	0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x10, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x40, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00,
+}
+
+func appendToMetadataLog(t *testing.T, dir string, name string, data []byte) {
+	t.Helper()
+	file, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.Write(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClusterMetadata_TailsMetadataLog(t *testing.T) {
+	dir := t.TempDir()
+	appendToMetadataLog(t, dir, metadataSegmentFileName(0), featureLevelBatch)
+	appendToMetadataLog(t, dir, metadataSegmentFileName(0), topicBatch[:50])
+
+	repo := NewClusterMetadataRepository(WithMetadataLogDir(dir), WithPollInterval(10*time.Millisecond))
+	if err := repo.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer repo.Close()
+
+	before, err := repo.GetClusterMetadata()
+	if err != nil {
+		t.Fatalf("GetClusterMetadata() error = %v", err)
+	}
+	if len(before.TopicNameTopicUuidMap) != 0 {
+		t.Fatalf("image has topics %v before the topic batch is complete", before.TopicNameTopicUuidMap)
+	}
+
+	appendToMetadataLog(t, dir, metadataSegmentFileName(0), topicBatch[50:])
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		after, _ := repo.GetClusterMetadata()
+		if topicUuid, exists := after.TopicNameTopicUuidMap["saz"]; exists {
+			if partitions := len(after.TopicUUIDPartitionMetadataMap[topicUuid]); partitions != 2 {
+				t.Errorf("topic saz has %d partitions, want 2", partitions)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("appended topic batch was not picked up by the tailer")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if len(before.TopicNameTopicUuidMap) != 0 || len(before.TopicUUIDPartitionMetadataMap) != 0 {
+		t.Errorf("earlier image was modified by the refresh")
+	}
+}
+
+// snapshotMarkerBatch builds the SnapshotHeader or SnapshotFooter control batch
+func snapshotMarkerBatch(controlType int16) []byte {
+	value := binary.BigEndian.AppendUint16(nil, 0) // Version
+	if controlType == snapshotHeaderControlType {
+		value = binary.BigEndian.AppendUint64(value, 0) // LastContainedLogTimestamp
+	}
+	return snapshotControlBatch(0, 1, controlType, append(value, 0x00))
+}
+
+func TestClusterMetadata_LoadsSnapshotBeforeLog(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name string, data []byte) {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The snapshot covers offsets 0 and 1, which the log still holds with older contents
+	writeFile(snapshotID{endOffset: 2, epoch: 1}.fileName(), slices.Concat(
+		snapshotMarkerBatch(snapshotHeaderControlType),
+		metadataBatchAt(0, topicRecordValue("foo", fooTopicID), partitionRecordValue(fooTopicID, 0, 1, []int32{1})),
+		snapshotMarkerBatch(snapshotFooterControlType),
+	))
+	// A newer snapshot that was cut short before its footer
+	writeFile(snapshotID{endOffset: 3, epoch: 1}.fileName(), slices.Concat(
+		snapshotMarkerBatch(snapshotHeaderControlType),
+		metadataBatchAt(0, topicRecordValue("partial", bytes.Repeat([]byte{0xee}, 16))),
+	))
+	writeFile(metadataSegmentFileName(0), metadataBatchAt(0, topicRecordValue("stale", bytes.Repeat([]byte{0xff}, 16)), noOpRecordValue()))
+	writeFile(metadataSegmentFileName(2), metadataBatchAt(2, topicRecordValue("bar", barTopicID)))
+
+	repo := NewClusterMetadataRepository(WithMetadataLogDir(dir))
+	image, err := repo.GetClusterMetadata()
+	if err != nil {
+		t.Fatalf("GetClusterMetadata() error = %v", err)
+	}
+	for name, want := range map[string]bool{"foo": true, "bar": true, "stale": false, "partial": false} {
+		if _, exists := image.TopicNameTopicUuidMap[name]; exists != want {
+			t.Errorf("topic %s in image = %v, want %v", name, exists, want)
+		}
+	}
+	if image.FindPartition("foo", 0) == nil {
+		t.Errorf("partition foo-0 from the snapshot is missing")
+	}
+
+	// Batches appended to the active segment and to a new segment are tailed
+	appendToMetadataLog(t, dir, metadataSegmentFileName(2), metadataBatchAt(3, topicRecordValue("baz", bytes.Repeat([]byte{0x12}, 16))))
+	writeFile(metadataSegmentFileName(4), metadataBatchAt(4, topicRecordValue("qux", bytes.Repeat([]byte{0x34}, 16))))
+	if err := repo.refresh(); err != nil {
+		t.Fatalf("refresh() error = %v", err)
+	}
+	image, _ = repo.GetClusterMetadata()
+	for _, name := range []string{"foo", "bar", "baz", "qux"} {
+		if _, exists := image.TopicNameTopicUuidMap[name]; !exists {
+			t.Errorf("topic %s is missing after the refresh", name)
+		}
+	}
+}
+
+func noOpRecordValue() []byte {
+	return metadataRecordValue(noOpRecordType, 0, nil)
+}
+
+func TestClusterMetadata_WritesSnapshotAndPrunesLog(t *testing.T) {
+	dir := t.TempDir()
+	brokerConfig := metadataRecordValue(configRecordType, 0, func(w *common.KafkaWriter) {
+		w.Int8(domain.ConfigResourceTypeBroker)
+		w.String("1", true)
+		w.String("log.retention.ms", true)
+		w.String("1000", true)
+	})
+	registerBroker := registerBrokerRecord{
+		brokerID:      1,
+		incarnationID: bytes.Repeat([]byte{0x01}, 16),
+		brokerEpoch:   7,
+		endpoints:     []brokerEndpoint{{name: "PLAINTEXT", host: "localhost", port: 9092}},
+		fenced:        true,
+	}.encode()
+	appendToMetadataLog(t, dir, metadataSegmentFileName(0), metadataBatchAt(0,
+		featureLevelRecord{name: "metadata.version", featureLevel: 20}.encode(),
+		registerBroker,
+		topicRecordValue("foo", fooTopicID),
+	))
+	appendToMetadataLog(t, dir, metadataSegmentFileName(3), metadataBatchAt(3,
+		partitionRecordValue(fooTopicID, 0, 1, []int32{1, 2}),
+		brokerConfig,
+	))
+	appendToMetadataLog(t, dir, metadataSegmentFileName(5), metadataBatchAt(5, topicRecordValue("bar", barTopicID)))
+
+	repo := NewClusterMetadataRepository(WithMetadataLogDir(dir), WithSnapshotThresholds(0, 5))
+	want, err := repo.GetClusterMetadata()
+	if err != nil {
+		t.Fatalf("GetClusterMetadata() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, snapshotID{endOffset: 6}.fileName())); err != nil {
+		t.Fatalf("snapshot at offset 6 was not written: %v", err)
+	}
+	segments, _ := listMetadataLogSegments(dir)
+	if !slices.Equal(segments, []int64{6}) {
+		t.Errorf("segments after pruning = %v, want only the segment rolled at 6", segments)
+	}
+
+	// A restart rebuilds the same image from the snapshot and the remaining segment
+	got, err := NewClusterMetadataRepository(WithMetadataLogDir(dir)).GetClusterMetadata()
+	if err != nil {
+		t.Fatalf("GetClusterMetadata() after the restart error = %v", err)
+	}
+	if !maps.Equal(got.FeatureLevels, want.FeatureLevels) || !maps.Equal(got.TopicNameTopicUuidMap, want.TopicNameTopicUuidMap) {
+		t.Errorf("restored features %v and topics %v, want %v and %v", got.FeatureLevels, got.TopicNameTopicUuidMap, want.FeatureLevels, want.TopicNameTopicUuidMap)
+	}
+	if broker := got.Brokers[1]; broker == nil || !broker.Fenced || broker.Epoch != 7 || broker.Rack != "" || len(broker.Endpoints) != 1 || broker.Endpoints[0].Port != 9092 {
+		t.Errorf("restored broker 1 = %+v, want %+v", broker, want.Brokers[1])
+	}
+	if configs := got.Configs[domain.ConfigResource{Type: domain.ConfigResourceTypeBroker, Name: "1"}]; configs["log.retention.ms"] != "1000" {
+		t.Errorf("restored broker configs = %v, want log.retention.ms=1000", configs)
+	}
+	partition, wantPartition := got.FindPartition("foo", 0), want.FindPartition("foo", 0)
+	if partition == nil || !bytes.Equal(partition.ReplicaNodesArray, wantPartition.ReplicaNodesArray) ||
+		!bytes.Equal(partition.IsrNodeArray, wantPartition.IsrNodeArray) || !bytes.Equal(partition.LeaderId, wantPartition.LeaderId) {
+		t.Errorf("restored partition foo-0 = %+v, want %+v", partition, wantPartition)
+	}
+}
+
+func TestClusterMetadata_StopsAtCorruptBatch(t *testing.T) {
+	dir := t.TempDir()
+	corrupt := metadataBatchAt(1, topicRecordValue("bar", barTopicID))
+	corrupt[len(corrupt)-1] ^= 0xff
+	appendToMetadataLog(t, dir, metadataSegmentFileName(0), slices.Concat(
+		metadataBatchAt(0, topicRecordValue("foo", fooTopicID)),
+		corrupt,
+		metadataBatchAt(2, topicRecordValue("baz", bytes.Repeat([]byte{0xcc}, 16))),
+	))
+
+	repo := NewClusterMetadataRepository(WithMetadataLogDir(dir))
+	if err := repo.refresh(); !errors.Is(err, common.ErrCorruptRecordBatch) {
+		t.Fatalf("refresh() error = %v, want a corrupt batch", err)
+	}
+	image := repo.image.Load()
+	if _, exists := image.TopicNameTopicUuidMap["foo"]; !exists {
+		t.Errorf("topic foo before the corrupt batch was not applied")
+	}
+	if _, exists := image.TopicNameTopicUuidMap["baz"]; exists {
+		t.Errorf("topic baz after the corrupt batch was applied")
+	}
+	if repo.nextOffset != 1 {
+		t.Errorf("nextOffset = %d, want 1, the corrupt batch", repo.nextOffset)
+	}
+
+	// Once the batch is repaired tailing resumes from it
+	path := filepath.Join(dir, metadataSegmentFileName(0))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupt[len(corrupt)-1] ^= 0xff
+	copy(data[repo.position:], corrupt)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.refresh(); err != nil {
+		t.Fatalf("refresh() after the repair error = %v", err)
+	}
+	image = repo.image.Load()
+	for _, name := range []string{"foo", "bar", "baz"} {
+		if _, exists := image.TopicNameTopicUuidMap[name]; !exists {
+			t.Errorf("topic %s is missing after the repair", name)
+		}
+	}
+}
+
+func TestClusterMetadata_KeepsBatchesAppliedBeforeAReadError(t *testing.T) {
+	dir := t.TempDir()
+	appendToMetadataLog(t, dir, metadataSegmentFileName(0), metadataBatchAt(0, topicRecordValue("foo", fooTopicID)))
+	repo := NewClusterMetadataRepository(WithMetadataLogDir(dir))
+	if err := repo.refresh(); err != nil {
+		t.Fatalf("refresh() error = %v", err)
+	}
+
+	// bar lands in the tailed segment while the next segment can't be read
+	appendToMetadataLog(t, dir, metadataSegmentFileName(0), metadataBatchAt(1, topicRecordValue("bar", barTopicID)))
+	unreadable := filepath.Join(dir, metadataSegmentFileName(2))
+	if err := os.Mkdir(unreadable, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.refresh(); err == nil {
+		t.Fatal("refresh() with an unreadable segment succeeded")
+	}
+	if _, exists := repo.image.Load().TopicNameTopicUuidMap["bar"]; !exists {
+		t.Fatalf("topic bar applied before the read error was not published")
+	}
+
+	if err := os.Remove(unreadable); err != nil {
+		t.Fatal(err)
+	}
+	appendToMetadataLog(t, dir, metadataSegmentFileName(2), metadataBatchAt(2, topicRecordValue("baz", bytes.Repeat([]byte{0xcc}, 16))))
+	if err := repo.refresh(); err != nil {
+		t.Fatalf("refresh() error = %v", err)
+	}
+	image := repo.image.Load()
+	for _, name := range []string{"foo", "bar", "baz"} {
+		if _, exists := image.TopicNameTopicUuidMap[name]; !exists {
+			t.Errorf("topic %s is missing", name)
+		}
+	}
+}
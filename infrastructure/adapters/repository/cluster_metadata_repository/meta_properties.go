@@ -0,0 +1,27 @@
+package cluster_metadata_repository
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadClusterID returns the cluster.id stored in the meta.properties file that
+// kafka-storage format writes into the log directory, or "" if there is none
+func ReadClusterID(logDir string) string {
+	file, err := os.Open(filepath.Join(logDir, "meta.properties"))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if found && strings.TrimSpace(key) == "cluster.id" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
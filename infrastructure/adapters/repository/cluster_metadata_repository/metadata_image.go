@@ -0,0 +1,353 @@
+package cluster_metadata_repository
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/core/ports/parser"
+	clutser_metadata_port "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// metadataTransaction buffers the records of a metadata transaction until it ends. It
+// outlives a single image because a transaction may span several refreshes.
+type metadataTransaction struct {
+	open    bool
+	records []metadataRecord
+}
+
+// metadataImage is a metadata image under construction. It starts as a copy of the
+// published image; maps, slices and values shared with the published image are copied
+// before they are changed.
+type metadataImage struct {
+	*clutser_metadata_port.ClusterMetadataRepositoryResponse
+	transaction *metadataTransaction
+}
+
+func newMetadataImage(base *clutser_metadata_port.ClusterMetadataRepositoryResponse, transaction *metadataTransaction) metadataImage {
+	image := &clutser_metadata_port.ClusterMetadataRepositoryResponse{
+		TopicUUIDTopicMetadataInfoMap: make(map[string]*clutser_metadata_port.TopicMetadataInfo),
+		TopicUUIDPartitionMetadataMap: make(map[string][]*domain.PartitionMetadata),
+		TopicNameTopicUuidMap:         make(map[string]string),
+		Brokers:                       make(map[int32]*domain.BrokerRegistration),
+		Configs:                       make(map[domain.ConfigResource]map[string]string),
+		FeatureLevels:                 make(map[string]int16),
+	}
+	if base != nil {
+		*image = *base
+		image.TopicUUIDTopicMetadataInfoMap = maps.Clone(base.TopicUUIDTopicMetadataInfoMap)
+		image.TopicUUIDPartitionMetadataMap = maps.Clone(base.TopicUUIDPartitionMetadataMap)
+		image.TopicNameTopicUuidMap = maps.Clone(base.TopicNameTopicUuidMap)
+		image.Brokers = maps.Clone(base.Brokers)
+		image.Configs = maps.Clone(base.Configs)
+		image.FeatureLevels = maps.Clone(base.FeatureLevels)
+	}
+	return metadataImage{ClusterMetadataRepositoryResponse: image, transaction: transaction}
+}
+
+// processRecordBatches applies the metadata records in data from offset nextOffset on and
+// returns the offset following the last metadata batch and the bytes of data applied.
+// Control batches, such as the Raft leader change messages and snapshot markers, carry
+// no metadata and are skipped. A corrupt batch stops the processing: the batches before
+// it stay applied and the error is returned.
+func (c *metadataImage) processRecordBatches(data []byte, nextOffset int64) (int64, int, error) {
+	position := 0
+	for position < len(data) {
+		header, err := common.ReadRecordBatchHeader(data[position:])
+		if err != nil {
+			return nextOffset, position, err
+		}
+		if header.Size() < common.RecordBatchHeaderSize || position+header.Size() > len(data) {
+			return nextOffset, position, fmt.Errorf("%w: batch length %d at position %d overruns the record set", common.ErrCorruptRecordBatch, header.BatchLength, position)
+		}
+		batch := data[position : position+header.Size()]
+		if err := common.ValidateRecordBatch(batch); err != nil {
+			return nextOffset, position, fmt.Errorf("batch at offset %d: %w", header.BaseOffset, err)
+		}
+		decoded, err := common.DecodeRecordBatch(batch)
+		if err != nil {
+			return nextOffset, position, fmt.Errorf("decoding batch at offset %d: %w", header.BaseOffset, err)
+		}
+		position += len(batch)
+		if decoded.IsControl() || decoded.LastOffset() < nextOffset {
+			continue
+		}
+		c.RecordsLength = len(decoded.Records)
+
+		for _, record := range decoded.Records {
+			if decoded.BaseOffset+int64(record.OffsetDelta) < nextOffset {
+				continue
+			}
+			metadataRecord, err := decodeMetadataRecord(record.Value)
+			if err != nil {
+				fmt.Printf("Skipping cluster metadata record: %v\n", err)
+				continue
+			}
+			if metadataRecord != nil {
+				c.applyRecord(metadataRecord)
+			}
+		}
+		nextOffset = decoded.NextOffset()
+	}
+	return nextOffset, position, nil
+}
+
+// applyRecord applies a record to the image, holding it back while a metadata
+// transaction is open
+func (c *metadataImage) applyRecord(record metadataRecord) {
+	switch record.(type) {
+	case beginTransactionRecord:
+		*c.transaction = metadataTransaction{open: true}
+		return
+	case endTransactionRecord:
+		records := c.transaction.records
+		*c.transaction = metadataTransaction{}
+		for _, record := range records {
+			record.apply(c)
+		}
+		return
+	case abortTransactionRecord:
+		*c.transaction = metadataTransaction{}
+		return
+	}
+
+	if c.transaction.open {
+		c.transaction.records = append(c.transaction.records, record)
+		return
+	}
+	record.apply(c)
+}
+
+func (r registerBrokerRecord) apply(image *metadataImage) {
+	registration := &domain.BrokerRegistration{
+		BrokerID:             r.brokerID,
+		Epoch:                r.brokerEpoch,
+		IncarnationID:        r.incarnationID,
+		Rack:                 r.rack,
+		Fenced:               r.fenced,
+		InControlledShutdown: r.inControlledShutdown,
+	}
+	for _, endpoint := range r.endpoints {
+		registration.Endpoints = append(registration.Endpoints, domain.BrokerEndpoint{
+			Name:             endpoint.name,
+			Host:             endpoint.host,
+			Port:             endpoint.port,
+			SecurityProtocol: endpoint.securityProtocol,
+		})
+	}
+	image.Brokers[r.brokerID] = registration
+}
+
+func (r unregisterBrokerRecord) apply(image *metadataImage) {
+	if registration, exists := image.Brokers[r.brokerID]; exists && registration.Epoch == r.brokerEpoch {
+		delete(image.Brokers, r.brokerID)
+	}
+}
+
+func (r fenceBrokerRecord) apply(image *metadataImage) {
+	registration, exists := image.Brokers[r.brokerID]
+	if !exists || registration.Epoch != r.brokerEpoch {
+		return
+	}
+	updated := *registration
+	updated.Fenced = !r.unfence
+	image.Brokers[r.brokerID] = &updated
+}
+
+func (r topicRecord) apply(image *metadataImage) {
+	topicUuid := hex.EncodeToString(r.topicID)
+	partitions := image.TopicUUIDPartitionMetadataMap[topicUuid]
+	if partitions == nil {
+		partitions = []*domain.PartitionMetadata{}
+	}
+	image.TopicNameTopicUuidMap[r.name] = topicUuid
+	image.TopicUUIDTopicMetadataInfoMap[topicUuid] = &clutser_metadata_port.TopicMetadataInfo{
+		TopicNameInfo:             parser.TopicNameInfo{TopicNameBytes: []byte(r.name), TopicName: r.name},
+		TopicId:                   r.topicID,
+		IsInternal:                []byte{0x00},
+		PartitionsArray:           partitions,
+		TopicAuthorizedOperations: []byte{0x00, 0x00, 0x00, 0x00},
+		TagBuffer:                 []byte{0x00},
+	}
+}
+
+func (r removeTopicRecord) apply(image *metadataImage) {
+	topicUuid := hex.EncodeToString(r.topicID)
+	if topicMetadata, exists := image.TopicUUIDTopicMetadataInfoMap[topicUuid]; exists {
+		topicName := topicMetadata.TopicNameInfo.TopicName
+		delete(image.TopicNameTopicUuidMap, topicName)
+		delete(image.Configs, domain.ConfigResource{Type: domain.ConfigResourceTypeTopic, Name: topicName})
+	}
+	delete(image.TopicUUIDTopicMetadataInfoMap, topicUuid)
+	delete(image.TopicUUIDPartitionMetadataMap, topicUuid)
+}
+
+// apply adds the partition to its topic. A partition of a topic the image doesn't have,
+// left over from a deleted topic or ahead of its TopicRecord, is skipped.
+func (r partitionRecord) apply(image *metadataImage) {
+	topicUuid := hex.EncodeToString(r.topicID)
+	if _, exists := image.TopicUUIDTopicMetadataInfoMap[topicUuid]; !exists {
+		fmt.Printf("Skipping partition %d of unknown topic %s\n", r.partitionID, topicUuid)
+		return
+	}
+
+	partition := &domain.PartitionMetadata{
+		ErrorCode:                         []byte{0x00, 0x00},
+		PartitionIndex:                    common.IntToFourBytes(int(r.partitionID)),
+		LeaderId:                          common.IntToFourBytes(int(r.leader)),
+		LeaderEpoch:                       common.IntToFourBytes(int(r.leaderEpoch)),
+		PartitionEpoch:                    common.IntToFourBytes(int(r.partitionEpoch)),
+		EligibleLeaderReplicasArrayLength: []byte{0x01},
+		LastKnownElrArrayLength:           []byte{0x01},
+		OfflineReplicasArrayLength:        []byte{0x01},
+		TagBuffer:                         []byte{0x00},
+	}
+	setReplicas(partition, r.replicas)
+	setIsr(partition, r.isr)
+	image.putPartition(topicUuid, partition)
+}
+
+// apply updates a copy of the partition. A new leader starts a new leader epoch; every
+// change starts a new partition epoch.
+func (r partitionChangeRecord) apply(image *metadataImage) {
+	topicUuid := hex.EncodeToString(r.topicID)
+	current := image.FindPartitionByTopicUUID(topicUuid, r.partitionID)
+	if current == nil {
+		return
+	}
+
+	partition := *current
+	if r.isr != nil {
+		setIsr(&partition, r.isr)
+	}
+	if r.replicas != nil {
+		setReplicas(&partition, r.replicas)
+	}
+	if r.leader != noLeaderChange {
+		partition.LeaderId = common.IntToFourBytes(int(r.leader))
+		partition.LeaderEpoch = common.IntToFourBytes(common.BytesToInt(partition.LeaderEpoch) + 1)
+	}
+	partition.PartitionEpoch = common.IntToFourBytes(common.BytesToInt(partition.PartitionEpoch) + 1)
+	image.putPartition(topicUuid, &partition)
+}
+
+func (r configRecord) apply(image *metadataImage) {
+	resource := domain.ConfigResource{Type: r.resourceType, Name: r.resourceName}
+	configs := maps.Clone(image.Configs[resource])
+	if configs == nil {
+		configs = make(map[string]string)
+	}
+	if r.isNull {
+		delete(configs, r.name)
+	} else {
+		configs[r.name] = r.value
+	}
+
+	if len(configs) == 0 {
+		delete(image.Configs, resource)
+	} else {
+		image.Configs[resource] = configs
+	}
+}
+
+func (r featureLevelRecord) apply(image *metadataImage) {
+	if r.featureLevel == 0 {
+		delete(image.FeatureLevels, r.name)
+		return
+	}
+	image.FeatureLevels[r.name] = r.featureLevel
+}
+
+func (r producerIdsRecord) apply(image *metadataImage) {
+	image.NextProducerID = r.nextProducerID
+}
+
+func (noOpRecord) apply(*metadataImage) {}
+
+// Transaction markers are handled by applyRecord
+func (beginTransactionRecord) apply(*metadataImage) {}
+func (endTransactionRecord) apply(*metadataImage)   {}
+func (abortTransactionRecord) apply(*metadataImage) {}
+
+// putPartition adds a partition to its topic or replaces the one with the same index,
+// copying the topic's partition slice and metadata first
+func (c *metadataImage) putPartition(topicUuid string, partition *domain.PartitionMetadata) {
+	partitions := slices.Clone(c.TopicUUIDPartitionMetadataMap[topicUuid])
+	index := slices.IndexFunc(partitions, func(existing *domain.PartitionMetadata) bool {
+		return common.BytesToInt(existing.PartitionIndex) == common.BytesToInt(partition.PartitionIndex)
+	})
+	if index < 0 {
+		partitions = append(partitions, partition)
+	} else {
+		partitions[index] = partition
+	}
+	c.TopicUUIDPartitionMetadataMap[topicUuid] = partitions
+
+	if topicMetadata, exists := c.TopicUUIDTopicMetadataInfoMap[topicUuid]; exists {
+		updated := *topicMetadata
+		updated.PartitionsArray = partitions
+		c.TopicUUIDTopicMetadataInfoMap[topicUuid] = &updated
+	}
+}
+
+// copyImage returns a copy of a published image for a reader. Its maps and the topics,
+// partitions and brokers they hold are copied, so a reader changing them affects neither
+// the published image nor other readers.
+func copyImage(image *clutser_metadata_port.ClusterMetadataRepositoryResponse) clutser_metadata_port.ClusterMetadataRepositoryResponse {
+	copied := *image
+	copied.TopicNameTopicUuidMap = maps.Clone(image.TopicNameTopicUuidMap)
+	copied.FeatureLevels = maps.Clone(image.FeatureLevels)
+
+	copied.TopicUUIDPartitionMetadataMap = make(map[string][]*domain.PartitionMetadata, len(image.TopicUUIDPartitionMetadataMap))
+	for topicUuid, partitions := range image.TopicUUIDPartitionMetadataMap {
+		copiedPartitions := make([]*domain.PartitionMetadata, len(partitions))
+		for i, partition := range partitions {
+			copiedPartition := *partition
+			copiedPartitions[i] = &copiedPartition
+		}
+		copied.TopicUUIDPartitionMetadataMap[topicUuid] = copiedPartitions
+	}
+	copied.TopicUUIDTopicMetadataInfoMap = make(map[string]*clutser_metadata_port.TopicMetadataInfo, len(image.TopicUUIDTopicMetadataInfoMap))
+	for topicUuid, topicMetadata := range image.TopicUUIDTopicMetadataInfoMap {
+		copiedTopic := *topicMetadata
+		copiedTopic.PartitionsArray = slices.Clone(copied.TopicUUIDPartitionMetadataMap[topicUuid])
+		if copiedTopic.PartitionsArray == nil {
+			copiedTopic.PartitionsArray = []*domain.PartitionMetadata{}
+		}
+		copied.TopicUUIDTopicMetadataInfoMap[topicUuid] = &copiedTopic
+	}
+
+	copied.Brokers = make(map[int32]*domain.BrokerRegistration, len(image.Brokers))
+	for brokerID, registration := range image.Brokers {
+		copiedRegistration := *registration
+		copiedRegistration.Endpoints = slices.Clone(registration.Endpoints)
+		copied.Brokers[brokerID] = &copiedRegistration
+	}
+	copied.Configs = make(map[domain.ConfigResource]map[string]string, len(image.Configs))
+	for resource, configs := range image.Configs {
+		copied.Configs[resource] = maps.Clone(configs)
+	}
+	return copied
+}
+
+func setReplicas(partition *domain.PartitionMetadata, replicas []int32) {
+	partition.ReplicaNodes.ArrayLength, partition.ReplicaNodesArray = encodeNodeIds(replicas)
+}
+
+func setIsr(partition *domain.PartitionMetadata, isr []int32) {
+	partition.IsrNodes.ArrayLength, partition.IsrNodeArray = encodeNodeIds(isr)
+}
+
+// encodeNodeIds returns the COMPACT_ARRAY length and the 4 byte IDs the describe topic
+// encoder writes for a node list
+func encodeNodeIds(nodeIds []int32) ([]byte, []byte) {
+	nodes := make([]byte, 0, 4*len(nodeIds))
+	for _, nodeId := range nodeIds {
+		nodes = binary.BigEndian.AppendUint32(nodes, uint32(nodeId))
+	}
+	return binary.AppendUvarint(nil, uint64(len(nodeIds)+1)), nodes
+}
@@ -0,0 +1,249 @@
+package cluster_metadata_repository
+
+import (
+	"bytes"
+	"encoding/hex"
+	"slices"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+var (
+	fooTopicID = bytes.Repeat([]byte{0xaa}, 16)
+	barTopicID = bytes.Repeat([]byte{0xbb}, 16)
+)
+
+// metadataBatchAt builds a batch of metadata records starting at baseOffset
+func metadataBatchAt(baseOffset int64, values ...[]byte) []byte {
+	batch := common.RecordBatch{}
+	batch.BaseOffset = baseOffset
+	for i, value := range values {
+		batch.Records = append(batch.Records, common.Record{OffsetDelta: int32(i), Value: value})
+	}
+	batch.LastOffsetDelta = int32(len(values) - 1)
+	return common.EncodeRecordBatch(batch)
+}
+
+func topicRecordValue(name string, topicID []byte) []byte {
+	return metadataRecordValue(topicRecordType, 0, func(w *common.KafkaWriter) {
+		w.String(name, true)
+		w.UUID(topicID)
+	})
+}
+
+func partitionRecordValue(topicID []byte, partitionID int32, leader int32, replicas []int32) []byte {
+	return metadataRecordValue(partitionRecordType, 1, func(w *common.KafkaWriter) {
+		w.Int32(partitionID)
+		w.UUID(topicID)
+		w.Int32Array(replicas, true)
+		w.Int32Array(replicas, true) // Isr
+		w.Int32Array(nil, true)      // RemovingReplicas
+		w.Int32Array(nil, true)      // AddingReplicas
+		w.Int32(leader)
+		w.Int32(0) // LeaderEpoch
+		w.Int32(0) // PartitionEpoch
+		w.ArrayLength(1, true)
+		w.UUID(make([]byte, 16)) // Directories
+	})
+}
+
+func brokerRecordValue(recordType int, brokerID int32, epoch int64) []byte {
+	return metadataRecordValue(recordType, 0, func(w *common.KafkaWriter) {
+		w.Int32(brokerID)
+		w.Int64(epoch)
+	})
+}
+
+func TestMetadataImage_AppliesRecords(t *testing.T) {
+	registerBroker := metadataRecordValue(registerBrokerRecordType, 1, func(w *common.KafkaWriter) {
+		w.Int32(1)
+		w.UUID(bytes.Repeat([]byte{0x01}, 16)) // IncarnationId
+		w.Int64(7)                             // BrokerEpoch
+		w.ArrayLength(1, true)
+		w.String("PLAINTEXT", true)
+		w.String("localhost", true)
+		w.Int16(9092)
+		w.Int16(0)
+		w.EmptyTaggedFields()
+		w.ArrayLength(0, true) // Features
+		w.String("rack-a", true)
+		w.Bool(true)  // Fenced
+		w.Bool(false) // InControlledShutdown
+	})
+	// Leader moves to broker 2 and the ISR shrinks to it
+	isr := common.NewKafkaWriter()
+	isr.Int32Array([]int32{2}, true)
+	leader := common.NewKafkaWriter()
+	leader.Int32(2)
+	partitionChange := common.NewKafkaWriter()
+	partitionChange.UnsignedVarInt(1) // Frame version
+	partitionChange.UnsignedVarInt(partitionChangeRecordType)
+	partitionChange.UnsignedVarInt(0)
+	partitionChange.Int32(0)
+	partitionChange.UUID(fooTopicID)
+	partitionChange.UnsignedVarInt(2) // Tagged fields
+	for tag, data := range map[int][]byte{partitionChangeIsrTag: isr.Bytes(), partitionChangeLeaderTag: leader.Bytes()} {
+		partitionChange.UnsignedVarInt(tag)
+		partitionChange.UnsignedVarInt(len(data))
+		partitionChange.Raw(data)
+	}
+	topicConfig := func(name string, value *string) []byte {
+		return metadataRecordValue(configRecordType, 0, func(w *common.KafkaWriter) {
+			w.Int8(domain.ConfigResourceTypeTopic)
+			w.String("foo", true)
+			w.String(name, true)
+			w.NullableString(value, true)
+		})
+	}
+	retention, compact := "1000", "compact"
+
+	image := newMetadataImage(nil, &metadataTransaction{})
+	image.processRecordBatches(slices.Concat(
+		metadataBatchAt(0,
+			metadataRecordValue(featureLevelRecordType, 0, func(w *common.KafkaWriter) {
+				w.String("metadata.version", true)
+				w.Int16(20)
+			}),
+			registerBroker,
+			brokerRecordValue(unfenceBrokerRecordType, 1, 7),
+			topicRecordValue("foo", fooTopicID),
+			partitionRecordValue(fooTopicID, 1, 1, []int32{1, 2}),
+			partitionRecordValue(fooTopicID, 0, 1, []int32{1, 2}),
+			topicRecordValue("bar", barTopicID),
+			partitionRecordValue(barTopicID, 0, 1, []int32{1}),
+		),
+		metadataBatchAt(8,
+			partitionChange.Bytes(),
+			topicConfig("retention.ms", &retention),
+			topicConfig("cleanup.policy", &compact),
+			topicConfig("cleanup.policy", nil),
+			metadataRecordValue(removeTopicRecordType, 0, func(w *common.KafkaWriter) { w.UUID(barTopicID) }),
+			metadataRecordValue(producerIdsRecordType, 0, func(w *common.KafkaWriter) {
+				w.Int32(1)
+				w.Int64(7)
+				w.Int64(2000)
+			}),
+			metadataRecordValue(noOpRecordType, 0, nil),
+		),
+		// An aborted transaction leaves no trace, a committed one is applied as a whole
+		metadataBatchAt(15,
+			metadataRecordValue(beginTransactionRecordType, 0, func(w *common.KafkaWriter) { w.String("aborted", true) }),
+			topicRecordValue("aborted", bytes.Repeat([]byte{0xcc}, 16)),
+			metadataRecordValue(abortTransactionRecordType, 0, nil),
+			metadataRecordValue(beginTransactionRecordType, 0, func(w *common.KafkaWriter) { w.String("committed", true) }),
+			topicRecordValue("committed", bytes.Repeat([]byte{0xdd}, 16)),
+		),
+	), 0)
+
+	if _, exists := image.TopicNameTopicUuidMap["committed"]; exists {
+		t.Errorf("records of an open transaction were applied")
+	}
+	image.processRecordBatches(metadataBatchAt(20,
+		brokerRecordValue(fenceBrokerRecordType, 1, 7),
+		metadataRecordValue(endTransactionRecordType, 0, nil),
+		partitionRecordValue(barTopicID, 1, 1, []int32{1}),
+	), 0)
+
+	if got := image.FeatureLevels["metadata.version"]; got != 20 {
+		t.Errorf("metadata.version = %d, want 20", got)
+	}
+	broker := image.Brokers[1]
+	if broker == nil || broker.Epoch != 7 || broker.Rack != "rack-a" || !broker.Fenced ||
+		len(broker.Endpoints) != 1 || broker.Endpoints[0].Host != "localhost" || broker.Endpoints[0].Port != 9092 {
+		t.Errorf("broker 1 = %+v, want a fenced broker at localhost:9092 in rack-a", broker)
+	}
+
+	partition := image.FindPartition("foo", 0)
+	if partition == nil {
+		t.Fatal("partition foo-0 is missing")
+	}
+	if leader, leaderEpoch, partitionEpoch := common.BytesToInt(partition.LeaderId), common.BytesToInt(partition.LeaderEpoch), common.BytesToInt(partition.PartitionEpoch); leader != 2 || leaderEpoch != 1 || partitionEpoch != 1 {
+		t.Errorf("foo-0 leader = %d, leader epoch = %d, partition epoch = %d, want 2, 1 and 1", leader, leaderEpoch, partitionEpoch)
+	}
+	if !bytes.Equal(partition.IsrNodeArray, common.IntToFourBytes(2)) || !bytes.Equal(partition.IsrNodes.ArrayLength, []byte{0x02}) {
+		t.Errorf("foo-0 ISR = %x, want only broker 2", partition.IsrNodeArray)
+	}
+	if partition := image.FindPartition("foo", 1); partition == nil || common.BytesToInt(partition.PartitionIndex) != 1 {
+		t.Errorf("partition foo-1 is missing")
+	}
+
+	if _, exists := image.TopicNameTopicUuidMap["bar"]; exists || image.TopicUUIDPartitionMetadataMap[hex.EncodeToString(barTopicID)] != nil {
+		t.Errorf("removed topic bar or a partition of it is still in the image")
+	}
+	if partitions := image.TopicUUIDTopicMetadataInfoMap[hex.EncodeToString(fooTopicID)].PartitionsArray; len(partitions) != 2 || partitions[0] != image.FindPartition("foo", 1) {
+		t.Errorf("foo PartitionsArray = %v, want foo-1 and foo-0 as in the image", partitions)
+	}
+	configs := image.Configs[domain.ConfigResource{Type: domain.ConfigResourceTypeTopic, Name: "foo"}]
+	if len(configs) != 1 || configs["retention.ms"] != "1000" {
+		t.Errorf("foo configs = %v, want only retention.ms=1000", configs)
+	}
+	if image.NextProducerID != 2000 {
+		t.Errorf("NextProducerID = %d, want 2000", image.NextProducerID)
+	}
+	if _, exists := image.TopicNameTopicUuidMap["aborted"]; exists {
+		t.Errorf("aborted transaction was applied")
+	}
+	if _, exists := image.TopicNameTopicUuidMap["committed"]; !exists {
+		t.Errorf("committed transaction was not applied")
+	}
+}
+
+func TestMetadataImage_CopiesOnWrite(t *testing.T) {
+	base := newMetadataImage(nil, &metadataTransaction{})
+	base.processRecordBatches(metadataBatchAt(0,
+		topicRecordValue("foo", fooTopicID),
+		partitionRecordValue(fooTopicID, 0, 1, []int32{1}),
+	), 0)
+
+	next := newMetadataImage(base.ClusterMetadataRepositoryResponse, &metadataTransaction{})
+	next.processRecordBatches(metadataBatchAt(2,
+		partitionRecordValue(fooTopicID, 1, 1, []int32{1}),
+		metadataRecordValue(partitionChangeRecordType, 0, func(w *common.KafkaWriter) {
+			w.Int32(0)
+			w.UUID(fooTopicID)
+		}),
+	), 0)
+
+	if partitions := len(base.TopicUUIDPartitionMetadataMap[hex.EncodeToString(fooTopicID)]); partitions != 1 {
+		t.Errorf("base image has %d partitions, want 1", partitions)
+	}
+	if epoch := common.BytesToInt(base.FindPartition("foo", 0).PartitionEpoch); epoch != 0 {
+		t.Errorf("base image partition epoch = %d, want 0", epoch)
+	}
+	if epoch := common.BytesToInt(next.FindPartition("foo", 0).PartitionEpoch); epoch != 1 {
+		t.Errorf("next image partition epoch = %d, want 1", epoch)
+	}
+	topicUuid := hex.EncodeToString(fooTopicID)
+	if partitions := base.TopicUUIDTopicMetadataInfoMap[topicUuid].PartitionsArray; len(partitions) != 1 || common.BytesToInt(partitions[0].PartitionEpoch) != 0 {
+		t.Errorf("base image topic partitions = %v, want only foo-0 at epoch 0", partitions)
+	}
+	if partitions := next.TopicUUIDTopicMetadataInfoMap[topicUuid].PartitionsArray; len(partitions) != 2 {
+		t.Errorf("next image topic has %d partitions, want 2", len(partitions))
+	}
+}
+
+func TestCopyImage_OwnedByReader(t *testing.T) {
+	image := newMetadataImage(nil, &metadataTransaction{})
+	image.processRecordBatches(metadataBatchAt(0,
+		topicRecordValue("foo", fooTopicID),
+		partitionRecordValue(fooTopicID, 0, 1, []int32{1}),
+	), 0)
+
+	copied := copyImage(image.ClusterMetadataRepositoryResponse)
+	topicUuid := hex.EncodeToString(fooTopicID)
+	copied.TopicUUIDTopicMetadataInfoMap[topicUuid].PartitionsArray = nil
+	copied.FindPartition("foo", 0).LeaderId = common.IntToFourBytes(9)
+	copied.TopicNameTopicUuidMap["bar"] = "bar"
+
+	if partitions := image.TopicUUIDTopicMetadataInfoMap[topicUuid].PartitionsArray; len(partitions) != 1 {
+		t.Errorf("image topic has %d partitions after the copy was changed, want 1", len(partitions))
+	}
+	if leader := common.BytesToInt(image.FindPartition("foo", 0).LeaderId); leader != 1 {
+		t.Errorf("image foo-0 leader = %d after the copy was changed, want 1", leader)
+	}
+	if _, exists := image.TopicNameTopicUuidMap["bar"]; exists {
+		t.Errorf("topic added to the copy is in the image")
+	}
+}
@@ -0,0 +1,373 @@
+package cluster_metadata_repository
+
+import (
+	"fmt"
+
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// KRaft metadata record types, the apiKey in each record's frame
+// (https://github.com/apache/kafka/tree/trunk/metadata/src/main/resources/common/metadata)
+const (
+	registerBrokerRecordType   = 0
+	unregisterBrokerRecordType = 1
+	topicRecordType            = 2
+	partitionRecordType        = 3
+	configRecordType           = 4
+	partitionChangeRecordType  = 5
+	fenceBrokerRecordType      = 7
+	unfenceBrokerRecordType    = 8
+	removeTopicRecordType      = 9
+	featureLevelRecordType     = 12
+	producerIdsRecordType      = 15
+	noOpRecordType             = 20
+	beginTransactionRecordType = 23
+	abortTransactionRecordType = 24
+	endTransactionRecordType   = 25
+)
+
+// noLeaderChange is the PartitionChangeRecord Leader meaning the leader stays the same
+const noLeaderChange = -2
+
+// metadataRecord is a decoded metadata record that knows how to apply itself to an image
+type metadataRecord interface {
+	apply(image *metadataImage)
+}
+
+type registerBrokerRecord struct {
+	brokerID             int32
+	incarnationID        []byte
+	brokerEpoch          int64
+	endpoints            []brokerEndpoint
+	rack                 string
+	fenced               bool
+	inControlledShutdown bool
+}
+
+type brokerEndpoint struct {
+	name             string
+	host             string
+	port             int32
+	securityProtocol int16
+}
+
+type unregisterBrokerRecord struct {
+	brokerID    int32
+	brokerEpoch int64
+}
+
+type topicRecord struct {
+	name    string
+	topicID []byte
+}
+
+type partitionRecord struct {
+	partitionID    int32
+	topicID        []byte
+	replicas       []int32
+	isr            []int32
+	leader         int32
+	leaderEpoch    int32
+	partitionEpoch int32
+}
+
+type configRecord struct {
+	resourceType int8
+	resourceName string
+	name         string
+	value        string
+	isNull       bool // A null value removes the config
+}
+
+// partitionChangeRecord carries only the partition fields that changed; nil arrays and
+// a leader of noLeaderChange are left as they are
+type partitionChangeRecord struct {
+	partitionID int32
+	topicID     []byte
+	isr         []int32
+	leader      int32
+	replicas    []int32
+}
+
+// fenceBrokerRecord fences (or with unfence, unfences) a broker
+type fenceBrokerRecord struct {
+	brokerID    int32
+	brokerEpoch int64
+	unfence     bool
+}
+
+type removeTopicRecord struct {
+	topicID []byte
+}
+
+type featureLevelRecord struct {
+	name         string
+	featureLevel int16
+}
+
+type producerIdsRecord struct {
+	brokerID       int32
+	brokerEpoch    int64
+	nextProducerID int64
+}
+
+type noOpRecord struct{}
+
+// Records between BeginTransaction and EndTransaction are applied together; an
+// AbortTransaction drops them
+type beginTransactionRecord struct{ name string }
+type endTransactionRecord struct{}
+type abortTransactionRecord struct{}
+
+// decodeMetadataRecord decodes the value of a metadata log record. Records of types this
+// broker doesn't use, such as ACLs and quotas, decode to nil.
+func decodeMetadataRecord(value []byte) (metadataRecord, error) {
+	reader := common.NewKafkaReader(value, 0)
+	reader.UnsignedVarInt("frame version")
+	recordType := reader.UnsignedVarInt("record type")
+	version := reader.UnsignedVarInt("record version")
+	if err := reader.Err(); err != nil {
+		return nil, err
+	}
+
+	var record metadataRecord
+	switch recordType {
+	case registerBrokerRecordType:
+		record = decodeRegisterBrokerRecord(reader, version)
+	case unregisterBrokerRecordType:
+		record = unregisterBrokerRecord{brokerID: reader.Int32("BrokerId"), brokerEpoch: reader.Int64("BrokerEpoch")}
+	case topicRecordType:
+		record = topicRecord{name: reader.String("Name", true), topicID: reader.UUID("TopicId")}
+	case partitionRecordType:
+		record = decodePartitionRecord(reader, version)
+	case configRecordType:
+		config := configRecord{
+			resourceType: reader.Int8("ResourceType"),
+			resourceName: reader.String("ResourceName", true),
+			name:         reader.String("Name", true),
+		}
+		var exists bool
+		config.value, exists = reader.NullableString("Value", true)
+		config.isNull = !exists
+		record = config
+	case partitionChangeRecordType:
+		record = decodePartitionChangeRecord(reader)
+	case fenceBrokerRecordType, unfenceBrokerRecordType:
+		record = fenceBrokerRecord{
+			brokerID:    reader.Int32("Id"),
+			brokerEpoch: reader.Int64("Epoch"),
+			unfence:     recordType == unfenceBrokerRecordType,
+		}
+	case removeTopicRecordType:
+		record = removeTopicRecord{topicID: reader.UUID("TopicId")}
+	case featureLevelRecordType:
+		record = featureLevelRecord{name: reader.String("Name", true), featureLevel: reader.Int16("FeatureLevel")}
+	case producerIdsRecordType:
+		record = producerIdsRecord{
+			brokerID:       reader.Int32("BrokerId"),
+			brokerEpoch:    reader.Int64("BrokerEpoch"),
+			nextProducerID: reader.Int64("NextProducerId"),
+		}
+	case noOpRecordType:
+		record = noOpRecord{}
+	case beginTransactionRecordType:
+		record = beginTransactionRecord{name: reader.String("Name", true)}
+	case endTransactionRecordType:
+		record = endTransactionRecord{}
+	case abortTransactionRecordType:
+		record = abortTransactionRecord{}
+	default:
+		return nil, nil
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, fmt.Errorf("decoding metadata record type %d version %d: %w", recordType, version, err)
+	}
+	return record, nil
+}
+
+func decodeRegisterBrokerRecord(reader *common.KafkaReader, version int) registerBrokerRecord {
+	record := registerBrokerRecord{brokerID: reader.Int32("BrokerId")}
+	if version >= 2 {
+		reader.Bool("IsMigratingZkBroker")
+	}
+	record.incarnationID = reader.UUID("IncarnationId")
+	record.brokerEpoch = reader.Int64("BrokerEpoch")
+
+	endpointsLength := reader.ArrayLength("EndPoints", true)
+	for range endpointsLength {
+		endpoint := brokerEndpoint{
+			name:             reader.String("EndPoint Name", true),
+			host:             reader.String("EndPoint Host", true),
+			port:             int32(uint16(reader.Int16("EndPoint Port"))),
+			securityProtocol: reader.Int16("EndPoint SecurityProtocol"),
+		}
+		reader.SkipTaggedFields()
+		record.endpoints = append(record.endpoints, endpoint)
+	}
+
+	featuresLength := reader.ArrayLength("Features", true)
+	for range featuresLength {
+		reader.String("Feature Name", true)
+		reader.Int16("Feature MinSupportedVersion")
+		reader.Int16("Feature MaxSupportedVersion")
+		reader.SkipTaggedFields()
+	}
+
+	record.rack = reader.String("Rack", true)
+	record.fenced = reader.Bool("Fenced")
+	if version >= 1 {
+		record.inControlledShutdown = reader.Bool("InControlledShutdown")
+	}
+	if version >= 3 {
+		logDirsLength := reader.ArrayLength("LogDirs", true)
+		for range logDirsLength {
+			reader.UUID("LogDir")
+		}
+	}
+	reader.SkipTaggedFields()
+	return record
+}
+
+func decodePartitionRecord(reader *common.KafkaReader, version int) partitionRecord {
+	record := partitionRecord{
+		partitionID: reader.Int32("PartitionId"),
+		topicID:     reader.UUID("TopicId"),
+		replicas:    reader.Int32Array("Replicas", true),
+		isr:         reader.Int32Array("Isr", true),
+	}
+	reader.Int32Array("RemovingReplicas", true)
+	reader.Int32Array("AddingReplicas", true)
+	record.leader = reader.Int32("Leader")
+	record.leaderEpoch = reader.Int32("LeaderEpoch")
+	record.partitionEpoch = reader.Int32("PartitionEpoch")
+	if version >= 1 {
+		directoriesLength := reader.ArrayLength("Directories", true)
+		for range directoriesLength {
+			reader.UUID("Directory")
+		}
+	}
+	reader.SkipTaggedFields()
+	return record
+}
+
+// Tags of the optional PartitionChangeRecord fields
+const (
+	partitionChangeIsrTag      = 0
+	partitionChangeLeaderTag   = 1
+	partitionChangeReplicasTag = 2
+)
+
+func decodePartitionChangeRecord(reader *common.KafkaReader) partitionChangeRecord {
+	record := partitionChangeRecord{
+		partitionID: reader.Int32("PartitionId"),
+		topicID:     reader.UUID("TopicId"),
+		leader:      noLeaderChange,
+	}
+	for tag, data := range reader.TaggedFields("PartitionChangeRecord tagged fields") {
+		tagReader := common.NewKafkaReader(data, 0)
+		switch tag {
+		case partitionChangeIsrTag:
+			record.isr = tagReader.Int32Array("Isr", true)
+		case partitionChangeLeaderTag:
+			record.leader = tagReader.Int32("Leader")
+		case partitionChangeReplicasTag:
+			record.replicas = tagReader.Int32Array("Replicas", true)
+		}
+	}
+	return record
+}
+
+// metadataRecordValue frames an encoded record body the way the KRaft controller writes it
+func metadataRecordValue(recordType int, version int, body func(w *common.KafkaWriter)) []byte {
+	w := common.NewKafkaWriter()
+	w.UnsignedVarInt(1) // Frame version
+	w.UnsignedVarInt(recordType)
+	w.UnsignedVarInt(version)
+	if body != nil {
+		body(w)
+	}
+	w.EmptyTaggedFields()
+	return w.Bytes()
+}
+
+// encode writes the record as version 1, without features; this broker doesn't use them
+func (r registerBrokerRecord) encode() []byte {
+	return metadataRecordValue(registerBrokerRecordType, 1, func(w *common.KafkaWriter) {
+		w.Int32(r.brokerID)
+		w.UUID(r.incarnationID)
+		w.Int64(r.brokerEpoch)
+		w.ArrayLength(len(r.endpoints), true)
+		for _, endpoint := range r.endpoints {
+			w.String(endpoint.name, true)
+			w.String(endpoint.host, true)
+			w.Int16(int16(uint16(endpoint.port)))
+			w.Int16(endpoint.securityProtocol)
+			w.EmptyTaggedFields()
+		}
+		w.ArrayLength(0, true) // Features
+		var rack *string
+		if r.rack != "" {
+			rack = &r.rack
+		}
+		w.NullableString(rack, true)
+		w.Bool(r.fenced)
+		w.Bool(r.inControlledShutdown)
+	})
+}
+
+func (r topicRecord) encode() []byte {
+	return metadataRecordValue(topicRecordType, 0, func(w *common.KafkaWriter) {
+		w.String(r.name, true)
+		w.UUID(r.topicID)
+	})
+}
+
+// encode writes the record as version 0, which has no log directories
+func (r partitionRecord) encode() []byte {
+	return metadataRecordValue(partitionRecordType, 0, func(w *common.KafkaWriter) {
+		w.Int32(r.partitionID)
+		w.UUID(r.topicID)
+		w.Int32Array(r.replicas, true)
+		w.Int32Array(r.isr, true)
+		w.Int32Array(nil, true) // RemovingReplicas
+		w.Int32Array(nil, true) // AddingReplicas
+		w.Int32(r.leader)
+		w.Int32(r.leaderEpoch)
+		w.Int32(r.partitionEpoch)
+	})
+}
+
+func (r configRecord) encode() []byte {
+	return metadataRecordValue(configRecordType, 0, func(w *common.KafkaWriter) {
+		w.Int8(r.resourceType)
+		w.String(r.resourceName, true)
+		w.String(r.name, true)
+		if r.isNull {
+			w.NullableString(nil, true)
+		} else {
+			w.String(r.value, true)
+		}
+	})
+}
+
+func (r featureLevelRecord) encode() []byte {
+	return metadataRecordValue(featureLevelRecordType, 0, func(w *common.KafkaWriter) {
+		w.String(r.name, true)
+		w.Int16(r.featureLevel)
+	})
+}
+
+func (r producerIdsRecord) encode() []byte {
+	return metadataRecordValue(producerIdsRecordType, 0, func(w *common.KafkaWriter) {
+		w.Int32(r.brokerID)
+		w.Int64(r.brokerEpoch)
+		w.Int64(r.nextProducerID)
+	})
+}
+
+func (r removeTopicRecord) encode() []byte {
+	return metadataRecordValue(removeTopicRecordType, 0, func(w *common.KafkaWriter) {
+		w.UUID(r.topicID)
+	})
+}
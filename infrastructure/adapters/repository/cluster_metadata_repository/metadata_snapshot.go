@@ -0,0 +1,337 @@
+package cluster_metadata_repository
+
+import (
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	clutser_metadata_port "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/cluster_metadata"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+const (
+	metadataLogFileSuffix = ".log"
+	snapshotFileSuffix    = ".checkpoint"
+)
+
+// Control record types marking the start and end of a KRaft snapshot
+const (
+	snapshotHeaderControlType = 3
+	snapshotFooterControlType = 4
+)
+
+func metadataSegmentFileName(baseOffset int64) string {
+	return fmt.Sprintf("%020d%s", baseOffset, metadataLogFileSuffix)
+}
+
+// listMetadataLogSegments returns the base offsets of the metadata log segments in dir,
+// in offset order
+func listMetadataLogSegments(dir string) ([]int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := []int64{}
+	for _, entry := range entries {
+		name, found := strings.CutSuffix(entry.Name(), metadataLogFileSuffix)
+		if !found || len(name) != 20 {
+			continue
+		}
+		if baseOffset, err := strconv.ParseInt(name, 10, 64); err == nil {
+			segments = append(segments, baseOffset)
+		}
+	}
+	slices.Sort(segments)
+	return segments, nil
+}
+
+// snapshotID names a <endOffset>-<epoch>.checkpoint file. The snapshot holds the
+// metadata state up to, but not including, endOffset.
+type snapshotID struct {
+	endOffset int64
+	epoch     int32
+}
+
+func (id snapshotID) fileName() string {
+	return fmt.Sprintf("%020d-%010d%s", id.endOffset, id.epoch, snapshotFileSuffix)
+}
+
+func parseSnapshotFileName(name string) (snapshotID, bool) {
+	name, found := strings.CutSuffix(name, snapshotFileSuffix)
+	if !found {
+		return snapshotID{}, false
+	}
+	offset, epoch, found := strings.Cut(name, "-")
+	if !found || len(offset) != 20 || len(epoch) != 10 {
+		return snapshotID{}, false
+	}
+	endOffset, offsetErr := strconv.ParseInt(offset, 10, 64)
+	leaderEpoch, epochErr := strconv.ParseInt(epoch, 10, 32)
+	if offsetErr != nil || epochErr != nil {
+		return snapshotID{}, false
+	}
+	return snapshotID{endOffset: endOffset, epoch: int32(leaderEpoch)}, true
+}
+
+// loadLatestSnapshot applies the newest complete snapshot in dir to image and returns its
+// ID, whose end offset is where the log must be replayed from. Snapshots that are
+// unreadable or miss their header or footer are skipped in favor of older ones.
+func loadLatestSnapshot(dir string, image metadataImage) (snapshotID, bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return snapshotID{}, false, err
+	}
+
+	snapshots := []snapshotID{}
+	for _, entry := range entries {
+		if id, ok := parseSnapshotFileName(entry.Name()); ok {
+			snapshots = append(snapshots, id)
+		}
+	}
+	slices.SortFunc(snapshots, func(a, b snapshotID) int {
+		if a.endOffset != b.endOffset {
+			return int(b.endOffset - a.endOffset)
+		}
+		return int(b.epoch - a.epoch)
+	})
+
+	for _, id := range snapshots {
+		path := filepath.Join(dir, id.fileName())
+		data, err := os.ReadFile(path)
+		if err == nil {
+			err = validateSnapshot(data)
+		}
+		if err != nil {
+			fmt.Printf("Skipping metadata snapshot %s: %v\n", path, err)
+			continue
+		}
+		if _, _, err := image.processRecordBatches(data, 0); err != nil {
+			return snapshotID{}, false, fmt.Errorf("loading metadata snapshot %s: %w", path, err)
+		}
+		return id, true, nil
+	}
+	return snapshotID{}, false, nil
+}
+
+// validateSnapshot checks that a snapshot starts with a SnapshotHeader control record
+// and ends with a SnapshotFooter, so a snapshot cut short while being written is not used
+func validateSnapshot(data []byte) error {
+	batches, err := common.SplitRecordBatches(data)
+	if err != nil {
+		return err
+	}
+	if len(batches) == 0 {
+		return fmt.Errorf("snapshot is empty")
+	}
+	if err := expectControlRecord(batches[0], snapshotHeaderControlType); err != nil {
+		return fmt.Errorf("snapshot header: %w", err)
+	}
+	if err := expectControlRecord(batches[len(batches)-1], snapshotFooterControlType); err != nil {
+		return fmt.Errorf("snapshot footer: %w", err)
+	}
+	return nil
+}
+
+// expectControlRecord checks that batch is a control batch whose first record has the
+// given control type. A control record key is a version (INT16) and a type (INT16).
+func expectControlRecord(batch []byte, controlType int16) error {
+	decoded, err := common.DecodeRecordBatch(batch)
+	if err != nil {
+		return err
+	}
+	if !decoded.IsControl() || len(decoded.Records) == 0 || len(decoded.Records[0].Key) < 4 {
+		return fmt.Errorf("missing control record")
+	}
+	if got := int16(binary.BigEndian.Uint16(decoded.Records[0].Key[2:4])); got != controlType {
+		return fmt.Errorf("control record type %d, want %d", got, controlType)
+	}
+	return nil
+}
+
+// snapshotBatchRecords is the most metadata records written to one snapshot batch
+const snapshotBatchRecords = 1000
+
+// snapshotRecords returns the records that rebuild image: feature levels first, so
+// metadata.version is known before anything else, then brokers, topics with their
+// partitions, configs and the producer ID block
+func snapshotRecords(image *clutser_metadata_port.ClusterMetadataRepositoryResponse) [][]byte {
+	records := [][]byte{}
+	for _, name := range slices.Sorted(maps.Keys(image.FeatureLevels)) {
+		records = append(records, featureLevelRecord{name: name, featureLevel: image.FeatureLevels[name]}.encode())
+	}
+
+	for _, brokerID := range slices.Sorted(maps.Keys(image.Brokers)) {
+		broker := image.Brokers[brokerID]
+		record := registerBrokerRecord{
+			brokerID:             broker.BrokerID,
+			incarnationID:        broker.IncarnationID,
+			brokerEpoch:          broker.Epoch,
+			rack:                 broker.Rack,
+			fenced:               broker.Fenced,
+			inControlledShutdown: broker.InControlledShutdown,
+		}
+		for _, endpoint := range broker.Endpoints {
+			record.endpoints = append(record.endpoints, brokerEndpoint{
+				name:             endpoint.Name,
+				host:             endpoint.Host,
+				port:             endpoint.Port,
+				securityProtocol: endpoint.SecurityProtocol,
+			})
+		}
+		records = append(records, record.encode())
+	}
+
+	for _, topicName := range slices.Sorted(maps.Keys(image.TopicNameTopicUuidMap)) {
+		topicUuid := image.TopicNameTopicUuidMap[topicName]
+		topicMetadata, exists := image.TopicUUIDTopicMetadataInfoMap[topicUuid]
+		if !exists {
+			continue
+		}
+		records = append(records, topicRecord{name: topicName, topicID: topicMetadata.TopicId}.encode())
+
+		partitions := slices.Clone(image.TopicUUIDPartitionMetadataMap[topicUuid])
+		slices.SortFunc(partitions, func(a, b *domain.PartitionMetadata) int {
+			return common.BytesToInt(a.PartitionIndex) - common.BytesToInt(b.PartitionIndex)
+		})
+		for _, partition := range partitions {
+			records = append(records, partitionRecord{
+				partitionID:    int32(common.BytesToInt(partition.PartitionIndex)),
+				topicID:        topicMetadata.TopicId,
+				replicas:       decodeNodeIds(partition.ReplicaNodesArray),
+				isr:            decodeNodeIds(partition.IsrNodeArray),
+				leader:         int32(common.BytesToInt(partition.LeaderId)),
+				leaderEpoch:    int32(common.BytesToInt(partition.LeaderEpoch)),
+				partitionEpoch: int32(common.BytesToInt(partition.PartitionEpoch)),
+			}.encode())
+		}
+	}
+
+	resources := slices.SortedFunc(maps.Keys(image.Configs), func(a, b domain.ConfigResource) int {
+		if a.Type != b.Type {
+			return int(a.Type) - int(b.Type)
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	for _, resource := range resources {
+		configs := image.Configs[resource]
+		for _, name := range slices.Sorted(maps.Keys(configs)) {
+			records = append(records, configRecord{
+				resourceType: resource.Type,
+				resourceName: resource.Name,
+				name:         name,
+				value:        configs[name],
+			}.encode())
+		}
+	}
+
+	if image.NextProducerID > 0 {
+		records = append(records, producerIdsRecord{brokerID: -1, brokerEpoch: -1, nextProducerID: image.NextProducerID}.encode())
+	}
+	return records
+}
+
+// decodeNodeIds is the inverse of encodeNodeIds for the 4 byte IDs of a node list
+func decodeNodeIds(nodes []byte) []int32 {
+	nodeIds := make([]int32, 0, len(nodes)/4)
+	for i := 0; i+4 <= len(nodes); i += 4 {
+		nodeIds = append(nodeIds, int32(binary.BigEndian.Uint32(nodes[i:i+4])))
+	}
+	return nodeIds
+}
+
+// encodeSnapshot lays records out between a SnapshotHeader and a SnapshotFooter, every
+// batch stamped with the epoch of the snapshot
+func encodeSnapshot(id snapshotID, lastContainedLogTimestamp int64, records [][]byte) []byte {
+	header := common.NewKafkaWriter()
+	header.Int16(0) // Version
+	header.Int64(lastContainedLogTimestamp)
+	header.EmptyTaggedFields()
+	footer := common.NewKafkaWriter()
+	footer.Int16(0) // Version
+	footer.EmptyTaggedFields()
+
+	data := snapshotControlBatch(0, id.epoch, snapshotHeaderControlType, header.Bytes())
+	offset := int64(1)
+	for chunk := range slices.Chunk(records, snapshotBatchRecords) {
+		data = append(data, metadataBatch(offset, id.epoch, lastContainedLogTimestamp, chunk)...)
+		offset += int64(len(chunk))
+	}
+	return append(data, snapshotControlBatch(offset, id.epoch, snapshotFooterControlType, footer.Bytes())...)
+}
+
+func snapshotControlBatch(baseOffset int64, epoch int32, controlType int16, value []byte) []byte {
+	key := binary.BigEndian.AppendUint16(nil, 0) // Version
+	key = binary.BigEndian.AppendUint16(key, uint16(controlType))
+
+	batch := common.RecordBatch{Records: []common.Record{{Key: key, Value: value}}}
+	batch.BaseOffset = baseOffset
+	batch.PartitionLeaderEpoch = epoch
+	batch.Attributes = common.ControlBatchAttributeFlag
+	batch.ProducerID = -1
+	batch.ProducerEpoch = -1
+	batch.BaseSequence = -1
+	return common.EncodeRecordBatch(batch)
+}
+
+// writeSnapshot writes the snapshot to a temporary file and renames it into place, so a
+// crash never leaves a partial .checkpoint behind
+func writeSnapshot(dir string, id snapshotID, data []byte) error {
+	path := filepath.Join(dir, id.fileName())
+	file, err := os.CreateTemp(dir, id.fileName()+".*.part")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// pruneMetadataLog deletes the log segments whose records all come before snapshotOffset,
+// keeping the active segment, and the snapshots older than the first remaining segment
+func pruneMetadataLog(dir string, snapshotOffset int64, activeSegment int64) error {
+	segments, err := listMetadataLogSegments(dir)
+	if err != nil {
+		return err
+	}
+	logStartOffset := snapshotOffset
+	for i, baseOffset := range segments {
+		if baseOffset == activeSegment || i+1 == len(segments) || segments[i+1] > snapshotOffset {
+			logStartOffset = baseOffset
+			break
+		}
+		if err := os.Remove(filepath.Join(dir, metadataSegmentFileName(baseOffset))); err != nil {
+			return err
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if id, ok := parseSnapshotFileName(entry.Name()); ok && id.endOffset < logStartOffset {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package cluster_metadata_repository
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// CreateTopic appends the TopicRecord, ConfigRecords and PartitionRecords of a new topic
+// to the metadata log as one batch and applies it to the image
+func (c *ClusterMetadata) CreateTopic(topic domain.NewTopic) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.update(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if image := c.image.Load(); image != nil {
+		if _, exists := image.TopicNameTopicUuidMap[topic.Name]; exists {
+			return domain.ErrTopicAlreadyExists
+		}
+	}
+
+	records := [][]byte{topicRecord{name: topic.Name, topicID: topic.TopicID}.encode()}
+	for _, name := range slices.Sorted(maps.Keys(topic.Configs)) {
+		records = append(records, configRecord{
+			resourceType: domain.ConfigResourceTypeTopic,
+			resourceName: topic.Name,
+			name:         name,
+			value:        topic.Configs[name],
+		}.encode())
+	}
+	for _, partition := range topic.Partitions {
+		records = append(records, newPartitionRecord(topic.TopicID, partition).encode())
+	}
+	return c.appendRecords(records)
+}
+
+// CreatePartitions appends a PartitionRecord for each new partition of the topic as one
+// batch and applies it to the image
+func (c *ClusterMetadata) CreatePartitions(topicID []byte, partitions []domain.NewPartition) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.update(); err != nil {
+		return err
+	}
+	topicUuid := hex.EncodeToString(topicID)
+	image := c.image.Load()
+	if _, exists := image.TopicUUIDTopicMetadataInfoMap[topicUuid]; !exists {
+		return domain.ErrUnknownTopicID
+	}
+
+	current := len(image.TopicUUIDPartitionMetadataMap[topicUuid])
+	records := make([][]byte, 0, len(partitions))
+	for i, partition := range partitions {
+		if int(partition.PartitionIndex) != current+i {
+			return fmt.Errorf("%w: partition %d does not follow the %d existing ones", domain.ErrInvalidPartitions, partition.PartitionIndex, current)
+		}
+		records = append(records, newPartitionRecord(topicID, partition).encode())
+	}
+	return c.appendRecords(records)
+}
+
+// newPartitionRecord describes a new partition led by its first replica with every
+// replica in sync
+func newPartitionRecord(topicID []byte, partition domain.NewPartition) partitionRecord {
+	return partitionRecord{
+		partitionID: partition.PartitionIndex,
+		topicID:     topicID,
+		replicas:    partition.Replicas,
+		isr:         partition.Replicas,
+		leader:      partition.Replicas[0],
+	}
+}
+
+// DeleteTopic appends a RemoveTopicRecord for the topic and applies it to the image
+func (c *ClusterMetadata) DeleteTopic(topicID []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.update(); err != nil {
+		return err
+	}
+	if _, exists := c.image.Load().TopicUUIDTopicMetadataInfoMap[hex.EncodeToString(topicID)]; !exists {
+		return domain.ErrUnknownTopicID
+	}
+	return c.appendRecords([][]byte{removeTopicRecord{topicID: topicID}.encode()})
+}
+
+// AllocateProducerIds appends a ProducerIdsRecord moving the next producer ID past a
+// block of blockSize IDs and returns that block
+func (c *ClusterMetadata) AllocateProducerIds(brokerID int32, blockSize int64) (domain.ProducerIDBlock, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.update(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return domain.ProducerIDBlock{}, err
+	}
+	block := domain.ProducerIDBlock{Size: blockSize}
+	if image := c.image.Load(); image != nil {
+		block.FirstProducerID = image.NextProducerID
+	}
+	record := producerIdsRecord{brokerID: brokerID, brokerEpoch: -1, nextProducerID: block.NextBlockFirstProducerID()}
+	if err := c.appendRecords([][]byte{record.encode()}); err != nil {
+		return domain.ProducerIDBlock{}, err
+	}
+	return block, nil
+}
+
+// appendRecords writes records as one batch at the end of the active metadata log
+// segment, creating the log if there is none yet, then brings the image up to date
+func (c *ClusterMetadata) appendRecords(records [][]byte) error {
+	if err := os.MkdirAll(c.logDir, 0o755); err != nil {
+		return err
+	}
+	batch := metadataBatch(c.logEndOffset, c.lastEpoch, time.Now().UnixMilli(), records)
+
+	file, err := os.OpenFile(filepath.Join(c.logDir, metadataSegmentFileName(c.segmentBase)), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(batch); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := c.update(); err != nil {
+		return err
+	}
+	c.maybeSnapshot()
+	return nil
+}
+
+// metadataBatch builds an uncompressed batch of metadata records starting at baseOffset
+func metadataBatch(baseOffset int64, epoch int32, timestamp int64, records [][]byte) []byte {
+	batch := common.RecordBatch{}
+	batch.BaseOffset = baseOffset
+	batch.PartitionLeaderEpoch = epoch
+	batch.LastOffsetDelta = int32(len(records) - 1)
+	batch.BaseTimestamp = timestamp
+	batch.MaxTimestamp = timestamp
+	batch.ProducerID = -1
+	batch.ProducerEpoch = -1
+	batch.BaseSequence = -1
+	for i, value := range records {
+		batch.Records = append(batch.Records, common.Record{OffsetDelta: int32(i), Value: value})
+	}
+	return common.EncodeRecordBatch(batch)
+}
@@ -0,0 +1,162 @@
+package cluster_metadata_repository
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func TestClusterMetadata_CreateTopic(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "__cluster_metadata-0")
+	repo := NewClusterMetadataRepository(WithMetadataLogDir(dir))
+
+	topic := domain.NewTopic{
+		Name:    "foo",
+		TopicID: fooTopicID,
+		Partitions: []domain.NewPartition{
+			{PartitionIndex: 0, Replicas: []int32{1, 2}},
+			{PartitionIndex: 1, Replicas: []int32{2, 1}},
+		},
+		Configs: map[string]string{"retention.ms": "1000"},
+	}
+	if err := repo.CreateTopic(topic); err != nil {
+		t.Fatalf("CreateTopic() error = %v", err)
+	}
+	if err := repo.CreateTopic(domain.NewTopic{Name: "bar", TopicID: barTopicID, Partitions: []domain.NewPartition{{Replicas: []int32{1}}}}); err != nil {
+		t.Fatalf("CreateTopic() of a second topic error = %v", err)
+	}
+	if err := repo.CreateTopic(topic); !errors.Is(err, domain.ErrTopicAlreadyExists) {
+		t.Errorf("CreateTopic() of an existing topic error = %v, want ErrTopicAlreadyExists", err)
+	}
+
+	// The topics are in the image right away and in the log for the next broker start
+	for name, repo := range map[string]*ClusterMetadata{"writer": repo, "restarted": NewClusterMetadataRepository(WithMetadataLogDir(dir))} {
+		image, err := repo.GetClusterMetadata()
+		if err != nil {
+			t.Fatalf("%s: GetClusterMetadata() error = %v", name, err)
+		}
+		if _, exists := image.TopicNameTopicUuidMap["bar"]; !exists {
+			t.Errorf("%s: topic bar is missing", name)
+		}
+		partition := image.FindPartition("foo", 1)
+		if partition == nil || common.BytesToInt(partition.LeaderId) != 2 || !bytes.Equal(partition.IsrNodeArray, partition.ReplicaNodesArray) {
+			t.Fatalf("%s: partition foo-1 = %+v, want broker 2 leading with replicas 2 and 1 in sync", name, partition)
+		}
+		configs := image.Configs[domain.ConfigResource{Type: domain.ConfigResourceTypeTopic, Name: "foo"}]
+		if configs["retention.ms"] != "1000" {
+			t.Errorf("%s: foo configs = %v, want retention.ms=1000", name, configs)
+		}
+	}
+}
+
+func TestClusterMetadata_DeleteTopic(t *testing.T) {
+	dir := t.TempDir()
+	repo := NewClusterMetadataRepository(WithMetadataLogDir(dir))
+	if err := repo.CreateTopic(domain.NewTopic{Name: "foo", TopicID: fooTopicID, Partitions: []domain.NewPartition{{Replicas: []int32{1}}}}); err != nil {
+		t.Fatalf("CreateTopic() error = %v", err)
+	}
+
+	if err := repo.DeleteTopic(fooTopicID); err != nil {
+		t.Fatalf("DeleteTopic() error = %v", err)
+	}
+	if err := repo.DeleteTopic(fooTopicID); !errors.Is(err, domain.ErrUnknownTopicID) {
+		t.Errorf("DeleteTopic() of a deleted topic error = %v, want ErrUnknownTopicID", err)
+	}
+	for name, repo := range map[string]*ClusterMetadata{"writer": repo, "restarted": NewClusterMetadataRepository(WithMetadataLogDir(dir))} {
+		image, err := repo.GetClusterMetadata()
+		if err != nil {
+			t.Fatalf("%s: GetClusterMetadata() error = %v", name, err)
+		}
+		if _, exists := image.TopicNameTopicUuidMap["foo"]; exists || image.FindPartition("foo", 0) != nil {
+			t.Errorf("%s: deleted topic foo is still in the image", name)
+		}
+	}
+}
+
+func TestClusterMetadata_CreatePartitions(t *testing.T) {
+	repo := NewClusterMetadataRepository(WithMetadataLogDir(t.TempDir()))
+	if err := repo.CreateTopic(domain.NewTopic{Name: "foo", TopicID: fooTopicID, Partitions: []domain.NewPartition{{Replicas: []int32{1}}}}); err != nil {
+		t.Fatalf("CreateTopic() error = %v", err)
+	}
+
+	if err := repo.CreatePartitions(fooTopicID, []domain.NewPartition{{PartitionIndex: 1, Replicas: []int32{1}}, {PartitionIndex: 2, Replicas: []int32{1}}}); err != nil {
+		t.Fatalf("CreatePartitions() error = %v", err)
+	}
+	if err := repo.CreatePartitions(fooTopicID, []domain.NewPartition{{PartitionIndex: 2, Replicas: []int32{1}}}); !errors.Is(err, domain.ErrInvalidPartitions) {
+		t.Errorf("CreatePartitions() of an existing partition error = %v, want ErrInvalidPartitions", err)
+	}
+	if err := repo.CreatePartitions(barTopicID, []domain.NewPartition{{PartitionIndex: 0, Replicas: []int32{1}}}); !errors.Is(err, domain.ErrUnknownTopicID) {
+		t.Errorf("CreatePartitions() of an unknown topic error = %v, want ErrUnknownTopicID", err)
+	}
+
+	image, err := repo.GetClusterMetadata()
+	if err != nil {
+		t.Fatalf("GetClusterMetadata() error = %v", err)
+	}
+	if partitions := len(image.TopicUUIDPartitionMetadataMap[hex.EncodeToString(fooTopicID)]); partitions != 3 {
+		t.Errorf("foo has %d partitions, want 3", partitions)
+	}
+}
+
+func TestClusterMetadata_AllocateProducerIds(t *testing.T) {
+	dir := t.TempDir()
+	repo := NewClusterMetadataRepository(WithMetadataLogDir(dir))
+
+	for _, want := range []int64{0, 1000} {
+		block, err := repo.AllocateProducerIds(1, 1000)
+		if err != nil {
+			t.Fatalf("AllocateProducerIds() error = %v", err)
+		}
+		if block.FirstProducerID != want || block.Size != 1000 {
+			t.Errorf("AllocateProducerIds() = %+v, want the block starting at %d", block, want)
+		}
+	}
+
+	// A restarted controller does not hand out the same IDs again
+	restarted := NewClusterMetadataRepository(WithMetadataLogDir(dir))
+	block, err := restarted.AllocateProducerIds(1, 1000)
+	if err != nil {
+		t.Fatalf("AllocateProducerIds() after restart error = %v", err)
+	}
+	if block.FirstProducerID != 2000 {
+		t.Errorf("AllocateProducerIds() after restart = %+v, want the block starting at 2000", block)
+	}
+}
+
+func TestClusterMetadata_WriterRollsAndPrunesLog(t *testing.T) {
+	dir := t.TempDir()
+	repo := NewClusterMetadataRepository(WithMetadataLogDir(dir), WithSnapshotThresholds(0, 4))
+
+	// Every topic is three records, so most writes cross the threshold
+	for i := range 10 {
+		topicID := bytes.Repeat([]byte{byte(i + 1)}, 16)
+		if err := repo.CreateTopic(domain.NewTopic{Name: fmt.Sprintf("topic-%d", i), TopicID: topicID, Partitions: []domain.NewPartition{{Replicas: []int32{1}}, {PartitionIndex: 1, Replicas: []int32{1}}}}); err != nil {
+			t.Fatalf("CreateTopic(%d) error = %v", i, err)
+		}
+		if _, err := repo.AllocateProducerIds(1, 1000); err != nil {
+			t.Fatalf("AllocateProducerIds() error = %v", err)
+		}
+	}
+
+	segments, err := listMetadataLogSegments(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 1 || segments[0] != repo.segmentBase || segments[0] == 0 {
+		t.Errorf("segments = %v, want only a rolled active segment", segments)
+	}
+
+	restarted, err := NewClusterMetadataRepository(WithMetadataLogDir(dir)).GetClusterMetadata()
+	if err != nil {
+		t.Fatalf("GetClusterMetadata() after the restart error = %v", err)
+	}
+	if len(restarted.TopicNameTopicUuidMap) != 10 || restarted.NextProducerID != 10_000 {
+		t.Errorf("restart has %d topics and next producer ID %d, want 10 and 10000", len(restarted.TopicNameTopicUuidMap), restarted.NextProducerID)
+	}
+}
@@ -0,0 +1,272 @@
+package consumer_offsets_repository
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	port_repo "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/partition_file_repository"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+const (
+	// ConsumerOffsetsTopic is the internal topic committed offsets are kept in
+	ConsumerOffsetsTopic = domain.ConsumerOffsetsTopic
+	// DefaultPartitionCount matches Kafka's offsets.topic.num.partitions
+	DefaultPartitionCount = 50
+
+	// loadMaxBytes is how much of a partition is read at a time while loading
+	loadMaxBytes = 1 << 20
+)
+
+// ConsumerOffsetsRepository writes committed offsets to the partitions of the
+// __consumer_offsets topic in Kafka's record format, a group always landing in the same
+// partition. The partitions are plain partition logs; the topic isn't part of the
+// cluster metadata.
+type ConsumerOffsetsRepository struct {
+	partitions     port_repo.PartitionFileRepository
+	partitionCount int
+}
+
+// ConsumerOffsetsRepositoryOption configures a ConsumerOffsetsRepository
+type ConsumerOffsetsRepositoryOption func(*ConsumerOffsetsRepository)
+
+// WithPartitionCount sets how many partitions the groups are spread over. Changing it
+// moves groups to other partitions, losing their committed offsets.
+func WithPartitionCount(partitionCount int) ConsumerOffsetsRepositoryOption {
+	return func(r *ConsumerOffsetsRepository) {
+		r.partitionCount = partitionCount
+	}
+}
+
+func NewConsumerOffsetsRepository(partitions port_repo.PartitionFileRepository, opts ...ConsumerOffsetsRepositoryOption) *ConsumerOffsetsRepository {
+	r := &ConsumerOffsetsRepository{
+		partitions:     partitions,
+		partitionCount: DefaultPartitionCount,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// PartitionFor returns the partition holding the offsets of a group, computed from the
+// Java hash code of the group ID like Kafka does
+func (r *ConsumerOffsetsRepository) PartitionFor(groupID string) int {
+	hash := int32(0)
+	for _, unit := range utf16.Encode([]rune(groupID)) {
+		hash = 31*hash + int32(unit)
+	}
+	return int(hash&0x7fffffff) % r.partitionCount
+}
+
+// StoreOffsets appends an OffsetCommit record for each partition as one batch
+func (r *ConsumerOffsetsRepository) StoreOffsets(groupID string, offsets map[domain.TopicPartition]domain.CommittedOffset) error {
+	records := make([]common.Record, 0, len(offsets))
+	for _, tp := range sortedPartitions(slices.Collect(maps.Keys(offsets))) {
+		records = append(records, common.Record{
+			Key:   offsetCommitKey{groupID: groupID, topic: tp.Topic, partition: tp.Partition}.encode(),
+			Value: encodeOffsetCommitValue(offsets[tp]),
+		})
+	}
+	return r.append(groupID, -1, -1, records)
+}
+
+// StoreTransactionalOffsets appends the OffsetCommit records as one transactional batch
+// of the producer. The batch has no sequence: the coordinator writes it, not the producer.
+func (r *ConsumerOffsetsRepository) StoreTransactionalOffsets(groupID string, producerID int64, producerEpoch int16, offsets map[domain.TopicPartition]domain.CommittedOffset) error {
+	records := make([]common.Record, 0, len(offsets))
+	for _, tp := range sortedPartitions(slices.Collect(maps.Keys(offsets))) {
+		records = append(records, common.Record{
+			Key:   offsetCommitKey{groupID: groupID, topic: tp.Topic, partition: tp.Partition}.encode(),
+			Value: encodeOffsetCommitValue(offsets[tp]),
+		})
+	}
+	return r.append(groupID, producerID, producerEpoch, records)
+}
+
+// DeleteOffsets appends a tombstone, a record without value, for each partition
+func (r *ConsumerOffsetsRepository) DeleteOffsets(groupID string, partitions []domain.TopicPartition) error {
+	records := make([]common.Record, 0, len(partitions))
+	for _, tp := range sortedPartitions(partitions) {
+		records = append(records, common.Record{
+			Key: offsetCommitKey{groupID: groupID, topic: tp.Topic, partition: tp.Partition}.encode(),
+		})
+	}
+	return r.append(groupID, -1, -1, records)
+}
+
+// append writes the records as one batch, a transactional one when a producer is given
+func (r *ConsumerOffsetsRepository) append(groupID string, producerID int64, producerEpoch int16, records []common.Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	timestamp := time.Now().UnixMilli()
+	batch := common.RecordBatch{Records: records}
+	batch.LastOffsetDelta = int32(len(records) - 1)
+	batch.BaseTimestamp = timestamp
+	batch.MaxTimestamp = timestamp
+	batch.ProducerID = producerID
+	batch.ProducerEpoch = producerEpoch
+	batch.BaseSequence = -1
+	if producerID >= 0 {
+		batch.Attributes = common.TransactionalAttributeFlag
+	}
+	for i := range batch.Records {
+		batch.Records[i].OffsetDelta = int32(i)
+	}
+
+	_, err := r.partitions.AppendRecordBatches(domain.AppendRequest{
+		TopicName:      ConsumerOffsetsTopic,
+		PartitionIndex: r.PartitionFor(groupID),
+		Records:        common.EncodeRecordBatch(batch),
+	})
+	return err
+}
+
+// LoadOffsets replays every partition from its start. The last record of a key wins and
+// a tombstone removes the offset. The offsets of a transaction are applied when its
+// commit marker is read and dropped on an abort marker.
+func (r *ConsumerOffsetsRepository) LoadOffsets() (domain.LoadedOffsets, error) {
+	loaded := domain.LoadedOffsets{
+		Committed: make(map[string]map[domain.TopicPartition]domain.CommittedOffset),
+		Pending:   make(map[int64]map[string]map[domain.TopicPartition]domain.CommittedOffset),
+	}
+	for partitionIndex := range r.partitionCount {
+		if err := r.loadPartition(partitionIndex, loaded); err != nil {
+			return domain.LoadedOffsets{}, fmt.Errorf("loading %s-%d: %w", ConsumerOffsetsTopic, partitionIndex, err)
+		}
+	}
+	return loaded, nil
+}
+
+func (r *ConsumerOffsetsRepository) loadPartition(partitionIndex int, loaded domain.LoadedOffsets) error {
+	logOffsets, err := r.partitions.GetLogOffsets(ConsumerOffsetsTopic, partitionIndex)
+	if err != nil {
+		return err
+	}
+
+	// Transactions are tracked per partition, each has its own marker
+	pending := make(map[int64]map[string]map[domain.TopicPartition]domain.CommittedOffset)
+	offset := logOffsets.LogStartOffset
+	for offset < logOffsets.HighWatermark {
+		read, err := r.partitions.ReadRecordBatches(domain.ReadRequest{
+			TopicName:      ConsumerOffsetsTopic,
+			PartitionIndex: partitionIndex,
+			FetchOffset:    offset,
+			MaxBytes:       loadMaxBytes,
+			MinOneBatch:    true,
+		})
+		if err != nil {
+			return err
+		}
+		batches, err := common.SplitRecordBatches(read.Records)
+		if err != nil {
+			return err
+		}
+		if len(batches) == 0 {
+			break
+		}
+		for _, data := range batches {
+			batch, err := common.DecodeRecordBatch(data)
+			if err != nil {
+				return err
+			}
+			switch {
+			case batch.IsControl():
+				if batch.BaseOffset >= offset {
+					if err := completeTransaction(batch, pending, loaded.Committed); err != nil {
+						return err
+					}
+				}
+			default:
+				groups := loaded.Committed
+				if batch.IsTransactional() {
+					if pending[batch.ProducerID] == nil {
+						pending[batch.ProducerID] = make(map[string]map[domain.TopicPartition]domain.CommittedOffset)
+					}
+					groups = pending[batch.ProducerID]
+				}
+				for _, record := range batch.Records {
+					if batch.BaseOffset+int64(record.OffsetDelta) < offset {
+						continue
+					}
+					if err := applyRecord(record, groups); err != nil {
+						return err
+					}
+				}
+			}
+			offset = batch.NextOffset()
+		}
+	}
+
+	for producerID, groups := range pending {
+		if loaded.Pending[producerID] == nil {
+			loaded.Pending[producerID] = make(map[string]map[domain.TopicPartition]domain.CommittedOffset)
+		}
+		for groupID, offsets := range groups {
+			loaded.Pending[producerID][groupID] = offsets
+		}
+	}
+	return nil
+}
+
+// completeTransaction applies the offsets of the producer's transaction when the marker
+// commits it, and drops them either way
+func completeTransaction(batch common.RecordBatch, pending map[int64]map[string]map[domain.TopicPartition]domain.CommittedOffset, committed map[string]map[domain.TopicPartition]domain.CommittedOffset) error {
+	marker, err := common.DecodeControlRecord(batch)
+	if err != nil {
+		return err
+	}
+	groups := pending[batch.ProducerID]
+	delete(pending, batch.ProducerID)
+	if marker.Type != common.ControlRecordTypeCommit {
+		return nil
+	}
+	for groupID, offsets := range groups {
+		if committed[groupID] == nil {
+			committed[groupID] = make(map[domain.TopicPartition]domain.CommittedOffset)
+		}
+		maps.Copy(committed[groupID], offsets)
+	}
+	return nil
+}
+
+func applyRecord(record common.Record, groups map[string]map[domain.TopicPartition]domain.CommittedOffset) error {
+	key, isOffsetCommit, err := decodeOffsetCommitKey(record.Key)
+	if err != nil || !isOffsetCommit {
+		return err
+	}
+	tp := domain.TopicPartition{Topic: key.topic, Partition: key.partition}
+	if record.Value == nil {
+		delete(groups[key.groupID], tp)
+		if len(groups[key.groupID]) == 0 {
+			delete(groups, key.groupID)
+		}
+		return nil
+	}
+
+	value, err := decodeOffsetCommitValue(record.Value)
+	if err != nil {
+		return err
+	}
+	if groups[key.groupID] == nil {
+		groups[key.groupID] = make(map[domain.TopicPartition]domain.CommittedOffset)
+	}
+	groups[key.groupID][tp] = value
+	return nil
+}
+
+func sortedPartitions(partitions []domain.TopicPartition) []domain.TopicPartition {
+	return slices.SortedFunc(slices.Values(partitions), func(a, b domain.TopicPartition) int {
+		if a.Topic != b.Topic {
+			return strings.Compare(a.Topic, b.Topic)
+		}
+		return cmp.Compare(a.Partition, b.Partition)
+	})
+}
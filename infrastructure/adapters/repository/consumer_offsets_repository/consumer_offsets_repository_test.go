@@ -0,0 +1,104 @@
+package consumer_offsets_repository
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	partition_file_repository "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/partition_repository"
+)
+
+func TestConsumerOffsetsRepository_PartitionFor(t *testing.T) {
+	r := NewConsumerOffsetsRepository(partition_file_repository.NewPartitionFileRepositoryWithLogDir(t.TempDir()))
+	// abs(String.hashCode()) % 50, as Kafka computes it
+	for groupID, want := range map[string]int{"": 0, "group-a": 47, "console-consumer-12345": 6} {
+		if got := r.PartitionFor(groupID); got != want {
+			t.Errorf("PartitionFor(%q) = %d, want %d", groupID, got, want)
+		}
+	}
+}
+
+func TestConsumerOffsetsRepository_StoreAndLoad(t *testing.T) {
+	logDir := t.TempDir()
+	r := NewConsumerOffsetsRepository(partition_file_repository.NewPartitionFileRepositoryWithLogDir(logDir))
+
+	orders0 := domain.TopicPartition{Topic: "orders", Partition: 0}
+	orders1 := domain.TopicPartition{Topic: "orders", Partition: 1}
+	if err := r.StoreOffsets("group-a", map[domain.TopicPartition]domain.CommittedOffset{
+		orders0: {Offset: 10, LeaderEpoch: 2, Metadata: "first", CommitTimestamp: 1000, ExpireTimestamp: -1},
+		orders1: {Offset: 20, LeaderEpoch: -1, CommitTimestamp: 1000, ExpireTimestamp: 5000},
+	}); err != nil {
+		t.Fatalf("StoreOffsets() error = %v", err)
+	}
+	if err := r.StoreOffsets("group-a", map[domain.TopicPartition]domain.CommittedOffset{
+		orders0: {Offset: 15, LeaderEpoch: 3, CommitTimestamp: 2000, ExpireTimestamp: -1},
+	}); err != nil {
+		t.Fatalf("StoreOffsets() error = %v", err)
+	}
+	if err := r.StoreOffsets("group-b", map[domain.TopicPartition]domain.CommittedOffset{
+		orders0: {Offset: 7, LeaderEpoch: -1, CommitTimestamp: 3000, ExpireTimestamp: -1},
+	}); err != nil {
+		t.Fatalf("StoreOffsets() error = %v", err)
+	}
+	if err := r.DeleteOffsets("group-b", []domain.TopicPartition{orders0}); err != nil {
+		t.Fatalf("DeleteOffsets() error = %v", err)
+	}
+
+	// A fresh repository reads what the first one wrote
+	reloaded := NewConsumerOffsetsRepository(partition_file_repository.NewPartitionFileRepositoryWithLogDir(logDir))
+	got, err := reloaded.LoadOffsets()
+	if err != nil {
+		t.Fatalf("LoadOffsets() error = %v", err)
+	}
+	want := map[string]map[domain.TopicPartition]domain.CommittedOffset{
+		"group-a": {
+			orders0: {Offset: 15, LeaderEpoch: 3, CommitTimestamp: 2000, ExpireTimestamp: -1},
+			orders1: {Offset: 20, LeaderEpoch: -1, CommitTimestamp: 1000, ExpireTimestamp: 5000},
+		},
+	}
+	if !reflect.DeepEqual(got.Committed, want) || len(got.Pending) != 0 {
+		t.Errorf("LoadOffsets() = %+v, want %+v", got, want)
+	}
+}
+
+func TestConsumerOffsetsRepository_TransactionalOffsets(t *testing.T) {
+	logDir := t.TempDir()
+	partitions := partition_file_repository.NewPartitionFileRepositoryWithLogDir(logDir)
+	r := NewConsumerOffsetsRepository(partitions)
+	orders0 := domain.TopicPartition{Topic: "orders", Partition: 0}
+	offsetOf := func(offset int64) map[domain.TopicPartition]domain.CommittedOffset {
+		return map[domain.TopicPartition]domain.CommittedOffset{orders0: {Offset: offset, LeaderEpoch: -1, CommitTimestamp: 1000, ExpireTimestamp: -1}}
+	}
+	endTxn := func(groupID string, producerID int64, committed bool) {
+		t.Helper()
+		if _, err := partitions.WriteTxnMarker(domain.TxnMarkerAppend{
+			TopicName:      ConsumerOffsetsTopic,
+			PartitionIndex: r.PartitionFor(groupID),
+			ProducerID:     producerID,
+			Committed:      committed,
+		}); err != nil {
+			t.Fatalf("WriteTxnMarker() error = %v", err)
+		}
+	}
+
+	r.StoreOffsets("group-a", offsetOf(5))
+	// Committed, aborted and still open transactions
+	r.StoreTransactionalOffsets("group-a", 4000, 0, offsetOf(10))
+	endTxn("group-a", 4000, true)
+	r.StoreTransactionalOffsets("group-a", 4001, 0, offsetOf(20))
+	endTxn("group-a", 4001, false)
+	r.StoreTransactionalOffsets("group-b", 4002, 0, offsetOf(30))
+
+	got, err := NewConsumerOffsetsRepository(partition_file_repository.NewPartitionFileRepositoryWithLogDir(logDir)).LoadOffsets()
+	if err != nil {
+		t.Fatalf("LoadOffsets() error = %v", err)
+	}
+	wantCommitted := map[string]map[domain.TopicPartition]domain.CommittedOffset{"group-a": offsetOf(10)}
+	if !reflect.DeepEqual(got.Committed, wantCommitted) {
+		t.Errorf("Committed = %+v, want %+v", got.Committed, wantCommitted)
+	}
+	wantPending := map[int64]map[string]map[domain.TopicPartition]domain.CommittedOffset{4002: {"group-b": offsetOf(30)}}
+	if !reflect.DeepEqual(got.Pending, wantPending) {
+		t.Errorf("Pending = %+v, want %+v", got.Pending, wantPending)
+	}
+}
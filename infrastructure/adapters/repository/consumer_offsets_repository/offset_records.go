@@ -0,0 +1,103 @@
+package consumer_offsets_repository
+
+import (
+	"fmt"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// Versions of the __consumer_offsets record keys and values
+// (https://github.com/apache/kafka/tree/trunk/group-coordinator/src/main/resources/common/message)
+const (
+	// offsetCommitKeyVersion keys the committed offset of a group's partition; versions 0
+	// and 1 share the schema
+	offsetCommitKeyVersion = 1
+	// groupMetadataKeyVersion keys the membership of a group, which isn't persisted here
+	groupMetadataKeyVersion = 2
+
+	// offsetCommitValueVersion carries the leader epoch
+	offsetCommitValueVersion = 3
+	// offsetCommitValueExpireVersion is the only version carrying an expire timestamp
+	offsetCommitValueExpireVersion = 1
+)
+
+// offsetCommitKey is the key of an OffsetCommit record
+type offsetCommitKey struct {
+	groupID   string
+	topic     string
+	partition int32
+}
+
+func (k offsetCommitKey) encode() []byte {
+	w := common.NewKafkaWriter()
+	w.Int16(offsetCommitKeyVersion)
+	w.String(k.groupID, false)
+	w.String(k.topic, false)
+	w.Int32(k.partition)
+	return w.Bytes()
+}
+
+// decodeOffsetCommitKey decodes the key of a record, reporting false for the keys of
+// other record types
+func decodeOffsetCommitKey(data []byte) (offsetCommitKey, bool, error) {
+	reader := common.NewKafkaReader(data, 0)
+	version := reader.Int16("key version")
+	if err := reader.Err(); err != nil {
+		return offsetCommitKey{}, false, err
+	}
+	if version < 0 || version >= groupMetadataKeyVersion {
+		return offsetCommitKey{}, false, nil
+	}
+	key := offsetCommitKey{
+		groupID:   reader.String("group", false),
+		topic:     reader.String("topic", false),
+		partition: reader.Int32("partition"),
+	}
+	return key, true, reader.Err()
+}
+
+// encodeOffsetCommitValue writes version 3, or version 1 for an offset that expires on
+// its own
+func encodeOffsetCommitValue(offset domain.CommittedOffset) []byte {
+	w := common.NewKafkaWriter()
+	if offset.ExpireTimestamp >= 0 {
+		w.Int16(offsetCommitValueExpireVersion)
+		w.Int64(offset.Offset)
+		w.String(offset.Metadata, false)
+		w.Int64(offset.CommitTimestamp)
+		w.Int64(offset.ExpireTimestamp)
+		return w.Bytes()
+	}
+	w.Int16(offsetCommitValueVersion)
+	w.Int64(offset.Offset)
+	w.Int32(offset.LeaderEpoch)
+	w.String(offset.Metadata, false)
+	w.Int64(offset.CommitTimestamp)
+	return w.Bytes()
+}
+
+func decodeOffsetCommitValue(data []byte) (domain.CommittedOffset, error) {
+	reader := common.NewKafkaReader(data, 0)
+	version := reader.Int16("value version")
+	if err := reader.Err(); err != nil {
+		return domain.CommittedOffset{}, err
+	}
+	if version < 0 || version > offsetCommitValueVersion {
+		return domain.CommittedOffset{}, fmt.Errorf("unknown offset commit value version %d", version)
+	}
+	offset := domain.CommittedOffset{
+		Offset:          reader.Int64("offset"),
+		LeaderEpoch:     -1,
+		ExpireTimestamp: -1,
+	}
+	if version >= 3 {
+		offset.LeaderEpoch = reader.Int32("leader epoch")
+	}
+	offset.Metadata = reader.String("metadata", false)
+	offset.CommitTimestamp = reader.Int64("commit timestamp")
+	if version == offsetCommitValueExpireVersion {
+		offset.ExpireTimestamp = reader.Int64("expire timestamp")
+	}
+	return offset, reader.Err()
+}
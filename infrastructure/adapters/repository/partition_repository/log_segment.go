@@ -0,0 +1,260 @@
+package partition_file_repository
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// Files making up a segment, all named after the segment's zero-padded base offset
+const (
+	logFileSuffix       = ".log"
+	indexFileSuffix     = ".index"
+	timeIndexFileSuffix = ".timeindex"
+	txnIndexFileSuffix  = ".txnindex"
+)
+
+func segmentFileName(baseOffset int64, suffix string) string {
+	return fmt.Sprintf("%020d%s", baseOffset, suffix)
+}
+
+// logSegment is one <baseOffset>.log file of a partition together with its
+// .index, .timeindex and .txnindex files
+type logSegment struct {
+	dir                      string
+	baseOffset               int64
+	indexIntervalBytes       int64
+	size                     int64 // Bytes of complete batches in the .log file
+	nextOffset               int64 // Offset following the last batch in the segment
+	maxTimestamp             int64
+	offsetOfMaxTimestamp     int64 // Last offset of the batch holding maxTimestamp
+	bytesSinceLastIndexEntry int64
+	index                    *offsetIndex
+	timeIndex                *timeIndex
+	txnIndex                 *txnIndex
+}
+
+func newLogSegment(dir string, baseOffset int64, indexIntervalBytes int64) *logSegment {
+	return &logSegment{
+		dir:                  dir,
+		baseOffset:           baseOffset,
+		indexIntervalBytes:   indexIntervalBytes,
+		nextOffset:           baseOffset,
+		maxTimestamp:         -1,
+		offsetOfMaxTimestamp: baseOffset,
+		index:                &offsetIndex{path: filepath.Join(dir, segmentFileName(baseOffset, indexFileSuffix)), baseOffset: baseOffset},
+		timeIndex:            &timeIndex{path: filepath.Join(dir, segmentFileName(baseOffset, timeIndexFileSuffix)), baseOffset: baseOffset},
+		txnIndex:             &txnIndex{path: filepath.Join(dir, segmentFileName(baseOffset, txnIndexFileSuffix))},
+	}
+}
+
+// createLogSegment creates the empty files of a new segment starting at baseOffset
+func createLogSegment(dir string, baseOffset int64, indexIntervalBytes int64) (*logSegment, error) {
+	segment := newLogSegment(dir, baseOffset, indexIntervalBytes)
+	for _, path := range []string{segment.logPath(), segment.index.path, segment.timeIndex.path} {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		file.Close()
+	}
+	return segment, nil
+}
+
+// openLogSegment loads an existing segment. Valid indexes are trusted up to their last
+// entry and only the batches after it are scanned; missing or corrupt indexes are rebuilt
+// from the whole .log file. A torn batch at the end of the file is truncated away.
+func openLogSegment(dir string, baseOffset int64, indexIntervalBytes int64) (*logSegment, error) {
+	segment := newLogSegment(dir, baseOffset, indexIntervalBytes)
+	info, err := os.Stat(segment.logPath())
+	if err != nil {
+		return nil, err
+	}
+	segment.size = info.Size()
+
+	index, indexErr := loadOffsetIndex(segment.index.path, baseOffset, segment.size)
+	timeIndex, timeIndexErr := loadTimeIndex(segment.timeIndex.path, baseOffset)
+	for _, err := range []error{indexErr, timeIndexErr} {
+		if err != nil && !errors.Is(err, errCorruptIndex) {
+			return nil, err
+		}
+	}
+
+	if indexErr == nil && timeIndexErr == nil {
+		segment.index = index
+		segment.timeIndex = timeIndex
+		position := int64(0)
+		if last, ok := index.last(); ok {
+			position = int64(last.position)
+		}
+		if last, ok := timeIndex.last(); ok {
+			segment.maxTimestamp = last.timestamp
+			segment.offsetOfMaxTimestamp = baseOffset + int64(last.relativeOffset)
+		}
+		if err := segment.recover(position); err != nil {
+			return nil, err
+		}
+		if position == 0 || segment.size > position {
+			return segment, segment.txnIndex.load(segment.nextOffset)
+		}
+		// The last indexed batch was torn off the end of the segment
+		indexErr = fmt.Errorf("%w: %s points past the last complete batch", errCorruptIndex, segment.index.path)
+	}
+
+	fmt.Printf("Rebuilding indexes of %s: %v\n", segment.logPath(), errors.Join(indexErr, timeIndexErr))
+	if err := segment.rebuildIndexes(); err != nil {
+		return nil, err
+	}
+	return segment, segment.txnIndex.load(segment.nextOffset)
+}
+
+func (s *logSegment) logPath() string {
+	return filepath.Join(s.dir, segmentFileName(s.baseOffset, logFileSuffix))
+}
+
+// rebuildIndexes replaces both index files with ones built from the whole .log file
+func (s *logSegment) rebuildIndexes() error {
+	fresh := newLogSegment(s.dir, s.baseOffset, s.indexIntervalBytes)
+	fresh.size = s.size
+	*s = *fresh
+	if err := s.index.write(); err != nil {
+		return err
+	}
+	if err := s.timeIndex.write(); err != nil {
+		return err
+	}
+	return s.recover(0)
+}
+
+// recover walks the batch headers from position to the end of the .log file, restoring the
+// segment's offsets and timestamps and indexing the batches as if they were appended again
+func (s *logSegment) recover(position int64) error {
+	file, err := os.Open(s.logPath())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fileSize := s.size
+	s.size = position
+	for {
+		header, err := readBatchHeaderAt(file, position, fileSize)
+		if err != nil {
+			break
+		}
+		if err := s.indexBatch(header, position); err != nil {
+			return err
+		}
+		position += int64(header.Size())
+		s.size = position
+	}
+
+	if s.size < fileSize {
+		// A torn write at the tail of the segment; everything before it is still readable
+		fmt.Printf("Truncating %s from %d to %d bytes\n", s.logPath(), fileSize, s.size)
+		return os.Truncate(s.logPath(), s.size)
+	}
+	return nil
+}
+
+// indexBatch accounts for the batch at position, adding index entries every
+// indexIntervalBytes the same way Kafka does
+func (s *logSegment) indexBatch(header common.RecordBatchHeader, position int64) error {
+	if header.MaxTimestamp > s.maxTimestamp {
+		s.maxTimestamp = header.MaxTimestamp
+		s.offsetOfMaxTimestamp = header.LastOffset()
+	}
+
+	if s.bytesSinceLastIndexEntry > s.indexIntervalBytes {
+		if err := s.index.append(header.LastOffset(), position); err != nil {
+			return err
+		}
+		if err := s.timeIndex.maybeAppend(s.maxTimestamp, s.offsetOfMaxTimestamp); err != nil {
+			return err
+		}
+		s.bytesSinceLastIndexEntry = 0
+	}
+
+	s.bytesSinceLastIndexEntry += int64(header.Size())
+	s.nextOffset = header.NextOffset()
+	return nil
+}
+
+// append writes batches whose offsets are already assigned to the end of the segment
+func (s *logSegment) append(batches [][]byte) error {
+	records := []byte{}
+	for _, batch := range batches {
+		records = append(records, batch...)
+	}
+	if err := appendToFile(s.logPath(), records); err != nil {
+		return err
+	}
+
+	for _, batch := range batches {
+		header, _ := common.ReadRecordBatchHeader(batch)
+		if err := s.indexBatch(header, s.size); err != nil {
+			return err
+		}
+		s.size += int64(len(batch))
+	}
+	return nil
+}
+
+// close adds the final time index entry Kafka writes when a segment stops being active,
+// so the segment's max timestamp survives a restart
+func (s *logSegment) close() error {
+	if s.maxTimestamp < 0 {
+		return nil
+	}
+	return s.timeIndex.maybeAppend(s.maxTimestamp, s.offsetOfMaxTimestamp)
+}
+
+// forEachBatch calls fn for every complete batch from position on, in offset order,
+// until fn returns false
+func (s *logSegment) forEachBatch(position int64, fn func(header common.RecordBatchHeader, batch []byte) bool) error {
+	file, err := os.Open(s.logPath())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	for position < s.size {
+		header, err := readBatchHeaderAt(file, position, s.size)
+		if err != nil {
+			return err
+		}
+		batch := make([]byte, header.Size())
+		if _, err := file.ReadAt(batch, position); err != nil {
+			return err
+		}
+		if !fn(header, batch) {
+			return nil
+		}
+		position += int64(header.Size())
+	}
+	return nil
+}
+
+// readBatchHeaderAt reads the header of the batch at position, failing if the batch is
+// not a complete v2 batch within the first fileSize bytes
+func readBatchHeaderAt(file *os.File, position int64, fileSize int64) (common.RecordBatchHeader, error) {
+	if position+common.RecordBatchHeaderSize > fileSize {
+		return common.RecordBatchHeader{}, io.ErrUnexpectedEOF
+	}
+	data := make([]byte, common.RecordBatchHeaderSize)
+	if _, err := file.ReadAt(data, position); err != nil {
+		return common.RecordBatchHeader{}, err
+	}
+	header, err := common.ReadRecordBatchHeader(data)
+	if err != nil {
+		return common.RecordBatchHeader{}, err
+	}
+	if header.Magic != common.RecordBatchMagic || header.Size() < common.RecordBatchHeaderSize || position+int64(header.Size()) > fileSize {
+		return common.RecordBatchHeader{}, fmt.Errorf("%w at position %d", common.ErrCorruptRecordBatch, position)
+	}
+	return header, nil
+}
@@ -0,0 +1,130 @@
+package partition_file_repository
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+)
+
+// segmentedTestLog appends ten two-record batches (offsets 0-19, timestamps 1000, 1010, ...)
+// to a partition whose segments roll every three batches and index every batch
+func segmentedTestLog(t *testing.T, logDir string) {
+	t.Helper()
+	batchSize := int64(len(timestampedBatch(0, 0, 5)))
+	repo := NewPartitionFileRepositoryWithLogDir(logDir, WithSegmentBytes(3*batchSize), WithIndexIntervalBytes(1))
+	for i := range 10 {
+		batch := timestampedBatch(1000+int64(i)*10, 0, 5)
+		if _, err := repo.AppendRecordBatches(domain.AppendRequest{TopicName: "foo", PartitionIndex: 0, Records: batch}); err != nil {
+			t.Fatalf("AppendRecordBatches() error = %v", err)
+		}
+	}
+}
+
+func checkSegmentedTestLog(t *testing.T, repo *PartitionFileRepository) {
+	t.Helper()
+
+	offsets, err := repo.GetLogOffsets("foo", 0)
+	if err != nil || offsets.LogStartOffset != 0 || offsets.HighWatermark != 20 {
+		t.Errorf("GetLogOffsets() = %+v, %v, want [0, 20]", offsets, err)
+	}
+
+	for _, fetchOffset := range []int64{0, 5, 7, 13, 19} {
+		result, err := repo.ReadRecordBatches(domain.ReadRequest{TopicName: "foo", PartitionIndex: 0, FetchOffset: fetchOffset, MaxBytes: 1 << 20})
+		if err != nil {
+			t.Fatalf("ReadRecordBatches(%d) error = %v", fetchOffset, err)
+		}
+		wantBatches := 10 - int(fetchOffset/2)
+		if len(result.Records) != wantBatches*len(timestampedBatch(0, 0, 5)) {
+			t.Errorf("ReadRecordBatches(%d) returned %d bytes, want %d batches", fetchOffset, len(result.Records), wantBatches)
+		}
+	}
+
+	lookups := []struct {
+		timestamp int64
+		want      domain.OffsetLookupResult
+	}{
+		{timestamp: 0, want: domain.OffsetLookupResult{Found: true, Offset: 0, Timestamp: 1000}},
+		{timestamp: 1032, want: domain.OffsetLookupResult{Found: true, Offset: 7, Timestamp: 1035}},
+		{timestamp: 1070, want: domain.OffsetLookupResult{Found: true, Offset: 14, Timestamp: 1070}},
+		{timestamp: 1096, want: domain.OffsetLookupResult{}},
+	}
+	for _, lookup := range lookups {
+		got, err := repo.FindOffsetByTimestamp("foo", 0, lookup.timestamp)
+		if err != nil || got != lookup.want {
+			t.Errorf("FindOffsetByTimestamp(%d) = %+v, %v, want %+v", lookup.timestamp, got, err, lookup.want)
+		}
+	}
+
+	maxTimestamp, err := repo.FindOffsetOfMaxTimestamp("foo", 0)
+	want := domain.OffsetLookupResult{Found: true, Offset: 19, Timestamp: 1095}
+	if err != nil || maxTimestamp != want {
+		t.Errorf("FindOffsetOfMaxTimestamp() = %+v, %v, want %+v", maxTimestamp, err, want)
+	}
+}
+
+func TestPartitionLog_RollsSegments(t *testing.T) {
+	logDir := t.TempDir()
+	segmentedTestLog(t, logDir)
+
+	for _, baseOffset := range []int64{0, 6, 12, 18} {
+		for _, suffix := range []string{logFileSuffix, indexFileSuffix, timeIndexFileSuffix} {
+			if _, err := os.Stat(filepath.Join(logDir, "foo-0", segmentFileName(baseOffset, suffix))); err != nil {
+				t.Errorf("segment file missing: %v", err)
+			}
+		}
+	}
+
+	// A fresh repository discovers the segments from disk
+	checkSegmentedTestLog(t, NewPartitionFileRepositoryWithLogDir(logDir, WithIndexIntervalBytes(1)))
+}
+
+func TestPartitionLog_RebuildsIndexes(t *testing.T) {
+	logDir := t.TempDir()
+	segmentedTestLog(t, logDir)
+	partitionDir := filepath.Join(logDir, "foo-0")
+
+	originalIndex, _ := os.ReadFile(filepath.Join(partitionDir, segmentFileName(6, indexFileSuffix)))
+	originalTimeIndex, _ := os.ReadFile(filepath.Join(partitionDir, segmentFileName(12, timeIndexFileSuffix)))
+
+	// Missing offset index, a time index with a partial entry and an index pointing past the log
+	os.Remove(filepath.Join(partitionDir, segmentFileName(6, indexFileSuffix)))
+	os.WriteFile(filepath.Join(partitionDir, segmentFileName(12, timeIndexFileSuffix)), []byte{1, 2, 3}, 0o644)
+	os.WriteFile(filepath.Join(partitionDir, segmentFileName(0, indexFileSuffix)), offsetIndexEntry{relativeOffset: 1, position: 1 << 20}.appendTo(nil), 0o644)
+
+	checkSegmentedTestLog(t, NewPartitionFileRepositoryWithLogDir(logDir, WithIndexIntervalBytes(1)))
+
+	rebuiltIndex, _ := os.ReadFile(filepath.Join(partitionDir, segmentFileName(6, indexFileSuffix)))
+	if !bytes.Equal(rebuiltIndex, originalIndex) {
+		t.Errorf("rebuilt .index = %x, want %x", rebuiltIndex, originalIndex)
+	}
+	rebuiltTimeIndex, _ := os.ReadFile(filepath.Join(partitionDir, segmentFileName(12, timeIndexFileSuffix)))
+	// The rolled segment also carried the final entry written when it was closed
+	if !bytes.HasPrefix(originalTimeIndex, rebuiltTimeIndex) || len(rebuiltTimeIndex) == 0 {
+		t.Errorf("rebuilt .timeindex = %x, want a prefix of %x", rebuiltTimeIndex, originalTimeIndex)
+	}
+}
+
+func TestPartitionLog_TruncatesTornTail(t *testing.T) {
+	logDir := t.TempDir()
+	segmentedTestLog(t, logDir)
+	activeLog := filepath.Join(logDir, "foo-0", segmentFileName(18, logFileSuffix))
+
+	file, _ := os.OpenFile(activeLog, os.O_WRONLY|os.O_APPEND, 0o644)
+	file.Write(timestampedBatch(2000, 0)[:30])
+	file.Close()
+
+	repo := NewPartitionFileRepositoryWithLogDir(logDir, WithIndexIntervalBytes(1))
+	checkSegmentedTestLog(t, repo)
+
+	result, err := repo.AppendRecordBatches(domain.AppendRequest{TopicName: "foo", PartitionIndex: 0, Records: timestampedBatch(2000, 0)})
+	if err != nil || result.BaseOffset != 20 {
+		t.Fatalf("AppendRecordBatches() = %+v, %v, want base offset 20", result, err)
+	}
+	read, err := repo.ReadRecordBatches(domain.ReadRequest{TopicName: "foo", PartitionIndex: 0, FetchOffset: 20, MaxBytes: 1 << 20})
+	if err != nil || len(read.Records) != len(timestampedBatch(2000, 0)) {
+		t.Errorf("ReadRecordBatches(20) = %d bytes, %v, want the appended batch", len(read.Records), err)
+	}
+}
@@ -0,0 +1,129 @@
+package partition_file_repository
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// offsetIndexEntrySize is the size of an .index entry: relative offset (INT32) and position (INT32)
+const offsetIndexEntrySize = 8
+
+var errCorruptIndex = errors.New("corrupt index")
+
+type offsetIndexEntry struct {
+	relativeOffset int32 // Last offset of the indexed batch, relative to the segment base offset
+	position       int32 // Byte position of the batch in the segment's .log file
+}
+
+func (e offsetIndexEntry) appendTo(data []byte) []byte {
+	data = binary.BigEndian.AppendUint32(data, uint32(e.relativeOffset))
+	return binary.BigEndian.AppendUint32(data, uint32(e.position))
+}
+
+// offsetIndex is the sparse offset to position index of a segment, kept in memory and
+// mirrored to a Kafka-compatible .index file
+type offsetIndex struct {
+	path       string
+	baseOffset int64
+	entries    []offsetIndexEntry
+}
+
+// loadOffsetIndex reads an .index file, failing with errCorruptIndex when the file is
+// missing or its entries don't describe a log of logSize bytes
+func loadOffsetIndex(path string, baseOffset int64, logSize int64) (*offsetIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s is missing", errCorruptIndex, path)
+		}
+		return nil, err
+	}
+	if len(data)%offsetIndexEntrySize != 0 {
+		return nil, fmt.Errorf("%w: %s has a partial entry", errCorruptIndex, path)
+	}
+
+	index := &offsetIndex{path: path, baseOffset: baseOffset}
+	for position := 0; position < len(data); position += offsetIndexEntrySize {
+		entry := offsetIndexEntry{
+			relativeOffset: int32(binary.BigEndian.Uint32(data[position:])),
+			position:       int32(binary.BigEndian.Uint32(data[position+4:])),
+		}
+		// Kafka preallocates index files; the zeroed tail is not part of the index
+		if entry == (offsetIndexEntry{}) {
+			if allZero(data[position:]) {
+				break
+			}
+			return nil, fmt.Errorf("%w: %s has an empty entry", errCorruptIndex, path)
+		}
+		if entry.relativeOffset < 0 || entry.position < 0 || int64(entry.position) >= logSize {
+			return nil, fmt.Errorf("%w: %s points outside the segment", errCorruptIndex, path)
+		}
+		if last, ok := index.last(); ok && (entry.relativeOffset <= last.relativeOffset || entry.position <= last.position) {
+			return nil, fmt.Errorf("%w: %s entries are out of order", errCorruptIndex, path)
+		}
+		index.entries = append(index.entries, entry)
+	}
+	return index, nil
+}
+
+func (i *offsetIndex) last() (offsetIndexEntry, bool) {
+	if len(i.entries) == 0 {
+		return offsetIndexEntry{}, false
+	}
+	return i.entries[len(i.entries)-1], true
+}
+
+// lookup returns the position to start scanning from for offset: the position of the
+// last indexed batch ending at or before it, or the start of the segment
+func (i *offsetIndex) lookup(offset int64) int64 {
+	relativeOffset := offset - i.baseOffset
+	n := sort.Search(len(i.entries), func(j int) bool {
+		return int64(i.entries[j].relativeOffset) > relativeOffset
+	})
+	if n == 0 {
+		return 0
+	}
+	return int64(i.entries[n-1].position)
+}
+
+// append indexes the batch ending at lastOffset that starts at position
+func (i *offsetIndex) append(lastOffset int64, position int64) error {
+	entry := offsetIndexEntry{relativeOffset: int32(lastOffset - i.baseOffset), position: int32(position)}
+	if err := appendToFile(i.path, entry.appendTo(nil)); err != nil {
+		return err
+	}
+	i.entries = append(i.entries, entry)
+	return nil
+}
+
+// write replaces the .index file with the in-memory entries
+func (i *offsetIndex) write() error {
+	data := make([]byte, 0, len(i.entries)*offsetIndexEntrySize)
+	for _, entry := range i.entries {
+		data = entry.appendTo(data)
+	}
+	return os.WriteFile(i.path, data, 0o644)
+}
+
+func appendToFile(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(data)
+	return err
+}
+
+func allZero(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,158 @@
+package partition_file_repository
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// deleteDirSuffix marks a partition directory renamed for deletion, as
+// <topic>-<partition>.<uuid>-delete
+const deleteDirSuffix = "-delete"
+
+// DefaultFileDeleteDelay matches Kafka's file.delete.delay.ms
+const DefaultFileDeleteDelay = 60 * time.Second
+
+type pendingDelete struct {
+	dir string
+	due time.Time
+}
+
+// partitionDeleter removes the directories of deleted partitions in the background, each
+// once the file delete delay has passed since it was renamed
+type partitionDeleter struct {
+	mu      sync.Mutex
+	pending []pendingDelete // In due order, every deletion waits the same delay
+
+	wake     chan struct{}
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+func newPartitionDeleter() *partitionDeleter {
+	return &partitionDeleter{
+		wake: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+	}
+}
+
+func (d *partitionDeleter) schedule(dir string, due time.Time) {
+	d.mu.Lock()
+	d.pending = append(d.pending, pendingDelete{dir: dir, due: due})
+	d.mu.Unlock()
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run removes pending directories as they fall due until close is called
+func (d *partitionDeleter) run() {
+	for {
+		d.mu.Lock()
+		next, hasNext := pendingDelete{}, len(d.pending) > 0
+		if hasNext {
+			next = d.pending[0]
+		}
+		d.mu.Unlock()
+
+		var timer *time.Timer
+		var due <-chan time.Time
+		if hasNext {
+			wait := time.Until(next.due)
+			if wait <= 0 {
+				if err := os.RemoveAll(next.dir); err != nil {
+					fmt.Printf("Deleting partition directory %s failed: %v\n", next.dir, err)
+				}
+				d.mu.Lock()
+				d.pending = d.pending[1:]
+				d.mu.Unlock()
+				continue
+			}
+			timer = time.NewTimer(wait)
+			due = timer.C
+		}
+
+		select {
+		case <-d.stop:
+			return
+		case <-d.wake:
+		case <-due:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+func (d *partitionDeleter) close() {
+	d.stopOnce.Do(func() { close(d.stop) })
+}
+
+// Start runs the background deleter, first removing the directories a previous run
+// renamed for deletion but did not get to
+func (r *PartitionFileRepository) Start() error {
+	entries, err := os.ReadDir(r.logDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasSuffix(entry.Name(), deleteDirSuffix) {
+			r.deleter.schedule(filepath.Join(r.logDir, entry.Name()), time.Now())
+		}
+	}
+	go r.deleter.run()
+	return nil
+}
+
+// Close stops the background deleter and snapshots the producer state of the open logs
+func (r *PartitionFileRepository) Close() {
+	r.deleter.close()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for dirName, log := range r.logs {
+		if err := log.close(); err != nil {
+			fmt.Printf("Snapshotting the producer state of %s failed: %v\n", dirName, err)
+		}
+	}
+}
+
+// DeletePartition renames the partition directory to <topic>-<partition>.<uuid>-delete,
+// so a topic created with the same name starts with an empty log, and schedules the
+// renamed directory for removal
+func (r *PartitionFileRepository) DeletePartition(topicName string, partitionIndex int) error {
+	dirName := partitionDirName(topicName, partitionIndex)
+	r.mu.Lock()
+	log, exists := r.logs[dirName]
+	delete(r.logs, dirName)
+	r.mu.Unlock()
+
+	// Wait for appends and reads in progress on the partition to finish; the ones still
+	// waiting for the log fail once they get it
+	if exists {
+		log.mu.Lock()
+		defer log.mu.Unlock()
+		log.deleted = true
+	}
+
+	dir := filepath.Join(r.logDir, dirName)
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	uniqueID := make([]byte, 16)
+	rand.Read(uniqueID)
+	deleteDir := filepath.Join(r.logDir, fmt.Sprintf("%s.%s%s", dirName, hex.EncodeToString(uniqueID), deleteDirSuffix))
+	if err := os.Rename(dir, deleteDir); err != nil {
+		return err
+	}
+	r.deleter.schedule(deleteDir, time.Now().Add(r.fileDeleteDelay))
+	return nil
+}
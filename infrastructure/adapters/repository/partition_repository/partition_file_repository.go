@@ -1,166 +1,168 @@
 package partition_file_repository
 
 import (
+	"encoding/base64"
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
-	"strconv"
+	"sync"
+	"time"
 
 	"github.com/codecrafters-io/kafka-starter-go/core/domain"
 	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
 )
 import port_repo "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/partition_file_repository"
 
+const (
+	// DefaultLogDir is where the broker keeps its partition directories
+	DefaultLogDir = "/tmp/kraft-combined-logs"
+	// DefaultSegmentBytes matches Kafka's log.segment.bytes
+	DefaultSegmentBytes int64 = 1 << 30
+	// DefaultIndexIntervalBytes matches Kafka's log.index.interval.bytes
+	DefaultIndexIntervalBytes int64 = 4096
+)
+
 type PartitionFileRepository struct {
+	logDir          string
+	config          logConfig
+	appendListeners []port_repo.AppendListener
+	mu              sync.Mutex
+	logs            map[string]*partitionLog
+	fileDeleteDelay time.Duration
+	deleter         *partitionDeleter
 }
 
-func NewPartitionFileRepository() port_repo.PartitionFileRepository {
-	return &PartitionFileRepository{}
+// PartitionFileRepositoryOption configures a PartitionFileRepository
+type PartitionFileRepositoryOption func(*PartitionFileRepository)
+
+// WithSegmentBytes sets the size at which a partition's active segment is rolled
+func WithSegmentBytes(segmentBytes int64) PartitionFileRepositoryOption {
+	return func(r *PartitionFileRepository) {
+		r.config.segmentBytes = segmentBytes
+	}
 }
 
-func (PartitionFileRepository) GetPartitionMessage(messageFetchRequest domain.MessageFetchRequest) {
-	for _, partitionToFetch := range messageFetchRequest.PartitionsToFetch {
-		openLogFile(partitionToFetch)
+// WithIndexIntervalBytes sets how many bytes are appended between two index entries
+func WithIndexIntervalBytes(indexIntervalBytes int64) PartitionFileRepositoryOption {
+	return func(r *PartitionFileRepository) {
+		r.config.indexIntervalBytes = indexIntervalBytes
 	}
 }
 
-func openLogFile(partitionToFetch domain.PartitionToFetch) {
+// WithFileDeleteDelay sets how long the directory of a deleted partition is kept before
+// it is removed
+func WithFileDeleteDelay(delay time.Duration) PartitionFileRepositoryOption {
+	return func(r *PartitionFileRepository) {
+		r.fileDeleteDelay = delay
+	}
+}
 
-	fileToFetch := fmt.Sprintf("/tmp/kraft-combined-logs/%s-%s/00000000000000000000.log", partitionToFetch.TopicName, strconv.Itoa(partitionToFetch.PartitionIndex))
+// WithAppendListener registers a listener told about every successful append
+func WithAppendListener(listener port_repo.AppendListener) PartitionFileRepositoryOption {
+	return func(r *PartitionFileRepository) {
+		r.appendListeners = append(r.appendListeners, listener)
+	}
+}
 
-	root := "/tmp/kraft-combined-logs/"
+func NewPartitionFileRepository(opts ...PartitionFileRepositoryOption) *PartitionFileRepository {
+	return NewPartitionFileRepositoryWithLogDir(DefaultLogDir, opts...)
+}
 
-	// WalkDir is the most efficient way to recursively traverse directories in Go.
-	// It visits every file and folder starting from the root path.
-	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
-		// If there is an error accessing a path (e.g., permissions), we report it
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error accessing %s: %v\n", path, err)
-			return nil // Return nil to continue walking other directories
-		}
+// NewPartitionFileRepositoryWithLogDir creates a repository rooted at a custom log directory
+func NewPartitionFileRepositoryWithLogDir(logDir string, opts ...PartitionFileRepositoryOption) *PartitionFileRepository {
+	r := &PartitionFileRepository{
+		logDir:          logDir,
+		config:          logConfig{segmentBytes: DefaultSegmentBytes, indexIntervalBytes: DefaultIndexIntervalBytes},
+		logs:            make(map[string]*partitionLog),
+		fileDeleteDelay: DefaultFileDeleteDelay,
+		deleter:         newPartitionDeleter(),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
 
-		// We only want to print files, not the directory names themselves
-		fmt.Println(path)
+// CreatePartition creates the partition directory and its partition.metadata file, which
+// ties the directory to the topic ID the way Kafka does
+func (r *PartitionFileRepository) CreatePartition(topicName string, partitionIndex int, topicID []byte) error {
+	dir := filepath.Join(r.logDir, partitionDirName(topicName, partitionIndex))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	metadata := fmt.Sprintf("version: 0\ntopic_id: %s\n", base64.RawURLEncoding.EncodeToString(topicID))
+	return os.WriteFile(filepath.Join(dir, "partition.metadata"), []byte(metadata), 0o644)
+}
 
-		return nil
-	})
-	data, err := os.ReadFile(fileToFetch)
+// AppendRecordBatches appends the RecordBatches of a Produce request to the partition log,
+// creating the partition directory on first write
+func (r *PartitionFileRepository) AppendRecordBatches(appendRequest domain.AppendRequest) (domain.AppendResult, error) {
+	result, err := r.getPartitionLog(appendRequest.TopicName, appendRequest.PartitionIndex).append(appendRequest.LeaderEpoch, appendRequest.Records)
 	if err != nil {
-		fmt.Printf("Failed to get file: %v\n", err)
-		panic("file partition error")
+		return result, err
 	}
-	fmt.Println("Length of above file ", len(data))
-
-	//fullyProcessed := cluster_metadata_repository.ProcessRecordBatchesPublic(data)
-
-	currentOffset := 0
-
-	baseOffset := common.BytesToInt(data[currentOffset : currentOffset+8])
-	currentOffset += 8
-	_ = baseOffset
-
-	batchLength := common.BytesToInt(data[currentOffset : currentOffset+4]) //batchLength will be the total bytes of a RecordBatch without the Base Offset and Batch Length
-	currentOffset += 4
-
-	// The Records field should contain the full RecordBatch (starting from baseOffset)
-	// batchLength is the size of the RecordBatch excluding Base Offset and Batch Length
-	// So the full RecordBatch is: Base Offset (8) + Batch Length (4) + batchLength bytes
-	recordBatchStart := 0                  // Start from baseOffset
-	recordBatchSize := 8 + 4 + batchLength // Base Offset + Batch Length + batchLength bytes
-
-	// Read the recordsLength (number of records) for encoding
-	recordsLengthOffset := 8 + 4 + 4 + 1 + 4 + 2 + 4 + 8 + 8 + 8 + 2 + 4 // Skip to recordsLength field
-	if recordsLengthOffset+4 <= len(data) {
-		recordsLength := common.BytesToInt(data[recordsLengthOffset : recordsLengthOffset+4])
-		partitionToFetch.TopicFetchResponse.RecordsLength = recordsLength
-	} else {
-		partitionToFetch.TopicFetchResponse.RecordsLength = 0
+	for _, listener := range r.appendListeners {
+		listener.OnAppend(appendRequest.TopicName, appendRequest.PartitionIndex)
 	}
+	return result, nil
+}
 
-	fmt.Println(">>>>>>>> showing record stuff", len(data), "batchLength:", batchLength, "recordBatchSize:", recordBatchSize, "recordsLength:", partitionToFetch.TopicFetchResponse.RecordsLength)
-
-	// Extract the full RecordBatch first
-	var extractedRecords []byte
-	if recordBatchStart+recordBatchSize > len(data) {
-		fmt.Printf("Warning: recordBatchSize (%d) exceeds data length (%d)\n", recordBatchSize, len(data))
-		extractedRecords = data[recordBatchStart:]
-	} else {
-		extractedRecords = data[recordBatchStart : recordBatchStart+recordBatchSize]
+// WriteTxnMarker appends the control batch ending the producer's transaction, rejecting
+// a producer epoch older than the partition has seen
+func (r *PartitionFileRepository) WriteTxnMarker(marker domain.TxnMarkerAppend) (domain.AppendResult, error) {
+	controlRecord := common.ControlRecord{Type: common.ControlRecordTypeAbort, CoordinatorEpoch: marker.CoordinatorEpoch}
+	if marker.Committed {
+		controlRecord.Type = common.ControlRecordTypeCommit
 	}
+	return r.AppendRecordBatches(domain.AppendRequest{
+		TopicName:      marker.TopicName,
+		PartitionIndex: marker.PartitionIndex,
+		LeaderEpoch:    marker.LeaderEpoch,
+		Records:        common.EncodeControlBatch(marker.ProducerID, marker.ProducerEpoch, time.Now().UnixMilli(), controlRecord),
+	})
+}
 
-	// Parse the RecordBatch to find where the first record ends
-	// RecordBatch structure:
-	// - Base Offset (8 bytes)
-	// - Batch Length (4 bytes)
-	// - Partition Leader Epoch (4 bytes)
-	// - Magic Byte (1 byte)
-	// - CRC (4 bytes)
-	// - Attributes (2 bytes)
-	// - Last Offset Delta (4 bytes)
-	// - Base Timestamp (8 bytes)
-	// - Max Timestamp (8 bytes)
-	// - Producer ID (8 bytes)
-	// - Producer Epoch (2 bytes)
-	// - Base Sequence (4 bytes)
-	// - Records Length (4 bytes) - number of records
-	// - Records (variable)
-
-	offset := 0
-	if len(extractedRecords) < 8+4 {
-		partitionToFetch.TopicFetchResponse.Records = extractedRecords
-		return
-	}
+// ReadRecordBatches returns the whole RecordBatches from the one containing the fetch offset
+// that fit in the byte budget, up to the last stable offset when reading committed data
+func (r *PartitionFileRepository) ReadRecordBatches(readRequest domain.ReadRequest) (domain.ReadResult, error) {
+	return r.getPartitionLog(readRequest.TopicName, readRequest.PartitionIndex).read(readRequest)
+}
 
-	offset = 8 + 4 // Skip Base Offset and Batch Length
+// GetLogOffsets returns the log start offset, last stable offset and high watermark of a
+// partition
+func (r *PartitionFileRepository) GetLogOffsets(topicName string, partitionIndex int) (domain.LogOffsets, error) {
+	return r.getPartitionLog(topicName, partitionIndex).offsets()
+}
 
-	// Skip RecordBatch header fields to get to Records Length
-	headerSize := 4 + 1 + 4 + 2 + 4 + 8 + 8 + 8 + 2 + 4 // All header fields before Records Length
-	if offset+headerSize+4 > len(extractedRecords) {
-		partitionToFetch.TopicFetchResponse.Records = extractedRecords
-		return
-	}
+// FindOffsetByTimestamp returns the first record whose timestamp is at least timestamp
+func (r *PartitionFileRepository) FindOffsetByTimestamp(topicName string, partitionIndex int, timestamp int64) (domain.OffsetLookupResult, error) {
+	return r.getPartitionLog(topicName, partitionIndex).findOffsetByTimestamp(timestamp)
+}
 
-	offset += headerSize // Now at Records Length field
+// FindOffsetOfMaxTimestamp returns the record with the largest timestamp in the partition
+func (r *PartitionFileRepository) FindOffsetOfMaxTimestamp(topicName string, partitionIndex int) (domain.OffsetLookupResult, error) {
+	return r.getPartitionLog(topicName, partitionIndex).findOffsetOfMaxTimestamp()
+}
 
-	// Read Records Length (number of records)
-	recordsCount := common.BytesToInt(extractedRecords[offset : offset+4])
-	offset += 4
+// DescribeProducers returns the state of the producers that wrote to the partition,
+// ordered by producer ID
+func (r *PartitionFileRepository) DescribeProducers(topicName string, partitionIndex int) ([]domain.ProducerState, error) {
+	return r.getPartitionLog(topicName, partitionIndex).describeProducers()
+}
 
-	if recordsCount <= 0 {
-		partitionToFetch.TopicFetchResponse.Records = extractedRecords
-		return
-	}
+func (r *PartitionFileRepository) getPartitionLog(topicName string, partitionIndex int) *partitionLog {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	// Read the first record's Length (varint) - this tells us the total byte length of the record
-	recordLength, bytesRead := common.ReadVarIntSigned(offset, extractedRecords)
-	if bytesRead == 0 {
-		partitionToFetch.TopicFetchResponse.Records = extractedRecords
-		return
+	dirName := partitionDirName(topicName, partitionIndex)
+	log, exists := r.logs[dirName]
+	if !exists {
+		log = newPartitionLog(filepath.Join(r.logDir, dirName), r.config)
+		r.logs[dirName] = log
 	}
+	return log
+}
 
-	fmt.Printf(">>>>>>>> First record length (varint): %d, bytes read: %d\n", recordLength, bytesRead)
-
-	// The record starts at 'offset', and its length is 'recordLength' bytes
-	// So the record ends at offset + bytesRead + recordLength
-	// But we need to include the record length varint itself, so:
-	// Record data starts at offset + bytesRead
-	// Record data ends at offset + bytesRead + recordLength
-	// Total record size including length varint: bytesRead + recordLength
-
-	firstRecordEndOffset := offset + bytesRead + recordLength
-
-	fmt.Printf(">>>>>>>> First record starts at offset %d, ends at offset %d (total %d bytes including length varint)\n",
-		offset, firstRecordEndOffset, firstRecordEndOffset-offset)
-
-	// Truncate the RecordBatch to only include up to the end of the first record
-	// We need to keep the full RecordBatch header + Records Length + first record
-	if firstRecordEndOffset > len(extractedRecords) {
-		fmt.Printf("Warning: First record extends beyond extracted data, using full RecordBatch\n")
-		partitionToFetch.TopicFetchResponse.Records = extractedRecords
-	} else {
-		partitionToFetch.TopicFetchResponse.Records = extractedRecords[:firstRecordEndOffset]
-		fmt.Printf(">>>>>>>> Truncated RecordBatch from %d bytes to %d bytes (first record only)\n",
-			len(extractedRecords), len(partitionToFetch.TopicFetchResponse.Records))
-	}
+func partitionDirName(topicName string, partitionIndex int) string {
+	return fmt.Sprintf("%s-%d", topicName, partitionIndex)
 }
@@ -0,0 +1,354 @@
+package partition_file_repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+func testRecordBatch(values ...string) []byte {
+	records := []common.Record{}
+	for i, value := range values {
+		records = append(records, common.Record{OffsetDelta: int32(i), Value: []byte(value)})
+	}
+	return common.EncodeRecordBatch(common.RecordBatch{
+		RecordBatchHeader: common.RecordBatchHeader{
+			LastOffsetDelta:      int32(len(values) - 1),
+			PartitionLeaderEpoch: -1,
+			ProducerID:           -1,
+			ProducerEpoch:        -1,
+			BaseSequence:         -1,
+		},
+		Records: records,
+	})
+}
+
+func TestPartitionFileRepository_AppendRecordBatches(t *testing.T) {
+	logDir := t.TempDir()
+	repo := NewPartitionFileRepositoryWithLogDir(logDir)
+
+	appends := []struct {
+		records        []byte
+		wantBaseOffset int64
+	}{
+		{records: testRecordBatch("a", "b", "c"), wantBaseOffset: 0},
+		{records: testRecordBatch("d"), wantBaseOffset: 3},
+		{records: append(testRecordBatch("e", "f"), testRecordBatch("g")...), wantBaseOffset: 4},
+	}
+
+	for _, a := range appends {
+		result, err := repo.AppendRecordBatches(domain.AppendRequest{TopicName: "foo", PartitionIndex: 0, LeaderEpoch: 3, Records: a.records})
+		if err != nil {
+			t.Fatalf("AppendRecordBatches() error = %v", err)
+		}
+		if result.BaseOffset != a.wantBaseOffset {
+			t.Errorf("BaseOffset = %d, want %d", result.BaseOffset, a.wantBaseOffset)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(logDir, "foo-0", segmentFileName(0, logFileSuffix)))
+	if err != nil {
+		t.Fatalf("reading segment: %v", err)
+	}
+	batches, err := common.SplitRecordBatches(data)
+	if err != nil {
+		t.Fatalf("segment is not a valid record set: %v", err)
+	}
+	wantBaseOffsets := []int64{0, 3, 4, 6}
+	if len(batches) != len(wantBaseOffsets) {
+		t.Fatalf("segment has %d batches, want %d", len(batches), len(wantBaseOffsets))
+	}
+	for i, batch := range batches {
+		header, _ := common.ReadRecordBatchHeader(batch)
+		if header.BaseOffset != wantBaseOffsets[i] || header.PartitionLeaderEpoch != 3 {
+			t.Errorf("batch %d has base offset %d and leader epoch %d, want %d and 3", i, header.BaseOffset, header.PartitionLeaderEpoch, wantBaseOffsets[i])
+		}
+	}
+
+	// A fresh repository recovers the log end offset from disk
+	reopened := NewPartitionFileRepositoryWithLogDir(logDir)
+	result, err := reopened.AppendRecordBatches(domain.AppendRequest{TopicName: "foo", PartitionIndex: 0, Records: testRecordBatch("h")})
+	if err != nil {
+		t.Fatalf("AppendRecordBatches() after reopen error = %v", err)
+	}
+	if result.BaseOffset != 7 {
+		t.Errorf("BaseOffset after reopen = %d, want 7", result.BaseOffset)
+	}
+}
+
+func TestPartitionFileRepository_AppendRecordBatches_Corrupt(t *testing.T) {
+	corruptCrc := testRecordBatch("a")
+	corruptCrc[len(corruptCrc)-1] ^= 0xff
+
+	tests := []struct {
+		name    string
+		records []byte
+	}{
+		{name: "bad crc", records: corruptCrc},
+		{name: "truncated batch", records: testRecordBatch("a")[:40]},
+		{name: "empty record set", records: []byte{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewPartitionFileRepositoryWithLogDir(t.TempDir())
+			_, err := repo.AppendRecordBatches(domain.AppendRequest{TopicName: "foo", PartitionIndex: 0, Records: tt.records})
+			if !errors.Is(err, domain.ErrCorruptMessage) {
+				t.Errorf("AppendRecordBatches() error = %v, want ErrCorruptMessage", err)
+			}
+		})
+	}
+}
+
+func timestampedBatch(baseTimestamp int64, timestampDeltas ...int64) []byte {
+	records := []common.Record{}
+	maxTimestamp := baseTimestamp
+	for i, delta := range timestampDeltas {
+		records = append(records, common.Record{OffsetDelta: int32(i), TimestampDelta: delta, Value: []byte("v")})
+		if baseTimestamp+delta > maxTimestamp {
+			maxTimestamp = baseTimestamp + delta
+		}
+	}
+	return common.EncodeRecordBatch(common.RecordBatch{
+		RecordBatchHeader: common.RecordBatchHeader{
+			LastOffsetDelta: int32(len(records) - 1),
+			BaseTimestamp:   baseTimestamp,
+			MaxTimestamp:    maxTimestamp,
+			ProducerID:      -1,
+			ProducerEpoch:   -1,
+			BaseSequence:    -1,
+		},
+		Records: records,
+	})
+}
+
+func TestPartitionFileRepository_OffsetLookups(t *testing.T) {
+	repo := NewPartitionFileRepositoryWithLogDir(t.TempDir())
+
+	// Offsets 0-2 at 1000, 1010, 1020 (epoch 1); offsets 3-4 at 1500, 1005 (epoch 2); offset 5 at 1200 (epoch 2)
+	appends := []struct {
+		epoch int32
+		batch []byte
+	}{
+		{1, timestampedBatch(1000, 0, 10, 20)},
+		{2, timestampedBatch(1500, 0, -495)},
+		{2, timestampedBatch(1200, 0)},
+	}
+	for _, a := range appends {
+		if _, err := repo.AppendRecordBatches(domain.AppendRequest{TopicName: "foo", PartitionIndex: 0, LeaderEpoch: a.epoch, Records: a.batch}); err != nil {
+			t.Fatalf("AppendRecordBatches() error = %v", err)
+		}
+	}
+
+	offsets, err := repo.GetLogOffsets("foo", 0)
+	if err != nil || offsets.LogStartOffset != 0 || offsets.HighWatermark != 6 {
+		t.Errorf("GetLogOffsets() = %+v, %v, want start 0 and high watermark 6", offsets, err)
+	}
+
+	tests := []struct {
+		name      string
+		timestamp int64
+		want      domain.OffsetLookupResult
+	}{
+		{name: "before first record", timestamp: 0, want: domain.OffsetLookupResult{Found: true, Offset: 0, Timestamp: 1000, LeaderEpoch: 1}},
+		{name: "inside first batch", timestamp: 1011, want: domain.OffsetLookupResult{Found: true, Offset: 2, Timestamp: 1020, LeaderEpoch: 1}},
+		{name: "second batch", timestamp: 1100, want: domain.OffsetLookupResult{Found: true, Offset: 3, Timestamp: 1500, LeaderEpoch: 2}},
+		{name: "after last record", timestamp: 2000, want: domain.OffsetLookupResult{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.FindOffsetByTimestamp("foo", 0, tt.timestamp)
+			if err != nil {
+				t.Fatalf("FindOffsetByTimestamp() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FindOffsetByTimestamp(%d) = %+v, want %+v", tt.timestamp, got, tt.want)
+			}
+		})
+	}
+
+	got, err := repo.FindOffsetOfMaxTimestamp("foo", 0)
+	want := domain.OffsetLookupResult{Found: true, Offset: 3, Timestamp: 1500, LeaderEpoch: 2}
+	if err != nil || got != want {
+		t.Errorf("FindOffsetOfMaxTimestamp() = %+v, %v, want %+v", got, err, want)
+	}
+
+	empty, err := repo.FindOffsetOfMaxTimestamp("bar", 0)
+	if err != nil || empty.Found {
+		t.Errorf("FindOffsetOfMaxTimestamp() on an empty log = %+v, %v, want not found", empty, err)
+	}
+}
+
+func TestPartitionFileRepository_ReadRecordBatches(t *testing.T) {
+	repo := NewPartitionFileRepositoryWithLogDir(t.TempDir())
+
+	// Batches at offsets 0-2, 3 and 4-5
+	batches := [][]byte{testRecordBatch("a", "b", "c"), testRecordBatch("d"), testRecordBatch("e", "f")}
+	for _, batch := range batches {
+		if _, err := repo.AppendRecordBatches(domain.AppendRequest{TopicName: "foo", PartitionIndex: 0, LeaderEpoch: 0, Records: batch}); err != nil {
+			t.Fatalf("AppendRecordBatches() error = %v", err)
+		}
+	}
+	sizes := []int{len(batches[0]), len(batches[1]), len(batches[2])}
+
+	tests := []struct {
+		name            string
+		fetchOffset     int64
+		maxBytes        int
+		minOneBatch     bool
+		wantBaseOffsets []int64
+		wantErr         error
+	}{
+		{name: "whole log", fetchOffset: 0, maxBytes: 1 << 20, wantBaseOffsets: []int64{0, 3, 4}},
+		{name: "offset inside a batch returns that batch", fetchOffset: 1, maxBytes: 1 << 20, wantBaseOffsets: []int64{0, 3, 4}},
+		{name: "starts at later batch", fetchOffset: 4, maxBytes: 1 << 20, wantBaseOffsets: []int64{4}},
+		{name: "byte limit keeps whole batches", fetchOffset: 0, maxBytes: sizes[0] + sizes[1] + 1, wantBaseOffsets: []int64{0, 3}},
+		{name: "oversized first batch with minOneBatch", fetchOffset: 0, maxBytes: 1, minOneBatch: true, wantBaseOffsets: []int64{0}},
+		{name: "oversized first batch without minOneBatch", fetchOffset: 0, maxBytes: 1, wantBaseOffsets: nil},
+		{name: "at log end", fetchOffset: 6, maxBytes: 1 << 20, wantBaseOffsets: nil},
+		{name: "past log end", fetchOffset: 7, maxBytes: 1 << 20, wantErr: domain.ErrOffsetOutOfRange},
+		{name: "negative offset", fetchOffset: -1, maxBytes: 1 << 20, wantErr: domain.ErrOffsetOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := repo.ReadRecordBatches(domain.ReadRequest{
+				TopicName:      "foo",
+				PartitionIndex: 0,
+				FetchOffset:    tt.fetchOffset,
+				MaxBytes:       int32(tt.maxBytes),
+				MinOneBatch:    tt.minOneBatch,
+			})
+			if result.LogStartOffset != 0 || result.HighWatermark != 6 {
+				t.Errorf("offsets = [%d, %d], want [0, 6]", result.LogStartOffset, result.HighWatermark)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("ReadRecordBatches() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadRecordBatches() error = %v", err)
+			}
+
+			read, err := common.SplitRecordBatches(result.Records)
+			if err != nil {
+				t.Fatalf("result is not a valid record set: %v", err)
+			}
+			var baseOffsets []int64
+			for _, batch := range read {
+				header, _ := common.ReadRecordBatchHeader(batch)
+				baseOffsets = append(baseOffsets, header.BaseOffset)
+			}
+			if !slices.Equal(baseOffsets, tt.wantBaseOffsets) {
+				t.Errorf("base offsets = %v, want %v", baseOffsets, tt.wantBaseOffsets)
+			}
+		})
+	}
+}
+
+func TestPartitionFileRepository_ReadRecordBatches_MissingPartition(t *testing.T) {
+	repo := NewPartitionFileRepositoryWithLogDir(t.TempDir())
+
+	result, err := repo.ReadRecordBatches(domain.ReadRequest{TopicName: "foo", PartitionIndex: 0, MaxBytes: 1024})
+	if err != nil || len(result.Records) != 0 || result.HighWatermark != 0 {
+		t.Errorf("ReadRecordBatches() = %+v, %v, want an empty log", result, err)
+	}
+}
+
+func TestPartitionFileRepository_DeletePartition(t *testing.T) {
+	logDir := t.TempDir()
+	leftover := filepath.Join(logDir, "old-0.0123456789abcdef0123456789abcdef-delete")
+	if err := os.Mkdir(leftover, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewPartitionFileRepositoryWithLogDir(logDir, WithFileDeleteDelay(20*time.Millisecond))
+	if err := repo.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer repo.Close()
+
+	if _, err := repo.AppendRecordBatches(domain.AppendRequest{TopicName: "foo", PartitionIndex: 0, Records: testRecordBatch("a")}); err != nil {
+		t.Fatalf("AppendRecordBatches() error = %v", err)
+	}
+	if err := repo.DeletePartition("foo", 0); err != nil {
+		t.Fatalf("DeletePartition() error = %v", err)
+	}
+	if err := repo.DeletePartition("never-created", 0); err != nil {
+		t.Errorf("DeletePartition() of a partition without a directory error = %v", err)
+	}
+
+	// The directory is renamed right away and a topic with the same name starts afresh
+	if _, err := os.Stat(filepath.Join(logDir, "foo-0")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("foo-0 still exists after DeletePartition(): %v", err)
+	}
+	renamed, _ := filepath.Glob(filepath.Join(logDir, "foo-0.*-delete"))
+	if len(renamed) != 1 {
+		t.Errorf("renamed directories = %v, want one foo-0.<uuid>-delete", renamed)
+	}
+	if offsets, err := repo.GetLogOffsets("foo", 0); err != nil || offsets.HighWatermark != 0 {
+		t.Errorf("GetLogOffsets() of the recreated partition = %+v, %v, want an empty log", offsets, err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		remaining, _ := filepath.Glob(filepath.Join(logDir, "*-delete"))
+		if len(remaining) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("directories %v were not removed in the background", remaining)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPartitionFileRepository_DeletePartition_ConcurrentAppend(t *testing.T) {
+	logDir := t.TempDir()
+	repo := NewPartitionFileRepositoryWithLogDir(logDir)
+
+	// Each round an append that already holds the log races the deletion for its lock
+	for partitionIndex := range 20 {
+		if _, err := repo.AppendRecordBatches(domain.AppendRequest{TopicName: "foo", PartitionIndex: partitionIndex, Records: testRecordBatch("a")}); err != nil {
+			t.Fatalf("AppendRecordBatches() error = %v", err)
+		}
+		log := repo.getPartitionLog("foo", partitionIndex)
+		log.mu.Lock()
+
+		appendErr := make(chan error, 1)
+		go func() {
+			_, err := log.append(0, testRecordBatch("b"))
+			appendErr <- err
+		}()
+		deleteErr := make(chan error, 1)
+		go func() { deleteErr <- repo.DeletePartition("foo", partitionIndex) }()
+		for {
+			repo.mu.Lock()
+			_, exists := repo.logs[partitionDirName("foo", partitionIndex)]
+			repo.mu.Unlock()
+			if !exists {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		log.mu.Unlock()
+
+		if err := <-deleteErr; err != nil {
+			t.Fatalf("DeletePartition() error = %v", err)
+		}
+		if err := <-appendErr; err != nil && !errors.Is(err, domain.ErrUnknownTopicOrPartition) {
+			t.Errorf("append racing the deletion error = %v, want none or UNKNOWN_TOPIC_OR_PARTITION", err)
+		}
+		if _, err := os.Stat(filepath.Join(logDir, partitionDirName("foo", partitionIndex))); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("partition directory of the deleted partition %d exists: %v", partitionIndex, err)
+		}
+	}
+}
@@ -0,0 +1,513 @@
+package partition_file_repository
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// logConfig holds the segment settings shared by every partition log
+type logConfig struct {
+	segmentBytes       int64 // Size at which the active segment is rolled
+	indexIntervalBytes int64 // Bytes appended between two index entries
+}
+
+// partitionLog is the on-disk log of a single topic partition, split into segments.
+// Appends are serialized by mu so concurrent producers get distinct offsets.
+type partitionLog struct {
+	mu             sync.RWMutex
+	dir            string
+	config         logConfig
+	loaded         bool
+	segments       []*logSegment // Ordered by base offset, the last one is active
+	logStartOffset int64
+	logEndOffset   int64 // Offset the next appended record will get
+	producerState  *producerStateManager
+	deleted        bool // Set once the partition was deleted, the log must not be used
+}
+
+func newPartitionLog(dir string, config logConfig) *partitionLog {
+	return &partitionLog{dir: dir, config: config, producerState: newProducerStateManager(dir)}
+}
+
+// load discovers the segments in the partition directory and recovers the log end offset
+func (l *partitionLog) load() error {
+	if l.loaded {
+		return nil
+	}
+
+	entries, err := os.ReadDir(l.dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	baseOffsets := []int64{}
+	for _, entry := range entries {
+		name, isLog := strings.CutSuffix(entry.Name(), logFileSuffix)
+		if !isLog || entry.IsDir() || len(name) != 20 {
+			continue
+		}
+		baseOffset, err := strconv.ParseInt(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		baseOffsets = append(baseOffsets, baseOffset)
+	}
+	sort.Slice(baseOffsets, func(i, j int) bool { return baseOffsets[i] < baseOffsets[j] })
+
+	l.segments = nil
+	for _, baseOffset := range baseOffsets {
+		segment, err := openLogSegment(l.dir, baseOffset, l.config.indexIntervalBytes)
+		if err != nil {
+			return fmt.Errorf("loading segment %d of %s: %w", baseOffset, l.dir, err)
+		}
+		l.segments = append(l.segments, segment)
+	}
+	if len(l.segments) > 0 {
+		l.logStartOffset = l.segments[0].baseOffset
+		l.logEndOffset = l.activeSegment().nextOffset
+	}
+	if err := l.loadProducerState(); err != nil {
+		return fmt.Errorf("loading producer state of %s: %w", l.dir, err)
+	}
+
+	l.loaded = true
+	return nil
+}
+
+// loadProducerState restores the producer state from the newest snapshot and replays the
+// batches appended after it, or the whole log when there is no snapshot. The transactions
+// aborted in the replayed batches are indexed again in case their entries were lost.
+func (l *partitionLog) loadProducerState() error {
+	snapshotOffset, err := l.producerState.loadSnapshot(l.logEndOffset)
+	if err != nil {
+		return err
+	}
+	from := max(snapshotOffset, l.logStartOffset)
+	var indexErr error
+	err = l.forEachBatchFrom(from, func(header common.RecordBatchHeader, batch []byte) bool {
+		if header.BaseOffset < from {
+			return true
+		}
+		aborted := l.abortedTxns([]common.RecordBatchHeader{header}, [][]byte{batch})
+		l.producerState.replay(header)
+		indexErr = l.indexAbortedTxns(aborted)
+		return indexErr == nil
+	})
+	return errors.Join(err, indexErr)
+}
+
+// ensureLoaded loads the log on first use so readers can work under the read lock
+func (l *partitionLog) ensureLoaded() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.errIfDeleted(); err != nil {
+		return err
+	}
+	return l.load()
+}
+
+// errIfDeleted fails the operations that got hold of the log before its partition was
+// deleted, so none of them recreates the partition directory
+func (l *partitionLog) errIfDeleted() error {
+	if l.deleted {
+		return fmt.Errorf("%w: %s was deleted", domain.ErrUnknownTopicOrPartition, filepath.Base(l.dir))
+	}
+	return nil
+}
+
+func (l *partitionLog) activeSegment() *logSegment {
+	if len(l.segments) == 0 {
+		return nil
+	}
+	return l.segments[len(l.segments)-1]
+}
+
+// segmentIndexFor returns the index in segments of the segment that holds offset
+func (l *partitionLog) segmentIndexFor(offset int64) int {
+	n := sort.Search(len(l.segments), func(i int) bool {
+		return l.segments[i].baseOffset > offset
+	})
+	return max(n-1, 0)
+}
+
+// roll closes the active segment and starts a new one at the log end offset, snapshotting
+// the producer state as of the new segment's base offset
+func (l *partitionLog) roll() error {
+	if active := l.activeSegment(); active != nil {
+		if err := active.close(); err != nil {
+			return err
+		}
+		if err := l.producerState.takeSnapshot(l.logEndOffset); err != nil {
+			return err
+		}
+	}
+	segment, err := createLogSegment(l.dir, l.logEndOffset, l.config.indexIntervalBytes)
+	if err != nil {
+		return err
+	}
+	l.segments = append(l.segments, segment)
+	return nil
+}
+
+// shouldRoll reports whether appending size bytes ending at lastOffset needs a new segment,
+// either because the active one would outgrow segmentBytes or because relative offsets
+// would no longer fit the index
+func (l *partitionLog) shouldRoll(size int, lastOffset int64) bool {
+	active := l.activeSegment()
+	if active == nil {
+		return true
+	}
+	if active.size == 0 {
+		return false
+	}
+	return active.size+int64(size) > l.config.segmentBytes || lastOffset-active.baseOffset > math.MaxInt32
+}
+
+// append assigns offsets to the batches in records and writes them to the end of the log.
+// Nothing is written when a batch of an idempotent producer is out of sequence, fenced or
+// a retry of an appended batch, which fails with the result of the first append.
+func (l *partitionLog) append(leaderEpoch int32, records []byte) (domain.AppendResult, error) {
+	batches, err := common.SplitRecordBatches(records)
+	if err != nil {
+		return domain.AppendResult{}, fmt.Errorf("%w: %v", domain.ErrCorruptMessage, err)
+	}
+	if len(batches) == 0 {
+		return domain.AppendResult{}, fmt.Errorf("%w: empty record set", domain.ErrCorruptMessage)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.errIfDeleted(); err != nil {
+		return domain.AppendResult{}, err
+	}
+	if err := l.load(); err != nil {
+		return domain.AppendResult{}, err
+	}
+
+	// Copy so the offsets are not written into the request buffer
+	toWrite := make([][]byte, 0, len(batches))
+	headers := make([]common.RecordBatchHeader, 0, len(batches))
+	baseOffset := l.logEndOffset
+	nextOffset := baseOffset
+	for _, batch := range batches {
+		header, _ := common.ReadRecordBatchHeader(batch)
+		header.BaseOffset = nextOffset
+		batch = append([]byte{}, batch...)
+		common.SetRecordBatchBaseOffset(batch, nextOffset)
+		common.SetRecordBatchPartitionLeaderEpoch(batch, leaderEpoch)
+		toWrite = append(toWrite, batch)
+		headers = append(headers, header)
+		nextOffset += int64(header.LastOffsetDelta) + 1
+	}
+	producers, duplicate, err := l.producerState.prepareAppend(headers)
+	if errors.Is(err, domain.ErrDuplicateSequenceNumber) {
+		return domain.AppendResult{BaseOffset: duplicate.firstOffset, LogAppendTimeMs: -1, LogStartOffset: l.logStartOffset}, err
+	}
+	if err != nil {
+		return domain.AppendResult{}, err
+	}
+
+	if err := os.MkdirAll(l.dir, 0o755); err != nil {
+		return domain.AppendResult{}, err
+	}
+	if l.shouldRoll(len(records), nextOffset-1) {
+		if err := l.roll(); err != nil {
+			return domain.AppendResult{}, err
+		}
+	}
+	if err := l.activeSegment().append(toWrite); err != nil {
+		return domain.AppendResult{}, err
+	}
+	l.logEndOffset = nextOffset
+	aborted := l.abortedTxns(headers, toWrite)
+	l.producerState.commit(producers)
+	if err := l.indexAbortedTxns(aborted); err != nil {
+		return domain.AppendResult{}, err
+	}
+
+	return domain.AppendResult{
+		BaseOffset:      baseOffset,
+		LogAppendTimeMs: -1, // Topics use CreateTime, the producer's timestamps are kept
+		LogStartOffset:  l.logStartOffset,
+	}, nil
+}
+
+// close snapshots the producer state so the next load doesn't have to replay the log
+func (l *partitionLog) close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.deleted || !l.loaded || len(l.segments) == 0 {
+		return nil
+	}
+	return l.producerState.takeSnapshot(l.logEndOffset)
+}
+
+// abortedTxns returns the transactions ended by the ABORT markers among the batches. It
+// must be called before the batches are applied to the producer state, which still
+// knows where the transactions began.
+func (l *partitionLog) abortedTxns(headers []common.RecordBatchHeader, batches [][]byte) []abortedTxn {
+	aborted := []abortedTxn{}
+	for i, header := range headers {
+		if !header.IsControl() {
+			continue
+		}
+		decoded, err := common.DecodeRecordBatch(batches[i])
+		if err != nil {
+			continue
+		}
+		marker, err := common.DecodeControlRecord(decoded)
+		if err != nil || marker.Type != common.ControlRecordTypeAbort {
+			continue
+		}
+		firstOffset := l.producerState.currentTxnFirstOffset(header.ProducerID)
+		if firstOffset < 0 {
+			firstOffset = header.BaseOffset
+		}
+		aborted = append(aborted, abortedTxn{producerID: header.ProducerID, firstOffset: firstOffset, lastOffset: header.BaseOffset})
+	}
+	return aborted
+}
+
+// indexAbortedTxns adds the aborted transactions to the .txnindex of the segments holding
+// their markers once the markers are applied to the producer state
+func (l *partitionLog) indexAbortedTxns(aborted []abortedTxn) error {
+	for _, txn := range aborted {
+		txn.lastStableOffset = l.lastStableOffset()
+		if err := l.segments[l.segmentIndexFor(txn.lastOffset)].txnIndex.append(txn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lastStableOffset is the offset up to which every transaction is decided: the first
+// offset of the oldest open transaction, or the high watermark. The caller holds mu.
+func (l *partitionLog) lastStableOffset() int64 {
+	if firstUnstableOffset, exists := l.producerState.firstUnstableOffset(); exists {
+		return min(firstUnstableOffset, l.logEndOffset)
+	}
+	return l.logEndOffset
+}
+
+// offsets returns the log start offset, the last stable offset and the offset the next
+// append will get
+func (l *partitionLog) offsets() (domain.LogOffsets, error) {
+	if err := l.ensureLoaded(); err != nil {
+		return domain.LogOffsets{}, err
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if err := l.errIfDeleted(); err != nil {
+		return domain.LogOffsets{}, err
+	}
+
+	return domain.LogOffsets{LogStartOffset: l.logStartOffset, LastStableOffset: l.lastStableOffset(), HighWatermark: l.logEndOffset}, nil
+}
+
+// describeProducers returns the state of the producers that wrote to the log
+func (l *partitionLog) describeProducers() ([]domain.ProducerState, error) {
+	if err := l.ensureLoaded(); err != nil {
+		return nil, err
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if err := l.errIfDeleted(); err != nil {
+		return nil, err
+	}
+
+	return l.producerState.describe(), nil
+}
+
+// read returns the whole batches from the one containing the fetch offset up to the high
+// watermark that fit in MaxBytes. With MinOneBatch the first batch is returned even when
+// it is larger than MaxBytes, so a consumer can always make progress past an oversized
+// batch. Reading committed data stops at the last stable offset and lists the aborted
+// transactions overlapping the batches returned.
+func (l *partitionLog) read(readRequest domain.ReadRequest) (domain.ReadResult, error) {
+	if err := l.ensureLoaded(); err != nil {
+		return domain.ReadResult{}, err
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if err := l.errIfDeleted(); err != nil {
+		return domain.ReadResult{}, err
+	}
+
+	fetchOffset := readRequest.FetchOffset
+	result := domain.ReadResult{
+		Records:          []byte{},
+		LogStartOffset:   l.logStartOffset,
+		LastStableOffset: l.lastStableOffset(),
+		HighWatermark:    l.logEndOffset,
+	}
+	if fetchOffset < l.logStartOffset || fetchOffset > l.logEndOffset {
+		return result, fmt.Errorf("%w: %d is not in [%d, %d]", domain.ErrOffsetOutOfRange, fetchOffset, l.logStartOffset, l.logEndOffset)
+	}
+
+	readCommitted := readRequest.IsolationLevel == domain.IsolationLevelReadCommitted
+	maxOffset := l.logEndOffset
+	if readCommitted {
+		maxOffset = result.LastStableOffset
+	}
+	upperBound := fetchOffset
+	err := l.forEachBatchFrom(fetchOffset, func(header common.RecordBatchHeader, batch []byte) bool {
+		if header.NextOffset() <= fetchOffset {
+			return true
+		}
+		if header.BaseOffset >= maxOffset {
+			return false
+		}
+		firstBatch := len(result.Records) == 0
+		if len(result.Records)+len(batch) > int(readRequest.MaxBytes) && !(firstBatch && readRequest.MinOneBatch) {
+			return false
+		}
+		result.Records = append(result.Records, batch...)
+		upperBound = header.NextOffset()
+		return true
+	})
+	if err != nil || !readCommitted || len(result.Records) == 0 {
+		return result, err
+	}
+
+	result.AbortedTransactions = []domain.AbortedTransaction{}
+	for _, segment := range l.segments[l.segmentIndexFor(fetchOffset):] {
+		result.AbortedTransactions = segment.txnIndex.collect(fetchOffset, upperBound, result.AbortedTransactions)
+	}
+	return result, nil
+}
+
+// forEachBatchFrom calls fn for every batch starting with the indexed batch at or before
+// offset, in offset order across segments, until fn returns false. The caller holds mu.
+func (l *partitionLog) forEachBatchFrom(offset int64, fn func(header common.RecordBatchHeader, batch []byte) bool) error {
+	if len(l.segments) == 0 {
+		return nil
+	}
+
+	first := l.segmentIndexFor(offset)
+	for i, segment := range l.segments[first:] {
+		position := int64(0)
+		if i == 0 {
+			position = segment.index.lookup(offset)
+		}
+		stopped := false
+		err := segment.forEachBatch(position, func(header common.RecordBatchHeader, batch []byte) bool {
+			stopped = !fn(header, batch)
+			return !stopped
+		})
+		if err != nil || stopped {
+			return err
+		}
+	}
+	return nil
+}
+
+// findOffsetByTimestamp returns the first record with a timestamp at or after timestamp.
+// Segments whose max timestamp is below it are skipped, and the time index locates
+// where to start scanning in the first segment that may hold it.
+func (l *partitionLog) findOffsetByTimestamp(timestamp int64) (domain.OffsetLookupResult, error) {
+	if err := l.ensureLoaded(); err != nil {
+		return domain.OffsetLookupResult{}, err
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if err := l.errIfDeleted(); err != nil {
+		return domain.OffsetLookupResult{}, err
+	}
+
+	result := domain.OffsetLookupResult{}
+	for _, segment := range l.segments {
+		if segment.maxTimestamp < timestamp {
+			continue
+		}
+		position := segment.index.lookup(segment.timeIndex.lookup(timestamp))
+		err := segment.forEachBatch(position, func(header common.RecordBatchHeader, batch []byte) bool {
+			if header.IsControl() || header.MaxTimestamp < timestamp {
+				return true
+			}
+			result = findRecordInBatch(header, batch, func(recordTimestamp int64) bool {
+				return recordTimestamp >= timestamp
+			})
+			return false
+		})
+		if err != nil || result.Found {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// findOffsetOfMaxTimestamp returns the first record carrying the largest timestamp in the log,
+// found through the segment with the largest max timestamp
+func (l *partitionLog) findOffsetOfMaxTimestamp() (domain.OffsetLookupResult, error) {
+	if err := l.ensureLoaded(); err != nil {
+		return domain.OffsetLookupResult{}, err
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if err := l.errIfDeleted(); err != nil {
+		return domain.OffsetLookupResult{}, err
+	}
+
+	var maxSegment *logSegment
+	for _, segment := range l.segments {
+		if segment.size > 0 && (maxSegment == nil || segment.maxTimestamp > maxSegment.maxTimestamp) {
+			maxSegment = segment
+		}
+	}
+	if maxSegment == nil {
+		return domain.OffsetLookupResult{}, nil
+	}
+
+	result := domain.OffsetLookupResult{}
+	position := maxSegment.index.lookup(maxSegment.offsetOfMaxTimestamp)
+	err := maxSegment.forEachBatch(position, func(header common.RecordBatchHeader, batch []byte) bool {
+		if header.MaxTimestamp != maxSegment.maxTimestamp {
+			return true
+		}
+		result = findRecordInBatch(header, batch, func(recordTimestamp int64) bool {
+			return recordTimestamp >= maxSegment.maxTimestamp
+		})
+		return false
+	})
+	return result, err
+}
+
+// findRecordInBatch returns the first record of the batch whose timestamp matches.
+// Compressed batches are not decoded; their base offset and max timestamp are returned.
+func findRecordInBatch(header common.RecordBatchHeader, batch []byte, matches func(recordTimestamp int64) bool) domain.OffsetLookupResult {
+	result := domain.OffsetLookupResult{
+		Found:       true,
+		Offset:      header.BaseOffset,
+		Timestamp:   header.MaxTimestamp,
+		LeaderEpoch: header.PartitionLeaderEpoch,
+	}
+	if header.CompressionCodec() != 0 || header.Attributes&common.TimestampTypeLogAppendTime != 0 {
+		return result
+	}
+
+	decoded, err := common.DecodeRecordBatch(batch)
+	if err != nil {
+		return result
+	}
+	for _, record := range decoded.Records {
+		recordTimestamp := header.BaseTimestamp + record.TimestampDelta
+		if matches(recordTimestamp) {
+			result.Offset = header.BaseOffset + int64(record.OffsetDelta)
+			result.Timestamp = recordTimestamp
+			return result
+		}
+	}
+	return result
+}
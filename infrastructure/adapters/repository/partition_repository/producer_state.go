@@ -0,0 +1,381 @@
+package partition_file_repository
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// Producer state snapshots are named after the offset up to which they cover the log
+const snapshotFileSuffix = ".snapshot"
+
+const (
+	// producerSnapshotVersion is the version of Kafka's producer snapshot format
+	producerSnapshotVersion int16 = 1
+	// producerSnapshotHeaderSize covers the version, the CRC and the entry count
+	producerSnapshotHeaderSize = 10
+	// producerSnapshotEntrySize is the size of a snapshotted producer: ID (INT64), epoch
+	// (INT16), last sequence (INT32), last offset (INT64), offset delta (INT32), timestamp
+	// (INT64), coordinator epoch (INT32) and first offset of the open transaction (INT64)
+	producerSnapshotEntrySize = 46
+
+	// maxProducerBatches is how many of a producer's last batches are kept to detect
+	// retried ones, as many as a producer may have in flight
+	maxProducerBatches = 5
+	noSequence         = -1
+	noProducerEpoch    = -1
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+var errCorruptSnapshot = errors.New("corrupt producer snapshot")
+
+// producerBatch is what is kept of a batch an idempotent producer appended
+type producerBatch struct {
+	firstSequence int32
+	lastSequence  int32
+	firstOffset   int64
+	lastOffset    int64
+	timestamp     int64 // Max timestamp of the batch
+}
+
+func newProducerBatch(header common.RecordBatchHeader) producerBatch {
+	return producerBatch{
+		firstSequence: header.BaseSequence,
+		lastSequence:  lastSequence(header),
+		firstOffset:   header.BaseOffset,
+		lastOffset:    header.LastOffset(),
+		timestamp:     header.MaxTimestamp,
+	}
+}
+
+// lastSequence returns the sequence of the last record of the batch; sequences wrap
+// around to 0 after math.MaxInt32
+func lastSequence(header common.RecordBatchHeader) int32 {
+	last := int64(header.BaseSequence) + int64(header.LastOffsetDelta)
+	if last > math.MaxInt32 {
+		last -= math.MaxInt32 + 1
+	}
+	return int32(last)
+}
+
+// inSequence reports whether a batch starting at sequence next may follow one ending at last
+func inSequence(last int32, next int32) bool {
+	return next == last+1 || last == math.MaxInt32 && next == 0
+}
+
+// producerStateEntry is the state of one producer ID in a partition
+type producerStateEntry struct {
+	producerID int64
+	epoch      int16
+	batches    []producerBatch // The last batches of the epoch, oldest first
+	// currentTxnFirstOffset is the offset of the first batch of the producer's open
+	// transaction in the partition, -1 outside of a transaction
+	currentTxnFirstOffset int64
+}
+
+func newProducerStateEntry(producerID int64) *producerStateEntry {
+	return &producerStateEntry{producerID: producerID, epoch: noProducerEpoch, currentTxnFirstOffset: -1}
+}
+
+func (e *producerStateEntry) clone() *producerStateEntry {
+	clone := *e
+	clone.batches = slices.Clone(e.batches)
+	return &clone
+}
+
+func (e *producerStateEntry) lastSequence() int32 {
+	if len(e.batches) == 0 {
+		return noSequence
+	}
+	return e.batches[len(e.batches)-1].lastSequence
+}
+
+// findDuplicate returns the kept batch with the sequences of the batch
+func (e *producerStateEntry) findDuplicate(header common.RecordBatchHeader) (producerBatch, bool) {
+	for _, batch := range e.batches {
+		if batch.firstSequence == header.BaseSequence && batch.lastSequence == lastSequence(header) {
+			return batch, true
+		}
+	}
+	return producerBatch{}, false
+}
+
+// apply records an appended batch, starting over when it opens a new epoch. Batches
+// without a sequence, written by a coordinator, and the markers ending transactions
+// only move the epoch and the open transaction.
+func (e *producerStateEntry) apply(header common.RecordBatchHeader) {
+	if header.ProducerEpoch != e.epoch {
+		e.epoch = header.ProducerEpoch
+		e.batches = nil
+	}
+	switch {
+	case header.IsControl():
+		e.currentTxnFirstOffset = -1
+		return
+	case header.BaseSequence != noSequence:
+		e.batches = append(e.batches, newProducerBatch(header))
+		if len(e.batches) > maxProducerBatches {
+			e.batches = slices.Delete(e.batches, 0, len(e.batches)-maxProducerBatches)
+		}
+	}
+	if header.IsTransactional() && e.currentTxnFirstOffset < 0 {
+		e.currentTxnFirstOffset = header.BaseOffset
+	}
+}
+
+// producerStateManager tracks the idempotent producers writing to a partition so retried
+// batches are not appended twice and lost ones are noticed, along with the transactions
+// they have open in the partition. The state is snapshotted to
+// <offset>.snapshot files next to the segments; on load the newest snapshot is read and
+// the batches appended after it are replayed.
+type producerStateManager struct {
+	dir       string
+	producers map[int64]*producerStateEntry
+}
+
+func newProducerStateManager(dir string) *producerStateManager {
+	return &producerStateManager{dir: dir, producers: map[int64]*producerStateEntry{}}
+}
+
+// prepareAppend checks that the batches, with their offsets assigned, continue the
+// sequences of their producers and returns the producer states after appending them.
+// A batch appended before fails with domain.ErrDuplicateSequenceNumber and that batch.
+// Markers and batches without a sequence only need a current epoch.
+func (m *producerStateManager) prepareAppend(headers []common.RecordBatchHeader) (map[int64]*producerStateEntry, producerBatch, error) {
+	updated := map[int64]*producerStateEntry{}
+	for _, header := range headers {
+		if header.ProducerID < 0 {
+			continue
+		}
+		entry, exists := updated[header.ProducerID]
+		if !exists {
+			entry = newProducerStateEntry(header.ProducerID)
+			if current, known := m.producers[header.ProducerID]; known {
+				entry = current.clone()
+			}
+			updated[header.ProducerID] = entry
+		}
+
+		switch {
+		case header.ProducerEpoch < entry.epoch:
+			return nil, producerBatch{}, fmt.Errorf("%w: producer %d is at epoch %d, not %d", domain.ErrInvalidProducerEpoch, header.ProducerID, entry.epoch, header.ProducerEpoch)
+		case header.IsControl() || header.BaseSequence == noSequence:
+			// Only the epoch is checked
+		case header.ProducerEpoch > entry.epoch:
+			// A producer whose state the partition lost, after its snapshot was deleted or
+			// its state expired, resumes at whatever sequence it is at
+			if header.BaseSequence != 0 && entry.epoch != noProducerEpoch {
+				return nil, producerBatch{}, fmt.Errorf("%w: producer %d starts epoch %d at sequence %d", domain.ErrOutOfOrderSequenceNumber, header.ProducerID, header.ProducerEpoch, header.BaseSequence)
+			}
+		default:
+			if duplicate, found := entry.findDuplicate(header); found {
+				return nil, duplicate, fmt.Errorf("%w: producer %d already appended sequence %d at offset %d", domain.ErrDuplicateSequenceNumber, header.ProducerID, header.BaseSequence, duplicate.firstOffset)
+			}
+			if !inSequence(entry.lastSequence(), header.BaseSequence) {
+				return nil, producerBatch{}, fmt.Errorf("%w: producer %d sent sequence %d after %d", domain.ErrOutOfOrderSequenceNumber, header.ProducerID, header.BaseSequence, entry.lastSequence())
+			}
+		}
+		entry.apply(header)
+	}
+	return updated, producerBatch{}, nil
+}
+
+// commit makes the states prepareAppend returned current once the batches are written
+func (m *producerStateManager) commit(updated map[int64]*producerStateEntry) {
+	maps.Copy(m.producers, updated)
+}
+
+// replay applies a batch read back from the log without checking it
+func (m *producerStateManager) replay(header common.RecordBatchHeader) {
+	if header.ProducerID < 0 {
+		return
+	}
+	entry, exists := m.producers[header.ProducerID]
+	if !exists {
+		entry = newProducerStateEntry(header.ProducerID)
+		m.producers[header.ProducerID] = entry
+	}
+	entry.apply(header)
+}
+
+// currentTxnFirstOffset returns the first offset of the producer's open transaction, -1
+// when it has none
+func (m *producerStateManager) currentTxnFirstOffset(producerID int64) int64 {
+	if entry, exists := m.producers[producerID]; exists {
+		return entry.currentTxnFirstOffset
+	}
+	return -1
+}
+
+// firstUnstableOffset returns the first offset of the oldest open transaction, if any
+func (m *producerStateManager) firstUnstableOffset() (int64, bool) {
+	firstOffset, exists := int64(math.MaxInt64), false
+	for _, entry := range m.producers {
+		if entry.currentTxnFirstOffset >= 0 {
+			firstOffset, exists = min(firstOffset, entry.currentTxnFirstOffset), true
+		}
+	}
+	return firstOffset, exists
+}
+
+// describe returns the state of every producer, ordered by producer ID. Coordinator
+// epochs are not tracked and reported unknown.
+func (m *producerStateManager) describe() []domain.ProducerState {
+	described := make([]domain.ProducerState, 0, len(m.producers))
+	for _, producerID := range slices.Sorted(maps.Keys(m.producers)) {
+		entry := m.producers[producerID]
+		state := domain.ProducerState{
+			ProducerID:            entry.producerID,
+			ProducerEpoch:         int32(entry.epoch),
+			LastSequence:          entry.lastSequence(),
+			LastTimestamp:         -1,
+			CoordinatorEpoch:      -1,
+			CurrentTxnStartOffset: entry.currentTxnFirstOffset,
+		}
+		if len(entry.batches) > 0 {
+			state.LastTimestamp = entry.batches[len(entry.batches)-1].timestamp
+		}
+		described = append(described, state)
+	}
+	return described
+}
+
+// takeSnapshot writes the state of every producer to <offset>.snapshot, offset being the
+// log end offset it reflects. Like Kafka's, the snapshot keeps each producer's last batch.
+func (m *producerStateManager) takeSnapshot(offset int64) error {
+	data := make([]byte, producerSnapshotHeaderSize, producerSnapshotHeaderSize+len(m.producers)*producerSnapshotEntrySize)
+	binary.BigEndian.PutUint16(data[0:2], uint16(producerSnapshotVersion))
+	binary.BigEndian.PutUint32(data[6:10], uint32(len(m.producers)))
+	for _, producerID := range slices.Sorted(maps.Keys(m.producers)) {
+		entry := m.producers[producerID]
+		last := producerBatch{lastSequence: noSequence, firstOffset: -1, lastOffset: -1, timestamp: -1}
+		if len(entry.batches) > 0 {
+			last = entry.batches[len(entry.batches)-1]
+		}
+		data = binary.BigEndian.AppendUint64(data, uint64(entry.producerID))
+		data = binary.BigEndian.AppendUint16(data, uint16(entry.epoch))
+		data = binary.BigEndian.AppendUint32(data, uint32(last.lastSequence))
+		data = binary.BigEndian.AppendUint64(data, uint64(last.lastOffset))
+		data = binary.BigEndian.AppendUint32(data, uint32(last.lastOffset-last.firstOffset))
+		data = binary.BigEndian.AppendUint64(data, uint64(last.timestamp))
+		data = binary.BigEndian.AppendUint32(data, math.MaxUint32) // No coordinator epoch
+		data = binary.BigEndian.AppendUint64(data, uint64(entry.currentTxnFirstOffset))
+	}
+	binary.BigEndian.PutUint32(data[2:6], crc32.Checksum(data[6:], crc32cTable))
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(m.dir, segmentFileName(offset, snapshotFileSuffix))
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// loadSnapshot restores the producers from the newest readable snapshot taken at or before
+// logEndOffset and returns its offset, or -1 when there is none. Snapshots past the end
+// of the log and unreadable ones are deleted.
+func (m *producerStateManager) loadSnapshot(logEndOffset int64) (int64, error) {
+	offsets, err := listSnapshotOffsets(m.dir)
+	if err != nil {
+		return -1, err
+	}
+	m.producers = map[int64]*producerStateEntry{}
+	for _, offset := range slices.Backward(offsets) {
+		path := filepath.Join(m.dir, segmentFileName(offset, snapshotFileSuffix))
+		if offset > logEndOffset {
+			os.Remove(path)
+			continue
+		}
+		producers, err := readSnapshot(path)
+		if err == nil {
+			m.producers = producers
+			return offset, nil
+		}
+		if !errors.Is(err, errCorruptSnapshot) {
+			return -1, err
+		}
+		fmt.Printf("Deleting producer snapshot %s: %v\n", path, err)
+		os.Remove(path)
+	}
+	return -1, nil
+}
+
+func readSnapshot(path string) (map[int64]*producerStateEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < producerSnapshotHeaderSize {
+		return nil, fmt.Errorf("%w: %d bytes is shorter than the header", errCorruptSnapshot, len(data))
+	}
+	if version := int16(binary.BigEndian.Uint16(data[0:2])); version != producerSnapshotVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", errCorruptSnapshot, version)
+	}
+	if crc := crc32.Checksum(data[6:], crc32cTable); crc != binary.BigEndian.Uint32(data[2:6]) {
+		return nil, fmt.Errorf("%w: crc %08x does not match", errCorruptSnapshot, crc)
+	}
+	count := int(binary.BigEndian.Uint32(data[6:10]))
+	if len(data) != producerSnapshotHeaderSize+count*producerSnapshotEntrySize {
+		return nil, fmt.Errorf("%w: %d bytes do not hold %d producers", errCorruptSnapshot, len(data), count)
+	}
+
+	producers := make(map[int64]*producerStateEntry, count)
+	for position := producerSnapshotHeaderSize; position < len(data); position += producerSnapshotEntrySize {
+		entry := &producerStateEntry{
+			producerID:            int64(binary.BigEndian.Uint64(data[position:])),
+			epoch:                 int16(binary.BigEndian.Uint16(data[position+8:])),
+			currentTxnFirstOffset: int64(binary.BigEndian.Uint64(data[position+38:])),
+		}
+		last := producerBatch{
+			lastSequence: int32(binary.BigEndian.Uint32(data[position+10:])),
+			lastOffset:   int64(binary.BigEndian.Uint64(data[position+14:])),
+			timestamp:    int64(binary.BigEndian.Uint64(data[position+26:])),
+		}
+		offsetDelta := int32(binary.BigEndian.Uint32(data[position+22:]))
+		if last.lastSequence != noSequence {
+			last.firstOffset = last.lastOffset - int64(offsetDelta)
+			last.firstSequence = last.lastSequence - offsetDelta
+			if last.firstSequence < 0 {
+				last.firstSequence += math.MaxInt32
+				last.firstSequence++
+			}
+			entry.batches = []producerBatch{last}
+		}
+		producers[entry.producerID] = entry
+	}
+	return producers, nil
+}
+
+// listSnapshotOffsets returns the offsets of the snapshots in dir in ascending order
+func listSnapshotOffsets(dir string) ([]int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	offsets := []int64{}
+	for _, entry := range entries {
+		name, isSnapshot := strings.CutSuffix(entry.Name(), snapshotFileSuffix)
+		if !isSnapshot || entry.IsDir() || len(name) != 20 {
+			continue
+		}
+		if offset, err := strconv.ParseInt(name, 10, 64); err == nil {
+			offsets = append(offsets, offset)
+		}
+	}
+	slices.Sort(offsets)
+	return offsets, nil
+}
@@ -0,0 +1,235 @@
+package partition_file_repository
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// producerBatchOf is a batch of records records of producer 4000
+func producerBatchOf(epoch int16, sequence int32, records int) []byte {
+	batch := common.RecordBatch{
+		RecordBatchHeader: common.RecordBatchHeader{
+			LastOffsetDelta: int32(records - 1),
+			ProducerID:      4000,
+			ProducerEpoch:   epoch,
+			BaseSequence:    sequence,
+		},
+	}
+	for i := range records {
+		batch.Records = append(batch.Records, common.Record{OffsetDelta: int32(i), Value: []byte("value")})
+	}
+	return common.EncodeRecordBatch(batch)
+}
+
+func appendTo(repo *PartitionFileRepository, records ...[]byte) (domain.AppendResult, error) {
+	all := []byte{}
+	for _, batch := range records {
+		all = append(all, batch...)
+	}
+	return repo.AppendRecordBatches(domain.AppendRequest{TopicName: "foo", PartitionIndex: 0, Records: all})
+}
+
+func TestPartitionFileRepository_IdempotentAppends(t *testing.T) {
+	repo := NewPartitionFileRepositoryWithLogDir(t.TempDir())
+
+	appends := []struct {
+		name           string
+		records        [][]byte
+		wantErr        error
+		wantBaseOffset int64
+	}{
+		{name: "first batches", records: [][]byte{producerBatchOf(0, 0, 2), producerBatchOf(0, 2, 1)}, wantBaseOffset: 0},
+		{name: "retry of an earlier batch", records: [][]byte{producerBatchOf(0, 0, 2)}, wantErr: domain.ErrDuplicateSequenceNumber, wantBaseOffset: 0},
+		{name: "retry of the last batch", records: [][]byte{producerBatchOf(0, 2, 1)}, wantErr: domain.ErrDuplicateSequenceNumber, wantBaseOffset: 2},
+		{name: "gap", records: [][]byte{producerBatchOf(0, 4, 1)}, wantErr: domain.ErrOutOfOrderSequenceNumber},
+		{name: "gap inside the request", records: [][]byte{producerBatchOf(0, 3, 1), producerBatchOf(0, 5, 1)}, wantErr: domain.ErrOutOfOrderSequenceNumber},
+		{name: "next batch", records: [][]byte{producerBatchOf(0, 3, 1)}, wantBaseOffset: 3},
+		{name: "new epoch not at 0", records: [][]byte{producerBatchOf(1, 4, 1)}, wantErr: domain.ErrOutOfOrderSequenceNumber},
+		{name: "new epoch", records: [][]byte{producerBatchOf(1, 0, 1)}, wantBaseOffset: 4},
+		{name: "fenced epoch", records: [][]byte{producerBatchOf(0, 4, 1)}, wantErr: domain.ErrInvalidProducerEpoch},
+		{name: "without producer", records: [][]byte{testRecordBatch("a")}, wantBaseOffset: 5},
+	}
+	for _, a := range appends {
+		result, err := appendTo(repo, a.records...)
+		if !errors.Is(err, a.wantErr) {
+			t.Fatalf("%s: AppendRecordBatches() error = %v, want %v", a.name, err, a.wantErr)
+		}
+		if (err == nil || errors.Is(err, domain.ErrDuplicateSequenceNumber)) && result.BaseOffset != a.wantBaseOffset {
+			t.Errorf("%s: BaseOffset = %d, want %d", a.name, result.BaseOffset, a.wantBaseOffset)
+		}
+	}
+
+	offsets, _ := repo.GetLogOffsets("foo", 0)
+	if offsets.HighWatermark != 6 {
+		t.Errorf("HighWatermark = %d, want 6: rejected batches must not be written", offsets.HighWatermark)
+	}
+}
+
+func TestPartitionFileRepository_IdempotentAppends_UnknownProducer(t *testing.T) {
+	repo := NewPartitionFileRepositoryWithLogDir(t.TempDir())
+
+	appends := []struct {
+		name    string
+		records [][]byte
+		wantErr error
+	}{
+		{name: "unknown producer past sequence 0", records: [][]byte{producerBatchOf(2, 7, 1)}},
+		{name: "next batch", records: [][]byte{producerBatchOf(2, 8, 1)}},
+		{name: "retry", records: [][]byte{producerBatchOf(2, 7, 1)}, wantErr: domain.ErrDuplicateSequenceNumber},
+		{name: "new epoch of a known producer not at 0", records: [][]byte{producerBatchOf(3, 9, 1)}, wantErr: domain.ErrOutOfOrderSequenceNumber},
+	}
+	for _, a := range appends {
+		if _, err := appendTo(repo, a.records...); !errors.Is(err, a.wantErr) {
+			t.Fatalf("%s: AppendRecordBatches() error = %v, want %v", a.name, err, a.wantErr)
+		}
+	}
+}
+
+func TestProducerStateEntry_KeepsLastBatches(t *testing.T) {
+	entry := newProducerStateEntry(4000)
+	for sequence := range int32(maxProducerBatches + 2) {
+		entry.apply(common.RecordBatchHeader{ProducerID: 4000, BaseSequence: sequence, BaseOffset: int64(sequence)})
+	}
+	if _, found := entry.findDuplicate(common.RecordBatchHeader{BaseSequence: 1}); found {
+		t.Errorf("batch 1 is still kept, want only the last %d", maxProducerBatches)
+	}
+	if batch, found := entry.findDuplicate(common.RecordBatchHeader{BaseSequence: 2}); !found || batch.firstOffset != 2 {
+		t.Errorf("findDuplicate(2) = %+v, %v, want the batch at offset 2", batch, found)
+	}
+
+	// Sequences wrap around after math.MaxInt32
+	header := common.RecordBatchHeader{BaseSequence: math.MaxInt32 - 1, LastOffsetDelta: 2}
+	if last := lastSequence(header); last != 0 || !inSequence(last, 1) {
+		t.Errorf("lastSequence() = %d, want 0 followed by 1", last)
+	}
+}
+
+func TestPartitionFileRepository_ProducerSnapshots(t *testing.T) {
+	logDir := t.TempDir()
+	partitionDir := filepath.Join(logDir, "foo-0")
+	repo := NewPartitionFileRepositoryWithLogDir(logDir, WithSegmentBytes(1))
+	for sequence := range int32(3) {
+		if _, err := appendTo(repo, producerBatchOf(0, sequence, 1)); err != nil {
+			t.Fatalf("AppendRecordBatches() error = %v", err)
+		}
+	}
+
+	// Every roll snapshots the state as of the new segment's base offset
+	offsets, err := listSnapshotOffsets(partitionDir)
+	if err != nil || len(offsets) != 2 || offsets[0] != 1 || offsets[1] != 2 {
+		t.Fatalf("snapshots = %v, %v, want at offsets 1 and 2", offsets, err)
+	}
+
+	// The batch after the newest snapshot is replayed from the log
+	reopened := NewPartitionFileRepositoryWithLogDir(logDir, WithSegmentBytes(1))
+	if result, err := appendTo(reopened, producerBatchOf(0, 2, 1)); !errors.Is(err, domain.ErrDuplicateSequenceNumber) || result.BaseOffset != 2 {
+		t.Errorf("retry after reopening = %+v, %v, want duplicate of offset 2", result, err)
+	}
+	if _, err := appendTo(reopened, producerBatchOf(0, 3, 1)); err != nil {
+		t.Fatalf("AppendRecordBatches() after reopening error = %v", err)
+	}
+
+	// Closing snapshots the state at the log end
+	reopened.Close()
+	offsets, _ = listSnapshotOffsets(partitionDir)
+	if offsets[len(offsets)-1] != 4 {
+		t.Errorf("snapshots after Close() = %v, want one at offset 4", offsets)
+	}
+
+	restarted := NewPartitionFileRepositoryWithLogDir(logDir)
+	if result, err := appendTo(restarted, producerBatchOf(0, 3, 1)); !errors.Is(err, domain.ErrDuplicateSequenceNumber) || result.BaseOffset != 3 {
+		t.Errorf("retry after restarting = %+v, %v, want duplicate of offset 3", result, err)
+	}
+
+	// A corrupt snapshot is dropped and the state rebuilt from the log
+	newest := filepath.Join(partitionDir, segmentFileName(4, snapshotFileSuffix))
+	if err := os.WriteFile(newest, []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	recovered := NewPartitionFileRepositoryWithLogDir(logDir)
+	if _, err := appendTo(recovered, producerBatchOf(0, 3, 1)); !errors.Is(err, domain.ErrDuplicateSequenceNumber) {
+		t.Errorf("retry after recovering error = %v, want ErrDuplicateSequenceNumber", err)
+	}
+	if _, err := os.Stat(newest); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("corrupt snapshot was kept: %v", err)
+	}
+}
+
+func TestPartitionFileRepository_TxnMarkers(t *testing.T) {
+	logDir := t.TempDir()
+	repo := NewPartitionFileRepositoryWithLogDir(logDir)
+	transactional := func(epoch int16, sequence int32) []byte {
+		batch, _ := common.DecodeRecordBatch(producerBatchOf(epoch, sequence, 2))
+		batch.Attributes |= common.TransactionalAttributeFlag
+		return common.EncodeRecordBatch(batch)
+	}
+	openTxn := func(r *PartitionFileRepository) int64 {
+		return r.getPartitionLog("foo", 0).producerState.producers[4000].currentTxnFirstOffset
+	}
+
+	appendTo(repo, producerBatchOf(0, 0, 1))
+	if _, err := appendTo(repo, transactional(0, 1)); err != nil {
+		t.Fatalf("AppendRecordBatches() error = %v", err)
+	}
+	appendTo(repo, transactional(0, 3))
+	if first := openTxn(repo); first != 1 {
+		t.Errorf("open transaction starts at %d, want 1", first)
+	}
+
+	// The open transaction survives a restart through the snapshot
+	repo.Close()
+	repo = NewPartitionFileRepositoryWithLogDir(logDir)
+	appendTo(repo, producerBatchOf(0, 5, 1))
+	if first := openTxn(repo); first != 1 {
+		t.Errorf("open transaction after restarting starts at %d, want 1", first)
+	}
+
+	result, err := repo.WriteTxnMarker(domain.TxnMarkerAppend{TopicName: "foo", ProducerID: 4000, ProducerEpoch: 1, Committed: true})
+	if err != nil || result.BaseOffset != 6 {
+		t.Fatalf("WriteTxnMarker() = %+v, %v, want the marker at offset 6", result, err)
+	}
+	if first := openTxn(repo); first != -1 {
+		t.Errorf("open transaction starts at %d after the marker, want none", first)
+	}
+	read, _ := repo.ReadRecordBatches(domain.ReadRequest{TopicName: "foo", FetchOffset: 6, MaxBytes: 1024})
+	batch, _ := common.DecodeRecordBatch(read.Records)
+	if marker, err := common.DecodeControlRecord(batch); err != nil || marker.Type != common.ControlRecordTypeCommit {
+		t.Errorf("DecodeControlRecord() = %+v, %v, want a commit marker", marker, err)
+	}
+
+	// The marker's epoch fences the producer's older epochs
+	if _, err := appendTo(repo, producerBatchOf(0, 6, 1)); !errors.Is(err, domain.ErrInvalidProducerEpoch) {
+		t.Errorf("append at the fenced epoch error = %v, want ErrInvalidProducerEpoch", err)
+	}
+	if _, err := repo.WriteTxnMarker(domain.TxnMarkerAppend{TopicName: "foo", ProducerID: 4000, ProducerEpoch: 0}); !errors.Is(err, domain.ErrInvalidProducerEpoch) {
+		t.Errorf("WriteTxnMarker() at the fenced epoch error = %v, want ErrInvalidProducerEpoch", err)
+	}
+}
+
+func TestPartitionFileRepository_DescribeProducers(t *testing.T) {
+	repo := NewPartitionFileRepositoryWithLogDir(t.TempDir())
+	appendTo(repo, producerBatchOf(1, 0, 3))
+	appendTo(repo, transactionalBatchOf(1, 3, 2))
+
+	want := []domain.ProducerState{{
+		ProducerID:            4000,
+		ProducerEpoch:         1,
+		LastSequence:          4,
+		LastTimestamp:         0,
+		CoordinatorEpoch:      -1,
+		CurrentTxnStartOffset: 3,
+	}}
+	if got, err := repo.DescribeProducers("foo", 0); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("DescribeProducers() = %+v, %v, want %+v", got, err, want)
+	}
+	if got, err := repo.DescribeProducers("foo", 1); err != nil || len(got) != 0 {
+		t.Errorf("DescribeProducers() of an empty partition = %+v, %v, want none", got, err)
+	}
+}
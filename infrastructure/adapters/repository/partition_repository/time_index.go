@@ -0,0 +1,106 @@
+package partition_file_repository
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// timeIndexEntrySize is the size of a .timeindex entry: timestamp (INT64) and relative offset (INT32)
+const timeIndexEntrySize = 12
+
+type timeIndexEntry struct {
+	timestamp      int64 // Largest timestamp in the segment up to relativeOffset
+	relativeOffset int32 // Last offset of the batch holding that timestamp, relative to the segment base offset
+}
+
+func (e timeIndexEntry) appendTo(data []byte) []byte {
+	data = binary.BigEndian.AppendUint64(data, uint64(e.timestamp))
+	return binary.BigEndian.AppendUint32(data, uint32(e.relativeOffset))
+}
+
+// timeIndex is the sparse timestamp to offset index of a segment, kept in memory and
+// mirrored to a Kafka-compatible .timeindex file. Timestamps only grow along the index.
+type timeIndex struct {
+	path       string
+	baseOffset int64
+	entries    []timeIndexEntry
+}
+
+// loadTimeIndex reads a .timeindex file, failing with errCorruptIndex when the file is
+// missing or its entries are not ordered
+func loadTimeIndex(path string, baseOffset int64) (*timeIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s is missing", errCorruptIndex, path)
+		}
+		return nil, err
+	}
+	if len(data)%timeIndexEntrySize != 0 {
+		return nil, fmt.Errorf("%w: %s has a partial entry", errCorruptIndex, path)
+	}
+
+	index := &timeIndex{path: path, baseOffset: baseOffset}
+	for position := 0; position < len(data); position += timeIndexEntrySize {
+		entry := timeIndexEntry{
+			timestamp:      int64(binary.BigEndian.Uint64(data[position:])),
+			relativeOffset: int32(binary.BigEndian.Uint32(data[position+8:])),
+		}
+		// Kafka preallocates index files; the zeroed tail is not part of the index
+		if entry == (timeIndexEntry{}) && allZero(data[position:]) {
+			break
+		}
+		if entry.relativeOffset < 0 {
+			return nil, fmt.Errorf("%w: %s points before the segment", errCorruptIndex, path)
+		}
+		if last, ok := index.last(); ok && (entry.timestamp <= last.timestamp || entry.relativeOffset < last.relativeOffset) {
+			return nil, fmt.Errorf("%w: %s entries are out of order", errCorruptIndex, path)
+		}
+		index.entries = append(index.entries, entry)
+	}
+	return index, nil
+}
+
+func (i *timeIndex) last() (timeIndexEntry, bool) {
+	if len(i.entries) == 0 {
+		return timeIndexEntry{}, false
+	}
+	return i.entries[len(i.entries)-1], true
+}
+
+// lookup returns an offset at or before the first record with a timestamp of at least
+// timestamp: every batch up to the returned offset only holds earlier timestamps
+func (i *timeIndex) lookup(timestamp int64) int64 {
+	n := sort.Search(len(i.entries), func(j int) bool {
+		return i.entries[j].timestamp >= timestamp
+	})
+	if n == 0 {
+		return i.baseOffset
+	}
+	return i.baseOffset + int64(i.entries[n-1].relativeOffset)
+}
+
+// maybeAppend records timestamp at offset if it is larger than the last indexed timestamp
+func (i *timeIndex) maybeAppend(timestamp int64, offset int64) error {
+	if last, ok := i.last(); ok && timestamp <= last.timestamp {
+		return nil
+	}
+	entry := timeIndexEntry{timestamp: timestamp, relativeOffset: int32(offset - i.baseOffset)}
+	if err := appendToFile(i.path, entry.appendTo(nil)); err != nil {
+		return err
+	}
+	i.entries = append(i.entries, entry)
+	return nil
+}
+
+// write replaces the .timeindex file with the in-memory entries
+func (i *timeIndex) write() error {
+	data := make([]byte, 0, len(i.entries)*timeIndexEntrySize)
+	for _, entry := range i.entries {
+		data = entry.appendTo(data)
+	}
+	return os.WriteFile(i.path, data, 0o644)
+}
@@ -0,0 +1,103 @@
+package partition_file_repository
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+)
+
+const (
+	// txnIndexEntrySize is the size of a .txnindex entry: version (INT16), producer ID
+	// (INT64), first offset (INT64), last offset (INT64) and last stable offset (INT64)
+	txnIndexEntrySize = 34
+	// txnIndexEntryVersion is the version of Kafka's aborted transaction entries
+	txnIndexEntryVersion int16 = 0
+)
+
+// abortedTxn is a transaction whose ABORT marker was written to the segment
+type abortedTxn struct {
+	producerID       int64
+	firstOffset      int64 // Offset of the transaction's first batch in the partition
+	lastOffset       int64 // Offset of the ABORT marker
+	lastStableOffset int64 // Last stable offset of the partition once the marker was written
+}
+
+func (t abortedTxn) appendTo(data []byte) []byte {
+	data = binary.BigEndian.AppendUint16(data, uint16(txnIndexEntryVersion))
+	data = binary.BigEndian.AppendUint64(data, uint64(t.producerID))
+	data = binary.BigEndian.AppendUint64(data, uint64(t.firstOffset))
+	data = binary.BigEndian.AppendUint64(data, uint64(t.lastOffset))
+	return binary.BigEndian.AppendUint64(data, uint64(t.lastStableOffset))
+}
+
+// txnIndex lists the transactions aborted in a segment, in the order of their markers,
+// kept in memory and mirrored to a Kafka-compatible .txnindex file. Consumers reading
+// committed data get the aborted transactions overlapping what they fetch so they can
+// drop those batches.
+type txnIndex struct {
+	path    string
+	entries []abortedTxn
+}
+
+// load reads the .txnindex file, which only exists once a transaction was aborted in the
+// segment. Entries for markers past nextOffset, torn off the end of the segment, and
+// unreadable ones are dropped; the producer state replay indexes them again.
+func (i *txnIndex) load(nextOffset int64) error {
+	i.entries = nil
+	data, err := os.ReadFile(i.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	valid := 0
+	for position := 0; position+txnIndexEntrySize <= len(data); position += txnIndexEntrySize {
+		if version := int16(binary.BigEndian.Uint16(data[position:])); version != txnIndexEntryVersion {
+			break
+		}
+		entry := abortedTxn{
+			producerID:       int64(binary.BigEndian.Uint64(data[position+2:])),
+			firstOffset:      int64(binary.BigEndian.Uint64(data[position+10:])),
+			lastOffset:       int64(binary.BigEndian.Uint64(data[position+18:])),
+			lastStableOffset: int64(binary.BigEndian.Uint64(data[position+26:])),
+		}
+		if entry.lastOffset >= nextOffset {
+			break
+		}
+		i.entries = append(i.entries, entry)
+		valid = position + txnIndexEntrySize
+	}
+	if valid < len(data) {
+		fmt.Printf("Truncating %s from %d to %d bytes\n", i.path, len(data), valid)
+		return os.Truncate(i.path, int64(valid))
+	}
+	return nil
+}
+
+// append records an aborted transaction unless its marker is already indexed, as happens
+// when the log is replayed over markers written before a restart
+func (i *txnIndex) append(txn abortedTxn) error {
+	if len(i.entries) > 0 && txn.lastOffset <= i.entries[len(i.entries)-1].lastOffset {
+		return nil
+	}
+	if err := appendToFile(i.path, txn.appendTo(nil)); err != nil {
+		return err
+	}
+	i.entries = append(i.entries, txn)
+	return nil
+}
+
+// collect appends the aborted transactions with records in [fetchOffset, upperBound)
+func (i *txnIndex) collect(fetchOffset int64, upperBound int64, aborted []domain.AbortedTransaction) []domain.AbortedTransaction {
+	for _, entry := range i.entries {
+		if entry.lastOffset >= fetchOffset && entry.firstOffset < upperBound {
+			aborted = append(aborted, domain.AbortedTransaction{ProducerID: entry.producerID, FirstOffset: entry.firstOffset})
+		}
+	}
+	return aborted
+}
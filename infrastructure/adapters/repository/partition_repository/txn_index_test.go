@@ -0,0 +1,90 @@
+package partition_file_repository
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// transactionalBatchOf is a batch of records records of producer 4000 within a transaction
+func transactionalBatchOf(epoch int16, sequence int32, records int) []byte {
+	batch, _ := common.DecodeRecordBatch(producerBatchOf(epoch, sequence, records))
+	batch.Attributes |= common.TransactionalAttributeFlag
+	return common.EncodeRecordBatch(batch)
+}
+
+func readCommitted(t *testing.T, repo *PartitionFileRepository, fetchOffset int64) domain.ReadResult {
+	t.Helper()
+	result, err := repo.ReadRecordBatches(domain.ReadRequest{
+		TopicName:      "foo",
+		FetchOffset:    fetchOffset,
+		MaxBytes:       1 << 20,
+		IsolationLevel: domain.IsolationLevelReadCommitted,
+	})
+	if err != nil {
+		t.Fatalf("ReadRecordBatches(%d) error = %v", fetchOffset, err)
+	}
+	return result
+}
+
+func batchBaseOffsets(records []byte) []int64 {
+	baseOffsets := []int64{}
+	batches, _ := common.SplitRecordBatches(records)
+	for _, batch := range batches {
+		header, _ := common.ReadRecordBatchHeader(batch)
+		baseOffsets = append(baseOffsets, header.BaseOffset)
+	}
+	return baseOffsets
+}
+
+func TestPartitionFileRepository_ReadCommitted(t *testing.T) {
+	logDir := t.TempDir()
+	repo := NewPartitionFileRepositoryWithLogDir(logDir)
+	appendTo(repo, transactionalBatchOf(0, 0, 2))
+	appendTo(repo, testRecordBatch("a"))
+
+	// The open transaction holds back everything after its first batch
+	open := readCommitted(t, repo, 0)
+	if len(open.Records) != 0 || open.LastStableOffset != 0 || open.HighWatermark != 3 {
+		t.Errorf("read with an open transaction = %d bytes, LSO %d, HW %d, want nothing, LSO 0, HW 3", len(open.Records), open.LastStableOffset, open.HighWatermark)
+	}
+	if offsets, _ := repo.GetLogOffsets("foo", 0); offsets.LastStableOffset != 0 {
+		t.Errorf("GetLogOffsets().LastStableOffset = %d, want 0", offsets.LastStableOffset)
+	}
+
+	repo.WriteTxnMarker(domain.TxnMarkerAppend{TopicName: "foo", ProducerID: 4000, ProducerEpoch: 0})
+	wantAborted := []domain.AbortedTransaction{{ProducerID: 4000, FirstOffset: 0}}
+	aborted := readCommitted(t, repo, 0)
+	if got := batchBaseOffsets(aborted.Records); !reflect.DeepEqual(got, []int64{0, 2, 3}) || aborted.LastStableOffset != 4 {
+		t.Errorf("read after the abort = batches %v, LSO %d, want [0 2 3], LSO 4", got, aborted.LastStableOffset)
+	}
+	if !reflect.DeepEqual(aborted.AbortedTransactions, wantAborted) {
+		t.Errorf("AbortedTransactions = %+v, want %+v", aborted.AbortedTransactions, wantAborted)
+	}
+	uncommitted, _ := repo.ReadRecordBatches(domain.ReadRequest{TopicName: "foo", MaxBytes: 1 << 20})
+	if uncommitted.AbortedTransactions != nil {
+		t.Errorf("read_uncommitted AbortedTransactions = %+v, want none", uncommitted.AbortedTransactions)
+	}
+
+	// A committed transaction after the aborted one lists no aborted transaction
+	appendTo(repo, transactionalBatchOf(0, 2, 1))
+	repo.WriteTxnMarker(domain.TxnMarkerAppend{TopicName: "foo", ProducerID: 4000, ProducerEpoch: 0, Committed: true})
+	if committed := readCommitted(t, repo, 4); len(committed.AbortedTransactions) != 0 || committed.LastStableOffset != 6 {
+		t.Errorf("read of the committed transaction = aborted %+v, LSO %d, want none, LSO 6", committed.AbortedTransactions, committed.LastStableOffset)
+	}
+
+	// Replaying the log after a restart neither duplicates nor loses the index entries
+	restarted := NewPartitionFileRepositoryWithLogDir(logDir)
+	if got := readCommitted(t, restarted, 0).AbortedTransactions; !reflect.DeepEqual(got, wantAborted) {
+		t.Errorf("AbortedTransactions after restarting = %+v, want %+v", got, wantAborted)
+	}
+	os.Remove(filepath.Join(logDir, "foo-0", segmentFileName(0, txnIndexFileSuffix)))
+	rebuilt := NewPartitionFileRepositoryWithLogDir(logDir)
+	if got := readCommitted(t, rebuilt, 0).AbortedTransactions; !reflect.DeepEqual(got, wantAborted) {
+		t.Errorf("AbortedTransactions after losing the index = %+v, want %+v", got, wantAborted)
+	}
+}
@@ -0,0 +1,108 @@
+package transaction_state_repository
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+// Versions of the __transaction_state record keys and values
+// (https://github.com/apache/kafka/tree/trunk/transaction-coordinator/src/main/resources/common/message)
+const (
+	transactionLogKeyVersion = 0
+	// transactionLogValueVersion is the last version without tagged fields
+	transactionLogValueVersion = 0
+)
+
+func encodeTransactionLogKey(transactionalID string) []byte {
+	w := common.NewKafkaWriter()
+	w.Int16(transactionLogKeyVersion)
+	w.String(transactionalID, false)
+	return w.Bytes()
+}
+
+func decodeTransactionLogKey(data []byte) (string, error) {
+	reader := common.NewKafkaReader(data, 0)
+	version := reader.Int16("key version")
+	transactionalID := reader.String("transactional id", false)
+	if err := reader.Err(); err != nil {
+		return "", err
+	}
+	if version != transactionLogKeyVersion {
+		return "", fmt.Errorf("unknown transaction log key version %d", version)
+	}
+	return transactionalID, nil
+}
+
+// encodeTransactionLogValue writes the partitions grouped by topic, null once the
+// transaction has none
+func encodeTransactionLogValue(txn domain.TransactionMetadata) []byte {
+	w := common.NewKafkaWriter()
+	w.Int16(transactionLogValueVersion)
+	w.Int64(txn.ProducerID)
+	w.Int16(txn.ProducerEpoch)
+	w.Int32(txn.TimeoutMs)
+	w.Int8(int8(txn.State))
+
+	partitions := slices.SortedFunc(slices.Values(txn.Partitions), func(a, b domain.TopicPartition) int {
+		if a.Topic != b.Topic {
+			return strings.Compare(a.Topic, b.Topic)
+		}
+		return cmp.Compare(a.Partition, b.Partition)
+	})
+	topics := [][]domain.TopicPartition{}
+	for _, tp := range partitions {
+		if len(topics) == 0 || topics[len(topics)-1][0].Topic != tp.Topic {
+			topics = append(topics, nil)
+		}
+		topics[len(topics)-1] = append(topics[len(topics)-1], tp)
+	}
+	if len(topics) == 0 {
+		w.ArrayLength(-1, false)
+	} else {
+		w.ArrayLength(len(topics), false)
+	}
+	for _, topic := range topics {
+		w.String(topic[0].Topic, false)
+		w.ArrayLength(len(topic), false)
+		for _, tp := range topic {
+			w.Int32(tp.Partition)
+		}
+	}
+
+	w.Int64(txn.LastUpdateTimestamp)
+	w.Int64(txn.StartTimestamp)
+	return w.Bytes()
+}
+
+func decodeTransactionLogValue(transactionalID string, data []byte) (domain.TransactionMetadata, error) {
+	reader := common.NewKafkaReader(data, 0)
+	version := reader.Int16("value version")
+	if err := reader.Err(); err != nil {
+		return domain.TransactionMetadata{}, err
+	}
+	if version != transactionLogValueVersion {
+		return domain.TransactionMetadata{}, fmt.Errorf("unknown transaction log value version %d", version)
+	}
+	txn := domain.TransactionMetadata{
+		TransactionalID: transactionalID,
+		ProducerID:      reader.Int64("producer id"),
+		ProducerEpoch:   reader.Int16("producer epoch"),
+		TimeoutMs:       reader.Int32("transaction timeout"),
+		State:           domain.TransactionState(reader.Int8("transaction status")),
+	}
+	topicsLength := reader.ArrayLength("transaction partitions", false)
+	for range topicsLength {
+		topic := reader.String("topic", false)
+		for _, partition := range reader.Int32Array("partition ids", false) {
+			txn.Partitions = append(txn.Partitions, domain.TopicPartition{Topic: topic, Partition: partition})
+		}
+	}
+	txn.LastUpdateTimestamp = reader.Int64("last update timestamp")
+	txn.StartTimestamp = reader.Int64("start timestamp")
+	return txn, reader.Err()
+}
@@ -0,0 +1,156 @@
+package transaction_state_repository
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf16"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	port_repo "github.com/codecrafters-io/kafka-starter-go/core/ports/repository/partition_file_repository"
+	"github.com/codecrafters-io/kafka-starter-go/infrastructure/common"
+)
+
+const (
+	// TransactionStateTopic is the internal topic the state of transactions is kept in
+	TransactionStateTopic = domain.TransactionStateTopic
+	// DefaultPartitionCount matches Kafka's transaction.state.log.num.partitions
+	DefaultPartitionCount = 50
+
+	// loadMaxBytes is how much of a partition is read at a time while loading
+	loadMaxBytes = 1 << 20
+)
+
+// TransactionStateRepository writes the state of transactional IDs to the partitions of
+// the __transaction_state topic in Kafka's record format, a transactional ID always
+// landing in the same partition. The partitions are plain partition logs; the topic isn't
+// part of the cluster metadata.
+type TransactionStateRepository struct {
+	partitions     port_repo.PartitionFileRepository
+	partitionCount int
+}
+
+// TransactionStateRepositoryOption configures a TransactionStateRepository
+type TransactionStateRepositoryOption func(*TransactionStateRepository)
+
+// WithPartitionCount sets how many partitions the transactional IDs are spread over.
+// Changing it moves transactional IDs to other partitions, losing their state.
+func WithPartitionCount(partitionCount int) TransactionStateRepositoryOption {
+	return func(r *TransactionStateRepository) {
+		r.partitionCount = partitionCount
+	}
+}
+
+func NewTransactionStateRepository(partitions port_repo.PartitionFileRepository, opts ...TransactionStateRepositoryOption) *TransactionStateRepository {
+	r := &TransactionStateRepository{
+		partitions:     partitions,
+		partitionCount: DefaultPartitionCount,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// PartitionFor returns the partition holding the state of a transactional ID, computed
+// from the Java hash code of the ID like Kafka does
+func (r *TransactionStateRepository) PartitionFor(transactionalID string) int {
+	hash := int32(0)
+	for _, unit := range utf16.Encode([]rune(transactionalID)) {
+		hash = 31*hash + int32(unit)
+	}
+	return int(hash&0x7fffffff) % r.partitionCount
+}
+
+// StoreTransaction appends a TransactionLog record in a batch of its own
+func (r *TransactionStateRepository) StoreTransaction(txn domain.TransactionMetadata) error {
+	timestamp := time.Now().UnixMilli()
+	batch := common.RecordBatch{Records: []common.Record{{
+		Key:   encodeTransactionLogKey(txn.TransactionalID),
+		Value: encodeTransactionLogValue(txn),
+	}}}
+	batch.BaseTimestamp = timestamp
+	batch.MaxTimestamp = timestamp
+	batch.ProducerID = -1
+	batch.ProducerEpoch = -1
+	batch.BaseSequence = -1
+
+	_, err := r.partitions.AppendRecordBatches(domain.AppendRequest{
+		TopicName:      TransactionStateTopic,
+		PartitionIndex: r.PartitionFor(txn.TransactionalID),
+		Records:        common.EncodeRecordBatch(batch),
+	})
+	return err
+}
+
+// LoadTransactions replays every partition from its start; the last record of a
+// transactional ID wins and a tombstone removes it
+func (r *TransactionStateRepository) LoadTransactions() (map[string]domain.TransactionMetadata, error) {
+	transactions := make(map[string]domain.TransactionMetadata)
+	for partitionIndex := range r.partitionCount {
+		if err := r.loadPartition(partitionIndex, transactions); err != nil {
+			return nil, fmt.Errorf("loading %s-%d: %w", TransactionStateTopic, partitionIndex, err)
+		}
+	}
+	return transactions, nil
+}
+
+func (r *TransactionStateRepository) loadPartition(partitionIndex int, transactions map[string]domain.TransactionMetadata) error {
+	logOffsets, err := r.partitions.GetLogOffsets(TransactionStateTopic, partitionIndex)
+	if err != nil {
+		return err
+	}
+
+	offset := logOffsets.LogStartOffset
+	for offset < logOffsets.HighWatermark {
+		read, err := r.partitions.ReadRecordBatches(domain.ReadRequest{
+			TopicName:      TransactionStateTopic,
+			PartitionIndex: partitionIndex,
+			FetchOffset:    offset,
+			MaxBytes:       loadMaxBytes,
+			MinOneBatch:    true,
+		})
+		if err != nil {
+			return err
+		}
+		batches, err := common.SplitRecordBatches(read.Records)
+		if err != nil {
+			return err
+		}
+		if len(batches) == 0 {
+			break
+		}
+		for _, data := range batches {
+			batch, err := common.DecodeRecordBatch(data)
+			if err != nil {
+				return err
+			}
+			for _, record := range batch.Records {
+				if batch.IsControl() || batch.BaseOffset+int64(record.OffsetDelta) < offset {
+					continue
+				}
+				if err := applyRecord(record, transactions); err != nil {
+					return err
+				}
+			}
+			offset = batch.NextOffset()
+		}
+	}
+	return nil
+}
+
+func applyRecord(record common.Record, transactions map[string]domain.TransactionMetadata) error {
+	transactionalID, err := decodeTransactionLogKey(record.Key)
+	if err != nil {
+		return err
+	}
+	if record.Value == nil {
+		delete(transactions, transactionalID)
+		return nil
+	}
+	txn, err := decodeTransactionLogValue(transactionalID, record.Value)
+	if err != nil {
+		return err
+	}
+	transactions[transactionalID] = txn
+	return nil
+}
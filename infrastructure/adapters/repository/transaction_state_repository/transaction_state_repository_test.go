@@ -0,0 +1,54 @@
+package transaction_state_repository
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/core/domain"
+	partition_file_repository "github.com/codecrafters-io/kafka-starter-go/infrastructure/adapters/repository/partition_repository"
+)
+
+func TestTransactionStateRepository_PartitionFor(t *testing.T) {
+	r := NewTransactionStateRepository(partition_file_repository.NewPartitionFileRepositoryWithLogDir(t.TempDir()))
+	// abs(String.hashCode()) % 50, as Kafka computes it
+	for transactionalID, want := range map[string]int{"": 0, "group-a": 47} {
+		if got := r.PartitionFor(transactionalID); got != want {
+			t.Errorf("PartitionFor(%q) = %d, want %d", transactionalID, got, want)
+		}
+	}
+}
+
+func TestTransactionStateRepository_StoreAndLoad(t *testing.T) {
+	logDir := t.TempDir()
+	r := NewTransactionStateRepository(partition_file_repository.NewPartitionFileRepositoryWithLogDir(logDir))
+
+	empty := domain.TransactionMetadata{
+		TransactionalID: "txn-1", ProducerID: 4000, ProducerEpoch: 0, TimeoutMs: 60000,
+		State: domain.TransactionStateEmpty, StartTimestamp: -1, LastUpdateTimestamp: 1000,
+	}
+	ongoing := empty
+	ongoing.State = domain.TransactionStateOngoing
+	ongoing.Partitions = []domain.TopicPartition{{Topic: "orders", Partition: 1}, {Topic: "audit", Partition: 0}, {Topic: "orders", Partition: 0}}
+	ongoing.StartTimestamp = 2000
+	ongoing.LastUpdateTimestamp = 2000
+	other := domain.TransactionMetadata{
+		TransactionalID: "txn-2", ProducerID: 4001, ProducerEpoch: 3, TimeoutMs: 1000,
+		State: domain.TransactionStateCompleteAbort, StartTimestamp: 500, LastUpdateTimestamp: 900,
+	}
+	for _, txn := range []domain.TransactionMetadata{empty, ongoing, other} {
+		if err := r.StoreTransaction(txn); err != nil {
+			t.Fatalf("StoreTransaction() error = %v", err)
+		}
+	}
+
+	// A fresh repository reads what the first one wrote, partitions sorted
+	got, err := NewTransactionStateRepository(partition_file_repository.NewPartitionFileRepositoryWithLogDir(logDir)).LoadTransactions()
+	if err != nil {
+		t.Fatalf("LoadTransactions() error = %v", err)
+	}
+	ongoing.Partitions = []domain.TopicPartition{{Topic: "audit", Partition: 0}, {Topic: "orders", Partition: 0}, {Topic: "orders", Partition: 1}}
+	want := map[string]domain.TransactionMetadata{"txn-1": ongoing, "txn-2": other}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadTransactions() = %+v, want %+v", got, want)
+	}
+}
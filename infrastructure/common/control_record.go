@@ -0,0 +1,63 @@
+package common
+
+import "fmt"
+
+// Control record types, the marker a transaction ends with
+// (https://kafka.apache.org/documentation/#controlbatch)
+const (
+	ControlRecordTypeAbort  int16 = 0
+	ControlRecordTypeCommit int16 = 1
+)
+
+// controlRecordVersion is the version of the control record key and of the end
+// transaction marker value
+const controlRecordVersion int16 = 0
+
+// ControlRecord is the single record of a control batch ending a transaction
+type ControlRecord struct {
+	Type             int16 // ControlRecordTypeAbort or ControlRecordTypeCommit
+	CoordinatorEpoch int32
+}
+
+// EncodeControlBatch serializes the control batch a transaction coordinator writes to
+// end a producer's transaction in a partition
+func EncodeControlBatch(producerID int64, producerEpoch int16, timestamp int64, marker ControlRecord) []byte {
+	key := NewKafkaWriter()
+	key.Int16(controlRecordVersion)
+	key.Int16(marker.Type)
+	value := NewKafkaWriter()
+	value.Int16(controlRecordVersion)
+	value.Int32(marker.CoordinatorEpoch)
+
+	batch := RecordBatch{Records: []Record{{Key: key.Bytes(), Value: value.Bytes()}}}
+	batch.Attributes = TransactionalAttributeFlag | ControlBatchAttributeFlag
+	batch.BaseTimestamp = timestamp
+	batch.MaxTimestamp = timestamp
+	batch.ProducerID = producerID
+	batch.ProducerEpoch = producerEpoch
+	batch.BaseSequence = -1
+	return EncodeRecordBatch(batch)
+}
+
+// DecodeControlRecord decodes the marker of a decoded control batch
+func DecodeControlRecord(batch RecordBatch) (ControlRecord, error) {
+	if !batch.IsControl() || len(batch.Records) != 1 {
+		return ControlRecord{}, fmt.Errorf("%w: not a control batch", ErrCorruptRecordBatch)
+	}
+	key := NewKafkaReader(batch.Records[0].Key, 0)
+	key.Int16("control record version")
+	marker := ControlRecord{Type: key.Int16("control record type")}
+	if err := key.Err(); err != nil {
+		return ControlRecord{}, fmt.Errorf("%w: %v", ErrCorruptRecordBatch, err)
+	}
+	if marker.Type != ControlRecordTypeAbort && marker.Type != ControlRecordTypeCommit {
+		return marker, nil
+	}
+	value := NewKafkaReader(batch.Records[0].Value, 0)
+	value.Int16("end transaction marker version")
+	marker.CoordinatorEpoch = value.Int32("coordinator epoch")
+	if err := value.Err(); err != nil {
+		return ControlRecord{}, fmt.Errorf("%w: %v", ErrCorruptRecordBatch, err)
+	}
+	return marker, nil
+}
@@ -9,33 +9,9 @@ func IntToVarInt(value int) []byte {
 	return uint64ToVarInt(uint64(value))
 }
 
-// uint64ToVarInt converts a uint64 to varint-encoded bytes
+// uint64ToVarInt converts a uint64 to varint-encoded bytes, least significant group first
 func uint64ToVarInt(value uint64) []byte {
-	if value == 0 {
-		return []byte{0x00}
-	}
-
-	var result []byte
-	for value > 0 {
-		// Extract the lower 7 bits
-		byteValue := byte(value & 0x7f)
-		value >>= 7
-
-		// If there are more bytes to follow, set the MSB
-		if value > 0 {
-			byteValue |= 0x80
-		}
-
-		result = append(result, byteValue)
-	}
-
-	// Reverse the result because varints are encoded with most significant byte first
-	// (readVarInt reads the first byte and shifts left, so first byte is most significant)
-	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
-		result[i], result[j] = result[j], result[i]
-	}
-
-	return result
+	return binary.AppendUvarint(nil, value)
 }
 
 // bytesToVarInt converts an array of bytes (representing an integer) to varint-encoded bytes.
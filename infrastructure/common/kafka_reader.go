@@ -0,0 +1,223 @@
+package common
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// KafkaReader reads Kafka protocol primitives from a byte slice.
+// The first read that runs past the end of the data records an error; every read after
+// that is a no-op returning zero values, so parsers can read a whole message and check
+// Err() once at the end instead of bounds-checking every field.
+type KafkaReader struct {
+	data   []byte
+	offset int
+	err    error
+}
+
+// NewKafkaReader creates a reader starting at the given offset
+func NewKafkaReader(data []byte, offset int) *KafkaReader {
+	return &KafkaReader{data: data, offset: offset}
+}
+
+// Err returns the first error encountered while reading, if any
+func (r *KafkaReader) Err() error {
+	return r.err
+}
+
+// Offset returns the current read position
+func (r *KafkaReader) Offset() int {
+	return r.offset
+}
+
+// Remaining returns the number of unread bytes
+func (r *KafkaReader) Remaining() int {
+	if r.err != nil || r.offset >= len(r.data) {
+		return 0
+	}
+	return len(r.data) - r.offset
+}
+
+func (r *KafkaReader) take(n int, field string) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.offset+n > len(r.data) {
+		r.err = fmt.Errorf("insufficient data for field '%s' at offset %d", field, r.offset)
+		return nil
+	}
+	b := r.data[r.offset : r.offset+n]
+	r.offset += n
+	return b
+}
+
+func (r *KafkaReader) Int8(field string) int8 {
+	b := r.take(1, field)
+	if b == nil {
+		return 0
+	}
+	return int8(b[0])
+}
+
+func (r *KafkaReader) Bool(field string) bool {
+	return r.Int8(field) != 0
+}
+
+func (r *KafkaReader) Int16(field string) int16 {
+	b := r.take(2, field)
+	if b == nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(b))
+}
+
+func (r *KafkaReader) Int32(field string) int32 {
+	b := r.take(4, field)
+	if b == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(b))
+}
+
+func (r *KafkaReader) Int64(field string) int64 {
+	b := r.take(8, field)
+	if b == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(b))
+}
+
+// UUID reads a 16 byte UUID
+func (r *KafkaReader) UUID(field string) []byte {
+	return r.take(16, field)
+}
+
+// UnsignedVarInt reads an unsigned varint
+func (r *KafkaReader) UnsignedVarInt(field string) int {
+	if r.err != nil {
+		return 0
+	}
+	value, bytesRead := ReadVarIntUnsigned(r.offset, r.data)
+	if bytesRead == 0 {
+		r.err = fmt.Errorf("insufficient data for field '%s' at offset %d", field, r.offset)
+		return 0
+	}
+	r.offset += bytesRead
+	return value
+}
+
+// VarInt reads a zigzag encoded signed varint
+func (r *KafkaReader) VarInt(field string) int {
+	if r.err != nil {
+		return 0
+	}
+	value, bytesRead := ReadVarIntSigned(r.offset, r.data)
+	if bytesRead == 0 {
+		r.err = fmt.Errorf("insufficient data for field '%s' at offset %d", field, r.offset)
+		return 0
+	}
+	r.offset += bytesRead
+	return value
+}
+
+// ArrayLength reads an array length, INT32 for classic versions and
+// UNSIGNED_VARINT (length + 1) for flexible versions. Null arrays are returned as -1.
+func (r *KafkaReader) ArrayLength(field string, compact bool) int {
+	var length int
+	if compact {
+		length = r.UnsignedVarInt(field) - 1
+	} else {
+		length = int(r.Int32(field))
+	}
+	if r.err == nil && length > r.Remaining() {
+		r.err = fmt.Errorf("invalid length %d for field '%s' at offset %d", length, field, r.offset)
+		return 0
+	}
+	return length
+}
+
+// NullableString reads a STRING/COMPACT_STRING that may be null
+func (r *KafkaReader) NullableString(field string, compact bool) (string, bool) {
+	var length int
+	if compact {
+		length = r.UnsignedVarInt(field) - 1
+	} else {
+		length = int(r.Int16(field))
+	}
+	if r.err != nil || length < 0 {
+		return "", false
+	}
+	return string(r.take(length, field)), true
+}
+
+// String reads a STRING/COMPACT_STRING, treating null as the empty string
+func (r *KafkaReader) String(field string, compact bool) string {
+	s, _ := r.NullableString(field, compact)
+	return s
+}
+
+// Bytes reads BYTES/COMPACT_BYTES (also used for RECORDS), returning nil for null
+func (r *KafkaReader) Bytes(field string, compact bool) []byte {
+	var length int
+	if compact {
+		length = r.UnsignedVarInt(field) - 1
+	} else {
+		length = int(r.Int32(field))
+	}
+	if r.err != nil || length < 0 {
+		return nil
+	}
+	return r.take(length, field)
+}
+
+// Int32Array reads an array of INT32
+func (r *KafkaReader) Int32Array(field string, compact bool) []int32 {
+	length := r.ArrayLength(field, compact)
+	if length < 0 {
+		return nil
+	}
+	values := make([]int32, 0, length)
+	for range length {
+		values = append(values, r.Int32(field))
+	}
+	return values
+}
+
+// StringArray reads an array of STRING/COMPACT_STRING
+func (r *KafkaReader) StringArray(field string, compact bool) []string {
+	length := r.ArrayLength(field, compact)
+	if length < 0 {
+		return nil
+	}
+	values := make([]string, 0, length)
+	for range length {
+		values = append(values, r.String(field, compact))
+	}
+	return values
+}
+
+// TaggedFields reads a tagged field section, returning the raw data of every tag
+func (r *KafkaReader) TaggedFields(field string) map[int][]byte {
+	numTaggedFields := r.UnsignedVarInt(field)
+	if r.err == nil && numTaggedFields > r.Remaining() {
+		r.err = fmt.Errorf("invalid tagged field count %d for field '%s' at offset %d", numTaggedFields, field, r.offset)
+		return nil
+	}
+	tags := make(map[int][]byte, numTaggedFields)
+	for range numTaggedFields {
+		tag := r.UnsignedVarInt("tag")
+		size := r.UnsignedVarInt("tag size")
+		tags[tag] = r.take(size, "tag data")
+	}
+	return tags
+}
+
+// SkipTaggedFields skips over a tagged field section of a flexible version message
+func (r *KafkaReader) SkipTaggedFields() {
+	numTaggedFields := r.UnsignedVarInt("tagged fields")
+	for range numTaggedFields {
+		r.UnsignedVarInt("tag")
+		size := r.UnsignedVarInt("tag size")
+		r.take(size, "tag data")
+	}
+}
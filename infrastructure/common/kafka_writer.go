@@ -0,0 +1,153 @@
+package common
+
+import (
+	"encoding/binary"
+)
+
+// KafkaWriter builds Kafka protocol messages field by field
+type KafkaWriter struct {
+	data []byte
+}
+
+// NewKafkaWriter creates an empty writer
+func NewKafkaWriter() *KafkaWriter {
+	return &KafkaWriter{data: []byte{}}
+}
+
+// Bytes returns everything written so far
+func (w *KafkaWriter) Bytes() []byte {
+	return w.data
+}
+
+// Len returns the number of bytes written so far
+func (w *KafkaWriter) Len() int {
+	return len(w.data)
+}
+
+// Raw appends bytes as-is
+func (w *KafkaWriter) Raw(b []byte) {
+	w.data = append(w.data, b...)
+}
+
+func (w *KafkaWriter) Int8(v int8) {
+	w.data = append(w.data, byte(v))
+}
+
+func (w *KafkaWriter) Bool(v bool) {
+	if v {
+		w.Int8(1)
+		return
+	}
+	w.Int8(0)
+}
+
+func (w *KafkaWriter) Int16(v int16) {
+	w.data = binary.BigEndian.AppendUint16(w.data, uint16(v))
+}
+
+func (w *KafkaWriter) Int32(v int32) {
+	w.data = binary.BigEndian.AppendUint32(w.data, uint32(v))
+}
+
+func (w *KafkaWriter) Int64(v int64) {
+	w.data = binary.BigEndian.AppendUint64(w.data, uint64(v))
+}
+
+// UUID appends a 16 byte UUID, writing the zero UUID when the slice is empty
+func (w *KafkaWriter) UUID(uuid []byte) {
+	if len(uuid) != 16 {
+		w.data = append(w.data, make([]byte, 16)...)
+		return
+	}
+	w.data = append(w.data, uuid...)
+}
+
+// UnsignedVarInt appends a little-endian base 128 varint
+func (w *KafkaWriter) UnsignedVarInt(v int) {
+	w.data = binary.AppendUvarint(w.data, uint64(v))
+}
+
+// VarInt appends a zigzag encoded signed varint
+func (w *KafkaWriter) VarInt(v int64) {
+	w.data = binary.AppendVarint(w.data, v)
+}
+
+// ArrayLength appends an array length, INT32 for classic versions and
+// UNSIGNED_VARINT (length + 1) for flexible versions. Pass -1 for a null array.
+func (w *KafkaWriter) ArrayLength(length int, compact bool) {
+	if compact {
+		w.UnsignedVarInt(length + 1)
+		return
+	}
+	w.Int32(int32(length))
+}
+
+// String appends a STRING/COMPACT_STRING
+func (w *KafkaWriter) String(s string, compact bool) {
+	if compact {
+		w.UnsignedVarInt(len(s) + 1)
+	} else {
+		w.Int16(int16(len(s)))
+	}
+	w.data = append(w.data, s...)
+}
+
+// NullableString appends a nullable STRING/COMPACT_STRING, null when s is nil
+func (w *KafkaWriter) NullableString(s *string, compact bool) {
+	if s == nil {
+		if compact {
+			w.UnsignedVarInt(0)
+		} else {
+			w.Int16(-1)
+		}
+		return
+	}
+	w.String(*s, compact)
+}
+
+// BytesField appends BYTES/COMPACT_BYTES (also used for RECORDS), null when b is nil
+func (w *KafkaWriter) BytesField(b []byte, compact bool) {
+	if b == nil {
+		if compact {
+			w.UnsignedVarInt(0)
+		} else {
+			w.Int32(-1)
+		}
+		return
+	}
+	if compact {
+		w.UnsignedVarInt(len(b) + 1)
+	} else {
+		w.Int32(int32(len(b)))
+	}
+	w.data = append(w.data, b...)
+}
+
+// Int32Array appends an array of INT32
+func (w *KafkaWriter) Int32Array(values []int32, compact bool) {
+	w.ArrayLength(len(values), compact)
+	for _, v := range values {
+		w.Int32(v)
+	}
+}
+
+// StringArray appends an array of STRING/COMPACT_STRING
+func (w *KafkaWriter) StringArray(values []string, compact bool) {
+	w.ArrayLength(len(values), compact)
+	for _, v := range values {
+		w.String(v, compact)
+	}
+}
+
+// EmptyTaggedFields appends an empty tagged field section
+func (w *KafkaWriter) EmptyTaggedFields() {
+	w.UnsignedVarInt(0)
+}
+
+// WithSizePrefix returns the written bytes prefixed with their INT32 length,
+// the framing every Kafka response is sent with
+func (w *KafkaWriter) WithSizePrefix() []byte {
+	framed := make([]byte, 4, 4+len(w.data))
+	binary.BigEndian.PutUint32(framed, uint32(len(w.data)))
+	return append(framed, w.data...)
+}
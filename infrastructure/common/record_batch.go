@@ -0,0 +1,301 @@
+package common
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// RecordBatch v2 layout (https://kafka.apache.org/documentation/#recordbatch):
+// - Base Offset (8 bytes)
+// - Batch Length (4 bytes)
+// - Partition Leader Epoch (4 bytes)
+// - Magic Byte (1 byte)
+// - CRC (4 bytes, CRC-32C of everything from Attributes to the end of the batch)
+// - Attributes (2 bytes)
+// - Last Offset Delta (4 bytes)
+// - Base Timestamp (8 bytes)
+// - Max Timestamp (8 bytes)
+// - Producer ID (8 bytes)
+// - Producer Epoch (2 bytes)
+// - Base Sequence (4 bytes)
+// - Records Length (4 bytes) - number of records
+// - Records (variable)
+const (
+	batchLengthOffset          = 8
+	partitionLeaderEpochOffset = 12
+	magicOffset                = 16
+	crcOffset                  = 17
+	attributesOffset           = 21
+	lastOffsetDeltaOffset      = 23
+	baseTimestampOffset        = 27
+	maxTimestampOffset         = 35
+	producerIdOffset           = 43
+	producerEpochOffset        = 51
+	baseSequenceOffset         = 53
+	recordsCountOffset         = 57
+
+	// RecordBatchHeaderSize is the size of a RecordBatch up to and including Records Length
+	RecordBatchHeaderSize = 61
+	// RecordBatchLogOverhead is the Base Offset and Batch Length prefix not counted in Batch Length
+	RecordBatchLogOverhead = 12
+
+	RecordBatchMagic int8 = 2
+)
+
+// Attribute bits of a RecordBatch
+const (
+	CompressionCodecMask       int16 = 0x07
+	TimestampTypeLogAppendTime int16 = 0x08
+	TransactionalAttributeFlag int16 = 0x10
+	ControlBatchAttributeFlag  int16 = 0x20
+	DeleteHorizonAttributeFlag int16 = 0x40
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrCorruptRecordBatch is returned when a RecordBatch is truncated, has an unsupported
+// magic byte or fails its CRC check
+var ErrCorruptRecordBatch = errors.New("corrupt record batch")
+
+// RecordBatchHeader holds the fixed-size fields of a v2 RecordBatch
+type RecordBatchHeader struct {
+	BaseOffset           int64
+	BatchLength          int32
+	PartitionLeaderEpoch int32
+	Magic                int8
+	CRC                  uint32
+	Attributes           int16
+	LastOffsetDelta      int32
+	BaseTimestamp        int64
+	MaxTimestamp         int64
+	ProducerID           int64
+	ProducerEpoch        int16
+	BaseSequence         int32
+	RecordsCount         int32
+}
+
+// Size returns the number of bytes the whole batch occupies in a log
+func (h RecordBatchHeader) Size() int {
+	return RecordBatchLogOverhead + int(h.BatchLength)
+}
+
+// LastOffset returns the offset of the last record in the batch
+func (h RecordBatchHeader) LastOffset() int64 {
+	return h.BaseOffset + int64(h.LastOffsetDelta)
+}
+
+// NextOffset returns the offset following the last record in the batch
+func (h RecordBatchHeader) NextOffset() int64 {
+	return h.LastOffset() + 1
+}
+
+func (h RecordBatchHeader) IsTransactional() bool {
+	return h.Attributes&TransactionalAttributeFlag != 0
+}
+
+func (h RecordBatchHeader) IsControl() bool {
+	return h.Attributes&ControlBatchAttributeFlag != 0
+}
+
+func (h RecordBatchHeader) CompressionCodec() int16 {
+	return h.Attributes & CompressionCodecMask
+}
+
+// ReadRecordBatchHeader decodes the header of the RecordBatch at the start of data
+func ReadRecordBatchHeader(data []byte) (RecordBatchHeader, error) {
+	if len(data) < RecordBatchHeaderSize {
+		return RecordBatchHeader{}, fmt.Errorf("%w: %d bytes is shorter than a batch header", ErrCorruptRecordBatch, len(data))
+	}
+	return RecordBatchHeader{
+		BaseOffset:           int64(binary.BigEndian.Uint64(data[0:batchLengthOffset])),
+		BatchLength:          int32(binary.BigEndian.Uint32(data[batchLengthOffset:partitionLeaderEpochOffset])),
+		PartitionLeaderEpoch: int32(binary.BigEndian.Uint32(data[partitionLeaderEpochOffset:magicOffset])),
+		Magic:                int8(data[magicOffset]),
+		CRC:                  binary.BigEndian.Uint32(data[crcOffset:attributesOffset]),
+		Attributes:           int16(binary.BigEndian.Uint16(data[attributesOffset:lastOffsetDeltaOffset])),
+		LastOffsetDelta:      int32(binary.BigEndian.Uint32(data[lastOffsetDeltaOffset:baseTimestampOffset])),
+		BaseTimestamp:        int64(binary.BigEndian.Uint64(data[baseTimestampOffset:maxTimestampOffset])),
+		MaxTimestamp:         int64(binary.BigEndian.Uint64(data[maxTimestampOffset:producerIdOffset])),
+		ProducerID:           int64(binary.BigEndian.Uint64(data[producerIdOffset:producerEpochOffset])),
+		ProducerEpoch:        int16(binary.BigEndian.Uint16(data[producerEpochOffset:baseSequenceOffset])),
+		BaseSequence:         int32(binary.BigEndian.Uint32(data[baseSequenceOffset:recordsCountOffset])),
+		RecordsCount:         int32(binary.BigEndian.Uint32(data[recordsCountOffset:RecordBatchHeaderSize])),
+	}, nil
+}
+
+// SplitRecordBatches splits a record set into its batches, validating each one.
+func SplitRecordBatches(records []byte) ([][]byte, error) {
+	batches := [][]byte{}
+	for offset := 0; offset < len(records); {
+		header, err := ReadRecordBatchHeader(records[offset:])
+		if err != nil {
+			return nil, err
+		}
+		if header.BatchLength < RecordBatchHeaderSize-RecordBatchLogOverhead || offset+header.Size() > len(records) {
+			return nil, fmt.Errorf("%w: batch length %d at position %d overruns the record set", ErrCorruptRecordBatch, header.BatchLength, offset)
+		}
+		batch := records[offset : offset+header.Size()]
+		if err := ValidateRecordBatch(batch); err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+		offset += header.Size()
+	}
+	return batches, nil
+}
+
+// ValidateRecordBatch checks the magic byte and CRC of a single complete batch
+func ValidateRecordBatch(batch []byte) error {
+	header, err := ReadRecordBatchHeader(batch)
+	if err != nil {
+		return err
+	}
+	if header.Magic != RecordBatchMagic {
+		return fmt.Errorf("%w: unsupported magic byte %d", ErrCorruptRecordBatch, header.Magic)
+	}
+	if header.Size() != len(batch) {
+		return fmt.Errorf("%w: batch length %d does not match %d bytes", ErrCorruptRecordBatch, header.BatchLength, len(batch)-RecordBatchLogOverhead)
+	}
+	if crc := crc32.Checksum(batch[attributesOffset:], crc32cTable); crc != header.CRC {
+		return fmt.Errorf("%w: crc %08x does not match computed crc %08x", ErrCorruptRecordBatch, header.CRC, crc)
+	}
+	if header.RecordsCount < 0 || header.LastOffsetDelta < 0 {
+		return fmt.Errorf("%w: invalid record count %d", ErrCorruptRecordBatch, header.RecordsCount)
+	}
+	return nil
+}
+
+// SetRecordBatchBaseOffset overwrites the base offset in place. The CRC does not cover it.
+func SetRecordBatchBaseOffset(batch []byte, baseOffset int64) {
+	binary.BigEndian.PutUint64(batch[0:batchLengthOffset], uint64(baseOffset))
+}
+
+// SetRecordBatchPartitionLeaderEpoch overwrites the partition leader epoch in place. The CRC does not cover it.
+func SetRecordBatchPartitionLeaderEpoch(batch []byte, epoch int32) {
+	binary.BigEndian.PutUint32(batch[partitionLeaderEpochOffset:magicOffset], uint32(epoch))
+}
+
+// RecordHeader is a key/value header attached to a Record
+type RecordHeader struct {
+	Key   string
+	Value []byte
+}
+
+// Record is a single record inside an uncompressed RecordBatch.
+// A nil Key or Value is encoded as null.
+type Record struct {
+	Attributes     int8
+	TimestampDelta int64
+	OffsetDelta    int32
+	Key            []byte
+	Value          []byte
+	Headers        []RecordHeader
+}
+
+// RecordBatch is a decoded, uncompressed RecordBatch
+type RecordBatch struct {
+	RecordBatchHeader
+	Records []Record
+}
+
+// EncodeRecordBatch serializes an uncompressed batch, filling in Magic, Batch Length,
+// Records Length and CRC from the records.
+func EncodeRecordBatch(batch RecordBatch) []byte {
+	records := []byte{}
+	for _, record := range batch.Records {
+		records = append(records, encodeRecord(record)...)
+	}
+
+	data := make([]byte, RecordBatchHeaderSize, RecordBatchHeaderSize+len(records))
+	binary.BigEndian.PutUint64(data[0:batchLengthOffset], uint64(batch.BaseOffset))
+	binary.BigEndian.PutUint32(data[batchLengthOffset:partitionLeaderEpochOffset], uint32(RecordBatchHeaderSize-RecordBatchLogOverhead+len(records)))
+	binary.BigEndian.PutUint32(data[partitionLeaderEpochOffset:magicOffset], uint32(batch.PartitionLeaderEpoch))
+	data[magicOffset] = byte(RecordBatchMagic)
+	binary.BigEndian.PutUint16(data[attributesOffset:lastOffsetDeltaOffset], uint16(batch.Attributes))
+	binary.BigEndian.PutUint32(data[lastOffsetDeltaOffset:baseTimestampOffset], uint32(batch.LastOffsetDelta))
+	binary.BigEndian.PutUint64(data[baseTimestampOffset:maxTimestampOffset], uint64(batch.BaseTimestamp))
+	binary.BigEndian.PutUint64(data[maxTimestampOffset:producerIdOffset], uint64(batch.MaxTimestamp))
+	binary.BigEndian.PutUint64(data[producerIdOffset:producerEpochOffset], uint64(batch.ProducerID))
+	binary.BigEndian.PutUint16(data[producerEpochOffset:baseSequenceOffset], uint16(batch.ProducerEpoch))
+	binary.BigEndian.PutUint32(data[baseSequenceOffset:recordsCountOffset], uint32(batch.BaseSequence))
+	binary.BigEndian.PutUint32(data[recordsCountOffset:RecordBatchHeaderSize], uint32(len(batch.Records)))
+	data = append(data, records...)
+
+	binary.BigEndian.PutUint32(data[crcOffset:attributesOffset], crc32.Checksum(data[attributesOffset:], crc32cTable))
+	return data
+}
+
+func encodeRecord(record Record) []byte {
+	body := []byte{byte(record.Attributes)}
+	body = binary.AppendVarint(body, record.TimestampDelta)
+	body = binary.AppendVarint(body, int64(record.OffsetDelta))
+	body = appendVarintBytes(body, record.Key)
+	body = appendVarintBytes(body, record.Value)
+	body = binary.AppendVarint(body, int64(len(record.Headers)))
+	for _, header := range record.Headers {
+		body = appendVarintBytes(body, []byte(header.Key))
+		body = appendVarintBytes(body, header.Value)
+	}
+	return append(binary.AppendVarint([]byte{}, int64(len(body))), body...)
+}
+
+func appendVarintBytes(data []byte, value []byte) []byte {
+	if value == nil {
+		return binary.AppendVarint(data, -1)
+	}
+	data = binary.AppendVarint(data, int64(len(value)))
+	return append(data, value...)
+}
+
+// DecodeRecordBatch decodes a single complete uncompressed batch including its records
+func DecodeRecordBatch(batch []byte) (RecordBatch, error) {
+	header, err := ReadRecordBatchHeader(batch)
+	if err != nil {
+		return RecordBatch{}, err
+	}
+	if header.Size() > len(batch) {
+		return RecordBatch{}, fmt.Errorf("%w: batch length %d overruns %d bytes", ErrCorruptRecordBatch, header.BatchLength, len(batch))
+	}
+	if header.CompressionCodec() != 0 {
+		return RecordBatch{}, fmt.Errorf("compression codec %d is not supported", header.CompressionCodec())
+	}
+
+	decoded := RecordBatch{RecordBatchHeader: header, Records: make([]Record, 0, header.RecordsCount)}
+	reader := NewKafkaReader(batch[:header.Size()], RecordBatchHeaderSize)
+	for range header.RecordsCount {
+		length := reader.VarInt("record length")
+		recordEnd := reader.Offset() + length
+		record := Record{
+			Attributes:     reader.Int8("record attributes"),
+			TimestampDelta: int64(reader.VarInt("record timestamp delta")),
+			OffsetDelta:    int32(reader.VarInt("record offset delta")),
+			Key:            readVarintBytes(reader, "record key"),
+			Value:          readVarintBytes(reader, "record value"),
+		}
+		headerCount := reader.VarInt("record headers count")
+		for range headerCount {
+			record.Headers = append(record.Headers, RecordHeader{
+				Key:   string(readVarintBytes(reader, "record header key")),
+				Value: readVarintBytes(reader, "record header value"),
+			})
+		}
+		if err := reader.Err(); err != nil {
+			return RecordBatch{}, fmt.Errorf("%w: %v", ErrCorruptRecordBatch, err)
+		}
+		if reader.Offset() != recordEnd {
+			return RecordBatch{}, fmt.Errorf("%w: record length %d does not match its contents", ErrCorruptRecordBatch, length)
+		}
+		decoded.Records = append(decoded.Records, record)
+	}
+	return decoded, nil
+}
+
+func readVarintBytes(reader *KafkaReader, field string) []byte {
+	length := reader.VarInt(field)
+	if length < 0 {
+		return nil
+	}
+	return reader.take(length, field)
+}